@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/zishang520/socket.io/v2/socket"
 
 	"ccbackend/models"
 )
@@ -38,9 +37,12 @@ type DiscordClient interface {
 	GetBotUser() (*DiscordBotUser, error)
 	GetChannelByID(channelID string) (*DiscordChannel, error)
 	PostMessage(channelID string, params DiscordMessageParams) (*DiscordPostMessageResponse, error)
+	UpdateMessage(channelID, messageID, content string) error
 	AddReaction(channelID, messageID, emoji string) error
 	RemoveReaction(channelID, messageID, emoji string) error
 	CreatePublicThread(channelID, messageID, threadName string) (*DiscordThreadResponse, error)
+	DownloadAttachment(url string) ([]byte, error)
+	UploadFile(channelID string, params DiscordUploadFileParams) error
 }
 
 // SlackClient defines the interface for Slack API operations
@@ -57,6 +59,11 @@ type SlackClient interface {
 
 	// Message operations
 	PostMessage(channelID string, params SlackMessageParams) (*SlackPostMessageResponse, error)
+	UpdateMessage(channelID, timestamp, text string) error
+
+	// File operations
+	DownloadFile(url string) ([]byte, error)
+	UploadFile(params SlackUploadFileParams) error
 
 	// Reaction operations
 	GetReactions(item SlackItemRef, params SlackGetReactionsParameters) ([]SlackItemReaction, error)
@@ -100,19 +107,42 @@ type SocketIOClient interface {
 	RegisterConnectionHook(hook ConnectionHookFunc)
 	RegisterDisconnectionHook(hook ConnectionHookFunc)
 	RegisterPingHook(hook PingHandlerFunc)
+	RegisterAckHook(hook AckHookFunc)
+	RegisterDeliveryFailureHook(hook DeliveryFailureHookFunc)
+	RegisterOutgoingMessageHook(hook OutgoingMessageHookFunc)
 }
 
 // Hook and handler function types
+// A MessageHandlerFunc returns core.ErrMessageInProgress to leave the message unacknowledged.
 type MessageHandlerFunc func(client *Client, msg any) error
 type ConnectionHookFunc func(client *Client) error
-type PingHandlerFunc func(client *Client) error
+type PingHandlerFunc func(client *Client, telemetry models.AgentTelemetry) error
+type AckHookFunc func(client *Client, messageID string) error
+type DeliveryFailureHookFunc func(client *Client, messageID string, reason error) error
+type OutgoingMessageHookFunc func(client *Client, msg any) (any, error)
 type APIKeyValidatorFunc func(apiKey string) (string, error)
 
+// AgentConnection is the transport an agent is connected over, either Socket.IO or plain WebSocket
+type AgentConnection interface {
+	// Emit sends an event to the agent - ack, if not nil, is called once the agent acknowledged the event
+	Emit(event string, data any, ack func(err error)) error
+	// Close disconnects the agent
+	Close()
+}
+
 // Client represents a connected WebSocket client
 type Client struct {
 	ID      string
-	Socket  *socket.Socket
+	Conn    AgentConnection
 	OrgID   models.OrgID
 	AgentID string
 	RepoURL string
+	// MaxConcurrency is the maximum number of jobs the agent accepts at once (0 means no limit)
+	MaxConcurrency int
+	// Labels are arbitrary key/value pairs the agent advertised, matched against channel agent selectors
+	Labels models.AgentLabels
+	// Telemetry is the version and host metadata the agent reported in its handshake
+	Telemetry models.AgentTelemetry
+	// Protocol is the protocol version and message types negotiated with the agent in its handshake
+	Protocol models.AgentProtocol
 }
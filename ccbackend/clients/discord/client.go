@@ -1,8 +1,10 @@
 package discord
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 
 	"ccbackend/clients"
@@ -108,6 +110,14 @@ func (c *DiscordClient) PostMessage(
 	}, nil
 }
 
+// UpdateMessage replaces the content of a message the bot posted in a Discord channel or thread
+func (c *DiscordClient) UpdateMessage(channelID, messageID, content string) error {
+	if _, err := c.sdkClient.ChannelMessageEdit(channelID, messageID, content); err != nil {
+		return fmt.Errorf("failed to edit Discord message: %w", err)
+	}
+	return nil
+}
+
 // AddReaction adds a reaction emoji to a Discord message
 func (c *DiscordClient) AddReaction(channelID, messageID, emoji string) error {
 	err := c.sdkClient.MessageReactionAdd(channelID, messageID, emoji)
@@ -142,3 +152,45 @@ func (c *DiscordClient) CreatePublicThread(
 		ThreadName: thread.Name,
 	}, nil
 }
+
+// DownloadAttachment downloads a file attached to a Discord message from its CDN URL
+func (c *DiscordClient) DownloadAttachment(url string) ([]byte, error) {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download Discord attachment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download Discord attachment: unexpected status %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Discord attachment: %w", err)
+	}
+	return content, nil
+}
+
+// UploadFile uploads a file with an optional comment to a Discord channel or thread
+func (c *DiscordClient) UploadFile(channelID string, params clients.DiscordUploadFileParams) error {
+	targetChannelID := channelID
+	if params.ThreadID != nil && *params.ThreadID != "" {
+		targetChannelID = *params.ThreadID
+	}
+
+	_, err := c.sdkClient.ChannelMessageSendComplex(targetChannelID, &discordgo.MessageSend{
+		Content: params.Comment,
+		Files: []*discordgo.File{
+			{
+				Name:        params.Filename,
+				ContentType: params.ContentType,
+				Reader:      bytes.NewReader(params.Content),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload file to Discord: %w", err)
+	}
+	return nil
+}
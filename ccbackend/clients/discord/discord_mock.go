@@ -50,6 +50,12 @@ func (m *MockDiscordClient) PostMessage(
 	return args.Get(0).(*clients.DiscordPostMessageResponse), args.Error(1)
 }
 
+// UpdateMessage mocks editing a Discord message
+func (m *MockDiscordClient) UpdateMessage(channelID, messageID, content string) error {
+	args := m.Called(channelID, messageID, content)
+	return args.Error(0)
+}
+
 // AddReaction mocks adding a reaction to a Discord message
 func (m *MockDiscordClient) AddReaction(channelID, messageID, emoji string) error {
 	args := m.Called(channelID, messageID, emoji)
@@ -72,3 +78,18 @@ func (m *MockDiscordClient) CreatePublicThread(
 	}
 	return args.Get(0).(*clients.DiscordThreadResponse), args.Error(1)
 }
+
+// DownloadAttachment mocks downloading a file attached to a Discord message
+func (m *MockDiscordClient) DownloadAttachment(url string) ([]byte, error) {
+	args := m.Called(url)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+// UploadFile mocks uploading a file to a Discord channel or thread
+func (m *MockDiscordClient) UploadFile(channelID string, params clients.DiscordUploadFileParams) error {
+	args := m.Called(channelID, params)
+	return args.Error(0)
+}
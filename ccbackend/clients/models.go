@@ -56,6 +56,15 @@ type SlackMessageParams struct {
 	ThreadTS mo.Option[string]
 }
 
+// SlackUploadFileParams holds parameters for uploading a file to a Slack thread
+type SlackUploadFileParams struct {
+	Channel        string
+	ThreadTS       string
+	Filename       string
+	Content        []byte
+	InitialComment string
+}
+
 // DiscordBotUser represents Discord bot user information
 type DiscordBotUser struct {
 	ID       string
@@ -88,3 +97,12 @@ type DiscordThreadResponse struct {
 	ThreadID   string
 	ThreadName string
 }
+
+// DiscordUploadFileParams holds parameters for uploading a file to Discord
+type DiscordUploadFileParams struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+	Comment     string
+	ThreadID    *string // For uploading files to threads
+}
@@ -1,6 +1,7 @@
 package slack
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 
@@ -109,6 +110,34 @@ func (c *SlackClient) PostMessage(
 	}, nil
 }
 
+// UpdateMessage replaces the text of a message the bot posted
+func (c *SlackClient) UpdateMessage(channelID, timestamp, text string) error {
+	_, _, _, err := c.Client.UpdateMessage(channelID, timestamp, slack.MsgOptionText(text, false))
+	return err
+}
+
+// DownloadFile downloads a file shared in Slack from its private download URL
+func (c *SlackClient) DownloadFile(url string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.Client.GetFile(url, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UploadFile uploads a file to a Slack thread
+func (c *SlackClient) UploadFile(params clients.SlackUploadFileParams) error {
+	_, err := c.Client.UploadFileV2(slack.UploadFileV2Parameters{
+		Filename:        params.Filename,
+		FileSize:        len(params.Content),
+		Reader:          bytes.NewReader(params.Content),
+		InitialComment:  params.InitialComment,
+		Channel:         params.Channel,
+		ThreadTimestamp: params.ThreadTS,
+	})
+	return err
+}
+
 // GetReactions gets the reactions on a message
 func (c *SlackClient) GetReactions(
 	item clients.SlackItemRef,
@@ -21,7 +21,12 @@ type MockSlackClient struct {
 	MockResolveMentionsInMessage func(ctx context.Context, message string) string
 
 	// Message operations
-	MockPostMessage func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error)
+	MockPostMessage   func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error)
+	MockUpdateMessage func(channelID, timestamp, text string) error
+
+	// File operations
+	MockDownloadFile func(url string) ([]byte, error)
+	MockUploadFile   func(params clients.SlackUploadFileParams) error
 
 	// Reaction operations
 	MockGetReactions   func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error)
@@ -107,6 +112,36 @@ func (m *MockSlackClient) PostMessage(
 	}, nil
 }
 
+// UpdateMessage implements SlackClient interface for testing
+func (m *MockSlackClient) UpdateMessage(channelID, timestamp, text string) error {
+	if m.MockUpdateMessage != nil {
+		return m.MockUpdateMessage(channelID, timestamp, text)
+	}
+
+	// Default mock response
+	return nil
+}
+
+// DownloadFile implements SlackClient interface for testing
+func (m *MockSlackClient) DownloadFile(url string) ([]byte, error) {
+	if m.MockDownloadFile != nil {
+		return m.MockDownloadFile(url)
+	}
+
+	// Default mock response
+	return []byte("test file content"), nil
+}
+
+// UploadFile implements SlackClient interface for testing
+func (m *MockSlackClient) UploadFile(params clients.SlackUploadFileParams) error {
+	if m.MockUploadFile != nil {
+		return m.MockUploadFile(params)
+	}
+
+	// Default mock response
+	return nil
+}
+
 // GetReactions implements SlackClient interface for testing
 func (m *MockSlackClient) GetReactions(
 	item clients.SlackItemRef,
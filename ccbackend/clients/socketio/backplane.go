@@ -0,0 +1,281 @@
+package socketio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/samber/mo"
+
+	"ccbackend/clients"
+	"ccbackend/core"
+	"ccbackend/models"
+)
+
+const (
+	// How often an instance refreshes its heartbeat and sweeps for undelivered relayed messages
+	backplaneHeartbeatInterval = 10 * time.Second
+	// Instances without a heartbeat for this long are considered dead along with their connections
+	backplaneInstanceStaleAfter = 30 * time.Second
+)
+
+// RelayHandlerFunc delivers a message relayed from another replica to a locally connected client
+type RelayHandlerFunc func(message *models.SocketIORelayedMessage)
+
+// Backplane shares Socket.IO connections between backend replicas so that any replica
+// can see which agents are connected and reach agents connected to another replica
+type Backplane interface {
+	Start(handler RelayHandlerFunc) error
+	Stop()
+
+	RegisterConnection(ctx context.Context, client *clients.Client) error
+	UnregisterConnection(ctx context.Context, clientID string) error
+	GetConnectedClientIDs(ctx context.Context) ([]string, error)
+
+	// Relay hands the message to the replica holding the client
+	// Returns core.ErrNotFound if the client is not connected to any other live replica
+	Relay(ctx context.Context, clientID string, kind models.SocketIORelayKind, msg any) error
+}
+
+// BackplaneStore keeps the replicas, their connections and the messages relayed between them in Postgres
+type BackplaneStore interface {
+	UpsertInstanceHeartbeat(ctx context.Context, instanceID string) (bool, error)
+	DeleteInstance(ctx context.Context, instanceID string) error
+	DeleteStaleInstances(ctx context.Context, staleAfter time.Duration) (int64, error)
+
+	UpsertConnection(ctx context.Context, connection *models.SocketIOConnection) error
+	DeleteConnection(ctx context.Context, clientID, instanceID string) (bool, error)
+	GetLiveConnectionIDs(ctx context.Context, staleAfter time.Duration) ([]string, error)
+	GetLiveConnectionInstanceID(
+		ctx context.Context,
+		clientID string,
+		staleAfter time.Duration,
+	) (mo.Option[string], error)
+
+	EnqueueRelayedMessage(ctx context.Context, message *models.SocketIORelayedMessage, notifyChannel string) error
+	PopRelayedMessages(ctx context.Context, instanceID string) ([]*models.SocketIORelayedMessage, error)
+}
+
+// PostgresBackplane implements Backplane with a shared connection registry in Postgres
+// and LISTEN/NOTIFY for waking up the replica which needs to deliver a relayed message
+type PostgresBackplane struct {
+	repo          BackplaneStore
+	databaseURL   string
+	instanceID    string
+	notifyChannel string
+
+	listener *pq.Listener
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopOnce sync.Once
+
+	// Connections registered by this instance, re-registered if other replicas removed this instance as stale
+	connections map[string]*clients.Client
+	mutex       sync.Mutex
+}
+
+func NewPostgresBackplane(
+	repo BackplaneStore,
+	databaseURL string,
+	databaseSchema string,
+) *PostgresBackplane {
+	return &PostgresBackplane{
+		repo:          repo,
+		databaseURL:   databaseURL,
+		instanceID:    core.NewID("sio"),
+		notifyChannel: databaseSchema + "_socketio_relay",
+		stopCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+		connections:   make(map[string]*clients.Client),
+	}
+}
+
+// Start registers this replica and begins listening for messages relayed to it
+func (b *PostgresBackplane) Start(handler RelayHandlerFunc) error {
+	log.Printf("🚀 Starting Socket.IO backplane for instance %s", b.instanceID)
+	if _, err := b.repo.UpsertInstanceHeartbeat(context.Background(), b.instanceID); err != nil {
+		return fmt.Errorf("failed to register socketio instance: %w", err)
+	}
+
+	listener := pq.NewListener(b.databaseURL, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("⚠️ Socket.IO backplane listener event %d: %v", ev, err)
+		}
+	})
+	if err := listener.Listen(b.notifyChannel); err != nil {
+		_ = listener.Close()
+		return fmt.Errorf("failed to listen on channel %s: %w", b.notifyChannel, err)
+	}
+	b.listener = listener
+
+	go b.run(handler)
+	log.Printf("✅ Socket.IO backplane started for instance %s on channel %s", b.instanceID, b.notifyChannel)
+	return nil
+}
+
+// Stop deregisters this replica - its connections become invisible to other replicas right away
+func (b *PostgresBackplane) Stop() {
+	b.stopOnce.Do(func() {
+		log.Printf("🛑 Stopping Socket.IO backplane for instance %s", b.instanceID)
+		close(b.stopCh)
+		if b.listener != nil {
+			<-b.doneCh
+			_ = b.listener.Close()
+		}
+		if err := b.repo.DeleteInstance(context.Background(), b.instanceID); err != nil {
+			log.Printf("❌ Failed to delete socketio instance %s: %v", b.instanceID, err)
+		}
+	})
+}
+
+func (b *PostgresBackplane) run(handler RelayHandlerFunc) {
+	defer close(b.doneCh)
+	ticker := time.NewTicker(backplaneHeartbeatInterval)
+	defer ticker.Stop()
+
+	// Deliver anything which was relayed to this instance before the listener was set up
+	b.drainRelayedMessages(handler)
+
+	for {
+		select {
+		case <-b.stopCh:
+			return
+		case notification := <-b.listener.Notify:
+			// A nil notification means the listener reconnected and may have missed notifications
+			if notification != nil && notification.Extra != b.instanceID {
+				continue
+			}
+			b.drainRelayedMessages(handler)
+		case <-ticker.C:
+			b.heartbeat()
+			b.drainRelayedMessages(handler)
+		}
+	}
+}
+
+func (b *PostgresBackplane) heartbeat() {
+	ctx := context.Background()
+	recreated, err := b.repo.UpsertInstanceHeartbeat(ctx, b.instanceID)
+	if err != nil {
+		log.Printf("❌ Failed to refresh heartbeat for socketio instance %s: %v", b.instanceID, err)
+		return
+	}
+	if recreated {
+		b.reregisterConnections(ctx)
+	}
+
+	deleted, err := b.repo.DeleteStaleInstances(ctx, backplaneInstanceStaleAfter)
+	if err != nil {
+		log.Printf("❌ Failed to delete stale socketio instances: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("🧹 Deleted %d stale socketio instance(s) and their connections", deleted)
+	}
+}
+
+// reregisterConnections restores the registry entries of local clients after another replica
+// removed this instance as stale (e.g. heartbeats were delayed by a database outage)
+func (b *PostgresBackplane) reregisterConnections(ctx context.Context) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	log.Printf("⚠️ Socket.IO instance %s was removed as stale, re-registering %d connections", b.instanceID, len(b.connections))
+	for _, client := range b.connections {
+		if err := b.upsertConnection(ctx, client); err != nil {
+			log.Printf("❌ Failed to re-register connection for client %s: %v", client.ID, err)
+		}
+	}
+}
+
+func (b *PostgresBackplane) drainRelayedMessages(handler RelayHandlerFunc) {
+	messages, err := b.repo.PopRelayedMessages(context.Background(), b.instanceID)
+	if err != nil {
+		log.Printf("❌ Failed to fetch relayed messages for socketio instance %s: %v", b.instanceID, err)
+		return
+	}
+
+	for _, message := range messages {
+		log.Printf("📨 Delivering relayed %s message %s to client %s", message.Kind, message.ID, message.ClientID)
+		handler(message)
+	}
+}
+
+func (b *PostgresBackplane) RegisterConnection(ctx context.Context, client *clients.Client) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.connections[client.ID] = client
+	return b.upsertConnection(ctx, client)
+}
+
+func (b *PostgresBackplane) upsertConnection(ctx context.Context, client *clients.Client) error {
+	connection := &models.SocketIOConnection{
+		ClientID:   client.ID,
+		InstanceID: b.instanceID,
+		OrgID:      client.OrgID,
+		CCAgentID:  client.AgentID,
+	}
+	if err := b.repo.UpsertConnection(ctx, connection); err != nil {
+		return fmt.Errorf("failed to register connection for client %s: %w", client.ID, err)
+	}
+	return nil
+}
+
+func (b *PostgresBackplane) UnregisterConnection(ctx context.Context, clientID string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.connections, clientID)
+	if _, err := b.repo.DeleteConnection(ctx, clientID, b.instanceID); err != nil {
+		return fmt.Errorf("failed to unregister connection for client %s: %w", clientID, err)
+	}
+	return nil
+}
+
+func (b *PostgresBackplane) GetConnectedClientIDs(ctx context.Context) ([]string, error) {
+	clientIDs, err := b.repo.GetLiveConnectionIDs(ctx, backplaneInstanceStaleAfter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connected client IDs: %w", err)
+	}
+	return clientIDs, nil
+}
+
+func (b *PostgresBackplane) Relay(
+	ctx context.Context,
+	clientID string,
+	kind models.SocketIORelayKind,
+	msg any,
+) error {
+	maybeInstanceID, err := b.repo.GetLiveConnectionInstanceID(ctx, clientID, backplaneInstanceStaleAfter)
+	if err != nil {
+		return fmt.Errorf("failed to look up instance for client %s: %w", clientID, err)
+	}
+	// A registry entry pointing at this instance is stale - the client is not in local memory anymore
+	if !maybeInstanceID.IsPresent() || maybeInstanceID.MustGet() == b.instanceID {
+		return core.ErrNotFound
+	}
+
+	var payload []byte
+	if msg != nil {
+		payload, err = json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal relayed message for client %s: %w", clientID, err)
+		}
+	}
+
+	message := &models.SocketIORelayedMessage{
+		ID:         core.NewID("rm"),
+		InstanceID: maybeInstanceID.MustGet(),
+		ClientID:   clientID,
+		Kind:       kind,
+		Payload:    payload,
+	}
+	if err := b.repo.EnqueueRelayedMessage(ctx, message, b.notifyChannel); err != nil {
+		return fmt.Errorf("failed to relay message to client %s: %w", clientID, err)
+	}
+
+	log.Printf("📡 Relayed %s message %s for client %s to instance %s", kind, message.ID, clientID, message.InstanceID)
+	return nil
+}
@@ -0,0 +1,52 @@
+package socketio
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"ccbackend/clients"
+	"ccbackend/models"
+)
+
+// MockBackplane is a mock implementation of the Backplane interface
+type MockBackplane struct {
+	mock.Mock
+}
+
+func (m *MockBackplane) Start(handler RelayHandlerFunc) error {
+	args := m.Called(handler)
+	return args.Error(0)
+}
+
+func (m *MockBackplane) Stop() {
+	m.Called()
+}
+
+func (m *MockBackplane) RegisterConnection(ctx context.Context, client *clients.Client) error {
+	args := m.Called(ctx, client)
+	return args.Error(0)
+}
+
+func (m *MockBackplane) UnregisterConnection(ctx context.Context, clientID string) error {
+	args := m.Called(ctx, clientID)
+	return args.Error(0)
+}
+
+func (m *MockBackplane) GetConnectedClientIDs(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockBackplane) Relay(
+	ctx context.Context,
+	clientID string,
+	kind models.SocketIORelayKind,
+	msg any,
+) error {
+	args := m.Called(ctx, clientID, kind, msg)
+	return args.Error(0)
+}
@@ -5,8 +5,12 @@ import (
 	"ccbackend/core"
 	"ccbackend/models"
 	"ccbackend/utils"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -22,26 +26,38 @@ type Message struct {
 type Server struct {
 	server             *socket.Server
 	clients            []*clients.Client
-	clientsBySocketID  map[string]*clients.Client
 	mutex              sync.RWMutex
 	messageHandlers    []clients.MessageHandlerFunc
 	connectionHooks    []clients.ConnectionHookFunc
 	disconnectionHooks []clients.ConnectionHookFunc
 	pingHooks          []clients.PingHandlerFunc
-	apiKeyValidator    clients.APIKeyValidatorFunc
+	ackHooks           []clients.AckHookFunc
+	// deliveryFailureHooks learn about relayed messages this replica could not hand to their client
+	deliveryFailureHooks []clients.DeliveryFailureHookFunc
+	// outgoingMessageHooks prepare messages right before they are emitted, on the replica holding the client
+	outgoingMessageHooks []clients.OutgoingMessageHookFunc
+	apiKeyValidator      clients.APIKeyValidatorFunc
+	// backplane shares connections with other backend replicas
+	backplane Backplane
 }
 
-func NewSocketIOClient(apiKeyValidator clients.APIKeyValidatorFunc) *Server {
-	server := socket.NewServer(nil, nil)
+func NewSocketIOClient(apiKeyValidator clients.APIKeyValidatorFunc, backplane Backplane) *Server {
+	// Agents send artifacts inline, so messages can be much larger than the 1 MB engine.io default
+	opts := socket.DefaultServerOptions()
+	opts.SetMaxHttpBufferSize(models.MaxAgentMessageSize)
+	server := socket.NewServer(nil, opts)
 	wsClient := &Server{
-		server:             server,
-		clients:            make([]*clients.Client, 0),
-		clientsBySocketID:  make(map[string]*clients.Client),
-		messageHandlers:    make([]clients.MessageHandlerFunc, 0),
-		connectionHooks:    make([]clients.ConnectionHookFunc, 0),
-		disconnectionHooks: make([]clients.ConnectionHookFunc, 0),
-		pingHooks:          make([]clients.PingHandlerFunc, 0),
-		apiKeyValidator:    apiKeyValidator,
+		server:               server,
+		clients:              make([]*clients.Client, 0),
+		messageHandlers:      make([]clients.MessageHandlerFunc, 0),
+		connectionHooks:      make([]clients.ConnectionHookFunc, 0),
+		disconnectionHooks:   make([]clients.ConnectionHookFunc, 0),
+		pingHooks:            make([]clients.PingHandlerFunc, 0),
+		ackHooks:             make([]clients.AckHookFunc, 0),
+		deliveryFailureHooks: make([]clients.DeliveryFailureHookFunc, 0),
+		outgoingMessageHooks: make([]clients.OutgoingMessageHookFunc, 0),
+		apiKeyValidator:      apiKeyValidator,
+		backplane:            backplane,
 	}
 
 	// Set up Socket.IO connection handler
@@ -54,14 +70,34 @@ func NewSocketIOClient(apiKeyValidator clients.APIKeyValidatorFunc) *Server {
 	return wsClient
 }
 
+// Start connects the server to the backplane so it can receive messages relayed from other replicas
+func (ws *Server) Start() error {
+	if err := ws.backplane.Start(ws.deliverRelayedMessage); err != nil {
+		return fmt.Errorf("failed to start socketio backplane: %w", err)
+	}
+	return nil
+}
+
+// Stop disconnects the server from the backplane, other replicas stop routing messages to it
+func (ws *Server) Stop() {
+	ws.backplane.Stop()
+}
+
 func (ws *Server) RegisterWithRouter(router *mux.Router) {
 	log.Printf("🚀 Registering Socket.IO server on /socket.io/ endpoint")
 	router.PathPrefix("/socket.io/").Handler(ws.server.ServeHandler(nil))
 	log.Printf("✅ Socket.IO server registered on /socket.io/")
+
+	// Plain WebSocket endpoint for agents which do not speak Socket.IO - it shares the clients and hooks
+	router.HandleFunc("/ws", ws.handleWebSocketConnection).Methods("GET")
+	log.Printf("✅ WebSocket agent endpoint registered on /ws")
 }
 
-// getSocketIOHeader performs a case-insensitive lookup for a header in the headers map
-func getSocketIOHeader(headers map[string][]string, headerName string) (string, bool) {
+// errIncompatibleProtocol rejects an agent whose protocol cannot be negotiated - the agent is told the reason
+var errIncompatibleProtocol = errors.New("incompatible agent protocol")
+
+// getHandshakeHeader performs a case-insensitive lookup for a header in the headers map
+func getHandshakeHeader(headers map[string][]string, headerName string) (string, bool) {
 	for key, value := range headers {
 		if strings.EqualFold(key, headerName) {
 			if len(value) > 0 && value[0] != "" {
@@ -72,79 +108,178 @@ func getSocketIOHeader(headers map[string][]string, headerName string) (string,
 	return "", false
 }
 
-func (ws *Server) handleSocketIOConnection(sock *socket.Socket) {
-	log.Printf("🔗 New Socket.IO connection attempt, socket ID: %s", sock.Id())
-
-	headers := sock.Handshake().Headers
-	apiKey, exists := getSocketIOHeader(headers, "X-CCAGENT-API-KEY")
+// newClientFromHandshake authenticates an agent by the X-CCAGENT-* headers of its handshake and creates its client
+// Agents send the same headers over every transport. The caller sets the client's connection.
+func (ws *Server) newClientFromHandshake(headers map[string][]string) (*clients.Client, error) {
+	apiKey, exists := getHandshakeHeader(headers, "X-CCAGENT-API-KEY")
 	if !exists {
-		log.Printf("❌ Rejecting Socket.IO connection: missing X-CCAGENT-API-KEY header")
-		sock.Disconnect(true)
-		return
+		return nil, fmt.Errorf("missing X-CCAGENT-API-KEY header")
 	}
 
-	agentID, exists := getSocketIOHeader(headers, "X-CCAGENT-ID")
+	agentID, exists := getHandshakeHeader(headers, "X-CCAGENT-ID")
 	if !exists {
-		log.Printf("❌ No X-CCAGENT-ID provided, rejecting connection")
-		sock.Disconnect(true)
-		return
+		return nil, fmt.Errorf("missing X-CCAGENT-ID header")
 	}
 
 	if !core.IsValidULID(agentID) {
-		log.Printf("❌ Rejecting Socket.IO connection: agent ID must be a valid ULID")
-		sock.Disconnect(true)
-		return
+		return nil, fmt.Errorf("agent ID must be a valid ULID")
 	}
 
 	// Validate API key
 	orgID, err := ws.apiKeyValidator(apiKey)
 	if err != nil {
-		log.Printf("❌ Rejecting Socket.IO connection: invalid API key: %v", err)
-		sock.Disconnect(true)
-		return
+		return nil, fmt.Errorf("invalid API key: %w", err)
 	}
 
 	// Extract repository URL from headers
-	repoURL, exists := getSocketIOHeader(headers, "X-CCAGENT-REPO")
+	repoURL, exists := getHandshakeHeader(headers, "X-CCAGENT-REPO")
 	if !exists || repoURL == "" {
 		repoURL = "github.com/unknown/repository"
 	}
 
-	client := &clients.Client{
-		ID:      core.NewID("cl"),
-		Socket:  sock,
-		OrgID:   models.OrgID(orgID),
-		AgentID: agentID,
-		RepoURL: repoURL,
+	// Extract agent-declared concurrency limit from headers (0 means no limit)
+	maxConcurrency := 0
+	if maxConcurrencyStr, exists := getHandshakeHeader(headers, "X-CCAGENT-MAX-CONCURRENCY"); exists {
+		maxConcurrency, err = strconv.Atoi(maxConcurrencyStr)
+		if err != nil || maxConcurrency < 0 {
+			return nil, fmt.Errorf("invalid X-CCAGENT-MAX-CONCURRENCY header: %s", maxConcurrencyStr)
+		}
+	}
+
+	// Extract agent-advertised labels from headers, e.g. "gpu=false,team=payments"
+	labels := models.AgentLabels{}
+	if labelsStr, exists := getHandshakeHeader(headers, "X-CCAGENT-LABELS"); exists {
+		labels, err = models.ParseAgentLabels(labelsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid X-CCAGENT-LABELS header: %w", err)
+		}
+	}
+
+	// Extract optional agent version and host metadata from headers
+	var telemetry models.AgentTelemetry
+	telemetry.Version, _ = getHandshakeHeader(headers, "X-CCAGENT-VERSION")
+	telemetry.OS, _ = getHandshakeHeader(headers, "X-CCAGENT-OS")
+	telemetry.Hostname, _ = getHandshakeHeader(headers, "X-CCAGENT-HOSTNAME")
+
+	// Negotiate the protocol version and message types the agent understands, e.g. "1" and
+	// "start_conversation_v1,user_message_v1" - agents without these headers are treated as legacy version 1 agents
+	protocolVersion, _ := getHandshakeHeader(headers, "X-CCAGENT-PROTOCOL-VERSION")
+	capabilities, _ := getHandshakeHeader(headers, "X-CCAGENT-CAPABILITIES")
+	protocol, err := models.NegotiateAgentProtocol(protocolVersion, capabilities)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errIncompatibleProtocol, err)
 	}
+
+	return &clients.Client{
+		ID:             core.NewID("cl"),
+		OrgID:          models.OrgID(orgID),
+		AgentID:        agentID,
+		RepoURL:        repoURL,
+		MaxConcurrency: maxConcurrency,
+		Labels:         labels,
+		Telemetry:      telemetry,
+		Protocol:       protocol,
+	}, nil
+}
+
+// connectClient registers the client of an accepted handshake and confirms the negotiated protocol to the agent
+func (ws *Server) connectClient(client *clients.Client) {
 	ws.addClient(client)
-	log.Printf("✅ Socket.IO client connected with ID: %s, socket ID: %s", client.ID, sock.Id())
+	if err := client.Conn.Emit("protocol_negotiated", client.Protocol, nil); err != nil {
+		log.Printf("⚠️ Failed to send negotiated protocol to client %s: %v", client.ID, err)
+	}
 	ws.invokeConnectionHooks(client)
+}
 
-	// Set up message handler for cc_message event
+// disconnectClient runs the disconnection hooks of a client whose connection closed and removes it
+func (ws *Server) disconnectClient(client *clients.Client) {
+	ws.invokeDisconnectionHooks(client)
+	ws.removeClient(client.ID)
+}
+
+// socketIOConnection is the connection of an agent connected over Socket.IO
+type socketIOConnection struct {
+	sock *socket.Socket
+}
+
+func (c socketIOConnection) Emit(event string, data any, ack func(err error)) error {
+	if ack == nil {
+		return c.sock.Emit(event, data)
+	}
+	return c.sock.Emit(event, data, func(_ []any, err error) {
+		ack(err)
+	})
+}
+
+func (c socketIOConnection) Close() {
+	c.sock.Disconnect(true)
+}
+
+func (ws *Server) handleSocketIOConnection(sock *socket.Socket) {
+	log.Printf("🔗 New Socket.IO connection attempt, socket ID: %s", sock.Id())
+
+	client, err := ws.newClientFromHandshake(sock.Handshake().Headers)
+	if err != nil {
+		log.Printf("❌ Rejecting Socket.IO connection: %v", err)
+		if errors.Is(err, errIncompatibleProtocol) {
+			rejectConnection(sock, err.Error())
+			return
+		}
+		sock.Disconnect(true)
+		return
+	}
+
+	client.Conn = socketIOConnection{sock: sock}
+	log.Printf(
+		"✅ Socket.IO client connected with ID: %s, socket ID: %s, protocol version: %d",
+		client.ID,
+		sock.Id(),
+		client.Protocol.Version,
+	)
+	ws.connectClient(client)
+
+	// Set up message handler for cc_message event - the message is acknowledged once it was handled,
+	// so the agent stops resending it. Resent duplicates are acknowledged the same way, unless the original
+	// is still being processed.
 	err = sock.On("cc_message", func(data ...any) {
+		var ack func([]any, error)
+		if len(data) > 0 {
+			if fn, ok := data[len(data)-1].(func([]any, error)); ok {
+				ack = fn
+				data = data[:len(data)-1]
+			}
+		}
 		if len(data) == 0 {
 			log.Printf("❌ No message data received for client %s", client.ID)
 			return
 		}
 
 		log.Printf("📥 Raw message received from client %s", client.ID)
-		ws.invokeMessageHandlers(client, data[0])
+		if ws.invokeMessageHandlers(client, data[0]) && ack != nil {
+			ack(nil, nil)
+		}
 	})
 	utils.AssertInvariant(err == nil, fmt.Sprintf("Failed to set up message handler for client %s: %v", client.ID, err))
 
-	// Handle ping events
+	// Handle ping events - the ack is the pong, which the agent times to report the round-trip time
+	// in its next ping
 	err = sock.On("ping", func(data ...any) {
 		log.Printf("💓 Received ping from client %s (socket ID: %s)", client.ID, sock.Id())
-		ws.invokePingHooks(client)
+		if len(data) > 0 {
+			if ack, ok := data[len(data)-1].(func([]any, error)); ok {
+				ack(nil, nil)
+				data = data[:len(data)-1]
+			}
+		}
+
+		ws.invokePingHooks(client, parsePingTelemetry(client, data))
 	})
 	utils.AssertInvariant(err == nil, fmt.Sprintf("Failed to set up ping handler for client %s: %v", client.ID, err))
 
 	// Handle disconnection
 	err = sock.On("disconnect", func(data ...any) {
 		log.Printf("🔌 Socket.IO connection closed for client %s (socket ID: %s)", client.ID, sock.Id())
-		ws.invokeDisconnectionHooks(client)
-		ws.removeClient(client.ID)
+		ws.disconnectClient(client)
 	})
 	utils.AssertInvariant(
 		err == nil,
@@ -154,37 +289,95 @@ func (ws *Server) handleSocketIOConnection(sock *socket.Socket) {
 	log.Printf("👂 Message listener setup complete for client %s", client.ID)
 }
 
+// rejectConnection tells the agent why its connection is refused before disconnecting it
+func rejectConnection(sock *socket.Socket, reason string) {
+	if err := sock.Emit("connection_rejected", map[string]string{"reason": reason}); err != nil {
+		log.Printf("⚠️ Failed to send rejection reason to socket %s: %v", sock.Id(), err)
+	}
+	sock.Disconnect(true)
+}
+
+// parsePingTelemetry extracts the telemetry an agent may attach to a ping
+// Pings without a payload or with a malformed one still count as a heartbeat
+func parsePingTelemetry(client *clients.Client, data []any) models.AgentTelemetry {
+	var telemetry models.AgentTelemetry
+	if len(data) == 0 || data[0] == nil {
+		return telemetry
+	}
+
+	payloadBytes, err := json.Marshal(data[0])
+	if err != nil {
+		log.Printf("⚠️ Ignoring ping payload from client %s: failed to marshal: %v", client.ID, err)
+		return telemetry
+	}
+	if err := json.Unmarshal(payloadBytes, &telemetry); err != nil {
+		log.Printf("⚠️ Ignoring ping payload from client %s: failed to parse: %v", client.ID, err)
+		return models.AgentTelemetry{}
+	}
+	return telemetry
+}
+
 func (ws *Server) addClient(client *clients.Client) {
 	ws.mutex.Lock()
-	defer ws.mutex.Unlock()
 	ws.clients = append(ws.clients, client)
-	ws.clientsBySocketID[string(client.Socket.Id())] = client
 	log.Printf("📊 Client %s added to active connections. Total clients: %d", client.ID, len(ws.clients))
+	ws.mutex.Unlock()
+
+	// The client stays reachable from this replica even if registering it with the backplane fails
+	if err := ws.backplane.RegisterConnection(context.Background(), client); err != nil {
+		log.Printf("❌ Failed to register client %s with backplane: %v", client.ID, err)
+	}
 }
 
 func (ws *Server) removeClient(clientID string) {
+	if err := ws.backplane.UnregisterConnection(context.Background(), clientID); err != nil {
+		log.Printf("❌ Failed to unregister client %s from backplane: %v", clientID, err)
+	}
+
 	ws.mutex.Lock()
 	defer ws.mutex.Unlock()
 	for i, client := range ws.clients {
 		if client.ID == clientID {
-			// Remove from both slices and map
-			delete(ws.clientsBySocketID, string(client.Socket.Id()))
 			ws.clients = append(ws.clients[:i], ws.clients[i+1:]...)
-			log.Printf("🔌 Socket.IO client %s disconnected. Remaining clients: %d", clientID, len(ws.clients))
+			log.Printf("🔌 Client %s disconnected. Remaining clients: %d", clientID, len(ws.clients))
 			return
 		}
 	}
 	log.Printf("⚠️ Attempted to remove client %s but not found in active connections", clientID)
 }
 
+// GetClientIDs returns the IDs of clients connected to any backend replica
 func (ws *Server) GetClientIDs() []string {
+	clientIDs := ws.getLocalClientIDs()
+
+	clusterClientIDs, err := ws.backplane.GetConnectedClientIDs(context.Background())
+	if err != nil {
+		log.Printf("❌ Failed to get client IDs from backplane, falling back to local clients: %v", err)
+		return clientIDs
+	}
+
+	seen := make(map[string]bool, len(clientIDs))
+	for _, clientID := range clientIDs {
+		seen[clientID] = true
+	}
+	for _, clientID := range clusterClientIDs {
+		if !seen[clientID] {
+			seen[clientID] = true
+			clientIDs = append(clientIDs, clientID)
+		}
+	}
+
+	log.Printf("📋 Retrieved %d active client IDs across all replicas", len(clientIDs))
+	return clientIDs
+}
+
+func (ws *Server) getLocalClientIDs() []string {
 	ws.mutex.RLock()
 	defer ws.mutex.RUnlock()
 	clientIDs := make([]string, len(ws.clients))
 	for i, client := range ws.clients {
 		clientIDs[i] = client.ID
 	}
-	log.Printf("📋 Retrieved %d active client IDs", len(clientIDs))
 	return clientIDs
 }
 
@@ -201,6 +394,7 @@ func (ws *Server) getClientByID(clientID string) *clients.Client {
 	return nil
 }
 
+// GetClientByID returns the client if it is connected to this replica
 func (ws *Server) GetClientByID(clientID string) any {
 	return ws.getClientByID(clientID)
 }
@@ -209,12 +403,16 @@ func (ws *Server) SendMessage(clientID string, msg any) error {
 	log.Printf("📤 Attempting to send message to client %s", clientID)
 	client := ws.getClientByID(clientID)
 	if client == nil {
-		log.Printf("❌ Cannot send message: client %s not found", clientID)
-		return fmt.Errorf("client with ID %s not found", clientID)
+		return ws.relayToRemoteClient(clientID, models.SocketIORelayKindMessage, msg)
+	}
+
+	if messageType, ok := getMessageType(msg); ok && !client.Protocol.Supports(messageType) {
+		log.Printf("⚠️ Not sending %s message to client %s - not supported by the agent", messageType, clientID)
+		return fmt.Errorf("cannot send %s to client %s: %w", messageType, clientID, core.ErrUnsupportedMessageType)
 	}
 
 	// Send message via Socket.IO emit to specific client
-	err := client.Socket.Emit("cc_message", msg)
+	err := ws.emitMessage(client, msg)
 	if err != nil {
 		log.Printf("❌ Failed to send message to client %s: %v", clientID, err)
 		return fmt.Errorf("failed to send message to client %s: %w", clientID, err)
@@ -224,20 +422,175 @@ func (ws *Server) SendMessage(clientID string, msg any) error {
 	return nil
 }
 
+// emitMessage sends a message to a client connected to this replica and reports its acknowledgement to the
+// ack hooks - agents which do not acknowledge messages count a successful emit as the acknowledgement
+func (ws *Server) emitMessage(client *clients.Client, msg any) error {
+	msg, err := ws.invokeOutgoingMessageHooks(client, msg)
+	if err != nil {
+		return fmt.Errorf("failed to prepare message: %w", err)
+	}
+
+	messageID, hasID := getMessageID(msg)
+	if !hasID || !client.Protocol.AcknowledgesMessages() {
+		if err := client.Conn.Emit("cc_message", msg, nil); err != nil {
+			return err
+		}
+		if hasID {
+			ws.invokeAckHooks(client, messageID)
+		}
+		return nil
+	}
+
+	return client.Conn.Emit("cc_message", msg, func(err error) {
+		if err != nil {
+			log.Printf(
+				"⚠️ Failed to receive acknowledgement of message %s from client %s: %v",
+				messageID,
+				client.ID,
+				err,
+			)
+			return
+		}
+		log.Printf("📬 Client %s acknowledged message %s", client.ID, messageID)
+		ws.invokeAckHooks(client, messageID)
+	})
+}
+
+// getMessageID returns the ID of an outgoing message, either a BaseMessage or one decoded from JSON
+func getMessageID(msg any) (string, bool) {
+	switch m := msg.(type) {
+	case models.BaseMessage:
+		return m.ID, m.ID != ""
+	case *models.BaseMessage:
+		if m == nil {
+			return "", false
+		}
+		return m.ID, m.ID != ""
+	case map[string]any:
+		messageID, ok := m["id"].(string)
+		return messageID, ok && messageID != ""
+	default:
+		return "", false
+	}
+}
+
+// getMessageType returns the type of an outgoing message, either a BaseMessage or one decoded from JSON
+func getMessageType(msg any) (string, bool) {
+	switch m := msg.(type) {
+	case models.BaseMessage:
+		return m.Type, true
+	case *models.BaseMessage:
+		if m == nil {
+			return "", false
+		}
+		return m.Type, true
+	case map[string]any:
+		messageType, ok := m["type"].(string)
+		return messageType, ok
+	default:
+		return "", false
+	}
+}
+
 func (ws *Server) DisconnectClientByID(clientID string) error {
 	log.Printf("🔌 Attempting to disconnect client %s", clientID)
 	client := ws.getClientByID(clientID)
 	if client == nil {
-		log.Printf("❌ Cannot disconnect: client %s not found", clientID)
-		return fmt.Errorf("client with ID %s not found", clientID)
+		return ws.relayToRemoteClient(clientID, models.SocketIORelayKindDisconnect, nil)
 	}
 
-	// Force disconnect the Socket.IO client
-	client.Socket.Disconnect(true)
+	// Force disconnect the client
+	client.Conn.Close()
 	log.Printf("✅ Client %s disconnected successfully", clientID)
 	return nil
 }
 
+// relayToRemoteClient hands a message for a client which is not connected to this replica to the replica holding it
+func (ws *Server) relayToRemoteClient(clientID string, kind models.SocketIORelayKind, msg any) error {
+	err := ws.backplane.Relay(context.Background(), clientID, kind, msg)
+	if errors.Is(err, core.ErrNotFound) {
+		log.Printf("❌ Cannot deliver %s: client %s not found on any replica", kind, clientID)
+		return fmt.Errorf("client with ID %s not found", clientID)
+	}
+	if err != nil {
+		log.Printf("❌ Failed to relay %s to client %s: %v", kind, clientID, err)
+		return fmt.Errorf("failed to relay %s to client %s: %w", kind, clientID, err)
+	}
+
+	log.Printf("✅ Relayed %s to client %s via backplane", kind, clientID)
+	return nil
+}
+
+// deliverRelayedMessage handles a message which another replica relayed to a client connected to this replica
+// Messages which cannot be sent to the client are reported to the delivery failure hooks, the sending replica
+// already counted them as sent.
+func (ws *Server) deliverRelayedMessage(message *models.SocketIORelayedMessage) {
+	client := ws.getClientByID(message.ClientID)
+	if client == nil {
+		// The client is gone along with its organization, so a message tracked in the agent outbox is left pending
+		// there and resent to the agent's new connection
+		log.Printf("⚠️ Dropping relayed %s for client %s - no longer connected", message.Kind, message.ClientID)
+		return
+	}
+
+	switch message.Kind {
+	case models.SocketIORelayKindMessage:
+		var msg any
+		if err := json.Unmarshal(message.Payload, &msg); err != nil {
+			log.Printf("❌ Failed to unmarshal relayed message %s for client %s: %v", message.ID, client.ID, err)
+			return
+		}
+		// The sending replica does not know the agent's protocol, so support is checked on delivery
+		if messageType, ok := getMessageType(msg); ok && !client.Protocol.Supports(messageType) {
+			log.Printf(
+				"⚠️ Dropping relayed message %s for client %s - %s not supported by the agent",
+				message.ID,
+				client.ID,
+				messageType,
+			)
+			ws.reportDeliveryFailure(
+				client,
+				msg,
+				fmt.Errorf("cannot send %s to client %s: %w", messageType, client.ID, core.ErrUnsupportedMessageType),
+			)
+			return
+		}
+		if err := ws.emitMessage(client, msg); err != nil {
+			log.Printf("❌ Failed to send relayed message %s to client %s: %v", message.ID, client.ID, err)
+			ws.reportDeliveryFailure(client, msg, fmt.Errorf("failed to send message to client %s: %w", client.ID, err))
+			return
+		}
+		log.Printf("✅ Relayed message %s sent successfully to client %s", message.ID, client.ID)
+	case models.SocketIORelayKindDisconnect:
+		client.Conn.Close()
+		log.Printf("✅ Client %s disconnected on request of another replica", client.ID)
+	default:
+		log.Printf("⚠️ Unknown relayed message kind %s for client %s", message.Kind, client.ID)
+	}
+}
+
+// reportDeliveryFailure tells the delivery failure hooks why a relayed message with an ID was not sent
+func (ws *Server) reportDeliveryFailure(client *clients.Client, msg any, reason error) {
+	messageID, ok := getMessageID(msg)
+	if !ok {
+		return
+	}
+
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+	for i, hook := range ws.deliveryFailureHooks {
+		if err := hook(client, messageID, reason); err != nil {
+			log.Printf(
+				"❌ Delivery failure hook %d failed for client %s, message %s: %v",
+				i+1,
+				client.ID,
+				messageID,
+				err,
+			)
+		}
+	}
+}
+
 // Interface methods with proper type signatures
 func (ws *Server) RegisterMessageHandler(handler clients.MessageHandlerFunc) {
 	ws.mutex.Lock()
@@ -267,17 +620,48 @@ func (ws *Server) RegisterPingHook(hook clients.PingHandlerFunc) {
 	log.Printf("💓 Ping hook registered. Total ping hooks: %d", len(ws.pingHooks))
 }
 
-func (ws *Server) invokeMessageHandlers(client *clients.Client, msg any) {
+func (ws *Server) RegisterAckHook(hook clients.AckHookFunc) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	ws.ackHooks = append(ws.ackHooks, hook)
+	log.Printf("📬 Ack hook registered. Total ack hooks: %d", len(ws.ackHooks))
+}
+
+func (ws *Server) RegisterDeliveryFailureHook(hook clients.DeliveryFailureHookFunc) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	ws.deliveryFailureHooks = append(ws.deliveryFailureHooks, hook)
+	log.Printf("📭 Delivery failure hook registered. Total delivery failure hooks: %d", len(ws.deliveryFailureHooks))
+}
+
+func (ws *Server) RegisterOutgoingMessageHook(hook clients.OutgoingMessageHookFunc) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	ws.outgoingMessageHooks = append(ws.outgoingMessageHooks, hook)
+	log.Printf("📦 Outgoing message hook registered. Total outgoing message hooks: %d", len(ws.outgoingMessageHooks))
+}
+
+// invokeMessageHandlers reports whether the message should be acknowledged - it is not while a handler is still
+// processing an earlier copy of it, so the agent resends it
+func (ws *Server) invokeMessageHandlers(client *clients.Client, msg any) bool {
 	ws.mutex.RLock()
 	defer ws.mutex.RUnlock()
 	log.Printf("🔄 Invoking %d message handlers for client %s", len(ws.messageHandlers), client.ID)
+	acknowledge := true
 	for i, handler := range ws.messageHandlers {
 		log.Printf("🎯 Executing handler %d for client %s", i+1, client.ID)
-		if err := handler(client, msg); err != nil {
+		err := handler(client, msg)
+		if errors.Is(err, core.ErrMessageInProgress) {
+			log.Printf("⏳ Not acknowledging message from client %s - it is still being processed", client.ID)
+			acknowledge = false
+			continue
+		}
+		if err != nil {
 			log.Printf("❌ Message handler %d failed for client %s: %v", i+1, client.ID, err)
 		}
 	}
 	log.Printf("✅ All message handlers completed for client %s", client.ID)
+	return acknowledge
 }
 
 func (ws *Server) invokeConnectionHooks(client *clients.Client) {
@@ -306,15 +690,42 @@ func (ws *Server) invokeDisconnectionHooks(client *clients.Client) {
 	log.Printf("✅ All disconnection hooks completed for client %s", client.ID)
 }
 
-func (ws *Server) invokePingHooks(client *clients.Client) {
+func (ws *Server) invokePingHooks(client *clients.Client, telemetry models.AgentTelemetry) {
 	ws.mutex.RLock()
 	defer ws.mutex.RUnlock()
 	log.Printf("💓 Invoking %d ping hooks for client %s", len(ws.pingHooks), client.ID)
 	for i, hook := range ws.pingHooks {
 		log.Printf("🎯 Executing ping hook %d for client %s", i+1, client.ID)
-		if err := hook(client); err != nil {
+		if err := hook(client, telemetry); err != nil {
 			log.Printf("❌ Ping hook %d failed for client %s: %v", i+1, client.ID, err)
 		}
 	}
 	log.Printf("✅ All ping hooks completed for client %s", client.ID)
 }
+
+func (ws *Server) invokeAckHooks(client *clients.Client, messageID string) {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+	for i, hook := range ws.ackHooks {
+		if err := hook(client, messageID); err != nil {
+			log.Printf("❌ Ack hook %d failed for client %s, message %s: %v", i+1, client.ID, messageID, err)
+		}
+	}
+}
+
+// invokeOutgoingMessageHooks passes the message through the outgoing message hooks in order
+// The hooks may download files, so they run without holding the lock.
+func (ws *Server) invokeOutgoingMessageHooks(client *clients.Client, msg any) (any, error) {
+	ws.mutex.RLock()
+	hooks := append([]clients.OutgoingMessageHookFunc(nil), ws.outgoingMessageHooks...)
+	ws.mutex.RUnlock()
+
+	for i, hook := range hooks {
+		prepared, err := hook(client, msg)
+		if err != nil {
+			return nil, fmt.Errorf("outgoing message hook %d failed: %w", i+1, err)
+		}
+		msg = prepared
+	}
+	return msg, nil
+}
@@ -54,3 +54,15 @@ func (m *MockSocketIOClient) RegisterDisconnectionHook(hook clients.ConnectionHo
 func (m *MockSocketIOClient) RegisterPingHook(hook clients.PingHandlerFunc) {
 	m.Called(hook)
 }
+
+func (m *MockSocketIOClient) RegisterAckHook(hook clients.AckHookFunc) {
+	m.Called(hook)
+}
+
+func (m *MockSocketIOClient) RegisterDeliveryFailureHook(hook clients.DeliveryFailureHookFunc) {
+	m.Called(hook)
+}
+
+func (m *MockSocketIOClient) RegisterOutgoingMessageHook(hook clients.OutgoingMessageHookFunc) {
+	m.Called(hook)
+}
@@ -0,0 +1,318 @@
+package socketio
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"ccbackend/clients"
+	"ccbackend/core"
+	"ccbackend/models"
+)
+
+func newTestServer(backplane Backplane, localClientIDs ...string) *Server {
+	server := NewSocketIOClient(func(apiKey string) (string, error) { return "", nil }, backplane)
+	for _, clientID := range localClientIDs {
+		server.clients = append(server.clients, &clients.Client{ID: clientID})
+	}
+	return server
+}
+
+// recordingConnection records the messages emitted to a client
+type recordingConnection struct {
+	emitted []any
+}
+
+func (c *recordingConnection) Emit(event string, data any, ack func(err error)) error {
+	c.emitted = append(c.emitted, data)
+	return nil
+}
+
+func (c *recordingConnection) Close() {}
+
+func TestGetClientIDs(t *testing.T) {
+	t.Run("Merges local clients with clients connected to other replicas", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane, "cl_local1", "cl_local2")
+
+		mockBackplane.On("GetConnectedClientIDs", mock.Anything).
+			Return([]string{"cl_local1", "cl_remote1"}, nil).
+			Once()
+
+		clientIDs := server.GetClientIDs()
+
+		assert.ElementsMatch(t, []string{"cl_local1", "cl_local2", "cl_remote1"}, clientIDs)
+		mockBackplane.AssertExpectations(t)
+	})
+
+	t.Run("Falls back to local clients when backplane fails", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane, "cl_local1")
+
+		mockBackplane.On("GetConnectedClientIDs", mock.Anything).
+			Return(nil, fmt.Errorf("database unavailable")).
+			Once()
+
+		clientIDs := server.GetClientIDs()
+
+		assert.Equal(t, []string{"cl_local1"}, clientIDs)
+		mockBackplane.AssertExpectations(t)
+	})
+}
+
+func TestSendMessage(t *testing.T) {
+	msg := models.BaseMessage{ID: "msg_123", Type: models.MessageTypeCheckIdleJobs}
+
+	t.Run("Relays message to client connected to another replica", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane)
+
+		mockBackplane.On("Relay", mock.Anything, "cl_remote1", models.SocketIORelayKindMessage, msg).
+			Return(nil).
+			Once()
+
+		err := server.SendMessage("cl_remote1", msg)
+
+		require.NoError(t, err)
+		mockBackplane.AssertExpectations(t)
+	})
+
+	t.Run("Returns not found when client is not connected to any replica", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane)
+
+		mockBackplane.On("Relay", mock.Anything, "cl_missing", models.SocketIORelayKindMessage, msg).
+			Return(core.ErrNotFound).
+			Once()
+
+		err := server.SendMessage("cl_missing", msg)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "client with ID cl_missing not found")
+		mockBackplane.AssertExpectations(t)
+	})
+
+	t.Run("Does not send message type the agent does not support", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane)
+		server.clients = append(server.clients, &clients.Client{
+			ID:       "cl_local1",
+			Protocol: models.AgentProtocol{Version: 1, Capabilities: []string{models.MessageTypeStartConversation}},
+		})
+
+		err := server.SendMessage("cl_local1", msg)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, core.ErrUnsupportedMessageType)
+		mockBackplane.AssertExpectations(t)
+	})
+
+	t.Run("Emits message prepared by outgoing message hooks", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane)
+		conn := &recordingConnection{}
+		server.clients = append(server.clients, &clients.Client{
+			ID:       "cl_local1",
+			Conn:     conn,
+			Protocol: models.AgentProtocol{Version: 1, Capabilities: []string{models.MessageTypeCheckIdleJobs}},
+		})
+		prepared := models.BaseMessage{ID: msg.ID, Type: msg.Type, Payload: "prepared"}
+		server.RegisterOutgoingMessageHook(func(client *clients.Client, m any) (any, error) {
+			return prepared, nil
+		})
+
+		err := server.SendMessage("cl_local1", msg)
+
+		require.NoError(t, err)
+		assert.Equal(t, []any{prepared}, conn.emitted)
+	})
+
+	t.Run("Does not emit message the outgoing message hooks failed to prepare", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane)
+		conn := &recordingConnection{}
+		server.clients = append(server.clients, &clients.Client{
+			ID:       "cl_local1",
+			Conn:     conn,
+			Protocol: models.AgentProtocol{Version: 1, Capabilities: []string{models.MessageTypeCheckIdleJobs}},
+		})
+		server.RegisterOutgoingMessageHook(func(client *clients.Client, m any) (any, error) {
+			return nil, fmt.Errorf("download failed")
+		})
+
+		err := server.SendMessage("cl_local1", msg)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "download failed")
+		assert.Empty(t, conn.emitted)
+	})
+
+	t.Run("Returns error when relaying fails", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane)
+
+		mockBackplane.On("Relay", mock.Anything, "cl_remote1", models.SocketIORelayKindMessage, msg).
+			Return(fmt.Errorf("database unavailable")).
+			Once()
+
+		err := server.SendMessage("cl_remote1", msg)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to relay")
+		mockBackplane.AssertExpectations(t)
+	})
+}
+
+func TestDisconnectClientByID(t *testing.T) {
+	t.Run("Relays disconnect to client connected to another replica", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane)
+
+		mockBackplane.On("Relay", mock.Anything, "cl_remote1", models.SocketIORelayKindDisconnect, nil).
+			Return(nil).
+			Once()
+
+		err := server.DisconnectClientByID("cl_remote1")
+
+		require.NoError(t, err)
+		mockBackplane.AssertExpectations(t)
+	})
+}
+
+func TestDeliverRelayedMessage(t *testing.T) {
+	t.Run("Drops message for client which is no longer connected", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane)
+
+		assert.NotPanics(t, func() {
+			server.deliverRelayedMessage(&models.SocketIORelayedMessage{
+				ID:       "rm_123",
+				ClientID: "cl_gone",
+				Kind:     models.SocketIORelayKindMessage,
+				Payload:  []byte(`{"type":"check_idle_jobs"}`),
+			})
+		})
+		mockBackplane.AssertExpectations(t)
+	})
+
+	t.Run("Drops message type the agent does not support", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane)
+		server.clients = append(server.clients, &clients.Client{
+			ID:       "cl_local1",
+			Protocol: models.AgentProtocol{Version: 1, Capabilities: []string{models.MessageTypeStartConversation}},
+		})
+
+		assert.NotPanics(t, func() {
+			server.deliverRelayedMessage(&models.SocketIORelayedMessage{
+				ID:       "rm_123",
+				ClientID: "cl_local1",
+				Kind:     models.SocketIORelayKindMessage,
+				Payload:  []byte(`{"type":"check_idle_jobs_v1"}`),
+			})
+		})
+		mockBackplane.AssertExpectations(t)
+	})
+
+	t.Run("Prepares relayed message on the replica holding the client", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane)
+		conn := &recordingConnection{}
+		server.clients = append(server.clients, &clients.Client{
+			ID:       "cl_local1",
+			Conn:     conn,
+			Protocol: models.AgentProtocol{Version: 1, Capabilities: []string{models.MessageTypeStartConversation}},
+		})
+		var hookedMessages []any
+		server.RegisterOutgoingMessageHook(func(client *clients.Client, m any) (any, error) {
+			hookedMessages = append(hookedMessages, m)
+			return "prepared", nil
+		})
+
+		server.deliverRelayedMessage(&models.SocketIORelayedMessage{
+			ID:       "rm_123",
+			ClientID: "cl_local1",
+			Kind:     models.SocketIORelayKindMessage,
+			Payload:  []byte(`{"id":"msg_123","type":"start_conversation_v1"}`),
+		})
+
+		assert.Equal(t, []any{map[string]any{"id": "msg_123", "type": "start_conversation_v1"}}, hookedMessages)
+		assert.Equal(t, []any{"prepared"}, conn.emitted)
+	})
+
+	t.Run("Reports dropped message to delivery failure hooks", func(t *testing.T) {
+		mockBackplane := &MockBackplane{}
+		server := newTestServer(mockBackplane)
+		server.clients = append(server.clients, &clients.Client{
+			ID:       "cl_local1",
+			Protocol: models.AgentProtocol{Version: 1, Capabilities: []string{models.MessageTypeStartConversation}},
+		})
+
+		var failedMessageIDs []string
+		var failureReason error
+		server.RegisterDeliveryFailureHook(func(client *clients.Client, messageID string, reason error) error {
+			failedMessageIDs = append(failedMessageIDs, messageID)
+			failureReason = reason
+			return nil
+		})
+
+		server.deliverRelayedMessage(&models.SocketIORelayedMessage{
+			ID:       "rm_123",
+			ClientID: "cl_local1",
+			Kind:     models.SocketIORelayKindMessage,
+			Payload:  []byte(`{"id":"msg_123","type":"check_idle_jobs_v1"}`),
+		})
+
+		assert.Equal(t, []string{"msg_123"}, failedMessageIDs)
+		assert.ErrorIs(t, failureReason, core.ErrUnsupportedMessageType)
+	})
+}
+
+func TestGetMessageType(t *testing.T) {
+	t.Run("Reads type of base message", func(t *testing.T) {
+		messageType, ok := getMessageType(models.BaseMessage{Type: models.MessageTypeUserMessage})
+
+		assert.True(t, ok)
+		assert.Equal(t, models.MessageTypeUserMessage, messageType)
+	})
+
+	t.Run("Reads type of relayed message", func(t *testing.T) {
+		messageType, ok := getMessageType(map[string]any{"type": models.MessageTypeCheckIdleJobs})
+
+		assert.True(t, ok)
+		assert.Equal(t, models.MessageTypeCheckIdleJobs, messageType)
+	})
+
+	t.Run("Unknown message shape has no type", func(t *testing.T) {
+		_, ok := getMessageType("hello")
+
+		assert.False(t, ok)
+	})
+}
+
+func TestParsePingTelemetry(t *testing.T) {
+	client := &clients.Client{ID: "cl_123"}
+
+	t.Run("Parses telemetry from ping payload", func(t *testing.T) {
+		telemetry := parsePingTelemetry(client, []any{
+			map[string]any{"rtt_ms": float64(42), "version": "1.2.3", "os": "linux", "hostname": "build-1"},
+		})
+
+		require.NotNil(t, telemetry.RoundTripTimeMs)
+		assert.Equal(t, int64(42), *telemetry.RoundTripTimeMs)
+		assert.Equal(t, "1.2.3", telemetry.Version)
+		assert.Equal(t, "linux", telemetry.OS)
+		assert.Equal(t, "build-1", telemetry.Hostname)
+	})
+
+	t.Run("Ping without payload has no telemetry", func(t *testing.T) {
+		assert.True(t, parsePingTelemetry(client, nil).IsEmpty())
+	})
+
+	t.Run("Malformed payload is ignored", func(t *testing.T) {
+		assert.True(t, parsePingTelemetry(client, []any{map[string]any{"rtt_ms": "fast"}}).IsEmpty())
+	})
+}
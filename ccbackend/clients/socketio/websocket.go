@@ -0,0 +1,222 @@
+package socketio
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"ccbackend/clients"
+	"ccbackend/core"
+	"ccbackend/models"
+)
+
+// Events of the frames exchanged with agents over plain WebSocket, named like their Socket.IO counterparts
+const (
+	webSocketEventMessage = "cc_message"
+	webSocketEventPing    = "ping"
+	webSocketEventAck     = "ack"
+)
+
+// How long writing a frame to an agent connected over plain WebSocket may take
+const webSocketWriteTimeout = 10 * time.Second
+
+// Agents authenticate with the X-CCAGENT-API-KEY header rather than cookies, so any origin is accepted
+var webSocketUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// webSocketFrame is a JSON text frame exchanged with an agent connected over plain WebSocket
+// Frames carry the same events and BaseMessage envelope as Socket.IO, e.g.
+// {"event": "cc_message", "data": {"id": "...", "type": "...", "payload": {...}}, "ack_id": "..."}
+type webSocketFrame struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	// AckID asks the receiver to acknowledge the frame with an "ack" frame carrying the same AckID -
+	// the equivalent of a Socket.IO ack callback
+	AckID string `json:"ack_id,omitempty"`
+}
+
+// webSocketConnection is the connection of an agent connected over plain WebSocket
+type webSocketConnection struct {
+	conn *websocket.Conn
+	// writeMutex serializes writes - a websocket connection supports only one concurrent writer
+	writeMutex sync.Mutex
+
+	acksMutex   sync.Mutex
+	pendingAcks map[string]func(err error)
+}
+
+func newWebSocketConnection(conn *websocket.Conn) *webSocketConnection {
+	return &webSocketConnection{
+		conn:        conn,
+		pendingAcks: make(map[string]func(err error)),
+	}
+}
+
+func (c *webSocketConnection) Emit(event string, data any, ack func(err error)) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	frame := webSocketFrame{Event: event, Data: dataBytes}
+	if ack != nil {
+		frame.AckID = core.NewID("ack")
+		c.acksMutex.Lock()
+		c.pendingAcks[frame.AckID] = ack
+		c.acksMutex.Unlock()
+	}
+
+	if err := c.writeFrame(frame); err != nil {
+		if ack != nil {
+			c.acksMutex.Lock()
+			delete(c.pendingAcks, frame.AckID)
+			c.acksMutex.Unlock()
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *webSocketConnection) Close() {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	_ = c.conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(webSocketWriteTimeout))
+	_ = c.conn.Close()
+}
+
+func (c *webSocketConnection) writeFrame(frame webSocketFrame) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	if err := c.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout)); err != nil {
+		return err
+	}
+	return c.conn.WriteJSON(frame)
+}
+
+// acknowledge answers a frame which asked for an acknowledgement
+func (c *webSocketConnection) acknowledge(ackID string) error {
+	if ackID == "" {
+		return nil
+	}
+	return c.writeFrame(webSocketFrame{Event: webSocketEventAck, AckID: ackID})
+}
+
+// acknowledged calls the ack callback of a frame the agent acknowledged
+// Unknown ack IDs are ignored, e.g. a duplicate acknowledgement.
+func (c *webSocketConnection) acknowledged(ackID string) {
+	c.acksMutex.Lock()
+	ack, ok := c.pendingAcks[ackID]
+	delete(c.pendingAcks, ackID)
+	c.acksMutex.Unlock()
+
+	if ok {
+		ack(nil)
+	}
+}
+
+// handleWebSocketConnection accepts an agent over plain WebSocket and reads its frames until it disconnects
+// The handshake carries the same X-CCAGENT-* headers as Socket.IO. Rejected handshakes are answered with
+// 401 Unauthorized, or 400 Bad Request carrying the reason when the agent's protocol is incompatible.
+func (ws *Server) handleWebSocketConnection(w http.ResponseWriter, r *http.Request) {
+	log.Printf("🔗 New WebSocket connection attempt from %s", r.RemoteAddr)
+
+	client, err := ws.newClientFromHandshake(r.Header)
+	if err != nil {
+		log.Printf("❌ Rejecting WebSocket connection: %v", err)
+		if errors.Is(err, errIncompatibleProtocol) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := webSocketUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader already answered the request with an error
+		log.Printf("❌ Failed to upgrade WebSocket connection for client %s: %v", client.ID, err)
+		return
+	}
+	// Agents send artifacts inline, so messages can be larger than usual
+	conn.SetReadLimit(models.MaxAgentMessageSize)
+
+	wsConn := newWebSocketConnection(conn)
+	client.Conn = wsConn
+	log.Printf(
+		"✅ WebSocket client connected with ID: %s, protocol version: %d",
+		client.ID,
+		client.Protocol.Version,
+	)
+	ws.connectClient(client)
+
+	defer func() {
+		log.Printf("🔌 WebSocket connection closed for client %s", client.ID)
+		_ = conn.Close()
+		ws.disconnectClient(client)
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("⚠️ WebSocket connection of client %s closed unexpectedly: %v", client.ID, err)
+			}
+			return
+		}
+
+		var frame webSocketFrame
+		if err := json.Unmarshal(data, &frame); err != nil {
+			log.Printf("❌ Ignoring malformed WebSocket frame from client %s: %v", client.ID, err)
+			continue
+		}
+		ws.handleWebSocketFrame(client, wsConn, frame)
+	}
+}
+
+// handleWebSocketFrame dispatches a frame from an agent the same way as the corresponding Socket.IO event
+func (ws *Server) handleWebSocketFrame(client *clients.Client, conn *webSocketConnection, frame webSocketFrame) {
+	switch frame.Event {
+	case webSocketEventMessage:
+		var msg any
+		if err := json.Unmarshal(frame.Data, &msg); err != nil || msg == nil {
+			log.Printf("❌ No message data received for client %s", client.ID)
+			return
+		}
+
+		// The message is acknowledged once it was handled, so the agent stops resending it
+		log.Printf("📥 Raw message received from client %s", client.ID)
+		if !ws.invokeMessageHandlers(client, msg) {
+			return
+		}
+		if err := conn.acknowledge(frame.AckID); err != nil {
+			log.Printf("⚠️ Failed to acknowledge message from client %s: %v", client.ID, err)
+		}
+
+	case webSocketEventPing:
+		// The ack is the pong, which the agent times to report the round-trip time in its next ping
+		log.Printf("💓 Received ping from client %s", client.ID)
+		if err := conn.acknowledge(frame.AckID); err != nil {
+			log.Printf("⚠️ Failed to acknowledge ping from client %s: %v", client.ID, err)
+		}
+
+		var data []any
+		var payload any
+		if len(frame.Data) > 0 && json.Unmarshal(frame.Data, &payload) == nil {
+			data = []any{payload}
+		}
+		ws.invokePingHooks(client, parsePingTelemetry(client, data))
+
+	case webSocketEventAck:
+		conn.acknowledged(frame.AckID)
+
+	default:
+		log.Printf("⚠️ Ignoring unknown WebSocket event %q from client %s", frame.Event, client.ID)
+	}
+}
@@ -0,0 +1,171 @@
+package socketio
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"ccbackend/clients"
+	"ccbackend/core"
+	"ccbackend/models"
+)
+
+func newTestWebSocketServer(t *testing.T) (*Server, string) {
+	mockBackplane := &MockBackplane{}
+	mockBackplane.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	mockBackplane.On("UnregisterConnection", mock.Anything, mock.Anything).Return(nil)
+
+	server := NewSocketIOClient(func(apiKey string) (string, error) {
+		if apiKey != "valid-key" {
+			return "", fmt.Errorf("unknown API key")
+		}
+		return core.NewID("org"), nil
+	}, mockBackplane)
+
+	router := mux.NewRouter()
+	server.RegisterWithRouter(router)
+	httpServer := httptest.NewServer(router)
+	t.Cleanup(httpServer.Close)
+
+	return server, "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws"
+}
+
+const testCapabilities = "start_conversation_v1,user_message_v1,check_idle_jobs_v1"
+
+func agentHeaders(apiKey, capabilities string) http.Header {
+	return http.Header{
+		"X-CCAGENT-API-KEY":          []string{apiKey},
+		"X-CCAGENT-ID":               []string{core.NewID("ccaid")},
+		"X-CCAGENT-PROTOCOL-VERSION": []string{"2"},
+		"X-CCAGENT-CAPABILITIES":     []string{capabilities},
+	}
+}
+
+func readFrame(t *testing.T, conn *websocket.Conn) webSocketFrame {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var frame webSocketFrame
+	require.NoError(t, conn.ReadJSON(&frame))
+	return frame
+}
+
+func TestWebSocketTransport(t *testing.T) {
+	t.Run("Rejects handshake with invalid API key", func(t *testing.T) {
+		_, url := newTestWebSocketServer(t)
+
+		_, resp, err := websocket.DefaultDialer.Dial(url, agentHeaders("wrong-key", testCapabilities))
+
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Rejects handshake with incompatible protocol", func(t *testing.T) {
+		_, url := newTestWebSocketServer(t)
+
+		_, resp, err := websocket.DefaultDialer.Dial(url, agentHeaders("valid-key", "assistant_message_v1"))
+
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Connects agent and exchanges messages through the shared hooks", func(t *testing.T) {
+		server, url := newTestWebSocketServer(t)
+		connected := make(chan *clients.Client, 1)
+		received := make(chan any, 1)
+		acked := make(chan string, 1)
+		disconnected := make(chan string, 1)
+		server.RegisterConnectionHook(func(client *clients.Client) error {
+			connected <- client
+			return nil
+		})
+		server.RegisterMessageHandler(func(client *clients.Client, msg any) error {
+			received <- msg
+			return nil
+		})
+		server.RegisterAckHook(func(client *clients.Client, messageID string) error {
+			acked <- messageID
+			return nil
+		})
+		server.RegisterDisconnectionHook(func(client *clients.Client) error {
+			disconnected <- client.ID
+			return nil
+		})
+
+		conn, _, err := websocket.DefaultDialer.Dial(url, agentHeaders("valid-key", testCapabilities))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// The negotiated protocol is confirmed and the client is registered like a Socket.IO client
+		frame := readFrame(t, conn)
+		assert.Equal(t, "protocol_negotiated", frame.Event)
+		client := <-connected
+		assert.Equal(t, 2, client.Protocol.Version)
+		assert.Contains(t, server.getLocalClientIDs(), client.ID)
+
+		// Messages from the agent are handled and acknowledged
+		require.NoError(t, conn.WriteJSON(webSocketFrame{
+			Event: "cc_message",
+			Data:  json.RawMessage(`{"id":"msg_1","type":"assistant_message_v1","payload":{}}`),
+			AckID: "ack_1",
+		}))
+		msg := <-received
+		assert.Equal(t, "msg_1", msg.(map[string]any)["id"])
+		frame = readFrame(t, conn)
+		assert.Equal(t, webSocketFrame{Event: "ack", AckID: "ack_1"}, frame)
+
+		// Messages to the agent ask for an acknowledgement, which is reported to the ack hooks
+		err = server.SendMessage(client.ID, models.BaseMessage{ID: "msg_2", Type: models.MessageTypeCheckIdleJobs})
+		require.NoError(t, err)
+		frame = readFrame(t, conn)
+		assert.Equal(t, "cc_message", frame.Event)
+		require.NotEmpty(t, frame.AckID)
+		require.NoError(t, conn.WriteJSON(webSocketFrame{Event: "ack", AckID: frame.AckID}))
+		assert.Equal(t, "msg_2", <-acked)
+
+		// Closing the connection runs the disconnection hooks
+		require.NoError(t, conn.Close())
+		assert.Equal(t, client.ID, <-disconnected)
+	})
+
+	t.Run("Leaves message still being processed unacknowledged", func(t *testing.T) {
+		server, url := newTestWebSocketServer(t)
+		server.RegisterMessageHandler(func(client *clients.Client, msg any) error {
+			if msg.(map[string]any)["id"] == "msg_1" {
+				return core.ErrMessageInProgress
+			}
+			return nil
+		})
+
+		conn, _, err := websocket.DefaultDialer.Dial(url, agentHeaders("valid-key", testCapabilities))
+		require.NoError(t, err)
+		defer conn.Close()
+		frame := readFrame(t, conn)
+		assert.Equal(t, "protocol_negotiated", frame.Event)
+
+		require.NoError(t, conn.WriteJSON(webSocketFrame{
+			Event: "cc_message",
+			Data:  json.RawMessage(`{"id":"msg_1","type":"assistant_message_v1","payload":{}}`),
+			AckID: "ack_1",
+		}))
+		require.NoError(t, conn.WriteJSON(webSocketFrame{
+			Event: "cc_message",
+			Data:  json.RawMessage(`{"id":"msg_2","type":"assistant_message_v1","payload":{}}`),
+			AckID: "ack_2",
+		}))
+
+		// Frames are handled in order, so the first acknowledgement is the one of the second message
+		frame = readFrame(t, conn)
+		assert.Equal(t, webSocketFrame{Event: "ack", AckID: "ack_2"}, frame)
+	})
+}
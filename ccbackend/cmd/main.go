@@ -26,6 +26,7 @@ import (
 	"ccbackend/db"
 	"ccbackend/handlers"
 	"ccbackend/middleware"
+	"ccbackend/models"
 	"ccbackend/salesnotif"
 	"ccbackend/services"
 	agentsservice "ccbackend/services/agents"
@@ -35,6 +36,7 @@ import (
 	discordmessages "ccbackend/services/discordmessages"
 	githubintegrations "ccbackend/services/github_integrations"
 	jobs "ccbackend/services/jobs"
+	"ccbackend/services/locks"
 	organizations "ccbackend/services/organizations"
 	settingsservice "ccbackend/services/settings"
 	slackintegrations "ccbackend/services/slack_integrations"
@@ -49,6 +51,14 @@ import (
 	"ccbackend/usecases/slack"
 )
 
+const (
+	// Advisory locks keeping the background tasks to one replica at a time
+	backgroundTasksLock    = "background_tasks"
+	disconnectedAgentsLock = "deregister_disconnected_agents"
+	// How often agents past their reconnect grace period are looked for
+	disconnectedAgentsCheckInterval = 10 * time.Second
+)
+
 func main() {
 	if err := run(); err != nil {
 		log.Printf("❌ Fatal error: %v", err)
@@ -85,6 +95,7 @@ func run() error {
 	// Initialize repositories with shared connection
 	agentsRepo := db.NewPostgresAgentsRepository(dbConn, cfg.DatabaseSchema)
 	jobsRepo := db.NewPostgresJobsRepository(dbConn, cfg.DatabaseSchema)
+	jobTranscriptsRepo := db.NewPostgresJobTranscriptsRepository(dbConn, cfg.DatabaseSchema)
 	processedSlackMessagesRepo := db.NewPostgresProcessedSlackMessagesRepository(dbConn, cfg.DatabaseSchema)
 	processedDiscordMessagesRepo := db.NewPostgresProcessedDiscordMessagesRepository(dbConn, cfg.DatabaseSchema)
 	usersRepo := db.NewPostgresUsersRepository(dbConn, cfg.DatabaseSchema)
@@ -96,6 +107,10 @@ func run() error {
 	ccAgentContainerIntegrationsRepo := db.NewPostgresCCAgentContainerIntegrationsRepository(dbConn, cfg.DatabaseSchema)
 	settingsRepo := db.NewPostgresSettingsRepository(dbConn, cfg.DatabaseSchema)
 	connectedChannelsRepo := db.NewPostgresConnectedChannelsRepository(dbConn, cfg.DatabaseSchema)
+	socketIOBackplaneRepo := db.NewPostgresSocketIOBackplaneRepository(dbConn, cfg.DatabaseSchema)
+	agentOutboxRepo := db.NewPostgresAgentOutboxRepository(dbConn, cfg.DatabaseSchema)
+	agentInboxRepo := db.NewPostgresAgentInboxRepository(dbConn, cfg.DatabaseSchema)
+	advisoryLocksRepo := db.NewPostgresAdvisoryLocksRepository(dbConn, cfg.DatabaseSchema)
 
 	// Initialize transaction manager
 	txManager := txmanager.NewTransactionManager(dbConn)
@@ -103,10 +118,17 @@ func run() error {
 	// Core services (always needed)
 	slackMessagesService := slackmessages.NewSlackMessagesService(processedSlackMessagesRepo)
 	discordMessagesService := discordmessages.NewDiscordMessagesService(processedDiscordMessagesRepo)
-	jobsService := jobs.NewJobsService(jobsRepo, slackMessagesService, discordMessagesService, txManager)
+	jobsService := jobs.NewJobsService(
+		jobsRepo,
+		jobTranscriptsRepo,
+		slackMessagesService,
+		discordMessagesService,
+		txManager,
+	)
 	organizationsService := organizations.NewOrganizationsService(organizationsRepo)
 	usersService := users.NewUsersService(usersRepo, organizationsService, txManager)
 	settingsService := settingsservice.NewSettingsService(settingsRepo)
+	locksService := locks.NewLocksService(advisoryLocksRepo)
 
 	// Anthropic service (always needed for ccagent container service)
 	anthropicClient := anthropic.NewAnthropicClient()
@@ -216,10 +238,22 @@ func run() error {
 		return maybeOrg.MustGet().ID, nil
 	}
 
-	wsClient := socketioclient.NewSocketIOClient(apiKeyValidator)
+	// Share Socket.IO connections between backend replicas so any replica can reach any agent
+	socketIOBackplane := socketioclient.NewPostgresBackplane(socketIOBackplaneRepo, cfg.DatabaseURL, cfg.DatabaseSchema)
+	wsClient := socketioclient.NewSocketIOClient(apiKeyValidator, socketIOBackplane)
+	if err := wsClient.Start(); err != nil {
+		return fmt.Errorf("failed to start Socket.IO server: %w", err)
+	}
+	defer wsClient.Stop()
 
 	// Create agents service after wsClient is available
-	agentsService := agentsservice.NewAgentsService(agentsRepo, wsClient)
+	agentsService := agentsservice.NewAgentsService(
+		agentsRepo,
+		agentOutboxRepo,
+		agentInboxRepo,
+		wsClient,
+		cfg.AgentMessageDedupeWindow,
+	)
 
 	// Create connected channels service after agentsService is available
 	connectedChannelsService := connectedchannels.NewConnectedChannelsService(connectedChannelsRepo, agentsService)
@@ -235,6 +269,7 @@ func run() error {
 			jobsService,
 			slackMessagesService,
 			slackIntegrationsService,
+			connectedChannelsService,
 			txManager,
 			agentsUseCase,
 			slackclient.NewSlackClient,
@@ -252,6 +287,7 @@ func run() error {
 			jobsService,
 			discordMessagesService,
 			discordIntegrationsService,
+			connectedChannelsService,
 			txManager,
 			agentsUseCase,
 		)
@@ -266,6 +302,7 @@ func run() error {
 		jobsService,
 		slackIntegrationsService,
 		organizationsService,
+		settingsService,
 		slackUseCase,
 		discordUseCaseInstance,
 	)
@@ -308,7 +345,9 @@ func run() error {
 		ccAgentContainerService,
 		organizationsService,
 		agentsService,
+		connectedChannelsService,
 		settingsService,
+		jobsService,
 		txManager,
 	)
 	dashboardHTTPHandler := handlers.NewDashboardHTTPHandler(dashboardHandler)
@@ -369,35 +408,99 @@ func run() error {
 	deregisterAgent := func(client *clients.Client) error {
 		return coreUseCase.DeregisterAgent(context.Background(), client)
 	}
-	processPing := func(client *clients.Client) error {
-		return coreUseCase.ProcessPing(context.Background(), client)
+	// Delay deregistration so an agent reconnecting after a network blip keeps its job assignments.
+	// The disconnect is recorded in the database and a background task on whichever replica holds the lock
+	// deregisters the agent once the grace period expires. Reconnecting to any replica clears the disconnect.
+	deregisterAgentAfterGracePeriod := func(client *clients.Client) error {
+		if cfg.AgentReconnectGracePeriod <= 0 {
+			return deregisterAgent(client)
+		}
+
+		log.Printf(
+			"⏳ Client %s disconnected, deregistering agent in %s unless it reconnects",
+			client.ID,
+			cfg.AgentReconnectGracePeriod,
+		)
+		return coreUseCase.MarkAgentDisconnected(context.Background(), client)
+	}
+	processPing := func(client *clients.Client, telemetry models.AgentTelemetry) error {
+		return coreUseCase.ProcessPing(context.Background(), client, telemetry)
+	}
+	processMessageAck := func(client *clients.Client, messageID string) error {
+		return coreUseCase.ProcessMessageAck(context.Background(), client, messageID)
+	}
+	processMessageDeliveryFailure := func(client *clients.Client, messageID string, reason error) error {
+		return coreUseCase.ProcessMessageDeliveryFailure(context.Background(), client, messageID, reason)
+	}
+	prepareAgentMessage := func(client *clients.Client, msg any) (any, error) {
+		return coreUseCase.PrepareAgentMessage(context.Background(), client, msg)
 	}
 
 	// Register WebSocket hooks for agent lifecycle
 	wsClient.RegisterConnectionHook(alertMiddleware.WrapConnectionHook(registerAgent))
-	wsClient.RegisterDisconnectionHook(alertMiddleware.WrapConnectionHook(deregisterAgent))
-	wsClient.RegisterPingHook(alertMiddleware.WrapConnectionHook(processPing))
-
-	// Register WebSocket message handler (middleware consumes errors internally)
-	wrappedHandler := alertMiddleware.WrapMessageHandler(wsHandler.HandleMessage)
-	messageHandlerAdapter := func(client *clients.Client, msg any) error {
-		wrappedHandler(client, msg)
-		return nil // Middleware handles errors internally
-	}
-	wsClient.RegisterMessageHandler(messageHandlerAdapter)
+	wsClient.RegisterDisconnectionHook(alertMiddleware.WrapConnectionHook(deregisterAgentAfterGracePeriod))
+	wsClient.RegisterPingHook(alertMiddleware.WrapPingHook(processPing))
+	wsClient.RegisterAckHook(alertMiddleware.WrapAckHook(processMessageAck))
+	wsClient.RegisterDeliveryFailureHook(alertMiddleware.WrapDeliveryFailureHook(processMessageDeliveryFailure))
+	wsClient.RegisterOutgoingMessageHook(alertMiddleware.WrapOutgoingMessageHook(prepareAgentMessage))
+
+	// Register WebSocket message handler (middleware consumes errors other than messages still in progress)
+	wsClient.RegisterMessageHandler(alertMiddleware.WrapMessageHandler(wsHandler.HandleMessage))
+
+	// Deregister agents which did not reconnect within the grace period. Only one replica runs it at a time.
+	disconnectedAgentsTicker := time.NewTicker(disconnectedAgentsCheckInterval)
+	go func() {
+		for range disconnectedAgentsTicker.C {
+			_ = alertMiddleware.WrapBackgroundTask("DeregisterDisconnectedAgents", func() error {
+				_, err := locksService.RunExclusively(
+					context.Background(),
+					disconnectedAgentsLock,
+					func(ctx context.Context) error {
+						return coreUseCase.DeregisterDisconnectedAgents(ctx, cfg.AgentReconnectGracePeriod)
+					},
+				)
+				return err
+			})()
+		}
+	}()
+	defer disconnectedAgentsTicker.Stop()
 
-	// Start periodic broadcast of CheckIdleJobs, cleanup of inactive agents, and processing of queued jobs
+	// Start periodic broadcast of CheckIdleJobs, closing of idle jobs, cleanup of inactive agents, recovery of stuck
+	// messages, processing of queued jobs, redelivery of unacknowledged agent messages and cleanup of expired inbound
+	// agent message IDs. Only one replica runs them at a time, the others skip the tick.
 	cleanupTicker := time.NewTicker(1 * time.Minute)
+	runBackgroundTasks := func(ctx context.Context) error {
+		_ = alertMiddleware.WrapBackgroundTask("RetryUndeliveredAgentMessages", func() error {
+			return coreUseCase.RetryUndeliveredAgentMessages(ctx)
+		})()
+		_ = alertMiddleware.WrapBackgroundTask("ProcessStuckMessages", func() error {
+			return coreUseCase.ProcessStuckMessages(ctx)
+		})()
+		_ = alertMiddleware.WrapBackgroundTask("ProcessQueuedJobs", func() error {
+			return coreUseCase.ProcessQueuedJobs(ctx)
+		})()
+		_ = alertMiddleware.WrapBackgroundTask("BroadcastCheckIdleJobs", func() error {
+			return coreUseCase.BroadcastCheckIdleJobs(ctx)
+		})()
+		_ = alertMiddleware.WrapBackgroundTask("CloseIdleJobs", func() error {
+			return coreUseCase.CloseIdleJobs(ctx)
+		})()
+		_ = alertMiddleware.WrapBackgroundTask("CleanupInactiveAgents", func() error {
+			return coreUseCase.CleanupInactiveAgents(ctx)
+		})()
+		_ = alertMiddleware.WrapBackgroundTask("CleanupExpiredAgentInboundMessages", func() error {
+			return coreUseCase.CleanupExpiredAgentInboundMessages(ctx)
+		})()
+		_ = alertMiddleware.WrapBackgroundTask("CleanupFinishedAgentMessages", func() error {
+			return coreUseCase.CleanupFinishedAgentMessages(ctx)
+		})()
+		return nil
+	}
 	go func() {
 		for range cleanupTicker.C {
-			_ = alertMiddleware.WrapBackgroundTask("ProcessQueuedJobs", func() error {
-				return coreUseCase.ProcessQueuedJobs(context.Background())
-			})()
-			_ = alertMiddleware.WrapBackgroundTask("BroadcastCheckIdleJobs", func() error {
-				return coreUseCase.BroadcastCheckIdleJobs(context.Background())
-			})()
-			_ = alertMiddleware.WrapBackgroundTask("CleanupInactiveAgents", func() error {
-				return coreUseCase.CleanupInactiveAgents(context.Background())
+			_ = alertMiddleware.WrapBackgroundTask("BackgroundTasks", func() error {
+				_, err := locksService.RunExclusively(context.Background(), backgroundTasksLock, runBackgroundTasks)
+				return err
 			})()
 		}
 	}()
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -82,6 +84,11 @@ type AppConfig struct {
 	ServerLogsURL      string
 	UseStrictConfig    bool // If true, error when any integration is not fully configured
 
+	// How long to keep a disconnected agent's job assignments around in case it reconnects
+	AgentReconnectGracePeriod time.Duration // Optional with default 30s
+	// How long the IDs of messages received from agents are remembered to drop resent duplicates
+	AgentMessageDedupeWindow time.Duration // Optional with default 1h
+
 	// Integration configurations (grouped)
 	SlackConfig   SlackConfig
 	DiscordConfig DiscordConfig
@@ -106,6 +113,16 @@ func LoadConfig() (*AppConfig, error) {
 		return nil, err
 	}
 
+	reconnectGraceSeconds, err := strconv.Atoi(getEnvWithDefault("AGENT_RECONNECT_GRACE_PERIOD_SECONDS", "30"))
+	if err != nil || reconnectGraceSeconds < 0 {
+		return nil, fmt.Errorf("AGENT_RECONNECT_GRACE_PERIOD_SECONDS must be a non-negative integer")
+	}
+
+	dedupeWindowSeconds, err := strconv.Atoi(getEnvWithDefault("AGENT_MESSAGE_DEDUPE_WINDOW_SECONDS", "3600"))
+	if err != nil || dedupeWindowSeconds <= 0 {
+		return nil, fmt.Errorf("AGENT_MESSAGE_DEDUPE_WINDOW_SECONDS must be a positive integer")
+	}
+
 	config := &AppConfig{
 		// Core configuration
 		DatabaseURL:        databaseURL,
@@ -116,6 +133,9 @@ func LoadConfig() (*AppConfig, error) {
 		ServerLogsURL:      getEnvWithDefault("SERVER_LOGS_URL", ""),
 		UseStrictConfig:    getEnvWithDefault("USE_STRICT_CONFIG", "true") == "true",
 
+		AgentReconnectGracePeriod: time.Duration(reconnectGraceSeconds) * time.Second,
+		AgentMessageDedupeWindow:  time.Duration(dedupeWindowSeconds) * time.Second,
+
 		// Slack configuration (optional)
 		SlackConfig: SlackConfig{
 			SigningSecret:   os.Getenv("SLACK_SIGNING_SECRET"),
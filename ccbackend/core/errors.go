@@ -8,6 +8,25 @@ import (
 // ErrNotFound is a sentinel error for "not found" cases
 var ErrNotFound = errors.New("not found")
 
+// ErrUnsupportedMessageType is returned when sending a message type the agent did not declare in its handshake
+var ErrUnsupportedMessageType = errors.New("message type not supported by agent")
+
+// ErrJobNotFound is returned when an agent's message is about a job which no longer exists
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrJobNotAssigned is returned when an agent's message is about a job which is not assigned to the agent
+var ErrJobNotAssigned = errors.New("job is not assigned to agent")
+
+// ErrInvalidPayload is returned when an agent's message payload is malformed or missing required fields
+var ErrInvalidPayload = errors.New("invalid payload")
+
+// ErrMessageInProgress is returned for a message an agent resent while the original is still being processed
+// The copy is left unacknowledged, so the agent resends it until the original is done.
+var ErrMessageInProgress = errors.New("message is still being processed")
+
+// ErrRedeployInProgress is returned when starting a redeploy of a container which is already being redeployed
+var ErrRedeployInProgress = errors.New("redeploy already in progress")
+
 // IsNotFoundError checks if an error is a "not found" error
 // This function handles both the new ErrNotFound sentinel error and legacy string-based errors
 func IsNotFoundError(err error) bool {
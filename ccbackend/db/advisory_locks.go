@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresAdvisoryLocksRepository runs work under Postgres session-level advisory locks, which are shared by every
+// backend replica using the database
+type PostgresAdvisoryLocksRepository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+func NewPostgresAdvisoryLocksRepository(db *sqlx.DB, schema string) *PostgresAdvisoryLocksRepository {
+	return &PostgresAdvisoryLocksRepository{db: db, schema: schema}
+}
+
+// TryWithLock runs fn if no other session holds the named lock, holding it on a dedicated connection until fn
+// returns. Returns false without running fn if the lock is taken.
+// The lock is released by Postgres if the replica dies while holding it, as its connection is closed.
+func (r *PostgresAdvisoryLocksRepository) TryWithLock(
+	ctx context.Context,
+	name string,
+	fn func(ctx context.Context) error,
+) (bool, error) {
+	conn, err := r.db.Connx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get connection for advisory lock %s: %w", name, err)
+	}
+	defer conn.Close()
+
+	// Locks are namespaced by schema so test runs do not contend with a local backend
+	key := r.schema + ":" + name
+
+	var acquired bool
+	if err := conn.QueryRowxContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", key).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("failed to acquire advisory lock %s: %w", name, err)
+	}
+	if !acquired {
+		return false, nil
+	}
+
+	fnErr := fn(ctx)
+
+	if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", key); err != nil {
+		// Discard the connection instead of returning it to the pool so its session, and the lock, end
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		return true, fmt.Errorf("failed to release advisory lock %s: %w", name, err)
+	}
+
+	return true, fnErr
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
+
+	"ccbackend/models"
+)
+
+type PostgresAgentInboxRepository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+func NewPostgresAgentInboxRepository(db *sqlx.DB, schema string) *PostgresAgentInboxRepository {
+	return &PostgresAgentInboxRepository{db: db, schema: schema}
+}
+
+// ClaimInboxMessage records a message received from an agent as being processed and reports whether it should be
+// processed. Returns false if the agent already sent a message with the same ID within the dedupe window, unless
+// that message was still being processed after the processing timeout, e.g. because its replica stopped.
+func (r *PostgresAgentInboxRepository) ClaimInboxMessage(
+	ctx context.Context,
+	orgID models.OrgID,
+	agentID string,
+	messageID string,
+	messageType string,
+	window time.Duration,
+	processingTimeout time.Duration,
+) (bool, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.agent_inbox_messages
+			(organization_id, agent_id, message_id, message_type, status, received_at)
+		VALUES ($1, $2, $3, $4, 'PROCESSING', NOW())
+		ON CONFLICT (organization_id, agent_id, message_id) DO UPDATE
+		SET message_type = EXCLUDED.message_type, status = 'PROCESSING', received_at = NOW()
+		WHERE agent_inbox_messages.received_at < NOW() - INTERVAL '%d seconds'
+			OR (agent_inbox_messages.status = 'PROCESSING'
+				AND agent_inbox_messages.received_at < NOW() - INTERVAL '%d seconds')`,
+		r.schema, int(window.Seconds()), int(processingTimeout.Seconds()))
+
+	result, err := r.db.ExecContext(ctx, query, orgID, agentID, messageID, messageType)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim agent inbox message: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetInboxMessageStatus returns whether a message received from an agent is still being processed
+// Returns None if the message is not in the inbox, e.g. because its processing failed.
+func (r *PostgresAgentInboxRepository) GetInboxMessageStatus(
+	ctx context.Context,
+	orgID models.OrgID,
+	agentID string,
+	messageID string,
+) (mo.Option[models.AgentInboxMessageStatus], error) {
+	query := fmt.Sprintf(`
+		SELECT status FROM %s.agent_inbox_messages
+		WHERE organization_id = $1 AND agent_id = $2 AND message_id = $3`, r.schema)
+
+	var status models.AgentInboxMessageStatus
+	err := r.db.GetContext(ctx, &status, query, orgID, agentID, messageID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return mo.None[models.AgentInboxMessageStatus](), nil
+		}
+		return mo.None[models.AgentInboxMessageStatus](), fmt.Errorf("failed to get agent inbox message: %w", err)
+	}
+
+	return mo.Some(status), nil
+}
+
+// MarkInboxMessageProcessed records that a message received from an agent was processed
+// Returns false if the message is not being processed, e.g. because it expired from the inbox meanwhile.
+func (r *PostgresAgentInboxRepository) MarkInboxMessageProcessed(
+	ctx context.Context,
+	orgID models.OrgID,
+	agentID string,
+	messageID string,
+) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.agent_inbox_messages
+		SET status = 'PROCESSED'
+		WHERE organization_id = $1 AND agent_id = $2 AND message_id = $3 AND status = 'PROCESSING'`, r.schema)
+
+	result, err := r.db.ExecContext(ctx, query, orgID, agentID, messageID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark agent inbox message processed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// DeleteInboxMessage forgets a message received from an agent so a resent copy is processed again
+func (r *PostgresAgentInboxRepository) DeleteInboxMessage(
+	ctx context.Context,
+	orgID models.OrgID,
+	agentID string,
+	messageID string,
+) (bool, error) {
+	query := fmt.Sprintf(`
+		DELETE FROM %s.agent_inbox_messages
+		WHERE organization_id = $1 AND agent_id = $2 AND message_id = $3`, r.schema)
+
+	result, err := r.db.ExecContext(ctx, query, orgID, agentID, messageID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete agent inbox message: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// DeleteExpiredInboxMessages removes messages received before the dedupe window of all organizations
+func (r *PostgresAgentInboxRepository) DeleteExpiredInboxMessages(
+	ctx context.Context,
+	window time.Duration,
+) (int64, error) {
+	query := fmt.Sprintf(`
+		DELETE FROM %s.agent_inbox_messages
+		WHERE received_at < NOW() - INTERVAL '%d seconds'`, r.schema, int(window.Seconds()))
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired agent inbox messages: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
@@ -0,0 +1,338 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/samber/mo"
+
+	"ccbackend/models"
+)
+
+type PostgresAgentOutboxRepository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+// Column names for agent_outbox_messages table
+var agentOutboxMessagesColumns = []string{
+	"id",
+	"organization_id",
+	"agent_id",
+	"job_id",
+	"processed_message_id",
+	"message_type",
+	"payload",
+	"status",
+	"attempts",
+	"last_error",
+	"next_attempt_at",
+	"delivered_at",
+	"created_at",
+	"updated_at",
+}
+
+// Column names for listing agent_outbox_messages - the payload is only needed to resend a message
+var agentOutboxMessageListingColumns = slices.DeleteFunc(
+	slices.Clone(agentOutboxMessagesColumns),
+	func(column string) bool { return column == "payload" },
+)
+
+func NewPostgresAgentOutboxRepository(db *sqlx.DB, schema string) *PostgresAgentOutboxRepository {
+	return &PostgresAgentOutboxRepository{db: db, schema: schema}
+}
+
+func (r *PostgresAgentOutboxRepository) CreateOutboxMessage(
+	ctx context.Context,
+	message *models.AgentOutboxMessage,
+) error {
+	insertColumns := []string{
+		"id",
+		"organization_id",
+		"agent_id",
+		"job_id",
+		"processed_message_id",
+		"message_type",
+		"payload",
+		"status",
+		"attempts",
+		"next_attempt_at",
+		"created_at",
+		"updated_at",
+	}
+	columnsStr := strings.Join(insertColumns, ", ")
+	returningStr := strings.Join(agentOutboxMessagesColumns, ", ")
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s.agent_outbox_messages (%s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		RETURNING %s`, r.schema, columnsStr, returningStr)
+
+	// Payload is passed as text - lib/pq would encode a byte slice as bytea which JSONB does not accept
+	err := r.db.QueryRowxContext(
+		ctx,
+		query,
+		message.ID,
+		message.OrgID,
+		message.AgentID,
+		message.JobID,
+		message.ProcessedMessageID,
+		message.MessageType,
+		string(message.Payload),
+		message.Status,
+		message.Attempts,
+		message.NextAttemptAt,
+	).StructScan(message)
+	if err != nil {
+		return fmt.Errorf("failed to create agent outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateOutboxMessageAttempt records another delivery attempt of a pending message
+func (r *PostgresAgentOutboxRepository) UpdateOutboxMessageAttempt(
+	ctx context.Context,
+	id string,
+	orgID models.OrgID,
+	attempts int,
+	nextAttemptAt time.Time,
+) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.agent_outbox_messages
+		SET attempts = $3, next_attempt_at = $4, updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND status = 'PENDING'`, r.schema)
+
+	result, err := r.db.ExecContext(ctx, query, id, orgID, attempts, nextAttemptAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to update agent outbox message attempt: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// UpdateOutboxMessageLastError stores why the latest delivery attempt of a pending message failed
+func (r *PostgresAgentOutboxRepository) UpdateOutboxMessageLastError(
+	ctx context.Context,
+	id string,
+	orgID models.OrgID,
+	lastError string,
+) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.agent_outbox_messages
+		SET last_error = $3, updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND status = 'PENDING'`, r.schema)
+
+	result, err := r.db.ExecContext(ctx, query, id, orgID, lastError)
+	if err != nil {
+		return false, fmt.Errorf("failed to update agent outbox message last error: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// MarkOutboxMessageDelivered marks a pending message as acknowledged by the agent and returns it
+// Returns None if the message does not exist or is no longer pending, e.g. on a duplicate acknowledgement
+func (r *PostgresAgentOutboxRepository) MarkOutboxMessageDelivered(
+	ctx context.Context,
+	id string,
+	orgID models.OrgID,
+) (mo.Option[*models.AgentOutboxMessage], error) {
+	returningStr := strings.Join(agentOutboxMessagesColumns, ", ")
+	query := fmt.Sprintf(`
+		UPDATE %s.agent_outbox_messages
+		SET status = 'DELIVERED', delivered_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND status = 'PENDING'
+		RETURNING %s`, r.schema, returningStr)
+
+	message := &models.AgentOutboxMessage{}
+	err := r.db.QueryRowxContext(ctx, query, id, orgID).StructScan(message)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return mo.None[*models.AgentOutboxMessage](), nil
+		}
+		return mo.None[*models.AgentOutboxMessage](), fmt.Errorf("failed to mark agent outbox message delivered: %w", err)
+	}
+
+	return mo.Some(message), nil
+}
+
+// MarkOutboxMessageFailed stops retrying a pending message
+func (r *PostgresAgentOutboxRepository) MarkOutboxMessageFailed(
+	ctx context.Context,
+	id string,
+	orgID models.OrgID,
+	lastError string,
+) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.agent_outbox_messages
+		SET status = 'FAILED', last_error = $3, updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND status = 'PENDING'`, r.schema)
+
+	result, err := r.db.ExecContext(ctx, query, id, orgID, lastError)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark agent outbox message failed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// FailPendingOutboxMessagesForJob stops retrying the job's pending messages, except those of keepMessageTypes
+// An empty agentID fails the pending messages of every agent.
+func (r *PostgresAgentOutboxRepository) FailPendingOutboxMessagesForJob(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+	agentID string,
+	keepMessageTypes []string,
+	lastError string,
+) (int64, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.agent_outbox_messages
+		SET status = 'FAILED', last_error = $5, updated_at = NOW()
+		WHERE organization_id = $1 AND job_id = $2 AND ($3 = '' OR agent_id = $3)
+			AND status = 'PENDING' AND NOT (message_type = ANY($4))`, r.schema)
+
+	result, err := r.db.ExecContext(ctx, query, orgID, jobID, agentID, pq.Array(keepMessageTypes), lastError)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail pending agent outbox messages of job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// FailStaleOutboxMessages stops retrying pending messages due for a retry whose agent is no longer assigned to the
+// job or whose processed message is no longer in progress, except those of keepMessageTypes
+func (r *PostgresAgentOutboxRepository) FailStaleOutboxMessages(
+	ctx context.Context,
+	orgID models.OrgID,
+	keepMessageTypes []string,
+	lastError string,
+) (int64, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.agent_outbox_messages o
+		SET status = 'FAILED', last_error = $3, updated_at = NOW()
+		WHERE o.organization_id = $1 AND o.status = 'PENDING' AND o.next_attempt_at <= NOW()
+			AND NOT (o.message_type = ANY($2))
+			AND (
+				NOT EXISTS (
+					SELECT 1 FROM %s.agent_job_assignments aja
+					WHERE aja.agent_id = o.agent_id AND aja.job_id = o.job_id
+				)
+				OR (
+					NOT EXISTS (
+						SELECT 1 FROM %s.processed_slack_messages psm
+						WHERE psm.id = o.processed_message_id AND psm.status = 'IN_PROGRESS'
+					)
+					AND NOT EXISTS (
+						SELECT 1 FROM %s.processed_discord_messages pdm
+						WHERE pdm.id = o.processed_message_id AND pdm.status = 'IN_PROGRESS'
+					)
+				)
+			)`, r.schema, r.schema, r.schema, r.schema)
+
+	result, err := r.db.ExecContext(ctx, query, orgID, pq.Array(keepMessageTypes), lastError)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail stale agent outbox messages: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// GetPendingOutboxMessagesDue returns pending messages whose acknowledgement is overdue, oldest first
+func (r *PostgresAgentOutboxRepository) GetPendingOutboxMessagesDue(
+	ctx context.Context,
+	orgID models.OrgID,
+) ([]*models.AgentOutboxMessage, error) {
+	columnsStr := strings.Join(agentOutboxMessagesColumns, ", ")
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.agent_outbox_messages
+		WHERE organization_id = $1 AND status = 'PENDING' AND next_attempt_at <= NOW()
+		ORDER BY created_at ASC, id ASC`, columnsStr, r.schema)
+
+	var messages []*models.AgentOutboxMessage
+	if err := r.db.SelectContext(ctx, &messages, query, orgID); err != nil {
+		return nil, fmt.Errorf("failed to get due agent outbox messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// GetOutboxMessages returns the organization's most recent messages, optionally only those of one job
+// Their payload is not loaded.
+func (r *PostgresAgentOutboxRepository) GetOutboxMessages(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+	limit int,
+) ([]*models.AgentOutboxMessage, error) {
+	columnsStr := strings.Join(agentOutboxMessageListingColumns, ", ")
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.agent_outbox_messages
+		WHERE organization_id = $1 AND ($2 = '' OR job_id = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3`, columnsStr, r.schema)
+
+	var messages []*models.AgentOutboxMessage
+	if err := r.db.SelectContext(ctx, &messages, query, orgID, jobID, limit); err != nil {
+		return nil, fmt.Errorf("failed to get agent outbox messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// DeleteFinishedOutboxMessages removes delivered and failed messages of all organizations which finished before
+// the retention period
+func (r *PostgresAgentOutboxRepository) DeleteFinishedOutboxMessages(
+	ctx context.Context,
+	retention time.Duration,
+) (int64, error) {
+	query := fmt.Sprintf(`
+		DELETE FROM %s.agent_outbox_messages
+		WHERE status <> 'PENDING' AND updated_at < NOW() - INTERVAL '%d seconds'`, r.schema, int(retention.Seconds()))
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished agent outbox messages: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
@@ -27,6 +27,15 @@ var activeAgentsColumns = []string{
 	"organization_id",
 	"ccagent_id",
 	"repo_url",
+	"max_concurrency",
+	"labels",
+	"draining",
+	"version",
+	"os",
+	"hostname",
+	"agent_reported_rtt_ms",
+	"validation_failures",
+	"disconnected_at",
 	"created_at",
 	"updated_at",
 	"last_active_at",
@@ -52,6 +61,8 @@ func (r *PostgresAgentsRepository) UpsertActiveAgent(ctx context.Context, agent
 		"organization_id",
 		"ccagent_id",
 		"repo_url",
+		"max_concurrency",
+		"labels",
 		"created_at",
 		"updated_at",
 		"last_active_at",
@@ -61,17 +72,29 @@ func (r *PostgresAgentsRepository) UpsertActiveAgent(ctx context.Context, agent
 
 	query := fmt.Sprintf(`
 		INSERT INTO %s.active_agents (%s)
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW(), NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW(), NOW())
 		ON CONFLICT (organization_id, ccagent_id)
 		DO UPDATE SET
 			ws_connection_id = EXCLUDED.ws_connection_id,
 			repo_url = EXCLUDED.repo_url,
+			max_concurrency = EXCLUDED.max_concurrency,
+			labels = EXCLUDED.labels,
+			disconnected_at = NULL,
 			updated_at = NOW(),
 			last_active_at = NOW()
 		RETURNING %s`, r.schema, columnsStr, returningStr)
 
-	err := r.db.QueryRowxContext(ctx, query, agent.ID, agent.WSConnectionID, agent.OrgID, agent.CCAgentID, agent.RepoURL).
-		StructScan(agent)
+	err := r.db.QueryRowxContext(
+		ctx,
+		query,
+		agent.ID,
+		agent.WSConnectionID,
+		agent.OrgID,
+		agent.CCAgentID,
+		agent.RepoURL,
+		agent.MaxConcurrency,
+		agent.Labels,
+	).StructScan(agent)
 	if err != nil {
 		return fmt.Errorf("failed to upsert active agent: %w", err)
 	}
@@ -84,7 +107,14 @@ func (r *PostgresAgentsRepository) DeleteActiveAgent(
 	id string,
 	orgID models.OrgID,
 ) (bool, error) {
-	query := fmt.Sprintf("DELETE FROM %s.active_agents WHERE id = $1 AND organization_id = $2", r.schema)
+	// The assignment history of the agent's jobs is closed along with its assignments
+	query := fmt.Sprintf(`
+		WITH closed_history AS (
+			UPDATE %s.job_assignment_history
+			SET unassigned_at = NOW()
+			WHERE agent_id = $1 AND organization_id = $2 AND unassigned_at IS NULL
+		)
+		DELETE FROM %s.active_agents WHERE id = $1 AND organization_id = $2`, r.schema, r.schema)
 
 	result, err := r.db.ExecContext(ctx, query, id, orgID)
 	if err != nil {
@@ -225,15 +255,24 @@ func (r *PostgresAgentsRepository) GetAgentByJobID(
 
 func (r *PostgresAgentsRepository) AssignAgentToJob(ctx context.Context, assignment *models.AgentJobAssignment) error {
 	// Use ON CONFLICT DO NOTHING to handle duplicate assignments gracefully
+	// A new assignment is also recorded in the job's assignment history, under the same ID.
 	insertColumns := []string{"id", "agent_id", "job_id", "organization_id", "assigned_at"}
 	columnsStr := strings.Join(insertColumns, ", ")
 	returningStr := strings.Join(agentJobAssignmentsColumns, ", ")
 
 	query := fmt.Sprintf(`
-		INSERT INTO %s.agent_job_assignments (%s) 
-		VALUES ($1, $2, $3, $4, NOW()) 
-		ON CONFLICT (agent_id, job_id) DO NOTHING
-		RETURNING %s`, r.schema, columnsStr, returningStr)
+		WITH inserted AS (
+			INSERT INTO %s.agent_job_assignments (%s) 
+			VALUES ($1, $2, $3, $4, NOW()) 
+			ON CONFLICT (agent_id, job_id) DO NOTHING
+			RETURNING %s
+		), history AS (
+			INSERT INTO %s.job_assignment_history (id, organization_id, job_id, agent_id, ccagent_id, assigned_at)
+			SELECT i.id, i.organization_id, i.job_id, i.agent_id, a.ccagent_id, i.assigned_at
+			FROM inserted i
+			JOIN %s.active_agents a ON a.id = i.agent_id
+		)
+		SELECT %s FROM inserted`, r.schema, columnsStr, returningStr, r.schema, r.schema, returningStr)
 
 	err := r.db.QueryRowxContext(ctx, query, assignment.ID, assignment.AgentID, assignment.JobID, assignment.OrgID).
 		StructScan(assignment)
@@ -249,26 +288,111 @@ func (r *PostgresAgentsRepository) AssignAgentToJob(ctx context.Context, assignm
 	return nil
 }
 
+// AssignAgentToJobWithinCapacity assigns the job to the agent unless the agent has reached its max concurrency
+// Returns false if the agent is at capacity or gone. Concurrent assignments to the same agent, also from other
+// backend replicas, wait for each other on the agent's row, so the capacity check always sees the latest count.
+func (r *PostgresAgentsRepository) AssignAgentToJobWithinCapacity(
+	ctx context.Context,
+	assignment *models.AgentJobAssignment,
+) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The lock is taken in its own statement - the insert below runs on a snapshot taken after it was granted
+	var maxConcurrency int
+	lockQuery := fmt.Sprintf(`
+		SELECT max_concurrency FROM %s.active_agents
+		WHERE id = $1 AND organization_id = $2
+		FOR UPDATE`, r.schema)
+	if err := tx.GetContext(ctx, &maxConcurrency, lockQuery, assignment.AgentID, assignment.OrgID); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to lock agent: %w", err)
+	}
+
+	// A max concurrency of 0 means the agent takes any number of jobs
+	insertColumns := []string{"id", "agent_id", "job_id", "organization_id", "assigned_at"}
+	columnsStr := strings.Join(insertColumns, ", ")
+	returningStr := strings.Join(agentJobAssignmentsColumns, ", ")
+	insertQuery := fmt.Sprintf(`
+		WITH inserted AS (
+			INSERT INTO %s.agent_job_assignments (%s)
+			SELECT $1, $2, $3, $4, NOW()
+			WHERE $5 <= 0 OR (
+				SELECT COUNT(*) FROM %s.agent_job_assignments WHERE agent_id = $2 AND organization_id = $4
+			) < $5
+			ON CONFLICT (agent_id, job_id) DO NOTHING
+			RETURNING %s
+		), history AS (
+			INSERT INTO %s.job_assignment_history (id, organization_id, job_id, agent_id, ccagent_id, assigned_at)
+			SELECT i.id, i.organization_id, i.job_id, i.agent_id, a.ccagent_id, i.assigned_at
+			FROM inserted i
+			JOIN %s.active_agents a ON a.id = i.agent_id
+		)
+		SELECT %s FROM inserted`, r.schema, columnsStr, r.schema, returningStr, r.schema, r.schema, returningStr)
+
+	err = tx.QueryRowxContext(
+		ctx,
+		insertQuery,
+		assignment.ID,
+		assignment.AgentID,
+		assignment.JobID,
+		assignment.OrgID,
+		maxConcurrency,
+	).StructScan(assignment)
+	if err == sql.ErrNoRows {
+		// Nothing was inserted - either the agent is at capacity or the job is already assigned to it
+		var alreadyAssigned bool
+		existsQuery := fmt.Sprintf(`
+			SELECT EXISTS (
+				SELECT 1 FROM %s.agent_job_assignments
+				WHERE agent_id = $1 AND job_id = $2 AND organization_id = $3
+			)`, r.schema)
+		err = tx.GetContext(ctx, &alreadyAssigned, existsQuery, assignment.AgentID, assignment.JobID, assignment.OrgID)
+		if err != nil {
+			return false, fmt.Errorf("failed to check existing assignment: %w", err)
+		}
+		return alreadyAssigned, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to assign agent to job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit assignment: %w", err)
+	}
+	return true, nil
+}
+
 func (r *PostgresAgentsRepository) UnassignAgentFromJob(
 	ctx context.Context,
 	agentID, jobID string,
 	orgID models.OrgID,
 ) (bool, error) {
+	// The assignment history entry of the removed assignment is closed as well
 	query := fmt.Sprintf(`
-		DELETE FROM %s.agent_job_assignments 
-		WHERE agent_id = $1 AND job_id = $2 AND organization_id = $3`, r.schema)
-
-	result, err := r.db.ExecContext(ctx, query, agentID, jobID, orgID)
+		WITH deleted AS (
+			DELETE FROM %s.agent_job_assignments 
+			WHERE agent_id = $1 AND job_id = $2 AND organization_id = $3
+			RETURNING id
+		), closed_history AS (
+			UPDATE %s.job_assignment_history
+			SET unassigned_at = NOW()
+			WHERE id IN (SELECT id FROM deleted) AND unassigned_at IS NULL
+		)
+		SELECT COUNT(*) FROM deleted`, r.schema, r.schema)
+
+	var deletedCount int
+	err := r.db.GetContext(ctx, &deletedCount, query, agentID, jobID, orgID)
 	if err != nil {
 		return false, fmt.Errorf("failed to unassign agent from job: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return false, fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	return rowsAffected > 0, nil
+	return deletedCount > 0, nil
 }
 
 func (r *PostgresAgentsRepository) GetActiveAgentJobAssignments(
@@ -314,6 +438,97 @@ func (r *PostgresAgentsRepository) UpdateAgentLastActiveAt(
 	return rowsAffected > 0, nil
 }
 
+// UpdateAgentTelemetry refreshes the agent's last_active_at timestamp and stores the reported telemetry
+// Empty fields and a nil round-trip time keep the previously stored values
+func (r *PostgresAgentsRepository) UpdateAgentTelemetry(
+	ctx context.Context,
+	wsConnectionID string,
+	orgID models.OrgID,
+	telemetry models.AgentTelemetry,
+) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.active_agents
+		SET
+			version = COALESCE(NULLIF($3, ''), version),
+			os = COALESCE(NULLIF($4, ''), os),
+			hostname = COALESCE(NULLIF($5, ''), hostname),
+			agent_reported_rtt_ms = COALESCE($6, agent_reported_rtt_ms),
+			last_active_at = NOW()
+		WHERE ws_connection_id = $1 AND organization_id = $2`, r.schema)
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		wsConnectionID,
+		orgID,
+		telemetry.Version,
+		telemetry.OS,
+		telemetry.Hostname,
+		telemetry.RoundTripTimeMs,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update agent telemetry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// IncrementAgentValidationFailures counts a message of the agent which failed payload validation
+func (r *PostgresAgentsRepository) IncrementAgentValidationFailures(
+	ctx context.Context,
+	wsConnectionID string,
+	orgID models.OrgID,
+) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.active_agents
+		SET validation_failures = validation_failures + 1
+		WHERE ws_connection_id = $1 AND organization_id = $2`, r.schema)
+
+	result, err := r.db.ExecContext(ctx, query, wsConnectionID, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to increment agent validation failures: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// UpdateAgentDraining sets whether the agent is draining
+// The flag is kept when the agent reconnects so a flapping connection does not undo a drain
+func (r *PostgresAgentsRepository) UpdateAgentDraining(
+	ctx context.Context,
+	id string,
+	orgID models.OrgID,
+	draining bool,
+) (mo.Option[*models.ActiveAgent], error) {
+	returningStr := strings.Join(activeAgentsColumns, ", ")
+	query := fmt.Sprintf(`
+		UPDATE %s.active_agents
+		SET draining = $3, updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2
+		RETURNING %s`, r.schema, returningStr)
+
+	agent := &models.ActiveAgent{}
+	err := r.db.QueryRowxContext(ctx, query, id, orgID, draining).StructScan(agent)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return mo.None[*models.ActiveAgent](), nil
+		}
+		return mo.None[*models.ActiveAgent](), fmt.Errorf("failed to update agent draining: %w", err)
+	}
+
+	return mo.Some(agent), nil
+}
+
 func (r *PostgresAgentsRepository) GetInactiveAgents(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -334,3 +549,51 @@ func (r *PostgresAgentsRepository) GetInactiveAgents(
 
 	return agents, nil
 }
+
+// MarkAgentDisconnected records that the agent's connection dropped
+// Returns false if the agent is no longer bound to the connection, e.g. because it already reconnected.
+func (r *PostgresAgentsRepository) MarkAgentDisconnected(
+	ctx context.Context,
+	wsConnectionID string,
+	orgID models.OrgID,
+) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.active_agents
+		SET disconnected_at = NOW(), updated_at = NOW()
+		WHERE ws_connection_id = $1 AND organization_id = $2 AND disconnected_at IS NULL`, r.schema)
+
+	result, err := r.db.ExecContext(ctx, query, wsConnectionID, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark agent disconnected: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetDisconnectedAgents returns agents whose connection dropped more than gracePeriodSeconds ago
+// without them reconnecting
+func (r *PostgresAgentsRepository) GetDisconnectedAgents(
+	ctx context.Context,
+	orgID models.OrgID,
+	gracePeriodSeconds int,
+) ([]*models.ActiveAgent, error) {
+	columnsStr := strings.Join(activeAgentsColumns, ", ")
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.active_agents
+		WHERE organization_id = $1 AND disconnected_at < NOW() - make_interval(secs => $2)
+		ORDER BY disconnected_at ASC`, columnsStr, r.schema)
+
+	var agents []*models.ActiveAgent
+	err := r.db.SelectContext(ctx, &agents, query, orgID, gracePeriodSeconds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disconnected agents: %w", err)
+	}
+
+	return agents, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"ccbackend/models"
 
@@ -11,6 +13,21 @@ import (
 	"github.com/samber/mo"
 )
 
+// ccagentContainerIntegrationsColumns are the columns selected when loading an integration
+var ccagentContainerIntegrationsColumns = []string{
+	"id",
+	"instances_count",
+	"repo_url",
+	"ssh_host",
+	"organization_id",
+	"redeploy_status",
+	"redeploy_error",
+	"redeploy_started_at",
+	"redeploy_updated_at",
+	"created_at",
+	"updated_at",
+}
+
 // PostgresCCAgentContainerIntegrationsRepository handles database operations for CCAgent container integrations
 type PostgresCCAgentContainerIntegrationsRepository struct {
 	db     *sqlx.DB
@@ -59,11 +76,12 @@ func (r *PostgresCCAgentContainerIntegrationsRepository) ListCCAgentContainerInt
 	orgID models.OrgID,
 ) ([]models.CCAgentContainerIntegration, error) {
 	integrations := []models.CCAgentContainerIntegration{}
+	columnsStr := strings.Join(ccagentContainerIntegrationsColumns, ", ")
 	query := fmt.Sprintf(`
-		SELECT id, instances_count, repo_url, ssh_host, organization_id, created_at, updated_at
+		SELECT %s
 		FROM %s.ccagent_container_integrations
 		WHERE organization_id = $1
-		ORDER BY created_at DESC`, r.schema)
+		ORDER BY created_at DESC`, columnsStr, r.schema)
 
 	err := r.db.SelectContext(ctx, &integrations, query, orgID)
 	if err != nil {
@@ -80,10 +98,11 @@ func (r *PostgresCCAgentContainerIntegrationsRepository) GetCCAgentContainerInte
 	id string,
 ) (mo.Option[*models.CCAgentContainerIntegration], error) {
 	var integration models.CCAgentContainerIntegration
+	columnsStr := strings.Join(ccagentContainerIntegrationsColumns, ", ")
 	query := fmt.Sprintf(`
-		SELECT id, instances_count, repo_url, ssh_host, organization_id, created_at, updated_at
+		SELECT %s
 		FROM %s.ccagent_container_integrations
-		WHERE id = $1 AND organization_id = $2`, r.schema)
+		WHERE id = $1 AND organization_id = $2`, columnsStr, r.schema)
 
 	err := r.db.GetContext(ctx, &integration, query, id, orgID)
 	if err != nil {
@@ -99,6 +118,83 @@ func (r *PostgresCCAgentContainerIntegrationsRepository) GetCCAgentContainerInte
 	return mo.Some(&integration), nil
 }
 
+// StartCCAgentContainerRedeploy marks a redeploy of the integration as draining
+// Returns None if the integration does not exist or another redeploy of it is in progress - one whose status was
+// last updated before staleAfter is considered abandoned and gets replaced
+func (r *PostgresCCAgentContainerIntegrationsRepository) StartCCAgentContainerRedeploy(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+	staleAfter time.Duration,
+) (mo.Option[*models.CCAgentContainerIntegration], error) {
+	var integration models.CCAgentContainerIntegration
+	returningStr := strings.Join(ccagentContainerIntegrationsColumns, ", ")
+	query := fmt.Sprintf(`
+		UPDATE %s.ccagent_container_integrations
+		SET redeploy_status = $3, redeploy_error = NULL, redeploy_started_at = NOW(), redeploy_updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2
+			AND (
+				redeploy_status IS NULL
+				OR redeploy_status NOT IN ($4, $5)
+				OR redeploy_updated_at < NOW() - make_interval(secs => $6)
+			)
+		RETURNING %s`, r.schema, returningStr)
+
+	err := r.db.GetContext(
+		ctx,
+		&integration,
+		query,
+		id,
+		orgID,
+		models.CCAgentRedeployStatusDraining,
+		models.CCAgentRedeployStatusDraining,
+		models.CCAgentRedeployStatusRedeploying,
+		int(staleAfter.Seconds()),
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return mo.None[*models.CCAgentContainerIntegration](), nil
+		}
+		return mo.None[*models.CCAgentContainerIntegration](), fmt.Errorf(
+			"failed to start CCAgent container redeploy: %w",
+			err,
+		)
+	}
+
+	return mo.Some(&integration), nil
+}
+
+// UpdateCCAgentContainerRedeployStatus records the progress of the integration's redeploy
+// An empty redeployError clears the error
+func (r *PostgresCCAgentContainerIntegrationsRepository) UpdateCCAgentContainerRedeployStatus(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+	status models.CCAgentRedeployStatus,
+	redeployError string,
+) error {
+	query := fmt.Sprintf(`
+		UPDATE %s.ccagent_container_integrations
+		SET redeploy_status = $3, redeploy_error = NULLIF($4, ''), redeploy_updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2`, r.schema)
+
+	result, err := r.db.ExecContext(ctx, query, id, orgID, status, redeployError)
+	if err != nil {
+		return fmt.Errorf("failed to update CCAgent container redeploy status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("CCAgent container integration not found")
+	}
+
+	return nil
+}
+
 // DeleteCCAgentContainerIntegration deletes a CCAgent container integration
 func (r *PostgresCCAgentContainerIntegrationsRepository) DeleteCCAgentContainerIntegration(
 	ctx context.Context,
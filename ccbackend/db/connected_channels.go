@@ -25,6 +25,7 @@ type DatabaseConnectedChannel struct {
 	DiscordGuildID    *string   `json:"discord_guild_id"   db:"discord_guild_id"`
 	DiscordChannelID  *string   `json:"discord_channel_id" db:"discord_channel_id"`
 	DefaultRepoURL    *string   `json:"default_repo_url"   db:"default_repo_url"`
+	AgentSelector     models.AgentLabels `json:"agent_selector"     db:"agent_selector"`
 	CreatedAt         time.Time `json:"created_at"         db:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"         db:"updated_at"`
 }
@@ -46,6 +47,7 @@ func (db *DatabaseConnectedChannel) ToSlackConnectedChannel() (*models.SlackConn
 		TeamID:         *db.SlackTeamID,
 		ChannelID:      *db.SlackChannelID,
 		DefaultRepoURL: db.DefaultRepoURL,
+		AgentSelector:  db.AgentSelector,
 		CreatedAt:      db.CreatedAt,
 		UpdatedAt:      db.UpdatedAt,
 	}, nil
@@ -66,6 +68,7 @@ func (db *DatabaseConnectedChannel) ToDiscordConnectedChannel() (*models.Discord
 		GuildID:        *db.DiscordGuildID,
 		ChannelID:      *db.DiscordChannelID,
 		DefaultRepoURL: db.DefaultRepoURL,
+		AgentSelector:  db.AgentSelector,
 		CreatedAt:      db.CreatedAt,
 		UpdatedAt:      db.UpdatedAt,
 	}, nil
@@ -105,6 +108,7 @@ func FromSlackConnectedChannel(slack *models.SlackConnectedChannel) *DatabaseCon
 		DiscordGuildID:   nil,
 		DiscordChannelID: nil,
 		DefaultRepoURL:   slack.DefaultRepoURL,
+		AgentSelector:    slack.AgentSelector,
 		CreatedAt:        slack.CreatedAt,
 		UpdatedAt:        slack.UpdatedAt,
 	}
@@ -120,6 +124,7 @@ func FromDiscordConnectedChannel(discord *models.DiscordConnectedChannel) *Datab
 		DiscordGuildID:   &discord.GuildID,
 		DiscordChannelID: &discord.ChannelID,
 		DefaultRepoURL:   discord.DefaultRepoURL,
+		AgentSelector:    discord.AgentSelector,
 		CreatedAt:        discord.CreatedAt,
 		UpdatedAt:        discord.UpdatedAt,
 	}
@@ -139,6 +144,7 @@ var connectedChannelsColumns = []string{
 	"discord_guild_id",
 	"discord_channel_id",
 	"default_repo_url",
+	"agent_selector",
 	"created_at",
 	"updated_at",
 }
@@ -156,7 +162,7 @@ func (r *PostgresConnectedChannelsRepository) UpsertSlackConnectedChannel(
 
 	query := fmt.Sprintf(`
 		INSERT INTO %s.connected_channels (%s)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
 		ON CONFLICT (organization_id, slack_team_id, slack_channel_id)
 		DO UPDATE SET
 			default_repo_url = EXCLUDED.default_repo_url,
@@ -170,7 +176,8 @@ func (r *PostgresConnectedChannelsRepository) UpsertSlackConnectedChannel(
 		channel.SlackChannelID,
 		channel.DiscordGuildID,
 		channel.DiscordChannelID,
-		channel.DefaultRepoURL).
+		channel.DefaultRepoURL,
+		channel.AgentSelector).
 		StructScan(channel)
 	if err != nil {
 		return fmt.Errorf("failed to upsert Slack connected channel: %w", err)
@@ -188,7 +195,7 @@ func (r *PostgresConnectedChannelsRepository) UpsertDiscordConnectedChannel(
 
 	query := fmt.Sprintf(`
 		INSERT INTO %s.connected_channels (%s)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
 		ON CONFLICT (organization_id, discord_guild_id, discord_channel_id)
 		DO UPDATE SET
 			default_repo_url = EXCLUDED.default_repo_url,
@@ -202,7 +209,8 @@ func (r *PostgresConnectedChannelsRepository) UpsertDiscordConnectedChannel(
 		channel.SlackChannelID,
 		channel.DiscordGuildID,
 		channel.DiscordChannelID,
-		channel.DefaultRepoURL).
+		channel.DefaultRepoURL,
+		channel.AgentSelector).
 		StructScan(channel)
 	if err != nil {
 		return fmt.Errorf("failed to upsert Discord connected channel: %w", err)
@@ -261,9 +269,28 @@ func (r *PostgresConnectedChannelsRepository) GetDiscordConnectedChannel(
 	return mo.Some(channel), nil
 }
 
+// UpdateAgentSelector sets the label selector of a connected channel and returns the updated record
+func (r *PostgresConnectedChannelsRepository) UpdateAgentSelector(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+	agentSelector models.AgentLabels,
+) (mo.Option[*DatabaseConnectedChannel], error) {
+	returningStr := strings.Join(connectedChannelsColumns, ", ")
+	query := fmt.Sprintf(`
+		UPDATE %s.connected_channels
+		SET agent_selector = $3, updated_at = NOW()
+		WHERE organization_id = $1 AND id = $2
+		RETURNING %s`, r.schema, returningStr)
 
+	channel := &DatabaseConnectedChannel{}
+	err := r.db.QueryRowxContext(ctx, query, orgID, id, agentSelector).StructScan(channel)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return mo.None[*DatabaseConnectedChannel](), nil
+		}
+		return mo.None[*DatabaseConnectedChannel](), fmt.Errorf("failed to update connected channel agent selector: %w", err)
+	}
 
-
-
-
-
+	return mo.Some(channel), nil
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	dbtx "ccbackend/db/tx"
+	"ccbackend/models"
+)
+
+type PostgresJobTranscriptsRepository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+// Column names for job_transcript_messages table - optional columns are read as empty strings
+var jobTranscriptMessagesColumns = []string{
+	"id",
+	"organization_id",
+	"job_id",
+	"author",
+	"COALESCE(author_id, '') AS author_id",
+	"message_type",
+	"content",
+	"COALESCE(processed_message_id, '') AS processed_message_id",
+	"created_at",
+}
+
+func NewPostgresJobTranscriptsRepository(db *sqlx.DB, schema string) *PostgresJobTranscriptsRepository {
+	return &PostgresJobTranscriptsRepository{db: db, schema: schema}
+}
+
+func (r *PostgresJobTranscriptsRepository) CreateTranscriptMessage(
+	ctx context.Context,
+	message *models.JobTranscriptMessage,
+) error {
+	db := dbtx.GetTransactional(ctx, r.db)
+	insertColumns := []string{
+		"id",
+		"organization_id",
+		"job_id",
+		"author",
+		"author_id",
+		"message_type",
+		"content",
+		"processed_message_id",
+		"created_at",
+	}
+	columnsStr := strings.Join(insertColumns, ", ")
+	returningStr := strings.Join(jobTranscriptMessagesColumns, ", ")
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s.job_transcript_messages (%s)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, NULLIF($8, ''), NOW())
+		RETURNING %s`, r.schema, columnsStr, returningStr)
+
+	err := db.QueryRowxContext(
+		ctx,
+		query,
+		message.ID,
+		message.OrgID,
+		message.JobID,
+		message.Author,
+		message.AuthorID,
+		message.MessageType,
+		message.Content,
+		message.ProcessedMessageID,
+	).StructScan(message)
+	if err != nil {
+		return fmt.Errorf("failed to create job transcript message: %w", err)
+	}
+
+	return nil
+}
+
+// GetTranscriptMessagesByJobID returns the transcript of a job, oldest message first
+func (r *PostgresJobTranscriptsRepository) GetTranscriptMessagesByJobID(
+	ctx context.Context,
+	jobID string,
+	orgID models.OrgID,
+) ([]*models.JobTranscriptMessage, error) {
+	db := dbtx.GetTransactional(ctx, r.db)
+	columnsStr := strings.Join(jobTranscriptMessagesColumns, ", ")
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.job_transcript_messages
+		WHERE job_id = $1 AND organization_id = $2
+		ORDER BY created_at ASC, id ASC`, columnsStr, r.schema)
+
+	messages := []*models.JobTranscriptMessage{}
+	if err := db.SelectContext(ctx, &messages, query, jobID, orgID); err != nil {
+		return nil, fmt.Errorf("failed to get job transcript messages: %w", err)
+	}
+
+	return messages, nil
+}
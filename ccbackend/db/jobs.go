@@ -27,9 +27,16 @@ type DBJob struct {
 	ID        string       `db:"id"`
 	JobType   string       `db:"job_type"`
 	OrgID     models.OrgID `db:"organization_id"`
+	Priority  int          `db:"priority"`
+	Status    string       `db:"status"`
 	CreatedAt time.Time    `db:"created_at"`
 	UpdatedAt time.Time    `db:"updated_at"`
 
+	// Closing fields (nullable)
+	CompletionReason *string    `db:"completion_reason"`
+	CompletedAt      *time.Time `db:"completed_at"`
+	AssignedAgentID  *string    `db:"assigned_agent_id"`
+
 	// Slack fields (nullable)
 	SlackThreadTS      *string `db:"slack_thread_ts"`
 	SlackChannelID     *string `db:"slack_channel_id"`
@@ -58,6 +65,11 @@ var jobsColumns = []string{
 	"discord_user_id",
 	"discord_integration_id",
 	"organization_id",
+	"priority",
+	"status",
+	"completion_reason",
+	"completed_at",
+	"assigned_agent_id",
 	"created_at",
 	"updated_at",
 }
@@ -72,8 +84,18 @@ func dbJobToModel(dbJob *DBJob) (*models.Job, error) {
 		ID:        dbJob.ID,
 		JobType:   models.JobType(dbJob.JobType),
 		OrgID:     dbJob.OrgID,
+		Priority:  models.JobPriority(dbJob.Priority),
+		Status:    models.JobStatus(dbJob.Status),
 		CreatedAt: dbJob.CreatedAt,
 		UpdatedAt: dbJob.UpdatedAt,
+
+		CompletedAt: dbJob.CompletedAt,
+	}
+	if dbJob.CompletionReason != nil {
+		job.CompletionReason = *dbJob.CompletionReason
+	}
+	if dbJob.AssignedAgentID != nil {
+		job.AssignedAgentID = *dbJob.AssignedAgentID
 	}
 
 	// Populate payload based on type with comprehensive validation
@@ -127,6 +149,7 @@ func modelToDBJob(job *models.Job) (*DBJob, error) {
 		ID:        job.ID,
 		JobType:   string(job.JobType),
 		OrgID:     job.OrgID,
+		Priority:  int(job.Priority),
 		CreatedAt: job.CreatedAt,
 		UpdatedAt: job.UpdatedAt,
 	}
@@ -240,7 +263,8 @@ func (r *PostgresJobsRepository) GetJobBySlackThread(
 	query := fmt.Sprintf(`
 		SELECT %s 
 		FROM %s.jobs 
-		WHERE slack_thread_ts = $1 AND slack_channel_id = $2 AND slack_integration_id = $3 AND organization_id = $4`, columnsStr, r.schema)
+		WHERE slack_thread_ts = $1 AND slack_channel_id = $2 AND slack_integration_id = $3 AND organization_id = $4
+			AND status = 'ACTIVE'`, columnsStr, r.schema)
 
 	var dbJob DBJob
 	err := db.GetContext(ctx, &dbJob, query, threadTS, channelID, slackIntegrationID, orgID)
@@ -268,7 +292,8 @@ func (r *PostgresJobsRepository) GetJobByDiscordThread(
 	query := fmt.Sprintf(`
 		SELECT %s 
 		FROM %s.jobs 
-		WHERE discord_thread_id = $1 AND discord_integration_id = $2 AND organization_id = $3`, columnsStr, r.schema)
+		WHERE discord_thread_id = $1 AND discord_integration_id = $2 AND organization_id = $3
+			AND status = 'ACTIVE'`, columnsStr, r.schema)
 
 	var dbJob DBJob
 	err := db.GetContext(ctx, &dbJob, query, threadID, discordIntegrationID, orgID)
@@ -305,6 +330,69 @@ func (r *PostgresJobsRepository) UpdateJobTimestamp(
 	return nil
 }
 
+// RaiseJobPriority sets the job's priority if it is higher than the current one
+// Returns false if the job does not exist, is closed or already has the same or a higher priority
+func (r *PostgresJobsRepository) RaiseJobPriority(
+	ctx context.Context,
+	id string,
+	orgID models.OrgID,
+	priority models.JobPriority,
+) (bool, error) {
+	db := dbtx.GetTransactional(ctx, r.db)
+	query := fmt.Sprintf(`
+		UPDATE %s.jobs
+		SET priority = $3
+		WHERE id = $1 AND organization_id = $2 AND priority < $3 AND status = 'ACTIVE'`, r.schema)
+
+	result, err := db.ExecContext(ctx, query, id, orgID, int(priority))
+	if err != nil {
+		return false, fmt.Errorf("failed to raise job priority: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// HasQueuedJobsAbovePriority checks if the organization has jobs of the given type with queued messages
+// and a priority higher than the given one
+func (r *PostgresJobsRepository) HasQueuedJobsAbovePriority(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobType models.JobType,
+	priority models.JobPriority,
+) (bool, error) {
+	var messagesTable string
+	switch jobType {
+	case models.JobTypeSlack:
+		messagesTable = "processed_slack_messages"
+	case models.JobTypeDiscord:
+		messagesTable = "processed_discord_messages"
+	default:
+		return false, fmt.Errorf("unsupported job type: %s", jobType)
+	}
+
+	db := dbtx.GetTransactional(ctx, r.db)
+	query := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM %s.jobs j
+			JOIN %s.%s m ON m.job_id = j.id AND m.organization_id = j.organization_id
+			WHERE j.organization_id = $1 AND j.job_type = $2 AND j.priority > $3 AND j.status = 'ACTIVE'
+				AND m.status = 'QUEUED'
+		)`, r.schema, r.schema, messagesTable)
+
+	var exists bool
+	if err := db.GetContext(ctx, &exists, query, orgID, string(jobType), int(priority)); err != nil {
+		return false, fmt.Errorf("failed to check for queued jobs above priority: %w", err)
+	}
+
+	return exists, nil
+}
+
 func (r *PostgresJobsRepository) GetJobs(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -315,7 +403,7 @@ func (r *PostgresJobsRepository) GetJobs(
 	query := fmt.Sprintf(`
 		SELECT %s 
 		FROM %s.jobs 
-		WHERE organization_id = $1
+		WHERE organization_id = $1 AND status = 'ACTIVE'
 		ORDER BY created_at ASC`, columnsStr, r.schema)
 
 	var dbJobs []DBJob
@@ -337,6 +425,178 @@ func (r *PostgresJobsRepository) GetJobs(
 	return jobs, nil
 }
 
+// ListJobs returns up to limit jobs of the organization matching the filter, closed jobs included,
+// newest job first
+func (r *PostgresJobsRepository) ListJobs(
+	ctx context.Context,
+	orgID models.OrgID,
+	filter models.JobsFilter,
+	limit int,
+) ([]*models.Job, error) {
+	db := dbtx.GetTransactional(ctx, r.db)
+	columnsStr := strings.Join(jobsColumns, ", ")
+
+	args := []any{orgID}
+	conditions := []string{"organization_id = $1"}
+	addArg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filter.JobType != "" {
+		conditions = append(conditions, "job_type = "+addArg(string(filter.JobType)))
+	}
+	if filter.ChannelID != "" {
+		arg := addArg(filter.ChannelID)
+		conditions = append(conditions, fmt.Sprintf("(slack_channel_id = %s OR discord_channel_id = %s)", arg, arg))
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = "+addArg(string(filter.Status)))
+	}
+	if filter.AgentID != "" {
+		// Closed jobs keep the agent which held them last, the history has every assignment
+		arg := addArg(filter.AgentID)
+		conditions = append(conditions, fmt.Sprintf(`(assigned_agent_id = %s OR EXISTS (
+			SELECT 1 FROM %s.job_assignment_history h
+			WHERE h.job_id = jobs.id AND h.organization_id = jobs.organization_id AND h.agent_id = %s
+		))`, arg, r.schema, arg))
+	}
+	if filter.CreatorID != "" {
+		arg := addArg(filter.CreatorID)
+		conditions = append(conditions, fmt.Sprintf("(slack_user_id = %s OR discord_user_id = %s)", arg, arg))
+	}
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "created_at >= "+addArg(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < "+addArg(*filter.CreatedBefore))
+	}
+	if filter.Cursor != "" {
+		conditions = append(conditions, "id < "+addArg(filter.Cursor))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s 
+		FROM %s.jobs 
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT %s`, columnsStr, r.schema, strings.Join(conditions, " AND "), addArg(limit))
+
+	var dbJobs []DBJob
+	err := db.SelectContext(ctx, &dbJobs, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	jobs := make([]*models.Job, 0, len(dbJobs))
+	for _, dbJob := range dbJobs {
+		convertedJob, err := dbJobToModel(&dbJob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert job: %w", err)
+		}
+		jobs = append(jobs, convertedJob)
+	}
+
+	return jobs, nil
+}
+
+// GetJobAssignmentHistory returns every agent assignment of a job, oldest first
+func (r *PostgresJobsRepository) GetJobAssignmentHistory(
+	ctx context.Context,
+	jobID string,
+	orgID models.OrgID,
+) ([]*models.JobAssignmentHistoryEntry, error) {
+	db := dbtx.GetTransactional(ctx, r.db)
+	query := fmt.Sprintf(`
+		SELECT id, organization_id, job_id, agent_id, ccagent_id, assigned_at, unassigned_at
+		FROM %s.job_assignment_history
+		WHERE job_id = $1 AND organization_id = $2
+		ORDER BY assigned_at ASC, id ASC`, r.schema)
+
+	entries := []*models.JobAssignmentHistoryEntry{}
+	err := db.SelectContext(ctx, &entries, query, jobID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job assignment history: %w", err)
+	}
+
+	return entries, nil
+}
+
+// HasClosedJobForSlackThread checks if a job of the Slack thread was closed already
+func (r *PostgresJobsRepository) HasClosedJobForSlackThread(
+	ctx context.Context,
+	threadTS, channelID, slackIntegrationID string,
+	orgID models.OrgID,
+) (bool, error) {
+	db := dbtx.GetTransactional(ctx, r.db)
+	query := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM %s.jobs
+			WHERE slack_thread_ts = $1 AND slack_channel_id = $2 AND slack_integration_id = $3
+				AND organization_id = $4 AND status != 'ACTIVE'
+		)`, r.schema)
+
+	var exists bool
+	if err := db.GetContext(ctx, &exists, query, threadTS, channelID, slackIntegrationID, orgID); err != nil {
+		return false, fmt.Errorf("failed to check for closed job by slack thread: %w", err)
+	}
+
+	return exists, nil
+}
+
+// HasClosedJobForDiscordThread checks if a job of the Discord thread was closed already
+func (r *PostgresJobsRepository) HasClosedJobForDiscordThread(
+	ctx context.Context,
+	threadID, discordIntegrationID string,
+	orgID models.OrgID,
+) (bool, error) {
+	db := dbtx.GetTransactional(ctx, r.db)
+	query := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM %s.jobs
+			WHERE discord_thread_id = $1 AND discord_integration_id = $2 AND organization_id = $3
+				AND status != 'ACTIVE'
+		)`, r.schema)
+
+	var exists bool
+	if err := db.GetContext(ctx, &exists, query, threadID, discordIntegrationID, orgID); err != nil {
+		return false, fmt.Errorf("failed to check for closed job by discord thread: %w", err)
+	}
+
+	return exists, nil
+}
+
+// CloseJob moves an active job to a terminal status, keeping it as history
+// Returns false if the job does not exist or is already closed
+func (r *PostgresJobsRepository) CloseJob(
+	ctx context.Context,
+	id string,
+	orgID models.OrgID,
+	status models.JobStatus,
+	completionReason string,
+	assignedAgentID string,
+) (bool, error) {
+	db := dbtx.GetTransactional(ctx, r.db)
+	query := fmt.Sprintf(`
+		UPDATE %s.jobs
+		SET status = $3, completion_reason = NULLIF($4, ''), assigned_agent_id = NULLIF($5, ''),
+			completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND status = 'ACTIVE'`, r.schema)
+
+	result, err := db.ExecContext(ctx, query, id, orgID, string(status), completionReason, assignedAgentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to close job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
 func (r *PostgresJobsRepository) DeleteJob(
 	ctx context.Context,
 	id string,
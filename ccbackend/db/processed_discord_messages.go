@@ -27,6 +27,7 @@ var processedDiscordMessagesColumns = []string{
 	"discord_message_id",
 	"discord_thread_id",
 	"text_content",
+	"attachments",
 	"status",
 	"discord_integration_id",
 	"organization_id",
@@ -52,6 +53,7 @@ func (r *PostgresProcessedDiscordMessagesRepository) CreateProcessedDiscordMessa
 		"discord_message_id",
 		"discord_thread_id",
 		"text_content",
+		"attachments",
 		"status",
 		"discord_integration_id",
 		"organization_id",
@@ -63,10 +65,10 @@ func (r *PostgresProcessedDiscordMessagesRepository) CreateProcessedDiscordMessa
 
 	query := fmt.Sprintf(`
 		INSERT INTO %s.processed_discord_messages (%s) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW()) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW()) 
 		RETURNING %s`, r.schema, columnsStr, returningStr)
 
-	err := db.QueryRowxContext(ctx, query, message.ID, message.JobID, message.DiscordMessageID, message.DiscordThreadID, message.TextContent, message.Status, message.DiscordIntegrationID, message.OrgID).
+	err := db.QueryRowxContext(ctx, query, message.ID, message.JobID, message.DiscordMessageID, message.DiscordThreadID, message.TextContent, message.Attachments, message.Status, message.DiscordIntegrationID, message.OrgID).
 		StructScan(message)
 	if err != nil {
 		return fmt.Errorf("failed to create processed discord message: %w", err)
@@ -130,6 +132,29 @@ func (r *PostgresProcessedDiscordMessagesRepository) UpdateProcessedDiscordMessa
 	return message, nil
 }
 
+func (r *PostgresProcessedDiscordMessagesRepository) GetProcessedDiscordMessagesByJobID(
+	ctx context.Context,
+	jobID string,
+	discordIntegrationID string,
+	orgID models.OrgID,
+) ([]*models.ProcessedDiscordMessage, error) {
+	db := dbtx.GetTransactional(ctx, r.db)
+	columnsStr := strings.Join(processedDiscordMessagesColumns, ", ")
+	query := fmt.Sprintf(`
+		SELECT %s 
+		FROM %s.processed_discord_messages 
+		WHERE job_id = $1 AND discord_integration_id = $2 AND organization_id = $3 
+		ORDER BY created_at ASC`, columnsStr, r.schema)
+
+	var messages []*models.ProcessedDiscordMessage
+	err := db.SelectContext(ctx, &messages, query, jobID, discordIntegrationID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get processed discord messages by job ID: %w", err)
+	}
+
+	return messages, nil
+}
+
 func (r *PostgresProcessedDiscordMessagesRepository) GetProcessedMessagesByJobIDAndStatus(
 	ctx context.Context,
 	jobID string,
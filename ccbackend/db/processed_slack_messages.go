@@ -27,6 +27,7 @@ var processedSlackMessagesColumns = []string{
 	"slack_channel_id",
 	"slack_ts",
 	"text_content",
+	"attachments",
 	"status",
 	"slack_integration_id",
 	"organization_id",
@@ -49,6 +50,7 @@ func (r *PostgresProcessedSlackMessagesRepository) CreateProcessedSlackMessage(
 		"slack_channel_id",
 		"slack_ts",
 		"text_content",
+		"attachments",
 		"status",
 		"slack_integration_id",
 		"organization_id",
@@ -60,10 +62,10 @@ func (r *PostgresProcessedSlackMessagesRepository) CreateProcessedSlackMessage(
 
 	query := fmt.Sprintf(`
 		INSERT INTO %s.processed_slack_messages (%s) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW()) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW()) 
 		RETURNING %s`, r.schema, columnsStr, returningStr)
 
-	err := db.QueryRowxContext(ctx, query, message.ID, message.JobID, message.SlackChannelID, message.SlackTS, message.TextContent, message.Status, message.SlackIntegrationID, message.OrgID).
+	err := db.QueryRowxContext(ctx, query, message.ID, message.JobID, message.SlackChannelID, message.SlackTS, message.TextContent, message.Attachments, message.Status, message.SlackIntegrationID, message.OrgID).
 		StructScan(message)
 	if err != nil {
 		return fmt.Errorf("failed to create processed slack message: %w", err)
@@ -137,7 +139,8 @@ func (r *PostgresProcessedSlackMessagesRepository) GetProcessedSlackMessagesByJo
 	query := fmt.Sprintf(`
 		SELECT %s 
 		FROM %s.processed_slack_messages 
-		WHERE job_id = $1 AND slack_integration_id = $2 AND organization_id = $3`, columnsStr, r.schema)
+		WHERE job_id = $1 AND slack_integration_id = $2 AND organization_id = $3
+		ORDER BY slack_ts ASC`, columnsStr, r.schema)
 
 	var messages []*models.ProcessedSlackMessage
 	err := db.SelectContext(ctx, &messages, query, jobID, slackIntegrationID, orgID)
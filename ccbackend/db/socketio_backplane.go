@@ -0,0 +1,244 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
+
+	"ccbackend/models"
+)
+
+type PostgresSocketIOBackplaneRepository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+// Column names for socketio_relayed_messages table
+var socketIORelayedMessagesColumns = []string{
+	"id",
+	"instance_id",
+	"client_id",
+	"kind",
+	"payload",
+	"created_at",
+}
+
+func NewPostgresSocketIOBackplaneRepository(db *sqlx.DB, schema string) *PostgresSocketIOBackplaneRepository {
+	return &PostgresSocketIOBackplaneRepository{db: db, schema: schema}
+}
+
+// UpsertInstanceHeartbeat registers the instance or refreshes its heartbeat timestamp
+// Returns true if the instance row was created, i.e. it did not exist or was removed as stale
+func (r *PostgresSocketIOBackplaneRepository) UpsertInstanceHeartbeat(
+	ctx context.Context,
+	instanceID string,
+) (bool, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.socketio_instances (id, last_heartbeat_at, created_at)
+		VALUES ($1, NOW(), NOW())
+		ON CONFLICT (id) DO UPDATE SET last_heartbeat_at = NOW()
+		RETURNING (xmax = 0) AS inserted`, r.schema)
+
+	var inserted bool
+	if err := r.db.QueryRowxContext(ctx, query, instanceID).Scan(&inserted); err != nil {
+		return false, fmt.Errorf("failed to upsert socketio instance heartbeat: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// DeleteInstance removes the instance - CASCADE DELETE cleans up its connections and relayed messages
+func (r *PostgresSocketIOBackplaneRepository) DeleteInstance(ctx context.Context, instanceID string) error {
+	query := fmt.Sprintf("DELETE FROM %s.socketio_instances WHERE id = $1", r.schema)
+
+	if _, err := r.db.ExecContext(ctx, query, instanceID); err != nil {
+		return fmt.Errorf("failed to delete socketio instance: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteStaleInstances removes instances which have not sent a heartbeat within the given period
+func (r *PostgresSocketIOBackplaneRepository) DeleteStaleInstances(
+	ctx context.Context,
+	staleAfter time.Duration,
+) (int64, error) {
+	query := fmt.Sprintf(`
+		DELETE FROM %s.socketio_instances
+		WHERE last_heartbeat_at < NOW() - INTERVAL '%d seconds'`, r.schema, int(staleAfter.Seconds()))
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale socketio instances: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+func (r *PostgresSocketIOBackplaneRepository) UpsertConnection(
+	ctx context.Context,
+	connection *models.SocketIOConnection,
+) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.socketio_connections (client_id, instance_id, organization_id, ccagent_id, connected_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (client_id)
+		DO UPDATE SET
+			instance_id = EXCLUDED.instance_id,
+			organization_id = EXCLUDED.organization_id,
+			ccagent_id = EXCLUDED.ccagent_id
+		RETURNING connected_at`, r.schema)
+
+	err := r.db.QueryRowxContext(
+		ctx,
+		query,
+		connection.ClientID,
+		connection.InstanceID,
+		connection.OrgID,
+		connection.CCAgentID,
+	).Scan(&connection.ConnectedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert socketio connection: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresSocketIOBackplaneRepository) DeleteConnection(
+	ctx context.Context,
+	clientID, instanceID string,
+) (bool, error) {
+	query := fmt.Sprintf(`
+		DELETE FROM %s.socketio_connections
+		WHERE client_id = $1 AND instance_id = $2`, r.schema)
+
+	result, err := r.db.ExecContext(ctx, query, clientID, instanceID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete socketio connection: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetLiveConnectionIDs returns the client IDs connected to instances with a recent heartbeat
+func (r *PostgresSocketIOBackplaneRepository) GetLiveConnectionIDs(
+	ctx context.Context,
+	staleAfter time.Duration,
+) ([]string, error) {
+	query := fmt.Sprintf(`
+		SELECT c.client_id
+		FROM %s.socketio_connections c
+		JOIN %s.socketio_instances i ON c.instance_id = i.id
+		WHERE i.last_heartbeat_at >= NOW() - INTERVAL '%d seconds'
+		ORDER BY c.connected_at ASC`, r.schema, r.schema, int(staleAfter.Seconds()))
+
+	var clientIDs []string
+	if err := r.db.SelectContext(ctx, &clientIDs, query); err != nil {
+		return nil, fmt.Errorf("failed to get live socketio connection IDs: %w", err)
+	}
+
+	return clientIDs, nil
+}
+
+// GetLiveConnectionInstanceID returns the instance holding the client if that instance has a recent heartbeat
+func (r *PostgresSocketIOBackplaneRepository) GetLiveConnectionInstanceID(
+	ctx context.Context,
+	clientID string,
+	staleAfter time.Duration,
+) (mo.Option[string], error) {
+	query := fmt.Sprintf(`
+		SELECT c.instance_id
+		FROM %s.socketio_connections c
+		JOIN %s.socketio_instances i ON c.instance_id = i.id
+		WHERE c.client_id = $1 AND i.last_heartbeat_at >= NOW() - INTERVAL '%d seconds'`,
+		r.schema, r.schema, int(staleAfter.Seconds()))
+
+	var instanceID string
+	err := r.db.GetContext(ctx, &instanceID, query, clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return mo.None[string](), nil
+		}
+		return mo.None[string](), fmt.Errorf("failed to get socketio connection instance: %w", err)
+	}
+
+	return mo.Some(instanceID), nil
+}
+
+// EnqueueRelayedMessage stores a message for the instance holding the client and notifies that instance
+// on the given LISTEN/NOTIFY channel (the notification payload is the target instance ID)
+func (r *PostgresSocketIOBackplaneRepository) EnqueueRelayedMessage(
+	ctx context.Context,
+	message *models.SocketIORelayedMessage,
+	notifyChannel string,
+) error {
+	insertColumns := []string{"id", "instance_id", "client_id", "kind", "payload", "created_at"}
+	columnsStr := strings.Join(insertColumns, ", ")
+
+	// NOTIFY is delivered on commit, so the message is always visible to the listener by the time it wakes up
+	query := fmt.Sprintf(`
+		WITH inserted AS (
+			INSERT INTO %s.socketio_relayed_messages (%s)
+			VALUES ($1, $2, $3, $4, $5, NOW())
+			RETURNING instance_id, created_at
+		)
+		SELECT created_at, pg_notify($6, instance_id) FROM inserted`, r.schema, columnsStr)
+
+	// Payload is passed as text - lib/pq would encode a byte slice as bytea which JSONB does not accept
+	payload := sql.NullString{String: string(message.Payload), Valid: len(message.Payload) > 0}
+
+	var notifyResult sql.NullString
+	err := r.db.QueryRowxContext(
+		ctx,
+		query,
+		message.ID,
+		message.InstanceID,
+		message.ClientID,
+		message.Kind,
+		payload,
+		notifyChannel,
+	).Scan(&message.CreatedAt, &notifyResult)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue socketio relayed message: %w", err)
+	}
+
+	return nil
+}
+
+// PopRelayedMessages deletes and returns all messages waiting for the instance, oldest first
+func (r *PostgresSocketIOBackplaneRepository) PopRelayedMessages(
+	ctx context.Context,
+	instanceID string,
+) ([]*models.SocketIORelayedMessage, error) {
+	returningStr := strings.Join(socketIORelayedMessagesColumns, ", ")
+	query := fmt.Sprintf(`
+		WITH deleted AS (
+			DELETE FROM %s.socketio_relayed_messages
+			WHERE instance_id = $1
+			RETURNING %s
+		)
+		SELECT %s FROM deleted
+		ORDER BY created_at ASC, id ASC`, r.schema, returningStr, returningStr)
+
+	var messages []*models.SocketIORelayedMessage
+	if err := r.db.SelectContext(ctx, &messages, query, instanceID); err != nil {
+		return nil, fmt.Errorf("failed to pop socketio relayed messages: %w", err)
+	}
+
+	return messages, nil
+}
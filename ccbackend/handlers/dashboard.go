@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"ccbackend/appctx"
+	"ccbackend/core"
 	"ccbackend/models"
 	"ccbackend/services"
 )
@@ -19,7 +21,9 @@ type DashboardAPIHandler struct {
 	ccAgentContainerService    services.CCAgentContainerIntegrationsService
 	organizationsService       services.OrganizationsService
 	agentsService              services.AgentsService
+	connectedChannelsService   services.ConnectedChannelsService
 	settingsService            services.SettingsService
+	jobsService                services.JobsService
 	txManager                  services.TransactionManager
 }
 
@@ -32,7 +36,9 @@ func NewDashboardAPIHandler(
 	ccAgentContainerService services.CCAgentContainerIntegrationsService,
 	organizationsService services.OrganizationsService,
 	agentsService services.AgentsService,
+	connectedChannelsService services.ConnectedChannelsService,
 	settingsService services.SettingsService,
+	jobsService services.JobsService,
 	txManager services.TransactionManager,
 ) *DashboardAPIHandler {
 	return &DashboardAPIHandler{
@@ -44,7 +50,9 @@ func NewDashboardAPIHandler(
 		ccAgentContainerService:    ccAgentContainerService,
 		organizationsService:       organizationsService,
 		agentsService:              agentsService,
+		connectedChannelsService:   connectedChannelsService,
 		settingsService:            settingsService,
+		jobsService:                jobsService,
 		txManager:                  txManager,
 	}
 }
@@ -169,6 +177,286 @@ func (h *DashboardAPIHandler) DeleteDiscordIntegration(ctx context.Context, inte
 	return nil
 }
 
+// UpdateConnectedChannelAgentSelector sets the labels agents must have to pick up jobs from a connected channel
+func (h *DashboardAPIHandler) UpdateConnectedChannelAgentSelector(
+	ctx context.Context,
+	channelID string,
+	agentSelector models.AgentLabels,
+) (models.ConnectedChannel, error) {
+	log.Printf("🏷️ Updating agent selector for connected channel: %s", channelID)
+	org, ok := appctx.GetOrganization(ctx)
+	if !ok {
+		return nil, fmt.Errorf("organization not found in context")
+	}
+
+	channel, err := h.connectedChannelsService.UpdateAgentSelector(ctx, models.OrgID(org.ID), channelID, agentSelector)
+	if err != nil {
+		log.Printf("❌ Failed to update agent selector for connected channel: %v", err)
+		return nil, err
+	}
+
+	log.Printf("✅ Agent selector updated successfully for connected channel: %s", channelID)
+	return channel, nil
+}
+
+// ListAgents returns all agents registered for the organization
+func (h *DashboardAPIHandler) ListAgents(ctx context.Context) ([]*models.ActiveAgent, error) {
+	org, ok := appctx.GetOrganization(ctx)
+	if !ok {
+		return nil, fmt.Errorf("organization not found in context")
+	}
+
+	agents, err := h.agentsService.GetAllActiveAgents(ctx, models.OrgID(org.ID))
+	if err != nil {
+		log.Printf("❌ Failed to list agents: %v", err)
+		return nil, err
+	}
+
+	log.Printf("📋 Retrieved %d agents for organization: %s", len(agents), org.ID)
+	return agents, nil
+}
+
+// ListAgentMessages returns the delivery state of the organization's most recent messages to agents
+// An empty jobID returns messages of all jobs
+func (h *DashboardAPIHandler) ListAgentMessages(
+	ctx context.Context,
+	jobID string,
+) ([]*models.AgentOutboxMessage, error) {
+	org, ok := appctx.GetOrganization(ctx)
+	if !ok {
+		return nil, fmt.Errorf("organization not found in context")
+	}
+
+	messages, err := h.agentsService.GetAgentMessages(ctx, models.OrgID(org.ID), jobID)
+	if err != nil {
+		log.Printf("❌ Failed to list agent messages: %v", err)
+		return nil, err
+	}
+
+	log.Printf("📋 Retrieved %d agent messages for organization: %s", len(messages), org.ID)
+	return messages, nil
+}
+
+// ListJobs returns a page of the organization's jobs matching the filter, closed jobs included, newest first
+func (h *DashboardAPIHandler) ListJobs(
+	ctx context.Context,
+	filter models.JobsFilter,
+	limit int,
+) (*models.JobsPage, error) {
+	org, ok := appctx.GetOrganization(ctx)
+	if !ok {
+		return nil, fmt.Errorf("organization not found in context")
+	}
+
+	page, err := h.jobsService.ListJobs(ctx, models.OrgID(org.ID), filter, limit)
+	if err != nil {
+		log.Printf("❌ Failed to list jobs: %v", err)
+		return nil, err
+	}
+
+	log.Printf("📋 Retrieved %d jobs for organization: %s", len(page.Jobs), org.ID)
+	return page, nil
+}
+
+// GetJob returns a job with its processed messages and assignment history - closed jobs included
+func (h *DashboardAPIHandler) GetJob(ctx context.Context, jobID string) (*models.JobDetail, error) {
+	org, ok := appctx.GetOrganization(ctx)
+	if !ok {
+		return nil, fmt.Errorf("organization not found in context")
+	}
+
+	maybeDetail, err := h.jobsService.GetJobDetail(ctx, models.OrgID(org.ID), jobID)
+	if err != nil {
+		log.Printf("❌ Failed to get job: %v", err)
+		return nil, err
+	}
+	detail, ok := maybeDetail.Get()
+	if !ok {
+		log.Printf("❌ Job not found: %s", jobID)
+		return nil, fmt.Errorf("job %s: %w", jobID, core.ErrNotFound)
+	}
+
+	detail.AgentMessages, err = h.agentsService.GetAgentMessages(ctx, models.OrgID(org.ID), jobID)
+	if err != nil {
+		log.Printf("❌ Failed to get agent messages of job: %v", err)
+		return nil, err
+	}
+
+	log.Printf("📋 Retrieved job: %s", jobID)
+	return detail, nil
+}
+
+// GetJobTranscript returns every message exchanged in a job's thread, oldest first - closed jobs included
+func (h *DashboardAPIHandler) GetJobTranscript(
+	ctx context.Context,
+	jobID string,
+) ([]*models.JobTranscriptMessage, error) {
+	org, ok := appctx.GetOrganization(ctx)
+	if !ok {
+		return nil, fmt.Errorf("organization not found in context")
+	}
+
+	maybeTranscript, err := h.jobsService.GetJobTranscript(ctx, models.OrgID(org.ID), jobID)
+	if err != nil {
+		log.Printf("❌ Failed to get job transcript: %v", err)
+		return nil, err
+	}
+	transcript, ok := maybeTranscript.Get()
+	if !ok {
+		log.Printf("❌ Job not found: %s", jobID)
+		return nil, fmt.Errorf("job %s: %w", jobID, core.ErrNotFound)
+	}
+
+	log.Printf("📋 Retrieved %d transcript messages of job: %s", len(transcript), jobID)
+	return transcript, nil
+}
+
+// SetAgentDraining starts or stops draining an agent - draining agents finish their jobs but get no new ones
+func (h *DashboardAPIHandler) SetAgentDraining(
+	ctx context.Context,
+	agentID string,
+	draining bool,
+) (*models.ActiveAgent, error) {
+	log.Printf("🚰 Setting draining=%t for agent: %s", draining, agentID)
+	org, ok := appctx.GetOrganization(ctx)
+	if !ok {
+		return nil, fmt.Errorf("organization not found in context")
+	}
+
+	agent, err := h.agentsService.SetAgentDraining(ctx, models.OrgID(org.ID), agentID, draining)
+	if err != nil {
+		log.Printf("❌ Failed to set agent draining: %v", err)
+		return nil, err
+	}
+
+	log.Printf("✅ Agent draining updated successfully: %s (draining=%t)", agentID, draining)
+	return agent, nil
+}
+
+// RedeployCCAgentContainer redeploys a CCAgent container right away, interrupting the jobs of its agents
+func (h *DashboardAPIHandler) RedeployCCAgentContainer(ctx context.Context, integrationID string) error {
+	org, ok := appctx.GetOrganization(ctx)
+	if !ok {
+		return fmt.Errorf("organization not found in context")
+	}
+
+	return h.ccAgentContainerService.RedeployCCAgentContainer(ctx, models.OrgID(org.ID), integrationID, false)
+}
+
+// StartDrainedCCAgentContainerRedeploy drains the agents on a CCAgent container's repository and redeploys the
+// container in the background once they have finished their jobs, waiting at most drainTimeout
+// The progress is tracked in the integration's redeploy status, which is returned as it is when draining starts
+func (h *DashboardAPIHandler) StartDrainedCCAgentContainerRedeploy(
+	ctx context.Context,
+	integrationID string,
+	drainTimeout time.Duration,
+) (*models.CCAgentContainerIntegration, error) {
+	org, ok := appctx.GetOrganization(ctx)
+	if !ok {
+		return nil, fmt.Errorf("organization not found in context")
+	}
+	orgID := models.OrgID(org.ID)
+
+	integration, err := h.ccAgentContainerService.StartCCAgentContainerRedeploy(ctx, orgID, integrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	drainedAgents, err := h.agentsService.DrainAgentsOnRepo(ctx, orgID, integration.RepoURL)
+	if err != nil {
+		err = fmt.Errorf("failed to drain agents before redeploy: %w", err)
+		h.failCCAgentContainerRedeploy(ctx, orgID, integrationID, drainedAgents, err)
+		return nil, err
+	}
+
+	// The redeploy outlives the request which started it
+	go h.drainAndRedeployCCAgentContainer(context.WithoutCancel(ctx), orgID, integration, drainedAgents, drainTimeout)
+
+	return integration, nil
+}
+
+// drainAndRedeployCCAgentContainer waits for the draining agents on the container's repository to finish their jobs,
+// redeploys the container and returns the agents to service, recording its progress on the integration
+func (h *DashboardAPIHandler) drainAndRedeployCCAgentContainer(
+	ctx context.Context,
+	orgID models.OrgID,
+	integration *models.CCAgentContainerIntegration,
+	drainedAgents []*models.ActiveAgent,
+	drainTimeout time.Duration,
+) {
+	log.Printf("🚰 Waiting up to %s for agents on %s to drain before redeploy", drainTimeout, integration.RepoURL)
+	if _, err := h.agentsService.WaitForDrainingAgents(ctx, orgID, integration.RepoURL, drainTimeout); err != nil {
+		err = fmt.Errorf("failed to drain agents before redeploy: %w", err)
+		h.failCCAgentContainerRedeploy(ctx, orgID, integration.ID, drainedAgents, err)
+		return
+	}
+
+	if err := h.ccAgentContainerService.UpdateCCAgentContainerRedeployStatus(
+		ctx,
+		orgID,
+		integration.ID,
+		models.CCAgentRedeployStatusRedeploying,
+		"",
+	); err != nil {
+		log.Printf("⚠️ Failed to record redeploy progress of CCAgent container %s: %v", integration.ID, err)
+	}
+
+	if err := h.ccAgentContainerService.RedeployCCAgentContainer(ctx, orgID, integration.ID, false); err != nil {
+		h.failCCAgentContainerRedeploy(ctx, orgID, integration.ID, drainedAgents, err)
+		return
+	}
+
+	// Redeployed agents may reconnect under the same ID, so they must not stay excluded from new work
+	// Agents which were drained by hand before the redeploy stay drained
+	h.stopDrainingAgents(ctx, orgID, drainedAgents)
+
+	if err := h.ccAgentContainerService.UpdateCCAgentContainerRedeployStatus(
+		ctx,
+		orgID,
+		integration.ID,
+		models.CCAgentRedeployStatusCompleted,
+		"",
+	); err != nil {
+		log.Printf("⚠️ Failed to record redeploy completion of CCAgent container %s: %v", integration.ID, err)
+		return
+	}
+	log.Printf("✅ CCAgent container redeployed successfully after draining: %s", integration.ID)
+}
+
+// failCCAgentContainerRedeploy returns the agents drained for the redeploy to service and records the failure
+func (h *DashboardAPIHandler) failCCAgentContainerRedeploy(
+	ctx context.Context,
+	orgID models.OrgID,
+	integrationID string,
+	drainedAgents []*models.ActiveAgent,
+	redeployErr error,
+) {
+	log.Printf("❌ Failed to redeploy CCAgent container %s: %v", integrationID, redeployErr)
+	h.stopDrainingAgents(ctx, orgID, drainedAgents)
+
+	if err := h.ccAgentContainerService.UpdateCCAgentContainerRedeployStatus(
+		ctx,
+		orgID,
+		integrationID,
+		models.CCAgentRedeployStatusFailed,
+		redeployErr.Error(),
+	); err != nil {
+		log.Printf("⚠️ Failed to record redeploy failure of CCAgent container %s: %v", integrationID, err)
+	}
+}
+
+func (h *DashboardAPIHandler) stopDrainingAgents(
+	ctx context.Context,
+	orgID models.OrgID,
+	agents []*models.ActiveAgent,
+) {
+	for _, agent := range agents {
+		if _, err := h.agentsService.SetAgentDraining(ctx, orgID, agent.ID, false); err != nil {
+			log.Printf("⚠️ Failed to stop draining agent %s after redeploy: %v", agent.ID, err)
+		}
+	}
+}
+
 // GenerateCCAgentSecretKey generates a new secret key for an organization
 func (h *DashboardAPIHandler) GenerateCCAgentSecretKey(ctx context.Context) (string, error) {
 	org, ok := appctx.GetOrganization(ctx)
@@ -2,9 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -15,6 +20,9 @@ import (
 	"ccbackend/models/api"
 )
 
+// Upper bound for how long a redeploy request may wait for draining agents to finish their jobs
+const maxDrainTimeoutSeconds = 3600
+
 type DashboardHTTPHandler struct {
 	handler *DashboardAPIHandler
 }
@@ -57,6 +65,14 @@ type UpsertSettingRequest struct {
 	Value       any                `json:"value"`
 }
 
+type UpdateAgentSelectorRequest struct {
+	AgentSelector models.AgentLabels `json:"agent_selector"`
+}
+
+type UpdateAgentDrainingRequest struct {
+	Draining *bool `json:"draining"`
+}
+
 type GetSettingResponse struct {
 	Key         string             `json:"key"`
 	SettingType models.SettingType `json:"settingType"`
@@ -730,9 +746,49 @@ func (h *DashboardHTTPHandler) HandleRedeployCCAgentContainer(w http.ResponseWri
 	vars := mux.Vars(r)
 	integrationID := vars["id"]
 
-	log.Printf("🚀 Redeploy CCAgent container request received for integration: %s, org: %s", integrationID, org.ID)
+	// With a drain timeout, the agents finish their jobs first and the redeploy runs in the background
+	var drainTimeout time.Duration
+	if drainTimeoutStr := r.URL.Query().Get("drain_timeout_seconds"); drainTimeoutStr != "" {
+		drainTimeoutSeconds, err := strconv.Atoi(drainTimeoutStr)
+		if err != nil || drainTimeoutSeconds < 0 || drainTimeoutSeconds > maxDrainTimeoutSeconds {
+			http.Error(
+				w,
+				fmt.Sprintf("drain_timeout_seconds must be an integer between 0 and %d", maxDrainTimeoutSeconds),
+				http.StatusBadRequest,
+			)
+			return
+		}
+		drainTimeout = time.Duration(drainTimeoutSeconds) * time.Second
+	}
+
+	log.Printf(
+		"🚀 Redeploy CCAgent container request received for integration: %s, org: %s, drain timeout: %s",
+		integrationID,
+		org.ID,
+		drainTimeout,
+	)
+
+	if drainTimeout > 0 {
+		integration, err := h.handler.StartDrainedCCAgentContainerRedeploy(ctx, integrationID, drainTimeout)
+		if err != nil {
+			log.Printf("❌ Failed to start drained redeploy of CCAgent container: %v", err)
+			switch {
+			case errors.Is(err, core.ErrNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, core.ErrRedeployInProgress):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			}
+			return
+		}
 
-	if err := h.handler.ccAgentContainerService.RedeployCCAgentContainer(ctx, models.OrgID(org.ID), integrationID, false); err != nil {
+		log.Printf("✅ CCAgent container redeploy started, draining agents first: %s", integrationID)
+		h.writeJSONResponse(w, http.StatusAccepted, integration)
+		return
+	}
+
+	if err := h.handler.RedeployCCAgentContainer(ctx, integrationID); err != nil {
 		log.Printf("❌ Failed to redeploy CCAgent container: %v", err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -744,6 +800,205 @@ func (h *DashboardHTTPHandler) HandleRedeployCCAgentContainer(w http.ResponseWri
 	})
 }
 
+func (h *DashboardHTTPHandler) HandleListAgents(w http.ResponseWriter, r *http.Request) {
+	log.Printf("📋 List agents request received from %s", r.RemoteAddr)
+
+	agents, err := h.handler.ListAgents(r.Context())
+	if err != nil {
+		log.Printf("❌ Failed to list agents: %v", err)
+		http.Error(w, "failed to list agents", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, agents)
+}
+
+func (h *DashboardHTTPHandler) HandleListAgentMessages(w http.ResponseWriter, r *http.Request) {
+	log.Printf("📋 List agent messages request received from %s", r.RemoteAddr)
+
+	jobID := r.URL.Query().Get("job_id")
+	if jobID != "" && !core.IsValidULID(jobID) {
+		log.Printf("❌ Invalid job ID in query: %s", jobID)
+		http.Error(w, "job_id must be a valid ULID", http.StatusBadRequest)
+		return
+	}
+
+	messages, err := h.handler.ListAgentMessages(r.Context(), jobID)
+	if err != nil {
+		log.Printf("❌ Failed to list agent messages: %v", err)
+		http.Error(w, "failed to list agent messages", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, messages)
+}
+
+func (h *DashboardHTTPHandler) HandleListJobs(w http.ResponseWriter, r *http.Request) {
+	log.Printf("📋 List jobs request received from %s", r.RemoteAddr)
+
+	filter, limit, err := parseJobsQuery(r.URL.Query())
+	if err != nil {
+		log.Printf("❌ Invalid jobs query: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.handler.ListJobs(r.Context(), filter, limit)
+	if err != nil {
+		log.Printf("❌ Failed to list jobs: %v", err)
+		http.Error(w, "failed to list jobs", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, page)
+}
+
+// parseJobsQuery reads the filters and page size of a job listing from the query parameters
+func parseJobsQuery(query url.Values) (models.JobsFilter, int, error) {
+	filter := models.JobsFilter{
+		ChannelID: query.Get("channel_id"),
+		CreatorID: query.Get("creator_id"),
+		Cursor:    query.Get("cursor"),
+	}
+
+	if platform := query.Get("platform"); platform != "" {
+		filter.JobType = models.JobType(platform)
+		if filter.JobType != models.JobTypeSlack && filter.JobType != models.JobTypeDiscord {
+			return filter, 0, fmt.Errorf("platform must be slack or discord")
+		}
+	}
+	if status := query.Get("status"); status != "" {
+		filter.Status = models.JobStatus(strings.ToUpper(status))
+		if filter.Status != models.JobStatusActive && !filter.Status.IsClosed() {
+			return filter, 0, fmt.Errorf("status must be one of ACTIVE, COMPLETED, ABANDONED, CANCELLED, FAILED")
+		}
+	}
+	if filter.AgentID = query.Get("agent_id"); filter.AgentID != "" && !core.IsValidULID(filter.AgentID) {
+		return filter, 0, fmt.Errorf("agent_id must be a valid ULID")
+	}
+	if filter.Cursor != "" && !core.IsValidULID(filter.Cursor) {
+		return filter, 0, fmt.Errorf("cursor must be a valid job ID")
+	}
+	var err error
+	if filter.CreatedAfter, err = parseTimeQueryParam(query, "created_after"); err != nil {
+		return filter, 0, err
+	}
+	if filter.CreatedBefore, err = parseTimeQueryParam(query, "created_before"); err != nil {
+		return filter, 0, err
+	}
+
+	limit := models.DefaultJobsPageSize
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > models.MaxJobsPageSize {
+			return filter, 0, fmt.Errorf("limit must be an integer between 1 and %d", models.MaxJobsPageSize)
+		}
+	}
+
+	return filter, limit, nil
+}
+
+// parseTimeQueryParam reads an optional RFC 3339 timestamp from the query parameters
+func parseTimeQueryParam(query url.Values, name string) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+	return &parsed, nil
+}
+
+func (h *DashboardHTTPHandler) HandleGetJob(w http.ResponseWriter, r *http.Request) {
+	log.Printf("📋 Get job request received from %s", r.RemoteAddr)
+
+	vars := mux.Vars(r)
+	jobID, ok := vars["id"]
+	if !ok || !core.IsValidULID(jobID) {
+		log.Printf("❌ Missing or invalid job ID in URL path")
+		http.Error(w, "job ID must be a valid ULID", http.StatusBadRequest)
+		return
+	}
+
+	detail, err := h.handler.GetJob(r.Context(), jobID)
+	if err != nil {
+		log.Printf("❌ Failed to get job: %v", err)
+		if errors.Is(err, core.ErrNotFound) {
+			http.Error(w, "job not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to get job", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, detail)
+}
+
+func (h *DashboardHTTPHandler) HandleGetJobTranscript(w http.ResponseWriter, r *http.Request) {
+	log.Printf("📋 Get job transcript request received from %s", r.RemoteAddr)
+
+	vars := mux.Vars(r)
+	jobID, ok := vars["id"]
+	if !ok || !core.IsValidULID(jobID) {
+		log.Printf("❌ Missing or invalid job ID in URL path")
+		http.Error(w, "job ID must be a valid ULID", http.StatusBadRequest)
+		return
+	}
+
+	transcript, err := h.handler.GetJobTranscript(r.Context(), jobID)
+	if err != nil {
+		log.Printf("❌ Failed to get job transcript: %v", err)
+		if errors.Is(err, core.ErrNotFound) {
+			http.Error(w, "job not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to get job transcript", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, transcript)
+}
+
+func (h *DashboardHTTPHandler) HandleUpdateAgentDraining(w http.ResponseWriter, r *http.Request) {
+	log.Printf("🚰 Update agent draining request received from %s", r.RemoteAddr)
+
+	vars := mux.Vars(r)
+	agentID, ok := vars["id"]
+	if !ok || !core.IsValidULID(agentID) {
+		log.Printf("❌ Missing or invalid agent ID in URL path")
+		http.Error(w, "agent ID must be a valid ULID", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateAgentDrainingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("❌ Failed to parse request body: %v", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Draining == nil {
+		log.Printf("❌ Missing draining in request")
+		http.Error(w, "draining is required", http.StatusBadRequest)
+		return
+	}
+
+	agent, err := h.handler.SetAgentDraining(r.Context(), agentID, *req.Draining)
+	if err != nil {
+		log.Printf("❌ Failed to update agent draining: %v", err)
+		if errors.Is(err, core.ErrNotFound) {
+			http.Error(w, "agent not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to update agent draining", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("✅ Agent draining updated successfully: %s", agentID)
+	h.writeJSONResponse(w, http.StatusOK, agent)
+}
+
 func (h *DashboardHTTPHandler) HandleListGitHubRepositories(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	org, ok := appctx.GetOrganization(ctx)
@@ -840,6 +1095,43 @@ func (h *DashboardHTTPHandler) HandleGetSetting(w http.ResponseWriter, r *http.R
 	h.writeJSONResponse(w, http.StatusOK, response)
 }
 
+func (h *DashboardHTTPHandler) HandleUpdateConnectedChannelAgentSelector(w http.ResponseWriter, r *http.Request) {
+	log.Printf("🏷️ Update connected channel agent selector request received from %s", r.RemoteAddr)
+
+	vars := mux.Vars(r)
+	channelID, ok := vars["id"]
+	if !ok || !core.IsValidULID(channelID) {
+		log.Printf("❌ Missing or invalid connected channel ID in URL path")
+		http.Error(w, "connected channel ID must be a valid ULID", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateAgentSelectorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("❌ Failed to parse request body: %v", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	channel, err := h.handler.UpdateConnectedChannelAgentSelector(r.Context(), channelID, req.AgentSelector)
+	if err != nil {
+		log.Printf("❌ Failed to update connected channel agent selector: %v", err)
+		if errors.Is(err, core.ErrNotFound) {
+			http.Error(w, "connected channel not found", http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "cannot be empty") {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "failed to update connected channel agent selector", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("✅ Connected channel agent selector updated successfully: %s", channelID)
+	h.writeJSONResponse(w, http.StatusOK, channel)
+}
+
 type endpointConfig struct {
 	path    string
 	handler http.HandlerFunc
@@ -932,6 +1224,16 @@ func (h *DashboardHTTPHandler) initEndpoints(middleware middlewareFunc) []endpoi
 		},
 		{"/ccagents/{id}/redeploy", middleware(h.HandleRedeployCCAgentContainer), "POST", "/ccagents/{id}/redeploy"},
 
+		// Agent endpoints
+		{"/agents", middleware(h.HandleListAgents), "GET", "/agents"},
+		{"/agents/{id}/draining", middleware(h.HandleUpdateAgentDraining), "PUT", "/agents/{id}/draining"},
+		{"/agent-messages", middleware(h.HandleListAgentMessages), "GET", "/agent-messages"},
+
+		// Job endpoints
+		{"/jobs", middleware(h.HandleListJobs), "GET", "/jobs"},
+		{"/jobs/{id}", middleware(h.HandleGetJob), "GET", "/jobs/{id}"},
+		{"/jobs/{id}/transcript", middleware(h.HandleGetJobTranscript), "GET", "/jobs/{id}/transcript"},
+
 		// Organization endpoints
 		{"/organizations", middleware(h.HandleGetOrganization), "GET", "/organizations"},
 		{
@@ -944,6 +1246,14 @@ func (h *DashboardHTTPHandler) initEndpoints(middleware middlewareFunc) []endpoi
 		// Settings endpoints
 		{"/settings", middleware(h.HandleUpsertSetting), "POST", "/settings"},
 		{"/settings/{key}", middleware(h.HandleGetSetting), "GET", "/settings/{key}"},
+
+		// Connected channels endpoints
+		{
+			"/connected-channels/{id}/agent-selector",
+			middleware(h.HandleUpdateConnectedChannelAgentSelector),
+			"PUT",
+			"/connected-channels/{id}/agent-selector",
+		},
 	}
 }
 
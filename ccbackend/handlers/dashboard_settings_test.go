@@ -90,7 +90,9 @@ func TestDashboardAPIHandler_UpsertSetting(t *testing.T) {
 				mockCCAgentContainerIntegrationsService,
 				mockOrganizationsService,
 				mockAgentsService,
+				nil, // connectedChannelsService
 				mockSettingsService,
+				nil, // jobsService
 				mockTxManager,
 			)
 
@@ -177,7 +179,9 @@ func TestDashboardAPIHandler_GetSetting(t *testing.T) {
 				mockCCAgentContainerIntegrationsService,
 				mockOrganizationsService,
 				mockAgentsService,
+				nil, // connectedChannelsService
 				mockSettingsService,
+				nil, // jobsService
 				mockTxManager,
 			)
 
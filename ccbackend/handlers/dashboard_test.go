@@ -7,14 +7,18 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/samber/mo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"ccbackend/appctx"
+	"ccbackend/core"
 	"ccbackend/models"
 	"ccbackend/models/api"
 	agents "ccbackend/services/agents"
@@ -22,6 +26,7 @@ import (
 	ccagentcontainerintegrations "ccbackend/services/ccagent_container_integrations"
 	discordintegrations "ccbackend/services/discord_integrations"
 	githubintegrations "ccbackend/services/github_integrations"
+	"ccbackend/services/jobs"
 	organizations "ccbackend/services/organizations"
 	settingsservice "ccbackend/services/settings"
 	slackintegrations "ccbackend/services/slack_integrations"
@@ -135,7 +140,9 @@ func TestDashboardAPIHandler_ListSlackIntegrations(t *testing.T) {
 				mockCCAgentContainerIntegrationsService,
 				mockOrganizationsService,
 				mockAgentsService,
+				nil, // connectedChannelsService
 				mockSettingsService,
+				nil, // jobsService
 				mockTxManager,
 			)
 
@@ -204,7 +211,9 @@ func TestDashboardAPIHandler_CreateSlackIntegration(t *testing.T) {
 				mockCCAgentContainerIntegrationsService,
 				mockOrganizationsService,
 				mockAgentsService,
+				nil, // connectedChannelsService
 				mockSettingsService,
+				nil, // jobsService
 				mockTxManager,
 			)
 
@@ -286,7 +295,9 @@ func TestDashboardAPIHandler_DeleteSlackIntegration(t *testing.T) {
 				mockCCAgentContainerIntegrationsService,
 				mockOrganizationsService,
 				mockAgentsService,
+				nil, // connectedChannelsService
 				mockSettingsService,
+				nil, // jobsService
 				mockTxManager,
 			)
 
@@ -364,7 +375,9 @@ func TestDashboardAPIHandler_GenerateCCAgentSecretKey(t *testing.T) {
 				mockCCAgentContainerIntegrationsService,
 				mockOrganizationsService,
 				mockAgentsService,
+				nil, // connectedChannelsService
 				mockSettingsService,
+				nil, // jobsService
 				mockTxManager,
 			)
 
@@ -389,6 +402,180 @@ func TestDashboardAPIHandler_GenerateCCAgentSecretKey(t *testing.T) {
 
 // HTTP Handler Tests
 
+func TestDashboardAPIHandler_RedeployCCAgentContainer(t *testing.T) {
+	ctx := contextWithUser(testUser)
+	orgID := models.OrgID(testOrg.ID)
+	integrationID := "cci_01234567890123456789012345"
+
+	mockCCAgentContainerIntegrationsService := &ccagentcontainerintegrations.MockCCAgentContainerIntegrationsService{}
+	mockCCAgentContainerIntegrationsService.On("RedeployCCAgentContainer", ctx, orgID, integrationID, false).Return(nil)
+
+	handler := NewDashboardAPIHandler(
+		&users.MockUsersService{},
+		&slackintegrations.MockSlackIntegrationsService{},
+		&discordintegrations.MockDiscordIntegrationsService{},
+		&githubintegrations.MockGitHubIntegrationsService{},
+		&anthropicintegrations.MockAnthropicIntegrationsService{},
+		mockCCAgentContainerIntegrationsService,
+		&organizations.MockOrganizationsService{},
+		&agents.MockAgentsService{},
+		nil, // connectedChannelsService
+		&settingsservice.MockSettingsService{},
+		nil, // jobsService
+		&txmanager.MockTransactionManager{},
+	)
+
+	err := handler.RedeployCCAgentContainer(ctx, integrationID)
+
+	require.NoError(t, err)
+	mockCCAgentContainerIntegrationsService.AssertExpectations(t)
+}
+
+func TestDashboardAPIHandler_StartDrainedCCAgentContainerRedeploy(t *testing.T) {
+	ctx := contextWithUser(testUser)
+	orgID := models.OrgID(testOrg.ID)
+	integrationID := "cci_01234567890123456789012345"
+	draining := models.CCAgentRedeployStatusDraining
+	integration := &models.CCAgentContainerIntegration{
+		ID:             integrationID,
+		RepoURL:        "github.com/acme/backend",
+		OrgID:          orgID,
+		RedeployStatus: &draining,
+	}
+	drainedAgent := &models.ActiveAgent{ID: "ag_01234567890123456789012345", Draining: true}
+	alreadyDrainedAgent := &models.ActiveAgent{ID: "ag_01234567890123456789012346", Draining: true}
+
+	tests := []struct {
+		name      string
+		mockSetup func(
+			*ccagentcontainerintegrations.MockCCAgentContainerIntegrationsService,
+			*agents.MockAgentsService,
+			chan struct{},
+		)
+		expectedError string
+	}{
+		{
+			name: "success - redeploys in the background once the agents have drained",
+			// alreadyDrainedAgent was drained by hand before the redeploy and stays drained
+			mockSetup: func(
+				c *ccagentcontainerintegrations.MockCCAgentContainerIntegrationsService,
+				a *agents.MockAgentsService,
+				done chan struct{},
+			) {
+				c.On("StartCCAgentContainerRedeploy", ctx, orgID, integrationID).Return(integration, nil)
+				a.On("DrainAgentsOnRepo", ctx, orgID, integration.RepoURL).
+					Return([]*models.ActiveAgent{drainedAgent}, nil)
+				a.On("WaitForDrainingAgents", mock.Anything, orgID, integration.RepoURL, time.Minute).
+					Return([]*models.ActiveAgent{drainedAgent, alreadyDrainedAgent}, nil)
+				c.On(
+					"UpdateCCAgentContainerRedeployStatus",
+					mock.Anything,
+					orgID,
+					integrationID,
+					models.CCAgentRedeployStatusRedeploying,
+					"",
+				).Return(nil)
+				c.On("RedeployCCAgentContainer", mock.Anything, orgID, integrationID, false).Return(nil)
+				a.On("SetAgentDraining", mock.Anything, orgID, drainedAgent.ID, false).
+					Return(&models.ActiveAgent{ID: drainedAgent.ID}, nil)
+				c.On(
+					"UpdateCCAgentContainerRedeployStatus",
+					mock.Anything,
+					orgID,
+					integrationID,
+					models.CCAgentRedeployStatusCompleted,
+					"",
+				).Return(nil).Run(func(mock.Arguments) { close(done) })
+			},
+			expectedError: "",
+		},
+		{
+			name: "failure - agents which do not drain in time are returned to service",
+			mockSetup: func(
+				c *ccagentcontainerintegrations.MockCCAgentContainerIntegrationsService,
+				a *agents.MockAgentsService,
+				done chan struct{},
+			) {
+				c.On("StartCCAgentContainerRedeploy", ctx, orgID, integrationID).Return(integration, nil)
+				a.On("DrainAgentsOnRepo", ctx, orgID, integration.RepoURL).
+					Return([]*models.ActiveAgent{drainedAgent}, nil)
+				a.On("WaitForDrainingAgents", mock.Anything, orgID, integration.RepoURL, time.Minute).
+					Return(nil, fmt.Errorf("timed out after 1m0s waiting for 1 draining agents to finish their jobs"))
+				a.On("SetAgentDraining", mock.Anything, orgID, drainedAgent.ID, false).
+					Return(&models.ActiveAgent{ID: drainedAgent.ID}, nil)
+				c.On(
+					"UpdateCCAgentContainerRedeployStatus",
+					mock.Anything,
+					orgID,
+					integrationID,
+					models.CCAgentRedeployStatusFailed,
+					mock.MatchedBy(func(redeployError string) bool {
+						return strings.Contains(redeployError, "failed to drain agents before redeploy")
+					}),
+				).Return(nil).Run(func(mock.Arguments) { close(done) })
+			},
+			expectedError: "",
+		},
+		{
+			name: "error - another redeploy is in progress",
+			mockSetup: func(
+				c *ccagentcontainerintegrations.MockCCAgentContainerIntegrationsService,
+				a *agents.MockAgentsService,
+				done chan struct{},
+			) {
+				c.On("StartCCAgentContainerRedeploy", ctx, orgID, integrationID).
+					Return(nil, fmt.Errorf("integration %s: %w", integrationID, core.ErrRedeployInProgress))
+				close(done)
+			},
+			expectedError: "redeploy already in progress",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAgentsService := &agents.MockAgentsService{}
+			mockCCAgentContainerIntegrationsService := &ccagentcontainerintegrations.MockCCAgentContainerIntegrationsService{}
+			done := make(chan struct{})
+			tt.mockSetup(mockCCAgentContainerIntegrationsService, mockAgentsService, done)
+
+			handler := NewDashboardAPIHandler(
+				&users.MockUsersService{},
+				&slackintegrations.MockSlackIntegrationsService{},
+				&discordintegrations.MockDiscordIntegrationsService{},
+				&githubintegrations.MockGitHubIntegrationsService{},
+				&anthropicintegrations.MockAnthropicIntegrationsService{},
+				mockCCAgentContainerIntegrationsService,
+				&organizations.MockOrganizationsService{},
+				mockAgentsService,
+				nil, // connectedChannelsService
+				&settingsservice.MockSettingsService{},
+				nil, // jobsService
+				&txmanager.MockTransactionManager{},
+			)
+
+			result, err := handler.StartDrainedCCAgentContainerRedeploy(ctx, integrationID, time.Minute)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				mockAgentsService.AssertNotCalled(t, "DrainAgentsOnRepo", ctx, orgID, integration.RepoURL)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, integration, result)
+			}
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("redeploy did not finish in the background")
+			}
+			mockCCAgentContainerIntegrationsService.AssertExpectations(t)
+			mockAgentsService.AssertExpectations(t)
+			mockAgentsService.AssertNotCalled(t, "SetAgentDraining", mock.Anything, orgID, alreadyDrainedAgent.ID, false)
+		})
+	}
+}
+
 func TestDashboardHTTPHandler_HandleUserAuthenticate(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -434,7 +621,9 @@ func TestDashboardHTTPHandler_HandleUserAuthenticate(t *testing.T) {
 				mockCCAgentContainerIntegrationsService,
 				mockOrganizationsService,
 				mockAgentsService,
+				nil, // connectedChannelsService
 				mockSettingsService,
+				nil, // jobsService
 				mockTxManager,
 			)
 			httpHandler := NewDashboardHTTPHandler(handler)
@@ -513,7 +702,9 @@ func TestDashboardHTTPHandler_HandleListSlackIntegrations(t *testing.T) {
 				mockCCAgentContainerIntegrationsService,
 				mockOrganizationsService,
 				mockAgentsService,
+				nil, // connectedChannelsService
 				mockSettingsService,
+				nil, // jobsService
 				mockTxManager,
 			)
 			httpHandler := NewDashboardHTTPHandler(handler)
@@ -612,7 +803,9 @@ func TestDashboardHTTPHandler_HandleCreateSlackIntegration(t *testing.T) {
 				mockCCAgentContainerIntegrationsService,
 				mockOrganizationsService,
 				mockAgentsService,
+				nil, // connectedChannelsService
 				mockSettingsService,
+				nil, // jobsService
 				mockTxManager,
 			)
 			httpHandler := NewDashboardHTTPHandler(handler)
@@ -688,7 +881,9 @@ func TestDashboardHTTPHandler_HandleDeleteSlackIntegration(t *testing.T) {
 				mockCCAgentContainerIntegrationsService,
 				mockOrganizationsService,
 				mockAgentsService,
+				nil, // connectedChannelsService
 				mockSettingsService,
+				nil, // jobsService
 				mockTxManager,
 			)
 			httpHandler := NewDashboardHTTPHandler(handler)
@@ -777,7 +972,9 @@ func TestDashboardHTTPHandler_HandleGenerateCCAgentSecretKey(t *testing.T) {
 				mockCCAgentContainerIntegrationsService,
 				mockOrganizationsService,
 				mockAgentsService,
+				nil, // connectedChannelsService
 				mockSettingsService,
+				nil, // jobsService
 				mockTxManager,
 			)
 			httpHandler := NewDashboardHTTPHandler(handler)
@@ -802,3 +999,371 @@ func TestDashboardHTTPHandler_HandleGenerateCCAgentSecretKey(t *testing.T) {
 		})
 	}
 }
+
+func TestDashboardHTTPHandler_HandleGetJobTranscript(t *testing.T) {
+	jobID := "j_01234567890123456789012345"
+	ctx := contextWithUser(testUser)
+	transcript := []*models.JobTranscriptMessage{
+		{
+			ID:          "jtm_01234567890123456789012345",
+			OrgID:       models.OrgID(testOrg.ID),
+			JobID:       jobID,
+			Author:      models.TranscriptAuthorUser,
+			AuthorID:    "U123456",
+			MessageType: models.TranscriptMessageTypeUserMessage,
+			Content:     "Please fix the login bug",
+		},
+		{
+			ID:          "jtm_01234567890123456789012346",
+			OrgID:       models.OrgID(testOrg.ID),
+			JobID:       jobID,
+			Author:      models.TranscriptAuthorAgent,
+			AuthorID:    "a_01234567890123456789012345",
+			MessageType: models.TranscriptMessageTypeAssistantMessage,
+			Content:     "Fixed the login bug",
+		},
+	}
+
+	tests := []struct {
+		name           string
+		jobID          string
+		mockSetup      func(*jobs.MockJobsService)
+		expectedStatus int
+		validateBody   func(*testing.T, []byte)
+	}{
+		{
+			name:  "success - returns transcript",
+			jobID: jobID,
+			mockSetup: func(m *jobs.MockJobsService) {
+				m.On("GetJobTranscript", mock.AnythingOfType("*context.valueCtx"), models.OrgID(testOrg.ID), jobID).
+					Return(mo.Some(transcript), nil)
+			},
+			expectedStatus: http.StatusOK,
+			validateBody: func(t *testing.T, body []byte) {
+				var response []*models.JobTranscriptMessage
+				require.NoError(t, json.Unmarshal(body, &response))
+				require.Len(t, response, 2)
+				assert.Equal(t, models.TranscriptAuthorUser, response[0].Author)
+				assert.Equal(t, "Fixed the login bug", response[1].Content)
+			},
+		},
+		{
+			name:  "job not found",
+			jobID: jobID,
+			mockSetup: func(m *jobs.MockJobsService) {
+				m.On("GetJobTranscript", mock.AnythingOfType("*context.valueCtx"), models.OrgID(testOrg.ID), jobID).
+					Return(mo.None[[]*models.JobTranscriptMessage](), nil)
+			},
+			expectedStatus: http.StatusNotFound,
+			validateBody: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "job not found")
+			},
+		},
+		{
+			name:           "invalid job ID",
+			jobID:          "not-a-ulid",
+			mockSetup:      func(m *jobs.MockJobsService) {},
+			expectedStatus: http.StatusBadRequest,
+			validateBody: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "job ID must be a valid ULID")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockJobsService := &jobs.MockJobsService{}
+			tt.mockSetup(mockJobsService)
+
+			handler := NewDashboardAPIHandler(
+				&users.MockUsersService{},
+				&slackintegrations.MockSlackIntegrationsService{},
+				&discordintegrations.MockDiscordIntegrationsService{},
+				&githubintegrations.MockGitHubIntegrationsService{},
+				&anthropicintegrations.MockAnthropicIntegrationsService{},
+				&ccagentcontainerintegrations.MockCCAgentContainerIntegrationsService{},
+				&organizations.MockOrganizationsService{},
+				&agents.MockAgentsService{},
+				nil, // connectedChannelsService
+				&settingsservice.MockSettingsService{},
+				mockJobsService,
+				&simpleTxManager{},
+			)
+			httpHandler := NewDashboardHTTPHandler(handler)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/jobs/%s/transcript", tt.jobID), nil)
+			req = req.WithContext(ctx)
+
+			// Setup mux router to capture path variables
+			router := mux.NewRouter()
+			router.HandleFunc("/jobs/{id}/transcript", httpHandler.HandleGetJobTranscript)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			tt.validateBody(t, rr.Body.Bytes())
+
+			mockJobsService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDashboardHTTPHandler_HandleListJobs(t *testing.T) {
+	ctx := contextWithUser(testUser)
+	agentID := "a_01234567890123456789012345"
+	createdAfter := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	page := &models.JobsPage{
+		Jobs: []*models.Job{
+			{ID: "j_01234567890123456789012346", JobType: models.JobTypeSlack, Status: models.JobStatusCompleted},
+		},
+		NextCursor: "j_01234567890123456789012346",
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*jobs.MockJobsService)
+		expectedStatus int
+		validateBody   func(*testing.T, []byte)
+	}{
+		{
+			name:  "success - default page size without filters",
+			query: "",
+			mockSetup: func(m *jobs.MockJobsService) {
+				m.On(
+					"ListJobs",
+					mock.AnythingOfType("*context.valueCtx"),
+					models.OrgID(testOrg.ID),
+					models.JobsFilter{},
+					models.DefaultJobsPageSize,
+				).Return(page, nil)
+			},
+			expectedStatus: http.StatusOK,
+			validateBody: func(t *testing.T, body []byte) {
+				var response models.JobsPage
+				require.NoError(t, json.Unmarshal(body, &response))
+				require.Len(t, response.Jobs, 1)
+				assert.Equal(t, models.JobStatusCompleted, response.Jobs[0].Status)
+				assert.Equal(t, "j_01234567890123456789012346", response.NextCursor)
+			},
+		},
+		{
+			name: "success - passes filters and cursor",
+			query: "platform=slack&channel_id=C123&status=completed&agent_id=" + agentID +
+				"&creator_id=U123&created_after=2025-10-01T00:00:00Z&cursor=j_01234567890123456789012347&limit=10",
+			mockSetup: func(m *jobs.MockJobsService) {
+				m.On(
+					"ListJobs",
+					mock.AnythingOfType("*context.valueCtx"),
+					models.OrgID(testOrg.ID),
+					models.JobsFilter{
+						JobType:      models.JobTypeSlack,
+						ChannelID:    "C123",
+						Status:       models.JobStatusCompleted,
+						AgentID:      agentID,
+						CreatorID:    "U123",
+						CreatedAfter: &createdAfter,
+						Cursor:       "j_01234567890123456789012347",
+					},
+					10,
+				).Return(page, nil)
+			},
+			expectedStatus: http.StatusOK,
+			validateBody:   func(t *testing.T, body []byte) {},
+		},
+		{
+			name:           "invalid platform",
+			query:          "platform=teams",
+			mockSetup:      func(m *jobs.MockJobsService) {},
+			expectedStatus: http.StatusBadRequest,
+			validateBody: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "platform must be slack or discord")
+			},
+		},
+		{
+			name:           "invalid status",
+			query:          "status=RUNNING",
+			mockSetup:      func(m *jobs.MockJobsService) {},
+			expectedStatus: http.StatusBadRequest,
+			validateBody: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "status must be one of")
+			},
+		},
+		{
+			name:           "invalid date",
+			query:          "created_before=yesterday",
+			mockSetup:      func(m *jobs.MockJobsService) {},
+			expectedStatus: http.StatusBadRequest,
+			validateBody: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "created_before must be an RFC 3339 timestamp")
+			},
+		},
+		{
+			name:           "limit out of range",
+			query:          "limit=1000",
+			mockSetup:      func(m *jobs.MockJobsService) {},
+			expectedStatus: http.StatusBadRequest,
+			validateBody: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "limit must be an integer between 1 and 100")
+			},
+		},
+		{
+			name:           "invalid cursor",
+			query:          "cursor=abc",
+			mockSetup:      func(m *jobs.MockJobsService) {},
+			expectedStatus: http.StatusBadRequest,
+			validateBody: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "cursor must be a valid job ID")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockJobsService := &jobs.MockJobsService{}
+			tt.mockSetup(mockJobsService)
+
+			handler := NewDashboardAPIHandler(
+				&users.MockUsersService{},
+				&slackintegrations.MockSlackIntegrationsService{},
+				&discordintegrations.MockDiscordIntegrationsService{},
+				&githubintegrations.MockGitHubIntegrationsService{},
+				&anthropicintegrations.MockAnthropicIntegrationsService{},
+				&ccagentcontainerintegrations.MockCCAgentContainerIntegrationsService{},
+				&organizations.MockOrganizationsService{},
+				&agents.MockAgentsService{},
+				nil, // connectedChannelsService
+				&settingsservice.MockSettingsService{},
+				mockJobsService,
+				&simpleTxManager{},
+			)
+			httpHandler := NewDashboardHTTPHandler(handler)
+
+			req := httptest.NewRequest("GET", "/jobs?"+tt.query, nil)
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+			httpHandler.HandleListJobs(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			tt.validateBody(t, rr.Body.Bytes())
+
+			mockJobsService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDashboardHTTPHandler_HandleGetJob(t *testing.T) {
+	jobID := "j_01234567890123456789012345"
+	ctx := contextWithUser(testUser)
+	detail := &models.JobDetail{
+		Job: &models.Job{ID: jobID, JobType: models.JobTypeSlack, Status: models.JobStatusActive},
+		ProcessedSlackMessages: []*models.ProcessedSlackMessage{
+			{ID: "psm_01234567890123456789012345", JobID: jobID, Status: models.ProcessedSlackMessageStatusCompleted},
+		},
+		AssignmentHistory: []*models.JobAssignmentHistoryEntry{
+			{ID: "aji_01234567890123456789012345", JobID: jobID, AgentID: "a_01234567890123456789012345"},
+		},
+	}
+	agentMessages := []*models.AgentOutboxMessage{
+		{
+			ID:                 "aom_01234567890123456789012345",
+			JobID:              jobID,
+			ProcessedMessageID: "psm_01234567890123456789012345",
+			Status:             models.AgentOutboxMessageStatusDelivered,
+		},
+	}
+
+	tests := []struct {
+		name           string
+		jobID          string
+		mockSetup      func(*jobs.MockJobsService, *agents.MockAgentsService)
+		expectedStatus int
+		validateBody   func(*testing.T, []byte)
+	}{
+		{
+			name:  "success - returns job with messages and assignment history",
+			jobID: jobID,
+			mockSetup: func(m *jobs.MockJobsService, am *agents.MockAgentsService) {
+				m.On("GetJobDetail", mock.AnythingOfType("*context.valueCtx"), models.OrgID(testOrg.ID), jobID).
+					Return(mo.Some(detail), nil)
+				am.On("GetAgentMessages", mock.AnythingOfType("*context.valueCtx"), models.OrgID(testOrg.ID), jobID).
+					Return(agentMessages, nil)
+			},
+			expectedStatus: http.StatusOK,
+			validateBody: func(t *testing.T, body []byte) {
+				var response models.JobDetail
+				require.NoError(t, json.Unmarshal(body, &response))
+				assert.Equal(t, jobID, response.Job.ID)
+				require.Len(t, response.ProcessedSlackMessages, 1)
+				assert.Equal(t, models.ProcessedSlackMessageStatusCompleted, response.ProcessedSlackMessages[0].Status)
+				require.Len(t, response.AssignmentHistory, 1)
+				assert.Equal(t, "a_01234567890123456789012345", response.AssignmentHistory[0].AgentID)
+				require.Len(t, response.AgentMessages, 1)
+				assert.Equal(t, "psm_01234567890123456789012345", response.AgentMessages[0].ProcessedMessageID)
+				assert.Equal(t, models.AgentOutboxMessageStatusDelivered, response.AgentMessages[0].Status)
+			},
+		},
+		{
+			name:  "job not found",
+			jobID: jobID,
+			mockSetup: func(m *jobs.MockJobsService, am *agents.MockAgentsService) {
+				m.On("GetJobDetail", mock.AnythingOfType("*context.valueCtx"), models.OrgID(testOrg.ID), jobID).
+					Return(mo.None[*models.JobDetail](), nil)
+			},
+			expectedStatus: http.StatusNotFound,
+			validateBody: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "job not found")
+			},
+		},
+		{
+			name:           "invalid job ID",
+			jobID:          "not-a-ulid",
+			mockSetup:      func(m *jobs.MockJobsService, am *agents.MockAgentsService) {},
+			expectedStatus: http.StatusBadRequest,
+			validateBody: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "job ID must be a valid ULID")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockJobsService := &jobs.MockJobsService{}
+			mockAgentsService := &agents.MockAgentsService{}
+			tt.mockSetup(mockJobsService, mockAgentsService)
+
+			handler := NewDashboardAPIHandler(
+				&users.MockUsersService{},
+				&slackintegrations.MockSlackIntegrationsService{},
+				&discordintegrations.MockDiscordIntegrationsService{},
+				&githubintegrations.MockGitHubIntegrationsService{},
+				&anthropicintegrations.MockAnthropicIntegrationsService{},
+				&ccagentcontainerintegrations.MockCCAgentContainerIntegrationsService{},
+				&organizations.MockOrganizationsService{},
+				mockAgentsService,
+				nil, // connectedChannelsService
+				&settingsservice.MockSettingsService{},
+				mockJobsService,
+				&simpleTxManager{},
+			)
+			httpHandler := NewDashboardHTTPHandler(handler)
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/jobs/%s", tt.jobID), nil)
+			req = req.WithContext(ctx)
+
+			// Setup mux router to capture path variables
+			router := mux.NewRouter()
+			router.HandleFunc("/jobs/{id}", httpHandler.HandleGetJob)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			tt.validateBody(t, rr.Body.Bytes())
+
+			mockJobsService.AssertExpectations(t)
+			mockAgentsService.AssertExpectations(t)
+		})
+	}
+}
@@ -190,14 +190,25 @@ func (h *DiscordEventsHandler) mapToDiscordMessageEvent(
 		mentions[i] = mentionedUser.ID
 	}
 
+	var attachments models.Attachments
+	for _, attachment := range m.Attachments {
+		attachments = append(attachments, models.Attachment{
+			Name:     attachment.Filename,
+			MimeType: attachment.ContentType,
+			Size:     int64(attachment.Size),
+			URL:      attachment.URL,
+		})
+	}
+
 	return models.DiscordMessageEvent{
-		GuildID:   m.GuildID,
-		ChannelID: m.ChannelID,
-		MessageID: m.ID,
-		UserID:    m.Author.ID,
-		Content:   m.Content,
-		ThreadID:  threadID,
-		Mentions:  mentions,
+		GuildID:     m.GuildID,
+		ChannelID:   m.ChannelID,
+		MessageID:   m.ID,
+		UserID:      m.Author.ID,
+		Content:     m.Content,
+		ThreadID:    threadID,
+		Mentions:    mentions,
+		Attachments: attachments,
 	}, nil
 }
 
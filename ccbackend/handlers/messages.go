@@ -3,10 +3,12 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 
 	"ccbackend/clients"
+	corepkg "ccbackend/core"
 	"ccbackend/models"
 	"ccbackend/usecases/core"
 )
@@ -42,6 +44,43 @@ func (h *MessagesHandler) HandleMessage(client *clients.Client, msg any) error {
 		client.AgentID,
 	)
 
+	ctx := context.Background()
+	claimed, err := h.coreUseCase.ClaimInboundMessage(ctx, client, parsedMsg.ID, parsedMsg.Type)
+	if errors.Is(err, corepkg.ErrMessageInProgress) {
+		// Returned so the copy is not acknowledged - the agent resends it in case the original fails
+		return err
+	}
+	if err != nil {
+		// Processing a duplicate is preferable to dropping the message
+		log.Printf("⚠️ Failed to check message %s from client %s for duplicates: %v", parsedMsg.ID, client.ID, err)
+		claimed = true
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := h.processMessage(client, parsedMsg); err != nil {
+		if releaseErr := h.coreUseCase.ReleaseInboundMessage(ctx, client, parsedMsg.ID); releaseErr != nil {
+			log.Printf("❌ Failed to release message %s from client %s: %v", parsedMsg.ID, client.ID, releaseErr)
+		}
+		jobID := payloadJobID(parsedMsg.Payload)
+		return h.coreUseCase.ProcessRejectedMessage(ctx, client, parsedMsg.ID, jobID, err)
+	}
+
+	if err := h.coreUseCase.CompleteInboundMessage(ctx, client, parsedMsg.ID); err != nil {
+		// Resent copies are held back until the claim times out, and are then processed again
+		log.Printf("⚠️ Failed to complete message %s from client %s: %v", parsedMsg.ID, client.ID, err)
+	}
+	return nil
+}
+
+func (h *MessagesHandler) processMessage(client *clients.Client, parsedMsg models.BaseMessage) error {
+	// Malformed payloads are rejected before they reach the core usecase
+	if err := models.ValidateAgentPayload(parsedMsg.Type, parsedMsg.Payload); err != nil {
+		log.Printf("❌ Rejecting invalid message %s from client %s: %v", parsedMsg.ID, client.ID, err)
+		return fmt.Errorf("%w: %w", corepkg.ErrInvalidPayload, err)
+	}
+
 	switch parsedMsg.Type {
 	case models.MessageTypeAssistantMessage:
 		var payload models.AssistantMessagePayload
@@ -56,6 +95,45 @@ func (h *MessagesHandler) HandleMessage(client *clients.Client, msg any) error {
 			return fmt.Errorf("failed to process assistant message: %w", err)
 		}
 
+	case models.MessageTypeAssistantDelta:
+		var payload models.AssistantDeltaPayload
+		if err := unmarshalPayload(parsedMsg.Payload, &payload); err != nil {
+			log.Printf("❌ Failed to unmarshal assistant delta payload from client %s: %v", client.ID, err)
+			return fmt.Errorf("failed to unmarshal assistant delta payload: %w", err)
+		}
+
+		err := h.coreUseCase.ProcessAssistantDelta(context.Background(), client.ID, payload, client.OrgID)
+		if err != nil {
+			log.Printf("❌ Failed to process assistant delta from client %s: %v", client.ID, err)
+			return fmt.Errorf("failed to process assistant delta: %w", err)
+		}
+
+	case models.MessageTypeProgressEvent:
+		var payload models.ProgressEventPayload
+		if err := unmarshalPayload(parsedMsg.Payload, &payload); err != nil {
+			log.Printf("❌ Failed to unmarshal progress event payload from client %s: %v", client.ID, err)
+			return fmt.Errorf("failed to unmarshal progress event payload: %w", err)
+		}
+
+		err := h.coreUseCase.ProcessProgressEvent(context.Background(), client.ID, payload, client.OrgID)
+		if err != nil {
+			log.Printf("❌ Failed to process progress event from client %s: %v", client.ID, err)
+			return fmt.Errorf("failed to process progress event: %w", err)
+		}
+
+	case models.MessageTypeArtifact:
+		var payload models.ArtifactPayload
+		if err := unmarshalPayload(parsedMsg.Payload, &payload); err != nil {
+			log.Printf("❌ Failed to unmarshal artifact payload from client %s: %v", client.ID, err)
+			return fmt.Errorf("failed to unmarshal artifact payload: %w", err)
+		}
+
+		err := h.coreUseCase.ProcessArtifact(context.Background(), client.ID, payload, client.OrgID)
+		if err != nil {
+			log.Printf("❌ Failed to process artifact from client %s: %v", client.ID, err)
+			return fmt.Errorf("failed to process artifact: %w", err)
+		}
+
 	case models.MessageTypeSystemMessage:
 		var payload models.SystemMessagePayload
 		if err := unmarshalPayload(parsedMsg.Payload, &payload); err != nil {
@@ -119,8 +197,22 @@ func unmarshalPayload(payload any, target any) error {
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", corepkg.ErrInvalidPayload, err)
 	}
 
-	return json.Unmarshal(payloadBytes, target)
+	if err := json.Unmarshal(payloadBytes, target); err != nil {
+		return fmt.Errorf("%w: %w", corepkg.ErrInvalidPayload, err)
+	}
+	return nil
+}
+
+// payloadJobID returns the job an agent's message is about, or an empty string if it has none
+func payloadJobID(payload any) string {
+	var jobPayload struct {
+		JobID string `json:"job_id"`
+	}
+	if err := unmarshalPayload(payload, &jobPayload); err != nil {
+		return ""
+	}
+	return jobPayload.JobID
 }
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"ccbackend/models"
+)
+
+func TestParseSlackFiles(t *testing.T) {
+	t.Run("Parses files shared with the message", func(t *testing.T) {
+		event := map[string]any{
+			"files": []any{
+				map[string]any{
+					"name":                 "screenshot.png",
+					"mimetype":             "image/png",
+					"size":                 float64(2048),
+					"url_private_download": "https://files.slack.com/files-pri/T1-F1/download/screenshot.png",
+				},
+				map[string]any{
+					"name": "hidden.log",
+					"mode": "hidden_by_limit",
+				},
+			},
+		}
+
+		attachments := parseSlackFiles(event)
+
+		assert.Equal(t, models.Attachments{{
+			Name:     "screenshot.png",
+			MimeType: "image/png",
+			Size:     2048,
+			URL:      "https://files.slack.com/files-pri/T1-F1/download/screenshot.png",
+		}}, attachments)
+	})
+
+	t.Run("Message without files has no attachments", func(t *testing.T) {
+		assert.Nil(t, parseSlackFiles(map[string]any{"text": "hello"}))
+	})
+}
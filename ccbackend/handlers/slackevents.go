@@ -218,16 +218,48 @@ func (h *SlackEventsHandler) handleAppMention(
 	}
 
 	slackEvent := models.SlackMessageEvent{
-		Channel:  channel,
-		User:     user,
-		Text:     text,
-		TS:       timestamp,
-		ThreadTS: threadTS,
+		Channel:     channel,
+		User:        user,
+		Text:        text,
+		TS:          timestamp,
+		ThreadTS:    threadTS,
+		Attachments: parseSlackFiles(event),
 	}
 
 	return h.coreUseCase.ProcessSlackMessageEvent(ctx, slackEvent, slackIntegrationID, orgID)
 }
 
+// parseSlackFiles reads the files shared with a message event
+// Files without a download URL, e.g. ones hidden by the workspace's file retention, are ignored.
+func parseSlackFiles(event map[string]any) models.Attachments {
+	files, ok := event["files"].([]any)
+	if !ok {
+		return nil
+	}
+
+	var attachments models.Attachments
+	for _, f := range files {
+		file, ok := f.(map[string]any)
+		if !ok {
+			continue
+		}
+		url, _ := file["url_private_download"].(string)
+		if url == "" {
+			continue
+		}
+		name, _ := file["name"].(string)
+		mimeType, _ := file["mimetype"].(string)
+		size, _ := file["size"].(float64)
+		attachments = append(attachments, models.Attachment{
+			Name:     name,
+			MimeType: mimeType,
+			Size:     int64(size),
+			URL:      url,
+		})
+	}
+	return attachments
+}
+
 func (h *SlackEventsHandler) handleReactionAdded(
 	ctx context.Context,
 	event map[string]any,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,6 +13,8 @@ import (
 	"time"
 
 	"ccbackend/clients"
+	"ccbackend/core"
+	"ccbackend/models"
 )
 
 type SlackAlertConfig struct {
@@ -45,13 +48,22 @@ func (m *ErrorAlertMiddleware) HTTPMiddleware(next http.Handler) http.Handler {
 }
 
 // WebSocket Message Handler Wrapper
-func (m *ErrorAlertMiddleware) WrapMessageHandler(handler func(*clients.Client, any) error) func(*clients.Client, any) {
-	return func(client *clients.Client, msg any) {
+// Only core.ErrMessageInProgress is passed on, so the transport leaves the message unacknowledged - every other
+// error is alerted on here and the message is acknowledged.
+func (m *ErrorAlertMiddleware) WrapMessageHandler(
+	handler func(*clients.Client, any) error,
+) func(*clients.Client, any) error {
+	return func(client *clients.Client, msg any) error {
 		defer m.recoverAndAlert(fmt.Sprintf("WebSocket message from client %s", client.ID))
 
-		if err := handler(client, msg); err != nil {
+		err := handler(client, msg)
+		if errors.Is(err, core.ErrMessageInProgress) {
+			return err
+		}
+		if err != nil {
 			m.alertOnError(err, fmt.Sprintf("WebSocket message handler (client: %s)", client.ID))
 		}
+		return nil
 	}
 }
 
@@ -68,6 +80,70 @@ func (m *ErrorAlertMiddleware) WrapConnectionHook(hook func(*clients.Client) err
 	}
 }
 
+// WebSocket Ping Hook Wrapper
+func (m *ErrorAlertMiddleware) WrapPingHook(
+	hook func(*clients.Client, models.AgentTelemetry) error,
+) func(*clients.Client, models.AgentTelemetry) error {
+	return func(client *clients.Client, telemetry models.AgentTelemetry) error {
+		defer m.recoverAndAlert(fmt.Sprintf("WebSocket ping hook for client %s", client.ID))
+
+		if err := hook(client, telemetry); err != nil {
+			m.alertOnError(err, fmt.Sprintf("WebSocket ping hook (client: %s)", client.ID))
+			return err
+		}
+		return nil
+	}
+}
+
+func (m *ErrorAlertMiddleware) WrapAckHook(
+	hook func(*clients.Client, string) error,
+) func(*clients.Client, string) error {
+	return func(client *clients.Client, messageID string) error {
+		defer m.recoverAndAlert(fmt.Sprintf("WebSocket ack hook for client %s", client.ID))
+
+		if err := hook(client, messageID); err != nil {
+			m.alertOnError(err, fmt.Sprintf("WebSocket ack hook (client: %s, message: %s)", client.ID, messageID))
+			return err
+		}
+		return nil
+	}
+}
+
+func (m *ErrorAlertMiddleware) WrapDeliveryFailureHook(
+	hook func(*clients.Client, string, error) error,
+) func(*clients.Client, string, error) error {
+	return func(client *clients.Client, messageID string, reason error) error {
+		defer m.recoverAndAlert(fmt.Sprintf("WebSocket delivery failure hook for client %s", client.ID))
+
+		if err := hook(client, messageID, reason); err != nil {
+			m.alertOnError(
+				err,
+				fmt.Sprintf("WebSocket delivery failure hook (client: %s, message: %s)", client.ID, messageID),
+			)
+			return err
+		}
+		return nil
+	}
+}
+
+// WrapOutgoingMessageHook alerts on messages which could not be prepared for their client
+// A panicking hook fails the emit rather than sending the message unprepared.
+func (m *ErrorAlertMiddleware) WrapOutgoingMessageHook(
+	hook func(*clients.Client, any) (any, error),
+) func(*clients.Client, any) (any, error) {
+	return func(client *clients.Client, msg any) (prepared any, err error) {
+		err = fmt.Errorf("outgoing message hook for client %s panicked", client.ID)
+		defer m.recoverAndAlert(fmt.Sprintf("WebSocket outgoing message hook for client %s", client.ID))
+
+		prepared, err = hook(client, msg)
+		if err != nil {
+			m.alertOnError(err, fmt.Sprintf("WebSocket outgoing message hook (client: %s)", client.ID))
+			return nil, err
+		}
+		return prepared, nil
+	}
+}
+
 // Background Task Wrapper
 func (m *ErrorAlertMiddleware) WrapBackgroundTask(taskName string, task func() error) func() error {
 	return func() error {
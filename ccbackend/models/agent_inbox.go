@@ -0,0 +1,9 @@
+package models
+
+// AgentInboxMessageStatus tracks whether a message received from an agent was processed yet
+type AgentInboxMessageStatus string
+
+const (
+	AgentInboxMessageStatusProcessing AgentInboxMessageStatus = "PROCESSING"
+	AgentInboxMessageStatusProcessed  AgentInboxMessageStatus = "PROCESSED"
+)
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+type AgentOutboxMessageStatus string
+
+const (
+	AgentOutboxMessageStatusPending   AgentOutboxMessageStatus = "PENDING"
+	AgentOutboxMessageStatusDelivered AgentOutboxMessageStatus = "DELIVERED"
+	AgentOutboxMessageStatusFailed    AgentOutboxMessageStatus = "FAILED"
+)
+
+// MaxAgentMessageDeliveryAttempts is how often a message is sent before the agent is given up on
+// and the job is requeued for another agent
+const MaxAgentMessageDeliveryAttempts = 5
+
+// AgentControlMessageTypes tell an agent to stop working on a job. Unlike the job's prompts they stay deliverable
+// after the agent was unassigned or the job's messages were cancelled.
+var AgentControlMessageTypes = []string{MessageTypeCancelJob, MessageTypeJobClosed}
+
+// AgentOutboxMessage tracks the delivery of a message sent to an agent for a processed Slack or Discord message
+// ID is the ID of the sent message and Payload the whole message, JSON encoded
+type AgentOutboxMessage struct {
+	ID                 string                   `json:"id"                   db:"id"`
+	OrgID              OrgID                    `json:"organization_id"      db:"organization_id"`
+	AgentID            string                   `json:"agent_id"             db:"agent_id"`
+	JobID              string                   `json:"job_id"               db:"job_id"`
+	ProcessedMessageID string                   `json:"processed_message_id" db:"processed_message_id"`
+	MessageType        string                   `json:"message_type"         db:"message_type"`
+	Payload            []byte                   `json:"-"                    db:"payload"`
+	Status             AgentOutboxMessageStatus `json:"status"               db:"status"`
+	Attempts           int                      `json:"attempts"             db:"attempts"`
+	LastError          *string                  `json:"last_error"           db:"last_error"`
+	NextAttemptAt      time.Time                `json:"next_attempt_at"      db:"next_attempt_at"`
+	DeliveredAt        *time.Time               `json:"delivered_at"         db:"delivered_at"`
+	CreatedAt          time.Time                `json:"created_at"           db:"created_at"`
+	UpdatedAt          time.Time                `json:"updated_at"           db:"updated_at"`
+}
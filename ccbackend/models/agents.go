@@ -1,18 +1,128 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
+// AgentLabels are arbitrary key/value pairs advertised by an agent (e.g. team=payments, env=staging)
+// The same type is used for channel-level selectors which agents must satisfy to be assigned a job
+type AgentLabels map[string]string
+
+// ParseAgentLabels parses a comma-separated list of key=value pairs, e.g. "gpu=false,team=payments"
+func ParseAgentLabels(raw string) (AgentLabels, error) {
+	labels := AgentLabels{}
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid label %q, expected key=value", pair)
+		}
+		labels[key] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
+
+// Matches returns true if the labels contain every key/value pair of the selector
+// An empty selector matches any labels
+func (l AgentLabels) Matches(selector AgentLabels) bool {
+	for key, value := range selector {
+		if labelValue, ok := l[key]; !ok || labelValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the labels as sorted key=value pairs, the same format accepted by ParseAgentLabels
+func (l AgentLabels) String() string {
+	pairs := make([]string, 0, len(l))
+	for key, value := range l {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Value implements driver.Valuer so labels are stored as a JSONB object
+func (l AgentLabels) Value() (driver.Value, error) {
+	if l == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(map[string]string(l))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal agent labels: %w", err)
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner for labels stored as a JSONB object
+func (l *AgentLabels) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*l = AgentLabels{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into AgentLabels", src)
+	}
+
+	labels := AgentLabels{}
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return fmt.Errorf("failed to unmarshal agent labels: %w", err)
+	}
+	*l = labels
+	return nil
+}
+
+// AgentTelemetry is the metadata and connection health an agent reports in its handshake and pings
+// Empty fields and a nil round-trip time mean "not reported" and leave the stored values unchanged
+type AgentTelemetry struct {
+	Version         string `json:"version,omitempty"`
+	OS              string `json:"os,omitempty"`
+	Hostname        string `json:"hostname,omitempty"`
+	RoundTripTimeMs *int64 `json:"rtt_ms,omitempty"` // Measured by the agent from the ack of its previous ping
+}
+
+// IsEmpty returns true if the agent reported no telemetry
+func (t AgentTelemetry) IsEmpty() bool {
+	return t.Version == "" && t.OS == "" && t.Hostname == "" && t.RoundTripTimeMs == nil
+}
+
 type ActiveAgent struct {
-	ID             string    `json:"id"               db:"id"`
-	WSConnectionID string    `json:"ws_connection_id" db:"ws_connection_id"`
-	OrgID          OrgID     `json:"organization_id"  db:"organization_id"`
-	CCAgentID      string    `json:"ccagent_id"       db:"ccagent_id"`
-	RepoURL        string    `json:"repo_url" db:"repo_url"`
-	LastActiveAt   time.Time `json:"last_active_at"   db:"last_active_at"`
-	CreatedAt      time.Time `json:"created_at"       db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"       db:"updated_at"`
+	ID             string      `json:"id"               db:"id"`
+	WSConnectionID string      `json:"ws_connection_id" db:"ws_connection_id"`
+	OrgID          OrgID       `json:"organization_id"  db:"organization_id"`
+	CCAgentID      string      `json:"ccagent_id"       db:"ccagent_id"`
+	RepoURL        string      `json:"repo_url" db:"repo_url"`
+	MaxConcurrency int         `json:"max_concurrency"  db:"max_concurrency"`
+	Labels         AgentLabels `json:"labels"           db:"labels"`
+	Draining       bool        `json:"draining"         db:"draining"` // Draining agents finish current jobs but get no new ones
+	Version        string      `json:"version"          db:"version"`
+	OS             string      `json:"os"               db:"os"`
+	Hostname       string      `json:"hostname"         db:"hostname"`
+	// AgentReportedRTTMs is the last ping round-trip time reported by the agent - the backend does not measure it
+	AgentReportedRTTMs *int64    `json:"agent_reported_rtt_ms" db:"agent_reported_rtt_ms"`
+	LastActiveAt       time.Time `json:"last_active_at"   db:"last_active_at"`
+	CreatedAt          time.Time `json:"created_at"       db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"       db:"updated_at"`
+	// ValidationFailures counts the agent's messages rejected for failing payload validation
+	ValidationFailures int `json:"validation_failures" db:"validation_failures"`
+	// DisconnectedAt is when the agent's connection dropped, nil while it is connected or after it reconnected
+	DisconnectedAt *time.Time `json:"disconnected_at" db:"disconnected_at"`
 }
 
 type AgentJobAssignment struct {
@@ -22,3 +132,14 @@ type AgentJobAssignment struct {
 	OrgID      OrgID     `json:"organization_id" db:"organization_id"`
 	AssignedAt time.Time `json:"assigned_at"     db:"assigned_at"`
 }
+
+// JobAssignmentHistoryEntry records an agent holding a job - UnassignedAt is nil while the agent still holds it
+type JobAssignmentHistoryEntry struct {
+	ID           string     `json:"id"                      db:"id"`
+	OrgID        OrgID      `json:"organization_id"         db:"organization_id"`
+	JobID        string     `json:"job_id"                  db:"job_id"`
+	AgentID      string     `json:"agent_id"                db:"agent_id"`
+	CCAgentID    string     `json:"ccagent_id"              db:"ccagent_id"`
+	AssignedAt   time.Time  `json:"assigned_at"             db:"assigned_at"`
+	UnassignedAt *time.Time `json:"unassigned_at,omitempty" db:"unassigned_at"`
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Size limits for files forwarded between chat platforms and agents
+// Attachments are sent inline in agent messages, so these also bound the size of a single message.
+const (
+	// MaxAttachmentSize is the largest single file forwarded, larger files are skipped
+	MaxAttachmentSize = 10 << 20
+	// MaxMessageAttachmentsSize is the largest combined size of the files forwarded with one chat message
+	MaxMessageAttachmentsSize = 20 << 20
+	// MaxAgentMessageSize is the largest message accepted from an agent - enough for a base64 encoded
+	// artifact of MaxAttachmentSize
+	MaxAgentMessageSize = 2 * MaxAttachmentSize
+)
+
+// Attachment is a file attached to a chat message
+// Only its metadata is stored - the file is downloaded from URL whenever the message is sent to an agent.
+type Attachment struct {
+	Name     string `json:"name"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+	URL      string `json:"url"`
+}
+
+// Attachments are the files attached to a chat message, stored as a JSONB array
+type Attachments []Attachment
+
+// Value implements driver.Valuer so attachments are stored as a JSONB array
+func (a Attachments) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]Attachment(a))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal attachments: %w", err)
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner for attachments stored as a JSONB array
+func (a *Attachments) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Attachments", src)
+	}
+
+	var attachments Attachments
+	if err := json.Unmarshal(data, &attachments); err != nil {
+		return fmt.Errorf("failed to unmarshal attachments: %w", err)
+	}
+	if len(attachments) == 0 {
+		attachments = nil
+	}
+	*a = attachments
+	return nil
+}
+
+// WithinLimits splits the attachments into the ones which are forwarded to agents and the ones which are
+// skipped for exceeding MaxAttachmentSize or, in order, the MaxMessageAttachmentsSize budget of the message
+func (a Attachments) WithinLimits() (forwarded, skipped Attachments) {
+	var totalSize int64
+	for _, attachment := range a {
+		if attachment.Size > MaxAttachmentSize || totalSize+attachment.Size > MaxMessageAttachmentsSize {
+			skipped = append(skipped, attachment)
+			continue
+		}
+		totalSize += attachment.Size
+		forwarded = append(forwarded, attachment)
+	}
+	return forwarded, skipped
+}
+
+// SkippedNotice renders the message telling the user which attachments were not forwarded to the agent
+func (a Attachments) SkippedNotice() string {
+	names := make([]string, 0, len(a))
+	for _, attachment := range a {
+		names = append(names, "`"+attachment.Name+"`")
+	}
+	return fmt.Sprintf(
+		"Some attachments were not forwarded to the agent - files are limited to %d MB each and %d MB per message: %s",
+		MaxAttachmentSize>>20,
+		MaxMessageAttachmentsSize>>20,
+		strings.Join(names, ", "),
+	)
+}
+
+// ForAgent describes the attachments of a chat message received through the given integration for an agent
+// Only their source is set - the content is downloaded when the message is emitted to the agent.
+func (a Attachments) ForAgent(platform JobType, integrationID string) []AgentAttachment {
+	var agentAttachments []AgentAttachment
+	for _, attachment := range a {
+		agentAttachments = append(agentAttachments, AgentAttachment{
+			Name:     attachment.Name,
+			MimeType: attachment.MimeType,
+			Source: &AttachmentSource{
+				Platform:      platform,
+				IntegrationID: integrationID,
+				URL:           attachment.URL,
+			},
+		})
+	}
+	return agentAttachments
+}
+
+// AgentAttachment is a file sent to or received from an agent
+// Content is base64 encoded on the wire. Files forwarded from chat messages carry their Source instead of their
+// Content until they are emitted to the agent, so neither the agent outbox nor relayed messages hold file content.
+type AgentAttachment struct {
+	Name     string            `json:"name"`
+	MimeType string            `json:"mime_type"`
+	Content  []byte            `json:"content,omitempty"`
+	Source   *AttachmentSource `json:"source,omitempty"`
+}
+
+// AttachmentSource is where the backend downloads a file forwarded to an agent from
+type AttachmentSource struct {
+	Platform      JobType `json:"platform"`
+	IntegrationID string  `json:"integration_id"`
+	URL           string  `json:"url"`
+}
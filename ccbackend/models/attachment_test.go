@@ -0,0 +1,101 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentsWithinLimits(t *testing.T) {
+	t.Run("Forwards attachments within the limits", func(t *testing.T) {
+		attachments := Attachments{
+			{Name: "a.png", Size: 1024},
+			{Name: "b.log", Size: MaxAttachmentSize},
+		}
+
+		forwarded, skipped := attachments.WithinLimits()
+
+		assert.Equal(t, attachments, forwarded)
+		assert.Nil(t, skipped)
+	})
+
+	t.Run("Skips attachments larger than the per file limit", func(t *testing.T) {
+		forwarded, skipped := Attachments{
+			{Name: "core.dump", Size: MaxAttachmentSize + 1},
+			{Name: "a.png", Size: 1024},
+		}.WithinLimits()
+
+		assert.Equal(t, Attachments{{Name: "a.png", Size: 1024}}, forwarded)
+		assert.Equal(t, Attachments{{Name: "core.dump", Size: MaxAttachmentSize + 1}}, skipped)
+	})
+
+	t.Run("Skips attachments exceeding the message budget in order", func(t *testing.T) {
+		forwarded, skipped := Attachments{
+			{Name: "1.zip", Size: MaxAttachmentSize},
+			{Name: "2.zip", Size: MaxAttachmentSize},
+			{Name: "3.zip", Size: MaxAttachmentSize},
+			{Name: "small.txt", Size: 0},
+		}.WithinLimits()
+
+		assert.Equal(t, []string{"1.zip", "2.zip", "small.txt"}, attachmentNames(forwarded))
+		assert.Equal(t, []string{"3.zip"}, attachmentNames(skipped))
+	})
+}
+
+func TestAttachmentsSkippedNotice(t *testing.T) {
+	notice := Attachments{{Name: "core.dump"}, {Name: "video.mp4"}}.SkippedNotice()
+
+	assert.Contains(t, notice, "`core.dump`, `video.mp4`")
+	assert.Contains(t, notice, "10 MB")
+	assert.Contains(t, notice, "20 MB")
+}
+
+func TestAttachmentsForAgent(t *testing.T) {
+	attachments := Attachments{{Name: "a.png", MimeType: "image/png", Size: 12, URL: "https://example.com/a.png"}}
+
+	agentAttachments := attachments.ForAgent(JobTypeSlack, "si_1")
+
+	assert.Equal(t, []AgentAttachment{
+		{
+			Name:     "a.png",
+			MimeType: "image/png",
+			Source:   &AttachmentSource{Platform: JobTypeSlack, IntegrationID: "si_1", URL: "https://example.com/a.png"},
+		},
+	}, agentAttachments)
+}
+
+func TestAttachmentsValueAndScan(t *testing.T) {
+	t.Run("Nil attachments are stored as an empty array", func(t *testing.T) {
+		value, err := Attachments(nil).Value()
+
+		require.NoError(t, err)
+		assert.Equal(t, "[]", value)
+	})
+
+	t.Run("Roundtrips attachments", func(t *testing.T) {
+		attachments := Attachments{{Name: "a.png", MimeType: "image/png", Size: 12, URL: "https://example.com/a.png"}}
+
+		value, err := attachments.Value()
+		require.NoError(t, err)
+
+		var scanned Attachments
+		require.NoError(t, scanned.Scan([]byte(value.(string))))
+		assert.Equal(t, attachments, scanned)
+	})
+
+	t.Run("Empty array is scanned as nil", func(t *testing.T) {
+		scanned := Attachments{{Name: "stale"}}
+
+		require.NoError(t, scanned.Scan([]byte("[]")))
+		assert.Nil(t, scanned)
+	})
+}
+
+func attachmentNames(attachments Attachments) []string {
+	names := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		names = append(names, attachment.Name)
+	}
+	return names
+}
@@ -4,13 +4,32 @@ import (
 	"time"
 )
 
+// CCAgentRedeployStatus is the progress of a drained redeploy running in the background
+type CCAgentRedeployStatus string
+
+const (
+	CCAgentRedeployStatusDraining    CCAgentRedeployStatus = "DRAINING"
+	CCAgentRedeployStatusRedeploying CCAgentRedeployStatus = "REDEPLOYING"
+	CCAgentRedeployStatusCompleted   CCAgentRedeployStatus = "COMPLETED"
+	CCAgentRedeployStatusFailed      CCAgentRedeployStatus = "FAILED"
+)
+
+// IsInProgress returns true while the redeploy is still draining agents or redeploying the container
+func (s CCAgentRedeployStatus) IsInProgress() bool {
+	return s == CCAgentRedeployStatusDraining || s == CCAgentRedeployStatusRedeploying
+}
+
 // CCAgentContainerIntegration represents a CCAgent container configuration for an organization
 type CCAgentContainerIntegration struct {
-	ID             string    `db:"id"              json:"id"`
-	InstancesCount int       `db:"instances_count" json:"instances_count"`
-	RepoURL        string    `db:"repo_url"        json:"repo_url"`
-	SSHHost        string    `db:"ssh_host"        json:"ssh_host"`
-	OrgID          OrgID     `db:"organization_id" json:"organization_id"`
-	CreatedAt      time.Time `db:"created_at"      json:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at"      json:"updated_at"`
+	ID                string                 `db:"id"                  json:"id"`
+	InstancesCount    int                    `db:"instances_count"     json:"instances_count"`
+	RepoURL           string                 `db:"repo_url"            json:"repo_url"`
+	SSHHost           string                 `db:"ssh_host"            json:"ssh_host"`
+	OrgID             OrgID                  `db:"organization_id"     json:"organization_id"`
+	RedeployStatus    *CCAgentRedeployStatus `db:"redeploy_status"     json:"redeploy_status"`
+	RedeployError     *string                `db:"redeploy_error"      json:"redeploy_error"`
+	RedeployStartedAt *time.Time             `db:"redeploy_started_at" json:"redeploy_started_at"`
+	RedeployUpdatedAt *time.Time             `db:"redeploy_updated_at" json:"redeploy_updated_at"`
+	CreatedAt         time.Time              `db:"created_at"          json:"created_at"`
+	UpdatedAt         time.Time              `db:"updated_at"          json:"updated_at"`
 }
@@ -19,6 +19,8 @@ type ConnectedChannel interface {
 	GetOrgID() OrgID
 	GetChannelType() ChannelType
 	GetDefaultRepoURL() *string
+	// Returns the label selector agents must satisfy to be assigned jobs from the channel
+	GetAgentSelector() AgentLabels
 	GetCreatedAt() time.Time
 	GetUpdatedAt() time.Time
 	// Returns the channel ID (either Slack or Discord channel ID)
@@ -31,8 +33,9 @@ type SlackConnectedChannel struct {
 	OrgID          OrgID     `json:"organization_id"`
 	TeamID         string    `json:"team_id"`
 	ChannelID      string    `json:"channel_id"`
-	DefaultRepoURL *string   `json:"default_repo_url"`
-	CreatedAt      time.Time `json:"created_at"`
+	DefaultRepoURL *string     `json:"default_repo_url"`
+	AgentSelector  AgentLabels `json:"agent_selector"`
+	CreatedAt      time.Time   `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
 
@@ -56,6 +59,11 @@ func (s *SlackConnectedChannel) GetDefaultRepoURL() *string {
 	return s.DefaultRepoURL
 }
 
+// GetAgentSelector implements ConnectedChannel interface
+func (s *SlackConnectedChannel) GetAgentSelector() AgentLabels {
+	return s.AgentSelector
+}
+
 // GetCreatedAt implements ConnectedChannel interface
 func (s *SlackConnectedChannel) GetCreatedAt() time.Time {
 	return s.CreatedAt
@@ -77,8 +85,9 @@ type DiscordConnectedChannel struct {
 	OrgID          OrgID     `json:"organization_id"`
 	GuildID        string    `json:"guild_id"`
 	ChannelID      string    `json:"channel_id"`
-	DefaultRepoURL *string   `json:"default_repo_url"`
-	CreatedAt      time.Time `json:"created_at"`
+	DefaultRepoURL *string     `json:"default_repo_url"`
+	AgentSelector  AgentLabels `json:"agent_selector"`
+	CreatedAt      time.Time   `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
 
@@ -102,6 +111,11 @@ func (d *DiscordConnectedChannel) GetDefaultRepoURL() *string {
 	return d.DefaultRepoURL
 }
 
+// GetAgentSelector implements ConnectedChannel interface
+func (d *DiscordConnectedChannel) GetAgentSelector() AgentLabels {
+	return d.AgentSelector
+}
+
 // GetCreatedAt implements ConnectedChannel interface
 func (d *DiscordConnectedChannel) GetCreatedAt() time.Time {
 	return d.CreatedAt
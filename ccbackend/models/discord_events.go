@@ -10,6 +10,8 @@ type DiscordMessageEvent struct {
 	ThreadID *string
 	// Mentions contains the user IDs of all users mentioned in this message
 	Mentions []string
+	// Attachments are the files attached to this message
+	Attachments Attachments
 }
 
 type DiscordReactionEvent struct {
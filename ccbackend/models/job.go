@@ -1,7 +1,9 @@
 package models
 
 import (
+	"strings"
 	"time"
+	"unicode"
 )
 
 type JobType string
@@ -11,13 +13,114 @@ const (
 	JobTypeDiscord JobType = "discord"
 )
 
+// JobPriority orders queued jobs - higher-priority jobs are dispatched to agents first
+type JobPriority int
+
+const (
+	JobPriorityNormal JobPriority = 0
+	JobPriorityUrgent JobPriority = 1
+)
+
+// UrgentJobKeyword marks a request as urgent when included in the bot mention
+const UrgentJobKeyword = "!urgent"
+
+// ContainsUrgentKeyword returns true if the message text includes the urgent keyword as a separate word
+func ContainsUrgentKeyword(text string) bool {
+	for _, word := range strings.Fields(text) {
+		if isUrgentKeyword(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// StripUrgentKeyword removes the urgent keyword from the message text so that the agent only gets the request itself
+// The rest of the text keeps its whitespace, e.g. the line breaks of a code block
+func StripUrgentKeyword(text string) string {
+	var stripped strings.Builder
+	rest := text
+	for rest != "" {
+		wordStart := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsSpace(r) })
+		if wordStart < 0 {
+			stripped.WriteString(rest)
+			break
+		}
+		wordEnd := len(rest)
+		if end := strings.IndexFunc(rest[wordStart:], unicode.IsSpace); end >= 0 {
+			wordEnd = wordStart + end
+		}
+
+		if isUrgentKeyword(rest[wordStart:wordEnd]) {
+			// The keyword goes with the whitespace before it, or after it when it starts the text
+			rest = rest[wordEnd:]
+			if stripped.Len() == 0 {
+				rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+			}
+			continue
+		}
+		stripped.WriteString(rest[:wordEnd])
+		rest = rest[wordEnd:]
+	}
+	return stripped.String()
+}
+
+func isUrgentKeyword(word string) bool {
+	return strings.EqualFold(strings.TrimRight(word, ".,;:?!"), UrgentJobKeyword)
+}
+
+// StopJobCommand cancels the job when it is the only word of a bot mention in the job's thread, e.g. "@bot stop"
+const StopJobCommand = "stop"
+
+// IsStopJobCommand returns true if the message text is only the stop command besides user mentions
+func IsStopJobCommand(text string) bool {
+	var words []string
+	for _, word := range strings.Fields(text) {
+		if strings.HasPrefix(word, "<@") && strings.HasSuffix(word, ">") {
+			continue
+		}
+		words = append(words, word)
+	}
+	return len(words) == 1 && strings.EqualFold(strings.TrimRight(words[0], ".,;:?!"), StopJobCommand)
+}
+
+// JobStatus tracks the lifecycle of a job - closed jobs are kept as history
+type JobStatus string
+
+const (
+	JobStatusActive    JobStatus = "ACTIVE"
+	JobStatusCompleted JobStatus = "COMPLETED"
+	JobStatusAbandoned JobStatus = "ABANDONED"
+	JobStatusCancelled JobStatus = "CANCELLED"
+	JobStatusFailed    JobStatus = "FAILED"
+)
+
+// IsClosed returns true for the terminal statuses
+func (s JobStatus) IsClosed() bool {
+	switch s {
+	case JobStatusCompleted, JobStatusAbandoned, JobStatusCancelled, JobStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// ManualJobCompletionReason is the completion reason of jobs the job creator marked as complete from chat
+const ManualJobCompletionReason = "Job manually marked as complete"
+
 type Job struct {
 	// Common fields
-	ID        string    `json:"id"              db:"id"`
-	JobType   JobType   `json:"job_type"        db:"job_type"`
-	OrgID     OrgID     `json:"organization_id" db:"organization_id"`
-	CreatedAt time.Time `json:"created_at"      db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"      db:"updated_at"`
+	ID        string      `json:"id"              db:"id"`
+	JobType   JobType     `json:"job_type"        db:"job_type"`
+	OrgID     OrgID       `json:"organization_id" db:"organization_id"`
+	Priority  JobPriority `json:"priority"        db:"priority"`
+	Status    JobStatus   `json:"status"          db:"status"`
+	CreatedAt time.Time   `json:"created_at"      db:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"      db:"updated_at"`
+
+	// Set when the job is closed
+	CompletionReason string     `json:"completion_reason,omitempty" db:"completion_reason"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"      db:"completed_at"`
+	AssignedAgentID  string     `json:"assigned_agent_id,omitempty" db:"assigned_agent_id"`
 
 	// Polymorphic payload - only one populated based on JobType
 	SlackPayload   *SlackJobPayload   `json:"slack_payload,omitempty"`
@@ -50,3 +153,42 @@ type JobCreationResult struct {
 	Job    *Job              `json:"job"`
 	Status JobCreationStatus `json:"status"`
 }
+
+// Page sizes of job listings
+const (
+	DefaultJobsPageSize = 50
+	MaxJobsPageSize     = 100
+)
+
+// JobsFilter narrows a job listing - zero-valued fields do not filter
+type JobsFilter struct {
+	JobType   JobType
+	ChannelID string
+	Status    JobStatus
+	// AgentID matches jobs the agent holds or held at any point
+	AgentID string
+	// CreatorID is the Slack or Discord user ID of the user who started the job
+	CreatorID     string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Cursor is the ID of the last job of the previous page - job IDs are ULIDs, so they sort by creation time
+	Cursor string
+}
+
+// JobsPage is a page of a job listing, newest job first
+// NextCursor is empty on the last page.
+type JobsPage struct {
+	Jobs       []*Job `json:"jobs"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// JobDetail is a job with its processed messages, the history of its agent assignments
+// and the delivery state of the messages sent to its agents
+// Only the processed messages of the job's platform are set.
+type JobDetail struct {
+	Job                      *Job                         `json:"job"`
+	ProcessedSlackMessages   []*ProcessedSlackMessage     `json:"processed_slack_messages,omitempty"`
+	ProcessedDiscordMessages []*ProcessedDiscordMessage   `json:"processed_discord_messages,omitempty"`
+	AssignmentHistory        []*JobAssignmentHistoryEntry `json:"assignment_history"`
+	AgentMessages            []*AgentOutboxMessage        `json:"agent_messages"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainsUrgentKeyword(t *testing.T) {
+	t.Run("Keyword as a separate word", func(t *testing.T) {
+		assert.True(t, ContainsUrgentKeyword("<@U123> !urgent prod is down"))
+		assert.True(t, ContainsUrgentKeyword("<@U123> fix the login page !URGENT"))
+		assert.True(t, ContainsUrgentKeyword("<@U123> fix the login page, !urgent."))
+	})
+
+	t.Run("Keyword inside another word or without marker", func(t *testing.T) {
+		assert.False(t, ContainsUrgentKeyword("<@U123> this is not urgent"))
+		assert.False(t, ContainsUrgentKeyword("<@U123> see !urgently"))
+		assert.False(t, ContainsUrgentKeyword(""))
+	})
+}
+
+func TestStripUrgentKeyword(t *testing.T) {
+	t.Run("Removes the keyword with its whitespace", func(t *testing.T) {
+		assert.Equal(t, "<@U123> prod is down", StripUrgentKeyword("<@U123> !urgent prod is down"))
+		assert.Equal(t, "<@U123> fix the login page,", StripUrgentKeyword("<@U123> fix the login page, !URGENT."))
+		assert.Equal(t, "prod is down", StripUrgentKeyword("!urgent prod is down"))
+	})
+
+	t.Run("Keeps the rest of the text as it is", func(t *testing.T) {
+		assert.Equal(t, "<@U123> fix\n```\n  a := 1\n```", StripUrgentKeyword("<@U123> !urgent fix\n```\n  a := 1\n```"))
+		assert.Equal(t, "<@U123> see !urgently", StripUrgentKeyword("<@U123> see !urgently"))
+		assert.Equal(t, "", StripUrgentKeyword(""))
+	})
+}
+
+func TestIsStopJobCommand(t *testing.T) {
+	t.Run("Stop command in a bot mention", func(t *testing.T) {
+		assert.True(t, IsStopJobCommand("<@U123> stop"))
+		assert.True(t, IsStopJobCommand("<@U123> Stop!"))
+		assert.True(t, IsStopJobCommand("<@!123456> stop"))
+	})
+
+	t.Run("Stop as part of a longer message", func(t *testing.T) {
+		assert.False(t, IsStopJobCommand("<@U123> stop using tabs please"))
+		assert.False(t, IsStopJobCommand("<@U123> don't stop"))
+		assert.False(t, IsStopJobCommand("<@U123>"))
+	})
+}
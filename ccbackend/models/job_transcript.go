@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// TranscriptAuthor is who wrote a message of a job's transcript
+type TranscriptAuthor string
+
+const (
+	TranscriptAuthorUser   TranscriptAuthor = "USER"
+	TranscriptAuthorAgent  TranscriptAuthor = "AGENT"
+	TranscriptAuthorSystem TranscriptAuthor = "SYSTEM"
+)
+
+type TranscriptMessageType string
+
+const (
+	TranscriptMessageTypeUserMessage      TranscriptMessageType = "USER_MESSAGE"
+	TranscriptMessageTypeAssistantMessage TranscriptMessageType = "ASSISTANT_MESSAGE"
+	TranscriptMessageTypeSystemMessage    TranscriptMessageType = "SYSTEM_MESSAGE"
+	TranscriptMessageTypeArtifact         TranscriptMessageType = "ARTIFACT"
+)
+
+// JobTranscriptMessage is a message exchanged in a job's thread, in either direction
+// AuthorID is the Slack or Discord user ID of users and the agent ID of agents - it is empty for the backend.
+type JobTranscriptMessage struct {
+	ID                 string                `json:"id"                             db:"id"`
+	OrgID              OrgID                 `json:"organization_id"                db:"organization_id"`
+	JobID              string                `json:"job_id"                         db:"job_id"`
+	Author             TranscriptAuthor      `json:"author"                         db:"author"`
+	AuthorID           string                `json:"author_id,omitempty"            db:"author_id"`
+	MessageType        TranscriptMessageType `json:"message_type"                   db:"message_type"`
+	Content            string                `json:"content"                        db:"content"`
+	ProcessedMessageID string                `json:"processed_message_id,omitempty" db:"processed_message_id"`
+	CreatedAt          time.Time             `json:"created_at"                     db:"created_at"`
+}
+
+// ConversationHistory returns the user messages and assistant replies of a job's transcript as conversation turns
+// The user messages of pendingProcessedMessageIDs are left out - they are yet to be sent to the agent as prompts.
+func ConversationHistory(transcript []*JobTranscriptMessage, pendingProcessedMessageIDs []string) []ConversationTurn {
+	pending := make(map[string]bool, len(pendingProcessedMessageIDs))
+	for _, id := range pendingProcessedMessageIDs {
+		pending[id] = true
+	}
+
+	var history []ConversationTurn
+	for _, message := range transcript {
+		switch message.MessageType {
+		case TranscriptMessageTypeUserMessage:
+			if pending[message.ProcessedMessageID] {
+				continue
+			}
+			history = append(history, ConversationTurn{Author: ConversationAuthorUser, Message: message.Content})
+		case TranscriptMessageTypeAssistantMessage:
+			history = append(history, ConversationTurn{Author: ConversationAuthorAssistant, Message: message.Content})
+		}
+	}
+	return history
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversationHistory(t *testing.T) {
+	transcript := []*JobTranscriptMessage{
+		{Author: TranscriptAuthorUser, MessageType: TranscriptMessageTypeUserMessage, Content: "Fix the bug", ProcessedMessageID: "psm_1"},
+		{Author: TranscriptAuthorAgent, MessageType: TranscriptMessageTypeAssistantMessage, Content: "Fixed it", ProcessedMessageID: "psm_1"},
+		{Author: TranscriptAuthorSystem, MessageType: TranscriptMessageTypeSystemMessage, Content: "Agent disconnected"},
+		{Author: TranscriptAuthorAgent, MessageType: TranscriptMessageTypeArtifact, Content: "fix.diff"},
+		{Author: TranscriptAuthorUser, MessageType: TranscriptMessageTypeUserMessage, Content: "Add a test", ProcessedMessageID: "psm_2"},
+	}
+
+	t.Run("Returns user messages and assistant replies", func(t *testing.T) {
+		history := ConversationHistory(transcript, nil)
+
+		assert.Equal(t, []ConversationTurn{
+			{Author: ConversationAuthorUser, Message: "Fix the bug"},
+			{Author: ConversationAuthorAssistant, Message: "Fixed it"},
+			{Author: ConversationAuthorUser, Message: "Add a test"},
+		}, history)
+	})
+
+	t.Run("Leaves out pending user messages", func(t *testing.T) {
+		history := ConversationHistory(transcript, []string{"psm_2"})
+
+		assert.Equal(t, []ConversationTurn{
+			{Author: ConversationAuthorUser, Message: "Fix the bug"},
+			{Author: ConversationAuthorAssistant, Message: "Fixed it"},
+		}, history)
+	})
+}
@@ -1,14 +1,24 @@
 package models
 
+import (
+	"fmt"
+)
+
 // Message types
 const (
 	MessageTypeStartConversation = "start_conversation_v1"
 	MessageTypeUserMessage       = "user_message_v1"
 	MessageTypeAssistantMessage  = "assistant_message_v1"
+	MessageTypeAssistantDelta    = "assistant_delta_v1"
+	MessageTypeProgressEvent     = "progress_event_v1"
 	MessageTypeSystemMessage     = "system_message_v1"
 	MessageTypeProcessingMessage = "processing_message_v1"
 	MessageTypeCheckIdleJobs     = "check_idle_jobs_v1"
 	MessageTypeJobComplete       = "job_complete_v1"
+	MessageTypeCancelJob         = "cancel_job_v1"
+	MessageTypeJobClosed         = "job_closed_v1"
+	MessageTypeArtifact          = "artifact_v1"
+	MessageTypeError             = "error_v1"
 )
 
 type BaseMessage struct {
@@ -18,25 +28,59 @@ type BaseMessage struct {
 }
 
 type StartConversationPayload struct {
-	JobID              string `json:"job_id"`
-	Message            string `json:"message"`
-	ProcessedMessageID string `json:"processed_message_id"`
-	MessageLink        string `json:"message_link"`
+	JobID              string            `json:"job_id"`
+	Message            string            `json:"message"`
+	ProcessedMessageID string            `json:"processed_message_id"`
+	MessageLink        string            `json:"message_link"`
+	Attachments        []AgentAttachment `json:"attachments,omitempty"`
+	// History is the earlier conversation of a job handed over from another agent, oldest first
+	// It is context only - Message is the prompt to answer.
+	History []ConversationTurn `json:"history,omitempty"`
+}
+
+// Authors of the turns of a job's earlier conversation
+const (
+	ConversationAuthorUser      = "user"
+	ConversationAuthorAssistant = "assistant"
+)
+
+// ConversationTurn is a message of a job's earlier conversation
+type ConversationTurn struct {
+	Author  string `json:"author"`
+	Message string `json:"message"`
 }
 
 type UserMessagePayload struct {
+	JobID              string            `json:"job_id"`
+	Message            string            `json:"message"`
+	ProcessedMessageID string            `json:"processed_message_id"`
+	MessageLink        string            `json:"message_link"`
+	Attachments        []AgentAttachment `json:"attachments,omitempty"`
+}
+
+type AssistantMessagePayload struct {
 	JobID              string `json:"job_id"`
 	Message            string `json:"message"`
 	ProcessedMessageID string `json:"processed_message_id"`
-	MessageLink        string `json:"message_link"`
 }
 
-type AssistantMessagePayload struct {
+// AssistantDeltaPayload is a chunk of an assistant reply which is still being written
+// The final reply still arrives as an assistant message with the same ProcessedMessageID.
+type AssistantDeltaPayload struct {
 	JobID              string `json:"job_id"`
-	Message            string `json:"message"`
+	Delta              string `json:"delta"`
 	ProcessedMessageID string `json:"processed_message_id"`
 }
 
+// ProgressEventPayload is a step the agent took while working on a job, e.g. editing a file or running tests
+type ProgressEventPayload struct {
+	JobID  string            `json:"job_id"`
+	Kind   ProgressEventKind `json:"kind"`
+	Title  string            `json:"title"`
+	Detail string            `json:"detail,omitempty"`
+	Link   string            `json:"link,omitempty"`
+}
+
 type SystemMessagePayload struct {
 	Message            string `json:"message"`
 	ProcessedMessageID string `json:"processed_message_id"`
@@ -52,7 +96,57 @@ type CheckIdleJobsPayload struct {
 	// Empty payload - agent checks all its jobs
 }
 
+// CancelJobPayload asks the agent to stop working on the job
+// The job stays open, so the user can continue the conversation in the thread.
+type CancelJobPayload struct {
+	JobID string `json:"job_id"`
+}
+
+// JobClosedPayload tells the agent the backend closed one of its jobs, e.g. because it was idle for too long
+// The agent should stop working on the job and discard its session - later messages for the job are rejected.
+type JobClosedPayload struct {
+	JobID  string `json:"job_id"`
+	Reason string `json:"reason"`
+}
+
+// ArtifactPayload is a file the agent produced while working on a job, e.g. a diff, a test report or a screenshot,
+// which is uploaded into the job's thread
+type ArtifactPayload struct {
+	JobID      string          `json:"job_id"`
+	Attachment AgentAttachment `json:"attachment"`
+	Comment    string          `json:"comment,omitempty"`
+}
+
+// TranscriptContent describes the artifact in the job's transcript by its name and comment - not its content
+func (p ArtifactPayload) TranscriptContent() string {
+	if p.Comment == "" {
+		return p.Attachment.Name
+	}
+	return fmt.Sprintf("%s: %s", p.Attachment.Name, p.Comment)
+}
+
 type JobCompletePayload struct {
 	JobID  string `json:"job_id"`
 	Reason string `json:"reason"`
 }
+
+// AgentErrorCode tells the agent why the backend rejected one of its messages
+type AgentErrorCode string
+
+const (
+	// AgentErrorCodeJobNotFound means the job no longer exists, e.g. it was completed from chat
+	AgentErrorCodeJobNotFound AgentErrorCode = "job_not_found"
+	// AgentErrorCodeNotAssigned means the job is assigned to another agent
+	AgentErrorCodeNotAssigned AgentErrorCode = "not_assigned"
+	// AgentErrorCodeInvalidPayload means the message payload is malformed or missing required fields
+	AgentErrorCodeInvalidPayload AgentErrorCode = "invalid_payload"
+)
+
+// ErrorPayload tells the agent the backend rejected one of its messages, so it can stop or resync the job
+type ErrorPayload struct {
+	// MessageID is the ID of the agent's message which was rejected
+	MessageID string         `json:"message_id"`
+	JobID     string         `json:"job_id,omitempty"`
+	Code      AgentErrorCode `json:"code"`
+	Message   string         `json:"message"`
+}
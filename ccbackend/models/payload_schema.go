@@ -0,0 +1,140 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PayloadFieldType is the JSON type of a field of an agent message payload
+type PayloadFieldType string
+
+const (
+	PayloadFieldTypeString PayloadFieldType = "string"
+	PayloadFieldTypeObject PayloadFieldType = "object"
+)
+
+// PayloadField declares a field of an agent message payload
+// Fields which are not declared are ignored, so newer agents can send additional fields.
+type PayloadField struct {
+	Name string
+	Type PayloadFieldType
+	// Required fields must be present - required strings must not be blank
+	Required bool
+	// Fields declares the fields of an object field
+	Fields []PayloadField
+}
+
+// agentPayloadSchemas declares the payload of every message type agents send
+var agentPayloadSchemas = map[string][]PayloadField{
+	MessageTypeAssistantMessage: {
+		{Name: "job_id", Type: PayloadFieldTypeString, Required: true},
+		{Name: "processed_message_id", Type: PayloadFieldTypeString, Required: true},
+		{Name: "message", Type: PayloadFieldTypeString},
+	},
+	MessageTypeAssistantDelta: {
+		{Name: "job_id", Type: PayloadFieldTypeString, Required: true},
+		{Name: "processed_message_id", Type: PayloadFieldTypeString, Required: true},
+		{Name: "delta", Type: PayloadFieldTypeString},
+	},
+	MessageTypeProgressEvent: {
+		{Name: "job_id", Type: PayloadFieldTypeString, Required: true},
+		{Name: "kind", Type: PayloadFieldTypeString},
+		{Name: "title", Type: PayloadFieldTypeString, Required: true},
+		{Name: "detail", Type: PayloadFieldTypeString},
+		{Name: "link", Type: PayloadFieldTypeString},
+	},
+	MessageTypeArtifact: {
+		{Name: "job_id", Type: PayloadFieldTypeString, Required: true},
+		{Name: "attachment", Type: PayloadFieldTypeObject, Required: true, Fields: []PayloadField{
+			{Name: "name", Type: PayloadFieldTypeString, Required: true},
+			{Name: "mime_type", Type: PayloadFieldTypeString},
+			{Name: "content", Type: PayloadFieldTypeString, Required: true},
+		}},
+		{Name: "comment", Type: PayloadFieldTypeString},
+	},
+	MessageTypeSystemMessage: {
+		{Name: "job_id", Type: PayloadFieldTypeString, Required: true},
+		{Name: "processed_message_id", Type: PayloadFieldTypeString},
+		{Name: "message", Type: PayloadFieldTypeString},
+	},
+	MessageTypeProcessingMessage: {
+		{Name: "job_id", Type: PayloadFieldTypeString, Required: true},
+		{Name: "processed_message_id", Type: PayloadFieldTypeString, Required: true},
+	},
+	MessageTypeJobComplete: {
+		{Name: "job_id", Type: PayloadFieldTypeString, Required: true},
+		{Name: "reason", Type: PayloadFieldTypeString},
+	},
+}
+
+// ValidateAgentPayload checks the payload of a message from an agent, decoded from JSON, against the schema
+// declared for its message type and returns a descriptive error for the first violation
+// Message types without a schema are not validated.
+func ValidateAgentPayload(messageType string, payload any) error {
+	fields, ok := agentPayloadSchemas[messageType]
+	if !ok {
+		return nil
+	}
+
+	object, ok := payload.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s payload must be an object, got %s", messageType, jsonTypeName(payload))
+	}
+	if err := validatePayloadFields(object, fields, ""); err != nil {
+		return fmt.Errorf("%s payload: %w", messageType, err)
+	}
+	return nil
+}
+
+func validatePayloadFields(object map[string]any, fields []PayloadField, prefix string) error {
+	for _, field := range fields {
+		name := prefix + field.Name
+		value, present := object[field.Name]
+		if !present || value == nil {
+			if field.Required {
+				return fmt.Errorf("%s is required", name)
+			}
+			continue
+		}
+
+		switch field.Type {
+		case PayloadFieldTypeString:
+			str, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("%s must be a string, got %s", name, jsonTypeName(value))
+			}
+			if field.Required && strings.TrimSpace(str) == "" {
+				return fmt.Errorf("%s must not be empty", name)
+			}
+		case PayloadFieldTypeObject:
+			nested, ok := value.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%s must be an object, got %s", name, jsonTypeName(value))
+			}
+			if err := validatePayloadFields(nested, field.Fields, name+"."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonTypeName names the JSON type of a value decoded from JSON
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAgentPayload(t *testing.T) {
+	t.Run("Accepts payload matching the schema", func(t *testing.T) {
+		err := ValidateAgentPayload(MessageTypeAssistantMessage, map[string]any{
+			"job_id":               "j_1",
+			"processed_message_id": "psm_1",
+			"message":              "Done",
+			"model":                "newer agents may send fields the backend does not know",
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Rejects missing required field", func(t *testing.T) {
+		err := ValidateAgentPayload(MessageTypeAssistantMessage, map[string]any{"job_id": "j_1"})
+
+		assert.EqualError(t, err, "assistant_message_v1 payload: processed_message_id is required")
+	})
+
+	t.Run("Rejects blank required field", func(t *testing.T) {
+		err := ValidateAgentPayload(MessageTypeJobComplete, map[string]any{"job_id": "  "})
+
+		assert.EqualError(t, err, "job_complete_v1 payload: job_id must not be empty")
+	})
+
+	t.Run("Rejects field of the wrong type", func(t *testing.T) {
+		err := ValidateAgentPayload(MessageTypeProcessingMessage, map[string]any{
+			"job_id":               float64(42),
+			"processed_message_id": "psm_1",
+		})
+
+		assert.EqualError(t, err, "processing_message_v1 payload: job_id must be a string, got number")
+	})
+
+	t.Run("Validates nested object fields", func(t *testing.T) {
+		err := ValidateAgentPayload(MessageTypeArtifact, map[string]any{
+			"job_id":     "j_1",
+			"attachment": map[string]any{"name": "fix.diff"},
+		})
+
+		assert.EqualError(t, err, "artifact_v1 payload: attachment.content is required")
+	})
+
+	t.Run("Rejects payload which is not an object", func(t *testing.T) {
+		err := ValidateAgentPayload(MessageTypeSystemMessage, nil)
+
+		assert.EqualError(t, err, "system_message_v1 payload must be an object, got null")
+	})
+
+	t.Run("Message type without schema is not validated", func(t *testing.T) {
+		assert.NoError(t, ValidateAgentPayload("unknown_v1", "anything"))
+	})
+}
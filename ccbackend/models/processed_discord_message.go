@@ -10,6 +10,8 @@ const (
 	ProcessedDiscordMessageStatusQueued     ProcessedDiscordMessageStatus = "QUEUED"
 	ProcessedDiscordMessageStatusInProgress ProcessedDiscordMessageStatus = "IN_PROGRESS"
 	ProcessedDiscordMessageStatusCompleted  ProcessedDiscordMessageStatus = "COMPLETED"
+	ProcessedDiscordMessageStatusCancelled  ProcessedDiscordMessageStatus = "CANCELLED"
+	ProcessedDiscordMessageStatusFailed     ProcessedDiscordMessageStatus = "FAILED"
 )
 
 type ProcessedDiscordMessage struct {
@@ -18,6 +20,7 @@ type ProcessedDiscordMessage struct {
 	DiscordMessageID     string                        `json:"discord_message_id"     db:"discord_message_id"`
 	DiscordThreadID      string                        `json:"discord_thread_id"      db:"discord_thread_id"`
 	TextContent          string                        `json:"text_content"           db:"text_content"`
+	Attachments          Attachments                   `json:"attachments"            db:"attachments"`
 	Status               ProcessedDiscordMessageStatus `json:"status"                 db:"status"`
 	DiscordIntegrationID string                        `json:"discord_integration_id" db:"discord_integration_id"`
 	OrgID                OrgID                         `json:"organization_id"        db:"organization_id"`
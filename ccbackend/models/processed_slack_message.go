@@ -10,6 +10,8 @@ const (
 	ProcessedSlackMessageStatusQueued     ProcessedSlackMessageStatus = "QUEUED"
 	ProcessedSlackMessageStatusInProgress ProcessedSlackMessageStatus = "IN_PROGRESS"
 	ProcessedSlackMessageStatusCompleted  ProcessedSlackMessageStatus = "COMPLETED"
+	ProcessedSlackMessageStatusCancelled  ProcessedSlackMessageStatus = "CANCELLED"
+	ProcessedSlackMessageStatusFailed     ProcessedSlackMessageStatus = "FAILED"
 )
 
 type ProcessedSlackMessage struct {
@@ -18,6 +20,7 @@ type ProcessedSlackMessage struct {
 	SlackChannelID     string                      `json:"slack_channel_id"     db:"slack_channel_id"`
 	SlackTS            string                      `json:"slack_ts"             db:"slack_ts"`
 	TextContent        string                      `json:"text_content"         db:"text_content"`
+	Attachments        Attachments                 `json:"attachments"          db:"attachments"`
 	Status             ProcessedSlackMessageStatus `json:"status"               db:"status"`
 	SlackIntegrationID string                      `json:"slack_integration_id" db:"slack_integration_id"`
 	OrgID              OrgID                       `json:"organization_id"      db:"organization_id"`
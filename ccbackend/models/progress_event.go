@@ -0,0 +1,49 @@
+package models
+
+import (
+	"strings"
+)
+
+type ProgressEventKind string
+
+const (
+	ProgressEventKindFileEdit    ProgressEventKind = "file_edit"
+	ProgressEventKindCommand     ProgressEventKind = "command"
+	ProgressEventKindTests       ProgressEventKind = "tests"
+	ProgressEventKindPullRequest ProgressEventKind = "pull_request"
+	ProgressEventKindOther       ProgressEventKind = "other"
+)
+
+// maxProgressDetailLength keeps a single step from taking over the status message
+const maxProgressDetailLength = 200
+
+var progressEventKindEmojis = map[ProgressEventKind]string{
+	ProgressEventKindFileEdit:    "✏️",
+	ProgressEventKindCommand:     "💻",
+	ProgressEventKindTests:       "🧪",
+	ProgressEventKindPullRequest: "🔀",
+	ProgressEventKindOther:       "🔹",
+}
+
+// StatusLine renders the progress event as a markdown line of the job's status message
+// Unknown kinds are rendered like ProgressEventKindOther so newer agents can add kinds without a backend release.
+func (p ProgressEventPayload) StatusLine() string {
+	emoji, ok := progressEventKindEmojis[p.Kind]
+	if !ok {
+		emoji = progressEventKindEmojis[ProgressEventKindOther]
+	}
+
+	title := strings.TrimSpace(p.Title)
+	if link := strings.TrimSpace(p.Link); link != "" {
+		title = "[" + title + "](" + link + ")"
+	}
+
+	line := emoji + " " + title
+	if detail := strings.Join(strings.Fields(p.Detail), " "); detail != "" {
+		if runes := []rune(detail); len(runes) > maxProgressDetailLength {
+			detail = string(runes[:maxProgressDetailLength]) + "..."
+		}
+		line += " - " + detail
+	}
+	return line
+}
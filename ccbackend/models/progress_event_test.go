@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressEventStatusLine(t *testing.T) {
+	t.Run("Renders kind, title and detail", func(t *testing.T) {
+		event := ProgressEventPayload{Kind: ProgressEventKindTests, Title: "Running tests", Detail: "12 passed"}
+
+		assert.Equal(t, "🧪 Running tests - 12 passed", event.StatusLine())
+	})
+
+	t.Run("Renders link as markdown link", func(t *testing.T) {
+		event := ProgressEventPayload{
+			Kind:  ProgressEventKindPullRequest,
+			Title: "Opened PR #12",
+			Link:  "https://github.com/acme/app/pull/12",
+		}
+
+		assert.Equal(t, "🔀 [Opened PR #12](https://github.com/acme/app/pull/12)", event.StatusLine())
+	})
+
+	t.Run("Unknown kind is rendered as other", func(t *testing.T) {
+		event := ProgressEventPayload{Kind: "deploy", Title: "Deploying"}
+
+		assert.Equal(t, "🔹 Deploying", event.StatusLine())
+	})
+
+	t.Run("Multi-line detail is collapsed and truncated", func(t *testing.T) {
+		event := ProgressEventPayload{
+			Kind:   ProgressEventKindCommand,
+			Title:  "go build",
+			Detail: "line one\nline two " + strings.Repeat("x", 300),
+		}
+
+		line := event.StatusLine()
+
+		assert.NotContains(t, line, "\n")
+		assert.True(t, strings.HasSuffix(line, "..."))
+	})
+}
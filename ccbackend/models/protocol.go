@@ -0,0 +1,97 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Versions of the ccagent wire protocol the backend speaks
+// Agents declaring a newer version are talked to in CurrentProtocolVersion
+//
+// Version 2 agents acknowledge every cc_message they receive.
+const (
+	MinSupportedProtocolVersion = 1
+	CurrentProtocolVersion      = 2
+)
+
+// First protocol version in which agents acknowledge messages
+const messageAcksProtocolVersion = 2
+
+// legacyAgentCapabilities are assumed for agents which connect without declaring a protocol version
+var legacyAgentCapabilities = []string{
+	MessageTypeStartConversation,
+	MessageTypeUserMessage,
+	MessageTypeAssistantMessage,
+	MessageTypeSystemMessage,
+	MessageTypeProcessingMessage,
+	MessageTypeCheckIdleJobs,
+	MessageTypeJobComplete,
+}
+
+// requiredAgentCapabilities are the message types an agent must understand to be assigned jobs
+var requiredAgentCapabilities = []string{
+	MessageTypeStartConversation,
+	MessageTypeUserMessage,
+}
+
+// AgentProtocol is the protocol version and capabilities negotiated with an agent during the handshake
+// Capabilities are the message types the agent understands
+type AgentProtocol struct {
+	Version      int      `json:"protocol_version"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// NegotiateAgentProtocol validates the protocol version and capabilities an agent declared in its handshake
+// An agent declaring no version is a legacy agent speaking version 1 with every version 1 message type.
+// The returned error is the rejection reason sent to the agent.
+func NegotiateAgentProtocol(version, capabilities string) (AgentProtocol, error) {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return AgentProtocol{
+			Version:      MinSupportedProtocolVersion,
+			Capabilities: slices.Clone(legacyAgentCapabilities),
+		}, nil
+	}
+
+	agentVersion, err := strconv.Atoi(version)
+	if err != nil || agentVersion <= 0 {
+		return AgentProtocol{}, fmt.Errorf("invalid protocol version %q, expected a positive integer", version)
+	}
+	if agentVersion < MinSupportedProtocolVersion {
+		return AgentProtocol{}, fmt.Errorf(
+			"protocol version %d is no longer supported, minimum supported version is %d - please upgrade ccagent",
+			agentVersion,
+			MinSupportedProtocolVersion,
+		)
+	}
+
+	var agentCapabilities []string
+	for _, capability := range strings.Split(capabilities, ",") {
+		capability = strings.TrimSpace(capability)
+		if capability != "" && !slices.Contains(agentCapabilities, capability) {
+			agentCapabilities = append(agentCapabilities, capability)
+		}
+	}
+	for _, required := range requiredAgentCapabilities {
+		if !slices.Contains(agentCapabilities, required) {
+			return AgentProtocol{}, fmt.Errorf("missing required capability %s", required)
+		}
+	}
+
+	return AgentProtocol{
+		Version:      min(agentVersion, CurrentProtocolVersion),
+		Capabilities: agentCapabilities,
+	}, nil
+}
+
+// AcknowledgesMessages returns true if the agent acknowledges every message sent to it
+func (p AgentProtocol) AcknowledgesMessages() bool {
+	return p.Version >= messageAcksProtocolVersion
+}
+
+// Supports returns true if the agent understands the message type
+func (p AgentProtocol) Supports(messageType string) bool {
+	return slices.Contains(p.Capabilities, messageType)
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateAgentProtocol(t *testing.T) {
+	t.Run("Legacy agent without a version gets every version 1 message type", func(t *testing.T) {
+		protocol, err := NegotiateAgentProtocol("", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, protocol.Version)
+		assert.False(t, protocol.AcknowledgesMessages())
+		assert.True(t, protocol.Supports(MessageTypeStartConversation))
+		assert.True(t, protocol.Supports(MessageTypeCheckIdleJobs))
+	})
+
+	t.Run("Agent declaring capabilities only supports those", func(t *testing.T) {
+		protocol, err := NegotiateAgentProtocol("1", "start_conversation_v1, user_message_v1,user_message_v1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{MessageTypeStartConversation, MessageTypeUserMessage}, protocol.Capabilities)
+		assert.False(t, protocol.Supports(MessageTypeCheckIdleJobs))
+	})
+
+	t.Run("Newer agent is talked to in the current version", func(t *testing.T) {
+		protocol, err := NegotiateAgentProtocol("7", "start_conversation_v1,user_message_v1")
+
+		require.NoError(t, err)
+		assert.Equal(t, CurrentProtocolVersion, protocol.Version)
+		assert.True(t, protocol.AcknowledgesMessages())
+	})
+
+	t.Run("Rejects invalid version", func(t *testing.T) {
+		_, err := NegotiateAgentProtocol("v1", "start_conversation_v1,user_message_v1")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid protocol version")
+	})
+
+	t.Run("Rejects agent missing a required capability", func(t *testing.T) {
+		_, err := NegotiateAgentProtocol("1", "start_conversation_v1")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing required capability user_message_v1")
+	})
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// QueuedJob is a job with messages waiting for an agent, as seen by the per-organization dispatch queue
+type QueuedJob struct {
+	Job           *Job
+	FirstQueuedAt time.Time // CreatedAt of the job's oldest queued message
+}
+
+// userKey identifies the user who started the job, scoped to the integration so IDs from different
+// workspaces never collide
+func (q QueuedJob) userKey() string {
+	switch {
+	case q.Job.SlackPayload != nil:
+		return "slack:" + q.Job.SlackPayload.IntegrationID + ":" + q.Job.SlackPayload.UserID
+	case q.Job.DiscordPayload != nil:
+		return "discord:" + q.Job.DiscordPayload.IntegrationID + ":" + q.Job.DiscordPayload.UserID
+	default:
+		return ""
+	}
+}
+
+// channelKey identifies the channel the job was started in (the parent channel for Discord threads)
+func (q QueuedJob) channelKey() string {
+	switch {
+	case q.Job.SlackPayload != nil:
+		return "slack:" + q.Job.SlackPayload.IntegrationID + ":" + q.Job.SlackPayload.ChannelID
+	case q.Job.DiscordPayload != nil:
+		return "discord:" + q.Job.DiscordPayload.IntegrationID + ":" + q.Job.DiscordPayload.ChannelID
+	default:
+		return ""
+	}
+}
+
+// OrderQueuedJobs returns queued jobs in dispatch order: highest priority first, then FIFO by
+// first-queued time with per-user and per-channel fair share
+//
+// Each job gets a round number - how many older jobs its user or channel already has queued,
+// whichever is higher. Within a priority, jobs are dispatched round by round, oldest first within
+// a round, so every user and channel gets its oldest job dispatched before anyone gets a second one.
+// Ties are broken by job ID which keeps the order deterministic.
+func OrderQueuedJobs(jobs []QueuedJob) []QueuedJob {
+	ordered := make([]QueuedJob, len(jobs))
+	copy(ordered, jobs)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return queuedBefore(ordered[i], ordered[j])
+	})
+
+	userCounts := make(map[string]int)
+	channelCounts := make(map[string]int)
+	rounds := make(map[string]int, len(ordered))
+	for _, queuedJob := range ordered {
+		userKey, channelKey := queuedJob.userKey(), queuedJob.channelKey()
+		rounds[queuedJob.Job.ID] = max(userCounts[userKey], channelCounts[channelKey])
+		userCounts[userKey]++
+		channelCounts[channelKey]++
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Job.Priority != ordered[j].Job.Priority {
+			return ordered[i].Job.Priority > ordered[j].Job.Priority
+		}
+		roundI, roundJ := rounds[ordered[i].Job.ID], rounds[ordered[j].Job.ID]
+		if roundI != roundJ {
+			return roundI < roundJ
+		}
+		return queuedBefore(ordered[i], ordered[j])
+	})
+	return ordered
+}
+
+func queuedBefore(a, b QueuedJob) bool {
+	if !a.FirstQueuedAt.Equal(b.FirstQueuedAt) {
+		return a.FirstQueuedAt.Before(b.FirstQueuedAt)
+	}
+	return a.Job.ID < b.Job.ID
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newQueuedSlackJob(id, userID, channelID string, firstQueuedAt time.Time) QueuedJob {
+	return QueuedJob{
+		Job: &Job{
+			ID:      id,
+			JobType: JobTypeSlack,
+			SlackPayload: &SlackJobPayload{
+				UserID:        userID,
+				ChannelID:     channelID,
+				IntegrationID: "si_1",
+			},
+		},
+		FirstQueuedAt: firstQueuedAt,
+	}
+}
+
+func queuedJobIDs(jobs []QueuedJob) []string {
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.Job.ID)
+	}
+	return ids
+}
+
+func TestOrderQueuedJobs(t *testing.T) {
+	base := time.Date(2025, 9, 24, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+
+	t.Run("FIFO by first queued time", func(t *testing.T) {
+		jobs := []QueuedJob{
+			newQueuedSlackJob("job_c", "U3", "C3", at(3)),
+			newQueuedSlackJob("job_a", "U1", "C1", at(1)),
+			newQueuedSlackJob("job_b", "U2", "C2", at(2)),
+		}
+
+		assert.Equal(t, []string{"job_a", "job_b", "job_c"}, queuedJobIDs(OrderQueuedJobs(jobs)))
+	})
+
+	t.Run("Noisy channel does not starve other channels", func(t *testing.T) {
+		jobs := []QueuedJob{
+			newQueuedSlackJob("job_noisy1", "U1", "C_noisy", at(1)),
+			newQueuedSlackJob("job_noisy2", "U2", "C_noisy", at(2)),
+			newQueuedSlackJob("job_noisy3", "U3", "C_noisy", at(3)),
+			newQueuedSlackJob("job_quiet1", "U4", "C_quiet", at(4)),
+		}
+
+		assert.Equal(
+			t,
+			[]string{"job_noisy1", "job_quiet1", "job_noisy2", "job_noisy3"},
+			queuedJobIDs(OrderQueuedJobs(jobs)),
+		)
+	})
+
+	t.Run("Busy user does not starve other users in the same channel", func(t *testing.T) {
+		jobs := []QueuedJob{
+			newQueuedSlackJob("job_busy1", "U_busy", "C1", at(1)),
+			newQueuedSlackJob("job_busy2", "U_busy", "C2", at(2)),
+			newQueuedSlackJob("job_other", "U_other", "C3", at(3)),
+		}
+
+		assert.Equal(
+			t,
+			[]string{"job_busy1", "job_other", "job_busy2"},
+			queuedJobIDs(OrderQueuedJobs(jobs)),
+		)
+	})
+
+	t.Run("Ties are broken by job ID", func(t *testing.T) {
+		jobs := []QueuedJob{
+			newQueuedSlackJob("job_b", "U1", "C1", at(1)),
+			newQueuedSlackJob("job_a", "U2", "C2", at(1)),
+		}
+
+		assert.Equal(t, []string{"job_a", "job_b"}, queuedJobIDs(OrderQueuedJobs(jobs)))
+	})
+
+	t.Run("Same IDs on different platforms are separate users and channels", func(t *testing.T) {
+		discordJob := QueuedJob{
+			Job: &Job{
+				ID:      "job_discord",
+				JobType: JobTypeDiscord,
+				DiscordPayload: &DiscordJobPayload{
+					UserID:        "U1",
+					ChannelID:     "C1",
+					IntegrationID: "si_1",
+				},
+			},
+			FirstQueuedAt: at(3),
+		}
+		jobs := []QueuedJob{
+			newQueuedSlackJob("job_slack1", "U1", "C1", at(1)),
+			newQueuedSlackJob("job_slack2", "U1", "C1", at(2)),
+			discordJob,
+		}
+
+		assert.Equal(
+			t,
+			[]string{"job_slack1", "job_discord", "job_slack2"},
+			queuedJobIDs(OrderQueuedJobs(jobs)),
+		)
+	})
+
+	t.Run("Higher priority jobs are dispatched first", func(t *testing.T) {
+		urgentJob := newQueuedSlackJob("job_urgent", "U1", "C1", at(3))
+		urgentJob.Job.Priority = JobPriorityUrgent
+		jobs := []QueuedJob{
+			newQueuedSlackJob("job_old", "U2", "C2", at(1)),
+			newQueuedSlackJob("job_busy", "U1", "C1", at(2)),
+			urgentJob,
+		}
+
+		assert.Equal(
+			t,
+			[]string{"job_urgent", "job_old", "job_busy"},
+			queuedJobIDs(OrderQueuedJobs(jobs)),
+		)
+	})
+
+	t.Run("Does not modify the input", func(t *testing.T) {
+		jobs := []QueuedJob{
+			newQueuedSlackJob("job_b", "U2", "C2", at(2)),
+			newQueuedSlackJob("job_a", "U1", "C1", at(1)),
+		}
+
+		OrderQueuedJobs(jobs)
+
+		assert.Equal(t, []string{"job_b", "job_a"}, queuedJobIDs(jobs))
+	})
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/lib/pq"
@@ -27,6 +28,34 @@ type SettingKeyDefinition struct {
 	Key          string
 	Type         SettingType
 	DefaultValue any
+	// Validate rejects values of the expected type which are not allowed (optional)
+	Validate func(value any) error
+}
+
+// IdleJobTimeoutSettingKey is how long a job may be idle before the backend closes it, as a duration like "24h"
+// Jobs are idle while no message of theirs is queued or in progress. Empty disables closing idle jobs.
+const IdleJobTimeoutSettingKey = "org-idle_job_timeout"
+
+// MinIdleJobTimeout is the shortest idle job timeout an organization can configure
+const MinIdleJobTimeout = time.Minute
+
+// ParseIdleJobTimeout parses the value of the idle job timeout setting - zero means idle jobs are not closed
+func ParseIdleJobTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("idle job timeout must be a duration like 24h: %w", err)
+	}
+	if timeout < MinIdleJobTimeout {
+		return 0, fmt.Errorf("idle job timeout must be at least %s", MinIdleJobTimeout)
+	}
+	// Idle jobs are looked up by whole minutes of inactivity
+	if timeout%time.Minute != 0 {
+		return 0, fmt.Errorf("idle job timeout must be a whole number of minutes")
+	}
+	return timeout, nil
 }
 
 // SupportedSettings is the registry of all supported setting keys with their types
@@ -41,6 +70,15 @@ var SupportedSettings = map[string]SettingKeyDefinition{
 		Type:         SettingTypeBool,
 		DefaultValue: false,
 	},
+	IdleJobTimeoutSettingKey: {
+		Key:          IdleJobTimeoutSettingKey,
+		Type:         SettingTypeString,
+		DefaultValue: "",
+		Validate: func(value any) error {
+			_, err := ParseIdleJobTimeout(value.(string))
+			return err
+		},
+	},
 }
 
 // Setting represents a generic setting with all possible value types
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIdleJobTimeout(t *testing.T) {
+	t.Run("Parses whole minutes", func(t *testing.T) {
+		timeout, err := ParseIdleJobTimeout("90m")
+		assert.NoError(t, err)
+		assert.Equal(t, 90*time.Minute, timeout)
+
+		timeout, err = ParseIdleJobTimeout("24h")
+		assert.NoError(t, err)
+		assert.Equal(t, 24*time.Hour, timeout)
+	})
+
+	t.Run("Empty value disables closing idle jobs", func(t *testing.T) {
+		timeout, err := ParseIdleJobTimeout("")
+		assert.NoError(t, err)
+		assert.Zero(t, timeout)
+	})
+
+	t.Run("Rejects invalid timeouts", func(t *testing.T) {
+		for _, value := range []string{"forever", "30s", "90s", "1m59s"} {
+			_, err := ParseIdleJobTimeout(value)
+			assert.Error(t, err, value)
+		}
+	})
+}
@@ -6,4 +6,6 @@ type SlackMessageEvent struct {
 	Text     string
 	TS       string
 	ThreadTS string
+	// Attachments are the files shared with the message
+	Attachments Attachments
 }
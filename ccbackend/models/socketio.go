@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// SocketIORelayKind represents what the receiving replica should do with a relayed message
+type SocketIORelayKind string
+
+const (
+	SocketIORelayKindMessage    SocketIORelayKind = "message"
+	SocketIORelayKindDisconnect SocketIORelayKind = "disconnect"
+)
+
+// SocketIOConnection represents a Socket.IO client connected to one of the backend replicas
+type SocketIOConnection struct {
+	ClientID    string    `json:"client_id"       db:"client_id"`
+	InstanceID  string    `json:"instance_id"     db:"instance_id"`
+	OrgID       OrgID     `json:"organization_id" db:"organization_id"`
+	CCAgentID   string    `json:"ccagent_id"      db:"ccagent_id"`
+	ConnectedAt time.Time `json:"connected_at"    db:"connected_at"`
+}
+
+// SocketIORelayedMessage is a message which must be delivered by the replica holding the target client
+type SocketIORelayedMessage struct {
+	ID         string            `json:"id"          db:"id"`
+	InstanceID string            `json:"instance_id" db:"instance_id"`
+	ClientID   string            `json:"client_id"   db:"client_id"`
+	Kind       SocketIORelayKind `json:"kind"        db:"kind"`
+	Payload    []byte            `json:"payload"     db:"payload"` // JSON encoded message, empty for disconnects
+	CreatedAt  time.Time         `json:"created_at"  db:"created_at"`
+}
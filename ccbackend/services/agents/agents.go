@@ -2,8 +2,12 @@ package agents
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/samber/mo"
 
@@ -11,17 +15,47 @@ import (
 	"ccbackend/core"
 	"ccbackend/db"
 	"ccbackend/models"
+	"ccbackend/utils"
 )
 
+// How often WaitForDrainingAgents re-checks the job assignments of draining agents
+var drainPollInterval = 5 * time.Second
+
+// How long an agent has to acknowledge a message before it is resent - doubled with every attempt
+var agentMessageAckTimeout = 30 * time.Second
+
+// How many of the most recent outbox messages GetAgentMessages returns
+const agentMessagesLimit = 100
+
+// How long delivered and failed outbox messages are kept to show their delivery state
+var agentMessageRetention = 7 * 24 * time.Hour
+
+// How long a message received from an agent may be processed before a resent copy takes it over, e.g. because
+// the replica processing it stopped
+var agentInboundMessageProcessingTimeout = 5 * time.Minute
+
 type AgentsService struct {
 	agentsRepo     *db.PostgresAgentsRepository
+	outboxRepo     *db.PostgresAgentOutboxRepository
+	inboxRepo      *db.PostgresAgentInboxRepository
 	socketIOClient clients.SocketIOClient
+	// messageDedupeWindow is how long the IDs of messages received from agents are remembered
+	messageDedupeWindow time.Duration
 }
 
-func NewAgentsService(repo *db.PostgresAgentsRepository, socketIOClient clients.SocketIOClient) *AgentsService {
+func NewAgentsService(
+	repo *db.PostgresAgentsRepository,
+	outboxRepo *db.PostgresAgentOutboxRepository,
+	inboxRepo *db.PostgresAgentInboxRepository,
+	socketIOClient clients.SocketIOClient,
+	messageDedupeWindow time.Duration,
+) *AgentsService {
 	return &AgentsService{
-		agentsRepo:     repo,
-		socketIOClient: socketIOClient,
+		agentsRepo:          repo,
+		outboxRepo:          outboxRepo,
+		inboxRepo:           inboxRepo,
+		socketIOClient:      socketIOClient,
+		messageDedupeWindow: messageDedupeWindow,
 	}
 }
 
@@ -31,8 +65,10 @@ func (s *AgentsService) UpsertActiveAgent(
 	wsConnectionID string,
 	agentID string,
 	repoURL string,
+	maxConcurrency int,
+	labels models.AgentLabels,
 ) (*models.ActiveAgent, error) {
-	log.Printf("📋 Starting to upsert active agent for wsConnectionID: %s, agentID: %s, repoURL: %s", wsConnectionID, agentID, repoURL)
+	log.Printf("📋 Starting to upsert active agent for wsConnectionID: %s, agentID: %s, repoURL: %s, labels: %s", wsConnectionID, agentID, repoURL, labels)
 	if !core.IsValidULID(wsConnectionID) {
 		return nil, fmt.Errorf("ws_connection_id must be a valid ULID")
 	}
@@ -45,6 +81,14 @@ func (s *AgentsService) UpsertActiveAgent(
 	if repoURL == "" {
 		return nil, fmt.Errorf("repo_url cannot be empty")
 	}
+	if maxConcurrency < 0 {
+		return nil, fmt.Errorf("max_concurrency cannot be negative")
+	}
+	for key := range labels {
+		if strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("label keys cannot be empty")
+		}
+	}
 
 	agent := &models.ActiveAgent{
 		ID:             core.NewID("ag"),
@@ -52,6 +96,8 @@ func (s *AgentsService) UpsertActiveAgent(
 		OrgID:          orgID,
 		CCAgentID:      agentID,
 		RepoURL:        repoURL,
+		MaxConcurrency: maxConcurrency,
+		Labels:         labels,
 	}
 	if err := s.agentsRepo.UpsertActiveAgent(ctx, agent); err != nil {
 		return nil, fmt.Errorf("failed to upsert active agent: %w", err)
@@ -290,6 +336,44 @@ func (s *AgentsService) AssignAgentToJob(
 	return nil
 }
 
+// AssignAgentToJobWithinCapacity assigns the job to the agent unless the agent has reached its max concurrency
+// Returns false if the agent has no room for the job, e.g. because a concurrent assignment took its last slot.
+func (s *AgentsService) AssignAgentToJobWithinCapacity(
+	ctx context.Context,
+	orgID models.OrgID,
+	agentID, jobID string,
+) (bool, error) {
+	log.Printf("📋 Starting to assign agent %s to job %s within its capacity", agentID, jobID)
+	if !core.IsValidULID(agentID) {
+		return false, fmt.Errorf("agent ID must be a valid ULID")
+	}
+	if !core.IsValidULID(jobID) {
+		return false, fmt.Errorf("job ID must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return false, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	assignment := &models.AgentJobAssignment{
+		ID:      core.NewID("aji"),
+		AgentID: agentID,
+		JobID:   jobID,
+		OrgID:   orgID,
+	}
+
+	assigned, err := s.agentsRepo.AssignAgentToJobWithinCapacity(ctx, assignment)
+	if err != nil {
+		return false, fmt.Errorf("failed to assign agent to job: %w", err)
+	}
+	if !assigned {
+		log.Printf("📋 Completed successfully - agent %s has no capacity left for job %s", agentID, jobID)
+		return false, nil
+	}
+
+	log.Printf("📋 Completed successfully - assigned agent %s to job %s within its capacity", agentID, jobID)
+	return true, nil
+}
+
 func (s *AgentsService) UnassignAgentFromJob(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -314,6 +398,22 @@ func (s *AgentsService) UnassignAgentFromJob(
 		return core.ErrNotFound
 	}
 
+	// The agent is not working on the job anymore, so its prompts for the job must not be resent to it
+	failed, err := s.outboxRepo.FailPendingOutboxMessagesForJob(
+		ctx,
+		orgID,
+		jobID,
+		agentID,
+		models.AgentControlMessageTypes,
+		"agent was unassigned from the job",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to fail pending messages of unassigned agent: %w", err)
+	}
+	if failed > 0 {
+		log.Printf("📭 Gave up on %d pending message(s) of agent %s for job %s", failed, agentID, jobID)
+	}
+
 	log.Printf("📋 Completed successfully - unassigned agent %s from job %s", agentID, jobID)
 	return nil
 }
@@ -419,6 +519,62 @@ func (s *AgentsService) UpdateAgentLastActiveAt(
 	return nil
 }
 
+// UpdateAgentTelemetry refreshes the agent's last_active_at timestamp and stores the telemetry it reported
+func (s *AgentsService) UpdateAgentTelemetry(
+	ctx context.Context,
+	orgID models.OrgID,
+	wsConnectionID string,
+	telemetry models.AgentTelemetry,
+) error {
+	log.Printf("📋 Starting to update telemetry for agent with WS connection ID: %s", wsConnectionID)
+	if !core.IsValidULID(wsConnectionID) {
+		return fmt.Errorf("ws_connection_id must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return fmt.Errorf("organization_id must be a valid ULID")
+	}
+	if telemetry.RoundTripTimeMs != nil && *telemetry.RoundTripTimeMs < 0 {
+		return fmt.Errorf("rtt_ms cannot be negative")
+	}
+
+	updated, err := s.agentsRepo.UpdateAgentTelemetry(ctx, wsConnectionID, orgID, telemetry)
+	if err != nil {
+		return fmt.Errorf("failed to update agent telemetry: %w", err)
+	}
+	if !updated {
+		return core.ErrNotFound
+	}
+
+	log.Printf("📋 Completed successfully - updated telemetry for agent with WS connection %s", wsConnectionID)
+	return nil
+}
+
+// IncrementAgentValidationFailures counts a message of the agent which failed payload validation
+func (s *AgentsService) IncrementAgentValidationFailures(
+	ctx context.Context,
+	orgID models.OrgID,
+	wsConnectionID string,
+) error {
+	log.Printf("📋 Starting to count validation failure for agent with WS connection ID: %s", wsConnectionID)
+	if !core.IsValidULID(wsConnectionID) {
+		return fmt.Errorf("ws_connection_id must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	updated, err := s.agentsRepo.IncrementAgentValidationFailures(ctx, wsConnectionID, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to increment agent validation failures: %w", err)
+	}
+	if !updated {
+		return core.ErrNotFound
+	}
+
+	log.Printf("📋 Completed successfully - counted validation failure for agent with WS connection %s", wsConnectionID)
+	return nil
+}
+
 func (s *AgentsService) GetInactiveAgents(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -446,6 +602,197 @@ func (s *AgentsService) GetInactiveAgents(
 	return agents, nil
 }
 
+// MarkAgentDisconnected records that the agent's connection dropped, starting its reconnect grace period
+// Returns core.ErrNotFound if no agent is bound to the connection anymore, e.g. because it already reconnected.
+func (s *AgentsService) MarkAgentDisconnected(ctx context.Context, orgID models.OrgID, wsConnectionID string) error {
+	log.Printf("📋 Starting to mark agent with WS connection ID %s as disconnected", wsConnectionID)
+	if !core.IsValidULID(wsConnectionID) {
+		return fmt.Errorf("ws_connection_id must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	updated, err := s.agentsRepo.MarkAgentDisconnected(ctx, wsConnectionID, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to mark agent disconnected: %w", err)
+	}
+	if !updated {
+		return core.ErrNotFound
+	}
+
+	log.Printf("📋 Completed successfully - marked agent with WS connection %s as disconnected", wsConnectionID)
+	return nil
+}
+
+// GetDisconnectedAgents returns agents which have not reconnected within the grace period after their
+// connection dropped
+func (s *AgentsService) GetDisconnectedAgents(
+	ctx context.Context,
+	orgID models.OrgID,
+	gracePeriod time.Duration,
+) ([]*models.ActiveAgent, error) {
+	log.Printf("📋 Starting to get disconnected agents for organization %s (grace period: %s)", orgID, gracePeriod)
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+	if gracePeriod < 0 {
+		return nil, fmt.Errorf("grace period must not be negative")
+	}
+
+	agents, err := s.agentsRepo.GetDisconnectedAgents(ctx, orgID, int(gracePeriod.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disconnected agents: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - found %d disconnected agents", len(agents))
+	return agents, nil
+}
+
+// GetAllActiveAgents returns all agents registered for the organization, connected or not
+func (s *AgentsService) GetAllActiveAgents(ctx context.Context, orgID models.OrgID) ([]*models.ActiveAgent, error) {
+	log.Printf("📋 Starting to get all active agents for organization: %s", orgID)
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	agents, err := s.agentsRepo.GetAllActiveAgents(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all active agents: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - retrieved %d active agents", len(agents))
+	return agents, nil
+}
+
+// SetAgentDraining marks an agent as draining (no new job assignments) or returns it to service
+func (s *AgentsService) SetAgentDraining(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+	draining bool,
+) (*models.ActiveAgent, error) {
+	log.Printf("📋 Starting to set draining=%t for agent: %s", draining, id)
+	if !core.IsValidULID(id) {
+		return nil, fmt.Errorf("agent ID must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	maybeAgent, err := s.agentsRepo.UpdateAgentDraining(ctx, id, orgID, draining)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update agent draining: %w", err)
+	}
+	if !maybeAgent.IsPresent() {
+		return nil, core.ErrNotFound
+	}
+
+	log.Printf("📋 Completed successfully - set draining=%t for agent: %s", draining, id)
+	return maybeAgent.MustGet(), nil
+}
+
+// DrainAgentsOnRepo marks every agent working on the repository as draining
+// Returns only the agents it marked, so that callers can return them to service without touching agents
+// which were drained already
+func (s *AgentsService) DrainAgentsOnRepo(
+	ctx context.Context,
+	orgID models.OrgID,
+	repoURL string,
+) ([]*models.ActiveAgent, error) {
+	log.Printf("📋 Starting to drain agents on %s", repoURL)
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	allAgents, err := s.agentsRepo.GetAllActiveAgents(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all active agents: %w", err)
+	}
+
+	var drainedAgents []*models.ActiveAgent
+	for _, agent := range allAgents {
+		if agent.Draining || !utils.RepoURLsMatch(agent.RepoURL, repoURL) {
+			continue
+		}
+
+		maybeAgent, err := s.agentsRepo.UpdateAgentDraining(ctx, agent.ID, orgID, true)
+		if err != nil {
+			return drainedAgents, fmt.Errorf("failed to drain agent %s: %w", agent.ID, err)
+		}
+		// The agent may have been deregistered in the meantime
+		if drainedAgent, ok := maybeAgent.Get(); ok {
+			drainedAgents = append(drainedAgents, drainedAgent)
+		}
+	}
+
+	log.Printf("📋 Completed successfully - drained %d agents on %s", len(drainedAgents), repoURL)
+	return drainedAgents, nil
+}
+
+// WaitForDrainingAgents blocks until every draining agent working on the repository has no job assignments
+// Returns the drained agents, or an error if they still have jobs when the timeout expires
+func (s *AgentsService) WaitForDrainingAgents(
+	ctx context.Context,
+	orgID models.OrgID,
+	repoURL string,
+	timeout time.Duration,
+) ([]*models.ActiveAgent, error) {
+	log.Printf("📋 Starting to wait up to %s for draining agents on %s to finish their jobs", timeout, repoURL)
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("drain timeout must be positive")
+	}
+
+	allAgents, err := s.agentsRepo.GetAllActiveAgents(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all active agents: %w", err)
+	}
+	var drainingAgents []*models.ActiveAgent
+	for _, agent := range allAgents {
+		if agent.Draining && utils.RepoURLsMatch(agent.RepoURL, repoURL) {
+			drainingAgents = append(drainingAgents, agent)
+		}
+	}
+	if len(drainingAgents) == 0 {
+		log.Printf("📋 Completed successfully - no draining agents on %s to wait for", repoURL)
+		return nil, nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		busyAgents := 0
+		for _, agent := range drainingAgents {
+			jobIDs, err := s.agentsRepo.GetActiveAgentJobAssignments(ctx, agent.ID, orgID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get job assignments for agent %s: %w", agent.ID, err)
+			}
+			if len(jobIDs) > 0 {
+				busyAgents++
+			}
+		}
+		if busyAgents == 0 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out after %s waiting for %d draining agents to finish their jobs", timeout, busyAgents)
+		}
+		log.Printf("⏳ Waiting for %d of %d draining agents to finish their jobs", busyAgents, len(drainingAgents))
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped waiting for draining agents: %w", ctx.Err())
+		case <-time.After(drainPollInterval):
+		}
+	}
+
+	log.Printf("📋 Completed successfully - %d draining agents on %s have no jobs left", len(drainingAgents), repoURL)
+	return drainingAgents, nil
+}
+
 func (s *AgentsService) DisconnectAllActiveAgentsByOrganization(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -483,3 +830,477 @@ func (s *AgentsService) DisconnectAllActiveAgentsByOrganization(
 	log.Printf("📋 Completed successfully - disconnected all %d agents", len(agents))
 	return nil
 }
+
+// SendMessageToAgent stores the message in the agent outbox and sends it to the agent's connection
+// The message stays pending until the agent acknowledges it. A failed send is recorded and retried by
+// RedeliverAgentMessage instead of being returned, except for message types the agent does not support.
+func (s *AgentsService) SendMessageToAgent(
+	ctx context.Context,
+	orgID models.OrgID,
+	wsConnectionID string,
+	jobID string,
+	processedMessageID string,
+	msg models.BaseMessage,
+) error {
+	log.Printf(
+		"📋 Starting to send %s message %s to agent with WS connection ID: %s",
+		msg.Type,
+		msg.ID,
+		wsConnectionID,
+	)
+	if !core.IsValidULID(wsConnectionID) {
+		return fmt.Errorf("ws_connection_id must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return fmt.Errorf("organization_id must be a valid ULID")
+	}
+	if !core.IsValidULID(jobID) {
+		return fmt.Errorf("job_id must be a valid ULID")
+	}
+	if !core.IsValidULID(processedMessageID) {
+		return fmt.Errorf("processed_message_id must be a valid ULID")
+	}
+	if !core.IsValidULID(msg.ID) {
+		return fmt.Errorf("message ID must be a valid ULID")
+	}
+
+	maybeAgent, err := s.agentsRepo.GetAgentByWSConnectionID(ctx, wsConnectionID, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get agent by WS connection ID: %w", err)
+	}
+	if !maybeAgent.IsPresent() {
+		return fmt.Errorf("no agent found for WS connection %s: %w", wsConnectionID, core.ErrNotFound)
+	}
+	agent := maybeAgent.MustGet()
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	// The message is stored as attempted before it is sent so an acknowledgement can never beat the insert
+	outboxMessage := &models.AgentOutboxMessage{
+		ID:                 msg.ID,
+		OrgID:              orgID,
+		AgentID:            agent.ID,
+		JobID:              jobID,
+		ProcessedMessageID: processedMessageID,
+		MessageType:        msg.Type,
+		Payload:            payload,
+		Status:             models.AgentOutboxMessageStatusPending,
+		Attempts:           1,
+		NextAttemptAt:      time.Now().Add(agentMessageRetryDelay(1)),
+	}
+	if err := s.outboxRepo.CreateOutboxMessage(ctx, outboxMessage); err != nil {
+		return fmt.Errorf("failed to store message in agent outbox: %w", err)
+	}
+
+	if err := s.emitOutboxMessage(ctx, outboxMessage, agent.WSConnectionID, msg); err != nil {
+		return err
+	}
+
+	log.Printf("📋 Completed successfully - sent message %s to agent %s", msg.ID, agent.ID)
+	return nil
+}
+
+// RedeliverAgentMessage resends a pending message whose acknowledgement is overdue
+// The message is marked as failed if its agent is no longer registered.
+func (s *AgentsService) RedeliverAgentMessage(
+	ctx context.Context,
+	orgID models.OrgID,
+	message *models.AgentOutboxMessage,
+) error {
+	log.Printf(
+		"📋 Starting to redeliver message %s to agent %s (attempt %d)",
+		message.ID,
+		message.AgentID,
+		message.Attempts+1,
+	)
+	if !core.IsValidULID(string(orgID)) {
+		return fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	maybeAgent, err := s.agentsRepo.GetAgentByID(ctx, message.AgentID, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get agent: %w", err)
+	}
+	if !maybeAgent.IsPresent() {
+		reason := "agent is no longer registered"
+		if _, err := s.outboxRepo.MarkOutboxMessageFailed(ctx, message.ID, orgID, reason); err != nil {
+			return fmt.Errorf("failed to mark message as failed: %w", err)
+		}
+		log.Printf("📋 Completed successfully - agent %s is gone, gave up on message %s", message.AgentID, message.ID)
+		return nil
+	}
+	agent := maybeAgent.MustGet()
+
+	var msg models.BaseMessage
+	if err := json.Unmarshal(message.Payload, &msg); err != nil {
+		return fmt.Errorf("failed to unmarshal outbox message %s: %w", message.ID, err)
+	}
+
+	attempts := message.Attempts + 1
+	updated, err := s.outboxRepo.UpdateOutboxMessageAttempt(
+		ctx,
+		message.ID,
+		orgID,
+		attempts,
+		time.Now().Add(agentMessageRetryDelay(attempts)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record delivery attempt: %w", err)
+	}
+	if !updated {
+		// Acknowledged or given up on since it was loaded
+		log.Printf("📋 Completed successfully - message %s is no longer pending", message.ID)
+		return nil
+	}
+	message.Attempts = attempts
+
+	if err := s.emitOutboxMessage(ctx, message, agent.WSConnectionID, msg); err != nil {
+		return err
+	}
+
+	log.Printf("📋 Completed successfully - redelivered message %s to agent %s", message.ID, agent.ID)
+	return nil
+}
+
+// emitOutboxMessage sends a stored message, recording why it could not be sent
+func (s *AgentsService) emitOutboxMessage(
+	ctx context.Context,
+	message *models.AgentOutboxMessage,
+	wsConnectionID string,
+	msg models.BaseMessage,
+) error {
+	sendErr := s.socketIOClient.SendMessage(wsConnectionID, msg)
+	if sendErr == nil {
+		return nil
+	}
+
+	if _, err := s.recordDeliveryFailure(ctx, message.OrgID, message.ID, sendErr); err != nil {
+		return err
+	}
+	if errors.Is(sendErr, core.ErrUnsupportedMessageType) {
+		return fmt.Errorf("failed to send message %s: %w", message.ID, sendErr)
+	}
+
+	log.Printf("⚠️ Failed to send message %s to client %s, will retry: %v", message.ID, wsConnectionID, sendErr)
+	return nil
+}
+
+// RecordAgentMessageDeliveryFailure records why a pending message could not be handed to its agent, e.g. by the
+// replica a message was relayed to. Returns core.ErrNotFound if the message is not pending in the outbox.
+func (s *AgentsService) RecordAgentMessageDeliveryFailure(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+	reason error,
+) error {
+	log.Printf("📋 Starting to record delivery failure of agent message %s", id)
+	if !core.IsValidULID(id) {
+		return fmt.Errorf("message ID must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return fmt.Errorf("organization_id must be a valid ULID")
+	}
+	if reason == nil {
+		return fmt.Errorf("delivery failure reason cannot be nil")
+	}
+
+	updated, err := s.recordDeliveryFailure(ctx, orgID, id, reason)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return core.ErrNotFound
+	}
+
+	log.Printf("📋 Completed successfully - recorded delivery failure of agent message %s", id)
+	return nil
+}
+
+// recordDeliveryFailure fails a message the agent does not understand, as resending cannot help, and keeps any
+// other message pending with the error recorded so it is resent
+func (s *AgentsService) recordDeliveryFailure(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+	reason error,
+) (bool, error) {
+	if errors.Is(reason, core.ErrUnsupportedMessageType) {
+		updated, err := s.outboxRepo.MarkOutboxMessageFailed(ctx, id, orgID, reason.Error())
+		if err != nil {
+			return false, fmt.Errorf("failed to mark message as failed: %w", err)
+		}
+		return updated, nil
+	}
+
+	updated, err := s.outboxRepo.UpdateOutboxMessageLastError(ctx, id, orgID, reason.Error())
+	if err != nil {
+		return false, fmt.Errorf("failed to record delivery error: %w", err)
+	}
+	return updated, nil
+}
+
+// FailPendingAgentJobMessages gives up on the job's pending prompts to any agent, e.g. when the job is cancelled
+// Messages telling the agent to stop working on the job are still delivered.
+func (s *AgentsService) FailPendingAgentJobMessages(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+	reason string,
+) error {
+	log.Printf("📋 Starting to fail pending agent messages of job %s", jobID)
+	if !core.IsValidULID(jobID) {
+		return fmt.Errorf("job_id must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	failed, err := s.outboxRepo.FailPendingOutboxMessagesForJob(
+		ctx,
+		orgID,
+		jobID,
+		"",
+		models.AgentControlMessageTypes,
+		reason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to fail pending agent messages of job: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - failed %d pending agent messages of job %s", failed, jobID)
+	return nil
+}
+
+// MarkAgentMessageDelivered records the agent's acknowledgement of a message and returns the message
+// Returns core.ErrNotFound if the message is not pending, e.g. it is not tracked in the outbox or was
+// already acknowledged
+func (s *AgentsService) MarkAgentMessageDelivered(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+) (*models.AgentOutboxMessage, error) {
+	log.Printf("📋 Starting to mark agent message %s as delivered", id)
+	if !core.IsValidULID(id) {
+		return nil, fmt.Errorf("message ID must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	maybeMessage, err := s.outboxRepo.MarkOutboxMessageDelivered(ctx, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark agent message delivered: %w", err)
+	}
+	message, ok := maybeMessage.Get()
+	if !ok {
+		return nil, core.ErrNotFound
+	}
+
+	log.Printf("📋 Completed successfully - marked agent message %s as delivered", id)
+	return message, nil
+}
+
+// MarkAgentMessageFailed stops retrying a pending message
+func (s *AgentsService) MarkAgentMessageFailed(ctx context.Context, orgID models.OrgID, id, reason string) error {
+	log.Printf("📋 Starting to mark agent message %s as failed: %s", id, reason)
+	if !core.IsValidULID(id) {
+		return fmt.Errorf("message ID must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	updated, err := s.outboxRepo.MarkOutboxMessageFailed(ctx, id, orgID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to mark agent message failed: %w", err)
+	}
+	if !updated {
+		return core.ErrNotFound
+	}
+
+	log.Printf("📋 Completed successfully - marked agent message %s as failed", id)
+	return nil
+}
+
+// GetAgentMessagesDueForRetry returns pending messages the agent did not acknowledge in time
+func (s *AgentsService) GetAgentMessagesDueForRetry(
+	ctx context.Context,
+	orgID models.OrgID,
+) ([]*models.AgentOutboxMessage, error) {
+	log.Printf("📋 Starting to get agent messages due for retry for organization: %s", orgID)
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	// Messages the agent no longer needs are given up on instead of being resent or getting their job requeued
+	failed, err := s.outboxRepo.FailStaleOutboxMessages(
+		ctx,
+		orgID,
+		models.AgentControlMessageTypes,
+		"processed message is no longer in progress for the agent",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail stale agent messages: %w", err)
+	}
+	if failed > 0 {
+		log.Printf("📭 Gave up on %d stale agent message(s)", failed)
+	}
+
+	messages, err := s.outboxRepo.GetPendingOutboxMessagesDue(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent messages due for retry: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - found %d agent messages due for retry", len(messages))
+	return messages, nil
+}
+
+// GetAgentMessages returns the delivery state of the organization's most recent messages to agents
+// An empty jobID returns messages of all jobs
+func (s *AgentsService) GetAgentMessages(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+) ([]*models.AgentOutboxMessage, error) {
+	log.Printf("📋 Starting to get agent messages for organization: %s, job: %s", orgID, jobID)
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+	if jobID != "" && !core.IsValidULID(jobID) {
+		return nil, fmt.Errorf("job_id must be a valid ULID")
+	}
+
+	messages, err := s.outboxRepo.GetOutboxMessages(ctx, orgID, jobID, agentMessagesLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent messages: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - retrieved %d agent messages", len(messages))
+	return messages, nil
+}
+
+// ClaimAgentInboundMessage records a message received from an agent and reports whether it should be processed
+// Returns false for a duplicate - the agent already sent a message with the same ID within the dedupe window -
+// and core.ErrMessageInProgress if that message is still being processed.
+func (s *AgentsService) ClaimAgentInboundMessage(
+	ctx context.Context,
+	orgID models.OrgID,
+	agentID string,
+	messageID string,
+	messageType string,
+) (bool, error) {
+	log.Printf("📋 Starting to claim inbound message %s from agent %s", messageID, agentID)
+	if !core.IsValidULID(string(orgID)) {
+		return false, fmt.Errorf("organization_id must be a valid ULID")
+	}
+	if agentID == "" {
+		return false, fmt.Errorf("agent_id cannot be empty")
+	}
+	if messageID == "" {
+		return false, fmt.Errorf("message_id cannot be empty")
+	}
+
+	claimed, err := s.inboxRepo.ClaimInboxMessage(
+		ctx,
+		orgID,
+		agentID,
+		messageID,
+		messageType,
+		s.messageDedupeWindow,
+		agentInboundMessageProcessingTimeout,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim agent inbound message: %w", err)
+	}
+	if !claimed {
+		maybeStatus, err := s.inboxRepo.GetInboxMessageStatus(ctx, orgID, agentID, messageID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get agent inbound message status: %w", err)
+		}
+		// A message released in the meantime failed, so the agent's next copy is processed instead
+		if maybeStatus.OrElse(models.AgentInboxMessageStatusProcessing) == models.AgentInboxMessageStatusProcessing {
+			return false, core.ErrMessageInProgress
+		}
+	}
+
+	log.Printf("📋 Completed successfully - claimed inbound message %s: %t", messageID, claimed)
+	return claimed, nil
+}
+
+// CompleteAgentInboundMessage records that a claimed message was processed, so resent copies are dropped
+func (s *AgentsService) CompleteAgentInboundMessage(
+	ctx context.Context,
+	orgID models.OrgID,
+	agentID string,
+	messageID string,
+) error {
+	log.Printf("📋 Starting to complete inbound message %s from agent %s", messageID, agentID)
+	if !core.IsValidULID(string(orgID)) {
+		return fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	updated, err := s.inboxRepo.MarkInboxMessageProcessed(ctx, orgID, agentID, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to complete agent inbound message: %w", err)
+	}
+	if !updated {
+		log.Printf("⚠️ Inbound message %s from agent %s is no longer being processed", messageID, agentID)
+	}
+
+	log.Printf("📋 Completed successfully - completed inbound message %s", messageID)
+	return nil
+}
+
+// ReleaseAgentInboundMessage forgets a claimed message whose processing failed, so a resent copy is processed
+func (s *AgentsService) ReleaseAgentInboundMessage(
+	ctx context.Context,
+	orgID models.OrgID,
+	agentID string,
+	messageID string,
+) error {
+	log.Printf("📋 Starting to release inbound message %s from agent %s", messageID, agentID)
+	if !core.IsValidULID(string(orgID)) {
+		return fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	if _, err := s.inboxRepo.DeleteInboxMessage(ctx, orgID, agentID, messageID); err != nil {
+		return fmt.Errorf("failed to release agent inbound message: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - released inbound message %s", messageID)
+	return nil
+}
+
+// DeleteExpiredAgentInboundMessages removes the IDs of messages received before the dedupe window
+func (s *AgentsService) DeleteExpiredAgentInboundMessages(ctx context.Context) (int64, error) {
+	log.Printf("📋 Starting to delete agent inbound messages older than %s", s.messageDedupeWindow)
+
+	deleted, err := s.inboxRepo.DeleteExpiredInboxMessages(ctx, s.messageDedupeWindow)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired agent inbound messages: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - deleted %d expired agent inbound messages", deleted)
+	return deleted, nil
+}
+
+// DeleteFinishedAgentMessages removes outbox messages which were delivered or given up on before the retention period
+func (s *AgentsService) DeleteFinishedAgentMessages(ctx context.Context) (int64, error) {
+	log.Printf("📋 Starting to delete agent messages finished more than %s ago", agentMessageRetention)
+
+	deleted, err := s.outboxRepo.DeleteFinishedOutboxMessages(ctx, agentMessageRetention)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished agent messages: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - deleted %d finished agent messages", deleted)
+	return deleted, nil
+}
+
+// agentMessageRetryDelay is how long to wait for an acknowledgement after the given delivery attempt
+func agentMessageRetryDelay(attempts int) time.Duration {
+	return agentMessageAckTimeout * time.Duration(1<<max(attempts-1, 0))
+}
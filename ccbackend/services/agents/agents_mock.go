@@ -2,6 +2,7 @@ package agents
 
 import (
 	"context"
+	"time"
 
 	"github.com/samber/mo"
 	"github.com/stretchr/testify/mock"
@@ -20,8 +21,10 @@ func (m *MockAgentsService) UpsertActiveAgent(
 	wsConnectionID string,
 	agentID string,
 	repoURL string,
+	maxConcurrency int,
+	labels models.AgentLabels,
 ) (*models.ActiveAgent, error) {
-	args := m.Called(ctx, orgID, wsConnectionID, agentID, repoURL)
+	args := m.Called(ctx, orgID, wsConnectionID, agentID, repoURL, maxConcurrency, labels)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -100,6 +103,15 @@ func (m *MockAgentsService) AssignAgentToJob(
 	return args.Error(0)
 }
 
+func (m *MockAgentsService) AssignAgentToJobWithinCapacity(
+	ctx context.Context,
+	orgID models.OrgID,
+	agentID, jobID string,
+) (bool, error) {
+	args := m.Called(ctx, orgID, agentID, jobID)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockAgentsService) UnassignAgentFromJob(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -148,6 +160,25 @@ func (m *MockAgentsService) UpdateAgentLastActiveAt(
 	return args.Error(0)
 }
 
+func (m *MockAgentsService) UpdateAgentTelemetry(
+	ctx context.Context,
+	orgID models.OrgID,
+	wsConnectionID string,
+	telemetry models.AgentTelemetry,
+) error {
+	args := m.Called(ctx, orgID, wsConnectionID, telemetry)
+	return args.Error(0)
+}
+
+func (m *MockAgentsService) IncrementAgentValidationFailures(
+	ctx context.Context,
+	orgID models.OrgID,
+	wsConnectionID string,
+) error {
+	args := m.Called(ctx, orgID, wsConnectionID)
+	return args.Error(0)
+}
+
 func (m *MockAgentsService) GetInactiveAgents(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -160,6 +191,23 @@ func (m *MockAgentsService) GetInactiveAgents(
 	return args.Get(0).([]*models.ActiveAgent), args.Error(1)
 }
 
+func (m *MockAgentsService) MarkAgentDisconnected(ctx context.Context, orgID models.OrgID, wsConnectionID string) error {
+	args := m.Called(ctx, orgID, wsConnectionID)
+	return args.Error(0)
+}
+
+func (m *MockAgentsService) GetDisconnectedAgents(
+	ctx context.Context,
+	orgID models.OrgID,
+	gracePeriod time.Duration,
+) ([]*models.ActiveAgent, error) {
+	args := m.Called(ctx, orgID, gracePeriod)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ActiveAgent), args.Error(1)
+}
+
 func (m *MockAgentsService) DisconnectAllActiveAgentsByOrganization(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -167,3 +215,170 @@ func (m *MockAgentsService) DisconnectAllActiveAgentsByOrganization(
 	args := m.Called(ctx, orgID)
 	return args.Error(0)
 }
+
+func (m *MockAgentsService) GetAllActiveAgents(ctx context.Context, orgID models.OrgID) ([]*models.ActiveAgent, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ActiveAgent), args.Error(1)
+}
+
+func (m *MockAgentsService) SetAgentDraining(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+	draining bool,
+) (*models.ActiveAgent, error) {
+	args := m.Called(ctx, orgID, id, draining)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ActiveAgent), args.Error(1)
+}
+
+func (m *MockAgentsService) DrainAgentsOnRepo(
+	ctx context.Context,
+	orgID models.OrgID,
+	repoURL string,
+) ([]*models.ActiveAgent, error) {
+	args := m.Called(ctx, orgID, repoURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ActiveAgent), args.Error(1)
+}
+
+func (m *MockAgentsService) WaitForDrainingAgents(
+	ctx context.Context,
+	orgID models.OrgID,
+	repoURL string,
+	timeout time.Duration,
+) ([]*models.ActiveAgent, error) {
+	args := m.Called(ctx, orgID, repoURL, timeout)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ActiveAgent), args.Error(1)
+}
+
+func (m *MockAgentsService) SendMessageToAgent(
+	ctx context.Context,
+	orgID models.OrgID,
+	wsConnectionID string,
+	jobID string,
+	processedMessageID string,
+	msg models.BaseMessage,
+) error {
+	args := m.Called(ctx, orgID, wsConnectionID, jobID, processedMessageID, msg)
+	return args.Error(0)
+}
+
+func (m *MockAgentsService) RedeliverAgentMessage(
+	ctx context.Context,
+	orgID models.OrgID,
+	message *models.AgentOutboxMessage,
+) error {
+	args := m.Called(ctx, orgID, message)
+	return args.Error(0)
+}
+
+func (m *MockAgentsService) MarkAgentMessageDelivered(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+) (*models.AgentOutboxMessage, error) {
+	args := m.Called(ctx, orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AgentOutboxMessage), args.Error(1)
+}
+
+func (m *MockAgentsService) MarkAgentMessageFailed(ctx context.Context, orgID models.OrgID, id, reason string) error {
+	args := m.Called(ctx, orgID, id, reason)
+	return args.Error(0)
+}
+
+func (m *MockAgentsService) RecordAgentMessageDeliveryFailure(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+	reason error,
+) error {
+	args := m.Called(ctx, orgID, id, reason)
+	return args.Error(0)
+}
+
+func (m *MockAgentsService) FailPendingAgentJobMessages(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID, reason string,
+) error {
+	args := m.Called(ctx, orgID, jobID, reason)
+	return args.Error(0)
+}
+
+func (m *MockAgentsService) GetAgentMessagesDueForRetry(
+	ctx context.Context,
+	orgID models.OrgID,
+) ([]*models.AgentOutboxMessage, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AgentOutboxMessage), args.Error(1)
+}
+
+func (m *MockAgentsService) GetAgentMessages(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+) ([]*models.AgentOutboxMessage, error) {
+	args := m.Called(ctx, orgID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AgentOutboxMessage), args.Error(1)
+}
+
+func (m *MockAgentsService) ClaimAgentInboundMessage(
+	ctx context.Context,
+	orgID models.OrgID,
+	agentID string,
+	messageID string,
+	messageType string,
+) (bool, error) {
+	args := m.Called(ctx, orgID, agentID, messageID, messageType)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAgentsService) CompleteAgentInboundMessage(
+	ctx context.Context,
+	orgID models.OrgID,
+	agentID string,
+	messageID string,
+) error {
+	args := m.Called(ctx, orgID, agentID, messageID)
+	return args.Error(0)
+}
+
+func (m *MockAgentsService) ReleaseAgentInboundMessage(
+	ctx context.Context,
+	orgID models.OrgID,
+	agentID string,
+	messageID string,
+) error {
+	args := m.Called(ctx, orgID, agentID, messageID)
+	return args.Error(0)
+}
+
+func (m *MockAgentsService) DeleteExpiredAgentInboundMessages(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAgentsService) DeleteFinishedAgentMessages(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
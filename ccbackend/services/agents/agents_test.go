@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"ccbackend/clients/socketio"
@@ -31,7 +33,10 @@ func setupTestService(t *testing.T) (*AgentsService, services.JobsService, *mode
 
 	// Create repositories
 	agentsRepo := db.NewPostgresAgentsRepository(dbConn, cfg.DatabaseSchema)
+	outboxRepo := db.NewPostgresAgentOutboxRepository(dbConn, cfg.DatabaseSchema)
+	inboxRepo := db.NewPostgresAgentInboxRepository(dbConn, cfg.DatabaseSchema)
 	jobsRepo := db.NewPostgresJobsRepository(dbConn, cfg.DatabaseSchema)
+	jobTranscriptsRepo := db.NewPostgresJobTranscriptsRepository(dbConn, cfg.DatabaseSchema)
 	messagesRepo := db.NewPostgresProcessedSlackMessagesRepository(dbConn, cfg.DatabaseSchema)
 	discordMessagesRepo := db.NewPostgresProcessedDiscordMessagesRepository(dbConn, cfg.DatabaseSchema)
 	usersRepo := db.NewPostgresUsersRepository(dbConn, cfg.DatabaseSchema)
@@ -44,10 +49,16 @@ func setupTestService(t *testing.T) (*AgentsService, services.JobsService, *mode
 	require.NoError(t, err, "Failed to create test slack integration")
 
 	txManager := txmanager.NewTransactionManager(dbConn)
-	agentsService := NewAgentsService(agentsRepo, nil)
+	agentsService := NewAgentsService(agentsRepo, outboxRepo, inboxRepo, nil, time.Hour)
 	slackMessagesService := slackmessages.NewSlackMessagesService(messagesRepo)
 	discordMessagesService := discordmessages.NewDiscordMessagesService(discordMessagesRepo)
-	jobsService := jobs.NewJobsService(jobsRepo, slackMessagesService, discordMessagesService, txManager)
+	jobsService := jobs.NewJobsService(
+		jobsRepo,
+		jobTranscriptsRepo,
+		slackMessagesService,
+		discordMessagesService,
+		txManager,
+	)
 
 	cleanup := func() {
 		// Clean up test data
@@ -78,6 +89,8 @@ func TestAgentsService(t *testing.T) {
 				wsConnectionID,
 				agentID,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 
 			require.NoError(t, err)
@@ -130,6 +143,8 @@ func TestAgentsService(t *testing.T) {
 				wsConnectionID,
 				agentID,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -171,7 +186,7 @@ func TestAgentsService(t *testing.T) {
 
 		t.Run("EmptyWSConnectionID", func(t *testing.T) {
 			agentID := core.NewID("ccaid")
-			_, err := agentsService.UpsertActiveAgent(context.Background(), orgID, "", agentID, "github.com/test/invalid")
+			_, err := agentsService.UpsertActiveAgent(context.Background(), orgID, "", agentID, "github.com/test/invalid", 0, nil)
 
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), "ws_connection_id must be a valid ULID")
@@ -179,12 +194,51 @@ func TestAgentsService(t *testing.T) {
 
 		t.Run("EmptyOrganizationID", func(t *testing.T) {
 			agentID := core.NewID("ccaid")
-			_, err := agentsService.UpsertActiveAgent(context.Background(), "", core.NewID("wsc"), agentID, "github.com/test/invalid")
+			_, err := agentsService.UpsertActiveAgent(context.Background(), "", core.NewID("wsc"), agentID, "github.com/test/invalid", 0, nil)
 
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), "organization_id must be a valid ULID")
 		})
 
+		t.Run("NegativeMaxConcurrency", func(t *testing.T) {
+			agentID := core.NewID("ccaid")
+			_, err := agentsService.UpsertActiveAgent(context.Background(), orgID, core.NewID("wsc"), agentID, "github.com/test/repo", -1, nil)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "max_concurrency cannot be negative")
+		})
+
+		t.Run("EmptyLabelKey", func(t *testing.T) {
+			agentID := core.NewID("ccaid")
+			labels := models.AgentLabels{"": "payments"}
+			_, err := agentsService.UpsertActiveAgent(context.Background(), orgID, core.NewID("wsc"), agentID, "github.com/test/repo", 0, labels)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "label keys cannot be empty")
+		})
+
+		t.Run("Persists labels", func(t *testing.T) {
+			labels := models.AgentLabels{"team": "payments", "env": "staging"}
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				"github.com/test/repo",
+				0,
+				labels,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+
+			assert.Equal(t, labels, agent.Labels)
+
+			maybeFetched, err := agentsService.GetAgentByID(context.Background(), orgID, agent.ID)
+			require.NoError(t, err)
+			require.True(t, maybeFetched.IsPresent())
+			assert.Equal(t, labels, maybeFetched.MustGet().Labels)
+		})
+
 		t.Run("UpsertBehavior - Updates existing agent", func(t *testing.T) {
 			wsConnectionID1 := core.NewID("wsc")
 			wsConnectionID2 := core.NewID("wsc")
@@ -197,6 +251,8 @@ func TestAgentsService(t *testing.T) {
 				wsConnectionID1,
 				agentID,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent1.ID) }()
@@ -211,6 +267,8 @@ func TestAgentsService(t *testing.T) {
 				wsConnectionID2,
 				agentID,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -246,6 +304,8 @@ func TestAgentsService(t *testing.T) {
 				wsConnectionID,
 				agentID1,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent1.ID) }()
@@ -257,6 +317,8 @@ func TestAgentsService(t *testing.T) {
 				wsConnectionID,
 				agentID2,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent2.ID) }()
@@ -278,6 +340,8 @@ func TestAgentsService(t *testing.T) {
 				wsConnectionID,
 				agentID,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -335,6 +399,8 @@ func TestAgentsService(t *testing.T) {
 				wsConnectionID,
 				agentID,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -404,6 +470,8 @@ func TestAgentsService(t *testing.T) {
 				core.NewID("wsc"),
 				agentID1,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent1.ID) }()
@@ -426,6 +494,8 @@ func TestAgentsService(t *testing.T) {
 				core.NewID("wsc"),
 				agentID2,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -442,6 +512,8 @@ func TestAgentsService(t *testing.T) {
 				core.NewID("wsc"),
 				agentID3,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent3.ID) }()
@@ -497,6 +569,8 @@ func TestAgentsService(t *testing.T) {
 				core.NewID("wsc"),
 				agentIDBusy1,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -522,6 +596,8 @@ func TestAgentsService(t *testing.T) {
 				core.NewID("wsc"),
 				agentIDBusy2,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -556,6 +632,8 @@ func TestAgentsService(t *testing.T) {
 				wsConnectionID,
 				agentID,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
@@ -602,6 +680,123 @@ func TestAgentsService(t *testing.T) {
 		})
 	})
 
+	t.Run("UpdateAgentTelemetry", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			wsConnectionID := core.NewID("wsc")
+			agentID := core.NewID("ccaid")
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				wsConnectionID,
+				agentID,
+				"github.com/test/repo",
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+
+			// Handshake metadata
+			err = agentsService.UpdateAgentTelemetry(
+				context.Background(),
+				orgID,
+				wsConnectionID,
+				models.AgentTelemetry{Version: "1.2.3", OS: "linux", Hostname: "build-1"},
+			)
+			require.NoError(t, err)
+
+			// Ping with a round-trip time only keeps the stored metadata
+			rttMs := int64(42)
+			err = agentsService.UpdateAgentTelemetry(
+				context.Background(),
+				orgID,
+				wsConnectionID,
+				models.AgentTelemetry{RoundTripTimeMs: &rttMs},
+			)
+			require.NoError(t, err)
+
+			maybeUpdatedAgent, err := agentsService.GetAgentByID(context.Background(), orgID, agent.ID)
+			require.NoError(t, err)
+			require.True(t, maybeUpdatedAgent.IsPresent())
+			updatedAgent := maybeUpdatedAgent.MustGet()
+			assert.Equal(t, "1.2.3", updatedAgent.Version)
+			assert.Equal(t, "linux", updatedAgent.OS)
+			assert.Equal(t, "build-1", updatedAgent.Hostname)
+			require.NotNil(t, updatedAgent.AgentReportedRTTMs)
+			assert.Equal(t, int64(42), *updatedAgent.AgentReportedRTTMs)
+		})
+
+		t.Run("NegativeRoundTripTime", func(t *testing.T) {
+			rttMs := int64(-1)
+			err := agentsService.UpdateAgentTelemetry(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				models.AgentTelemetry{RoundTripTimeMs: &rttMs},
+			)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "rtt_ms cannot be negative")
+		})
+
+		t.Run("NotFound", func(t *testing.T) {
+			err := agentsService.UpdateAgentTelemetry(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				models.AgentTelemetry{Version: "1.2.3"},
+			)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, core.ErrNotFound))
+		})
+	})
+
+	t.Run("IncrementAgentValidationFailures", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			wsConnectionID := core.NewID("wsc")
+			agentID := core.NewID("ccaid")
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				wsConnectionID,
+				agentID,
+				"github.com/test/repo",
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+			assert.Equal(t, 0, agent.ValidationFailures)
+
+			for range 2 {
+				err = agentsService.IncrementAgentValidationFailures(context.Background(), orgID, wsConnectionID)
+				require.NoError(t, err)
+			}
+
+			// The count survives the agent reconnecting
+			_, err = agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				agentID,
+				"github.com/test/repo",
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+
+			maybeUpdatedAgent, err := agentsService.GetAgentByID(context.Background(), orgID, agent.ID)
+			require.NoError(t, err)
+			require.True(t, maybeUpdatedAgent.IsPresent())
+			assert.Equal(t, 2, maybeUpdatedAgent.MustGet().ValidationFailures)
+		})
+
+		t.Run("NotFound", func(t *testing.T) {
+			err := agentsService.IncrementAgentValidationFailures(context.Background(), orgID, core.NewID("wsc"))
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, core.ErrNotFound))
+		})
+	})
+
 	t.Run("GetInactiveAgents", func(t *testing.T) {
 		t.Run("Success", func(t *testing.T) {
 			// Create agents with different last_active_at timestamps
@@ -614,6 +809,8 @@ func TestAgentsService(t *testing.T) {
 				wsConnectionID1,
 				agentID1,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent1.ID) }()
@@ -627,6 +824,8 @@ func TestAgentsService(t *testing.T) {
 				wsConnectionID2,
 				agentID2,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent2.ID) }()
@@ -676,6 +875,8 @@ func TestAgentsService(t *testing.T) {
 				wsConnectionID,
 				agentID,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
@@ -703,138 +904,1029 @@ func TestAgentsService(t *testing.T) {
 		})
 	})
 
-	t.Run("DisconnectAllActiveAgentsByOrganization", func(t *testing.T) {
-		// Create a mock Socket.IO client for these tests
-		mockSocketIO := &socketio.MockSocketIOClient{}
-
-		// Create test service with mock
-		cfg, err := testutils.LoadTestConfig()
-		require.NoError(t, err)
-		dbConn, err := db.NewConnection(cfg.DatabaseURL)
-		require.NoError(t, err, "Failed to create database connection")
-		defer dbConn.Close()
-
-		agentsRepo := db.NewPostgresAgentsRepository(dbConn, cfg.DatabaseSchema)
-		testServiceWithMock := NewAgentsService(agentsRepo, mockSocketIO)
-
-		t.Run("Success - disconnects all agents", func(t *testing.T) {
-			// Create multiple test agents
-			agentID1 := core.NewID("ccaid")
-			agent1, err := testServiceWithMock.UpsertActiveAgent(
+	t.Run("SetAgentDraining", func(t *testing.T) {
+		t.Run("Success - drain survives reconnect", func(t *testing.T) {
+			agentID := core.NewID("ccaid")
+			agent, err := agentsService.UpsertActiveAgent(
 				context.Background(),
 				orgID,
 				core.NewID("wsc"),
-				agentID1,
+				agentID,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
-			defer func() { _ = testServiceWithMock.DeleteActiveAgent(context.Background(), orgID, agent1.ID) }()
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+			assert.False(t, agent.Draining)
 
-			agentID2 := core.NewID("ccaid")
-			agent2, err := testServiceWithMock.UpsertActiveAgent(
+			drainedAgent, err := agentsService.SetAgentDraining(context.Background(), orgID, agent.ID, true)
+			require.NoError(t, err)
+			assert.True(t, drainedAgent.Draining)
+
+			// Reconnecting with a new connection must not return the agent to service
+			reconnectedAgent, err := agentsService.UpsertActiveAgent(
 				context.Background(),
 				orgID,
 				core.NewID("wsc"),
-				agentID2,
+				agentID,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
-			defer func() { _ = testServiceWithMock.DeleteActiveAgent(context.Background(), orgID, agent2.ID) }()
+			assert.Equal(t, agent.ID, reconnectedAgent.ID)
+			assert.True(t, reconnectedAgent.Draining)
 
-			// Set up mock expectations - both agents should be disconnected
-			mockSocketIO.On("DisconnectClientByID", agentID1).Return(nil)
-			mockSocketIO.On("DisconnectClientByID", agentID2).Return(nil)
-
-			// Call the disconnect function
-			err = testServiceWithMock.DisconnectAllActiveAgentsByOrganization(context.Background(), orgID)
+			undrainedAgent, err := agentsService.SetAgentDraining(context.Background(), orgID, agent.ID, false)
 			require.NoError(t, err)
+			assert.False(t, undrainedAgent.Draining)
+		})
 
-			// Verify all mock expectations were met
-			mockSocketIO.AssertExpectations(t)
+		t.Run("NotFound", func(t *testing.T) {
+			_, err := agentsService.SetAgentDraining(context.Background(), orgID, core.NewID("ag"), true)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, core.ErrNotFound))
 		})
 
-		t.Run("Success - no agents to disconnect", func(t *testing.T) {
-			// Reset mock for this test
-			mockSocketIO.ExpectedCalls = nil
-			mockSocketIO.Calls = nil
+		t.Run("InvalidAgentID", func(t *testing.T) {
+			_, err := agentsService.SetAgentDraining(context.Background(), orgID, "invalid", true)
+			require.Error(t, err)
+			assert.Equal(t, "agent ID must be a valid ULID", err.Error())
+		})
+	})
 
-			// Call disconnect with no agents
-			err = testServiceWithMock.DisconnectAllActiveAgentsByOrganization(context.Background(), orgID)
+	t.Run("DrainAgentsOnRepo", func(t *testing.T) {
+		repoURL := fmt.Sprintf("github.com/test/drain-repo-%s", core.NewID("repo"))
+
+		t.Run("Success - returns only the agents it drained", func(t *testing.T) {
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				repoURL,
+				0,
+				nil,
+			)
 			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
 
-			// Should not call DisconnectClientByID at all
-			mockSocketIO.AssertNotCalled(t, "DisconnectClientByID")
+			alreadyDrainedAgent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				repoURL,
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, alreadyDrainedAgent.ID) }()
+			_, err = agentsService.SetAgentDraining(context.Background(), orgID, alreadyDrainedAgent.ID, true)
+			require.NoError(t, err)
+
+			otherRepoAgent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				repoURL+"-other",
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, otherRepoAgent.ID) }()
+
+			drainedAgents, err := agentsService.DrainAgentsOnRepo(
+				context.Background(),
+				orgID,
+				"https://"+repoURL+".git",
+			)
+			require.NoError(t, err)
+			require.Len(t, drainedAgents, 1)
+			assert.Equal(t, agent.ID, drainedAgents[0].ID)
+			assert.True(t, drainedAgents[0].Draining)
+
+			allAgents, err := agentsService.GetAllActiveAgents(context.Background(), orgID)
+			require.NoError(t, err)
+			for _, a := range allAgents {
+				if a.ID == otherRepoAgent.ID {
+					assert.False(t, a.Draining)
+				}
+			}
 		})
 
-		t.Run("Fails immediately on first disconnect error", func(t *testing.T) {
-			// Reset mock for this test
-			mockSocketIO.ExpectedCalls = nil
-			mockSocketIO.Calls = nil
+		t.Run("InvalidOrgID", func(t *testing.T) {
+			_, err := agentsService.DrainAgentsOnRepo(context.Background(), models.OrgID("invalid"), repoURL)
+			require.Error(t, err)
+			assert.Equal(t, "organization_id must be a valid ULID", err.Error())
+		})
+	})
 
-			// Create test agents
-			agentID1 := core.NewID("ccaid")
-			agent1, err := testServiceWithMock.UpsertActiveAgent(
+	t.Run("MarkAgentDisconnected", func(t *testing.T) {
+		t.Run("Success - reconnect ends the grace period", func(t *testing.T) {
+			agentID := core.NewID("ccaid")
+			wsConnectionID := core.NewID("wsc")
+			agent, err := agentsService.UpsertActiveAgent(
 				context.Background(),
 				orgID,
-				core.NewID("wsc"),
-				agentID1,
+				wsConnectionID,
+				agentID,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
-			defer func() { _ = testServiceWithMock.DeleteActiveAgent(context.Background(), orgID, agent1.ID) }()
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+			assert.Nil(t, agent.DisconnectedAt)
 
-			agentID2 := core.NewID("ccaid")
-			agent2, err := testServiceWithMock.UpsertActiveAgent(
+			err = agentsService.MarkAgentDisconnected(context.Background(), orgID, wsConnectionID)
+			require.NoError(t, err)
+
+			disconnectedAgents, err := agentsService.GetDisconnectedAgents(context.Background(), orgID, 0)
+			require.NoError(t, err)
+			require.Len(t, disconnectedAgents, 1)
+			assert.Equal(t, agent.ID, disconnectedAgents[0].ID)
+			assert.NotNil(t, disconnectedAgents[0].DisconnectedAt)
+
+			// Agents still within the grace period are not returned
+			disconnectedAgents, err = agentsService.GetDisconnectedAgents(context.Background(), orgID, time.Hour)
+			require.NoError(t, err)
+			assert.Empty(t, disconnectedAgents)
+
+			// Reconnecting, possibly to another replica, clears the disconnect
+			reconnectedAgent, err := agentsService.UpsertActiveAgent(
 				context.Background(),
 				orgID,
 				core.NewID("wsc"),
-				agentID2,
+				agentID,
 				"github.com/test/repo",
+				0,
+				nil,
 			)
 			require.NoError(t, err)
-			defer func() { _ = testServiceWithMock.DeleteActiveAgent(context.Background(), orgID, agent2.ID) }()
-
-			// Set up mock expectations - first succeeds, second fails
-			mockSocketIO.On("DisconnectClientByID", agentID1).Return(nil)
-			mockSocketIO.On("DisconnectClientByID", agentID2).Return(fmt.Errorf("disconnect failed"))
+			assert.Nil(t, reconnectedAgent.DisconnectedAt)
 
-			// Call the disconnect function
-			err = testServiceWithMock.DisconnectAllActiveAgentsByOrganization(context.Background(), orgID)
-			require.Error(t, err)
-			assert.Contains(t, err.Error(), "failed to disconnect agent")
-			assert.Contains(t, err.Error(), agentID2) // Should mention the specific agent that failed
-			assert.Contains(t, err.Error(), "disconnect failed")
+			disconnectedAgents, err = agentsService.GetDisconnectedAgents(context.Background(), orgID, 0)
+			require.NoError(t, err)
+			assert.Empty(t, disconnectedAgents)
 
-			// Verify all mock expectations were met (both calls should still happen)
-			mockSocketIO.AssertExpectations(t)
+			// The old connection dropping late does not touch the reconnected agent
+			err = agentsService.MarkAgentDisconnected(context.Background(), orgID, wsConnectionID)
+			assert.True(t, errors.Is(err, core.ErrNotFound))
 		})
 
-		t.Run("Error - empty organization ID", func(t *testing.T) {
-			// Reset mock for this test
-			mockSocketIO.ExpectedCalls = nil
-			mockSocketIO.Calls = nil
-
-			// Call with empty organization ID
-			err = testServiceWithMock.DisconnectAllActiveAgentsByOrganization(context.Background(), "")
+		t.Run("InvalidWSConnectionID", func(t *testing.T) {
+			err := agentsService.MarkAgentDisconnected(context.Background(), orgID, "invalid")
 			require.Error(t, err)
-			assert.Contains(t, err.Error(), "organization ID must be a valid ULID")
-
-			// Should not call DisconnectClientByID
-			mockSocketIO.AssertNotCalled(t, "DisconnectClientByID")
+			assert.Equal(t, "ws_connection_id must be a valid ULID", err.Error())
 		})
+	})
 
-		t.Run("Error - invalid organization ULID", func(t *testing.T) {
-			// Reset mock for this test
-			mockSocketIO.ExpectedCalls = nil
-			mockSocketIO.Calls = nil
+	t.Run("WaitForDrainingAgents", func(t *testing.T) {
+		originalPollInterval := drainPollInterval
+		drainPollInterval = 50 * time.Millisecond
+		defer func() { drainPollInterval = originalPollInterval }()
 
-			// Call with invalid organization ID
-			err = testServiceWithMock.DisconnectAllActiveAgentsByOrganization(context.Background(), "invalid-ulid")
-			require.Error(t, err)
-			assert.Contains(t, err.Error(), "organization ID must be a valid ULID")
+		repoURL := fmt.Sprintf("github.com/test/drain-%s", core.NewID("repo"))
 
-			// Should not call DisconnectClientByID
-			mockSocketIO.AssertNotCalled(t, "DisconnectClientByID")
+		t.Run("Returns once draining agents have no jobs", func(t *testing.T) {
+			job, err := jobsService.CreateSlackJob(
+				context.Background(),
+				testIntegration.OrgID,
+				"test.thread.drain",
+				"C1234567890",
+				"testuser",
+				testIntegration.ID,
+			)
+			require.NoError(t, err)
+
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				repoURL,
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+			require.NoError(t, agentsService.AssignAgentToJob(context.Background(), orgID, agent.ID, job.ID))
+			_, err = agentsService.SetAgentDraining(context.Background(), orgID, agent.ID, true)
+			require.NoError(t, err)
+
+			// Finish the job shortly after the wait starts
+			go func() {
+				time.Sleep(150 * time.Millisecond)
+				_ = agentsService.UnassignAgentFromJob(context.Background(), orgID, agent.ID, job.ID)
+			}()
+
+			drainedAgents, err := agentsService.WaitForDrainingAgents(
+				context.Background(),
+				orgID,
+				"https://"+repoURL+".git",
+				5*time.Second,
+			)
+			require.NoError(t, err)
+			require.Len(t, drainedAgents, 1)
+			assert.Equal(t, agent.ID, drainedAgents[0].ID)
+		})
+
+		t.Run("Times out while draining agents still have jobs", func(t *testing.T) {
+			job, err := jobsService.CreateSlackJob(
+				context.Background(),
+				testIntegration.OrgID,
+				"test.thread.drain.timeout",
+				"C1234567890",
+				"testuser",
+				testIntegration.ID,
+			)
+			require.NoError(t, err)
+
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				repoURL,
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+			require.NoError(t, agentsService.AssignAgentToJob(context.Background(), orgID, agent.ID, job.ID))
+			_, err = agentsService.SetAgentDraining(context.Background(), orgID, agent.ID, true)
+			require.NoError(t, err)
+
+			_, err = agentsService.WaitForDrainingAgents(context.Background(), orgID, repoURL, 200*time.Millisecond)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "timed out")
+		})
+
+		t.Run("Ignores agents which are not draining", func(t *testing.T) {
+			job, err := jobsService.CreateSlackJob(
+				context.Background(),
+				testIntegration.OrgID,
+				"test.thread.drain.busy",
+				"C1234567890",
+				"testuser",
+				testIntegration.ID,
+			)
+			require.NoError(t, err)
+
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				repoURL,
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+			require.NoError(t, agentsService.AssignAgentToJob(context.Background(), orgID, agent.ID, job.ID))
+
+			drainedAgents, err := agentsService.WaitForDrainingAgents(
+				context.Background(),
+				orgID,
+				repoURL,
+				200*time.Millisecond,
+			)
+			require.NoError(t, err)
+			assert.Empty(t, drainedAgents)
+		})
+
+		t.Run("InvalidTimeout", func(t *testing.T) {
+			_, err := agentsService.WaitForDrainingAgents(context.Background(), orgID, repoURL, 0)
+			require.Error(t, err)
+			assert.Equal(t, "drain timeout must be positive", err.Error())
+		})
+	})
+
+	t.Run("DisconnectAllActiveAgentsByOrganization", func(t *testing.T) {
+		// Create a mock Socket.IO client for these tests
+		mockSocketIO := &socketio.MockSocketIOClient{}
+
+		// Create test service with mock
+		cfg, err := testutils.LoadTestConfig()
+		require.NoError(t, err)
+		dbConn, err := db.NewConnection(cfg.DatabaseURL)
+		require.NoError(t, err, "Failed to create database connection")
+		defer dbConn.Close()
+
+		agentsRepo := db.NewPostgresAgentsRepository(dbConn, cfg.DatabaseSchema)
+		outboxRepo := db.NewPostgresAgentOutboxRepository(dbConn, cfg.DatabaseSchema)
+		inboxRepo := db.NewPostgresAgentInboxRepository(dbConn, cfg.DatabaseSchema)
+		testServiceWithMock := NewAgentsService(agentsRepo, outboxRepo, inboxRepo, mockSocketIO, time.Hour)
+
+		t.Run("Success - disconnects all agents", func(t *testing.T) {
+			// Create multiple test agents
+			agentID1 := core.NewID("ccaid")
+			agent1, err := testServiceWithMock.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				agentID1,
+				"github.com/test/repo",
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = testServiceWithMock.DeleteActiveAgent(context.Background(), orgID, agent1.ID) }()
+
+			agentID2 := core.NewID("ccaid")
+			agent2, err := testServiceWithMock.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				agentID2,
+				"github.com/test/repo",
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = testServiceWithMock.DeleteActiveAgent(context.Background(), orgID, agent2.ID) }()
+
+			// Set up mock expectations - both agents should be disconnected
+			mockSocketIO.On("DisconnectClientByID", agentID1).Return(nil)
+			mockSocketIO.On("DisconnectClientByID", agentID2).Return(nil)
+
+			// Call the disconnect function
+			err = testServiceWithMock.DisconnectAllActiveAgentsByOrganization(context.Background(), orgID)
+			require.NoError(t, err)
+
+			// Verify all mock expectations were met
+			mockSocketIO.AssertExpectations(t)
+		})
+
+		t.Run("Success - no agents to disconnect", func(t *testing.T) {
+			// Reset mock for this test
+			mockSocketIO.ExpectedCalls = nil
+			mockSocketIO.Calls = nil
+
+			// Call disconnect with no agents
+			err = testServiceWithMock.DisconnectAllActiveAgentsByOrganization(context.Background(), orgID)
+			require.NoError(t, err)
+
+			// Should not call DisconnectClientByID at all
+			mockSocketIO.AssertNotCalled(t, "DisconnectClientByID")
+		})
+
+		t.Run("Fails immediately on first disconnect error", func(t *testing.T) {
+			// Reset mock for this test
+			mockSocketIO.ExpectedCalls = nil
+			mockSocketIO.Calls = nil
+
+			// Create test agents
+			agentID1 := core.NewID("ccaid")
+			agent1, err := testServiceWithMock.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				agentID1,
+				"github.com/test/repo",
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = testServiceWithMock.DeleteActiveAgent(context.Background(), orgID, agent1.ID) }()
+
+			agentID2 := core.NewID("ccaid")
+			agent2, err := testServiceWithMock.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				agentID2,
+				"github.com/test/repo",
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = testServiceWithMock.DeleteActiveAgent(context.Background(), orgID, agent2.ID) }()
+
+			// Set up mock expectations - first succeeds, second fails
+			mockSocketIO.On("DisconnectClientByID", agentID1).Return(nil)
+			mockSocketIO.On("DisconnectClientByID", agentID2).Return(fmt.Errorf("disconnect failed"))
+
+			// Call the disconnect function
+			err = testServiceWithMock.DisconnectAllActiveAgentsByOrganization(context.Background(), orgID)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "failed to disconnect agent")
+			assert.Contains(t, err.Error(), agentID2) // Should mention the specific agent that failed
+			assert.Contains(t, err.Error(), "disconnect failed")
+
+			// Verify all mock expectations were met (both calls should still happen)
+			mockSocketIO.AssertExpectations(t)
+		})
+
+		t.Run("Error - empty organization ID", func(t *testing.T) {
+			// Reset mock for this test
+			mockSocketIO.ExpectedCalls = nil
+			mockSocketIO.Calls = nil
+
+			// Call with empty organization ID
+			err = testServiceWithMock.DisconnectAllActiveAgentsByOrganization(context.Background(), "")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "organization ID must be a valid ULID")
+
+			// Should not call DisconnectClientByID
+			mockSocketIO.AssertNotCalled(t, "DisconnectClientByID")
+		})
+
+		t.Run("Error - invalid organization ULID", func(t *testing.T) {
+			// Reset mock for this test
+			mockSocketIO.ExpectedCalls = nil
+			mockSocketIO.Calls = nil
+
+			// Call with invalid organization ID
+			err = testServiceWithMock.DisconnectAllActiveAgentsByOrganization(context.Background(), "invalid-ulid")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "organization ID must be a valid ULID")
+
+			// Should not call DisconnectClientByID
+			mockSocketIO.AssertNotCalled(t, "DisconnectClientByID")
+		})
+	})
+
+	t.Run("AgentOutbox", func(t *testing.T) {
+		mockSocketIO := &socketio.MockSocketIOClient{}
+
+		cfg, err := testutils.LoadTestConfig()
+		require.NoError(t, err)
+		dbConn, err := db.NewConnection(cfg.DatabaseURL)
+		require.NoError(t, err, "Failed to create database connection")
+		defer dbConn.Close()
+
+		agentsRepo := db.NewPostgresAgentsRepository(dbConn, cfg.DatabaseSchema)
+		outboxRepo := db.NewPostgresAgentOutboxRepository(dbConn, cfg.DatabaseSchema)
+		inboxRepo := db.NewPostgresAgentInboxRepository(dbConn, cfg.DatabaseSchema)
+		testServiceWithMock := NewAgentsService(agentsRepo, outboxRepo, inboxRepo, mockSocketIO, time.Hour)
+
+		wsConnectionID := core.NewID("wsc")
+		agent, err := testServiceWithMock.UpsertActiveAgent(
+			context.Background(),
+			orgID,
+			wsConnectionID,
+			core.NewID("ccaid"),
+			"github.com/test/repo",
+			0,
+			nil,
+		)
+		require.NoError(t, err)
+		defer func() { _ = testServiceWithMock.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+
+		newMessage := func() models.BaseMessage {
+			return models.BaseMessage{
+				ID:   core.NewID("msg"),
+				Type: models.MessageTypeUserMessage,
+				Payload: models.UserMessagePayload{
+					JobID:   core.NewID("j"),
+					Message: "hello",
+				},
+			}
+		}
+
+		t.Run("Message stays pending until acknowledged", func(t *testing.T) {
+			mockSocketIO.ExpectedCalls = nil
+			mockSocketIO.Calls = nil
+			msg := newMessage()
+			jobID := core.NewID("j")
+			mockSocketIO.On("SendMessage", wsConnectionID, msg).Return(nil).Once()
+
+			err := testServiceWithMock.SendMessageToAgent(
+				context.Background(),
+				orgID,
+				wsConnectionID,
+				jobID,
+				core.NewID("psm"),
+				msg,
+			)
+			require.NoError(t, err)
+
+			messages, err := testServiceWithMock.GetAgentMessages(context.Background(), orgID, jobID)
+			require.NoError(t, err)
+			require.Len(t, messages, 1)
+			assert.Equal(t, msg.ID, messages[0].ID)
+			assert.Equal(t, agent.ID, messages[0].AgentID)
+			assert.Equal(t, models.AgentOutboxMessageStatusPending, messages[0].Status)
+			assert.Equal(t, 1, messages[0].Attempts)
+			assert.Nil(t, messages[0].Payload, "listing does not load the payload")
+
+			delivered, err := testServiceWithMock.MarkAgentMessageDelivered(context.Background(), orgID, msg.ID)
+			require.NoError(t, err)
+			assert.Equal(t, models.MessageTypeUserMessage, delivered.MessageType)
+
+			messages, err = testServiceWithMock.GetAgentMessages(context.Background(), orgID, jobID)
+			require.NoError(t, err)
+			require.Len(t, messages, 1)
+			assert.Equal(t, models.AgentOutboxMessageStatusDelivered, messages[0].Status)
+			assert.NotNil(t, messages[0].DeliveredAt)
+
+			// A duplicate acknowledgement finds nothing pending
+			_, err = testServiceWithMock.MarkAgentMessageDelivered(context.Background(), orgID, msg.ID)
+			assert.ErrorIs(t, err, core.ErrNotFound)
+			mockSocketIO.AssertExpectations(t)
+		})
+
+		t.Run("Failed send is recorded and redelivered", func(t *testing.T) {
+			mockSocketIO.ExpectedCalls = nil
+			mockSocketIO.Calls = nil
+			msg := newMessage()
+			jobID := core.NewID("j")
+			mockSocketIO.On("SendMessage", wsConnectionID, msg).Return(fmt.Errorf("client disconnected")).Once()
+
+			err := testServiceWithMock.SendMessageToAgent(
+				context.Background(),
+				orgID,
+				wsConnectionID,
+				jobID,
+				core.NewID("psm"),
+				msg,
+			)
+			require.NoError(t, err)
+
+			messages, err := testServiceWithMock.GetAgentMessages(context.Background(), orgID, jobID)
+			require.NoError(t, err)
+			require.Len(t, messages, 1)
+			require.NotNil(t, messages[0].LastError)
+			assert.Equal(t, "client disconnected", *messages[0].LastError)
+
+			// The resent message is the same message, with its payload decoded from the outbox - the listing
+			// leaves the payload out, so the message is loaded the way the retry task loads it
+			mockSocketIO.On("SendMessage", wsConnectionID, mock.MatchedBy(func(resent models.BaseMessage) bool {
+				return resent.ID == msg.ID && resent.Type == msg.Type
+			})).Return(nil).Once()
+
+			_, err = dbConn.ExecContext(
+				context.Background(),
+				fmt.Sprintf(
+					"UPDATE %s.agent_outbox_messages SET next_attempt_at = NOW() WHERE id = $1",
+					cfg.DatabaseSchema,
+				),
+				msg.ID,
+			)
+			require.NoError(t, err)
+			dueMessages, err := outboxRepo.GetPendingOutboxMessagesDue(context.Background(), orgID)
+			require.NoError(t, err)
+			var dueMessage *models.AgentOutboxMessage
+			for _, m := range dueMessages {
+				if m.ID == msg.ID {
+					dueMessage = m
+				}
+			}
+			require.NotNil(t, dueMessage)
+
+			err = testServiceWithMock.RedeliverAgentMessage(context.Background(), orgID, dueMessage)
+			require.NoError(t, err)
+
+			messages, err = testServiceWithMock.GetAgentMessages(context.Background(), orgID, jobID)
+			require.NoError(t, err)
+			require.Len(t, messages, 1)
+			assert.Equal(t, 2, messages[0].Attempts)
+			assert.Equal(t, models.AgentOutboxMessageStatusPending, messages[0].Status)
+			mockSocketIO.AssertExpectations(t)
+		})
+
+		t.Run("Unsupported message type fails the message", func(t *testing.T) {
+			mockSocketIO.ExpectedCalls = nil
+			mockSocketIO.Calls = nil
+			msg := newMessage()
+			jobID := core.NewID("j")
+			mockSocketIO.On("SendMessage", wsConnectionID, msg).Return(core.ErrUnsupportedMessageType).Once()
+
+			err := testServiceWithMock.SendMessageToAgent(
+				context.Background(),
+				orgID,
+				wsConnectionID,
+				jobID,
+				core.NewID("psm"),
+				msg,
+			)
+			require.ErrorIs(t, err, core.ErrUnsupportedMessageType)
+
+			messages, err := testServiceWithMock.GetAgentMessages(context.Background(), orgID, jobID)
+			require.NoError(t, err)
+			require.Len(t, messages, 1)
+			assert.Equal(t, models.AgentOutboxMessageStatusFailed, messages[0].Status)
+		})
+
+		t.Run("Relayed message the agent does not support fails the message", func(t *testing.T) {
+			mockSocketIO.ExpectedCalls = nil
+			mockSocketIO.Calls = nil
+			msg := newMessage()
+			jobID := core.NewID("j")
+			// The message was relayed to another replica, which reports back that it could not send it
+			mockSocketIO.On("SendMessage", wsConnectionID, msg).Return(nil).Once()
+
+			err := testServiceWithMock.SendMessageToAgent(
+				context.Background(),
+				orgID,
+				wsConnectionID,
+				jobID,
+				core.NewID("psm"),
+				msg,
+			)
+			require.NoError(t, err)
+
+			err = testServiceWithMock.RecordAgentMessageDeliveryFailure(
+				context.Background(),
+				orgID,
+				msg.ID,
+				fmt.Errorf("cannot send %s: %w", msg.Type, core.ErrUnsupportedMessageType),
+			)
+			require.NoError(t, err)
+
+			messages, err := testServiceWithMock.GetAgentMessages(context.Background(), orgID, jobID)
+			require.NoError(t, err)
+			require.Len(t, messages, 1)
+			assert.Equal(t, models.AgentOutboxMessageStatusFailed, messages[0].Status)
+
+			// A message which is no longer pending is not touched again
+			err = testServiceWithMock.RecordAgentMessageDeliveryFailure(
+				context.Background(),
+				orgID,
+				msg.ID,
+				fmt.Errorf("connection closed"),
+			)
+			assert.ErrorIs(t, err, core.ErrNotFound)
+		})
+
+		t.Run("Cancelled job fails pending messages but not the cancellation", func(t *testing.T) {
+			mockSocketIO.ExpectedCalls = nil
+			mockSocketIO.Calls = nil
+			jobID := core.NewID("j")
+			msg := newMessage()
+			cancelMsg := models.BaseMessage{
+				ID:      core.NewID("msg"),
+				Type:    models.MessageTypeCancelJob,
+				Payload: models.CancelJobPayload{JobID: jobID},
+			}
+			mockSocketIO.On("SendMessage", wsConnectionID, mock.Anything).Return(nil).Twice()
+
+			for _, m := range []models.BaseMessage{msg, cancelMsg} {
+				err := testServiceWithMock.SendMessageToAgent(
+					context.Background(),
+					orgID,
+					wsConnectionID,
+					jobID,
+					core.NewID("psm"),
+					m,
+				)
+				require.NoError(t, err)
+			}
+
+			err := testServiceWithMock.FailPendingAgentJobMessages(context.Background(), orgID, jobID, "job was cancelled")
+			require.NoError(t, err)
+
+			messages, err := testServiceWithMock.GetAgentMessages(context.Background(), orgID, jobID)
+			require.NoError(t, err)
+			require.Len(t, messages, 2)
+			statuses := make(map[string]models.AgentOutboxMessageStatus)
+			for _, m := range messages {
+				statuses[m.ID] = m.Status
+			}
+			assert.Equal(t, models.AgentOutboxMessageStatusFailed, statuses[msg.ID])
+			assert.Equal(t, models.AgentOutboxMessageStatusPending, statuses[cancelMsg.ID])
+			mockSocketIO.AssertExpectations(t)
+		})
+
+		t.Run("Finished messages are deleted after the retention period", func(t *testing.T) {
+			mockSocketIO.ExpectedCalls = nil
+			mockSocketIO.Calls = nil
+			jobID := core.NewID("j")
+			delivered := newMessage()
+			pending := newMessage()
+			mockSocketIO.On("SendMessage", wsConnectionID, mock.Anything).Return(nil).Twice()
+
+			for _, m := range []models.BaseMessage{delivered, pending} {
+				err := testServiceWithMock.SendMessageToAgent(
+					context.Background(),
+					orgID,
+					wsConnectionID,
+					jobID,
+					core.NewID("psm"),
+					m,
+				)
+				require.NoError(t, err)
+			}
+			_, err := testServiceWithMock.MarkAgentMessageDelivered(context.Background(), orgID, delivered.ID)
+			require.NoError(t, err)
+
+			originalRetention := agentMessageRetention
+			agentMessageRetention = 0
+			defer func() { agentMessageRetention = originalRetention }()
+
+			deleted, err := testServiceWithMock.DeleteFinishedAgentMessages(context.Background())
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, deleted, int64(1))
+
+			messages, err := testServiceWithMock.GetAgentMessages(context.Background(), orgID, jobID)
+			require.NoError(t, err)
+			require.Len(t, messages, 1)
+			assert.Equal(t, pending.ID, messages[0].ID)
+			mockSocketIO.AssertExpectations(t)
+		})
+
+		t.Run("Unknown connection", func(t *testing.T) {
+			err := testServiceWithMock.SendMessageToAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("j"),
+				core.NewID("psm"),
+				newMessage(),
+			)
+			assert.ErrorIs(t, err, core.ErrNotFound)
+		})
+	})
+
+	t.Run("AssignAgentToJobWithinCapacity", func(t *testing.T) {
+		createJob := func(t *testing.T, threadTS string) *models.Job {
+			job, err := jobsService.CreateSlackJob(
+				context.Background(),
+				testIntegration.OrgID,
+				threadTS,
+				"C1234567890",
+				"testuser",
+				testIntegration.ID,
+			)
+			require.NoError(t, err)
+			return job
+		}
+
+		t.Run("Agent at capacity rejects another job", func(t *testing.T) {
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				"github.com/test/repo",
+				1,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+
+			job1 := createJob(t, "test.thread.capacity.1")
+			job2 := createJob(t, "test.thread.capacity.2")
+
+			assigned, err := agentsService.AssignAgentToJobWithinCapacity(context.Background(), orgID, agent.ID, job1.ID)
+			require.NoError(t, err)
+			assert.True(t, assigned)
+
+			assigned, err = agentsService.AssignAgentToJobWithinCapacity(context.Background(), orgID, agent.ID, job2.ID)
+			require.NoError(t, err)
+			assert.False(t, assigned)
+
+			// The job the agent already holds is still reported as assigned
+			assigned, err = agentsService.AssignAgentToJobWithinCapacity(context.Background(), orgID, agent.ID, job1.ID)
+			require.NoError(t, err)
+			assert.True(t, assigned)
+
+			jobIDs, err := agentsService.GetActiveAgentJobAssignments(context.Background(), orgID, agent.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []string{job1.ID}, jobIDs)
+		})
+
+		t.Run("Concurrent assignments do not oversubscribe the agent", func(t *testing.T) {
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				"github.com/test/repo",
+				2,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+
+			var jobIDs []string
+			for i := 0; i < 5; i++ {
+				jobIDs = append(jobIDs, createJob(t, fmt.Sprintf("test.thread.concurrent.%d", i)).ID)
+			}
+
+			var wg sync.WaitGroup
+			results := make([]bool, len(jobIDs))
+			errs := make([]error, len(jobIDs))
+			for i, jobID := range jobIDs {
+				wg.Add(1)
+				go func(i int, jobID string) {
+					defer wg.Done()
+					results[i], errs[i] = agentsService.AssignAgentToJobWithinCapacity(
+						context.Background(),
+						orgID,
+						agent.ID,
+						jobID,
+					)
+				}(i, jobID)
+			}
+			wg.Wait()
+
+			assignedCount := 0
+			for i := range jobIDs {
+				require.NoError(t, errs[i])
+				if results[i] {
+					assignedCount++
+				}
+			}
+			assert.Equal(t, 2, assignedCount)
+
+			assignments, err := agentsService.GetActiveAgentJobAssignments(context.Background(), orgID, agent.ID)
+			require.NoError(t, err)
+			assert.Len(t, assignments, 2)
+		})
+
+		t.Run("Agent without max concurrency is unlimited", func(t *testing.T) {
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				"github.com/test/repo",
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+
+			for i := 0; i < 3; i++ {
+				job := createJob(t, fmt.Sprintf("test.thread.unlimited.%d", i))
+				assigned, err := agentsService.AssignAgentToJobWithinCapacity(
+					context.Background(),
+					orgID,
+					agent.ID,
+					job.ID,
+				)
+				require.NoError(t, err)
+				assert.True(t, assigned)
+			}
+		})
+
+		t.Run("Unknown agent", func(t *testing.T) {
+			job := createJob(t, "test.thread.capacity.unknown")
+
+			assigned, err := agentsService.AssignAgentToJobWithinCapacity(
+				context.Background(),
+				orgID,
+				core.NewID("ccaid"),
+				job.ID,
+			)
+			require.NoError(t, err)
+			assert.False(t, assigned)
+		})
+	})
+
+	t.Run("AgentInbox", func(t *testing.T) {
+		t.Run("Duplicate message is not claimed again", func(t *testing.T) {
+			agentID := core.NewID("ccaid")
+			messageID := core.NewID("msg")
+
+			claimed, err := agentsService.ClaimAgentInboundMessage(
+				context.Background(),
+				orgID,
+				agentID,
+				messageID,
+				models.MessageTypeAssistantMessage,
+			)
+			require.NoError(t, err)
+			assert.True(t, claimed)
+
+			err = agentsService.CompleteAgentInboundMessage(context.Background(), orgID, agentID, messageID)
+			require.NoError(t, err)
+
+			claimed, err = agentsService.ClaimAgentInboundMessage(
+				context.Background(),
+				orgID,
+				agentID,
+				messageID,
+				models.MessageTypeAssistantMessage,
+			)
+			require.NoError(t, err)
+			assert.False(t, claimed)
+
+			// The same message ID from another agent is a different message
+			claimed, err = agentsService.ClaimAgentInboundMessage(
+				context.Background(),
+				orgID,
+				core.NewID("ccaid"),
+				messageID,
+				models.MessageTypeAssistantMessage,
+			)
+			require.NoError(t, err)
+			assert.True(t, claimed)
+		})
+
+		t.Run("Duplicate of message still being processed is in progress", func(t *testing.T) {
+			agentID := core.NewID("ccaid")
+			messageID := core.NewID("msg")
+
+			claimed, err := agentsService.ClaimAgentInboundMessage(
+				context.Background(),
+				orgID,
+				agentID,
+				messageID,
+				models.MessageTypeJobComplete,
+			)
+			require.NoError(t, err)
+			require.True(t, claimed)
+
+			claimed, err = agentsService.ClaimAgentInboundMessage(
+				context.Background(),
+				orgID,
+				agentID,
+				messageID,
+				models.MessageTypeJobComplete,
+			)
+			assert.ErrorIs(t, err, core.ErrMessageInProgress)
+			assert.False(t, claimed)
+
+			// The original failed, so the next copy is processed
+			err = agentsService.ReleaseAgentInboundMessage(context.Background(), orgID, agentID, messageID)
+			require.NoError(t, err)
+
+			claimed, err = agentsService.ClaimAgentInboundMessage(
+				context.Background(),
+				orgID,
+				agentID,
+				messageID,
+				models.MessageTypeJobComplete,
+			)
+			require.NoError(t, err)
+			assert.True(t, claimed)
+		})
+
+		t.Run("Duplicate of message stuck in processing takes it over", func(t *testing.T) {
+			originalTimeout := agentInboundMessageProcessingTimeout
+			agentInboundMessageProcessingTimeout = 0
+			defer func() { agentInboundMessageProcessingTimeout = originalTimeout }()
+
+			agentID := core.NewID("ccaid")
+			messageID := core.NewID("msg")
+
+			claimed, err := agentsService.ClaimAgentInboundMessage(
+				context.Background(),
+				orgID,
+				agentID,
+				messageID,
+				models.MessageTypeJobComplete,
+			)
+			require.NoError(t, err)
+			require.True(t, claimed)
+
+			time.Sleep(10 * time.Millisecond)
+
+			claimed, err = agentsService.ClaimAgentInboundMessage(
+				context.Background(),
+				orgID,
+				agentID,
+				messageID,
+				models.MessageTypeJobComplete,
+			)
+			require.NoError(t, err)
+			assert.True(t, claimed)
+		})
+
+		t.Run("Released message is claimed again", func(t *testing.T) {
+			agentID := core.NewID("ccaid")
+			messageID := core.NewID("msg")
+
+			claimed, err := agentsService.ClaimAgentInboundMessage(
+				context.Background(),
+				orgID,
+				agentID,
+				messageID,
+				models.MessageTypeJobComplete,
+			)
+			require.NoError(t, err)
+			require.True(t, claimed)
+
+			err = agentsService.ReleaseAgentInboundMessage(context.Background(), orgID, agentID, messageID)
+			require.NoError(t, err)
+
+			claimed, err = agentsService.ClaimAgentInboundMessage(
+				context.Background(),
+				orgID,
+				agentID,
+				messageID,
+				models.MessageTypeJobComplete,
+			)
+			require.NoError(t, err)
+			assert.True(t, claimed)
+		})
+
+		t.Run("Message without ID", func(t *testing.T) {
+			_, err := agentsService.ClaimAgentInboundMessage(
+				context.Background(),
+				orgID,
+				core.NewID("ccaid"),
+				"",
+				models.MessageTypeAssistantMessage,
+			)
+			assert.Error(t, err)
 		})
 	})
 }
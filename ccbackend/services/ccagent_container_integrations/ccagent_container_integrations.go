@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"ccbackend/clients/ssh"
 	"ccbackend/config"
@@ -16,6 +17,10 @@ import (
 	"github.com/samber/mo"
 )
 
+// redeployStaleAfter is how long a redeploy may go without progress before a new one can replace it,
+// e.g. when the replica running it restarted - it must exceed the longest drain timeout
+var redeployStaleAfter = 2 * time.Hour
+
 // CCAgentContainerIntegrationsService handles CCAgent container integration operations
 type CCAgentContainerIntegrationsService struct {
 	repo                         *db.PostgresCCAgentContainerIntegrationsRepository
@@ -131,6 +136,66 @@ func (s *CCAgentContainerIntegrationsService) GetCCAgentContainerIntegrationByID
 	return integration, nil
 }
 
+// StartCCAgentContainerRedeploy records that a drained redeploy of the integration has started
+// Returns core.ErrRedeployInProgress if another redeploy of the integration is still running
+func (s *CCAgentContainerIntegrationsService) StartCCAgentContainerRedeploy(
+	ctx context.Context,
+	orgID models.OrgID,
+	integrationID string,
+) (*models.CCAgentContainerIntegration, error) {
+	log.Printf("📋 Starting to start redeploy of CCAgent container integration: %s for org: %s", integrationID, orgID)
+
+	if !core.IsValidULID(integrationID) {
+		return nil, fmt.Errorf("invalid integration ID")
+	}
+
+	maybeIntegration, err := s.repo.StartCCAgentContainerRedeploy(ctx, orgID, integrationID, redeployStaleAfter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start CCAgent container redeploy: %w", err)
+	}
+	if integration, ok := maybeIntegration.Get(); ok {
+		log.Printf("📋 Completed successfully - started redeploy of CCAgent container integration: %s", integrationID)
+		return integration, nil
+	}
+
+	// Nothing was updated - either the integration does not exist or it is being redeployed already
+	existing, err := s.repo.GetCCAgentContainerIntegrationByID(ctx, orgID, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CCAgent container integration: %w", err)
+	}
+	if !existing.IsPresent() {
+		return nil, fmt.Errorf("CCAgent container integration %s: %w", integrationID, core.ErrNotFound)
+	}
+	return nil, fmt.Errorf("CCAgent container integration %s: %w", integrationID, core.ErrRedeployInProgress)
+}
+
+// UpdateCCAgentContainerRedeployStatus records the progress of the integration's redeploy
+func (s *CCAgentContainerIntegrationsService) UpdateCCAgentContainerRedeployStatus(
+	ctx context.Context,
+	orgID models.OrgID,
+	integrationID string,
+	status models.CCAgentRedeployStatus,
+	redeployError string,
+) error {
+	log.Printf("📋 Starting to set redeploy status %s for CCAgent container integration: %s", status, integrationID)
+
+	if !core.IsValidULID(integrationID) {
+		return fmt.Errorf("invalid integration ID")
+	}
+
+	err := s.repo.UpdateCCAgentContainerRedeployStatus(ctx, orgID, integrationID, status, redeployError)
+	if err != nil {
+		return fmt.Errorf("failed to update CCAgent container redeploy status: %w", err)
+	}
+
+	log.Printf(
+		"📋 Completed successfully - set redeploy status %s for CCAgent container integration: %s",
+		status,
+		integrationID,
+	)
+	return nil
+}
+
 // DeleteCCAgentContainerIntegration deletes a CCAgent container integration
 func (s *CCAgentContainerIntegrationsService) DeleteCCAgentContainerIntegration(
 	ctx context.Context,
@@ -67,3 +67,26 @@ func (m *MockCCAgentContainerIntegrationsService) RedeployCCAgentContainer(
 	args := m.Called(ctx, orgID, integrationID, updateConfigOnly)
 	return args.Error(0)
 }
+
+func (m *MockCCAgentContainerIntegrationsService) StartCCAgentContainerRedeploy(
+	ctx context.Context,
+	orgID models.OrgID,
+	integrationID string,
+) (*models.CCAgentContainerIntegration, error) {
+	args := m.Called(ctx, orgID, integrationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CCAgentContainerIntegration), args.Error(1)
+}
+
+func (m *MockCCAgentContainerIntegrationsService) UpdateCCAgentContainerRedeployStatus(
+	ctx context.Context,
+	orgID models.OrgID,
+	integrationID string,
+	status models.CCAgentRedeployStatus,
+	redeployError string,
+) error {
+	args := m.Called(ctx, orgID, integrationID, status, redeployError)
+	return args.Error(0)
+}
@@ -57,3 +57,21 @@ func (s *UnconfiguredCCAgentContainerIntegrationsService) RedeployCCAgentContain
 ) error {
 	return fmt.Errorf("service CCAgentContainer is not configured")
 }
+
+func (s *UnconfiguredCCAgentContainerIntegrationsService) StartCCAgentContainerRedeploy(
+	ctx context.Context,
+	orgID models.OrgID,
+	integrationID string,
+) (*models.CCAgentContainerIntegration, error) {
+	return nil, fmt.Errorf("service CCAgentContainer is not configured")
+}
+
+func (s *UnconfiguredCCAgentContainerIntegrationsService) UpdateCCAgentContainerRedeployStatus(
+	ctx context.Context,
+	orgID models.OrgID,
+	integrationID string,
+	status models.CCAgentRedeployStatus,
+	redeployError string,
+) error {
+	return fmt.Errorf("service CCAgentContainer is not configured")
+}
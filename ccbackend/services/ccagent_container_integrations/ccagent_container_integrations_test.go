@@ -2,8 +2,10 @@ package ccagentcontainerintegrations
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/samber/mo"
 	"github.com/stretchr/testify/assert"
@@ -354,6 +356,94 @@ func TestCCAgentContainerIntegrationsService_DeleteCCAgentContainerIntegration(t
 	})
 }
 
+func TestCCAgentContainerIntegrationsService_StartCCAgentContainerRedeploy(t *testing.T) {
+	service, orgsRepo, cleanup := setupCCAgentContainerIntegrationsTest(t)
+	defer cleanup()
+
+	t.Run("tracks progress and rejects a concurrent redeploy", func(t *testing.T) {
+		testOrg := testutils.CreateTestOrganization(t, orgsRepo)
+		orgID := models.OrgID(testOrg.ID)
+
+		integration, err := service.CreateCCAgentContainerIntegration(
+			context.Background(),
+			orgID,
+			1,
+			"github.com/example/redeploy",
+		)
+		require.NoError(t, err)
+		assert.Nil(t, integration.RedeployStatus)
+
+		started, err := service.StartCCAgentContainerRedeploy(context.Background(), orgID, integration.ID)
+		require.NoError(t, err)
+		require.NotNil(t, started.RedeployStatus)
+		assert.Equal(t, models.CCAgentRedeployStatusDraining, *started.RedeployStatus)
+		assert.NotNil(t, started.RedeployStartedAt)
+
+		_, err = service.StartCCAgentContainerRedeploy(context.Background(), orgID, integration.ID)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, core.ErrRedeployInProgress))
+
+		err = service.UpdateCCAgentContainerRedeployStatus(
+			context.Background(),
+			orgID,
+			integration.ID,
+			models.CCAgentRedeployStatusFailed,
+			"timed out",
+		)
+		require.NoError(t, err)
+
+		integrationOpt, err := service.GetCCAgentContainerIntegrationByID(context.Background(), orgID, integration.ID)
+		require.NoError(t, err)
+		failed := integrationOpt.MustGet()
+		assert.Equal(t, models.CCAgentRedeployStatusFailed, *failed.RedeployStatus)
+		require.NotNil(t, failed.RedeployError)
+		assert.Equal(t, "timed out", *failed.RedeployError)
+
+		// A finished redeploy does not block the next one, which starts without the previous error
+		restarted, err := service.StartCCAgentContainerRedeploy(context.Background(), orgID, integration.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CCAgentRedeployStatusDraining, *restarted.RedeployStatus)
+		assert.Nil(t, restarted.RedeployError)
+	})
+
+	t.Run("replaces a stale redeploy", func(t *testing.T) {
+		originalStaleAfter := redeployStaleAfter
+		redeployStaleAfter = 0
+		defer func() { redeployStaleAfter = originalStaleAfter }()
+
+		testOrg := testutils.CreateTestOrganization(t, orgsRepo)
+		orgID := models.OrgID(testOrg.ID)
+
+		integration, err := service.CreateCCAgentContainerIntegration(
+			context.Background(),
+			orgID,
+			1,
+			"github.com/example/stale-redeploy",
+		)
+		require.NoError(t, err)
+
+		_, err = service.StartCCAgentContainerRedeploy(context.Background(), orgID, integration.ID)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+
+		_, err = service.StartCCAgentContainerRedeploy(context.Background(), orgID, integration.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("integration not found", func(t *testing.T) {
+		testOrg := testutils.CreateTestOrganization(t, orgsRepo)
+
+		_, err := service.StartCCAgentContainerRedeploy(
+			context.Background(),
+			models.OrgID(testOrg.ID),
+			core.NewID("cci"),
+		)
+
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, core.ErrNotFound))
+	})
+}
+
 func TestCCAgentContainerIntegrationsService_RedeployCCAgentContainer(t *testing.T) {
 	service, orgsRepo, cleanup := setupCCAgentContainerIntegrationsTest(t)
 	defer cleanup()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/samber/mo"
 
@@ -250,6 +251,47 @@ func (s *ConnectedChannelsService) GetDiscordConnectedChannel(
 }
 
 
+// UpdateAgentSelector sets the label selector agents must satisfy to be assigned jobs from the channel
+// An empty selector allows any agent to pick up jobs from the channel
+func (s *ConnectedChannelsService) UpdateAgentSelector(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+	agentSelector models.AgentLabels,
+) (models.ConnectedChannel, error) {
+	log.Printf("📋 Starting to update agent selector for connected channel: %s to %q for org: %s", id, agentSelector, orgID)
+
+	if !core.IsValidULID(id) {
+		return nil, fmt.Errorf("connected channel ID must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+	for key := range agentSelector {
+		if strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("agent selector keys cannot be empty")
+		}
+	}
+	if agentSelector == nil {
+		agentSelector = models.AgentLabels{}
+	}
+
+	maybeChannel, err := s.connectedChannelsRepo.UpdateAgentSelector(ctx, orgID, id, agentSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update connected channel agent selector: %w", err)
+	}
+	if !maybeChannel.IsPresent() {
+		return nil, core.ErrNotFound
+	}
+
+	channel, err := maybeChannel.MustGet().ToConnectedChannel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to domain model: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - updated agent selector for connected channel: %s", id)
+	return channel, nil
+}
 
 // getFirstAvailableRepoURL gets the repository URL from the first available active agent
 func (s *ConnectedChannelsService) getFirstAvailableRepoURL(ctx context.Context, orgID models.OrgID) (*string, error) {
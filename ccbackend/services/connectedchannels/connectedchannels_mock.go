@@ -68,4 +68,15 @@ func (m *MockConnectedChannelsService) GetDiscordConnectedChannel(
 	return args.Get(0).(mo.Option[*models.DiscordConnectedChannel]), args.Error(1)
 }
 
-
+func (m *MockConnectedChannelsService) UpdateAgentSelector(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+	agentSelector models.AgentLabels,
+) (models.ConnectedChannel, error) {
+	args := m.Called(ctx, orgID, id, agentSelector)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.ConnectedChannel), args.Error(1)
+}
@@ -313,3 +313,52 @@ func TestConnectedChannelsService_UpsertDiscordConnectedChannel(t *testing.T) {
 
 
 
+
+func TestConnectedChannelsService_UpdateAgentSelector(t *testing.T) {
+	service, testUser, mockAgentsService, cleanup := setupTestService(t)
+	defer cleanup()
+
+	t.Run("Sets selector and preserves it on subsequent upserts", func(t *testing.T) {
+		teamID := "T" + core.NewID("team")
+		channelID := "C" + core.NewID("chan")
+
+		mockAgentsService.On("GetAvailableAgents", context.Background(), testUser.OrgID).
+			Return([]*models.ActiveAgent{}, nil)
+
+		channel, err := service.UpsertSlackConnectedChannel(context.Background(), testUser.OrgID, teamID, channelID)
+		require.NoError(t, err)
+		assert.Empty(t, channel.AgentSelector)
+
+		selector := models.AgentLabels{"team": "payments"}
+		updated, err := service.UpdateAgentSelector(context.Background(), testUser.OrgID, channel.ID, selector)
+		require.NoError(t, err)
+		assert.Equal(t, selector, updated.GetAgentSelector())
+
+		// Re-upserting the channel (happens on every message) must not reset the selector
+		upserted, err := service.UpsertSlackConnectedChannel(context.Background(), testUser.OrgID, teamID, channelID)
+		require.NoError(t, err)
+		assert.Equal(t, selector, upserted.AgentSelector)
+	})
+
+	t.Run("Unknown channel returns not found", func(t *testing.T) {
+		_, err := service.UpdateAgentSelector(
+			context.Background(),
+			testUser.OrgID,
+			core.NewID("cc"),
+			models.AgentLabels{"team": "payments"},
+		)
+		require.Error(t, err)
+		assert.True(t, core.IsNotFoundError(err))
+	})
+
+	t.Run("Empty selector key returns error", func(t *testing.T) {
+		_, err := service.UpdateAgentSelector(
+			context.Background(),
+			testUser.OrgID,
+			core.NewID("cc"),
+			models.AgentLabels{"": "payments"},
+		)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "agent selector keys cannot be empty")
+	})
+}
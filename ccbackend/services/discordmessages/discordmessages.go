@@ -29,6 +29,7 @@ func (s *DiscordMessagesService) CreateProcessedDiscordMessage(
 	jobID string,
 	discordMessageID, discordThreadID, textContent, discordIntegrationID string,
 	status models.ProcessedDiscordMessageStatus,
+	attachments models.Attachments,
 ) (*models.ProcessedDiscordMessage, error) {
 	log.Printf(
 		"📋 Starting to create processed discord message for job: %s, message: %s, thread: %s, organization: %s",
@@ -66,6 +67,7 @@ func (s *DiscordMessagesService) CreateProcessedDiscordMessage(
 		DiscordMessageID:     discordMessageID,
 		DiscordThreadID:      discordThreadID,
 		TextContent:          textContent,
+		Attachments:          attachments,
 		Status:               status,
 		DiscordIntegrationID: discordIntegrationID,
 		OrgID:                orgID,
@@ -111,6 +113,35 @@ func (s *DiscordMessagesService) UpdateProcessedDiscordMessage(
 	return updatedMessage, nil
 }
 
+// GetProcessedMessagesByJobID returns every processed message of a job regardless of status, oldest first
+func (s *DiscordMessagesService) GetProcessedMessagesByJobID(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+	discordIntegrationID string,
+) ([]*models.ProcessedDiscordMessage, error) {
+	log.Printf("📋 Starting to get processed discord messages by job ID: %s", jobID)
+	if !core.IsValidULID(jobID) {
+		return nil, fmt.Errorf("job ID must be a valid ULID")
+	}
+	if !core.IsValidULID(discordIntegrationID) {
+		return nil, fmt.Errorf("discord_integration_id must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	messages, err := s.processedDiscordMessagesRepo.GetProcessedDiscordMessagesByJobID(
+		ctx, jobID, discordIntegrationID, orgID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get processed discord messages: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - found %d processed discord messages", len(messages))
+	return messages, nil
+}
+
 func (s *DiscordMessagesService) GetProcessedMessagesByJobIDAndStatus(
 	ctx context.Context,
 	orgID models.OrgID,
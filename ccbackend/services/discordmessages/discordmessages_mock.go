@@ -20,6 +20,7 @@ func (m *MockDiscordMessagesService) CreateProcessedDiscordMessage(
 	jobID string,
 	discordMessageID, discordThreadID, textContent, discordIntegrationID string,
 	status models.ProcessedDiscordMessageStatus,
+	attachments models.Attachments,
 ) (*models.ProcessedDiscordMessage, error) {
 	args := m.Called(
 		ctx,
@@ -30,6 +31,7 @@ func (m *MockDiscordMessagesService) CreateProcessedDiscordMessage(
 		textContent,
 		discordIntegrationID,
 		status,
+		attachments,
 	)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -51,6 +53,19 @@ func (m *MockDiscordMessagesService) UpdateProcessedDiscordMessage(
 	return args.Get(0).(*models.ProcessedDiscordMessage), args.Error(1)
 }
 
+func (m *MockDiscordMessagesService) GetProcessedMessagesByJobID(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+	discordIntegrationID string,
+) ([]*models.ProcessedDiscordMessage, error) {
+	args := m.Called(ctx, orgID, jobID, discordIntegrationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ProcessedDiscordMessage), args.Error(1)
+}
+
 func (m *MockDiscordMessagesService) GetProcessedMessagesByJobIDAndStatus(
 	ctx context.Context,
 	orgID models.OrgID,
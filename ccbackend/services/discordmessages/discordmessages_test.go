@@ -124,6 +124,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Hello Discord world!",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusQueued,
+				nil,
 			)
 
 			require.NoError(t, err)
@@ -162,6 +163,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Message to retrieve",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusInProgress,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() {
@@ -215,6 +217,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Message to update",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() {
@@ -254,6 +257,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Queued message 1",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -266,6 +270,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Queued message 2",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -278,6 +283,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"In progress message",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusInProgress,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -346,6 +352,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"First message",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -361,6 +368,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Latest message",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusInProgress,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -464,6 +472,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Active message 1",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -476,6 +485,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Active message 2",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusInProgress,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -488,6 +498,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Active message 3",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -501,6 +512,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Inactive message",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusCompleted,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -556,6 +568,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Completed message",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusCompleted,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -591,6 +604,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Message to delete 1",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -603,6 +617,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				"Message to delete 2",
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusInProgress,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -677,6 +692,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				queuedMessage.TextContent,
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 			_, err = service.CreateProcessedDiscordMessage(
@@ -688,6 +704,7 @@ func TestDiscordMessagesService(t *testing.T) {
 				inProgressMessage.TextContent,
 				discordIntegrationID,
 				models.ProcessedDiscordMessageStatusInProgress,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -17,6 +17,7 @@ import (
 
 type JobsService struct {
 	jobsRepo               *db.PostgresJobsRepository
+	transcriptsRepo        *db.PostgresJobTranscriptsRepository
 	slackMessagesService   services.SlackMessagesService
 	discordMessagesService services.DiscordMessagesService
 	txManager              services.TransactionManager
@@ -24,12 +25,14 @@ type JobsService struct {
 
 func NewJobsService(
 	repo *db.PostgresJobsRepository,
+	transcriptsRepo *db.PostgresJobTranscriptsRepository,
 	slackMessagesService services.SlackMessagesService,
 	discordMessagesService services.DiscordMessagesService,
 	txManager services.TransactionManager,
 ) *JobsService {
 	return &JobsService{
 		jobsRepo:               repo,
+		transcriptsRepo:        transcriptsRepo,
 		slackMessagesService:   slackMessagesService,
 		discordMessagesService: discordMessagesService,
 		txManager:              txManager,
@@ -88,6 +91,7 @@ func (s *JobsService) CreateSlackJob(
 	return job, nil
 }
 
+// GetJobByID returns the job if it is active - closed jobs are kept as history and are not returned
 func (s *JobsService) GetJobByID(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -110,6 +114,10 @@ func (s *JobsService) GetJobByID(
 		return mo.None[*models.Job](), nil
 	}
 	job := maybeJob.MustGet()
+	if job.Status.IsClosed() {
+		log.Printf("📋 Completed successfully - job %s is closed with status %s", job.ID, job.Status)
+		return mo.None[*models.Job](), nil
+	}
 
 	log.Printf("📋 Completed successfully - retrieved job with ID: %s", job.ID)
 	return mo.Some(job), nil
@@ -148,6 +156,35 @@ func (s *JobsService) GetJobBySlackThread(
 	return mo.Some(job), nil
 }
 
+// HasClosedJobForSlackThread checks if a job of the Slack thread was closed already
+func (s *JobsService) HasClosedJobForSlackThread(
+	ctx context.Context,
+	orgID models.OrgID,
+	threadTS, channelID, slackIntegrationID string,
+) (bool, error) {
+	log.Printf("📋 Starting to check for closed job of slack thread: %s, channel: %s", threadTS, channelID)
+	if threadTS == "" {
+		return false, fmt.Errorf("slack_thread_ts cannot be empty")
+	}
+	if channelID == "" {
+		return false, fmt.Errorf("slack_channel_id cannot be empty")
+	}
+	if !core.IsValidULID(slackIntegrationID) {
+		return false, fmt.Errorf("slack_integration_id must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return false, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	exists, err := s.jobsRepo.HasClosedJobForSlackThread(ctx, threadTS, channelID, slackIntegrationID, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for closed job by slack thread: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - slack thread %s has closed job: %t", threadTS, exists)
+	return exists, nil
+}
+
 func (s *JobsService) GetOrCreateJobForSlackThread(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -230,6 +267,61 @@ func (s *JobsService) UpdateJobTimestamp(
 	return nil
 }
 
+// RaiseJobPriority sets the job's priority if it is higher than the current one
+// Returns false if the job already has the same or a higher priority
+func (s *JobsService) RaiseJobPriority(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+	priority models.JobPriority,
+) (bool, error) {
+	log.Printf("📋 Starting to raise priority of job %s to %d", jobID, priority)
+	if !core.IsValidULID(jobID) {
+		return false, fmt.Errorf("job ID must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return false, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	maybeJob, err := s.jobsRepo.GetJobByID(ctx, jobID, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get job: %w", err)
+	}
+	if !maybeJob.IsPresent() || maybeJob.MustGet().Status.IsClosed() {
+		return false, core.ErrNotFound
+	}
+
+	raised, err := s.jobsRepo.RaiseJobPriority(ctx, jobID, orgID, priority)
+	if err != nil {
+		return false, fmt.Errorf("failed to raise job priority: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - raised priority of job %s: %t", jobID, raised)
+	return raised, nil
+}
+
+// HasQueuedJobsAbovePriority checks if the organization has queued jobs of the given type which should be
+// dispatched before a job with the given priority
+func (s *JobsService) HasQueuedJobsAbovePriority(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobType models.JobType,
+	priority models.JobPriority,
+) (bool, error) {
+	log.Printf("📋 Starting to check for queued %s jobs above priority %d", jobType, priority)
+	if !core.IsValidULID(string(orgID)) {
+		return false, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	exists, err := s.jobsRepo.HasQueuedJobsAbovePriority(ctx, orgID, jobType, priority)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for queued jobs above priority: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - queued %s jobs above priority %d: %t", jobType, priority, exists)
+	return exists, nil
+}
+
 func (s *JobsService) GetIdleJobs(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -370,6 +462,400 @@ func (s *JobsService) hasActiveDiscordMessages(
 	return len(inProgressMsgs) > 0, nil
 }
 
+// GetStuckJobs returns the jobs with in-progress messages which saw no agent activity for longer than stuckMinutes
+// Agent activity on a job (replies, progress events, artifacts) refreshes its updated_at timestamp.
+func (s *JobsService) GetStuckJobs(
+	ctx context.Context,
+	orgID models.OrgID,
+	stuckMinutes int,
+) ([]*models.Job, error) {
+	log.Printf("📋 Starting to get stuck jobs older than %d minutes for organization: %s", stuckMinutes, orgID)
+	if stuckMinutes <= 0 {
+		return nil, fmt.Errorf("stuck minutes must be greater than 0")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	allJobs, err := s.jobsRepo.GetJobs(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs: %w", err)
+	}
+
+	var stuckJobs []*models.Job
+	stuckThreshold := time.Now().Add(-time.Duration(stuckMinutes) * time.Minute)
+	for _, job := range allJobs {
+		if job.UpdatedAt.After(stuckThreshold) {
+			continue // The agent was active on the job recently
+		}
+
+		var stuck bool
+		switch job.JobType {
+		case models.JobTypeSlack:
+			stuck, err = s.hasStuckSlackMessages(ctx, orgID, job, stuckThreshold)
+		case models.JobTypeDiscord:
+			stuck, err = s.hasStuckDiscordMessages(ctx, orgID, job, stuckThreshold)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if stuck {
+			stuckJobs = append(stuckJobs, job)
+			log.Printf("⚠️ Job %s has in-progress messages without agent activity", job.ID)
+		}
+	}
+
+	log.Printf(
+		"📋 Completed successfully - found %d stuck jobs out of %d total jobs",
+		len(stuckJobs),
+		len(allJobs),
+	)
+	return stuckJobs, nil
+}
+
+// hasStuckSlackMessages checks if a Slack job has IN_PROGRESS messages sent to the agent before the threshold
+func (s *JobsService) hasStuckSlackMessages(
+	ctx context.Context,
+	orgID models.OrgID,
+	job *models.Job,
+	stuckThreshold time.Time,
+) (bool, error) {
+	if job.SlackPayload == nil {
+		return false, nil
+	}
+
+	inProgressMsgs, err := s.slackMessagesService.GetProcessedMessagesByJobIDAndStatus(
+		ctx, orgID, job.ID, models.ProcessedSlackMessageStatusInProgress, job.SlackPayload.IntegrationID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to check in-progress messages for job %s: %w", job.ID, err)
+	}
+	for _, message := range inProgressMsgs {
+		if message.UpdatedAt.Before(stuckThreshold) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasStuckDiscordMessages checks if a Discord job has IN_PROGRESS messages sent to the agent before the threshold
+func (s *JobsService) hasStuckDiscordMessages(
+	ctx context.Context,
+	orgID models.OrgID,
+	job *models.Job,
+	stuckThreshold time.Time,
+) (bool, error) {
+	if job.DiscordPayload == nil {
+		return false, nil
+	}
+
+	inProgressMsgs, err := s.discordMessagesService.GetProcessedMessagesByJobIDAndStatus(
+		ctx, orgID, job.ID, models.ProcessedDiscordMessageStatusInProgress, job.DiscordPayload.IntegrationID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to check in-progress Discord messages for job %s: %w", job.ID, err)
+	}
+	for _, message := range inProgressMsgs {
+		if message.UpdatedAt.Before(stuckThreshold) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CloseJob moves a job to a terminal status, keeping the job and its processed messages as history
+// Messages which are still queued or in progress are cancelled so they are never dispatched again.
+// Closing a job which was already closed or does not exist is a no-op.
+func (s *JobsService) CloseJob(
+	ctx context.Context,
+	orgID models.OrgID,
+	id string,
+	status models.JobStatus,
+	completionReason string,
+	assignedAgentID string,
+) error {
+	log.Printf("📋 Starting to close job %s with status %s", id, status)
+	if !core.IsValidULID(id) {
+		return fmt.Errorf("job ID must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return fmt.Errorf("organization_id must be a valid ULID")
+	}
+	if !status.IsClosed() {
+		return fmt.Errorf("job status %s is not a terminal status", status)
+	}
+	if assignedAgentID != "" && !core.IsValidULID(assignedAgentID) {
+		return fmt.Errorf("assigned agent ID must be a valid ULID")
+	}
+
+	maybeJob, err := s.jobsRepo.GetJobByID(ctx, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get job for closing: %w", err)
+	}
+	if !maybeJob.IsPresent() {
+		log.Printf("📋 Completed successfully - job not found: %s", id)
+		return nil
+	}
+	job := maybeJob.MustGet()
+	if job.Status.IsClosed() {
+		log.Printf("📋 Completed successfully - job %s is already closed with status %s", id, job.Status)
+		return nil
+	}
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		switch job.JobType {
+		case models.JobTypeSlack:
+			if err := s.cancelActiveSlackMessages(ctx, orgID, job); err != nil {
+				return err
+			}
+		case models.JobTypeDiscord:
+			if err := s.cancelActiveDiscordMessages(ctx, orgID, job); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported job type for closing: %s", job.JobType)
+		}
+
+		if _, err := s.jobsRepo.CloseJob(ctx, id, orgID, status, completionReason, assignedAgentID); err != nil {
+			return fmt.Errorf("failed to close job: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to close job in transaction: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - closed job %s with status %s", id, status)
+	return nil
+}
+
+// cancelActiveSlackMessages cancels the QUEUED and IN_PROGRESS messages of a Slack job
+func (s *JobsService) cancelActiveSlackMessages(ctx context.Context, orgID models.OrgID, job *models.Job) error {
+	if job.SlackPayload == nil {
+		return fmt.Errorf("slack job missing slack payload")
+	}
+
+	for _, status := range []models.ProcessedSlackMessageStatus{
+		models.ProcessedSlackMessageStatusQueued,
+		models.ProcessedSlackMessageStatusInProgress,
+	} {
+		messages, err := s.slackMessagesService.GetProcessedMessagesByJobIDAndStatus(
+			ctx, orgID, job.ID, status, job.SlackPayload.IntegrationID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get %s messages for job %s: %w", status, job.ID, err)
+		}
+		for _, message := range messages {
+			_, err := s.slackMessagesService.UpdateProcessedSlackMessage(
+				ctx, orgID, message.ID, models.ProcessedSlackMessageStatusCancelled, job.SlackPayload.IntegrationID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to cancel message %s: %w", message.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// cancelActiveDiscordMessages cancels the QUEUED and IN_PROGRESS messages of a Discord job
+func (s *JobsService) cancelActiveDiscordMessages(ctx context.Context, orgID models.OrgID, job *models.Job) error {
+	if job.DiscordPayload == nil {
+		return fmt.Errorf("discord job missing discord payload")
+	}
+
+	for _, status := range []models.ProcessedDiscordMessageStatus{
+		models.ProcessedDiscordMessageStatusQueued,
+		models.ProcessedDiscordMessageStatusInProgress,
+	} {
+		messages, err := s.discordMessagesService.GetProcessedMessagesByJobIDAndStatus(
+			ctx, orgID, job.ID, status, job.DiscordPayload.IntegrationID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get %s messages for job %s: %w", status, job.ID, err)
+		}
+		for _, message := range messages {
+			_, err := s.discordMessagesService.UpdateProcessedDiscordMessage(
+				ctx, orgID, message.ID, models.ProcessedDiscordMessageStatusCancelled, job.DiscordPayload.IntegrationID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to cancel message %s: %w", message.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// AddJobTranscriptMessage records a message exchanged in the job's thread in its transcript
+func (s *JobsService) AddJobTranscriptMessage(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+	author models.TranscriptAuthor,
+	authorID string,
+	messageType models.TranscriptMessageType,
+	content string,
+	processedMessageID string,
+) (*models.JobTranscriptMessage, error) {
+	log.Printf("📋 Starting to add %s transcript message to job %s", messageType, jobID)
+	if !core.IsValidULID(jobID) {
+		return nil, fmt.Errorf("job ID must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+	if author == "" {
+		return nil, fmt.Errorf("author cannot be empty")
+	}
+	if messageType == "" {
+		return nil, fmt.Errorf("message_type cannot be empty")
+	}
+
+	message := &models.JobTranscriptMessage{
+		ID:                 core.NewID("jtm"),
+		OrgID:              orgID,
+		JobID:              jobID,
+		Author:             author,
+		AuthorID:           authorID,
+		MessageType:        messageType,
+		Content:            content,
+		ProcessedMessageID: processedMessageID,
+	}
+	if err := s.transcriptsRepo.CreateTranscriptMessage(ctx, message); err != nil {
+		return nil, fmt.Errorf("failed to create job transcript message: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - added transcript message %s to job %s", message.ID, jobID)
+	return message, nil
+}
+
+// GetJobTranscript returns the transcript of a job, oldest message first - closed jobs included
+// Returns None if the job does not exist.
+func (s *JobsService) GetJobTranscript(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+) (mo.Option[[]*models.JobTranscriptMessage], error) {
+	log.Printf("📋 Starting to get transcript of job %s", jobID)
+	if !core.IsValidULID(jobID) {
+		return mo.None[[]*models.JobTranscriptMessage](), fmt.Errorf("job ID must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return mo.None[[]*models.JobTranscriptMessage](), fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	maybeJob, err := s.jobsRepo.GetJobByID(ctx, jobID, orgID)
+	if err != nil {
+		return mo.None[[]*models.JobTranscriptMessage](), fmt.Errorf("failed to get job: %w", err)
+	}
+	if !maybeJob.IsPresent() {
+		log.Printf("📋 Completed successfully - job not found: %s", jobID)
+		return mo.None[[]*models.JobTranscriptMessage](), nil
+	}
+
+	messages, err := s.transcriptsRepo.GetTranscriptMessagesByJobID(ctx, jobID, orgID)
+	if err != nil {
+		return mo.None[[]*models.JobTranscriptMessage](), fmt.Errorf("failed to get job transcript: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - retrieved %d transcript messages of job %s", len(messages), jobID)
+	return mo.Some(messages), nil
+}
+
+// ListJobs returns a page of the organization's jobs matching the filter, closed jobs included, newest job first
+func (s *JobsService) ListJobs(
+	ctx context.Context,
+	orgID models.OrgID,
+	filter models.JobsFilter,
+	limit int,
+) (*models.JobsPage, error) {
+	log.Printf("📋 Starting to list jobs for organization: %s", orgID)
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+	if limit < 1 || limit > models.MaxJobsPageSize {
+		return nil, fmt.Errorf("limit must be between 1 and %d", models.MaxJobsPageSize)
+	}
+	if filter.Cursor != "" && !core.IsValidULID(filter.Cursor) {
+		return nil, fmt.Errorf("cursor must be a valid job ID")
+	}
+
+	// One job more than requested tells whether there is a next page
+	jobs, err := s.jobsRepo.ListJobs(ctx, orgID, filter, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	page := &models.JobsPage{Jobs: jobs}
+	if len(jobs) > limit {
+		page.Jobs = jobs[:limit]
+		page.NextCursor = page.Jobs[limit-1].ID
+	}
+
+	log.Printf("📋 Completed successfully - listed %d jobs", len(page.Jobs))
+	return page, nil
+}
+
+// GetJobDetail returns a job with its processed messages and assignment history - closed jobs included
+// Returns None if the job does not exist.
+func (s *JobsService) GetJobDetail(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+) (mo.Option[*models.JobDetail], error) {
+	log.Printf("📋 Starting to get detail of job %s", jobID)
+	if !core.IsValidULID(jobID) {
+		return mo.None[*models.JobDetail](), fmt.Errorf("job ID must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return mo.None[*models.JobDetail](), fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	maybeJob, err := s.jobsRepo.GetJobByID(ctx, jobID, orgID)
+	if err != nil {
+		return mo.None[*models.JobDetail](), fmt.Errorf("failed to get job: %w", err)
+	}
+	job, ok := maybeJob.Get()
+	if !ok {
+		log.Printf("📋 Completed successfully - job not found: %s", jobID)
+		return mo.None[*models.JobDetail](), nil
+	}
+
+	detail := &models.JobDetail{Job: job}
+	switch job.JobType {
+	case models.JobTypeSlack:
+		detail.ProcessedSlackMessages, err = s.slackMessagesService.GetProcessedMessagesByJobID(
+			ctx,
+			orgID,
+			job.ID,
+			job.SlackPayload.IntegrationID,
+		)
+	case models.JobTypeDiscord:
+		detail.ProcessedDiscordMessages, err = s.discordMessagesService.GetProcessedMessagesByJobID(
+			ctx,
+			orgID,
+			job.ID,
+			job.DiscordPayload.IntegrationID,
+		)
+	}
+	if err != nil {
+		return mo.None[*models.JobDetail](), fmt.Errorf("failed to get processed messages of job: %w", err)
+	}
+
+	detail.AssignmentHistory, err = s.jobsRepo.GetJobAssignmentHistory(ctx, jobID, orgID)
+	if err != nil {
+		return mo.None[*models.JobDetail](), fmt.Errorf("failed to get job assignment history: %w", err)
+	}
+
+	log.Printf(
+		"📋 Completed successfully - retrieved job %s with %d assignments",
+		jobID,
+		len(detail.AssignmentHistory),
+	)
+	return mo.Some(detail), nil
+}
+
+// DeleteJob permanently deletes a job and its processed messages
+// Finished jobs are closed with CloseJob instead, so their history is kept.
 func (s *JobsService) DeleteJob(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -551,6 +1037,32 @@ func (s *JobsService) GetJobByDiscordThread(
 	return mo.Some(job), nil
 }
 
+// HasClosedJobForDiscordThread checks if a job of the Discord thread was closed already
+func (s *JobsService) HasClosedJobForDiscordThread(
+	ctx context.Context,
+	orgID models.OrgID,
+	threadID, discordIntegrationID string,
+) (bool, error) {
+	log.Printf("📋 Starting to check for closed job of discord thread: %s", threadID)
+	if threadID == "" {
+		return false, fmt.Errorf("discord_thread_id cannot be empty")
+	}
+	if !core.IsValidULID(discordIntegrationID) {
+		return false, fmt.Errorf("discord_integration_id must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return false, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	exists, err := s.jobsRepo.HasClosedJobForDiscordThread(ctx, threadID, discordIntegrationID, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for closed job by discord thread: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - discord thread %s has closed job: %t", threadID, exists)
+	return exists, nil
+}
+
 func (s *JobsService) GetOrCreateJobForDiscordThread(
 	ctx context.Context,
 	orgID models.OrgID,
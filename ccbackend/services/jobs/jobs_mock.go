@@ -45,6 +45,15 @@ func (m *MockJobsService) GetJobBySlackThread(
 	return args.Get(0).(mo.Option[*models.Job]), args.Error(1)
 }
 
+func (m *MockJobsService) HasClosedJobForSlackThread(
+	ctx context.Context,
+	orgID models.OrgID,
+	threadTS, channelID, slackIntegrationID string,
+) (bool, error) {
+	args := m.Called(ctx, orgID, threadTS, channelID, slackIntegrationID)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockJobsService) GetOrCreateJobForSlackThread(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -57,6 +66,26 @@ func (m *MockJobsService) GetOrCreateJobForSlackThread(
 	return args.Get(0).(*models.JobCreationResult), args.Error(1)
 }
 
+func (m *MockJobsService) RaiseJobPriority(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+	priority models.JobPriority,
+) (bool, error) {
+	args := m.Called(ctx, orgID, jobID, priority)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobsService) HasQueuedJobsAbovePriority(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobType models.JobType,
+	priority models.JobPriority,
+) (bool, error) {
+	args := m.Called(ctx, orgID, jobType, priority)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockJobsService) UpdateJobTimestamp(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -78,15 +107,78 @@ func (m *MockJobsService) GetIdleJobs(
 	return args.Get(0).([]*models.Job), args.Error(1)
 }
 
-func (m *MockJobsService) DeleteJob(
+func (m *MockJobsService) GetStuckJobs(
+	ctx context.Context,
+	orgID models.OrgID,
+	stuckMinutes int,
+) ([]*models.Job, error) {
+	args := m.Called(ctx, orgID, stuckMinutes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Job), args.Error(1)
+}
+
+func (m *MockJobsService) CloseJob(
 	ctx context.Context,
 	orgID models.OrgID,
 	id string,
+	status models.JobStatus,
+	completionReason string,
+	assignedAgentID string,
 ) error {
-	args := m.Called(ctx, orgID, id)
+	args := m.Called(ctx, orgID, id, status, completionReason, assignedAgentID)
 	return args.Error(0)
 }
 
+func (m *MockJobsService) AddJobTranscriptMessage(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+	author models.TranscriptAuthor,
+	authorID string,
+	messageType models.TranscriptMessageType,
+	content string,
+	processedMessageID string,
+) (*models.JobTranscriptMessage, error) {
+	args := m.Called(ctx, orgID, jobID, author, authorID, messageType, content, processedMessageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JobTranscriptMessage), args.Error(1)
+}
+
+func (m *MockJobsService) GetJobTranscript(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+) (mo.Option[[]*models.JobTranscriptMessage], error) {
+	args := m.Called(ctx, orgID, jobID)
+	return args.Get(0).(mo.Option[[]*models.JobTranscriptMessage]), args.Error(1)
+}
+
+func (m *MockJobsService) ListJobs(
+	ctx context.Context,
+	orgID models.OrgID,
+	filter models.JobsFilter,
+	limit int,
+) (*models.JobsPage, error) {
+	args := m.Called(ctx, orgID, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JobsPage), args.Error(1)
+}
+
+func (m *MockJobsService) GetJobDetail(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+) (mo.Option[*models.JobDetail], error) {
+	args := m.Called(ctx, orgID, jobID)
+	return args.Get(0).(mo.Option[*models.JobDetail]), args.Error(1)
+}
+
 func (m *MockJobsService) TESTS_UpdateJobUpdatedAt(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -129,6 +221,15 @@ func (m *MockJobsService) GetJobByDiscordThread(
 	return args.Get(0).(mo.Option[*models.Job]), args.Error(1)
 }
 
+func (m *MockJobsService) HasClosedJobForDiscordThread(
+	ctx context.Context,
+	orgID models.OrgID,
+	threadID, discordIntegrationID string,
+) (bool, error) {
+	args := m.Called(ctx, orgID, threadID, discordIntegrationID)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockJobsService) GetOrCreateJobForDiscordThread(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -38,6 +38,7 @@ func setupTestJobsService(t *testing.T) (*JobsService, *models.SlackIntegration,
 
 	// Create repositories
 	jobsRepo := db.NewPostgresJobsRepository(dbConn, cfg.DatabaseSchema)
+	jobTranscriptsRepo := db.NewPostgresJobTranscriptsRepository(dbConn, cfg.DatabaseSchema)
 	processedSlackMessagesRepo := db.NewPostgresProcessedSlackMessagesRepository(dbConn, cfg.DatabaseSchema)
 	processedDiscordMessagesRepo := db.NewPostgresProcessedDiscordMessagesRepository(dbConn, cfg.DatabaseSchema)
 	usersRepo := db.NewPostgresUsersRepository(dbConn, cfg.DatabaseSchema)
@@ -53,7 +54,7 @@ func setupTestJobsService(t *testing.T) (*JobsService, *models.SlackIntegration,
 	txManager := txmanager.NewTransactionManager(dbConn)
 	slackMessagesService := slackmessages.NewSlackMessagesService(processedSlackMessagesRepo)
 	discordMessagesService := discordmessages.NewDiscordMessagesService(processedDiscordMessagesRepo)
-	service := NewJobsService(jobsRepo, slackMessagesService, discordMessagesService, txManager)
+	service := NewJobsService(jobsRepo, jobTranscriptsRepo, slackMessagesService, discordMessagesService, txManager)
 
 	cleanup := func() {
 		// Clean up test data
@@ -382,6 +383,58 @@ func TestJobsService(t *testing.T) {
 			require.NoError(t, err)
 		})
 	})
+
+	t.Run("RaiseJobPriority", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			job, err := service.CreateSlackJob(
+				context.Background(),
+				testIntegration.OrgID,
+				"priority.test.thread",
+				"C3333333333",
+				"testuser",
+				slackIntegrationID,
+			)
+			require.NoError(t, err)
+			defer func() { _ = service.DeleteJob(context.Background(), testIntegration.OrgID, job.ID) }()
+			assert.Equal(t, models.JobPriorityNormal, job.Priority)
+
+			raised, err := service.RaiseJobPriority(
+				context.Background(),
+				testIntegration.OrgID,
+				job.ID,
+				models.JobPriorityUrgent,
+			)
+			require.NoError(t, err)
+			assert.True(t, raised)
+
+			// Raising to the same priority again is a no-op
+			raised, err = service.RaiseJobPriority(
+				context.Background(),
+				testIntegration.OrgID,
+				job.ID,
+				models.JobPriorityUrgent,
+			)
+			require.NoError(t, err)
+			assert.False(t, raised)
+
+			maybeFetchedJob, err := service.GetJobByID(context.Background(), testIntegration.OrgID, job.ID)
+			require.NoError(t, err)
+			require.True(t, maybeFetchedJob.IsPresent())
+			assert.Equal(t, models.JobPriorityUrgent, maybeFetchedJob.MustGet().Priority)
+		})
+
+		t.Run("NotFound", func(t *testing.T) {
+			_, err := service.RaiseJobPriority(
+				context.Background(),
+				testIntegration.OrgID,
+				core.NewID("j"),
+				models.JobPriorityUrgent,
+			)
+
+			require.Error(t, err)
+			assert.ErrorIs(t, err, core.ErrNotFound)
+		})
+	})
 }
 
 func TestJobsAndAgentsIntegration(t *testing.T) {
@@ -395,6 +448,7 @@ func TestJobsAndAgentsIntegration(t *testing.T) {
 
 	// Create repositories
 	jobsRepo := db.NewPostgresJobsRepository(dbConn, cfg.DatabaseSchema)
+	jobTranscriptsRepo := db.NewPostgresJobTranscriptsRepository(dbConn, cfg.DatabaseSchema)
 	processedSlackMessagesRepo := db.NewPostgresProcessedSlackMessagesRepository(dbConn, cfg.DatabaseSchema)
 	processedDiscordMessagesRepo := db.NewPostgresProcessedDiscordMessagesRepository(dbConn, cfg.DatabaseSchema)
 	agentsRepo := db.NewPostgresAgentsRepository(dbConn, cfg.DatabaseSchema)
@@ -419,8 +473,8 @@ func TestJobsAndAgentsIntegration(t *testing.T) {
 	txManager := txmanager.NewTransactionManager(dbConn)
 	slackMessagesService := slackmessages.NewSlackMessagesService(processedSlackMessagesRepo)
 	discordMessagesService := discordmessages.NewDiscordMessagesService(processedDiscordMessagesRepo)
-	jobsService := NewJobsService(jobsRepo, slackMessagesService, discordMessagesService, txManager)
-	agentsService := agents.NewAgentsService(agentsRepo, nil)
+	jobsService := NewJobsService(jobsRepo, jobTranscriptsRepo, slackMessagesService, discordMessagesService, txManager)
+	agentsService := agents.NewAgentsService(agentsRepo, nil, nil, nil, time.Hour)
 
 	// Use the shared integration ID
 	slackIntegrationID := testIntegration.ID
@@ -434,6 +488,8 @@ func TestJobsAndAgentsIntegration(t *testing.T) {
 			core.NewID("wsc"),
 			core.NewID("ccaid"),
 			"github.com/test/repo",
+			0,
+			nil,
 		)
 		require.NoError(t, err)
 		defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
@@ -509,6 +565,8 @@ func TestJobsAndAgentsIntegration(t *testing.T) {
 			core.NewID("wsc"),
 			core.NewID("ccaid"),
 			"github.com/test/repo",
+			0,
+			nil,
 		)
 		require.NoError(t, err)
 		defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent1.ID) }()
@@ -519,6 +577,8 @@ func TestJobsAndAgentsIntegration(t *testing.T) {
 			core.NewID("wsc"),
 			core.NewID("ccaid"),
 			"github.com/test/repo",
+			0,
+			nil,
 		)
 		require.NoError(t, err)
 		defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent2.ID) }()
@@ -604,6 +664,8 @@ func TestJobsAndAgentsIntegration(t *testing.T) {
 			core.NewID("wsc"),
 			core.NewID("ccaid"),
 			"github.com/test/repo",
+			0,
+			nil,
 		)
 		require.NoError(t, err)
 		defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
@@ -655,6 +717,8 @@ func TestJobsAndAgentsIntegration(t *testing.T) {
 			wsConnectionID,
 			core.NewID("ccaid"),
 			"github.com/test/repo",
+			0,
+			nil,
 		)
 		require.NoError(t, err)
 		defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
@@ -1012,6 +1076,83 @@ func TestJobsAndAgentsIntegration(t *testing.T) {
 		})
 	})
 
+	t.Run("GetStuckJobs", func(t *testing.T) {
+		t.Run("JobWithOldInProgressMessage", func(t *testing.T) {
+			job, err := jobsService.CreateSlackJob(
+				context.Background(),
+				orgID,
+				"stuck.inprogress.messages",
+				"C7777777777",
+				"testuser",
+				slackIntegrationID,
+			)
+			require.NoError(t, err)
+			defer func() { _ = jobsService.DeleteJob(context.Background(), orgID, job.ID) }()
+
+			inProgressMessage := &models.ProcessedSlackMessage{
+				ID:                 core.NewID("psm"),
+				JobID:              job.ID,
+				SlackChannelID:     "C7777777777",
+				SlackTS:            "msg-stuck-123.5678",
+				TextContent:        "Test stuck message",
+				Status:             models.ProcessedSlackMessageStatusInProgress,
+				SlackIntegrationID: slackIntegrationID,
+				OrgID:              orgID,
+			}
+			err = processedSlackMessagesRepo.CreateProcessedSlackMessage(context.Background(), inProgressMessage)
+			require.NoError(t, err)
+			defer func() {
+				_ = processedSlackMessagesRepo.DeleteProcessedSlackMessagesByJobID(
+					context.Background(),
+					job.ID,
+					slackIntegrationID,
+					orgID,
+				)
+			}()
+
+			// The agent received the message just now, so the job is not stuck
+			oldTimestamp := time.Now().Add(-10 * time.Minute)
+			err = jobsService.TESTS_UpdateJobUpdatedAt(
+				context.Background(),
+				orgID,
+				job.ID,
+				oldTimestamp,
+				slackIntegrationID,
+			)
+			require.NoError(t, err)
+			stuckJobs, err := jobsService.GetStuckJobs(context.Background(), orgID, 5)
+			require.NoError(t, err)
+			assert.False(t, jobFoundInIdleList(job.ID, stuckJobs), "Job with a fresh in-progress message is not stuck")
+
+			// Without agent activity since the message was sent, the job is stuck
+			updated, err := processedSlackMessagesRepo.TESTS_UpdateProcessedSlackMessageUpdatedAt(
+				context.Background(),
+				inProgressMessage.ID,
+				oldTimestamp,
+				slackIntegrationID,
+				orgID,
+			)
+			require.NoError(t, err)
+			require.True(t, updated)
+			stuckJobs, err = jobsService.GetStuckJobs(context.Background(), orgID, 5)
+			require.NoError(t, err)
+			assert.True(t, jobFoundInIdleList(job.ID, stuckJobs), "Job with an old in-progress message is stuck")
+
+			// Agent activity on the job keeps it from being stuck
+			err = jobsService.UpdateJobTimestamp(context.Background(), orgID, job.ID)
+			require.NoError(t, err)
+			stuckJobs, err = jobsService.GetStuckJobs(context.Background(), orgID, 5)
+			require.NoError(t, err)
+			assert.False(t, jobFoundInIdleList(job.ID, stuckJobs), "Job with recent agent activity is not stuck")
+		})
+
+		t.Run("InvalidStuckMinutes", func(t *testing.T) {
+			_, err := jobsService.GetStuckJobs(context.Background(), orgID, 0)
+			require.Error(t, err)
+			assert.Equal(t, "stuck minutes must be greater than 0", err.Error())
+		})
+	})
+
 	t.Run("DeleteJobWithAgentAssignment", func(t *testing.T) {
 		// Create an agent and job
 		agent, err := agentsService.UpsertActiveAgent(
@@ -1020,6 +1161,8 @@ func TestJobsAndAgentsIntegration(t *testing.T) {
 			core.NewID("wsc"),
 			core.NewID("ccaid"),
 			"github.com/test/repo",
+			0,
+			nil,
 		)
 		require.NoError(t, err)
 		defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
@@ -1073,4 +1216,424 @@ func TestJobsAndAgentsIntegration(t *testing.T) {
 		require.NoError(t, err)
 		assert.Empty(t, remainingJobs)
 	})
+
+	t.Run("CloseJob", func(t *testing.T) {
+		t.Run("KeepsJobAsHistory", func(t *testing.T) {
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				"github.com/test/repo",
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+
+			job, err := jobsService.CreateSlackJob(
+				context.Background(),
+				orgID,
+				"close.history.thread",
+				"C9999999999",
+				"testuser",
+				slackIntegrationID,
+			)
+			require.NoError(t, err)
+			defer func() { _ = jobsService.DeleteJob(context.Background(), orgID, job.ID) }()
+			assert.Equal(t, models.JobStatusActive, job.Status)
+
+			completedMessage := &models.ProcessedSlackMessage{
+				ID:                 core.NewID("psm"),
+				JobID:              job.ID,
+				SlackChannelID:     "C9999999999",
+				SlackTS:            "msg-completed-123.1234",
+				TextContent:        "Fix the bug",
+				Status:             models.ProcessedSlackMessageStatusCompleted,
+				SlackIntegrationID: slackIntegrationID,
+				OrgID:              orgID,
+			}
+			require.NoError(t, processedSlackMessagesRepo.CreateProcessedSlackMessage(context.Background(), completedMessage))
+			queuedMessage := &models.ProcessedSlackMessage{
+				ID:                 core.NewID("psm"),
+				JobID:              job.ID,
+				SlackChannelID:     "C9999999999",
+				SlackTS:            "msg-queued-123.1234",
+				TextContent:        "And add a test",
+				Status:             models.ProcessedSlackMessageStatusQueued,
+				SlackIntegrationID: slackIntegrationID,
+				OrgID:              orgID,
+			}
+			require.NoError(t, processedSlackMessagesRepo.CreateProcessedSlackMessage(context.Background(), queuedMessage))
+
+			err = jobsService.CloseJob(
+				context.Background(),
+				orgID,
+				job.ID,
+				models.JobStatusCompleted,
+				"Fixed the bug",
+				agent.ID,
+			)
+			require.NoError(t, err)
+
+			// Closed jobs are no longer returned by the active-job queries
+			maybeJob, err := jobsService.GetJobByID(context.Background(), orgID, job.ID)
+			require.NoError(t, err)
+			assert.False(t, maybeJob.IsPresent())
+			maybeJob, err = jobsService.GetJobBySlackThread(
+				context.Background(),
+				orgID,
+				"close.history.thread",
+				"C9999999999",
+				slackIntegrationID,
+			)
+			require.NoError(t, err)
+			assert.False(t, maybeJob.IsPresent())
+
+			// But the job is kept with its completion details
+			maybeClosedJob, err := jobsRepo.GetJobByID(context.Background(), job.ID, orgID)
+			require.NoError(t, err)
+			require.True(t, maybeClosedJob.IsPresent())
+			closedJob := maybeClosedJob.MustGet()
+			assert.Equal(t, models.JobStatusCompleted, closedJob.Status)
+			assert.Equal(t, "Fixed the bug", closedJob.CompletionReason)
+			assert.Equal(t, agent.ID, closedJob.AssignedAgentID)
+			require.NotNil(t, closedJob.CompletedAt)
+
+			// And so are its messages - the queued one is cancelled so it is never dispatched
+			messages, err := processedSlackMessagesRepo.GetProcessedSlackMessagesByJobID(
+				context.Background(),
+				job.ID,
+				slackIntegrationID,
+				orgID,
+			)
+			require.NoError(t, err)
+			statuses := make(map[string]models.ProcessedSlackMessageStatus)
+			for _, message := range messages {
+				statuses[message.ID] = message.Status
+			}
+			assert.Equal(t, map[string]models.ProcessedSlackMessageStatus{
+				completedMessage.ID: models.ProcessedSlackMessageStatusCompleted,
+				queuedMessage.ID:    models.ProcessedSlackMessageStatusCancelled,
+			}, statuses)
+
+			// Closing the job again keeps the original completion details
+			err = jobsService.CloseJob(context.Background(), orgID, job.ID, models.JobStatusFailed, "Failed", "")
+			require.NoError(t, err)
+			maybeClosedJob, err = jobsRepo.GetJobByID(context.Background(), job.ID, orgID)
+			require.NoError(t, err)
+			assert.Equal(t, models.JobStatusCompleted, maybeClosedJob.MustGet().Status)
+		})
+
+		t.Run("FollowUpInThreadStartsNewJob", func(t *testing.T) {
+			job, err := jobsService.CreateSlackJob(
+				context.Background(),
+				orgID,
+				"close.followup.thread",
+				"C9999999999",
+				"testuser",
+				slackIntegrationID,
+			)
+			require.NoError(t, err)
+			defer func() { _ = jobsService.DeleteJob(context.Background(), orgID, job.ID) }()
+
+			hasClosedJob, err := jobsService.HasClosedJobForSlackThread(
+				context.Background(),
+				orgID,
+				"close.followup.thread",
+				"C9999999999",
+				slackIntegrationID,
+			)
+			require.NoError(t, err)
+			assert.False(t, hasClosedJob)
+
+			err = jobsService.CloseJob(context.Background(), orgID, job.ID, models.JobStatusCompleted, "Done", "")
+			require.NoError(t, err)
+
+			hasClosedJob, err = jobsService.HasClosedJobForSlackThread(
+				context.Background(),
+				orgID,
+				"close.followup.thread",
+				"C9999999999",
+				slackIntegrationID,
+			)
+			require.NoError(t, err)
+			assert.True(t, hasClosedJob)
+
+			result, err := jobsService.GetOrCreateJobForSlackThread(
+				context.Background(),
+				orgID,
+				"close.followup.thread",
+				"C9999999999",
+				"otheruser",
+				slackIntegrationID,
+			)
+			require.NoError(t, err)
+			defer func() { _ = jobsService.DeleteJob(context.Background(), orgID, result.Job.ID) }()
+			assert.Equal(t, models.JobCreationStatusCreated, result.Status)
+			assert.NotEqual(t, job.ID, result.Job.ID)
+			assert.Equal(t, models.JobStatusActive, result.Job.Status)
+			assert.Equal(t, "otheruser", result.Job.SlackPayload.UserID)
+		})
+
+		t.Run("RejectsActiveStatus", func(t *testing.T) {
+			err := jobsService.CloseJob(context.Background(), orgID, core.NewID("j"), models.JobStatusActive, "", "")
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "is not a terminal status")
+		})
+	})
+
+	t.Run("JobTranscript", func(t *testing.T) {
+		t.Run("KeepsMessagesInBothDirectionsAfterJobIsClosed", func(t *testing.T) {
+			job, err := jobsService.CreateSlackJob(
+				context.Background(),
+				orgID,
+				"transcript.thread",
+				"C9999999999",
+				"testuser",
+				slackIntegrationID,
+			)
+			require.NoError(t, err)
+			defer func() { _ = jobsService.DeleteJob(context.Background(), orgID, job.ID) }()
+
+			agentID := core.NewID("a")
+			userMessage, err := jobsService.AddJobTranscriptMessage(
+				context.Background(),
+				orgID,
+				job.ID,
+				models.TranscriptAuthorUser,
+				"testuser",
+				models.TranscriptMessageTypeUserMessage,
+				"Please fix the login bug",
+				"psm_1",
+			)
+			require.NoError(t, err)
+			assert.False(t, userMessage.CreatedAt.IsZero())
+			_, err = jobsService.AddJobTranscriptMessage(
+				context.Background(),
+				orgID,
+				job.ID,
+				models.TranscriptAuthorAgent,
+				agentID,
+				models.TranscriptMessageTypeAssistantMessage,
+				"Fixed the login bug",
+				"psm_1",
+			)
+			require.NoError(t, err)
+			_, err = jobsService.AddJobTranscriptMessage(
+				context.Background(),
+				orgID,
+				job.ID,
+				models.TranscriptAuthorSystem,
+				"",
+				models.TranscriptMessageTypeSystemMessage,
+				models.ManualJobCompletionReason,
+				"",
+			)
+			require.NoError(t, err)
+
+			err = jobsService.CloseJob(context.Background(), orgID, job.ID, models.JobStatusCompleted, "Done", "")
+			require.NoError(t, err)
+
+			maybeTranscript, err := jobsService.GetJobTranscript(context.Background(), orgID, job.ID)
+			require.NoError(t, err)
+			require.True(t, maybeTranscript.IsPresent())
+			transcript := maybeTranscript.MustGet()
+			require.Len(t, transcript, 3)
+			assert.Equal(t, userMessage.ID, transcript[0].ID)
+			assert.Equal(t, models.TranscriptAuthorUser, transcript[0].Author)
+			assert.Equal(t, "testuser", transcript[0].AuthorID)
+			assert.Equal(t, "Please fix the login bug", transcript[0].Content)
+			assert.Equal(t, models.TranscriptAuthorAgent, transcript[1].Author)
+			assert.Equal(t, agentID, transcript[1].AuthorID)
+			assert.Equal(t, models.TranscriptMessageTypeAssistantMessage, transcript[1].MessageType)
+			assert.Equal(t, "psm_1", transcript[1].ProcessedMessageID)
+			assert.Equal(t, models.TranscriptAuthorSystem, transcript[2].Author)
+			assert.Empty(t, transcript[2].AuthorID)
+			assert.Empty(t, transcript[2].ProcessedMessageID)
+		})
+
+		t.Run("ReturnsNoneForUnknownJob", func(t *testing.T) {
+			maybeTranscript, err := jobsService.GetJobTranscript(context.Background(), orgID, core.NewID("j"))
+
+			require.NoError(t, err)
+			assert.False(t, maybeTranscript.IsPresent())
+		})
+	})
+
+	t.Run("ListJobs", func(t *testing.T) {
+		t.Run("FiltersAndPaginatesNewestFirst", func(t *testing.T) {
+			channelID := core.NewID("C")
+			var createdJobs []*models.Job
+			for i := 0; i < 3; i++ {
+				job, err := jobsService.CreateSlackJob(
+					context.Background(),
+					orgID,
+					fmt.Sprintf("listing.thread.%d", i),
+					channelID,
+					"listinguser",
+					slackIntegrationID,
+				)
+				require.NoError(t, err)
+				defer func() { _ = jobsService.DeleteJob(context.Background(), orgID, job.ID) }()
+				createdJobs = append(createdJobs, job)
+				// Job IDs only sort by creation time across milliseconds
+				time.Sleep(2 * time.Millisecond)
+			}
+			err := jobsService.CloseJob(
+				context.Background(),
+				orgID,
+				createdJobs[0].ID,
+				models.JobStatusCompleted,
+				"Done",
+				"",
+			)
+			require.NoError(t, err)
+
+			filter := models.JobsFilter{JobType: models.JobTypeSlack, ChannelID: channelID, CreatorID: "listinguser"}
+			firstPage, err := jobsService.ListJobs(context.Background(), orgID, filter, 2)
+			require.NoError(t, err)
+			require.Len(t, firstPage.Jobs, 2)
+			assert.Equal(t, createdJobs[2].ID, firstPage.Jobs[0].ID)
+			assert.Equal(t, createdJobs[1].ID, firstPage.Jobs[1].ID)
+			assert.Equal(t, createdJobs[1].ID, firstPage.NextCursor)
+
+			filter.Cursor = firstPage.NextCursor
+			secondPage, err := jobsService.ListJobs(context.Background(), orgID, filter, 2)
+			require.NoError(t, err)
+			require.Len(t, secondPage.Jobs, 1)
+			assert.Equal(t, createdJobs[0].ID, secondPage.Jobs[0].ID)
+			assert.Equal(t, models.JobStatusCompleted, secondPage.Jobs[0].Status)
+			assert.Empty(t, secondPage.NextCursor)
+
+			closedOnly, err := jobsService.ListJobs(
+				context.Background(),
+				orgID,
+				models.JobsFilter{ChannelID: channelID, Status: models.JobStatusCompleted},
+				10,
+			)
+			require.NoError(t, err)
+			require.Len(t, closedOnly.Jobs, 1)
+			assert.Equal(t, createdJobs[0].ID, closedOnly.Jobs[0].ID)
+
+			future := time.Now().Add(time.Hour)
+			createdLater, err := jobsService.ListJobs(
+				context.Background(),
+				orgID,
+				models.JobsFilter{ChannelID: channelID, CreatedAfter: &future},
+				10,
+			)
+			require.NoError(t, err)
+			assert.Empty(t, createdLater.Jobs)
+		})
+
+		t.Run("FiltersByAgentWhichHeldTheJob", func(t *testing.T) {
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				"github.com/test/repo",
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+			defer func() { _ = agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID) }()
+
+			job, err := jobsService.CreateSlackJob(
+				context.Background(),
+				orgID,
+				"listing.agent.thread",
+				"C1234567890",
+				"testuser",
+				slackIntegrationID,
+			)
+			require.NoError(t, err)
+			defer func() { _ = jobsService.DeleteJob(context.Background(), orgID, job.ID) }()
+
+			require.NoError(t, agentsService.AssignAgentToJob(context.Background(), orgID, agent.ID, job.ID))
+			require.NoError(t, agentsService.UnassignAgentFromJob(context.Background(), orgID, agent.ID, job.ID))
+
+			page, err := jobsService.ListJobs(context.Background(), orgID, models.JobsFilter{AgentID: agent.ID}, 10)
+			require.NoError(t, err)
+			require.Len(t, page.Jobs, 1)
+			assert.Equal(t, job.ID, page.Jobs[0].ID)
+		})
+
+		t.Run("RejectsInvalidLimit", func(t *testing.T) {
+			_, err := jobsService.ListJobs(context.Background(), orgID, models.JobsFilter{}, 0)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "limit must be between 1 and")
+		})
+	})
+
+	t.Run("GetJobDetail", func(t *testing.T) {
+		t.Run("ReturnsMessagesAndAssignmentHistory", func(t *testing.T) {
+			agent, err := agentsService.UpsertActiveAgent(
+				context.Background(),
+				orgID,
+				core.NewID("wsc"),
+				core.NewID("ccaid"),
+				"github.com/test/repo",
+				0,
+				nil,
+			)
+			require.NoError(t, err)
+
+			job, err := jobsService.CreateSlackJob(
+				context.Background(),
+				orgID,
+				"detail.thread",
+				"C1234567890",
+				"testuser",
+				slackIntegrationID,
+			)
+			require.NoError(t, err)
+			defer func() { _ = jobsService.DeleteJob(context.Background(), orgID, job.ID) }()
+
+			message, err := slackMessagesService.CreateProcessedSlackMessage(
+				context.Background(),
+				orgID,
+				job.ID,
+				"C1234567890",
+				"1234567890.000001",
+				"Please fix the login bug",
+				slackIntegrationID,
+				models.ProcessedSlackMessageStatusCompleted,
+				nil,
+			)
+			require.NoError(t, err)
+
+			// The first assignment ends with an unassignment, the second when the agent disconnects
+			require.NoError(t, agentsService.AssignAgentToJob(context.Background(), orgID, agent.ID, job.ID))
+			require.NoError(t, agentsService.UnassignAgentFromJob(context.Background(), orgID, agent.ID, job.ID))
+			require.NoError(t, agentsService.AssignAgentToJob(context.Background(), orgID, agent.ID, job.ID))
+			require.NoError(t, agentsService.DeleteActiveAgent(context.Background(), orgID, agent.ID))
+
+			maybeDetail, err := jobsService.GetJobDetail(context.Background(), orgID, job.ID)
+			require.NoError(t, err)
+			require.True(t, maybeDetail.IsPresent())
+			detail := maybeDetail.MustGet()
+			assert.Equal(t, job.ID, detail.Job.ID)
+			require.Len(t, detail.ProcessedSlackMessages, 1)
+			assert.Equal(t, message.ID, detail.ProcessedSlackMessages[0].ID)
+			assert.Equal(t, models.ProcessedSlackMessageStatusCompleted, detail.ProcessedSlackMessages[0].Status)
+			assert.Empty(t, detail.ProcessedDiscordMessages)
+			require.Len(t, detail.AssignmentHistory, 2)
+			for _, entry := range detail.AssignmentHistory {
+				assert.Equal(t, agent.ID, entry.AgentID)
+				assert.Equal(t, agent.CCAgentID, entry.CCAgentID)
+				assert.NotNil(t, entry.UnassignedAt)
+			}
+		})
+
+		t.Run("ReturnsNoneForUnknownJob", func(t *testing.T) {
+			maybeDetail, err := jobsService.GetJobDetail(context.Background(), orgID, core.NewID("j"))
+
+			require.NoError(t, err)
+			assert.False(t, maybeDetail.IsPresent())
+		})
+	})
 }
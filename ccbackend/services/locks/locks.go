@@ -0,0 +1,42 @@
+package locks
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"ccbackend/db"
+)
+
+type LocksService struct {
+	locksRepo *db.PostgresAdvisoryLocksRepository
+}
+
+func NewLocksService(repo *db.PostgresAdvisoryLocksRepository) *LocksService {
+	return &LocksService{locksRepo: repo}
+}
+
+// RunExclusively runs fn unless another replica is running work under the same lock name
+// Returns false without running fn if the lock is held elsewhere.
+func (s *LocksService) RunExclusively(
+	ctx context.Context,
+	name string,
+	fn func(ctx context.Context) error,
+) (bool, error) {
+	log.Printf("📋 Starting to run work under lock %s", name)
+	if name == "" {
+		return false, fmt.Errorf("lock name cannot be empty")
+	}
+
+	ran, err := s.locksRepo.TryWithLock(ctx, name, fn)
+	if err != nil {
+		return ran, fmt.Errorf("failed to run work under lock %s: %w", name, err)
+	}
+	if !ran {
+		log.Printf("📋 Completed successfully - lock %s is held by another replica, skipped", name)
+		return false, nil
+	}
+
+	log.Printf("📋 Completed successfully - ran work under lock %s", name)
+	return true, nil
+}
@@ -0,0 +1,21 @@
+package locks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLocksService is a mock implementation of the LocksService interface
+type MockLocksService struct {
+	mock.Mock
+}
+
+func (m *MockLocksService) RunExclusively(
+	ctx context.Context,
+	name string,
+	fn func(ctx context.Context) error,
+) (bool, error) {
+	args := m.Called(ctx, name, fn)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,94 @@
+package locks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ccbackend/core"
+	"ccbackend/db"
+	"ccbackend/testutils"
+)
+
+func setupLocksTest(t *testing.T) (*LocksService, func()) {
+	cfg, err := testutils.LoadTestConfig()
+	require.NoError(t, err)
+
+	dbConn, err := db.NewConnection(cfg.DatabaseURL)
+	require.NoError(t, err)
+
+	service := NewLocksService(db.NewPostgresAdvisoryLocksRepository(dbConn, cfg.DatabaseSchema))
+
+	cleanup := func() {
+		dbConn.Close()
+	}
+
+	return service, cleanup
+}
+
+func TestLocksService_RunExclusively(t *testing.T) {
+	service, cleanup := setupLocksTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	t.Run("Runs work while the lock is free", func(t *testing.T) {
+		lockName := core.NewID("lock")
+
+		calls := 0
+		ran, err := service.RunExclusively(ctx, lockName, func(ctx context.Context) error {
+			calls++
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.True(t, ran)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Skips work while another holder has the lock", func(t *testing.T) {
+		lockName := core.NewID("lock")
+
+		var nestedRan bool
+		ran, err := service.RunExclusively(ctx, lockName, func(ctx context.Context) error {
+			// A second session, like another replica, cannot take the lock
+			var nestedErr error
+			nestedRan, nestedErr = service.RunExclusively(ctx, lockName, func(ctx context.Context) error {
+				t.Fatal("work must not run while the lock is held")
+				return nil
+			})
+			return nestedErr
+		})
+
+		require.NoError(t, err)
+		assert.True(t, ran)
+		assert.False(t, nestedRan)
+
+		// The lock is released once the work is done
+		ran, err = service.RunExclusively(ctx, lockName, func(ctx context.Context) error { return nil })
+		require.NoError(t, err)
+		assert.True(t, ran)
+	})
+
+	t.Run("Returns the error of the work and releases the lock", func(t *testing.T) {
+		lockName := core.NewID("lock")
+		workErr := errors.New("work failed")
+
+		ran, err := service.RunExclusively(ctx, lockName, func(ctx context.Context) error { return workErr })
+		assert.True(t, ran)
+		assert.ErrorIs(t, err, workErr)
+
+		ran, err = service.RunExclusively(ctx, lockName, func(ctx context.Context) error { return nil })
+		require.NoError(t, err)
+		assert.True(t, ran)
+	})
+
+	t.Run("Empty lock name", func(t *testing.T) {
+		_, err := service.RunExclusively(ctx, "", func(ctx context.Context) error { return nil })
+		require.Error(t, err)
+		assert.Equal(t, "lock name cannot be empty", err.Error())
+	})
+}
@@ -126,6 +126,18 @@ type CCAgentContainerIntegrationsService interface {
 		orgID models.OrgID,
 		integrationID string,
 	) error
+	StartCCAgentContainerRedeploy(
+		ctx context.Context,
+		orgID models.OrgID,
+		integrationID string,
+	) (*models.CCAgentContainerIntegration, error)
+	UpdateCCAgentContainerRedeployStatus(
+		ctx context.Context,
+		orgID models.OrgID,
+		integrationID string,
+		status models.CCAgentRedeployStatus,
+		redeployError string,
+	) error
 	RedeployCCAgentContainer(
 		ctx context.Context,
 		orgID models.OrgID,
@@ -142,6 +154,8 @@ type AgentsService interface {
 		wsConnectionID string,
 		agentID string,
 		repoURL string,
+		maxConcurrency int,
+		labels models.AgentLabels,
 	) (*models.ActiveAgent, error)
 	DeleteActiveAgentByWsConnectionID(ctx context.Context, orgID models.OrgID, wsConnectionID string) error
 	DeleteActiveAgent(ctx context.Context, orgID models.OrgID, id string) error
@@ -159,6 +173,7 @@ type AgentsService interface {
 	) ([]*models.ActiveAgent, error)
 	CheckAgentHasActiveConnection(agent *models.ActiveAgent, connectedClientIDs []string) bool
 	AssignAgentToJob(ctx context.Context, orgID models.OrgID, agentID, jobID string) error
+	AssignAgentToJobWithinCapacity(ctx context.Context, orgID models.OrgID, agentID, jobID string) (bool, error)
 	UnassignAgentFromJob(ctx context.Context, orgID models.OrgID, agentID, jobID string) error
 	GetAgentByJobID(
 		ctx context.Context,
@@ -172,12 +187,66 @@ type AgentsService interface {
 	) (mo.Option[*models.ActiveAgent], error)
 	GetActiveAgentJobAssignments(ctx context.Context, orgID models.OrgID, agentID string) ([]string, error)
 	UpdateAgentLastActiveAt(ctx context.Context, orgID models.OrgID, wsConnectionID string) error
+	UpdateAgentTelemetry(
+		ctx context.Context,
+		orgID models.OrgID,
+		wsConnectionID string,
+		telemetry models.AgentTelemetry,
+	) error
+	IncrementAgentValidationFailures(ctx context.Context, orgID models.OrgID, wsConnectionID string) error
 	GetInactiveAgents(
 		ctx context.Context,
 		orgID models.OrgID,
 		inactiveThresholdMinutes int,
 	) ([]*models.ActiveAgent, error)
 	DisconnectAllActiveAgentsByOrganization(ctx context.Context, orgID models.OrgID) error
+	MarkAgentDisconnected(ctx context.Context, orgID models.OrgID, wsConnectionID string) error
+	GetDisconnectedAgents(
+		ctx context.Context,
+		orgID models.OrgID,
+		gracePeriod time.Duration,
+	) ([]*models.ActiveAgent, error)
+
+	// Draining
+	GetAllActiveAgents(ctx context.Context, orgID models.OrgID) ([]*models.ActiveAgent, error)
+	SetAgentDraining(ctx context.Context, orgID models.OrgID, id string, draining bool) (*models.ActiveAgent, error)
+	DrainAgentsOnRepo(ctx context.Context, orgID models.OrgID, repoURL string) ([]*models.ActiveAgent, error)
+	WaitForDrainingAgents(
+		ctx context.Context,
+		orgID models.OrgID,
+		repoURL string,
+		timeout time.Duration,
+	) ([]*models.ActiveAgent, error)
+
+	// Agent outbox
+	SendMessageToAgent(
+		ctx context.Context,
+		orgID models.OrgID,
+		wsConnectionID string,
+		jobID string,
+		processedMessageID string,
+		msg models.BaseMessage,
+	) error
+	RedeliverAgentMessage(ctx context.Context, orgID models.OrgID, message *models.AgentOutboxMessage) error
+	MarkAgentMessageDelivered(ctx context.Context, orgID models.OrgID, id string) (*models.AgentOutboxMessage, error)
+	MarkAgentMessageFailed(ctx context.Context, orgID models.OrgID, id, reason string) error
+	RecordAgentMessageDeliveryFailure(ctx context.Context, orgID models.OrgID, id string, reason error) error
+	FailPendingAgentJobMessages(ctx context.Context, orgID models.OrgID, jobID, reason string) error
+	GetAgentMessagesDueForRetry(ctx context.Context, orgID models.OrgID) ([]*models.AgentOutboxMessage, error)
+	GetAgentMessages(ctx context.Context, orgID models.OrgID, jobID string) ([]*models.AgentOutboxMessage, error)
+
+	// Agent inbox
+	ClaimAgentInboundMessage(
+		ctx context.Context,
+		orgID models.OrgID,
+		agentID string,
+		messageID string,
+		messageType string,
+	) (bool, error)
+	CompleteAgentInboundMessage(ctx context.Context, orgID models.OrgID, agentID string, messageID string) error
+	ReleaseAgentInboundMessage(ctx context.Context, orgID models.OrgID, agentID string, messageID string) error
+	DeleteExpiredAgentInboundMessages(ctx context.Context) (int64, error)
+	DeleteFinishedAgentMessages(ctx context.Context) (int64, error)
 }
 
 // SlackMessagesService defines the interface for processed slack message operations
@@ -188,6 +257,7 @@ type SlackMessagesService interface {
 		jobID string,
 		slackChannelID, slackTS, textContent, slackIntegrationID string,
 		status models.ProcessedSlackMessageStatus,
+		attachments models.Attachments,
 	) (*models.ProcessedSlackMessage, error)
 	UpdateProcessedSlackMessage(
 		ctx context.Context,
@@ -196,6 +266,12 @@ type SlackMessagesService interface {
 		status models.ProcessedSlackMessageStatus,
 		slackIntegrationID string,
 	) (*models.ProcessedSlackMessage, error)
+	GetProcessedMessagesByJobID(
+		ctx context.Context,
+		orgID models.OrgID,
+		jobID string,
+		slackIntegrationID string,
+	) ([]*models.ProcessedSlackMessage, error)
 	GetProcessedMessagesByJobIDAndStatus(
 		ctx context.Context,
 		orgID models.OrgID,
@@ -249,6 +325,7 @@ type DiscordMessagesService interface {
 		jobID string,
 		discordMessageID, discordThreadID, textContent, discordIntegrationID string,
 		status models.ProcessedDiscordMessageStatus,
+		attachments models.Attachments,
 	) (*models.ProcessedDiscordMessage, error)
 	UpdateProcessedDiscordMessage(
 		ctx context.Context,
@@ -257,6 +334,12 @@ type DiscordMessagesService interface {
 		status models.ProcessedDiscordMessageStatus,
 		discordIntegrationID string,
 	) (*models.ProcessedDiscordMessage, error)
+	GetProcessedMessagesByJobID(
+		ctx context.Context,
+		orgID models.OrgID,
+		jobID string,
+		discordIntegrationID string,
+	) ([]*models.ProcessedDiscordMessage, error)
 	GetProcessedMessagesByJobIDAndStatus(
 		ctx context.Context,
 		orgID models.OrgID,
@@ -310,8 +393,40 @@ type JobsService interface {
 		id string,
 	) (mo.Option[*models.Job], error)
 	UpdateJobTimestamp(ctx context.Context, orgID models.OrgID, jobID string) error
+	RaiseJobPriority(ctx context.Context, orgID models.OrgID, jobID string, priority models.JobPriority) (bool, error)
+	HasQueuedJobsAbovePriority(
+		ctx context.Context,
+		orgID models.OrgID,
+		jobType models.JobType,
+		priority models.JobPriority,
+	) (bool, error)
 	GetIdleJobs(ctx context.Context, orgID models.OrgID, idleMinutes int) ([]*models.Job, error)
-	DeleteJob(ctx context.Context, orgID models.OrgID, id string) error
+	GetStuckJobs(ctx context.Context, orgID models.OrgID, stuckMinutes int) ([]*models.Job, error)
+	CloseJob(
+		ctx context.Context,
+		orgID models.OrgID,
+		id string,
+		status models.JobStatus,
+		completionReason string,
+		assignedAgentID string,
+	) error
+	AddJobTranscriptMessage(
+		ctx context.Context,
+		orgID models.OrgID,
+		jobID string,
+		author models.TranscriptAuthor,
+		authorID string,
+		messageType models.TranscriptMessageType,
+		content string,
+		processedMessageID string,
+	) (*models.JobTranscriptMessage, error)
+	GetJobTranscript(
+		ctx context.Context,
+		orgID models.OrgID,
+		jobID string,
+	) (mo.Option[[]*models.JobTranscriptMessage], error)
+	ListJobs(ctx context.Context, orgID models.OrgID, filter models.JobsFilter, limit int) (*models.JobsPage, error)
+	GetJobDetail(ctx context.Context, orgID models.OrgID, jobID string) (mo.Option[*models.JobDetail], error)
 
 	// Slack-specific methods
 	CreateSlackJob(
@@ -324,6 +439,11 @@ type JobsService interface {
 		orgID models.OrgID,
 		threadTS, channelID, slackIntegrationID string,
 	) (mo.Option[*models.Job], error)
+	HasClosedJobForSlackThread(
+		ctx context.Context,
+		orgID models.OrgID,
+		threadTS, channelID, slackIntegrationID string,
+	) (bool, error)
 	GetOrCreateJobForSlackThread(
 		ctx context.Context,
 		orgID models.OrgID,
@@ -341,6 +461,11 @@ type JobsService interface {
 		orgID models.OrgID,
 		threadID, discordIntegrationID string,
 	) (mo.Option[*models.Job], error)
+	HasClosedJobForDiscordThread(
+		ctx context.Context,
+		orgID models.OrgID,
+		threadID, discordIntegrationID string,
+	) (bool, error)
 	GetOrCreateJobForDiscordThread(
 		ctx context.Context,
 		orgID models.OrgID,
@@ -393,6 +518,19 @@ type ConnectedChannelsService interface {
 		guildID string,
 		channelID string,
 	) (mo.Option[*models.DiscordConnectedChannel], error)
+
+	// Agent routing
+	UpdateAgentSelector(
+		ctx context.Context,
+		orgID models.OrgID,
+		id string,
+		agentSelector models.AgentLabels,
+	) (models.ConnectedChannel, error)
+}
+
+// LocksService keeps work which must not run on several backend replicas at once to a single replica
+type LocksService interface {
+	RunExclusively(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error)
 }
 
 // TransactionManager handles database transactions via context
@@ -29,6 +29,9 @@ func (s *SettingsService) UpsertBooleanSetting(
 	if err := s.validateKey(key, models.SettingTypeBool); err != nil {
 		return fmt.Errorf("invalid setting: %w", err)
 	}
+	if err := s.validateValue(key, value); err != nil {
+		return fmt.Errorf("invalid setting: %w", err)
+	}
 
 	_, err := s.settingsRepo.UpsertBooleanSetting(
 		ctx,
@@ -56,6 +59,9 @@ func (s *SettingsService) UpsertStringSetting(
 	if err := s.validateKey(key, models.SettingTypeString); err != nil {
 		return err
 	}
+	if err := s.validateValue(key, value); err != nil {
+		return err
+	}
 
 	_, err := s.settingsRepo.UpsertStringSetting(
 		ctx,
@@ -83,6 +89,9 @@ func (s *SettingsService) UpsertStringArraySetting(
 	if err := s.validateKey(key, models.SettingTypeStringArr); err != nil {
 		return fmt.Errorf("invalid setting: %w", err)
 	}
+	if err := s.validateValue(key, value); err != nil {
+		return fmt.Errorf("invalid setting: %w", err)
+	}
 
 	_, err := s.settingsRepo.UpsertStringArraySetting(
 		ctx,
@@ -243,6 +252,15 @@ func (s *SettingsService) validateKey(key string, expectedType models.SettingTyp
 	return nil
 }
 
+// validateValue runs the validation the setting key defines for its values, if any
+func (s *SettingsService) validateValue(key string, value any) error {
+	keyDef := models.SupportedSettings[key]
+	if keyDef.Validate == nil {
+		return nil
+	}
+	return keyDef.Validate(value)
+}
+
 func (s *SettingsService) getDefaultValue(key string, expectedType models.SettingType) any {
 	keyDef, exists := models.SupportedSettings[key]
 	if !exists || keyDef.Type != expectedType {
@@ -200,3 +200,28 @@ func TestSettingsService_ValidateKey(t *testing.T) {
 		assert.Contains(t, err.Error(), "expects type bool, got string")
 	})
 }
+
+func TestSettingsService_ValidateValue(t *testing.T) {
+	service := &SettingsService{}
+
+	t.Run("accepts any value for keys without validation", func(t *testing.T) {
+		err := service.validateValue("org-onboarding_finished", true)
+		assert.NoError(t, err)
+	})
+
+	t.Run("accepts valid idle job timeout", func(t *testing.T) {
+		assert.NoError(t, service.validateValue(models.IdleJobTimeoutSettingKey, "24h"))
+		assert.NoError(t, service.validateValue(models.IdleJobTimeoutSettingKey, ""))
+	})
+
+	t.Run("rejects malformed idle job timeout", func(t *testing.T) {
+		err := service.validateValue(models.IdleJobTimeoutSettingKey, "one day")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "idle job timeout must be a duration like 24h")
+	})
+
+	t.Run("rejects too short idle job timeout", func(t *testing.T) {
+		err := service.validateValue(models.IdleJobTimeoutSettingKey, "30s")
+		assert.EqualError(t, err, "idle job timeout must be at least 1m0s")
+	})
+}
@@ -29,6 +29,7 @@ func (s *SlackMessagesService) CreateProcessedSlackMessage(
 	jobID string,
 	slackChannelID, slackTS, textContent, slackIntegrationID string,
 	status models.ProcessedSlackMessageStatus,
+	attachments models.Attachments,
 ) (*models.ProcessedSlackMessage, error) {
 	log.Printf(
 		"📋 Starting to create processed slack message for job: %s, channel: %s, ts: %s, organization: %s",
@@ -63,6 +64,7 @@ func (s *SlackMessagesService) CreateProcessedSlackMessage(
 		SlackChannelID:     slackChannelID,
 		SlackTS:            slackTS,
 		TextContent:        textContent,
+		Attachments:        attachments,
 		Status:             status,
 		SlackIntegrationID: slackIntegrationID,
 		OrgID:              orgID,
@@ -113,6 +115,33 @@ func (s *SlackMessagesService) UpdateProcessedSlackMessage(
 	return updatedMessage, nil
 }
 
+// GetProcessedMessagesByJobID returns every processed message of a job regardless of status, oldest first
+func (s *SlackMessagesService) GetProcessedMessagesByJobID(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+	slackIntegrationID string,
+) ([]*models.ProcessedSlackMessage, error) {
+	log.Printf("📋 Starting to get processed messages for job: %s", jobID)
+	if !core.IsValidULID(jobID) {
+		return nil, fmt.Errorf("job ID must be a valid ULID")
+	}
+	if !core.IsValidULID(slackIntegrationID) {
+		return nil, fmt.Errorf("slack_integration_id must be a valid ULID")
+	}
+	if !core.IsValidULID(string(orgID)) {
+		return nil, fmt.Errorf("organization_id must be a valid ULID")
+	}
+
+	messages, err := s.processedSlackMessagesRepo.GetProcessedSlackMessagesByJobID(ctx, jobID, slackIntegrationID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get processed messages: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - retrieved %d processed messages", len(messages))
+	return messages, nil
+}
+
 func (s *SlackMessagesService) GetProcessedMessagesByJobIDAndStatus(
 	ctx context.Context,
 	orgID models.OrgID,
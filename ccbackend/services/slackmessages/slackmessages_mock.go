@@ -20,8 +20,9 @@ func (m *MockSlackMessagesService) CreateProcessedSlackMessage(
 	jobID string,
 	slackChannelID, slackTS, textContent, slackIntegrationID string,
 	status models.ProcessedSlackMessageStatus,
+	attachments models.Attachments,
 ) (*models.ProcessedSlackMessage, error) {
-	args := m.Called(ctx, orgID, jobID, slackChannelID, slackTS, textContent, slackIntegrationID, status)
+	args := m.Called(ctx, orgID, jobID, slackChannelID, slackTS, textContent, slackIntegrationID, status, attachments)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -42,6 +43,19 @@ func (m *MockSlackMessagesService) UpdateProcessedSlackMessage(
 	return args.Get(0).(*models.ProcessedSlackMessage), args.Error(1)
 }
 
+func (m *MockSlackMessagesService) GetProcessedMessagesByJobID(
+	ctx context.Context,
+	orgID models.OrgID,
+	jobID string,
+	slackIntegrationID string,
+) ([]*models.ProcessedSlackMessage, error) {
+	args := m.Called(ctx, orgID, jobID, slackIntegrationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ProcessedSlackMessage), args.Error(1)
+}
+
 func (m *MockSlackMessagesService) GetProcessedMessagesByJobIDAndStatus(
 	ctx context.Context,
 	orgID models.OrgID,
@@ -88,6 +88,7 @@ func TestSlackMessagesService(t *testing.T) {
 				textContent,
 				slackIntegrationID,
 				status,
+				nil,
 			)
 			defer func() {
 				_ = processedSlackMessagesRepo.DeleteProcessedSlackMessagesByJobID(
@@ -109,6 +110,47 @@ func TestSlackMessagesService(t *testing.T) {
 			assert.Equal(t, orgID, message.OrgID)
 		})
 
+		t.Run("StoresAttachments", func(t *testing.T) {
+			attachments := models.Attachments{{
+				Name:     "screenshot.png",
+				MimeType: "image/png",
+				Size:     2048,
+				URL:      "https://files.slack.com/screenshot.png",
+			}}
+
+			message, err := slackMessagesService.CreateProcessedSlackMessage(
+				context.Background(),
+				orgID,
+				jobID,
+				"C1234567",
+				"1234567890.654321",
+				"See the screenshot",
+				slackIntegrationID,
+				models.ProcessedSlackMessageStatusQueued,
+				attachments,
+			)
+			defer func() {
+				_ = processedSlackMessagesRepo.DeleteProcessedSlackMessagesByJobID(
+					context.Background(),
+					jobID,
+					slackIntegrationID,
+					orgID,
+				)
+			}()
+
+			require.NoError(t, err)
+			assert.Equal(t, attachments, message.Attachments)
+
+			maybeStored, err := slackMessagesService.GetProcessedSlackMessageByID(
+				context.Background(),
+				orgID,
+				message.ID,
+			)
+			require.NoError(t, err)
+			require.True(t, maybeStored.IsPresent())
+			assert.Equal(t, attachments, maybeStored.MustGet().Attachments)
+		})
+
 		t.Run("EmptySlackChannelID", func(t *testing.T) {
 			_, err := slackMessagesService.CreateProcessedSlackMessage(
 				context.Background(),
@@ -119,6 +161,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Hello",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusQueued,
+				nil,
 			)
 			assert.Error(t, err)
 			assert.Contains(t, err.Error(), "slack_channel_id cannot be empty")
@@ -134,6 +177,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Hello",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusQueued,
+				nil,
 			)
 			assert.Error(t, err)
 			assert.Contains(t, err.Error(), "slack_ts cannot be empty")
@@ -149,6 +193,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusQueued,
+				nil,
 			)
 			assert.Error(t, err)
 			assert.Contains(t, err.Error(), "text_content cannot be empty")
@@ -167,6 +212,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Hello, world!",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() {
@@ -217,6 +263,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Hello, world!",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() {
@@ -264,6 +311,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Message 1",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -276,6 +324,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Message 2",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusInProgress,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -326,6 +375,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"First message",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusCompleted,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -340,6 +390,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Latest message",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusCompleted,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -391,6 +442,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Message 1",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -403,6 +455,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Message 2",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusCompleted,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -495,6 +548,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Job1 Message 1",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -507,6 +561,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Job1 Message 2",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusInProgress,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -520,6 +575,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Job2 Message 1",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusQueued,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -532,6 +588,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Job2 Message 2",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusCompleted,
+				nil,
 			)
 			require.NoError(t, err)
 
@@ -574,6 +631,7 @@ func TestSlackMessagesService(t *testing.T) {
 				"Test message",
 				slackIntegrationID,
 				models.ProcessedSlackMessageStatusCompleted,
+				nil,
 			)
 			require.NoError(t, err)
 			defer func() {
@@ -0,0 +1,8 @@
+-- Add max_concurrency column to active_agents table for agent-declared capacity limits
+-- 0 means the agent did not declare a limit
+ALTER TABLE claudecontrol.active_agents
+ADD COLUMN max_concurrency INTEGER NOT NULL DEFAULT 0;
+
+-- Also add to test schema
+ALTER TABLE claudecontrol_test.active_agents
+ADD COLUMN max_concurrency INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,70 @@
+-- Create tables backing the Socket.IO backplane which lets multiple backend replicas
+-- share their agent connections and relay messages to agents connected to another replica
+
+-- Backend replicas currently serving Socket.IO connections (kept alive via heartbeats)
+CREATE TABLE claudecontrol.socketio_instances (
+    id TEXT PRIMARY KEY,                           -- ULID with "sio_" prefix
+    last_heartbeat_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Socket.IO clients connected to each replica
+CREATE TABLE claudecontrol.socketio_connections (
+    client_id TEXT PRIMARY KEY,                    -- Socket.IO client ID ("cl_" prefix)
+    instance_id TEXT NOT NULL,                     -- Replica holding the socket
+    organization_id TEXT NOT NULL,
+    ccagent_id TEXT NOT NULL,
+    connected_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_socketio_connections_instance
+        FOREIGN KEY (instance_id) REFERENCES claudecontrol.socketio_instances(id) ON DELETE CASCADE
+);
+
+-- Messages waiting to be emitted by the replica holding the target client
+CREATE TABLE claudecontrol.socketio_relayed_messages (
+    id TEXT PRIMARY KEY,                           -- ULID with "rm_" prefix
+    instance_id TEXT NOT NULL,                     -- Replica which must deliver the message
+    client_id TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('message', 'disconnect')),
+    payload JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_socketio_relayed_messages_instance
+        FOREIGN KEY (instance_id) REFERENCES claudecontrol.socketio_instances(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_socketio_connections_instance_id ON claudecontrol.socketio_connections(instance_id);
+CREATE INDEX idx_socketio_relayed_messages_instance_id ON claudecontrol.socketio_relayed_messages(instance_id);
+
+-- Create the same tables for test schema
+CREATE TABLE claudecontrol_test.socketio_instances (
+    id TEXT PRIMARY KEY,                           -- ULID with "sio_" prefix
+    last_heartbeat_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE claudecontrol_test.socketio_connections (
+    client_id TEXT PRIMARY KEY,                    -- Socket.IO client ID ("cl_" prefix)
+    instance_id TEXT NOT NULL,                     -- Replica holding the socket
+    organization_id TEXT NOT NULL,
+    ccagent_id TEXT NOT NULL,
+    connected_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_socketio_connections_instance_test
+        FOREIGN KEY (instance_id) REFERENCES claudecontrol_test.socketio_instances(id) ON DELETE CASCADE
+);
+
+CREATE TABLE claudecontrol_test.socketio_relayed_messages (
+    id TEXT PRIMARY KEY,                           -- ULID with "rm_" prefix
+    instance_id TEXT NOT NULL,                     -- Replica which must deliver the message
+    client_id TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('message', 'disconnect')),
+    payload JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_socketio_relayed_messages_instance_test
+        FOREIGN KEY (instance_id) REFERENCES claudecontrol_test.socketio_instances(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_socketio_connections_instance_id_test ON claudecontrol_test.socketio_connections(instance_id);
+CREATE INDEX idx_socketio_relayed_messages_instance_id_test ON claudecontrol_test.socketio_relayed_messages(instance_id);
@@ -0,0 +1,15 @@
+-- Add labels advertised by agents at connect time (e.g. {"team": "payments", "env": "staging"})
+ALTER TABLE claudecontrol.active_agents
+ADD COLUMN labels JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- Add label selector which agents must satisfy to be assigned jobs from a channel
+-- An empty selector matches any agent
+ALTER TABLE claudecontrol.connected_channels
+ADD COLUMN agent_selector JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- Also add to test schema
+ALTER TABLE claudecontrol_test.active_agents
+ADD COLUMN labels JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+ALTER TABLE claudecontrol_test.connected_channels
+ADD COLUMN agent_selector JSONB NOT NULL DEFAULT '{}'::jsonb;
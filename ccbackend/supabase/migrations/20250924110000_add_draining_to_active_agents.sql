@@ -0,0 +1,7 @@
+-- Add draining flag - draining agents finish their current jobs but are not assigned new ones
+ALTER TABLE claudecontrol.active_agents
+ADD COLUMN draining BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Also add to test schema
+ALTER TABLE claudecontrol_test.active_agents
+ADD COLUMN draining BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,13 @@
+-- Add agent telemetry - metadata reported in the handshake and pings, and the last measured ping round-trip time
+ALTER TABLE claudecontrol.active_agents
+ADD COLUMN version TEXT NOT NULL DEFAULT '',
+ADD COLUMN os TEXT NOT NULL DEFAULT '',
+ADD COLUMN hostname TEXT NOT NULL DEFAULT '',
+ADD COLUMN last_rtt_ms INTEGER;
+
+-- Also add to test schema
+ALTER TABLE claudecontrol_test.active_agents
+ADD COLUMN version TEXT NOT NULL DEFAULT '',
+ADD COLUMN os TEXT NOT NULL DEFAULT '',
+ADD COLUMN hostname TEXT NOT NULL DEFAULT '',
+ADD COLUMN last_rtt_ms INTEGER;
@@ -0,0 +1,7 @@
+-- Add job priority - queued jobs with a higher priority are dispatched to agents first
+ALTER TABLE claudecontrol.jobs
+ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+
+-- Also add to test schema
+ALTER TABLE claudecontrol_test.jobs
+ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,44 @@
+-- Create the outbox of messages sent to agents - a message stays PENDING until the agent
+-- acknowledges it and is resent on a backoff until it is acknowledged or given up on
+CREATE TABLE claudecontrol.agent_outbox_messages (
+    id TEXT PRIMARY KEY,                           -- ID of the message sent to the agent ("msg_" prefix)
+    organization_id TEXT NOT NULL,
+    agent_id TEXT NOT NULL REFERENCES claudecontrol.active_agents(id) ON DELETE CASCADE,
+    job_id TEXT NOT NULL,
+    processed_message_id TEXT NOT NULL,            -- Processed Slack or Discord message the agent works on
+    message_type TEXT NOT NULL,
+    payload JSONB NOT NULL,                        -- The full message, resent unchanged so the agent can deduplicate
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DELIVERED', 'FAILED')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_agent_outbox_messages_pending
+    ON claudecontrol.agent_outbox_messages(organization_id, next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_agent_outbox_messages_job_id ON claudecontrol.agent_outbox_messages(job_id);
+
+-- Create the same table for test schema
+CREATE TABLE claudecontrol_test.agent_outbox_messages (
+    id TEXT PRIMARY KEY,                           -- ID of the message sent to the agent ("msg_" prefix)
+    organization_id TEXT NOT NULL,
+    agent_id TEXT NOT NULL REFERENCES claudecontrol_test.active_agents(id) ON DELETE CASCADE,
+    job_id TEXT NOT NULL,
+    processed_message_id TEXT NOT NULL,            -- Processed Slack or Discord message the agent works on
+    message_type TEXT NOT NULL,
+    payload JSONB NOT NULL,                        -- The full message, resent unchanged so the agent can deduplicate
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DELIVERED', 'FAILED')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_agent_outbox_messages_pending_test
+    ON claudecontrol_test.agent_outbox_messages(organization_id, next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_agent_outbox_messages_job_id_test ON claudecontrol_test.agent_outbox_messages(job_id);
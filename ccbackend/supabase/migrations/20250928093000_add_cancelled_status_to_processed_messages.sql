@@ -0,0 +1,26 @@
+-- Allow processed messages to be cancelled by the job creator, which stops the agent working on them
+
+ALTER TABLE claudecontrol.processed_slack_messages
+DROP CONSTRAINT processed_slack_messages_status_check;
+ALTER TABLE claudecontrol.processed_slack_messages
+ADD CONSTRAINT processed_slack_messages_status_check
+CHECK (status IN ('QUEUED', 'IN_PROGRESS', 'COMPLETED', 'CANCELLED'));
+
+ALTER TABLE claudecontrol.processed_discord_messages
+DROP CONSTRAINT processed_discord_messages_status_check;
+ALTER TABLE claudecontrol.processed_discord_messages
+ADD CONSTRAINT processed_discord_messages_status_check
+CHECK (status IN ('QUEUED', 'IN_PROGRESS', 'COMPLETED', 'CANCELLED'));
+
+-- Same changes for test schema
+ALTER TABLE claudecontrol_test.processed_slack_messages
+DROP CONSTRAINT processed_slack_messages_status_check;
+ALTER TABLE claudecontrol_test.processed_slack_messages
+ADD CONSTRAINT processed_slack_messages_status_check
+CHECK (status IN ('QUEUED', 'IN_PROGRESS', 'COMPLETED', 'CANCELLED'));
+
+ALTER TABLE claudecontrol_test.processed_discord_messages
+DROP CONSTRAINT processed_discord_messages_status_check;
+ALTER TABLE claudecontrol_test.processed_discord_messages
+ADD CONSTRAINT processed_discord_messages_status_check
+CHECK (status IN ('QUEUED', 'IN_PROGRESS', 'COMPLETED', 'CANCELLED'));
@@ -0,0 +1,13 @@
+-- Add attachments - metadata of the files attached to the chat message, forwarded to the agent with the message
+ALTER TABLE claudecontrol.processed_slack_messages
+ADD COLUMN attachments JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+ALTER TABLE claudecontrol.processed_discord_messages
+ADD COLUMN attachments JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+-- Also add to test schema
+ALTER TABLE claudecontrol_test.processed_slack_messages
+ADD COLUMN attachments JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+ALTER TABLE claudecontrol_test.processed_discord_messages
+ADD COLUMN attachments JSONB NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,24 @@
+-- Create the inbox of message IDs recently received from agents - an agent resends a message it got
+-- no acknowledgement for, e.g. after a reconnect, and the resent copy is dropped instead of processed again
+CREATE TABLE claudecontrol.agent_inbox_messages (
+    organization_id TEXT NOT NULL,
+    agent_id TEXT NOT NULL,                        -- The agent's own ID, stable across reconnects
+    message_id TEXT NOT NULL,                      -- ID the agent generated for the message
+    message_type TEXT NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, agent_id, message_id)
+);
+
+CREATE INDEX idx_agent_inbox_messages_received_at ON claudecontrol.agent_inbox_messages(received_at);
+
+-- Create the same table for test schema
+CREATE TABLE claudecontrol_test.agent_inbox_messages (
+    organization_id TEXT NOT NULL,
+    agent_id TEXT NOT NULL,                        -- The agent's own ID, stable across reconnects
+    message_id TEXT NOT NULL,                      -- ID the agent generated for the message
+    message_type TEXT NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, agent_id, message_id)
+);
+
+CREATE INDEX idx_agent_inbox_messages_received_at_test ON claudecontrol_test.agent_inbox_messages(received_at);
@@ -0,0 +1,7 @@
+-- Count the messages of each agent which were rejected for failing payload validation
+ALTER TABLE claudecontrol.active_agents
+ADD COLUMN validation_failures INTEGER NOT NULL DEFAULT 0;
+
+-- Also add to test schema
+ALTER TABLE claudecontrol_test.active_agents
+ADD COLUMN validation_failures INTEGER NOT NULL DEFAULT 0;
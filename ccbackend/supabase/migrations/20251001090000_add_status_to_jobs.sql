@@ -0,0 +1,19 @@
+-- Keep completed jobs as history - closed jobs move to a terminal status instead of being deleted
+ALTER TABLE claudecontrol.jobs
+ADD COLUMN status TEXT NOT NULL DEFAULT 'ACTIVE'
+    CHECK (status IN ('ACTIVE', 'COMPLETED', 'ABANDONED', 'CANCELLED', 'FAILED')),
+ADD COLUMN completion_reason TEXT,
+ADD COLUMN completed_at TIMESTAMPTZ,
+ADD COLUMN assigned_agent_id TEXT;
+
+CREATE INDEX idx_jobs_organization_id_status ON claudecontrol.jobs(organization_id, status);
+
+-- Also add to test schema
+ALTER TABLE claudecontrol_test.jobs
+ADD COLUMN status TEXT NOT NULL DEFAULT 'ACTIVE'
+    CHECK (status IN ('ACTIVE', 'COMPLETED', 'ABANDONED', 'CANCELLED', 'FAILED')),
+ADD COLUMN completion_reason TEXT,
+ADD COLUMN completed_at TIMESTAMPTZ,
+ADD COLUMN assigned_agent_id TEXT;
+
+CREATE INDEX idx_jobs_organization_id_status_test ON claudecontrol_test.jobs(organization_id, status);
@@ -0,0 +1,33 @@
+-- Create the transcript of every job - the messages exchanged in the job's thread in both directions
+CREATE TABLE claudecontrol.job_transcript_messages (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    job_id TEXT NOT NULL REFERENCES claudecontrol.jobs(id) ON DELETE CASCADE,
+    author TEXT NOT NULL CHECK (author IN ('USER', 'AGENT', 'SYSTEM')),
+    author_id TEXT,                                -- Slack or Discord user ID, or agent ID
+    message_type TEXT NOT NULL
+        CHECK (message_type IN ('USER_MESSAGE', 'ASSISTANT_MESSAGE', 'SYSTEM_MESSAGE', 'ARTIFACT')),
+    content TEXT NOT NULL,
+    processed_message_id TEXT,                     -- Processed Slack or Discord message the message belongs to
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_job_transcript_messages_job_id
+    ON claudecontrol.job_transcript_messages(organization_id, job_id, created_at);
+
+-- Create the same table for test schema
+CREATE TABLE claudecontrol_test.job_transcript_messages (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    job_id TEXT NOT NULL REFERENCES claudecontrol_test.jobs(id) ON DELETE CASCADE,
+    author TEXT NOT NULL CHECK (author IN ('USER', 'AGENT', 'SYSTEM')),
+    author_id TEXT,                                -- Slack or Discord user ID, or agent ID
+    message_type TEXT NOT NULL
+        CHECK (message_type IN ('USER_MESSAGE', 'ASSISTANT_MESSAGE', 'SYSTEM_MESSAGE', 'ARTIFACT')),
+    content TEXT NOT NULL,
+    processed_message_id TEXT,                     -- Processed Slack or Discord message the message belongs to
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_job_transcript_messages_job_id_test
+    ON claudecontrol_test.job_transcript_messages(organization_id, job_id, created_at);
@@ -0,0 +1,44 @@
+-- Keep the history of agent assignments of every job - agent_job_assignments only holds the current ones
+-- History rows share the ID of the assignment they record. agent_id has no foreign key, so the history
+-- outlives disconnected agents.
+CREATE TABLE claudecontrol.job_assignment_history (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    job_id TEXT NOT NULL REFERENCES claudecontrol.jobs(id) ON DELETE CASCADE,
+    agent_id TEXT NOT NULL,
+    ccagent_id TEXT NOT NULL,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    unassigned_at TIMESTAMPTZ                      -- NULL while the agent still holds the job
+);
+
+CREATE INDEX idx_job_assignment_history_job_id
+    ON claudecontrol.job_assignment_history(organization_id, job_id, assigned_at);
+CREATE INDEX idx_job_assignment_history_agent_id
+    ON claudecontrol.job_assignment_history(organization_id, agent_id);
+
+-- Record the assignments which are currently held
+INSERT INTO claudecontrol.job_assignment_history (id, organization_id, job_id, agent_id, ccagent_id, assigned_at)
+SELECT aja.id, aja.organization_id, aja.job_id, aja.agent_id, aa.ccagent_id, aja.assigned_at
+FROM claudecontrol.agent_job_assignments aja
+JOIN claudecontrol.active_agents aa ON aa.id = aja.agent_id;
+
+-- Create the same table for test schema
+CREATE TABLE claudecontrol_test.job_assignment_history (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    job_id TEXT NOT NULL REFERENCES claudecontrol_test.jobs(id) ON DELETE CASCADE,
+    agent_id TEXT NOT NULL,
+    ccagent_id TEXT NOT NULL,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    unassigned_at TIMESTAMPTZ                      -- NULL while the agent still holds the job
+);
+
+CREATE INDEX idx_job_assignment_history_job_id_test
+    ON claudecontrol_test.job_assignment_history(organization_id, job_id, assigned_at);
+CREATE INDEX idx_job_assignment_history_agent_id_test
+    ON claudecontrol_test.job_assignment_history(organization_id, agent_id);
+
+INSERT INTO claudecontrol_test.job_assignment_history (id, organization_id, job_id, agent_id, ccagent_id, assigned_at)
+SELECT aja.id, aja.organization_id, aja.job_id, aja.agent_id, aa.ccagent_id, aja.assigned_at
+FROM claudecontrol_test.agent_job_assignments aja
+JOIN claudecontrol_test.active_agents aa ON aa.id = aja.agent_id;
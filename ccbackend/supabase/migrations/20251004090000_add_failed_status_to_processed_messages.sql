@@ -0,0 +1,26 @@
+-- Allow processed messages to fail, e.g. when their agent stopped responding and no other agent can take them over
+
+ALTER TABLE claudecontrol.processed_slack_messages
+DROP CONSTRAINT processed_slack_messages_status_check;
+ALTER TABLE claudecontrol.processed_slack_messages
+ADD CONSTRAINT processed_slack_messages_status_check
+CHECK (status IN ('QUEUED', 'IN_PROGRESS', 'COMPLETED', 'CANCELLED', 'FAILED'));
+
+ALTER TABLE claudecontrol.processed_discord_messages
+DROP CONSTRAINT processed_discord_messages_status_check;
+ALTER TABLE claudecontrol.processed_discord_messages
+ADD CONSTRAINT processed_discord_messages_status_check
+CHECK (status IN ('QUEUED', 'IN_PROGRESS', 'COMPLETED', 'CANCELLED', 'FAILED'));
+
+-- Same changes for test schema
+ALTER TABLE claudecontrol_test.processed_slack_messages
+DROP CONSTRAINT processed_slack_messages_status_check;
+ALTER TABLE claudecontrol_test.processed_slack_messages
+ADD CONSTRAINT processed_slack_messages_status_check
+CHECK (status IN ('QUEUED', 'IN_PROGRESS', 'COMPLETED', 'CANCELLED', 'FAILED'));
+
+ALTER TABLE claudecontrol_test.processed_discord_messages
+DROP CONSTRAINT processed_discord_messages_status_check;
+ALTER TABLE claudecontrol_test.processed_discord_messages
+ADD CONSTRAINT processed_discord_messages_status_check
+CHECK (status IN ('QUEUED', 'IN_PROGRESS', 'COMPLETED', 'CANCELLED', 'FAILED'));
@@ -0,0 +1,7 @@
+-- Track when an agent's connection dropped - any replica deregisters it once the reconnect grace period expires
+ALTER TABLE claudecontrol.active_agents
+ADD COLUMN disconnected_at TIMESTAMPTZ NULL;
+
+-- Also add to test schema
+ALTER TABLE claudecontrol_test.active_agents
+ADD COLUMN disconnected_at TIMESTAMPTZ NULL;
@@ -0,0 +1,7 @@
+-- Delivered and failed outbox messages are deleted once the retention period expires
+CREATE INDEX idx_agent_outbox_messages_finished
+    ON claudecontrol.agent_outbox_messages(updated_at) WHERE status <> 'PENDING';
+
+-- Also add to test schema
+CREATE INDEX idx_agent_outbox_messages_finished_test
+    ON claudecontrol_test.agent_outbox_messages(updated_at) WHERE status <> 'PENDING';
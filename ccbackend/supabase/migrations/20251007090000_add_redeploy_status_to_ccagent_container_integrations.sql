@@ -0,0 +1,13 @@
+-- Track a drained redeploy running in the background so its progress survives the request which started it
+ALTER TABLE claudecontrol.ccagent_container_integrations
+ADD COLUMN redeploy_status TEXT NULL CHECK (redeploy_status IN ('DRAINING', 'REDEPLOYING', 'COMPLETED', 'FAILED')),
+ADD COLUMN redeploy_error TEXT NULL,
+ADD COLUMN redeploy_started_at TIMESTAMPTZ NULL,
+ADD COLUMN redeploy_updated_at TIMESTAMPTZ NULL;
+
+-- Also add to test schema
+ALTER TABLE claudecontrol_test.ccagent_container_integrations
+ADD COLUMN redeploy_status TEXT NULL CHECK (redeploy_status IN ('DRAINING', 'REDEPLOYING', 'COMPLETED', 'FAILED')),
+ADD COLUMN redeploy_error TEXT NULL,
+ADD COLUMN redeploy_started_at TIMESTAMPTZ NULL,
+ADD COLUMN redeploy_updated_at TIMESTAMPTZ NULL;
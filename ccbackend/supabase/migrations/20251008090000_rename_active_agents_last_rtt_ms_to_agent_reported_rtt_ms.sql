@@ -0,0 +1,7 @@
+-- The ping round-trip time is measured and reported by the agent, the backend stores it as-is
+ALTER TABLE claudecontrol.active_agents
+RENAME COLUMN last_rtt_ms TO agent_reported_rtt_ms;
+
+-- Also rename in test schema
+ALTER TABLE claudecontrol_test.active_agents
+RENAME COLUMN last_rtt_ms TO agent_reported_rtt_ms;
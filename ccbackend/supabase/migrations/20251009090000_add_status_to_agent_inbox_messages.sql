@@ -0,0 +1,8 @@
+-- Track whether a message received from an agent was processed yet - a resent copy of a message which is still
+-- being processed is not acknowledged, so the agent keeps resending it until the original succeeds or fails
+ALTER TABLE claudecontrol.agent_inbox_messages
+ADD COLUMN status TEXT NOT NULL DEFAULT 'PROCESSED' CHECK (status IN ('PROCESSING', 'PROCESSED'));
+
+-- Also add to test schema
+ALTER TABLE claudecontrol_test.agent_inbox_messages
+ADD COLUMN status TEXT NOT NULL DEFAULT 'PROCESSED' CHECK (status IN ('PROCESSING', 'PROCESSED'));
@@ -2,6 +2,7 @@ package agents
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 
@@ -17,9 +18,11 @@ func (m *MockAgentsUseCase) GetOrAssignAgentForJob(
 	ctx context.Context,
 	job *models.Job,
 	threadTS string,
+	repoURL string,
+	agentSelector models.AgentLabels,
 	orgID models.OrgID,
 ) (string, error) {
-	args := m.Called(ctx, job, threadTS, orgID)
+	args := m.Called(ctx, job, threadTS, repoURL, agentSelector, orgID)
 	return args.String(0), args.Error(1)
 }
 
@@ -27,21 +30,36 @@ func (m *MockAgentsUseCase) AssignJobToAvailableAgent(
 	ctx context.Context,
 	job *models.Job,
 	threadTS string,
+	repoURL string,
+	agentSelector models.AgentLabels,
 	orgID models.OrgID,
 ) (string, error) {
-	args := m.Called(ctx, job, threadTS, orgID)
+	args := m.Called(ctx, job, threadTS, repoURL, agentSelector, orgID)
 	return args.String(0), args.Error(1)
 }
 
 func (m *MockAgentsUseCase) TryAssignJobToAgent(
 	ctx context.Context,
 	jobID string,
+	repoURL string,
+	agentSelector models.AgentLabels,
 	orgID models.OrgID,
 ) (string, bool, error) {
-	args := m.Called(ctx, jobID, orgID)
+	args := m.Called(ctx, jobID, repoURL, agentSelector, orgID)
 	return args.String(0), args.Bool(1), args.Error(2)
 }
 
+func (m *MockAgentsUseCase) HasAvailableAgent(
+	ctx context.Context,
+	repoURL string,
+	agentSelector models.AgentLabels,
+	orgID models.OrgID,
+	activeSince time.Time,
+) (bool, error) {
+	args := m.Called(ctx, repoURL, agentSelector, orgID, activeSince)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockAgentsUseCase) ValidateJobBelongsToAgent(
 	ctx context.Context,
 	agentID, jobID string,
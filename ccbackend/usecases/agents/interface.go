@@ -2,6 +2,7 @@ package agents
 
 import (
 	"context"
+	"time"
 
 	"ccbackend/models"
 )
@@ -13,10 +14,14 @@ type AgentsUseCaseInterface interface {
 		ctx context.Context,
 		job *models.Job,
 		threadTS string,
+		repoURL string,
+		agentSelector models.AgentLabels,
 		orgID models.OrgID,
 	) (string, error)
 
 	// TryAssignJobToAgent attempts to assign a job to the least loaded available agent
+	// When repoURL is non-empty, only agents working on that repository are considered
+	// When agentSelector is non-empty, only agents whose labels match every selector pair are considered
 	// Returns (clientID, wasAssigned, error) where:
 	// - clientID: WebSocket connection ID of assigned agent (empty if not assigned)
 	// - wasAssigned: true if job was successfully assigned to an agent, false if no agents available
@@ -24,9 +29,21 @@ type AgentsUseCaseInterface interface {
 	TryAssignJobToAgent(
 		ctx context.Context,
 		jobID string,
+		repoURL string,
+		agentSelector models.AgentLabels,
 		orgID models.OrgID,
 	) (string, bool, error)
 
+	// HasAvailableAgent returns true if a job routed to repoURL and agentSelector could be assigned right now
+	// Agents which have not been active since activeSince are not counted
+	HasAvailableAgent(
+		ctx context.Context,
+		repoURL string,
+		agentSelector models.AgentLabels,
+		orgID models.OrgID,
+		activeSince time.Time,
+	) (bool, error)
+
 	// ValidateJobBelongsToAgent checks if a job is assigned to the specified agent
 	ValidateJobBelongsToAgent(
 		ctx context.Context,
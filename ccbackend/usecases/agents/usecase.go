@@ -6,10 +6,13 @@ import (
 	"log"
 	"slices"
 	"sort"
+	"time"
 
 	"ccbackend/clients"
+	"ccbackend/core"
 	"ccbackend/models"
 	"ccbackend/services"
+	"ccbackend/utils"
 )
 
 // AgentsUseCase handles agent-job assignment logic
@@ -34,6 +37,8 @@ func (s *AgentsUseCase) GetOrAssignAgentForJob(
 	ctx context.Context,
 	job *models.Job,
 	threadTS string,
+	repoURL string,
+	agentSelector models.AgentLabels,
 	orgID models.OrgID,
 ) (string, error) {
 	// Check if this job is already assigned to an agent
@@ -46,7 +51,7 @@ func (s *AgentsUseCase) GetOrAssignAgentForJob(
 
 	if !maybeExistingAgent.IsPresent() {
 		// Job not assigned to any agent yet - need to assign to an available agent
-		return s.AssignJobToAvailableAgent(ctx, job, threadTS, orgID)
+		return s.AssignJobToAvailableAgent(ctx, job, threadTS, repoURL, agentSelector, orgID)
 	}
 
 	existingAgent := maybeExistingAgent.MustGet()
@@ -73,11 +78,13 @@ func (s *AgentsUseCase) AssignJobToAvailableAgent(
 	ctx context.Context,
 	job *models.Job,
 	threadTS string,
+	repoURL string,
+	agentSelector models.AgentLabels,
 	orgID models.OrgID,
 ) (string, error) {
 	log.Printf("📝 Job %s not yet assigned, looking for any active agent", job.ID)
 
-	clientID, assigned, err := s.TryAssignJobToAgent(ctx, job.ID, orgID)
+	clientID, assigned, err := s.TryAssignJobToAgent(ctx, job.ID, repoURL, agentSelector, orgID)
 	if err != nil {
 		return "", err
 	}
@@ -92,6 +99,8 @@ func (s *AgentsUseCase) AssignJobToAvailableAgent(
 }
 
 // TryAssignJobToAgent is a reusable function that attempts to assign a job to the least loaded available agent
+// When repoURL is non-empty, only agents working on that repository are considered
+// When agentSelector is non-empty, only agents whose labels match every selector pair are considered
 // Returns (clientID, wasAssigned, error) where:
 // - clientID: WebSocket connection ID of assigned agent (empty if not assigned)
 // - wasAssigned: true if job was successfully assigned to an agent, false if no agents available
@@ -99,6 +108,8 @@ func (s *AgentsUseCase) AssignJobToAvailableAgent(
 func (s *AgentsUseCase) TryAssignJobToAgent(
 	ctx context.Context,
 	jobID string,
+	repoURL string,
+	agentSelector models.AgentLabels,
 	orgID models.OrgID,
 ) (string, bool, error) {
 	// First check if this job is already assigned to an agent
@@ -121,6 +132,64 @@ func (s *AgentsUseCase) TryAssignJobToAgent(
 	}
 
 	// Job not assigned - proceed with assignment
+	availableAgents, err := s.findAvailableAgents(ctx, repoURL, agentSelector, orgID, time.Time{})
+	if err != nil {
+		return "", false, err
+	}
+	if len(availableAgents) == 0 {
+		return "", false, nil
+	}
+
+	// The loads were read without a lock - the assignment itself re-checks the agent's capacity, so concurrent
+	// assignments from this or other replicas can't oversubscribe it. An agent which filled up meanwhile is skipped.
+	for _, candidate := range availableAgents {
+		selectedAgent := candidate.agent
+		log.Printf("🎯 Selected agent %s with %d active jobs (least loaded)", selectedAgent.ID, candidate.load)
+
+		assigned, err := s.agentsService.AssignAgentToJobWithinCapacity(ctx, orgID, selectedAgent.ID, jobID)
+		if err != nil {
+			log.Printf("❌ Failed to assign job %s to agent %s: %v", jobID, selectedAgent.ID, err)
+			return "", false, fmt.Errorf("failed to assign job to agent: %w", err)
+		}
+		if !assigned {
+			log.Printf("⏭️ Agent %s reached its capacity before job %s could be assigned", selectedAgent.ID, jobID)
+			continue
+		}
+
+		log.Printf("✅ Assigned job %s to agent %s", jobID, selectedAgent.ID)
+		return selectedAgent.WSConnectionID, true, nil
+	}
+
+	log.Printf("⚠️ All available agents reached their capacity before job %s could be assigned", jobID)
+	return "", false, nil
+}
+
+// HasAvailableAgent returns true if a job routed to repoURL and agentSelector could be assigned to an agent right now
+// It applies the same filters as TryAssignJobToAgent without assigning anything. Agents which have not been active
+// since activeSince are considered unresponsive and are not counted.
+func (s *AgentsUseCase) HasAvailableAgent(
+	ctx context.Context,
+	repoURL string,
+	agentSelector models.AgentLabels,
+	orgID models.OrgID,
+	activeSince time.Time,
+) (bool, error) {
+	availableAgents, err := s.findAvailableAgents(ctx, repoURL, agentSelector, orgID, activeSince)
+	if err != nil {
+		return false, err
+	}
+	return len(availableAgents) > 0, nil
+}
+
+// findAvailableAgents returns the connected agents which can take a new job routed to repoURL and agentSelector,
+// least loaded first. A zero activeSince does not filter agents by their last activity.
+func (s *AgentsUseCase) findAvailableAgents(
+	ctx context.Context,
+	repoURL string,
+	agentSelector models.AgentLabels,
+	orgID models.OrgID,
+	activeSince time.Time,
+) ([]agentWithLoad, error) {
 	// Get active WebSocket connections first
 	connectedClientIDs := s.wsClient.GetClientIDs()
 	log.Printf("🔍 Found %d connected WebSocket clients", len(connectedClientIDs))
@@ -129,32 +198,63 @@ func (s *AgentsUseCase) TryAssignJobToAgent(
 	connectedAgents, err := s.agentsService.GetConnectedActiveAgents(ctx, orgID, connectedClientIDs)
 	if err != nil {
 		log.Printf("❌ Failed to get connected active agents: %v", err)
-		return "", false, fmt.Errorf("failed to get connected active agents: %w", err)
+		return nil, fmt.Errorf("failed to get connected active agents: %w", err)
 	}
 
 	if len(connectedAgents) == 0 {
 		log.Printf("⚠️ No agents have active WebSocket connections")
-		return "", false, nil
+		return nil, nil
+	}
+
+	// Agents which stopped pinging would not pick the job up
+	if !activeSince.IsZero() {
+		connectedAgents = filterUnresponsiveAgents(connectedAgents, activeSince)
+		if len(connectedAgents) == 0 {
+			log.Printf("⚠️ No connected agents have been active since %s", activeSince.Format(time.RFC3339))
+			return nil, nil
+		}
+	}
+
+	// Draining agents finish the jobs they already have but must not be given new ones
+	connectedAgents = filterDrainingAgents(connectedAgents)
+	if len(connectedAgents) == 0 {
+		log.Printf("⚠️ All connected agents are draining")
+		return nil, nil
+	}
+
+	// Only consider agents working on the job's repository (if the channel has one configured)
+	if repoURL != "" {
+		connectedAgents = filterAgentsByRepo(connectedAgents, repoURL)
+		if len(connectedAgents) == 0 {
+			log.Printf("⚠️ No connected agents are working on repository %s", repoURL)
+			return nil, nil
+		}
+	}
+
+	// Only consider agents whose labels satisfy the channel's agent selector (if it has one)
+	if len(agentSelector) > 0 {
+		connectedAgents = filterAgentsBySelector(connectedAgents, agentSelector)
+		if len(connectedAgents) == 0 {
+			log.Printf("⚠️ No connected agents match agent selector %s", agentSelector)
+			return nil, nil
+		}
 	}
 
 	// Sort agents by load (number of assigned jobs) to select the least loaded agent
 	sortedAgents, err := s.sortAgentsByLoad(ctx, connectedAgents, orgID)
 	if err != nil {
 		log.Printf("❌ Failed to sort agents by load: %v", err)
-		return "", false, fmt.Errorf("failed to sort agents by load: %w", err)
+		return nil, fmt.Errorf("failed to sort agents by load: %w", err)
 	}
 
-	selectedAgent := sortedAgents[0].agent
-	log.Printf("🎯 Selected agent %s with %d active jobs (least loaded)", selectedAgent.ID, sortedAgents[0].load)
-
-	// Assign the job to the selected agent (agents can now handle multiple jobs simultaneously)
-	if err := s.agentsService.AssignAgentToJob(ctx, orgID, selectedAgent.ID, jobID); err != nil {
-		log.Printf("❌ Failed to assign job %s to agent %s: %v", jobID, selectedAgent.ID, err)
-		return "", false, fmt.Errorf("failed to assign job to agent: %w", err)
+	// Skip agents which have reached their declared concurrency limit
+	availableAgents := filterSaturatedAgents(sortedAgents)
+	if len(availableAgents) == 0 {
+		log.Printf("⚠️ All %d connected agents are at capacity", len(sortedAgents))
+		return nil, nil
 	}
 
-	log.Printf("✅ Assigned job %s to agent %s", jobID, selectedAgent.ID)
-	return selectedAgent.WSConnectionID, true, nil
+	return availableAgents, nil
 }
 
 // ValidateJobBelongsToAgent checks if a job is assigned to the specified agent
@@ -173,7 +273,7 @@ func (s *AgentsUseCase) ValidateJobBelongsToAgent(
 	}
 
 	log.Printf("❌ Agent %s is not assigned to job %s", agentID, jobID)
-	return fmt.Errorf("agent %s is not assigned to job %s", agentID, jobID)
+	return fmt.Errorf("agent %s is not assigned to job %s: %w", agentID, jobID, core.ErrJobNotAssigned)
 }
 
 type agentWithLoad struct {
@@ -208,3 +308,70 @@ func (s *AgentsUseCase) sortAgentsByLoad(
 
 	return agentsWithLoad, nil
 }
+
+// filterSaturatedAgents returns only the agents which can accept another job, preserving order
+// Agents with MaxConcurrency of 0 have not declared a limit and are never saturated
+func filterSaturatedAgents(agents []agentWithLoad) []agentWithLoad {
+	var available []agentWithLoad
+	for _, agentLoad := range agents {
+		if agentLoad.agent.MaxConcurrency > 0 && agentLoad.load >= agentLoad.agent.MaxConcurrency {
+			log.Printf(
+				"⏭️ Skipping agent %s - at capacity (%d/%d jobs)",
+				agentLoad.agent.ID,
+				agentLoad.load,
+				agentLoad.agent.MaxConcurrency,
+			)
+			continue
+		}
+		available = append(available, agentLoad)
+	}
+	return available
+}
+
+// filterAgentsByRepo returns only the agents whose repository matches the given repository URL
+func filterAgentsByRepo(agents []*models.ActiveAgent, repoURL string) []*models.ActiveAgent {
+	var matchingAgents []*models.ActiveAgent
+	for _, agent := range agents {
+		if utils.RepoURLsMatch(agent.RepoURL, repoURL) {
+			matchingAgents = append(matchingAgents, agent)
+		}
+	}
+	return matchingAgents
+}
+
+// filterDrainingAgents returns only the agents which accept new jobs
+func filterDrainingAgents(agents []*models.ActiveAgent) []*models.ActiveAgent {
+	var acceptingAgents []*models.ActiveAgent
+	for _, agent := range agents {
+		if agent.Draining {
+			log.Printf("⏭️ Skipping agent %s - draining", agent.ID)
+			continue
+		}
+		acceptingAgents = append(acceptingAgents, agent)
+	}
+	return acceptingAgents
+}
+
+// filterUnresponsiveAgents returns only the agents which have been active since the given time
+func filterUnresponsiveAgents(agents []*models.ActiveAgent, activeSince time.Time) []*models.ActiveAgent {
+	var responsiveAgents []*models.ActiveAgent
+	for _, agent := range agents {
+		if agent.LastActiveAt.Before(activeSince) {
+			log.Printf("⏭️ Skipping agent %s - last active at %s", agent.ID, agent.LastActiveAt.Format(time.RFC3339))
+			continue
+		}
+		responsiveAgents = append(responsiveAgents, agent)
+	}
+	return responsiveAgents
+}
+
+// filterAgentsBySelector returns only the agents whose labels contain every key/value pair of the selector
+func filterAgentsBySelector(agents []*models.ActiveAgent, agentSelector models.AgentLabels) []*models.ActiveAgent {
+	var matchingAgents []*models.ActiveAgent
+	for _, agent := range agents {
+		if agent.Labels.Matches(agentSelector) {
+			matchingAgents = append(matchingAgents, agent)
+		}
+	}
+	return matchingAgents
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/samber/mo"
 	"github.com/stretchr/testify/assert"
@@ -71,7 +72,7 @@ func TestGetOrAssignAgentForJob(t *testing.T) {
 			Return(true)
 
 		useCase := NewAgentsUseCase(mockWS, mockAgents)
-		clientID, err := useCase.GetOrAssignAgentForJob(ctx, job, threadTS, orgID)
+		clientID, err := useCase.GetOrAssignAgentForJob(ctx, job, threadTS, "", nil, orgID)
 
 		assert.NoError(t, err)
 		assert.Equal(t, "ws_conn_123", clientID)
@@ -93,7 +94,7 @@ func TestGetOrAssignAgentForJob(t *testing.T) {
 			Return(false)
 
 		useCase := NewAgentsUseCase(mockWS, mockAgents)
-		clientID, err := useCase.GetOrAssignAgentForJob(ctx, job, threadTS, orgID)
+		clientID, err := useCase.GetOrAssignAgentForJob(ctx, job, threadTS, "", nil, orgID)
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "no active agents available")
@@ -120,11 +121,11 @@ func TestGetOrAssignAgentForJob(t *testing.T) {
 			Return([]*models.ActiveAgent{agent}, nil)
 		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, agent.ID).
 			Return([]string{}, nil)
-		mockAgents.On("AssignAgentToJob", ctx, orgID, agent.ID, job.ID).
-			Return(nil)
+		mockAgents.On("AssignAgentToJobWithinCapacity", ctx, orgID, agent.ID, job.ID).
+			Return(true, nil)
 
 		useCase := NewAgentsUseCase(mockWS, mockAgents)
-		clientID, err := useCase.GetOrAssignAgentForJob(ctx, job, threadTS, orgID)
+		clientID, err := useCase.GetOrAssignAgentForJob(ctx, job, threadTS, "", nil, orgID)
 
 		assert.NoError(t, err)
 		assert.Equal(t, "ws_conn_123", clientID)
@@ -154,11 +155,11 @@ func TestAssignJobToAvailableAgent(t *testing.T) {
 			Return([]*models.ActiveAgent{agent}, nil)
 		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, agent.ID).
 			Return([]string{}, nil)
-		mockAgents.On("AssignAgentToJob", ctx, orgID, agent.ID, job.ID).
-			Return(nil)
+		mockAgents.On("AssignAgentToJobWithinCapacity", ctx, orgID, agent.ID, job.ID).
+			Return(true, nil)
 
 		useCase := NewAgentsUseCase(mockWS, mockAgents)
-		clientID, err := useCase.AssignJobToAvailableAgent(ctx, job, threadTS, orgID)
+		clientID, err := useCase.AssignJobToAvailableAgent(ctx, job, threadTS, "", nil, orgID)
 
 		assert.NoError(t, err)
 		assert.Equal(t, "ws_conn_123", clientID)
@@ -178,7 +179,7 @@ func TestAssignJobToAvailableAgent(t *testing.T) {
 			Return([]*models.ActiveAgent{}, nil)
 
 		useCase := NewAgentsUseCase(mockWS, mockAgents)
-		clientID, err := useCase.AssignJobToAvailableAgent(ctx, job, threadTS, orgID)
+		clientID, err := useCase.AssignJobToAvailableAgent(ctx, job, threadTS, "", nil, orgID)
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "no agents with active WebSocket connections")
@@ -208,7 +209,7 @@ func TestTryAssignJobToAgent(t *testing.T) {
 			Return(true)
 
 		useCase := NewAgentsUseCase(mockWS, mockAgents)
-		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, orgID)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, "", nil, orgID)
 
 		assert.NoError(t, err)
 		assert.True(t, wasAssigned)
@@ -231,7 +232,7 @@ func TestTryAssignJobToAgent(t *testing.T) {
 			Return(false)
 
 		useCase := NewAgentsUseCase(mockWS, mockAgents)
-		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, orgID)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, "", nil, orgID)
 
 		assert.NoError(t, err)
 		assert.False(t, wasAssigned)
@@ -258,11 +259,11 @@ func TestTryAssignJobToAgent(t *testing.T) {
 			Return([]string{"job_a", "job_b"}, nil)
 		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, agent2.ID).
 			Return([]string{"job_c"}, nil)
-		mockAgents.On("AssignAgentToJob", ctx, orgID, agent2.ID, jobID).
-			Return(nil)
+		mockAgents.On("AssignAgentToJobWithinCapacity", ctx, orgID, agent2.ID, jobID).
+			Return(true, nil)
 
 		useCase := NewAgentsUseCase(mockWS, mockAgents)
-		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, orgID)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, "", nil, orgID)
 
 		assert.NoError(t, err)
 		assert.True(t, wasAssigned)
@@ -271,6 +272,65 @@ func TestTryAssignJobToAgent(t *testing.T) {
 		mockAgents.AssertExpectations(t)
 	})
 
+	t.Run("Agent filled up concurrently falls back to the next least loaded agent", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		agent1 := createTestAgent("agent_1", "ws_conn_1", orgID)
+		agent2 := createTestAgent("agent_2", "ws_conn_2", orgID)
+
+		// Setup expectations - agent 2 is least loaded but took its last job in the meantime
+		mockAgents.On("GetAgentByJobID", ctx, orgID, jobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1", "ws_conn_2"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1", "ws_conn_2"}).
+			Return([]*models.ActiveAgent{agent1, agent2}, nil)
+		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, agent1.ID).
+			Return([]string{"job_a", "job_b"}, nil)
+		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, agent2.ID).
+			Return([]string{"job_c"}, nil)
+		mockAgents.On("AssignAgentToJobWithinCapacity", ctx, orgID, agent2.ID, jobID).
+			Return(false, nil)
+		mockAgents.On("AssignAgentToJobWithinCapacity", ctx, orgID, agent1.ID, jobID).
+			Return(true, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, "", nil, orgID)
+
+		assert.NoError(t, err)
+		assert.True(t, wasAssigned)
+		assert.Equal(t, "ws_conn_1", clientID)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
+	})
+
+	t.Run("Job stays unassigned when every agent filled up concurrently", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		agent := createTestAgent("agent_1", "ws_conn_1", orgID)
+
+		// Setup expectations
+		mockAgents.On("GetAgentByJobID", ctx, orgID, jobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1"}).
+			Return([]*models.ActiveAgent{agent}, nil)
+		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, agent.ID).
+			Return([]string{}, nil)
+		mockAgents.On("AssignAgentToJobWithinCapacity", ctx, orgID, agent.ID, jobID).
+			Return(false, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, "", nil, orgID)
+
+		assert.NoError(t, err)
+		assert.False(t, wasAssigned)
+		assert.Empty(t, clientID)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
+	})
+
 	t.Run("No connected agents", func(t *testing.T) {
 		mockWS := &socketio.MockSocketIOClient{}
 		mockAgents := &agents.MockAgentsService{}
@@ -283,13 +343,331 @@ func TestTryAssignJobToAgent(t *testing.T) {
 			Return([]*models.ActiveAgent{}, nil)
 
 		useCase := NewAgentsUseCase(mockWS, mockAgents)
-		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, orgID)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, "", nil, orgID)
+
+		assert.NoError(t, err)
+		assert.False(t, wasAssigned)
+		assert.Empty(t, clientID)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
+	})
+	t.Run("Only agents on the job's repository are considered", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		frontendAgent := createTestAgent("agent_1", "ws_conn_1", orgID)
+		frontendAgent.RepoURL = "github.com/acme/frontend"
+		backendAgent := createTestAgent("agent_2", "ws_conn_2", orgID)
+		backendAgent.RepoURL = "github.com/acme/backend"
+
+		// Setup expectations - frontend agent is idle but must not be picked
+		mockAgents.On("GetAgentByJobID", ctx, orgID, jobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1", "ws_conn_2"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1", "ws_conn_2"}).
+			Return([]*models.ActiveAgent{frontendAgent, backendAgent}, nil)
+		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, backendAgent.ID).
+			Return([]string{"job_a", "job_b"}, nil)
+		mockAgents.On("AssignAgentToJobWithinCapacity", ctx, orgID, backendAgent.ID, jobID).
+			Return(true, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, "https://github.com/acme/backend.git", nil, orgID)
+
+		assert.NoError(t, err)
+		assert.True(t, wasAssigned)
+		assert.Equal(t, "ws_conn_2", clientID)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
+	})
+
+	t.Run("No connected agents on the job's repository", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		frontendAgent := createTestAgent("agent_1", "ws_conn_1", orgID)
+		frontendAgent.RepoURL = "github.com/acme/frontend"
+
+		// Setup expectations
+		mockAgents.On("GetAgentByJobID", ctx, orgID, jobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1"}).
+			Return([]*models.ActiveAgent{frontendAgent}, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, "github.com/acme/backend", nil, orgID)
 
 		assert.NoError(t, err)
 		assert.False(t, wasAssigned)
 		assert.Empty(t, clientID)
 		mockWS.AssertExpectations(t)
 		mockAgents.AssertExpectations(t)
+		mockAgents.AssertNotCalled(t, "AssignAgentToJobWithinCapacity", ctx, orgID, frontendAgent.ID, jobID)
+	})
+	t.Run("Only agents matching the agent selector are considered", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		cpuAgent := createTestAgent("agent_1", "ws_conn_1", orgID)
+		cpuAgent.Labels = models.AgentLabels{"env": "prod"}
+		gpuAgent := createTestAgent("agent_2", "ws_conn_2", orgID)
+		gpuAgent.Labels = models.AgentLabels{"env": "prod", "gpu": "true"}
+
+		// Setup expectations - cpu agent is idle but lacks the gpu label
+		mockAgents.On("GetAgentByJobID", ctx, orgID, jobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1", "ws_conn_2"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1", "ws_conn_2"}).
+			Return([]*models.ActiveAgent{cpuAgent, gpuAgent}, nil)
+		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, gpuAgent.ID).
+			Return([]string{"job_a"}, nil)
+		mockAgents.On("AssignAgentToJobWithinCapacity", ctx, orgID, gpuAgent.ID, jobID).
+			Return(true, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(
+			ctx,
+			jobID,
+			"",
+			models.AgentLabels{"gpu": "true"},
+			orgID,
+		)
+
+		assert.NoError(t, err)
+		assert.True(t, wasAssigned)
+		assert.Equal(t, "ws_conn_2", clientID)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
+	})
+
+	t.Run("No connected agents match the agent selector", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		agent := createTestAgent("agent_1", "ws_conn_1", orgID)
+		agent.Labels = models.AgentLabels{"env": "staging"}
+
+		// Setup expectations
+		mockAgents.On("GetAgentByJobID", ctx, orgID, jobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1"}).
+			Return([]*models.ActiveAgent{agent}, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(
+			ctx,
+			jobID,
+			"",
+			models.AgentLabels{"env": "prod"},
+			orgID,
+		)
+
+		assert.NoError(t, err)
+		assert.False(t, wasAssigned)
+		assert.Empty(t, clientID)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
+		mockAgents.AssertNotCalled(t, "AssignAgentToJobWithinCapacity", ctx, orgID, agent.ID, jobID)
+	})
+	t.Run("Draining agents are skipped", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		drainingAgent := createTestAgent("agent_1", "ws_conn_1", orgID)
+		drainingAgent.Draining = true
+		acceptingAgent := createTestAgent("agent_2", "ws_conn_2", orgID)
+
+		// Setup expectations - draining agent is idle but must not be picked
+		mockAgents.On("GetAgentByJobID", ctx, orgID, jobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1", "ws_conn_2"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1", "ws_conn_2"}).
+			Return([]*models.ActiveAgent{drainingAgent, acceptingAgent}, nil)
+		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, acceptingAgent.ID).
+			Return([]string{"job_a", "job_b"}, nil)
+		mockAgents.On("AssignAgentToJobWithinCapacity", ctx, orgID, acceptingAgent.ID, jobID).
+			Return(true, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, "", nil, orgID)
+
+		assert.NoError(t, err)
+		assert.True(t, wasAssigned)
+		assert.Equal(t, "ws_conn_2", clientID)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
+	})
+
+	t.Run("All connected agents draining", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		agent := createTestAgent("agent_1", "ws_conn_1", orgID)
+		agent.Draining = true
+
+		// Setup expectations
+		mockAgents.On("GetAgentByJobID", ctx, orgID, jobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1"}).
+			Return([]*models.ActiveAgent{agent}, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, "", nil, orgID)
+
+		assert.NoError(t, err)
+		assert.False(t, wasAssigned)
+		assert.Empty(t, clientID)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
+		mockAgents.AssertNotCalled(t, "AssignAgentToJobWithinCapacity", ctx, orgID, agent.ID, jobID)
+	})
+
+	t.Run("Saturated agents are skipped", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		busyAgent := createTestAgent("agent_1", "ws_conn_1", orgID)
+		busyAgent.MaxConcurrency = 1
+		unlimitedAgent := createTestAgent("agent_2", "ws_conn_2", orgID)
+
+		// Setup expectations - agent 1 is least loaded but already at its limit
+		mockAgents.On("GetAgentByJobID", ctx, orgID, jobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1", "ws_conn_2"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1", "ws_conn_2"}).
+			Return([]*models.ActiveAgent{busyAgent, unlimitedAgent}, nil)
+		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, busyAgent.ID).
+			Return([]string{"job_a"}, nil)
+		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, unlimitedAgent.ID).
+			Return([]string{"job_b", "job_c"}, nil)
+		mockAgents.On("AssignAgentToJobWithinCapacity", ctx, orgID, unlimitedAgent.ID, jobID).
+			Return(true, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, "", nil, orgID)
+
+		assert.NoError(t, err)
+		assert.True(t, wasAssigned)
+		assert.Equal(t, "ws_conn_2", clientID)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
+	})
+
+	t.Run("All agents at capacity", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		agent := createTestAgent("agent_1", "ws_conn_1", orgID)
+		agent.MaxConcurrency = 2
+
+		// Setup expectations
+		mockAgents.On("GetAgentByJobID", ctx, orgID, jobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1"}).
+			Return([]*models.ActiveAgent{agent}, nil)
+		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, agent.ID).
+			Return([]string{"job_a", "job_b"}, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		clientID, wasAssigned, err := useCase.TryAssignJobToAgent(ctx, jobID, "", nil, orgID)
+
+		assert.NoError(t, err)
+		assert.False(t, wasAssigned)
+		assert.Empty(t, clientID)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
+		mockAgents.AssertNotCalled(t, "AssignAgentToJobWithinCapacity", ctx, orgID, agent.ID, jobID)
+	})
+}
+
+// HasAvailableAgent Tests
+func TestHasAvailableAgent(t *testing.T) {
+	ctx := context.Background()
+	orgID := models.OrgID("org_test123")
+	repoURL := "github.com/acme/backend"
+	activeSince := time.Now().Add(-30 * time.Minute)
+
+	t.Run("Agent on the repository with room for the job", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		otherRepoAgent := createTestAgent("agent_1", "ws_conn_1", orgID)
+		otherRepoAgent.RepoURL = "github.com/acme/frontend"
+		otherRepoAgent.LastActiveAt = time.Now()
+		matchingAgent := createTestAgent("agent_2", "ws_conn_2", orgID)
+		matchingAgent.RepoURL = repoURL
+		matchingAgent.LastActiveAt = time.Now()
+
+		// Setup expectations
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1", "ws_conn_2"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1", "ws_conn_2"}).
+			Return([]*models.ActiveAgent{otherRepoAgent, matchingAgent}, nil)
+		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, matchingAgent.ID).
+			Return([]string{}, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		hasAgent, err := useCase.HasAvailableAgent(ctx, repoURL, nil, orgID, activeSince)
+
+		assert.NoError(t, err)
+		assert.True(t, hasAgent)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
+		mockAgents.AssertNotCalled(t, "AssignAgentToJobWithinCapacity")
+	})
+
+	t.Run("Unresponsive and saturated agents are not counted", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		unresponsiveAgent := createTestAgent("agent_1", "ws_conn_1", orgID)
+		unresponsiveAgent.RepoURL = repoURL
+		unresponsiveAgent.LastActiveAt = time.Now().Add(-time.Hour)
+		busyAgent := createTestAgent("agent_2", "ws_conn_2", orgID)
+		busyAgent.RepoURL = repoURL
+		busyAgent.LastActiveAt = time.Now()
+		busyAgent.MaxConcurrency = 1
+
+		// Setup expectations
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1", "ws_conn_2"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1", "ws_conn_2"}).
+			Return([]*models.ActiveAgent{unresponsiveAgent, busyAgent}, nil)
+		mockAgents.On("GetActiveAgentJobAssignments", ctx, orgID, busyAgent.ID).
+			Return([]string{"job_a"}, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		hasAgent, err := useCase.HasAvailableAgent(ctx, repoURL, nil, orgID, activeSince)
+
+		assert.NoError(t, err)
+		assert.False(t, hasAgent)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
+		mockAgents.AssertNotCalled(t, "GetActiveAgentJobAssignments", ctx, orgID, unresponsiveAgent.ID)
+	})
+
+	t.Run("Agents not matching the agent selector are not counted", func(t *testing.T) {
+		mockWS := &socketio.MockSocketIOClient{}
+		mockAgents := &agents.MockAgentsService{}
+
+		agent := createTestAgent("agent_1", "ws_conn_1", orgID)
+		agent.LastActiveAt = time.Now()
+		agent.Labels = models.AgentLabels{"gpu": "false"}
+
+		// Setup expectations
+		mockWS.On("GetClientIDs").Return([]string{"ws_conn_1"})
+		mockAgents.On("GetConnectedActiveAgents", ctx, orgID, []string{"ws_conn_1"}).
+			Return([]*models.ActiveAgent{agent}, nil)
+
+		useCase := NewAgentsUseCase(mockWS, mockAgents)
+		hasAgent, err := useCase.HasAvailableAgent(ctx, "", models.AgentLabels{"gpu": "true"}, orgID, activeSince)
+
+		assert.NoError(t, err)
+		assert.False(t, hasAgent)
+		mockWS.AssertExpectations(t)
+		mockAgents.AssertExpectations(t)
 	})
 }
 
@@ -2,8 +2,12 @@ package core
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"ccbackend/clients"
 	"ccbackend/core"
@@ -20,6 +24,7 @@ type CoreUseCase struct {
 	jobsService              services.JobsService
 	slackIntegrationsService services.SlackIntegrationsService
 	organizationsService     services.OrganizationsService
+	settingsService          services.SettingsService
 
 	// Use case dependencies
 	slackUseCase   usecases.SlackUseCaseInterface
@@ -33,6 +38,7 @@ func NewCoreUseCase(
 	jobsService services.JobsService,
 	slackIntegrationsService services.SlackIntegrationsService,
 	organizationsService services.OrganizationsService,
+	settingsService services.SettingsService,
 	slackUseCase usecases.SlackUseCaseInterface,
 	discordUseCase usecases.DiscordUseCaseInterface,
 ) *CoreUseCase {
@@ -42,6 +48,7 @@ func NewCoreUseCase(
 		jobsService:              jobsService,
 		slackIntegrationsService: slackIntegrationsService,
 		organizationsService:     organizationsService,
+		settingsService:          settingsService,
 		slackUseCase:             slackUseCase,
 		discordUseCase:           discordUseCase,
 	}
@@ -92,8 +99,8 @@ func (s *CoreUseCase) ProcessProcessingMessage(
 		return fmt.Errorf("failed to get job: %w", err)
 	}
 	if !maybeJob.IsPresent() {
-		log.Printf("⚠️ Job %s not found - already completed, skipping processing message", jobID)
-		return nil
+		log.Printf("⚠️ Job %s not found - already completed, rejecting processing message", jobID)
+		return fmt.Errorf("%w: %s", core.ErrJobNotFound, jobID)
 	}
 
 	job := maybeJob.MustGet()
@@ -121,7 +128,7 @@ func (s *CoreUseCase) ProcessAssistantMessage(
 	// Get the job to determine the type
 	jobID := payload.JobID
 	if jobID == "" {
-		return fmt.Errorf("JobID is empty in AssistantMessage payload")
+		return fmt.Errorf("%w: JobID is empty in AssistantMessage payload", core.ErrInvalidPayload)
 	}
 
 	// Get job to determine the platform
@@ -130,8 +137,8 @@ func (s *CoreUseCase) ProcessAssistantMessage(
 		return fmt.Errorf("failed to get job: %w", err)
 	}
 	if !maybeJob.IsPresent() {
-		log.Printf("⚠️ Job %s not found - already completed, skipping assistant message", jobID)
-		return nil
+		log.Printf("⚠️ Job %s not found - already completed, rejecting assistant message", jobID)
+		return fmt.Errorf("%w: %s", core.ErrJobNotFound, jobID)
 	}
 
 	job := maybeJob.MustGet()
@@ -149,6 +156,122 @@ func (s *CoreUseCase) ProcessAssistantMessage(
 	}
 }
 
+// ProcessAssistantDelta routes to appropriate usecase based on job type
+func (s *CoreUseCase) ProcessAssistantDelta(
+	ctx context.Context,
+	clientID string,
+	payload models.AssistantDeltaPayload,
+	orgID models.OrgID,
+) error {
+	jobID := payload.JobID
+	if jobID == "" {
+		return fmt.Errorf("%w: JobID is empty in AssistantDelta payload", core.ErrInvalidPayload)
+	}
+
+	// Get job to determine the platform
+	maybeJob, err := s.jobsService.GetJobByID(ctx, orgID, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+	if !maybeJob.IsPresent() {
+		log.Printf("⚠️ Job %s not found - already completed, rejecting assistant delta", jobID)
+		return fmt.Errorf("%w: %s", core.ErrJobNotFound, jobID)
+	}
+
+	job := maybeJob.MustGet()
+	switch job.JobType {
+	case models.JobTypeSlack:
+		return s.slackUseCase.ProcessAssistantDelta(ctx, clientID, payload, orgID)
+	case models.JobTypeDiscord:
+		return s.discordUseCase.ProcessAssistantDelta(ctx, clientID, payload, orgID)
+	default:
+		return fmt.Errorf("unsupported job type: %s", job.JobType)
+	}
+}
+
+// ProcessProgressEvent routes to appropriate usecase based on job type
+func (s *CoreUseCase) ProcessProgressEvent(
+	ctx context.Context,
+	clientID string,
+	payload models.ProgressEventPayload,
+	orgID models.OrgID,
+) error {
+	jobID := payload.JobID
+	if jobID == "" {
+		return fmt.Errorf("%w: JobID is empty in ProgressEvent payload", core.ErrInvalidPayload)
+	}
+	if strings.TrimSpace(payload.Title) == "" {
+		return fmt.Errorf("%w: Title is empty in ProgressEvent payload", core.ErrInvalidPayload)
+	}
+
+	// Get job to determine the platform
+	maybeJob, err := s.jobsService.GetJobByID(ctx, orgID, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+	if !maybeJob.IsPresent() {
+		log.Printf("⚠️ Job %s not found - already completed, rejecting progress event", jobID)
+		return fmt.Errorf("%w: %s", core.ErrJobNotFound, jobID)
+	}
+
+	job := maybeJob.MustGet()
+	switch job.JobType {
+	case models.JobTypeSlack:
+		return s.slackUseCase.ProcessProgressEvent(ctx, clientID, payload, orgID)
+	case models.JobTypeDiscord:
+		return s.discordUseCase.ProcessProgressEvent(ctx, clientID, payload, orgID)
+	default:
+		return fmt.Errorf("unsupported job type: %s", job.JobType)
+	}
+}
+
+// ProcessArtifact routes to appropriate usecase based on job type
+func (s *CoreUseCase) ProcessArtifact(
+	ctx context.Context,
+	clientID string,
+	payload models.ArtifactPayload,
+	orgID models.OrgID,
+) error {
+	jobID := payload.JobID
+	if jobID == "" {
+		return fmt.Errorf("%w: JobID is empty in Artifact payload", core.ErrInvalidPayload)
+	}
+	if strings.TrimSpace(payload.Attachment.Name) == "" {
+		return fmt.Errorf("%w: Attachment name is empty in Artifact payload", core.ErrInvalidPayload)
+	}
+	if len(payload.Attachment.Content) == 0 {
+		return fmt.Errorf("%w: Attachment content is empty in Artifact payload", core.ErrInvalidPayload)
+	}
+	if len(payload.Attachment.Content) > models.MaxAttachmentSize {
+		return fmt.Errorf(
+			"%w: Attachment in Artifact payload is %d bytes, larger than the %d bytes limit",
+			core.ErrInvalidPayload,
+			len(payload.Attachment.Content),
+			models.MaxAttachmentSize,
+		)
+	}
+
+	// Get job to determine the platform
+	maybeJob, err := s.jobsService.GetJobByID(ctx, orgID, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+	if !maybeJob.IsPresent() {
+		log.Printf("⚠️ Job %s not found - already completed, rejecting artifact", jobID)
+		return fmt.Errorf("%w: %s", core.ErrJobNotFound, jobID)
+	}
+
+	job := maybeJob.MustGet()
+	switch job.JobType {
+	case models.JobTypeSlack:
+		return s.slackUseCase.ProcessArtifact(ctx, clientID, payload, orgID)
+	case models.JobTypeDiscord:
+		return s.discordUseCase.ProcessArtifact(ctx, clientID, payload, orgID)
+	default:
+		return fmt.Errorf("unsupported job type: %s", job.JobType)
+	}
+}
+
 // ProcessSystemMessage routes to appropriate usecase based on job type
 func (s *CoreUseCase) ProcessSystemMessage(
 	ctx context.Context,
@@ -161,7 +284,7 @@ func (s *CoreUseCase) ProcessSystemMessage(
 	// Get the job ID from the payload to determine the type
 	jobID := payload.JobID
 	if jobID == "" {
-		return fmt.Errorf("JobID is empty in SystemMessage payload")
+		return fmt.Errorf("%w: JobID is empty in SystemMessage payload", core.ErrInvalidPayload)
 	}
 
 	// Get job to determine the platform
@@ -170,8 +293,8 @@ func (s *CoreUseCase) ProcessSystemMessage(
 		return fmt.Errorf("failed to get job: %w", err)
 	}
 	if !maybeJob.IsPresent() {
-		log.Printf("⚠️ Job %s not found - already completed, skipping system message", jobID)
-		return nil
+		log.Printf("⚠️ Job %s not found - already completed, rejecting system message", jobID)
+		return fmt.Errorf("%w: %s", core.ErrJobNotFound, jobID)
 	}
 
 	job := maybeJob.MustGet()
@@ -207,8 +330,8 @@ func (s *CoreUseCase) ProcessJobComplete(
 		return fmt.Errorf("failed to get job: %w", err)
 	}
 	if !maybeJob.IsPresent() {
-		log.Printf("⚠️ Job %s not found - already completed, skipping job complete message", jobID)
-		return nil
+		log.Printf("⚠️ Job %s not found - already completed, rejecting job complete message", jobID)
+		return fmt.Errorf("%w: %s", core.ErrJobNotFound, jobID)
 	}
 
 	job := maybeJob.MustGet()
@@ -226,6 +349,106 @@ func (s *CoreUseCase) ProcessJobComplete(
 	}
 }
 
+// ClaimInboundMessage reports whether a message received from an agent should be processed
+// Agents resend messages they got no acknowledgement for, e.g. after a reconnect, so a message whose ID
+// was already received from the same agent within the dedupe window is a duplicate and is skipped.
+// A duplicate of a message which is still being processed returns core.ErrMessageInProgress instead, so it
+// is not acknowledged and is processed once resent if the original fails.
+// Messages without an ID cannot be deduplicated and are always processed.
+func (s *CoreUseCase) ClaimInboundMessage(
+	ctx context.Context,
+	client *clients.Client,
+	messageID, messageType string,
+) (bool, error) {
+	if messageID == "" {
+		return true, nil
+	}
+
+	claimed, err := s.agentsService.ClaimAgentInboundMessage(ctx, client.OrgID, client.AgentID, messageID, messageType)
+	if errors.Is(err, core.ErrMessageInProgress) {
+		log.Printf("⏳ Message %s (%s) from agent %s is still being processed", messageID, messageType, client.AgentID)
+		return false, err
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim inbound message %s: %w", messageID, err)
+	}
+	if !claimed {
+		log.Printf("⏭️ Skipping duplicate message %s (%s) from agent %s", messageID, messageType, client.AgentID)
+	}
+	return claimed, nil
+}
+
+// CompleteInboundMessage records that a claimed message was processed, so resent copies of it are skipped
+func (s *CoreUseCase) CompleteInboundMessage(ctx context.Context, client *clients.Client, messageID string) error {
+	if messageID == "" {
+		return nil
+	}
+
+	if err := s.agentsService.CompleteAgentInboundMessage(ctx, client.OrgID, client.AgentID, messageID); err != nil {
+		return fmt.Errorf("failed to complete inbound message %s: %w", messageID, err)
+	}
+	return nil
+}
+
+// ReleaseInboundMessage forgets a claimed message whose processing failed, so it is processed if resent
+func (s *CoreUseCase) ReleaseInboundMessage(ctx context.Context, client *clients.Client, messageID string) error {
+	if messageID == "" {
+		return nil
+	}
+
+	if err := s.agentsService.ReleaseAgentInboundMessage(ctx, client.OrgID, client.AgentID, messageID); err != nil {
+		return fmt.Errorf("failed to release inbound message %s: %w", messageID, err)
+	}
+	return nil
+}
+
+// ProcessRejectedMessage tells the agent why one of its messages was rejected with an error message
+// Rejections for invalid payloads are counted per agent.
+// Jobs which no longer exist are expected once a job is completed from chat, so the agent is only told
+// to stop working on them. Every other error is returned for alerting.
+func (s *CoreUseCase) ProcessRejectedMessage(
+	ctx context.Context,
+	client *clients.Client,
+	messageID, jobID string,
+	rejection error,
+) error {
+	code, ok := agentErrorCode(rejection)
+	if !ok {
+		return rejection
+	}
+	if code == models.AgentErrorCodeInvalidPayload {
+		if err := s.agentsService.IncrementAgentValidationFailures(ctx, client.OrgID, client.ID); err != nil {
+			log.Printf("❌ Failed to count validation failure of client %s: %v", client.ID, err)
+		}
+	}
+
+	log.Printf("📋 Starting to report rejected message %s to client %s: %s", messageID, client.ID, code)
+	errorMessage := models.BaseMessage{
+		ID:   core.NewID("msg"),
+		Type: models.MessageTypeError,
+		Payload: models.ErrorPayload{
+			MessageID: messageID,
+			JobID:     jobID,
+			Code:      code,
+			Message:   rejection.Error(),
+		},
+	}
+	err := s.wsClient.SendMessage(client.ID, errorMessage)
+	switch {
+	case errors.Is(err, core.ErrUnsupportedMessageType):
+		log.Printf("⏭️ Client %s does not support error messages - not reporting rejected message", client.ID)
+	case err != nil:
+		log.Printf("❌ Failed to report rejected message %s to client %s: %v", messageID, client.ID, err)
+	default:
+		log.Printf("📋 Completed successfully - reported rejected message %s to client %s", messageID, client.ID)
+	}
+
+	if code == models.AgentErrorCodeJobNotFound {
+		return nil
+	}
+	return rejection
+}
+
 // ProcessQueuedJobs processes queued jobs for all platforms
 func (s *CoreUseCase) ProcessQueuedJobs(ctx context.Context) error {
 	log.Printf("📋 Starting to process queued jobs for all platforms")
@@ -244,16 +467,134 @@ func (s *CoreUseCase) ProcessQueuedJobs(ctx context.Context) error {
 	return nil
 }
 
+const DefaultStuckMessageTimeoutMinutes = 30
+
+// ProcessStuckMessages recovers in-progress messages whose agent stopped responding mid-turn, e.g. because it crashed
+// without disconnecting cleanly. Jobs are left alone while their agent is connected and still pinging.
+// Stuck jobs are requeued when a responsive agent matching their repository and agent selector has room for them,
+// and handed out by ProcessQueuedJobs. Otherwise their in-progress messages are failed and the thread is told to send
+// them again.
+func (s *CoreUseCase) ProcessStuckMessages(ctx context.Context) error {
+	log.Printf("📋 Starting to process stuck messages")
+	organizations, err := s.organizationsService.GetAllOrganizations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get organizations: %w", err)
+	}
+
+	requeueNotice := "The agent working on your message stopped responding, your job has been queued for another agent"
+	failureNotice := "The agent working on your message stopped responding and no other agent is available - " +
+		"please send your message again"
+	connectedClientIDs := s.wsClient.GetClientIDs()
+	stuckThreshold := time.Now().Add(-DefaultStuckMessageTimeoutMinutes * time.Minute)
+	var requeuedJobs, failedJobs int
+	for _, organization := range organizations {
+		orgID := models.OrgID(organization.ID)
+
+		stuckJobs, err := s.jobsService.GetStuckJobs(ctx, orgID, DefaultStuckMessageTimeoutMinutes)
+		if err != nil {
+			return fmt.Errorf("failed to get stuck jobs for organization %s: %w", orgID, err)
+		}
+		if len(stuckJobs) == 0 {
+			continue
+		}
+
+		for _, job := range stuckJobs {
+			maybeAgent, err := s.agentsService.GetAgentByJobID(ctx, orgID, job.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get agent for stuck job %s: %w", job.ID, err)
+			}
+
+			// The agent of the job may be gone already, e.g. cleaned up after it stopped pinging
+			var agentID string
+			if agent, ok := maybeAgent.Get(); ok {
+				if s.agentsService.CheckAgentHasActiveConnection(agent, connectedClientIDs) &&
+					agent.LastActiveAt.After(stuckThreshold) {
+					log.Printf("⏭️ Agent %s of job %s is still responsive, leaving the job to it", agent.ID, job.ID)
+					continue
+				}
+				agentID = agent.ID
+			}
+
+			// Requeue only if an agent matching the job's repository and agent selector could take it right now
+			var hasAvailableAgent bool
+			switch job.JobType {
+			case models.JobTypeSlack:
+				hasAvailableAgent, err = s.slackUseCase.HasAvailableAgentForSlackJob(ctx, job, stuckThreshold)
+				if err != nil {
+					return fmt.Errorf("failed to check for agents available to stuck job %s: %w", job.ID, err)
+				}
+				if hasAvailableAgent {
+					err = s.slackUseCase.RequeueSlackJob(ctx, job, agentID, requeueNotice)
+				} else {
+					err = s.slackUseCase.FailStuckSlackJob(ctx, job, agentID, failureNotice)
+				}
+			case models.JobTypeDiscord:
+				hasAvailableAgent, err = s.discordUseCase.HasAvailableAgentForDiscordJob(ctx, job, stuckThreshold)
+				if err != nil {
+					return fmt.Errorf("failed to check for agents available to stuck job %s: %w", job.ID, err)
+				}
+				if hasAvailableAgent {
+					err = s.discordUseCase.RequeueDiscordJob(ctx, job, agentID, requeueNotice)
+				} else {
+					err = s.discordUseCase.FailStuckDiscordJob(ctx, job, agentID, failureNotice)
+				}
+			default:
+				log.Printf("⚠️ Unknown job type %s for job %s, skipping", job.JobType, job.ID)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to recover stuck job %s: %w", job.ID, err)
+			}
+
+			if hasAvailableAgent {
+				requeuedJobs++
+			} else {
+				failedJobs++
+			}
+		}
+	}
+
+	log.Printf(
+		"📋 Completed successfully - requeued %d stuck jobs, failed the messages of %d stuck jobs",
+		requeuedJobs,
+		failedJobs,
+	)
+	return nil
+}
+
 // RegisterAgent registers a new agent connection in the system
 func (s *CoreUseCase) RegisterAgent(ctx context.Context, client *clients.Client) error {
 	log.Printf("📋 Starting to register agent for client %s", client.ID)
 
 	// Pass the agent ID and repository URL to UpsertActiveAgent - use organization ID since agents are organization-scoped
-	_, err := s.agentsService.UpsertActiveAgent(ctx, client.OrgID, client.ID, client.AgentID, client.RepoURL)
+	agent, err := s.agentsService.UpsertActiveAgent(ctx, client.OrgID, client.ID, client.AgentID, client.RepoURL, client.MaxConcurrency, client.Labels)
 	if err != nil {
 		return fmt.Errorf("failed to register agent for client %s: %w", client.ID, err)
 	}
 
+	// Store the version and host metadata from the handshake - the round-trip time follows with the pings
+	if !client.Telemetry.IsEmpty() {
+		if err := s.agentsService.UpdateAgentTelemetry(ctx, client.OrgID, client.ID, client.Telemetry); err != nil {
+			return fmt.Errorf("failed to store telemetry for agent %s: %w", agent.ID, err)
+		}
+	}
+
+	// An agent reconnecting within the grace period keeps its job assignments (the upsert re-binds them
+	// to the new connection) - deliver any messages which were queued while it was away
+	jobIDs, err := s.agentsService.GetActiveAgentJobAssignments(ctx, client.OrgID, agent.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get job assignments for agent %s: %w", agent.ID, err)
+	}
+	if len(jobIDs) > 0 {
+		log.Printf("🔁 Agent %s reconnected with %d assigned job(s), resuming them on client %s", agent.ID, len(jobIDs), client.ID)
+		if err := s.slackUseCase.ProcessQueuedJobs(ctx); err != nil {
+			log.Printf("⚠️ Failed to process queued Slack jobs for reconnected agent, background processor will retry: %v", err)
+		}
+		if err := s.discordUseCase.ProcessQueuedJobs(ctx); err != nil {
+			log.Printf("⚠️ Failed to process queued Discord jobs for reconnected agent, background processor will retry: %v", err)
+		}
+	}
+
 	log.Printf(
 		"📋 Completed successfully - registered agent for client %s with organization %s, repo_url: %s",
 		client.ID,
@@ -263,7 +604,7 @@ func (s *CoreUseCase) RegisterAgent(ctx context.Context, client *clients.Client)
 	return nil
 }
 
-// DeregisterAgent removes an agent from the system and cleans up its jobs
+// DeregisterAgent removes an agent from the system and requeues its jobs for other agents
 func (s *CoreUseCase) DeregisterAgent(ctx context.Context, client *clients.Client) error {
 	log.Printf("📋 Starting to deregister agent for client %s", client.ID)
 
@@ -288,42 +629,45 @@ func (s *CoreUseCase) DeregisterAgent(ctx context.Context, client *clients.Clien
 		return fmt.Errorf("failed to get jobs for cleanup: %w", err)
 	}
 
-	// Clean up all job assignments - handle each job consistently
-	log.Printf("🧹 Agent %s has %d assigned job(s), cleaning up all assignments", agent.ID, len(jobs))
+	// Requeue all job assignments so that another agent can continue the conversations
+	log.Printf("🔄 Agent %s has %d assigned job(s), requeuing all assignments", agent.ID, len(jobs))
+
+	requeueNotice := "The assigned agent was disconnected, your job has been queued for another agent"
+	var requeuedSlackJobs, requeuedDiscordJobs int
 
-	// Process each job: route cleanup based on job type
+	// Process each job: route requeue based on job type
 	for _, jobID := range jobs {
 		// Get job directly using organization_id (optimization)
 		maybeJob, err := s.jobsService.GetJobByID(ctx, client.OrgID, jobID)
 		if err != nil {
-			log.Printf("❌ Failed to get job %s for cleanup: %v", jobID, err)
-			return fmt.Errorf("failed to get job for cleanup: %w", err)
+			log.Printf("❌ Failed to get job %s for requeue: %v", jobID, err)
+			return fmt.Errorf("failed to get job for requeue: %w", err)
 		}
 		if !maybeJob.IsPresent() {
-			log.Printf("❌ Job %s not found for cleanup", jobID)
-			continue // Skip this job, it may have been deleted already
+			log.Printf("❌ Job %s not found for requeue", jobID)
+			continue // Skip this job, it may have been closed already
 		}
 
 		job := maybeJob.MustGet()
 
-		// Route cleanup based on job type
+		// Route requeue based on job type
 		switch job.JobType {
 		case models.JobTypeSlack:
-			abandonmentMessage := ":x: The assigned agent was disconnected, abandoning job"
-			if err := s.slackUseCase.CleanupFailedSlackJob(ctx, job, agent.ID, abandonmentMessage); err != nil {
-				return fmt.Errorf("failed to cleanup abandoned Slack job %s: %w", jobID, err)
+			if err := s.slackUseCase.RequeueSlackJob(ctx, job, agent.ID, requeueNotice); err != nil {
+				return fmt.Errorf("failed to requeue Slack job %s: %w", jobID, err)
 			}
+			requeuedSlackJobs++
 		case models.JobTypeDiscord:
-			abandonmentMessage := "❌ The assigned agent was disconnected, abandoning job"
-			if err := s.discordUseCase.CleanupFailedDiscordJob(ctx, job, agent.ID, abandonmentMessage); err != nil {
-				return fmt.Errorf("failed to cleanup abandoned Discord job %s: %w", jobID, err)
+			if err := s.discordUseCase.RequeueDiscordJob(ctx, job, agent.ID, requeueNotice); err != nil {
+				return fmt.Errorf("failed to requeue Discord job %s: %w", jobID, err)
 			}
+			requeuedDiscordJobs++
 		default:
-			log.Printf("⚠️ Unknown job type %s for job %s, skipping cleanup", job.JobType, jobID)
+			log.Printf("⚠️ Unknown job type %s for job %s, skipping requeue", job.JobType, jobID)
 			continue
 		}
 
-		log.Printf("✅ Cleaned up abandoned job %s", jobID)
+		log.Printf("✅ Requeued job %s from disconnected agent %s", jobID, agent.ID)
 	}
 
 	// Delete the agent record (use organization ID since agents are organization-scoped)
@@ -332,12 +676,75 @@ func (s *CoreUseCase) DeregisterAgent(ctx context.Context, client *clients.Clien
 		return fmt.Errorf("failed to deregister agent for client %s: %w", client.ID, err)
 	}
 
+	// Hand requeued jobs to other connected agents right away instead of waiting for the background processor
+	if requeuedSlackJobs > 0 {
+		if err := s.slackUseCase.ProcessQueuedJobs(ctx); err != nil {
+			log.Printf("⚠️ Failed to reassign requeued Slack jobs, background processor will retry: %v", err)
+		}
+	}
+	if requeuedDiscordJobs > 0 {
+		if err := s.discordUseCase.ProcessQueuedJobs(ctx); err != nil {
+			log.Printf("⚠️ Failed to reassign requeued Discord jobs, background processor will retry: %v", err)
+		}
+	}
+
 	log.Printf("📋 Completed successfully - deregistered agent for client %s", client.ID)
 	return nil
 }
 
-// ProcessPing updates the last active timestamp for an agent
-func (s *CoreUseCase) ProcessPing(ctx context.Context, client *clients.Client) error {
+// MarkAgentDisconnected starts the reconnect grace period of the client's agent
+// The grace period is tracked in the database so that the agent keeps its jobs when it reconnects to any replica,
+// and so that it is still deregistered if this replica goes away.
+func (s *CoreUseCase) MarkAgentDisconnected(ctx context.Context, client *clients.Client) error {
+	log.Printf("📋 Starting to mark agent for client %s as disconnected", client.ID)
+
+	err := s.agentsService.MarkAgentDisconnected(ctx, client.OrgID, client.ID)
+	if errors.Is(err, core.ErrNotFound) {
+		log.Printf("📋 Completed successfully - no agent bound to client %s (already cleaned up or reconnected)", client.ID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to mark agent for client %s as disconnected: %w", client.ID, err)
+	}
+
+	log.Printf("📋 Completed successfully - marked agent for client %s as disconnected", client.ID)
+	return nil
+}
+
+// DeregisterDisconnectedAgents deregisters agents which did not reconnect within the grace period
+// and requeues their jobs
+func (s *CoreUseCase) DeregisterDisconnectedAgents(ctx context.Context, gracePeriod time.Duration) error {
+	log.Printf("📋 Starting to deregister disconnected agents")
+	organizations, err := s.organizationsService.GetAllOrganizations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get organizations: %w", err)
+	}
+
+	totalDeregistered := 0
+	for _, organization := range organizations {
+		orgID := models.OrgID(organization.ID)
+
+		disconnectedAgents, err := s.agentsService.GetDisconnectedAgents(ctx, orgID, gracePeriod)
+		if err != nil {
+			return fmt.Errorf("failed to get disconnected agents for organization %s: %w", orgID, err)
+		}
+
+		for _, agent := range disconnectedAgents {
+			log.Printf("⏰ Agent %s did not reconnect within %s - deregistering it", agent.ID, gracePeriod)
+			client := &clients.Client{ID: agent.WSConnectionID, OrgID: orgID, AgentID: agent.CCAgentID}
+			if err := s.DeregisterAgent(ctx, client); err != nil {
+				return fmt.Errorf("failed to deregister disconnected agent %s: %w", agent.ID, err)
+			}
+			totalDeregistered++
+		}
+	}
+
+	log.Printf("📋 Completed successfully - deregistered %d disconnected agents", totalDeregistered)
+	return nil
+}
+
+// ProcessPing updates the last active timestamp for an agent and stores the telemetry attached to the ping
+func (s *CoreUseCase) ProcessPing(ctx context.Context, client *clients.Client, telemetry models.AgentTelemetry) error {
 	log.Printf("📋 Starting to process ping from client %s", client.ID)
 
 	// Check if agent exists for this client (agents are organization-scoped)
@@ -351,18 +758,350 @@ func (s *CoreUseCase) ProcessPing(ctx context.Context, client *clients.Client) e
 		return fmt.Errorf("no agent found for client: %s", client.ID)
 	}
 
-	// Update the agent's last_active_at timestamp (use organization ID since agents are organization-scoped)
-	if err := s.agentsService.UpdateAgentLastActiveAt(ctx, client.OrgID, client.ID); err != nil {
-		log.Printf("❌ Failed to update agent last_active_at for client %s: %v", client.ID, err)
-		return fmt.Errorf("failed to update agent last_active_at: %w", err)
+	// Update the agent's last_active_at timestamp and telemetry (use organization ID since agents are organization-scoped)
+	if err := s.agentsService.UpdateAgentTelemetry(ctx, client.OrgID, client.ID, telemetry); err != nil {
+		log.Printf("❌ Failed to update agent telemetry for client %s: %v", client.ID, err)
+		return fmt.Errorf("failed to update agent telemetry: %w", err)
 	}
 
 	log.Printf("📋 Completed successfully - updated ping timestamp for client %s", client.ID)
 	return nil
 }
 
+// ProcessMessageAck records that an agent acknowledged a message sent to it
+func (s *CoreUseCase) ProcessMessageAck(ctx context.Context, client *clients.Client, messageID string) error {
+	log.Printf("📋 Starting to process acknowledgement of message %s from client %s", messageID, client.ID)
+
+	message, err := s.agentsService.MarkAgentMessageDelivered(ctx, client.OrgID, messageID)
+	if errors.Is(err, core.ErrNotFound) {
+		// Messages which are not tracked in the outbox (e.g. CheckIdleJobs) and duplicate acknowledgements
+		log.Printf("📋 Completed successfully - message %s has no pending delivery", messageID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to mark message %s as delivered: %w", messageID, err)
+	}
+
+	// The agent stopped working on the job, so the user can be told it was cancelled
+	if message.MessageType == models.MessageTypeCancelJob {
+		if err := s.processJobCancelled(ctx, client.OrgID, message.JobID); err != nil {
+			return fmt.Errorf("failed to process cancellation of job %s: %w", message.JobID, err)
+		}
+	}
+
+	log.Printf("📋 Completed successfully - message %s delivered to client %s", messageID, client.ID)
+	return nil
+}
+
+// ProcessMessageDeliveryFailure records a message relayed from another replica which could not be sent to the
+// client, so the outbox resends it or gives up on it instead of waiting for an acknowledgement that never comes
+func (s *CoreUseCase) ProcessMessageDeliveryFailure(
+	ctx context.Context,
+	client *clients.Client,
+	messageID string,
+	reason error,
+) error {
+	log.Printf("📋 Starting to process delivery failure of message %s to client %s", messageID, client.ID)
+
+	err := s.agentsService.RecordAgentMessageDeliveryFailure(ctx, client.OrgID, messageID, reason)
+	if errors.Is(err, core.ErrNotFound) {
+		// Messages which are not tracked in the outbox (e.g. CheckIdleJobs) are not resent
+		log.Printf("📋 Completed successfully - message %s has no pending delivery", messageID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record delivery failure of message %s: %w", messageID, err)
+	}
+
+	log.Printf("📋 Completed successfully - recorded delivery failure of message %s", messageID)
+	return nil
+}
+
+// PrepareAgentMessage downloads the files forwarded with a message right before it is emitted to the agent
+// Only their source is stored in the agent outbox and relayed between replicas. Files which fail to download are
+// skipped - the message itself is still delivered.
+func (s *CoreUseCase) PrepareAgentMessage(ctx context.Context, client *clients.Client, msg any) (any, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	var message struct {
+		ID      string          `json:"id"`
+		Type    string          `json:"type"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	switch message.Type {
+	case models.MessageTypeStartConversation:
+		var payload models.StartConversationPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s payload: %w", message.Type, err)
+		}
+		if !hasAttachmentSources(payload.Attachments) {
+			return msg, nil
+		}
+		payload.Attachments = s.downloadAttachments(ctx, client, payload.Attachments)
+		return models.BaseMessage{ID: message.ID, Type: message.Type, Payload: payload}, nil
+	case models.MessageTypeUserMessage:
+		var payload models.UserMessagePayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s payload: %w", message.Type, err)
+		}
+		if !hasAttachmentSources(payload.Attachments) {
+			return msg, nil
+		}
+		payload.Attachments = s.downloadAttachments(ctx, client, payload.Attachments)
+		return models.BaseMessage{ID: message.ID, Type: message.Type, Payload: payload}, nil
+	default:
+		return msg, nil
+	}
+}
+
+func hasAttachmentSources(attachments []models.AgentAttachment) bool {
+	for _, attachment := range attachments {
+		if attachment.Source != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// downloadAttachments replaces the source of each attachment with its content, skipping files which fail to download
+func (s *CoreUseCase) downloadAttachments(
+	ctx context.Context,
+	client *clients.Client,
+	attachments []models.AgentAttachment,
+) []models.AgentAttachment {
+	var downloaded []models.AgentAttachment
+	for _, attachment := range attachments {
+		if attachment.Source == nil {
+			downloaded = append(downloaded, attachment)
+			continue
+		}
+
+		var content []byte
+		var err error
+		switch attachment.Source.Platform {
+		case models.JobTypeSlack:
+			content, err = s.slackUseCase.DownloadAttachment(ctx, *attachment.Source)
+		case models.JobTypeDiscord:
+			content, err = s.discordUseCase.DownloadAttachment(ctx, *attachment.Source)
+		default:
+			err = fmt.Errorf("unknown attachment platform %s", attachment.Source.Platform)
+		}
+		if err != nil {
+			log.Printf(
+				"⚠️ Failed to download attachment %s for client %s - skipping: %v",
+				attachment.Name,
+				client.ID,
+				err,
+			)
+			continue
+		}
+		downloaded = append(downloaded, models.AgentAttachment{
+			Name:     attachment.Name,
+			MimeType: attachment.MimeType,
+			Content:  content,
+		})
+	}
+	return downloaded
+}
+
+// processJobCancelled routes the agent's confirmation of a job cancellation to the appropriate usecase
+func (s *CoreUseCase) processJobCancelled(ctx context.Context, orgID models.OrgID, jobID string) error {
+	maybeJob, err := s.jobsService.GetJobByID(ctx, orgID, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+	if !maybeJob.IsPresent() {
+		log.Printf("⚠️ Job %s not found - already completed, skipping cancellation confirmation", jobID)
+		return nil
+	}
+	job := maybeJob.MustGet()
+
+	switch job.JobType {
+	case models.JobTypeSlack:
+		log.Printf("🔀 Routing job cancellation to Slack usecase for job %s", jobID)
+		return s.slackUseCase.ProcessJobCancelled(ctx, job)
+	case models.JobTypeDiscord:
+		log.Printf("🔀 Routing job cancellation to Discord usecase for job %s", jobID)
+		return s.discordUseCase.ProcessJobCancelled(ctx, job)
+	default:
+		return fmt.Errorf("unsupported job type: %s", job.JobType)
+	}
+}
+
+// processJobCancellationUnconfirmed routes a cancellation the agent never acknowledged to the appropriate usecase,
+// which tells the user the agent may still be working on the job
+func (s *CoreUseCase) processJobCancellationUnconfirmed(ctx context.Context, orgID models.OrgID, jobID string) error {
+	maybeJob, err := s.jobsService.GetJobByID(ctx, orgID, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+	if !maybeJob.IsPresent() {
+		log.Printf("⚠️ Job %s not found - already completed, skipping unconfirmed cancellation notice", jobID)
+		return nil
+	}
+	job := maybeJob.MustGet()
+	if job.Status.IsClosed() {
+		log.Printf("⚠️ Job %s is %s, skipping unconfirmed cancellation notice", job.ID, job.Status)
+		return nil
+	}
+
+	switch job.JobType {
+	case models.JobTypeSlack:
+		log.Printf("🔀 Routing unconfirmed job cancellation to Slack usecase for job %s", jobID)
+		return s.slackUseCase.ProcessJobCancellationUnconfirmed(ctx, job)
+	case models.JobTypeDiscord:
+		log.Printf("🔀 Routing unconfirmed job cancellation to Discord usecase for job %s", jobID)
+		return s.discordUseCase.ProcessJobCancellationUnconfirmed(ctx, job)
+	default:
+		return fmt.Errorf("unsupported job type: %s", job.JobType)
+	}
+}
+
+// RetryUndeliveredAgentMessages resends messages which agents did not acknowledge in time
+// After MaxAgentMessageDeliveryAttempts the message is given up on and its job is requeued for another agent.
+func (s *CoreUseCase) RetryUndeliveredAgentMessages(ctx context.Context) error {
+	log.Printf("📋 Starting to retry undelivered agent messages")
+	organizations, err := s.organizationsService.GetAllOrganizations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get organizations: %w", err)
+	}
+
+	requeueNotice := "The assigned agent did not acknowledge your message, your job has been queued for another agent"
+	var retriedMessages, requeuedSlackJobs, requeuedDiscordJobs int
+	for _, organization := range organizations {
+		orgID := models.OrgID(organization.ID)
+
+		messages, err := s.agentsService.GetAgentMessagesDueForRetry(ctx, orgID)
+		if err != nil {
+			return fmt.Errorf("failed to get undelivered agent messages for organization %s: %w", orgID, err)
+		}
+
+		requeuedJobIDs := make(map[string]bool)
+		for _, message := range messages {
+			if message.Attempts < models.MaxAgentMessageDeliveryAttempts {
+				if err := s.agentsService.RedeliverAgentMessage(ctx, orgID, message); err != nil {
+					return fmt.Errorf("failed to redeliver message %s: %w", message.ID, err)
+				}
+				retriedMessages++
+				continue
+			}
+
+			reason := fmt.Sprintf("not acknowledged after %d attempts", message.Attempts)
+			if err := s.agentsService.MarkAgentMessageFailed(ctx, orgID, message.ID, reason); err != nil {
+				if errors.Is(err, core.ErrNotFound) {
+					continue // Acknowledged in the meantime
+				}
+				return fmt.Errorf("failed to mark message %s as failed: %w", message.ID, err)
+			}
+			if message.MessageType == models.MessageTypeCancelJob {
+				// The job's messages are already cancelled, there is nothing left to requeue. The message is given
+				// up on either way, so a failed notice doesn't hold up the other messages.
+				log.Printf("⚠️ Agent %s did not acknowledge cancellation of job %s", message.AgentID, message.JobID)
+				if err := s.processJobCancellationUnconfirmed(ctx, orgID, message.JobID); err != nil {
+					log.Printf("❌ Failed to report unconfirmed cancellation of job %s: %v", message.JobID, err)
+				}
+				continue
+			}
+			if message.MessageType == models.MessageTypeJobClosed {
+				// The job is closed already, there is nothing left to requeue
+				log.Printf("⚠️ Agent %s did not acknowledge closing of job %s", message.AgentID, message.JobID)
+				continue
+			}
+			log.Printf(
+				"⚠️ Agent %s did not acknowledge message %s, requeuing job %s",
+				message.AgentID,
+				message.ID,
+				message.JobID,
+			)
+
+			if requeuedJobIDs[message.JobID] {
+				continue
+			}
+			requeuedJobIDs[message.JobID] = true
+
+			maybeJob, err := s.jobsService.GetJobByID(ctx, orgID, message.JobID)
+			if err != nil {
+				return fmt.Errorf("failed to get job %s for requeue: %w", message.JobID, err)
+			}
+			if !maybeJob.IsPresent() {
+				log.Printf("⚠️ Job %s not found for requeue, skipping", message.JobID)
+				continue
+			}
+			job := maybeJob.MustGet()
+			if job.Status != models.JobStatusActive {
+				log.Printf("⚠️ Job %s is %s, skipping requeue", job.ID, job.Status)
+				continue
+			}
+
+			switch job.JobType {
+			case models.JobTypeSlack:
+				if err := s.slackUseCase.RequeueSlackJob(ctx, job, message.AgentID, requeueNotice); err != nil {
+					return fmt.Errorf("failed to requeue Slack job %s: %w", job.ID, err)
+				}
+				requeuedSlackJobs++
+			case models.JobTypeDiscord:
+				if err := s.discordUseCase.RequeueDiscordJob(ctx, job, message.AgentID, requeueNotice); err != nil {
+					return fmt.Errorf("failed to requeue Discord job %s: %w", job.ID, err)
+				}
+				requeuedDiscordJobs++
+			default:
+				log.Printf("⚠️ Unknown job type %s for job %s, skipping requeue", job.JobType, job.ID)
+			}
+		}
+	}
+
+	// Hand requeued jobs to other connected agents right away instead of waiting for the background processor
+	if requeuedSlackJobs > 0 {
+		if err := s.slackUseCase.ProcessQueuedJobs(ctx); err != nil {
+			log.Printf("⚠️ Failed to reassign requeued Slack jobs, background processor will retry: %v", err)
+		}
+	}
+	if requeuedDiscordJobs > 0 {
+		if err := s.discordUseCase.ProcessQueuedJobs(ctx); err != nil {
+			log.Printf("⚠️ Failed to reassign requeued Discord jobs, background processor will retry: %v", err)
+		}
+	}
+
+	log.Printf(
+		"📋 Completed successfully - retried %d agent messages, requeued %d jobs",
+		retriedMessages,
+		requeuedSlackJobs+requeuedDiscordJobs,
+	)
+	return nil
+}
+
 const DefaultInactiveAgentTimeoutMinutes = 10
 
+// CleanupExpiredAgentInboundMessages removes the IDs of messages received from agents before the dedupe window
+func (s *CoreUseCase) CleanupExpiredAgentInboundMessages(ctx context.Context) error {
+	log.Printf("📋 Starting to cleanup expired agent inbound messages")
+	deleted, err := s.agentsService.DeleteExpiredAgentInboundMessages(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired agent inbound messages: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - cleaned up %d expired agent inbound messages", deleted)
+	return nil
+}
+
+// CleanupFinishedAgentMessages removes outbox messages delivered or given up on before the retention period
+func (s *CoreUseCase) CleanupFinishedAgentMessages(ctx context.Context) error {
+	log.Printf("📋 Starting to cleanup finished agent messages")
+	deleted, err := s.agentsService.DeleteFinishedAgentMessages(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete finished agent messages: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - cleaned up %d finished agent messages", deleted)
+	return nil
+}
+
 // CleanupInactiveAgents removes agents that have been inactive for more than the timeout period
 func (s *CoreUseCase) CleanupInactiveAgents(ctx context.Context) error {
 	log.Printf("📋 Starting to cleanup inactive agents")
@@ -466,7 +1205,12 @@ func (s *CoreUseCase) BroadcastCheckIdleJobs(ctx context.Context) error {
 		}
 
 		for _, agent := range connectedAgents {
-			if err := s.wsClient.SendMessage(agent.WSConnectionID, checkIdleJobsMessage); err != nil {
+			err := s.wsClient.SendMessage(agent.WSConnectionID, checkIdleJobsMessage)
+			if errors.Is(err, core.ErrUnsupportedMessageType) {
+				log.Printf("⏭️ Skipping agent %s - it does not support CheckIdleJobs messages", agent.ID)
+				continue
+			}
+			if err != nil {
 				return fmt.Errorf("failed to send CheckIdleJobs message to agent %s: %w", agent.ID, err)
 			}
 			log.Printf("📤 Sent CheckIdleJobs message to agent %s", agent.ID)
@@ -477,3 +1221,75 @@ func (s *CoreUseCase) BroadcastCheckIdleJobs(ctx context.Context) error {
 	log.Printf("📋 Completed successfully - broadcasted CheckIdleJobs to %d agents", totalAgentCount)
 	return nil
 }
+
+// CloseIdleJobs closes the jobs which were idle for longer than their organization's idle job timeout
+// Organizations without an idle job timeout leave closing idle jobs to their agents.
+func (s *CoreUseCase) CloseIdleJobs(ctx context.Context) error {
+	log.Printf("📋 Starting to close idle jobs")
+
+	organizations, err := s.organizationsService.GetAllOrganizations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get organizations: %w", err)
+	}
+
+	closedJobCount := 0
+	for _, organization := range organizations {
+		orgID := models.OrgID(organization.ID)
+
+		timeoutValue, err := s.settingsService.GetStringSetting(ctx, organization.ID, models.IdleJobTimeoutSettingKey)
+		if err != nil {
+			return fmt.Errorf("failed to get idle job timeout for organization %s: %w", orgID, err)
+		}
+		idleTimeout, err := models.ParseIdleJobTimeout(timeoutValue)
+		if err != nil {
+			log.Printf("⚠️ Ignoring invalid idle job timeout %q of organization %s: %v", timeoutValue, orgID, err)
+			continue
+		}
+		if idleTimeout == 0 {
+			continue
+		}
+
+		idleJobs, err := s.jobsService.GetIdleJobs(ctx, orgID, int(idleTimeout/time.Minute))
+		if err != nil {
+			return fmt.Errorf("failed to get idle jobs for organization %s: %w", orgID, err)
+		}
+
+		notice := fmt.Sprintf("This job was closed automatically after being idle for %s", timeoutValue)
+		for _, job := range idleJobs {
+			switch job.JobType {
+			case models.JobTypeSlack:
+				err = s.slackUseCase.CloseIdleSlackJob(ctx, job, notice)
+			case models.JobTypeDiscord:
+				err = s.discordUseCase.CloseIdleDiscordJob(ctx, job, notice)
+			default:
+				log.Printf("⚠️ Unknown job type %s for job %s, skipping closing idle job", job.JobType, job.ID)
+				continue
+			}
+			// A job which failed to close is retried on the next run, without holding up the other jobs
+			if err != nil {
+				log.Printf("❌ Failed to close idle job %s: %v", job.ID, err)
+				continue
+			}
+			log.Printf("⏰ Closed job %s after being idle for %s", job.ID, timeoutValue)
+			closedJobCount++
+		}
+	}
+
+	log.Printf("📋 Completed successfully - closed %d idle jobs", closedJobCount)
+	return nil
+}
+
+// agentErrorCode returns the code reported to the agent for an error rejecting its message
+// Returns false for errors which are not caused by the message itself, e.g. database failures.
+func agentErrorCode(err error) (models.AgentErrorCode, bool) {
+	switch {
+	case errors.Is(err, core.ErrJobNotFound):
+		return models.AgentErrorCodeJobNotFound, true
+	case errors.Is(err, core.ErrJobNotAssigned):
+		return models.AgentErrorCodeNotAssigned, true
+	case errors.Is(err, core.ErrInvalidPayload):
+		return models.AgentErrorCodeInvalidPayload, true
+	default:
+		return "", false
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -11,11 +12,15 @@ import (
 
 	"ccbackend/clients"
 	"ccbackend/clients/socketio"
+	"ccbackend/core"
 	"ccbackend/models"
 	"ccbackend/services/agents"
 	"ccbackend/services/jobs"
 	"ccbackend/services/organizations"
+	settingsservice "ccbackend/services/settings"
 	slackintegrations "ccbackend/services/slack_integrations"
+	discordusecase "ccbackend/usecases/discord"
+	slackusecase "ccbackend/usecases/slack"
 )
 
 // Agent Management Tests
@@ -36,15 +41,19 @@ func TestRegisterAgent(t *testing.T) {
 			mockJobsService,
 			mockSlackIntegrationsService,
 			mockOrganizationsService,
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
 
 		client := &clients.Client{
-			ID:      "ws-123",
-			OrgID:   models.OrgID("org-456"),
-			AgentID: "agent-789",
-			RepoURL: "github.com/test/repo",
+			ID:             "ws-123",
+			OrgID:          models.OrgID("org-456"),
+			AgentID:        "agent-789",
+			RepoURL:        "github.com/test/repo",
+			MaxConcurrency: 3,
+			Labels:         models.AgentLabels{"gpu": "true"},
+			Telemetry:      models.AgentTelemetry{Version: "1.2.3", OS: "linux", Hostname: "build-1"},
 		}
 
 		agent := &models.ActiveAgent{
@@ -54,8 +63,17 @@ func TestRegisterAgent(t *testing.T) {
 		}
 
 		// Configure expectations
-		mockAgentsService.On("UpsertActiveAgent", ctx, models.OrgID("org-456"), "ws-123", "agent-789", "github.com/test/repo").
+		mockAgentsService.On("UpsertActiveAgent", ctx, models.OrgID("org-456"), "ws-123", "agent-789", "github.com/test/repo", 3, models.AgentLabels{"gpu": "true"}).
 			Return(agent, nil)
+		mockAgentsService.On(
+			"UpdateAgentTelemetry",
+			ctx,
+			models.OrgID("org-456"),
+			"ws-123",
+			models.AgentTelemetry{Version: "1.2.3", OS: "linux", Hostname: "build-1"},
+		).Return(nil)
+		mockAgentsService.On("GetActiveAgentJobAssignments", ctx, models.OrgID("org-456"), "agent-789").
+			Return([]string{}, nil)
 
 		// Execute
 		err := useCase.RegisterAgent(ctx, client)
@@ -80,6 +98,7 @@ func TestRegisterAgent(t *testing.T) {
 			mockJobsService,
 			mockSlackIntegrationsService,
 			mockOrganizationsService,
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
@@ -92,7 +111,7 @@ func TestRegisterAgent(t *testing.T) {
 		}
 
 		// Configure expectations
-		mockAgentsService.On("UpsertActiveAgent", ctx, models.OrgID("org-456"), "ws-123", "agent-789", "github.com/test/repo").
+		mockAgentsService.On("UpsertActiveAgent", ctx, models.OrgID("org-456"), "ws-123", "agent-789", "github.com/test/repo", 0, models.AgentLabels(nil)).
 			Return(nil, assert.AnError)
 
 		// Execute
@@ -103,6 +122,61 @@ func TestRegisterAgent(t *testing.T) {
 		assert.Contains(t, err.Error(), "failed to register agent")
 		mockAgentsService.AssertExpectations(t)
 	})
+
+	t.Run("reconnect_resumes_assigned_jobs", func(t *testing.T) {
+		// Setup - the same ccagent reconnects within the grace period with a new connection ID
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockWSClient := new(socketio.MockSocketIOClient)
+		mockJobsService := new(jobs.MockJobsService)
+		mockSlackIntegrationsService := new(slackintegrations.MockSlackIntegrationsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+		mockDiscordUseCase := new(discordusecase.MockDiscordUseCase)
+
+		useCase := NewCoreUseCase(
+			mockWSClient,
+			mockAgentsService,
+			mockJobsService,
+			mockSlackIntegrationsService,
+			mockOrganizationsService,
+			nil, // settingsService
+			mockSlackUseCase,
+			mockDiscordUseCase,
+		)
+
+		client := &clients.Client{
+			ID:      "ws-new",
+			OrgID:   models.OrgID("org-456"),
+			AgentID: "ccagent-789",
+			RepoURL: "github.com/test/repo",
+		}
+
+		// Upsert keeps the existing agent record and points it to the new connection
+		agent := &models.ActiveAgent{
+			ID:             "agent-789",
+			WSConnectionID: "ws-new",
+			OrgID:          models.OrgID("org-456"),
+			CCAgentID:      "ccagent-789",
+		}
+
+		// Configure expectations
+		mockAgentsService.On("UpsertActiveAgent", ctx, models.OrgID("org-456"), "ws-new", "ccagent-789", "github.com/test/repo", 0, models.AgentLabels(nil)).
+			Return(agent, nil)
+		mockAgentsService.On("GetActiveAgentJobAssignments", ctx, models.OrgID("org-456"), "agent-789").
+			Return([]string{"job-111"}, nil)
+		mockSlackUseCase.On("ProcessQueuedJobs", ctx).Return(nil)
+		mockDiscordUseCase.On("ProcessQueuedJobs", ctx).Return(nil)
+
+		// Execute
+		err := useCase.RegisterAgent(ctx, client)
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+		mockSlackUseCase.AssertExpectations(t)
+		mockDiscordUseCase.AssertExpectations(t)
+	})
 }
 
 func TestDeregisterAgent(t *testing.T) {
@@ -121,6 +195,7 @@ func TestDeregisterAgent(t *testing.T) {
 			mockJobsService,
 			mockSlackIntegrationsService,
 			mockOrganizationsService,
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
@@ -153,6 +228,69 @@ func TestDeregisterAgent(t *testing.T) {
 		mockAgentsService.AssertExpectations(t)
 	})
 
+	t.Run("success_requeues_slack_job", func(t *testing.T) {
+		// Setup - jobs of a disconnected agent are requeued and handed to other agents
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockWSClient := new(socketio.MockSocketIOClient)
+		mockJobsService := new(jobs.MockJobsService)
+		mockSlackIntegrationsService := new(slackintegrations.MockSlackIntegrationsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+
+		useCase := NewCoreUseCase(
+			mockWSClient,
+			mockAgentsService,
+			mockJobsService,
+			mockSlackIntegrationsService,
+			mockOrganizationsService,
+			nil, // settingsService
+			mockSlackUseCase,
+			nil, // discordUseCase
+		)
+
+		client := &clients.Client{
+			ID:      "ws-123",
+			OrgID:   models.OrgID("org-456"),
+			RepoURL: "github.com/test/repo",
+		}
+
+		agent := &models.ActiveAgent{
+			ID:             "agent-789",
+			WSConnectionID: "ws-123",
+			OrgID:          models.OrgID("org-456"),
+		}
+
+		job := &models.Job{
+			ID:      "job-111",
+			JobType: models.JobTypeSlack,
+			OrgID:   models.OrgID("org-456"),
+		}
+
+		// Configure expectations
+		mockAgentsService.On("GetAgentByWSConnectionID", ctx, models.OrgID("org-456"), "ws-123").
+			Return(mo.Some(agent), nil)
+		mockAgentsService.On("GetActiveAgentJobAssignments", ctx, models.OrgID("org-456"), "agent-789").
+			Return([]string{"job-111"}, nil)
+		mockJobsService.On("GetJobByID", ctx, models.OrgID("org-456"), "job-111").
+			Return(mo.Some(job), nil)
+		mockSlackUseCase.On("RequeueSlackJob", ctx, job, "agent-789", mock.AnythingOfType("string")).
+			Return(nil)
+		mockAgentsService.On("DeleteActiveAgentByWsConnectionID", ctx, models.OrgID("org-456"), "ws-123").
+			Return(nil)
+		mockSlackUseCase.On("ProcessQueuedJobs", ctx).Return(nil)
+
+		// Execute
+		err := useCase.DeregisterAgent(ctx, client)
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+		mockJobsService.AssertExpectations(t)
+		mockSlackUseCase.AssertExpectations(t)
+		mockSlackUseCase.AssertNotCalled(t, "CleanupFailedSlackJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
 	t.Run("success_with_unknown_job_type", func(t *testing.T) {
 		// Setup - test agent deregistration with job that has unknown type (should skip cleanup)
 		ctx := context.Background()
@@ -168,6 +306,7 @@ func TestDeregisterAgent(t *testing.T) {
 			mockJobsService,
 			mockSlackIntegrationsService,
 			mockOrganizationsService,
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
@@ -225,6 +364,7 @@ func TestDeregisterAgent(t *testing.T) {
 			mockJobsService,
 			mockSlackIntegrationsService,
 			mockOrganizationsService,
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
@@ -262,6 +402,7 @@ func TestDeregisterAgent(t *testing.T) {
 			mockJobsService,
 			mockSlackIntegrationsService,
 			mockOrganizationsService,
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
@@ -313,6 +454,7 @@ func TestDeregisterAgent(t *testing.T) {
 			mockJobsService,
 			mockSlackIntegrationsService,
 			mockOrganizationsService,
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
@@ -370,6 +512,7 @@ func TestDeregisterAgent(t *testing.T) {
 			mockJobsService,
 			mockSlackIntegrationsService,
 			mockOrganizationsService,
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
@@ -407,6 +550,106 @@ func TestDeregisterAgent(t *testing.T) {
 	})
 }
 
+func TestMarkAgentDisconnected(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		useCase := NewCoreUseCase(nil, mockAgentsService, nil, nil, nil, nil, nil, nil)
+
+		client := &clients.Client{ID: "ws-123", OrgID: models.OrgID("org-456")}
+
+		// Configure expectations
+		mockAgentsService.On("MarkAgentDisconnected", ctx, models.OrgID("org-456"), "ws-123").Return(nil)
+
+		// Execute
+		err := useCase.MarkAgentDisconnected(ctx, client)
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+	})
+
+	t.Run("agent_already_reconnected", func(t *testing.T) {
+		// Setup - the agent reconnected (possibly to another replica) before its old connection was seen dropping
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		useCase := NewCoreUseCase(nil, mockAgentsService, nil, nil, nil, nil, nil, nil)
+
+		client := &clients.Client{ID: "ws-123", OrgID: models.OrgID("org-456")}
+
+		// Configure expectations
+		mockAgentsService.On("MarkAgentDisconnected", ctx, models.OrgID("org-456"), "ws-123").Return(core.ErrNotFound)
+
+		// Execute
+		err := useCase.MarkAgentDisconnected(ctx, client)
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+	})
+}
+
+func TestDeregisterDisconnectedAgents(t *testing.T) {
+	t.Run("deregisters_agents_past_grace_period", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		useCase := NewCoreUseCase(nil, mockAgentsService, nil, nil, mockOrganizationsService, nil, nil, nil)
+
+		gracePeriod := 30 * time.Second
+		disconnectedAt := time.Now().Add(-time.Minute)
+		agent := &models.ActiveAgent{
+			ID:             "agent-789",
+			WSConnectionID: "ws-123",
+			OrgID:          models.OrgID("org-456"),
+			DisconnectedAt: &disconnectedAt,
+		}
+
+		// Configure expectations
+		mockOrganizationsService.On("GetAllOrganizations", ctx).
+			Return([]*models.Organization{{ID: "org-456"}}, nil)
+		mockAgentsService.On("GetDisconnectedAgents", ctx, models.OrgID("org-456"), gracePeriod).
+			Return([]*models.ActiveAgent{agent}, nil)
+		mockAgentsService.On("GetAgentByWSConnectionID", ctx, models.OrgID("org-456"), "ws-123").
+			Return(mo.Some(agent), nil)
+		mockAgentsService.On("GetActiveAgentJobAssignments", ctx, models.OrgID("org-456"), "agent-789").
+			Return([]string{}, nil)
+		mockAgentsService.On("DeleteActiveAgentByWsConnectionID", ctx, models.OrgID("org-456"), "ws-123").
+			Return(nil)
+
+		// Execute
+		err := useCase.DeregisterDisconnectedAgents(ctx, gracePeriod)
+
+		// Assert
+		assert.NoError(t, err)
+		mockOrganizationsService.AssertExpectations(t)
+		mockAgentsService.AssertExpectations(t)
+	})
+
+	t.Run("no_disconnected_agents", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		useCase := NewCoreUseCase(nil, mockAgentsService, nil, nil, mockOrganizationsService, nil, nil, nil)
+
+		// Configure expectations
+		mockOrganizationsService.On("GetAllOrganizations", ctx).
+			Return([]*models.Organization{{ID: "org-456"}}, nil)
+		mockAgentsService.On("GetDisconnectedAgents", ctx, models.OrgID("org-456"), 30*time.Second).
+			Return([]*models.ActiveAgent{}, nil)
+
+		// Execute
+		err := useCase.DeregisterDisconnectedAgents(ctx, 30*time.Second)
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertNotCalled(t, "DeleteActiveAgentByWsConnectionID", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestProcessPing(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		// Setup
@@ -423,6 +666,7 @@ func TestProcessPing(t *testing.T) {
 			mockJobsService,
 			mockSlackIntegrationsService,
 			mockOrganizationsService,
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
@@ -442,11 +686,13 @@ func TestProcessPing(t *testing.T) {
 		// Configure expectations
 		mockAgentsService.On("GetAgentByWSConnectionID", ctx, models.OrgID("org-456"), "ws-123").
 			Return(mo.Some(agent), nil)
-		mockAgentsService.On("UpdateAgentLastActiveAt", ctx, models.OrgID("org-456"), "ws-123").
+		rttMs := int64(42)
+		telemetry := models.AgentTelemetry{Version: "1.2.3", RoundTripTimeMs: &rttMs}
+		mockAgentsService.On("UpdateAgentTelemetry", ctx, models.OrgID("org-456"), "ws-123", telemetry).
 			Return(nil)
 
 		// Execute
-		err := useCase.ProcessPing(ctx, client)
+		err := useCase.ProcessPing(ctx, client, telemetry)
 
 		// Assert
 		assert.NoError(t, err)
@@ -468,6 +714,7 @@ func TestProcessPing(t *testing.T) {
 			mockJobsService,
 			mockSlackIntegrationsService,
 			mockOrganizationsService,
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
@@ -483,7 +730,7 @@ func TestProcessPing(t *testing.T) {
 			Return(mo.None[*models.ActiveAgent](), nil)
 
 		// Execute
-		err := useCase.ProcessPing(ctx, client)
+		err := useCase.ProcessPing(ctx, client, models.AgentTelemetry{})
 
 		// Assert
 		assert.Error(t, err)
@@ -492,128 +739,1000 @@ func TestProcessPing(t *testing.T) {
 	})
 }
 
-func TestCleanupInactiveAgents(t *testing.T) {
-	t.Run("no_integrations", func(t *testing.T) {
+func TestProcessMessageAck(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
 		// Setup
 		ctx := context.Background()
 		mockAgentsService := new(agents.MockAgentsService)
-		mockWSClient := new(socketio.MockSocketIOClient)
-		mockJobsService := new(jobs.MockJobsService)
-		mockSlackIntegrationsService := new(slackintegrations.MockSlackIntegrationsService)
-		mockOrganizationsService := new(organizations.MockOrganizationsService)
-		// Pass nil for use cases that aren't used in this test
 		useCase := NewCoreUseCase(
-			mockWSClient,
+			new(socketio.MockSocketIOClient),
 			mockAgentsService,
-			mockJobsService,
-			mockSlackIntegrationsService,
-			mockOrganizationsService,
+			new(jobs.MockJobsService),
+			new(slackintegrations.MockSlackIntegrationsService),
+			new(organizations.MockOrganizationsService),
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
 
+		client := &clients.Client{
+			ID:    "ws-123",
+			OrgID: models.OrgID("org-456"),
+		}
+
 		// Configure expectations
-		mockOrganizationsService.On("GetAllOrganizations", ctx).
-			Return([]*models.Organization{}, nil)
+		mockAgentsService.On("MarkAgentMessageDelivered", ctx, models.OrgID("org-456"), "msg-001").
+			Return(&models.AgentOutboxMessage{
+				ID:          "msg-001",
+				JobID:       "job-111",
+				MessageType: models.MessageTypeUserMessage,
+			}, nil)
 
 		// Execute
-		err := useCase.CleanupInactiveAgents(ctx)
+		err := useCase.ProcessMessageAck(ctx, client, "msg-001")
 
 		// Assert
 		assert.NoError(t, err)
-		mockOrganizationsService.AssertExpectations(t)
+		mockAgentsService.AssertExpectations(t)
 	})
 
-	t.Run("multiple_inactive_agents", func(t *testing.T) {
-		// Setup
+	t.Run("message_not_tracked_in_outbox", func(t *testing.T) {
+		// Setup - e.g. an acknowledged CheckIdleJobs message or a duplicate acknowledgement
 		ctx := context.Background()
 		mockAgentsService := new(agents.MockAgentsService)
-		mockWSClient := new(socketio.MockSocketIOClient)
-		mockJobsService := new(jobs.MockJobsService)
-		mockSlackIntegrationsService := new(slackintegrations.MockSlackIntegrationsService)
-		mockOrganizationsService := new(organizations.MockOrganizationsService)
-		// Pass nil for use cases that aren't used in this test
 		useCase := NewCoreUseCase(
-			mockWSClient,
+			new(socketio.MockSocketIOClient),
 			mockAgentsService,
-			mockJobsService,
-			mockSlackIntegrationsService,
-			mockOrganizationsService,
+			new(jobs.MockJobsService),
+			new(slackintegrations.MockSlackIntegrationsService),
+			new(organizations.MockOrganizationsService),
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
 
-		organization := &models.Organization{
-			ID: "org-456",
-		}
-
-		now := time.Now()
-		inactiveAgent1 := &models.ActiveAgent{
-			ID:           "agent-001",
-			OrgID:        models.OrgID("org-456"),
-			LastActiveAt: now.Add(-20 * time.Minute),
-		}
-		inactiveAgent2 := &models.ActiveAgent{
-			ID:           "agent-002",
-			OrgID:        models.OrgID("org-456"),
-			LastActiveAt: now.Add(-30 * time.Minute),
+		client := &clients.Client{
+			ID:    "ws-123",
+			OrgID: models.OrgID("org-456"),
 		}
 
 		// Configure expectations
-		mockOrganizationsService.On("GetAllOrganizations", ctx).
-			Return([]*models.Organization{organization}, nil)
-		mockAgentsService.On("GetInactiveAgents", ctx, models.OrgID("org-456"), DefaultInactiveAgentTimeoutMinutes).
-			Return([]*models.ActiveAgent{inactiveAgent1, inactiveAgent2}, nil)
-		mockAgentsService.On("DeleteActiveAgent", ctx, models.OrgID("org-456"), "agent-001").
-			Return(nil)
-		mockAgentsService.On("DeleteActiveAgent", ctx, models.OrgID("org-456"), "agent-002").
-			Return(nil)
+		mockAgentsService.On("MarkAgentMessageDelivered", ctx, models.OrgID("org-456"), "msg-001").
+			Return(nil, core.ErrNotFound)
 
 		// Execute
-		err := useCase.CleanupInactiveAgents(ctx)
+		err := useCase.ProcessMessageAck(ctx, client, "msg-001")
 
 		// Assert
 		assert.NoError(t, err)
-		mockOrganizationsService.AssertExpectations(t)
 		mockAgentsService.AssertExpectations(t)
 	})
-}
 
-// Background Processing Tests
-func TestBroadcastCheckIdleJobs(t *testing.T) {
-	t.Run("success_single_organization", func(t *testing.T) {
+	t.Run("cancel_job_acknowledgement_confirms_cancellation", func(t *testing.T) {
 		// Setup
 		ctx := context.Background()
 		mockAgentsService := new(agents.MockAgentsService)
-		mockWSClient := new(socketio.MockSocketIOClient)
 		mockJobsService := new(jobs.MockJobsService)
-		mockSlackIntegrationsService := new(slackintegrations.MockSlackIntegrationsService)
-		mockOrganizationsService := new(organizations.MockOrganizationsService)
-
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
 		useCase := NewCoreUseCase(
-			mockWSClient,
+			new(socketio.MockSocketIOClient),
 			mockAgentsService,
 			mockJobsService,
-			mockSlackIntegrationsService,
-			mockOrganizationsService,
-			nil, // slackUseCase
+			new(slackintegrations.MockSlackIntegrationsService),
+			new(organizations.MockOrganizationsService),
+			nil, // settingsService
+			mockSlackUseCase,
 			nil, // discordUseCase
 		)
 
-		organization := &models.Organization{
-			ID: "org-456",
-		}
-
-		agent1 := &models.ActiveAgent{
-			ID:             "agent-001",
-			WSConnectionID: "ws-001",
-			OrgID:          models.OrgID("org-456"),
+		client := &clients.Client{
+			ID:    "ws-123",
+			OrgID: models.OrgID("org-456"),
 		}
-
-		agent2 := &models.ActiveAgent{
-			ID:             "agent-002",
-			WSConnectionID: "ws-002",
-			OrgID:          models.OrgID("org-456"),
+		job := &models.Job{
+			ID:      "job-111",
+			JobType: models.JobTypeSlack,
+			OrgID:   models.OrgID("org-456"),
+		}
+
+		// Configure expectations
+		mockAgentsService.On("MarkAgentMessageDelivered", ctx, models.OrgID("org-456"), "msg-001").
+			Return(&models.AgentOutboxMessage{
+				ID:          "msg-001",
+				JobID:       "job-111",
+				MessageType: models.MessageTypeCancelJob,
+			}, nil)
+		mockJobsService.On("GetJobByID", ctx, models.OrgID("org-456"), "job-111").
+			Return(mo.Some(job), nil)
+		mockSlackUseCase.On("ProcessJobCancelled", ctx, job).Return(nil)
+
+		// Execute
+		err := useCase.ProcessMessageAck(ctx, client, "msg-001")
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+		mockJobsService.AssertExpectations(t)
+		mockSlackUseCase.AssertExpectations(t)
+	})
+}
+
+func TestProcessMessageDeliveryFailure(t *testing.T) {
+	t.Run("records_failure_of_pending_message", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		useCase := NewCoreUseCase(nil, mockAgentsService, nil, nil, nil, nil, nil, nil)
+
+		client := &clients.Client{ID: "ws-123", OrgID: models.OrgID("org-456")}
+		reason := fmt.Errorf("cannot send start_conversation_v1: %w", core.ErrUnsupportedMessageType)
+
+		// Configure expectations
+		mockAgentsService.On("RecordAgentMessageDeliveryFailure", ctx, models.OrgID("org-456"), "msg-123", reason).
+			Return(nil)
+
+		// Execute
+		err := useCase.ProcessMessageDeliveryFailure(ctx, client, "msg-123", reason)
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+	})
+
+	t.Run("ignores_message_not_tracked_in_outbox", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		useCase := NewCoreUseCase(nil, mockAgentsService, nil, nil, nil, nil, nil, nil)
+
+		client := &clients.Client{ID: "ws-123", OrgID: models.OrgID("org-456")}
+		reason := fmt.Errorf("connection closed")
+
+		// Configure expectations
+		mockAgentsService.On("RecordAgentMessageDeliveryFailure", ctx, models.OrgID("org-456"), "msg-123", reason).
+			Return(core.ErrNotFound)
+
+		// Execute
+		err := useCase.ProcessMessageDeliveryFailure(ctx, client, "msg-123", reason)
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+	})
+}
+
+func TestPrepareAgentMessage(t *testing.T) {
+	t.Run("downloads_attachments_from_their_source", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+		mockDiscordUseCase := new(discordusecase.MockDiscordUseCase)
+		useCase := NewCoreUseCase(nil, nil, nil, nil, nil, nil, mockSlackUseCase, mockDiscordUseCase)
+
+		client := &clients.Client{ID: "ws-123", OrgID: models.OrgID("org-456")}
+		slackSource := models.AttachmentSource{Platform: models.JobTypeSlack, IntegrationID: "si-1", URL: "https://slack/a"}
+		discordSource := models.AttachmentSource{Platform: models.JobTypeDiscord, IntegrationID: "di-1", URL: "https://cdn/b"}
+		msg := models.BaseMessage{
+			ID:   "msg-123",
+			Type: models.MessageTypeUserMessage,
+			Payload: models.UserMessagePayload{
+				JobID:   "job-111",
+				Message: "See attached",
+				Attachments: []models.AgentAttachment{
+					{Name: "a.png", MimeType: "image/png", Source: &slackSource},
+					{Name: "b.log", MimeType: "text/plain", Source: &discordSource},
+				},
+			},
+		}
+
+		// Configure expectations - the Discord download fails and is skipped
+		mockSlackUseCase.On("DownloadAttachment", ctx, slackSource).Return([]byte("png bytes"), nil)
+		mockDiscordUseCase.On("DownloadAttachment", ctx, discordSource).Return(nil, fmt.Errorf("expired"))
+
+		// Execute
+		prepared, err := useCase.PrepareAgentMessage(ctx, client, msg)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, models.BaseMessage{
+			ID:   "msg-123",
+			Type: models.MessageTypeUserMessage,
+			Payload: models.UserMessagePayload{
+				JobID:   "job-111",
+				Message: "See attached",
+				Attachments: []models.AgentAttachment{
+					{Name: "a.png", MimeType: "image/png", Content: []byte("png bytes")},
+				},
+			},
+		}, prepared)
+		mockSlackUseCase.AssertExpectations(t)
+		mockDiscordUseCase.AssertExpectations(t)
+	})
+
+	t.Run("downloads_attachments_of_relayed_message", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+		useCase := NewCoreUseCase(nil, nil, nil, nil, nil, nil, mockSlackUseCase, nil)
+
+		client := &clients.Client{ID: "ws-123", OrgID: models.OrgID("org-456")}
+		source := models.AttachmentSource{Platform: models.JobTypeSlack, IntegrationID: "si-1", URL: "https://slack/a"}
+		// Messages relayed from another replica arrive decoded from JSON
+		msg := map[string]any{
+			"id":   "msg-123",
+			"type": models.MessageTypeStartConversation,
+			"payload": map[string]any{
+				"job_id": "job-111",
+				"attachments": []any{
+					map[string]any{
+						"name":      "a.png",
+						"mime_type": "image/png",
+						"source": map[string]any{
+							"platform":       "slack",
+							"integration_id": "si-1",
+							"url":            "https://slack/a",
+						},
+					},
+				},
+			},
+		}
+
+		// Configure expectations
+		mockSlackUseCase.On("DownloadAttachment", ctx, source).Return([]byte("png bytes"), nil)
+
+		// Execute
+		prepared, err := useCase.PrepareAgentMessage(ctx, client, msg)
+
+		// Assert
+		assert.NoError(t, err)
+		preparedMessage, ok := prepared.(models.BaseMessage)
+		assert.True(t, ok)
+		assert.Equal(t, "msg-123", preparedMessage.ID)
+		assert.Equal(t, models.StartConversationPayload{
+			JobID:       "job-111",
+			Attachments: []models.AgentAttachment{{Name: "a.png", MimeType: "image/png", Content: []byte("png bytes")}},
+		}, preparedMessage.Payload)
+		mockSlackUseCase.AssertExpectations(t)
+	})
+
+	t.Run("passes_through_message_without_attachment_sources", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+		useCase := NewCoreUseCase(nil, nil, nil, nil, nil, nil, mockSlackUseCase, nil)
+
+		client := &clients.Client{ID: "ws-123", OrgID: models.OrgID("org-456")}
+		msg := models.BaseMessage{
+			ID:      "msg-123",
+			Type:    models.MessageTypeUserMessage,
+			Payload: models.UserMessagePayload{JobID: "job-111", Message: "Hello"},
+		}
+
+		// Execute
+		prepared, err := useCase.PrepareAgentMessage(ctx, client, msg)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, msg, prepared)
+		mockSlackUseCase.AssertNotCalled(t, "DownloadAttachment", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProcessArtifact(t *testing.T) {
+	t.Run("routes_artifact_to_slack", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockJobsService := new(jobs.MockJobsService)
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+		useCase := NewCoreUseCase(
+			new(socketio.MockSocketIOClient),
+			new(agents.MockAgentsService),
+			mockJobsService,
+			new(slackintegrations.MockSlackIntegrationsService),
+			new(organizations.MockOrganizationsService),
+			nil, // settingsService
+			mockSlackUseCase,
+			nil, // discordUseCase
+		)
+
+		job := &models.Job{
+			ID:      "job-111",
+			JobType: models.JobTypeSlack,
+			OrgID:   models.OrgID("org-456"),
+		}
+		payload := models.ArtifactPayload{
+			JobID:      "job-111",
+			Attachment: models.AgentAttachment{Name: "fix.diff", Content: []byte("diff")},
+		}
+
+		// Configure expectations
+		mockJobsService.On("GetJobByID", ctx, models.OrgID("org-456"), "job-111").
+			Return(mo.Some(job), nil)
+		mockSlackUseCase.On("ProcessArtifact", ctx, "ws-123", payload, models.OrgID("org-456")).Return(nil)
+
+		// Execute
+		err := useCase.ProcessArtifact(ctx, "ws-123", payload, models.OrgID("org-456"))
+
+		// Assert
+		assert.NoError(t, err)
+		mockJobsService.AssertExpectations(t)
+		mockSlackUseCase.AssertExpectations(t)
+	})
+
+	t.Run("rejects_artifact_over_size_limit", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockJobsService := new(jobs.MockJobsService)
+		useCase := NewCoreUseCase(
+			new(socketio.MockSocketIOClient),
+			new(agents.MockAgentsService),
+			mockJobsService,
+			new(slackintegrations.MockSlackIntegrationsService),
+			new(organizations.MockOrganizationsService),
+			nil, // settingsService
+			new(slackusecase.MockSlackUseCase),
+			nil, // discordUseCase
+		)
+
+		payload := models.ArtifactPayload{
+			JobID: "job-111",
+			Attachment: models.AgentAttachment{
+				Name:    "huge.bin",
+				Content: make([]byte, models.MaxAttachmentSize+1),
+			},
+		}
+
+		// Execute
+		err := useCase.ProcessArtifact(ctx, "ws-123", payload, models.OrgID("org-456"))
+
+		// Assert
+		assert.ErrorIs(t, err, core.ErrInvalidPayload)
+		assert.Contains(t, err.Error(), "larger than")
+		mockJobsService.AssertNotCalled(t, "GetJobByID", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestProcessRejectedMessage(t *testing.T) {
+	client := &clients.Client{
+		ID:    "ws-123",
+		OrgID: models.OrgID("org-456"),
+	}
+	isErrorMessage := func(code models.AgentErrorCode) any {
+		return mock.MatchedBy(func(msg models.BaseMessage) bool {
+			payload, ok := msg.Payload.(models.ErrorPayload)
+			return ok && msg.Type == models.MessageTypeError &&
+				payload.MessageID == "msg-001" && payload.JobID == "job-111" && payload.Code == code
+		})
+	}
+
+	t.Run("job_not_found_is_reported_to_agent", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockWSClient := new(socketio.MockSocketIOClient)
+		useCase := NewCoreUseCase(
+			mockWSClient,
+			new(agents.MockAgentsService),
+			new(jobs.MockJobsService),
+			new(slackintegrations.MockSlackIntegrationsService),
+			new(organizations.MockOrganizationsService),
+			nil, // settingsService
+			nil, // slackUseCase
+			nil, // discordUseCase
+		)
+
+		// Configure expectations
+		mockWSClient.On("SendMessage", "ws-123", isErrorMessage(models.AgentErrorCodeJobNotFound)).Return(nil)
+
+		// Execute
+		rejection := fmt.Errorf("%w: job-111", core.ErrJobNotFound)
+		err := useCase.ProcessRejectedMessage(ctx, client, "msg-001", "job-111", rejection)
+
+		// Assert - the agent was told, so there is nothing to alert on
+		assert.NoError(t, err)
+		mockWSClient.AssertExpectations(t)
+	})
+
+	t.Run("not_assigned_is_reported_to_agent_and_returned", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockWSClient := new(socketio.MockSocketIOClient)
+		useCase := NewCoreUseCase(
+			mockWSClient,
+			new(agents.MockAgentsService),
+			new(jobs.MockJobsService),
+			new(slackintegrations.MockSlackIntegrationsService),
+			new(organizations.MockOrganizationsService),
+			nil, // settingsService
+			nil, // slackUseCase
+			nil, // discordUseCase
+		)
+
+		// Configure expectations
+		mockWSClient.On("SendMessage", "ws-123", isErrorMessage(models.AgentErrorCodeNotAssigned)).Return(nil)
+
+		// Execute
+		rejection := fmt.Errorf("agent ag-1 is not assigned to job job-111: %w", core.ErrJobNotAssigned)
+		err := useCase.ProcessRejectedMessage(ctx, client, "msg-001", "job-111", rejection)
+
+		// Assert
+		assert.ErrorIs(t, err, core.ErrJobNotAssigned)
+		mockWSClient.AssertExpectations(t)
+	})
+
+	t.Run("invalid_payload_is_counted_and_reported_even_if_agent_cannot_receive_it", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockWSClient := new(socketio.MockSocketIOClient)
+		mockAgentsService := new(agents.MockAgentsService)
+		useCase := NewCoreUseCase(
+			mockWSClient,
+			mockAgentsService,
+			new(jobs.MockJobsService),
+			new(slackintegrations.MockSlackIntegrationsService),
+			new(organizations.MockOrganizationsService),
+			nil, // settingsService
+			nil, // slackUseCase
+			nil, // discordUseCase
+		)
+
+		// Configure expectations
+		mockAgentsService.On("IncrementAgentValidationFailures", ctx, client.OrgID, "ws-123").Return(nil)
+		mockWSClient.On("SendMessage", "ws-123", isErrorMessage(models.AgentErrorCodeInvalidPayload)).
+			Return(fmt.Errorf("failed to send: %w", core.ErrUnsupportedMessageType))
+
+		// Execute
+		rejection := fmt.Errorf("%w: JobID is empty in ProgressEvent payload", core.ErrInvalidPayload)
+		err := useCase.ProcessRejectedMessage(ctx, client, "msg-001", "job-111", rejection)
+
+		// Assert
+		assert.ErrorIs(t, err, core.ErrInvalidPayload)
+		mockWSClient.AssertExpectations(t)
+		mockAgentsService.AssertExpectations(t)
+	})
+
+	t.Run("other_errors_are_not_reported_to_agent", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockWSClient := new(socketio.MockSocketIOClient)
+		useCase := NewCoreUseCase(
+			mockWSClient,
+			new(agents.MockAgentsService),
+			new(jobs.MockJobsService),
+			new(slackintegrations.MockSlackIntegrationsService),
+			new(organizations.MockOrganizationsService),
+			nil, // settingsService
+			nil, // slackUseCase
+			nil, // discordUseCase
+		)
+
+		// Execute
+		rejection := fmt.Errorf("failed to get job: connection refused")
+		err := useCase.ProcessRejectedMessage(ctx, client, "msg-001", "job-111", rejection)
+
+		// Assert
+		assert.Equal(t, rejection, err)
+		mockWSClient.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+	})
+}
+
+func TestClaimInboundMessage(t *testing.T) {
+	client := &clients.Client{
+		ID:      "ws-123",
+		OrgID:   models.OrgID("org-456"),
+		AgentID: "ccaid-789",
+	}
+	newUseCase := func(mockAgentsService *agents.MockAgentsService) *CoreUseCase {
+		return NewCoreUseCase(
+			new(socketio.MockSocketIOClient),
+			mockAgentsService,
+			new(jobs.MockJobsService),
+			new(slackintegrations.MockSlackIntegrationsService),
+			new(organizations.MockOrganizationsService),
+			nil, // settingsService
+			nil, // slackUseCase
+			nil, // discordUseCase
+		)
+	}
+
+	t.Run("first_delivery_is_claimed", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		useCase := newUseCase(mockAgentsService)
+
+		// Configure expectations
+		mockAgentsService.On(
+			"ClaimAgentInboundMessage",
+			ctx,
+			client.OrgID,
+			"ccaid-789",
+			"msg-001",
+			models.MessageTypeAssistantMessage,
+		).Return(true, nil)
+
+		// Execute
+		claimed, err := useCase.ClaimInboundMessage(ctx, client, "msg-001", models.MessageTypeAssistantMessage)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		mockAgentsService.AssertExpectations(t)
+	})
+
+	t.Run("duplicate_is_skipped", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		useCase := newUseCase(mockAgentsService)
+
+		// Configure expectations
+		mockAgentsService.On(
+			"ClaimAgentInboundMessage",
+			ctx,
+			client.OrgID,
+			"ccaid-789",
+			"msg-001",
+			models.MessageTypeAssistantMessage,
+		).Return(false, nil)
+
+		// Execute
+		claimed, err := useCase.ClaimInboundMessage(ctx, client, "msg-001", models.MessageTypeAssistantMessage)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+		mockAgentsService.AssertExpectations(t)
+	})
+
+	t.Run("duplicate_of_message_in_progress_is_reported", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		useCase := newUseCase(mockAgentsService)
+
+		// Configure expectations
+		mockAgentsService.On(
+			"ClaimAgentInboundMessage",
+			ctx,
+			client.OrgID,
+			"ccaid-789",
+			"msg-001",
+			models.MessageTypeAssistantMessage,
+		).Return(false, core.ErrMessageInProgress)
+
+		// Execute
+		claimed, err := useCase.ClaimInboundMessage(ctx, client, "msg-001", models.MessageTypeAssistantMessage)
+
+		// Assert - the copy is left unacknowledged in case the original fails
+		assert.ErrorIs(t, err, core.ErrMessageInProgress)
+		assert.False(t, claimed)
+		mockAgentsService.AssertExpectations(t)
+	})
+
+	t.Run("processed_message_is_completed", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		useCase := newUseCase(mockAgentsService)
+
+		// Configure expectations
+		mockAgentsService.On("CompleteAgentInboundMessage", ctx, client.OrgID, "ccaid-789", "msg-001").Return(nil)
+
+		// Execute
+		err := useCase.CompleteInboundMessage(ctx, client, "msg-001")
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+	})
+
+	t.Run("message_without_id_is_always_processed", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		useCase := newUseCase(mockAgentsService)
+
+		// Execute
+		claimed, err := useCase.ClaimInboundMessage(ctx, client, "", models.MessageTypeAssistantMessage)
+		completeErr := useCase.CompleteInboundMessage(ctx, client, "")
+		releaseErr := useCase.ReleaseInboundMessage(ctx, client, "")
+
+		// Assert - the dedupe store is not touched
+		assert.NoError(t, err)
+		assert.NoError(t, completeErr)
+		assert.NoError(t, releaseErr)
+		assert.True(t, claimed)
+		mockAgentsService.AssertNotCalled(t, "ClaimAgentInboundMessage")
+		mockAgentsService.AssertNotCalled(t, "CompleteAgentInboundMessage")
+		mockAgentsService.AssertNotCalled(t, "ReleaseAgentInboundMessage")
+	})
+
+	t.Run("failed_message_is_released", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		useCase := newUseCase(mockAgentsService)
+
+		// Configure expectations
+		mockAgentsService.On("ReleaseAgentInboundMessage", ctx, client.OrgID, "ccaid-789", "msg-001").Return(nil)
+
+		// Execute
+		err := useCase.ReleaseInboundMessage(ctx, client, "msg-001")
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+	})
+}
+
+func TestRetryUndeliveredAgentMessages(t *testing.T) {
+	t.Run("redelivers_message_with_attempts_left", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		useCase := NewCoreUseCase(
+			new(socketio.MockSocketIOClient),
+			mockAgentsService,
+			new(jobs.MockJobsService),
+			new(slackintegrations.MockSlackIntegrationsService),
+			mockOrganizationsService,
+			nil, // settingsService
+			nil, // slackUseCase
+			nil, // discordUseCase
+		)
+
+		message := &models.AgentOutboxMessage{
+			ID:       "msg-001",
+			OrgID:    models.OrgID("org-456"),
+			AgentID:  "agent-789",
+			JobID:    "job-111",
+			Attempts: 1,
+		}
+
+		// Configure expectations
+		mockOrganizationsService.On("GetAllOrganizations", ctx).
+			Return([]*models.Organization{{ID: "org-456"}}, nil)
+		mockAgentsService.On("GetAgentMessagesDueForRetry", ctx, models.OrgID("org-456")).
+			Return([]*models.AgentOutboxMessage{message}, nil)
+		mockAgentsService.On("RedeliverAgentMessage", ctx, models.OrgID("org-456"), message).
+			Return(nil)
+
+		// Execute
+		err := useCase.RetryUndeliveredAgentMessages(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		mockOrganizationsService.AssertExpectations(t)
+		mockAgentsService.AssertExpectations(t)
+	})
+
+	t.Run("gives_up_and_requeues_job", func(t *testing.T) {
+		// Setup - two unacknowledged messages of the same job requeue it once
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockJobsService := new(jobs.MockJobsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+		useCase := NewCoreUseCase(
+			new(socketio.MockSocketIOClient),
+			mockAgentsService,
+			mockJobsService,
+			new(slackintegrations.MockSlackIntegrationsService),
+			mockOrganizationsService,
+			nil, // settingsService
+			mockSlackUseCase,
+			nil, // discordUseCase
+		)
+
+		messages := []*models.AgentOutboxMessage{
+			{
+				ID:       "msg-001",
+				OrgID:    models.OrgID("org-456"),
+				AgentID:  "agent-789",
+				JobID:    "job-111",
+				Attempts: models.MaxAgentMessageDeliveryAttempts,
+			},
+			{
+				ID:       "msg-002",
+				OrgID:    models.OrgID("org-456"),
+				AgentID:  "agent-789",
+				JobID:    "job-111",
+				Attempts: models.MaxAgentMessageDeliveryAttempts,
+			},
+		}
+		job := &models.Job{
+			ID:      "job-111",
+			JobType: models.JobTypeSlack,
+			OrgID:   models.OrgID("org-456"),
+			Status:  models.JobStatusActive,
+		}
+
+		// Configure expectations
+		mockOrganizationsService.On("GetAllOrganizations", ctx).
+			Return([]*models.Organization{{ID: "org-456"}}, nil)
+		mockAgentsService.On("GetAgentMessagesDueForRetry", ctx, models.OrgID("org-456")).
+			Return(messages, nil)
+		mockAgentsService.
+			On("MarkAgentMessageFailed", ctx, models.OrgID("org-456"), "msg-001", mock.AnythingOfType("string")).
+			Return(nil)
+		mockAgentsService.
+			On("MarkAgentMessageFailed", ctx, models.OrgID("org-456"), "msg-002", mock.AnythingOfType("string")).
+			Return(nil)
+		mockJobsService.On("GetJobByID", ctx, models.OrgID("org-456"), "job-111").
+			Return(mo.Some(job), nil).Once()
+		mockSlackUseCase.On("RequeueSlackJob", ctx, job, "agent-789", mock.AnythingOfType("string")).
+			Return(nil).Once()
+		mockSlackUseCase.On("ProcessQueuedJobs", ctx).Return(nil)
+
+		// Execute
+		err := useCase.RetryUndeliveredAgentMessages(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+		mockJobsService.AssertExpectations(t)
+		mockSlackUseCase.AssertExpectations(t)
+		mockAgentsService.AssertNotCalled(t, "RedeliverAgentMessage", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("gives_up_without_requeueing_cancelled_job", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockJobsService := new(jobs.MockJobsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+		useCase := NewCoreUseCase(
+			new(socketio.MockSocketIOClient),
+			mockAgentsService,
+			mockJobsService,
+			new(slackintegrations.MockSlackIntegrationsService),
+			mockOrganizationsService,
+			nil, // settingsService
+			mockSlackUseCase,
+			nil, // discordUseCase
+		)
+
+		message := &models.AgentOutboxMessage{
+			ID:       "msg-001",
+			OrgID:    models.OrgID("org-456"),
+			AgentID:  "agent-789",
+			JobID:    "job-111",
+			Attempts: models.MaxAgentMessageDeliveryAttempts,
+		}
+		job := &models.Job{
+			ID:      "job-111",
+			JobType: models.JobTypeSlack,
+			OrgID:   models.OrgID("org-456"),
+			Status:  models.JobStatusCancelled,
+		}
+
+		// Configure expectations
+		mockOrganizationsService.On("GetAllOrganizations", ctx).
+			Return([]*models.Organization{{ID: "org-456"}}, nil)
+		mockAgentsService.On("GetAgentMessagesDueForRetry", ctx, models.OrgID("org-456")).
+			Return([]*models.AgentOutboxMessage{message}, nil)
+		mockAgentsService.
+			On("MarkAgentMessageFailed", ctx, models.OrgID("org-456"), "msg-001", mock.AnythingOfType("string")).
+			Return(nil)
+		mockJobsService.On("GetJobByID", ctx, models.OrgID("org-456"), "job-111").
+			Return(mo.Some(job), nil)
+
+		// Execute
+		err := useCase.RetryUndeliveredAgentMessages(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+		mockJobsService.AssertExpectations(t)
+		mockSlackUseCase.AssertNotCalled(t, "RequeueSlackJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reports_unacknowledged_cancellation_in_thread", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockJobsService := new(jobs.MockJobsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+		useCase := NewCoreUseCase(
+			new(socketio.MockSocketIOClient),
+			mockAgentsService,
+			mockJobsService,
+			new(slackintegrations.MockSlackIntegrationsService),
+			mockOrganizationsService,
+			nil, // settingsService
+			mockSlackUseCase,
+			nil, // discordUseCase
+		)
+
+		cancelMessage := &models.AgentOutboxMessage{
+			ID:          "msg-001",
+			OrgID:       models.OrgID("org-456"),
+			AgentID:     "agent-789",
+			JobID:       "job-111",
+			MessageType: models.MessageTypeCancelJob,
+			Attempts:    models.MaxAgentMessageDeliveryAttempts,
+		}
+		otherCancelMessage := &models.AgentOutboxMessage{
+			ID:          "msg-002",
+			OrgID:       models.OrgID("org-456"),
+			AgentID:     "agent-789",
+			JobID:       "job-222",
+			MessageType: models.MessageTypeCancelJob,
+			Attempts:    models.MaxAgentMessageDeliveryAttempts,
+		}
+		job := &models.Job{
+			ID:      "job-111",
+			JobType: models.JobTypeSlack,
+			OrgID:   models.OrgID("org-456"),
+			Status:  models.JobStatusActive,
+		}
+		otherJob := &models.Job{
+			ID:      "job-222",
+			JobType: models.JobTypeSlack,
+			OrgID:   models.OrgID("org-456"),
+			Status:  models.JobStatusActive,
+		}
+
+		// Configure expectations
+		mockOrganizationsService.On("GetAllOrganizations", ctx).
+			Return([]*models.Organization{{ID: "org-456"}}, nil)
+		mockAgentsService.On("GetAgentMessagesDueForRetry", ctx, models.OrgID("org-456")).
+			Return([]*models.AgentOutboxMessage{cancelMessage, otherCancelMessage}, nil)
+		mockAgentsService.
+			On("MarkAgentMessageFailed", ctx, models.OrgID("org-456"), "msg-001", mock.AnythingOfType("string")).
+			Return(nil)
+		mockAgentsService.
+			On("MarkAgentMessageFailed", ctx, models.OrgID("org-456"), "msg-002", mock.AnythingOfType("string")).
+			Return(nil)
+		mockJobsService.On("GetJobByID", ctx, models.OrgID("org-456"), "job-111").
+			Return(mo.Some(job), nil)
+		mockJobsService.On("GetJobByID", ctx, models.OrgID("org-456"), "job-222").
+			Return(mo.Some(otherJob), nil)
+		mockSlackUseCase.On("ProcessJobCancellationUnconfirmed", ctx, job).
+			Return(fmt.Errorf("slack unavailable"))
+		mockSlackUseCase.On("ProcessJobCancellationUnconfirmed", ctx, otherJob).
+			Return(nil)
+
+		// Execute
+		err := useCase.RetryUndeliveredAgentMessages(ctx)
+
+		// Assert - a failed notice does not hold up the other messages
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+		mockJobsService.AssertExpectations(t)
+		mockSlackUseCase.AssertExpectations(t)
+		mockSlackUseCase.AssertNotCalled(t, "RequeueSlackJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCleanupInactiveAgents(t *testing.T) {
+	t.Run("no_integrations", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockWSClient := new(socketio.MockSocketIOClient)
+		mockJobsService := new(jobs.MockJobsService)
+		mockSlackIntegrationsService := new(slackintegrations.MockSlackIntegrationsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		// Pass nil for use cases that aren't used in this test
+		useCase := NewCoreUseCase(
+			mockWSClient,
+			mockAgentsService,
+			mockJobsService,
+			mockSlackIntegrationsService,
+			mockOrganizationsService,
+			nil, // settingsService
+			nil, // slackUseCase
+			nil, // discordUseCase
+		)
+
+		// Configure expectations
+		mockOrganizationsService.On("GetAllOrganizations", ctx).
+			Return([]*models.Organization{}, nil)
+
+		// Execute
+		err := useCase.CleanupInactiveAgents(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		mockOrganizationsService.AssertExpectations(t)
+	})
+
+	t.Run("multiple_inactive_agents", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockWSClient := new(socketio.MockSocketIOClient)
+		mockJobsService := new(jobs.MockJobsService)
+		mockSlackIntegrationsService := new(slackintegrations.MockSlackIntegrationsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		// Pass nil for use cases that aren't used in this test
+		useCase := NewCoreUseCase(
+			mockWSClient,
+			mockAgentsService,
+			mockJobsService,
+			mockSlackIntegrationsService,
+			mockOrganizationsService,
+			nil, // settingsService
+			nil, // slackUseCase
+			nil, // discordUseCase
+		)
+
+		organization := &models.Organization{
+			ID: "org-456",
+		}
+
+		now := time.Now()
+		inactiveAgent1 := &models.ActiveAgent{
+			ID:           "agent-001",
+			OrgID:        models.OrgID("org-456"),
+			LastActiveAt: now.Add(-20 * time.Minute),
+		}
+		inactiveAgent2 := &models.ActiveAgent{
+			ID:           "agent-002",
+			OrgID:        models.OrgID("org-456"),
+			LastActiveAt: now.Add(-30 * time.Minute),
+		}
+
+		// Configure expectations
+		mockOrganizationsService.On("GetAllOrganizations", ctx).
+			Return([]*models.Organization{organization}, nil)
+		mockAgentsService.On("GetInactiveAgents", ctx, models.OrgID("org-456"), DefaultInactiveAgentTimeoutMinutes).
+			Return([]*models.ActiveAgent{inactiveAgent1, inactiveAgent2}, nil)
+		mockAgentsService.On("DeleteActiveAgent", ctx, models.OrgID("org-456"), "agent-001").
+			Return(nil)
+		mockAgentsService.On("DeleteActiveAgent", ctx, models.OrgID("org-456"), "agent-002").
+			Return(nil)
+
+		// Execute
+		err := useCase.CleanupInactiveAgents(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		mockOrganizationsService.AssertExpectations(t)
+		mockAgentsService.AssertExpectations(t)
+	})
+}
+
+// Background Processing Tests
+func TestBroadcastCheckIdleJobs(t *testing.T) {
+	t.Run("success_single_organization", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockWSClient := new(socketio.MockSocketIOClient)
+		mockJobsService := new(jobs.MockJobsService)
+		mockSlackIntegrationsService := new(slackintegrations.MockSlackIntegrationsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+
+		useCase := NewCoreUseCase(
+			mockWSClient,
+			mockAgentsService,
+			mockJobsService,
+			mockSlackIntegrationsService,
+			mockOrganizationsService,
+			nil, // settingsService
+			nil, // slackUseCase
+			nil, // discordUseCase
+		)
+
+		organization := &models.Organization{
+			ID: "org-456",
+		}
+
+		agent1 := &models.ActiveAgent{
+			ID:             "agent-001",
+			WSConnectionID: "ws-001",
+			OrgID:          models.OrgID("org-456"),
+		}
+
+		agent2 := &models.ActiveAgent{
+			ID:             "agent-002",
+			WSConnectionID: "ws-002",
+			OrgID:          models.OrgID("org-456"),
 		}
 
 		// Configure expectations
@@ -647,6 +1766,63 @@ func TestBroadcastCheckIdleJobs(t *testing.T) {
 		mockAgentsService.AssertExpectations(t)
 	})
 
+	t.Run("skips_agents_without_check_idle_jobs_support", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockWSClient := new(socketio.MockSocketIOClient)
+		mockJobsService := new(jobs.MockJobsService)
+		mockSlackIntegrationsService := new(slackintegrations.MockSlackIntegrationsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+
+		useCase := NewCoreUseCase(
+			mockWSClient,
+			mockAgentsService,
+			mockJobsService,
+			mockSlackIntegrationsService,
+			mockOrganizationsService,
+			nil, // settingsService
+			nil, // slackUseCase
+			nil, // discordUseCase
+		)
+
+		organization := &models.Organization{
+			ID: "org-456",
+		}
+
+		agent1 := &models.ActiveAgent{
+			ID:             "agent-001",
+			WSConnectionID: "ws-001",
+			OrgID:          models.OrgID("org-456"),
+		}
+
+		agent2 := &models.ActiveAgent{
+			ID:             "agent-002",
+			WSConnectionID: "ws-002",
+			OrgID:          models.OrgID("org-456"),
+		}
+
+		// Configure expectations
+		mockOrganizationsService.On("GetAllOrganizations", ctx).
+			Return([]*models.Organization{organization}, nil)
+		mockWSClient.On("GetClientIDs").
+			Return([]string{"ws-001", "ws-002"})
+		mockAgentsService.On("GetConnectedActiveAgents", ctx, models.OrgID("org-456"), []string{"ws-001", "ws-002"}).
+			Return([]*models.ActiveAgent{agent1, agent2}, nil)
+		mockWSClient.On("SendMessage", "ws-001", mock.Anything).
+			Return(fmt.Errorf("cannot send check_idle_jobs_v1: %w", core.ErrUnsupportedMessageType))
+		mockWSClient.On("SendMessage", "ws-002", mock.Anything).Return(nil)
+
+		// Execute
+		err := useCase.BroadcastCheckIdleJobs(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		mockOrganizationsService.AssertExpectations(t)
+		mockWSClient.AssertExpectations(t)
+		mockAgentsService.AssertExpectations(t)
+	})
+
 	t.Run("no_organizations", func(t *testing.T) {
 		// Setup
 		ctx := context.Background()
@@ -662,6 +1838,7 @@ func TestBroadcastCheckIdleJobs(t *testing.T) {
 			mockJobsService,
 			mockSlackIntegrationsService,
 			mockOrganizationsService,
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
@@ -693,6 +1870,7 @@ func TestBroadcastCheckIdleJobs(t *testing.T) {
 			mockJobsService,
 			mockSlackIntegrationsService,
 			mockOrganizationsService,
+			nil, // settingsService
 			nil, // slackUseCase
 			nil, // discordUseCase
 		)
@@ -719,3 +1897,244 @@ func TestBroadcastCheckIdleJobs(t *testing.T) {
 		mockAgentsService.AssertExpectations(t)
 	})
 }
+
+func TestCloseIdleJobs(t *testing.T) {
+	newUseCase := func(
+		mockJobsService *jobs.MockJobsService,
+		mockOrganizationsService *organizations.MockOrganizationsService,
+		mockSettingsService *settingsservice.MockSettingsService,
+		mockSlackUseCase *slackusecase.MockSlackUseCase,
+		mockDiscordUseCase *discordusecase.MockDiscordUseCase,
+	) *CoreUseCase {
+		return NewCoreUseCase(
+			new(socketio.MockSocketIOClient),
+			new(agents.MockAgentsService),
+			mockJobsService,
+			new(slackintegrations.MockSlackIntegrationsService),
+			mockOrganizationsService,
+			mockSettingsService,
+			mockSlackUseCase,
+			mockDiscordUseCase,
+		)
+	}
+
+	t.Run("closes_idle_jobs_of_organizations_with_timeout", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockJobsService := new(jobs.MockJobsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		mockSettingsService := new(settingsservice.MockSettingsService)
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+		mockDiscordUseCase := new(discordusecase.MockDiscordUseCase)
+		useCase := newUseCase(
+			mockJobsService,
+			mockOrganizationsService,
+			mockSettingsService,
+			mockSlackUseCase,
+			mockDiscordUseCase,
+		)
+
+		slackJob := &models.Job{ID: "job-slack", JobType: models.JobTypeSlack, OrgID: models.OrgID("org-1")}
+		discordJob := &models.Job{ID: "job-discord", JobType: models.JobTypeDiscord, OrgID: models.OrgID("org-1")}
+		notice := "This job was closed automatically after being idle for 2h"
+
+		// Configure expectations - org-2 has no idle job timeout, so its jobs are left to its agents
+		mockOrganizationsService.On("GetAllOrganizations", ctx).
+			Return([]*models.Organization{{ID: "org-1"}, {ID: "org-2"}}, nil)
+		mockSettingsService.On("GetStringSetting", ctx, "org-1", models.IdleJobTimeoutSettingKey).Return("2h", nil)
+		mockSettingsService.On("GetStringSetting", ctx, "org-2", models.IdleJobTimeoutSettingKey).Return("", nil)
+		mockJobsService.On("GetIdleJobs", ctx, models.OrgID("org-1"), 120).
+			Return([]*models.Job{slackJob, discordJob}, nil)
+		mockSlackUseCase.On("CloseIdleSlackJob", ctx, slackJob, notice).Return(nil)
+		mockDiscordUseCase.On("CloseIdleDiscordJob", ctx, discordJob, notice).Return(nil)
+
+		// Execute
+		err := useCase.CloseIdleJobs(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		mockOrganizationsService.AssertExpectations(t)
+		mockSettingsService.AssertExpectations(t)
+		mockJobsService.AssertExpectations(t)
+		mockSlackUseCase.AssertExpectations(t)
+		mockDiscordUseCase.AssertExpectations(t)
+	})
+
+	t.Run("skips_organization_with_invalid_timeout", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockJobsService := new(jobs.MockJobsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		mockSettingsService := new(settingsservice.MockSettingsService)
+		useCase := newUseCase(mockJobsService, mockOrganizationsService, mockSettingsService, nil, nil)
+
+		// Configure expectations
+		mockOrganizationsService.On("GetAllOrganizations", ctx).
+			Return([]*models.Organization{{ID: "org-1"}}, nil)
+		mockSettingsService.On("GetStringSetting", ctx, "org-1", models.IdleJobTimeoutSettingKey).
+			Return("forever", nil)
+
+		// Execute
+		err := useCase.CloseIdleJobs(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		mockJobsService.AssertNotCalled(t, "GetIdleJobs", mock.Anything, mock.Anything, mock.Anything)
+		mockSettingsService.AssertExpectations(t)
+	})
+
+	t.Run("continues_when_closing_job_fails", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockJobsService := new(jobs.MockJobsService)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		mockSettingsService := new(settingsservice.MockSettingsService)
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+		useCase := newUseCase(mockJobsService, mockOrganizationsService, mockSettingsService, mockSlackUseCase, nil)
+
+		failingJob := &models.Job{ID: "job-failing", JobType: models.JobTypeSlack, OrgID: models.OrgID("org-1")}
+		slackJob := &models.Job{ID: "job-slack", JobType: models.JobTypeSlack, OrgID: models.OrgID("org-2")}
+
+		// Configure expectations - the job of org-2 is still closed after closing the job of org-1 failed
+		mockOrganizationsService.On("GetAllOrganizations", ctx).
+			Return([]*models.Organization{{ID: "org-1"}, {ID: "org-2"}}, nil)
+		mockSettingsService.On("GetStringSetting", ctx, "org-1", models.IdleJobTimeoutSettingKey).Return("30m", nil)
+		mockSettingsService.On("GetStringSetting", ctx, "org-2", models.IdleJobTimeoutSettingKey).Return("30m", nil)
+		mockJobsService.On("GetIdleJobs", ctx, models.OrgID("org-1"), 30).Return([]*models.Job{failingJob}, nil)
+		mockJobsService.On("GetIdleJobs", ctx, models.OrgID("org-2"), 30).Return([]*models.Job{slackJob}, nil)
+		mockSlackUseCase.On("CloseIdleSlackJob", ctx, failingJob, mock.Anything).Return(fmt.Errorf("slack is down"))
+		mockSlackUseCase.On("CloseIdleSlackJob", ctx, slackJob, mock.Anything).Return(nil)
+
+		// Execute
+		err := useCase.CloseIdleJobs(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		mockJobsService.AssertExpectations(t)
+		mockSlackUseCase.AssertExpectations(t)
+	})
+}
+
+func TestProcessStuckMessages(t *testing.T) {
+	orgID := models.OrgID("org-1")
+	connectedClientIDs := []string{"ws-stuck", "ws-healthy"}
+	stuckAgent := &models.ActiveAgent{
+		ID:             "agent-stuck",
+		WSConnectionID: "ws-stuck",
+		OrgID:          orgID,
+		LastActiveAt:   time.Now().Add(-time.Hour),
+	}
+
+	newUseCase := func(
+		mockAgentsService *agents.MockAgentsService,
+		mockJobsService *jobs.MockJobsService,
+		mockSlackUseCase *slackusecase.MockSlackUseCase,
+		mockDiscordUseCase *discordusecase.MockDiscordUseCase,
+	) *CoreUseCase {
+		mockWSClient := new(socketio.MockSocketIOClient)
+		mockWSClient.On("GetClientIDs").Return(connectedClientIDs)
+		mockOrganizationsService := new(organizations.MockOrganizationsService)
+		mockOrganizationsService.On("GetAllOrganizations", mock.Anything).
+			Return([]*models.Organization{{ID: string(orgID)}}, nil)
+
+		return NewCoreUseCase(
+			mockWSClient,
+			mockAgentsService,
+			mockJobsService,
+			new(slackintegrations.MockSlackIntegrationsService),
+			mockOrganizationsService,
+			nil, // settingsService
+			mockSlackUseCase,
+			mockDiscordUseCase,
+		)
+	}
+
+	t.Run("requeues_stuck_job_when_an_agent_matching_the_job_is_available", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockJobsService := new(jobs.MockJobsService)
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+		useCase := newUseCase(mockAgentsService, mockJobsService, mockSlackUseCase, nil)
+
+		job := &models.Job{ID: "job-slack", JobType: models.JobTypeSlack, OrgID: orgID}
+
+		// Configure expectations - the stuck agent is still connected but stopped pinging
+		mockJobsService.On("GetStuckJobs", ctx, orgID, DefaultStuckMessageTimeoutMinutes).
+			Return([]*models.Job{job}, nil)
+		mockAgentsService.On("GetAgentByJobID", ctx, orgID, job.ID).Return(mo.Some(stuckAgent), nil)
+		mockAgentsService.On("CheckAgentHasActiveConnection", stuckAgent, connectedClientIDs).Return(true)
+		mockSlackUseCase.On("HasAvailableAgentForSlackJob", ctx, job, mock.MatchedBy(func(activeSince time.Time) bool {
+			return stuckAgent.LastActiveAt.Before(activeSince)
+		})).Return(true, nil)
+		mockSlackUseCase.On("RequeueSlackJob", ctx, job, stuckAgent.ID, mock.AnythingOfType("string")).Return(nil)
+
+		// Execute
+		err := useCase.ProcessStuckMessages(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		mockJobsService.AssertExpectations(t)
+		mockAgentsService.AssertExpectations(t)
+		mockSlackUseCase.AssertExpectations(t)
+		mockSlackUseCase.AssertNotCalled(t, "FailStuckSlackJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails_stuck_messages_when_no_agent_matching_the_job_is_available", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockJobsService := new(jobs.MockJobsService)
+		mockDiscordUseCase := new(discordusecase.MockDiscordUseCase)
+		useCase := newUseCase(mockAgentsService, mockJobsService, nil, mockDiscordUseCase)
+
+		job := &models.Job{ID: "job-discord", JobType: models.JobTypeDiscord, OrgID: orgID}
+
+		// Configure expectations - the job's agent was already cleaned up and the connected agents work on
+		// other repositories
+		mockJobsService.On("GetStuckJobs", ctx, orgID, DefaultStuckMessageTimeoutMinutes).
+			Return([]*models.Job{job}, nil)
+		mockAgentsService.On("GetAgentByJobID", ctx, orgID, job.ID).Return(mo.None[*models.ActiveAgent](), nil)
+		mockDiscordUseCase.On("HasAvailableAgentForDiscordJob", ctx, job, mock.AnythingOfType("time.Time")).
+			Return(false, nil)
+		mockDiscordUseCase.On("FailStuckDiscordJob", ctx, job, "", mock.MatchedBy(func(explanation string) bool {
+			return explanation != ""
+		})).Return(nil)
+
+		// Execute
+		err := useCase.ProcessStuckMessages(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+		mockDiscordUseCase.AssertExpectations(t)
+		mockDiscordUseCase.AssertNotCalled(t, "RequeueDiscordJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("leaves_job_of_responsive_agent_alone", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockAgentsService := new(agents.MockAgentsService)
+		mockJobsService := new(jobs.MockJobsService)
+		mockSlackUseCase := new(slackusecase.MockSlackUseCase)
+		useCase := newUseCase(mockAgentsService, mockJobsService, mockSlackUseCase, nil)
+
+		job := &models.Job{ID: "job-slack", JobType: models.JobTypeSlack, OrgID: orgID}
+		workingAgent := &models.ActiveAgent{ID: "agent-working", WSConnectionID: "ws-stuck", LastActiveAt: time.Now()}
+
+		// Configure expectations
+		mockJobsService.On("GetStuckJobs", ctx, orgID, DefaultStuckMessageTimeoutMinutes).
+			Return([]*models.Job{job}, nil)
+		mockAgentsService.On("GetAgentByJobID", ctx, orgID, job.ID).Return(mo.Some(workingAgent), nil)
+		mockAgentsService.On("CheckAgentHasActiveConnection", workingAgent, connectedClientIDs).Return(true)
+
+		// Execute
+		err := useCase.ProcessStuckMessages(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		mockAgentsService.AssertExpectations(t)
+		mockSlackUseCase.AssertNotCalled(t, "RequeueSlackJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockSlackUseCase.AssertNotCalled(t, "FailStuckSlackJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -2,6 +2,7 @@ package discord
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 
@@ -23,6 +24,36 @@ func (m *MockDiscordUseCase) ProcessAssistantMessage(
 	return args.Error(0)
 }
 
+func (m *MockDiscordUseCase) ProcessAssistantDelta(
+	ctx context.Context,
+	clientID string,
+	payload models.AssistantDeltaPayload,
+	orgID models.OrgID,
+) error {
+	args := m.Called(ctx, clientID, payload, orgID)
+	return args.Error(0)
+}
+
+func (m *MockDiscordUseCase) ProcessProgressEvent(
+	ctx context.Context,
+	clientID string,
+	payload models.ProgressEventPayload,
+	orgID models.OrgID,
+) error {
+	args := m.Called(ctx, clientID, payload, orgID)
+	return args.Error(0)
+}
+
+func (m *MockDiscordUseCase) ProcessArtifact(
+	ctx context.Context,
+	clientID string,
+	payload models.ArtifactPayload,
+	orgID models.OrgID,
+) error {
+	args := m.Called(ctx, clientID, payload, orgID)
+	return args.Error(0)
+}
+
 func (m *MockDiscordUseCase) ProcessProcessingMessage(
 	ctx context.Context,
 	clientID string,
@@ -52,3 +83,93 @@ func (m *MockDiscordUseCase) CleanupFailedDiscordJob(
 	args := m.Called(ctx, job, agentID, failureMessage)
 	return args.Error(0)
 }
+
+func (m *MockDiscordUseCase) CloseIdleDiscordJob(ctx context.Context, job *models.Job, notice string) error {
+	args := m.Called(ctx, job, notice)
+	return args.Error(0)
+}
+
+func (m *MockDiscordUseCase) HasAvailableAgentForDiscordJob(
+	ctx context.Context,
+	job *models.Job,
+	activeSince time.Time,
+) (bool, error) {
+	args := m.Called(ctx, job, activeSince)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDiscordUseCase) FailStuckDiscordJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	explanation string,
+) error {
+	args := m.Called(ctx, job, agentID, explanation)
+	return args.Error(0)
+}
+
+func (m *MockDiscordUseCase) RequeueDiscordJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	notice string,
+) error {
+	args := m.Called(ctx, job, agentID, notice)
+	return args.Error(0)
+}
+
+func (m *MockDiscordUseCase) ProcessDiscordMessageEvent(
+	ctx context.Context,
+	event models.DiscordMessageEvent,
+	discordIntegrationID string,
+	orgID models.OrgID,
+) error {
+	args := m.Called(ctx, event, discordIntegrationID, orgID)
+	return args.Error(0)
+}
+
+func (m *MockDiscordUseCase) ProcessDiscordReactionEvent(
+	ctx context.Context,
+	event models.DiscordReactionEvent,
+	discordIntegrationID string,
+	orgID models.OrgID,
+) error {
+	args := m.Called(ctx, event, discordIntegrationID, orgID)
+	return args.Error(0)
+}
+
+func (m *MockDiscordUseCase) ProcessJobComplete(
+	ctx context.Context,
+	clientID string,
+	payload models.JobCompletePayload,
+	orgID models.OrgID,
+) error {
+	args := m.Called(ctx, clientID, payload, orgID)
+	return args.Error(0)
+}
+
+func (m *MockDiscordUseCase) ProcessQueuedJobs(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockDiscordUseCase) ProcessJobCancelled(ctx context.Context, job *models.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockDiscordUseCase) ProcessJobCancellationUnconfirmed(ctx context.Context, job *models.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockDiscordUseCase) DownloadAttachment(
+	ctx context.Context,
+	source models.AttachmentSource,
+) ([]byte, error) {
+	args := m.Called(ctx, source)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
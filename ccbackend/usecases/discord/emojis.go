@@ -8,6 +8,13 @@ const (
 	EmojiCheckMark  = "✅" // Completed successfully
 	EmojiRaisedHand = "✋" // Agent waiting for next steps
 	EmojiCrossMark  = "❌" // Error/failed status
+	EmojiNoEntry    = "🚫" // Cancelled by the job creator
+
+	// Priority emoji - added by the job creator to mark the job as urgent
+	EmojiFire = "🔥"
+
+	// Stop emoji - added by the job creator to stop the agent working on the job
+	EmojiStopSign = "🛑"
 
 	// System message prefix
 	EmojiGear = ":gear:" // System message indicator
@@ -22,5 +29,6 @@ var (
 		EmojiCheckMark,
 		EmojiRaisedHand,
 		EmojiCrossMark,
+		EmojiNoEntry,
 	}
 )
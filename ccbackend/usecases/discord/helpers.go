@@ -2,20 +2,76 @@ package discord
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
+
+	"github.com/samber/mo"
 
 	"ccbackend/clients"
 	"ccbackend/core"
 	"ccbackend/models"
+	"ccbackend/usecases/streaming"
 	"ccbackend/utils"
 )
 
+// getChannelRouting returns the default repository and agent selector configured for the job's channel
+// Returns an empty repository and a nil selector when the channel has none configured
+func (d *DiscordUseCase) getChannelRouting(
+	ctx context.Context,
+	orgID models.OrgID,
+	guildID string,
+	job *models.Job,
+) (string, models.AgentLabels, error) {
+	if job.DiscordPayload == nil {
+		return "", nil, fmt.Errorf("job has no Discord payload")
+	}
+
+	// Jobs live in threads, but the repository is configured on the parent channel
+	maybeChannel, err := d.connectedChannelsService.GetDiscordConnectedChannel(ctx, orgID, guildID, job.DiscordPayload.ChannelID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get Discord connected channel: %w", err)
+	}
+	if !maybeChannel.IsPresent() {
+		return "", nil, nil
+	}
+
+	channel := maybeChannel.MustGet()
+	repoURL := ""
+	if channel.DefaultRepoURL != nil {
+		repoURL = *channel.DefaultRepoURL
+	}
+	return repoURL, channel.AgentSelector, nil
+}
+
+// isOnlyQueuedMessage returns true if the message is the only queued message of its job, i.e. the job has just
+// started waiting for an agent - messages sent while it waits join the queue without another notice
+func (d *DiscordUseCase) isOnlyQueuedMessage(
+	ctx context.Context,
+	message *models.ProcessedDiscordMessage,
+) (bool, error) {
+	queuedMessages, err := d.discordMessagesService.GetProcessedMessagesByJobIDAndStatus(
+		ctx,
+		message.OrgID,
+		message.JobID,
+		models.ProcessedDiscordMessageStatusQueued,
+		message.DiscordIntegrationID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to get queued messages of job %s: %w", message.JobID, err)
+	}
+	return len(queuedMessages) == 1 && queuedMessages[0].ID == message.ID, nil
+}
+
+// sendStartConversationToAgent starts the job's conversation on the agent with the message as its prompt
+// A job handed over from another agent carries its earlier conversation as history.
 func (d *DiscordUseCase) sendStartConversationToAgent(
 	ctx context.Context,
 	clientID string,
 	message *models.ProcessedDiscordMessage,
+	history []models.ConversationTurn,
 ) error {
 	// Get job to access thread information
 	maybeJob, err := d.jobsService.GetJobByID(ctx, message.OrgID, message.JobID)
@@ -69,10 +125,21 @@ func (d *DiscordUseCase) sendStartConversationToAgent(
 			Message:            message.TextContent,
 			ProcessedMessageID: message.ID,
 			MessageLink:        messageLink,
+			Attachments:        message.Attachments.ForAgent(models.JobTypeDiscord, message.DiscordIntegrationID),
+			History:            history,
 		},
 	}
 
-	if err := d.wsClient.SendMessage(clientID, startConversationMessage); err != nil {
+	// Sent through the agent outbox which resends it until the agent acknowledges it
+	err = d.agentsService.SendMessageToAgent(
+		ctx,
+		message.OrgID,
+		clientID,
+		message.JobID,
+		message.ID,
+		startConversationMessage,
+	)
+	if err != nil {
 		return fmt.Errorf("failed to send start conversation message to client %s: %v", clientID, err)
 	}
 	log.Printf("🚀 Sent start conversation message to client %s", clientID)
@@ -130,10 +197,19 @@ func (d *DiscordUseCase) sendUserMessageToAgent(
 			Message:            message.TextContent,
 			ProcessedMessageID: message.ID,
 			MessageLink:        messageLink,
+			Attachments:        message.Attachments.ForAgent(models.JobTypeDiscord, message.DiscordIntegrationID),
 		},
 	}
 
-	if err := d.wsClient.SendMessage(clientID, userMessage); err != nil {
+	err = d.agentsService.SendMessageToAgent(
+		ctx,
+		message.OrgID,
+		clientID,
+		message.JobID,
+		message.ID,
+		userMessage,
+	)
+	if err != nil {
 		return fmt.Errorf("failed to send user message to client %s: %v", clientID, err)
 	}
 	log.Printf("💬 Sent user message to client %s", clientID)
@@ -168,6 +244,16 @@ func (d *DiscordUseCase) sendDiscordMessage(
 ) error {
 	log.Printf("📋 Starting to send message to channel %s, thread %s: %s", channelID, threadID, message)
 
+	if _, err := d.postDiscordMessage(channelID, threadID, message); err != nil {
+		return err
+	}
+
+	log.Printf("📋 Completed successfully - sent message to channel %s, thread %s", channelID, threadID)
+	return nil
+}
+
+// postDiscordMessage sends a message to a Discord channel or thread and returns its message ID
+func (d *DiscordUseCase) postDiscordMessage(channelID, threadID, message string) (string, error) {
 	// Trim message to Discord's 2000 character limit
 	trimmedMessage := trimDiscordMessage(message)
 
@@ -187,15 +273,118 @@ func (d *DiscordUseCase) sendDiscordMessage(
 	if threadID != "" && threadID != channelID {
 		params.ThreadID = &threadID
 	}
-	_, err := d.discordClient.PostMessage(channelID, params)
+	response, err := d.discordClient.PostMessage(channelID, params)
 	if err != nil {
-		return fmt.Errorf("failed to send message to Discord: %w", err)
+		return "", fmt.Errorf("failed to send message to Discord: %w", err)
 	}
 
-	log.Printf("📋 Completed successfully - sent message to channel %s, thread %s", channelID, threadID)
+	return response.MessageID, nil
+}
+
+// updateDiscordMessage replaces the content of a message the bot posted, trimmed to Discord's limit
+func (d *DiscordUseCase) updateDiscordMessage(channelID, messageID, message string) error {
+	if err := d.discordClient.UpdateMessage(channelID, messageID, trimDiscordMessage(message)); err != nil {
+		return fmt.Errorf("failed to update Discord message: %w", err)
+	}
 	return nil
 }
 
+// getAgentJob returns the job an agent's message is about together with the agent,
+// or None if the job no longer exists
+// Returns an error if the agent is not assigned to the job.
+func (d *DiscordUseCase) getAgentJob(
+	ctx context.Context,
+	clientID, jobID string,
+	orgID models.OrgID,
+) (mo.Option[*models.Job], *models.ActiveAgent, error) {
+	maybeAgent, err := d.agentsService.GetAgentByWSConnectionID(ctx, orgID, clientID)
+	if err != nil {
+		return mo.None[*models.Job](), nil, fmt.Errorf("failed to find agent for client: %w", err)
+	}
+	agent, ok := maybeAgent.Get()
+	if !ok {
+		return mo.None[*models.Job](), nil, fmt.Errorf("no agent found for client: %s", clientID)
+	}
+
+	maybeJob, err := d.jobsService.GetJobByID(ctx, orgID, jobID)
+	if err != nil {
+		return mo.None[*models.Job](), nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	job, ok := maybeJob.Get()
+	if !ok {
+		return mo.None[*models.Job](), nil, nil
+	}
+	if job.DiscordPayload == nil {
+		return mo.None[*models.Job](), nil, fmt.Errorf("job has no Discord payload")
+	}
+
+	// Validate that this agent is actually assigned to this job
+	if err := d.agentsUseCase.ValidateJobBelongsToAgent(ctx, agent.ID, job.ID, orgID); err != nil {
+		return mo.None[*models.Job](), nil, err
+	}
+
+	return mo.Some(job), agent, nil
+}
+
+// recordTranscriptMessage adds a message exchanged in the job's thread to the job's transcript
+// Failures are only logged - the transcript is history and must not hold up the conversation.
+func (d *DiscordUseCase) recordTranscriptMessage(
+	ctx context.Context,
+	job *models.Job,
+	author models.TranscriptAuthor,
+	authorID string,
+	messageType models.TranscriptMessageType,
+	content string,
+	processedMessageID string,
+) {
+	_, err := d.jobsService.AddJobTranscriptMessage(
+		ctx,
+		job.OrgID,
+		job.ID,
+		author,
+		authorID,
+		messageType,
+		content,
+		processedMessageID,
+	)
+	if err != nil {
+		log.Printf("⚠️ Failed to record %s in transcript of job %s: %v", messageType, job.ID, err)
+	}
+}
+
+// renderStreamUpdate posts or edits the stream's message in the job's Discord thread
+func (d *DiscordUseCase) renderStreamUpdate(
+	job *models.Job,
+	streams *streaming.Tracker,
+	key string,
+	update streaming.Update,
+) error {
+	threadID := job.DiscordPayload.ThreadID
+	switch update.Action {
+	case streaming.UpdateActionPost:
+		messageID, err := d.postDiscordMessage(threadID, threadID, update.Text)
+		streams.Posted(key, messageID)
+		return err
+	case streaming.UpdateActionEdit:
+		return d.updateDiscordMessage(threadID, update.MessageID, update.Text)
+	default:
+		return nil
+	}
+}
+
+// finishProgressStatus renders the latest steps of the job's status message before the agent's reply
+// is posted, so the next steps start a new status message below the reply
+func (d *DiscordUseCase) finishProgressStatus(ctx context.Context, job *models.Job) {
+	status, ok := d.progressStatuses.Finish(ctx, job.ID)
+	if !ok || status.MessageID == "" || !status.Outdated() {
+		return
+	}
+
+	if err := d.updateDiscordMessage(job.DiscordPayload.ThreadID, status.MessageID, status.Text); err != nil {
+		log.Printf("⚠️ Failed to update progress status of job %s: %v", job.ID, err)
+	}
+}
+
 func (d *DiscordUseCase) sendSystemMessage(
 	ctx context.Context,
 	discordIntegrationID, guildID, channelID, threadID, message string,
@@ -209,6 +398,233 @@ func (d *DiscordUseCase) sendSystemMessage(
 	return d.sendDiscordMessage(ctx, discordIntegrationID, guildID, channelID, threadID, systemMessage)
 }
 
+// raiseJobPriority raises the job's priority and confirms it in the job's thread
+// Nothing is posted if the job already had the same or a higher priority
+func (d *DiscordUseCase) raiseJobPriority(
+	ctx context.Context,
+	orgID models.OrgID,
+	job *models.Job,
+	priority models.JobPriority,
+	discordIntegrationID, guildID string,
+) error {
+	if job.DiscordPayload == nil {
+		return fmt.Errorf("job has no Discord payload")
+	}
+
+	raised, err := d.jobsService.RaiseJobPriority(ctx, orgID, job.ID, priority)
+	if err != nil {
+		return fmt.Errorf("failed to raise priority of job %s: %w", job.ID, err)
+	}
+	if !raised {
+		log.Printf("⏭️ Job %s already has priority %d or higher", job.ID, priority)
+		return nil
+	}
+	job.Priority = priority
+
+	log.Printf("⏫ Raised priority of job %s to %d", job.ID, priority)
+	confirmation := "Priority raised to urgent - this job will be dispatched ahead of other queued jobs"
+	return d.sendSystemMessage(
+		ctx,
+		discordIntegrationID,
+		guildID,
+		job.DiscordPayload.ChannelID,
+		job.DiscordPayload.ThreadID,
+		confirmation,
+	)
+}
+
+// dispatchHigherPriorityQueuedJobs gives queued jobs with a higher priority than the job the first pick
+// of free agents before the job itself is assigned
+func (d *DiscordUseCase) dispatchHigherPriorityQueuedJobs(
+	ctx context.Context,
+	orgID models.OrgID,
+	job *models.Job,
+) error {
+	hasHigherPriorityJobs, err := d.jobsService.HasQueuedJobsAbovePriority(
+		ctx,
+		orgID,
+		models.JobTypeDiscord,
+		job.Priority,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to check for higher priority queued jobs: %w", err)
+	}
+	if !hasHigherPriorityJobs {
+		return nil
+	}
+
+	// Only the organization's queue competes for its agents - other organizations are left to the background task
+	log.Printf("⏫ Dispatching queued jobs with a higher priority before assigning job %s", job.ID)
+	integrations, err := d.discordIntegrationsService.GetDiscordIntegrationsByOrganizationID(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get discord integrations of organization %s: %w", orgID, err)
+	}
+	if _, err := d.dispatchQueuedJobsOfIntegrations(ctx, integrations); err != nil {
+		return err
+	}
+	return nil
+}
+
+// cancelJob cancels the job's queued and in-progress messages and tells the agent working on them to stop
+// The job stays open for follow-up messages. If the agent was told to stop, the cancellation is confirmed
+// in the thread once it acknowledges - or reported as unconfirmed if it never does - otherwise right away.
+func (d *DiscordUseCase) cancelJob(ctx context.Context, orgID models.OrgID, job *models.Job, guildID string) error {
+	log.Printf("📋 Starting to cancel Discord job %s", job.ID)
+	if job.DiscordPayload == nil {
+		return fmt.Errorf("job has no Discord payload")
+	}
+	discordIntegrationID := job.DiscordPayload.IntegrationID
+
+	var activeMessages, inProgressMessages []*models.ProcessedDiscordMessage
+	for _, status := range []models.ProcessedDiscordMessageStatus{
+		models.ProcessedDiscordMessageStatusQueued,
+		models.ProcessedDiscordMessageStatusInProgress,
+	} {
+		messages, err := d.discordMessagesService.GetProcessedMessagesByJobIDAndStatus(
+			ctx,
+			orgID,
+			job.ID,
+			status,
+			discordIntegrationID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get %s messages for job %s: %w", status, job.ID, err)
+		}
+		activeMessages = append(activeMessages, messages...)
+		if status == models.ProcessedDiscordMessageStatusInProgress {
+			inProgressMessages = messages
+		}
+	}
+	if len(activeMessages) == 0 {
+		log.Printf("⏭️ Job %s has no queued or in-progress messages to cancel", job.ID)
+		return d.sendSystemMessage(
+			ctx,
+			discordIntegrationID,
+			guildID,
+			job.DiscordPayload.ChannelID,
+			job.DiscordPayload.ThreadID,
+			"There is nothing to stop - the agent is not working on this job",
+		)
+	}
+
+	maybeAgent, err := d.agentsService.GetAgentByJobID(ctx, orgID, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get agent by job id: %w", err)
+	}
+
+	// Queued messages never reached an agent, so only in-progress messages need the agent to stop
+	agent, hasAgent := maybeAgent.Get()
+	awaitingAgent := hasAgent && len(inProgressMessages) > 0
+	var cancelledMessages []*models.ProcessedDiscordMessage
+	if err := d.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		for _, message := range activeMessages {
+			updatedMessage, err := d.discordMessagesService.UpdateProcessedDiscordMessage(
+				ctx,
+				orgID,
+				message.ID,
+				models.ProcessedDiscordMessageStatusCancelled,
+				discordIntegrationID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to cancel message %s: %w", message.ID, err)
+			}
+			cancelledMessages = append(cancelledMessages, updatedMessage)
+		}
+
+		// The cancelled prompts must not be resent to the agent, only the cancellation itself
+		if err := d.agentsService.FailPendingAgentJobMessages(ctx, orgID, job.ID, "job was cancelled"); err != nil {
+			return fmt.Errorf("failed to fail pending agent messages of job %s: %w", job.ID, err)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to cancel messages of job %s in transaction: %w", job.ID, err)
+	}
+
+	// The top-level message shows the state of the whole job instead
+	for _, message := range cancelledMessages {
+		if message.DiscordMessageID == job.DiscordPayload.MessageID {
+			continue
+		}
+		if err := d.updateDiscordMessageReaction(
+			ctx,
+			message.DiscordThreadID,
+			message.DiscordMessageID,
+			deriveMessageReactionFromStatus(message.Status),
+			discordIntegrationID,
+		); err != nil {
+			log.Printf("⚠️ Failed to update reaction for cancelled message %s: %v", message.ID, err)
+		}
+	}
+
+	// The agent is told to stop only once the cancellation is committed, so it never stops on one which was
+	// rolled back
+	if awaitingAgent {
+		cancelMessage := models.BaseMessage{
+			ID:      core.NewID("msg"),
+			Type:    models.MessageTypeCancelJob,
+			Payload: models.CancelJobPayload{JobID: job.ID},
+		}
+		latestMessage := inProgressMessages[len(inProgressMessages)-1]
+		err := d.agentsService.SendMessageToAgent(
+			ctx,
+			orgID,
+			agent.WSConnectionID,
+			job.ID,
+			latestMessage.ID,
+			cancelMessage,
+		)
+		if errors.Is(err, core.ErrUnsupportedMessageType) {
+			log.Printf("⚠️ Agent %s does not support cancelling job %s", agent.ID, job.ID)
+			return d.sendSystemMessage(
+				ctx,
+				discordIntegrationID,
+				guildID,
+				job.DiscordPayload.ChannelID,
+				job.DiscordPayload.ThreadID,
+				"Your messages were cancelled, but the agent working on this job does not support stopping jobs "+
+					"and may finish its current work - upgrade ccagent to stop jobs from chat",
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to send cancel job message to agent %s: %w", agent.ID, err)
+		}
+		log.Printf("🛑 Sent cancel job message for job %s to agent %s", job.ID, agent.ID)
+	} else {
+		if err := d.confirmJobCancelled(ctx, job, guildID); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("📋 Completed successfully - cancelled %d messages of Discord job %s", len(cancelledMessages), job.ID)
+	return nil
+}
+
+// confirmJobCancelled tells the user the job was stopped and shows it as waiting for next steps
+func (d *DiscordUseCase) confirmJobCancelled(ctx context.Context, job *models.Job, guildID string) error {
+	discordIntegrationID := job.DiscordPayload.IntegrationID
+	if err := d.updateDiscordMessageReaction(
+		ctx,
+		job.DiscordPayload.ChannelID,
+		job.DiscordPayload.MessageID,
+		EmojiRaisedHand,
+		discordIntegrationID,
+	); err != nil {
+		log.Printf("⚠️ Failed to add hand emoji to cancelled job %s: %v", job.ID, err)
+	}
+
+	if err := d.sendSystemMessage(
+		ctx,
+		discordIntegrationID,
+		guildID,
+		job.DiscordPayload.ChannelID,
+		job.DiscordPayload.ThreadID,
+		"Job stopped - reply in this thread to continue",
+	); err != nil {
+		return fmt.Errorf("failed to send cancellation confirmation to Discord: %w", err)
+	}
+	return nil
+}
+
 func deriveMessageReactionFromStatus(status models.ProcessedDiscordMessageStatus) string {
 	switch status {
 	case models.ProcessedDiscordMessageStatusInProgress:
@@ -217,12 +633,49 @@ func deriveMessageReactionFromStatus(status models.ProcessedDiscordMessageStatus
 		return EmojiHourglass
 	case models.ProcessedDiscordMessageStatusCompleted:
 		return EmojiCheckMark
+	case models.ProcessedDiscordMessageStatusCancelled:
+		return EmojiNoEntry
+	case models.ProcessedDiscordMessageStatusFailed:
+		return EmojiCrossMark
 	default:
 		utils.AssertInvariant(false, "invalid status received")
 		return ""
 	}
 }
 
+// isJobHandedOver returns true if the job has no agent, e.g. after it was requeued because its agent disconnected,
+// so the agent it is assigned to next has not seen its earlier conversation
+func (d *DiscordUseCase) isJobHandedOver(ctx context.Context, orgID models.OrgID, job *models.Job) (bool, error) {
+	maybeAgent, err := d.agentsService.GetAgentByJobID(ctx, orgID, job.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get agent of job %s: %w", job.ID, err)
+	}
+	return maybeAgent.IsAbsent(), nil
+}
+
+// getConversationHistory returns the earlier conversation of a job for the agent taking it over
+// The pending messages are left out as they are sent to the agent as prompts.
+func (d *DiscordUseCase) getConversationHistory(
+	ctx context.Context,
+	job *models.Job,
+	pendingMessages []*models.ProcessedDiscordMessage,
+) ([]models.ConversationTurn, error) {
+	maybeTranscript, err := d.jobsService.GetJobTranscript(ctx, job.OrgID, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transcript of job %s: %w", job.ID, err)
+	}
+	transcript, ok := maybeTranscript.Get()
+	if !ok {
+		return nil, nil
+	}
+
+	pendingIDs := make([]string, 0, len(pendingMessages))
+	for _, message := range pendingMessages {
+		pendingIDs = append(pendingIDs, message.ID)
+	}
+	return models.ConversationHistory(transcript, pendingIDs), nil
+}
+
 // getDiscordMessageLink generates a Discord message link
 func getDiscordMessageLink(guildID, channelID, messageID string) string {
 	return fmt.Sprintf("https://discord.com/channels/%s/%s/%s", guildID, channelID, messageID)
@@ -266,13 +719,61 @@ func trimDiscordMessage(message string) string {
 	return message[:trimmedLength] + truncationSuffix
 }
 
-// groupDiscordMessagesByJobID groups processed Discord messages by their job ID
-func groupDiscordMessagesByJobID(
-	messages []*models.ProcessedDiscordMessage,
-) map[string][]*models.ProcessedDiscordMessage {
-	grouped := make(map[string][]*models.ProcessedDiscordMessage)
+// firstQueuedAtByDiscordJobID returns the creation time of the oldest message of each job
+func firstQueuedAtByDiscordJobID(messages []*models.ProcessedDiscordMessage) map[string]time.Time {
+	firstQueuedAt := make(map[string]time.Time)
 	for _, msg := range messages {
-		grouped[msg.JobID] = append(grouped[msg.JobID], msg)
+		if queuedAt, exists := firstQueuedAt[msg.JobID]; !exists || msg.CreatedAt.Before(queuedAt) {
+			firstQueuedAt[msg.JobID] = msg.CreatedAt
+		}
+	}
+	return firstQueuedAt
+}
+
+// notifyAgentJobClosed tells the agent which held a job that the backend closed it
+// Agents which do not support job closed messages find out once their next message for the job is rejected.
+func (d *DiscordUseCase) notifyAgentJobClosed(
+	ctx context.Context,
+	job *models.Job,
+	agent *models.ActiveAgent,
+	reason string,
+) error {
+	maybeLatestMessage, err := d.discordMessagesService.GetLatestProcessedMessageForJob(
+		ctx,
+		job.OrgID,
+		job.ID,
+		job.DiscordPayload.IntegrationID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get latest processed message for job: %w", err)
+	}
+	latestMessage, ok := maybeLatestMessage.Get()
+	if !ok {
+		log.Printf("⏭️ Job %s has no processed messages - not notifying agent %s", job.ID, agent.ID)
+		return nil
+	}
+
+	jobClosedMessage := models.BaseMessage{
+		ID:      core.NewID("msg"),
+		Type:    models.MessageTypeJobClosed,
+		Payload: models.JobClosedPayload{JobID: job.ID, Reason: reason},
+	}
+	err = d.agentsService.SendMessageToAgent(
+		ctx,
+		job.OrgID,
+		agent.WSConnectionID,
+		job.ID,
+		latestMessage.ID,
+		jobClosedMessage,
+	)
+	if errors.Is(err, core.ErrUnsupportedMessageType) {
+		log.Printf("⏭️ Agent %s does not support job closed messages", agent.ID)
+		return nil
 	}
-	return grouped
+	if err != nil {
+		return fmt.Errorf("failed to send job closed message to agent %s: %w", agent.ID, err)
+	}
+
+	log.Printf("📤 Sent job closed message for job %s to agent %s", job.ID, agent.ID)
+	return nil
 }
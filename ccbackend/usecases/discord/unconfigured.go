@@ -3,6 +3,7 @@ package discord
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"ccbackend/models"
 )
@@ -69,6 +70,33 @@ func (u *UnconfiguredDiscordUseCase) ProcessAssistantMessage(
 	return fmt.Errorf("discord use case is not configured")
 }
 
+func (u *UnconfiguredDiscordUseCase) ProcessAssistantDelta(
+	ctx context.Context,
+	clientID string,
+	payload models.AssistantDeltaPayload,
+	orgID models.OrgID,
+) error {
+	return fmt.Errorf("discord use case is not configured")
+}
+
+func (u *UnconfiguredDiscordUseCase) ProcessProgressEvent(
+	ctx context.Context,
+	clientID string,
+	payload models.ProgressEventPayload,
+	orgID models.OrgID,
+) error {
+	return fmt.Errorf("discord use case is not configured")
+}
+
+func (u *UnconfiguredDiscordUseCase) ProcessArtifact(
+	ctx context.Context,
+	clientID string,
+	payload models.ArtifactPayload,
+	orgID models.OrgID,
+) error {
+	return fmt.Errorf("discord use case is not configured")
+}
+
 func (u *UnconfiguredDiscordUseCase) CleanupFailedDiscordJob(
 	ctx context.Context,
 	job *models.Job,
@@ -78,6 +106,51 @@ func (u *UnconfiguredDiscordUseCase) CleanupFailedDiscordJob(
 	return fmt.Errorf("discord use case is not configured")
 }
 
+func (u *UnconfiguredDiscordUseCase) CloseIdleDiscordJob(ctx context.Context, job *models.Job, notice string) error {
+	return fmt.Errorf("discord use case is not configured")
+}
+
+func (u *UnconfiguredDiscordUseCase) HasAvailableAgentForDiscordJob(
+	ctx context.Context,
+	job *models.Job,
+	activeSince time.Time,
+) (bool, error) {
+	return false, fmt.Errorf("discord use case is not configured")
+}
+
+func (u *UnconfiguredDiscordUseCase) FailStuckDiscordJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	explanation string,
+) error {
+	return fmt.Errorf("discord use case is not configured")
+}
+
+func (u *UnconfiguredDiscordUseCase) RequeueDiscordJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	notice string,
+) error {
+	return fmt.Errorf("discord use case is not configured")
+}
+
 func (u *UnconfiguredDiscordUseCase) ProcessQueuedJobs(ctx context.Context) error {
 	return fmt.Errorf("discord use case is not configured")
 }
+
+func (u *UnconfiguredDiscordUseCase) ProcessJobCancelled(ctx context.Context, job *models.Job) error {
+	return fmt.Errorf("discord use case is not configured")
+}
+
+func (u *UnconfiguredDiscordUseCase) ProcessJobCancellationUnconfirmed(ctx context.Context, job *models.Job) error {
+	return fmt.Errorf("discord use case is not configured")
+}
+
+func (u *UnconfiguredDiscordUseCase) DownloadAttachment(
+	ctx context.Context,
+	source models.AttachmentSource,
+) ([]byte, error) {
+	return nil, fmt.Errorf("discord use case is not configured")
+}
@@ -6,6 +6,7 @@ import (
 	"ccbackend/salesnotif"
 	"ccbackend/services"
 	"ccbackend/usecases/agents"
+	"ccbackend/usecases/streaming"
 	"ccbackend/utils"
 	"context"
 	"fmt"
@@ -13,8 +14,18 @@ import (
 	"math/rand"
 	"slices"
 	"strings"
+	"time"
 )
 
+// assistantStreamUpdateInterval is how often the streamed messages of an integration are edited in place
+// Discord allows 5 message edits per 5 seconds per channel, and the bot's global rate limit is shared by all
+// the assistant replies and progress statuses streamed into the integration's threads. The interval applies per
+// replica.
+const assistantStreamUpdateInterval = time.Second
+
+// maxProgressStatusSteps is how many of the latest agent steps a job's status message shows
+const maxProgressStatusSteps = 5
+
 // DiscordUseCase handles all Discord-specific operations
 type DiscordUseCase struct {
 	discordClient              clients.DiscordClient
@@ -23,8 +34,11 @@ type DiscordUseCase struct {
 	jobsService                services.JobsService
 	discordMessagesService     services.DiscordMessagesService
 	discordIntegrationsService services.DiscordIntegrationsService
+	connectedChannelsService   services.ConnectedChannelsService
 	txManager                  services.TransactionManager
 	agentsUseCase              agents.AgentsUseCaseInterface
+	assistantStreams           *streaming.Tracker
+	progressStatuses           *streaming.Tracker
 }
 
 // NewDiscordUseCase creates a new instance of DiscordUseCase
@@ -35,9 +49,11 @@ func NewDiscordUseCase(
 	jobsService services.JobsService,
 	discordMessagesService services.DiscordMessagesService,
 	discordIntegrationsService services.DiscordIntegrationsService,
+	connectedChannelsService services.ConnectedChannelsService,
 	txManager services.TransactionManager,
 	agentsUseCase agents.AgentsUseCaseInterface,
 ) *DiscordUseCase {
+	streamThrottle := streaming.NewThrottle(assistantStreamUpdateInterval)
 	return &DiscordUseCase{
 		discordClient:              discordClient,
 		wsClient:                   wsClient,
@@ -45,8 +61,11 @@ func NewDiscordUseCase(
 		jobsService:                jobsService,
 		discordMessagesService:     discordMessagesService,
 		discordIntegrationsService: discordIntegrationsService,
+		connectedChannelsService:   connectedChannelsService,
 		txManager:                  txManager,
 		agentsUseCase:              agentsUseCase,
+		assistantStreams:           streaming.NewTracker(streamThrottle),
+		progressStatuses:           streaming.NewTracker(streamThrottle),
 	}
 }
 
@@ -94,17 +113,38 @@ func (d *DiscordUseCase) ProcessDiscordMessageEvent(
 			return fmt.Errorf("failed to get job for thread reply: %w", err)
 		}
 		if !maybeJob.IsPresent() {
-			// Job not found for thread reply - send error message
-			log.Printf("❌ No existing job found for thread reply in %s", event.ChannelID)
-			errorMessage := "Error: new jobs can only be started from top-level messages"
-			return d.sendSystemMessage(
+			// A follow-up in the thread of a closed job starts a new job in the same thread
+			hasClosedJob, err := d.jobsService.HasClosedJobForDiscordThread(
 				ctx,
-				discordIntegrationID,
-				event.GuildID,
-				event.ChannelID,
+				orgID,
 				*event.ThreadID,
-				errorMessage,
+				discordIntegrationID,
 			)
+			if err != nil {
+				log.Printf("❌ Failed to check for closed job of thread %s: %v", *event.ThreadID, err)
+				return fmt.Errorf("failed to check for closed job of thread: %w", err)
+			}
+			if !hasClosedJob {
+				// Job not found for thread reply - send error message
+				log.Printf("❌ No existing job found for thread reply in %s", event.ChannelID)
+				errorMessage := "Error: new jobs can only be started from top-level messages"
+				return d.sendSystemMessage(
+					ctx,
+					discordIntegrationID,
+					event.GuildID,
+					event.ChannelID,
+					*event.ThreadID,
+					errorMessage,
+				)
+			}
+			if models.IsStopJobCommand(event.Content) {
+				log.Printf("⏭️ Stop command in thread %s ignored - the job is already closed", *event.ThreadID)
+				notice := "There is nothing to stop - the job in this thread is already closed"
+				return d.sendSystemMessage(ctx, discordIntegrationID, event.GuildID, event.ChannelID, *event.ThreadID, notice)
+			}
+			log.Printf("🔁 Job of thread %s is closed - starting a new job in the thread", *event.ThreadID)
+		} else if models.IsStopJobCommand(event.Content) {
+			return d.processStopCommand(ctx, maybeJob.MustGet(), event, orgID)
 		}
 	} else {
 		log.Printf("🆕 Bot mentioned at start of new thread in channel %s", event.ChannelID)
@@ -149,6 +189,7 @@ func (d *DiscordUseCase) ProcessDiscordMessageEvent(
 	}
 
 	job := jobResult.Job
+	isNewConversation := jobResult.Status == models.JobCreationStatusCreated
 
 	// Get organization ID from Discord integration (agents are organization-scoped)
 	maybeDiscordIntegration, err := d.discordIntegrationsService.GetDiscordIntegrationByID(ctx, discordIntegrationID)
@@ -161,33 +202,56 @@ func (d *DiscordUseCase) ProcessDiscordMessageEvent(
 		return fmt.Errorf("discord integration not found: %s", discordIntegrationID)
 	}
 	// Verify the organization ID matches (already passed as parameter)
+	discordIntegration := maybeDiscordIntegration.MustGet()
 
-	// Check if agents are available first
-	connectedClientIDs := d.wsClient.GetClientIDs()
-	log.Printf("📋 Retrieved %d active client IDs", len(connectedClientIDs))
-	connectedAgents, err := d.agentsService.GetConnectedActiveAgents(ctx, orgID, connectedClientIDs)
+	// Resolve the repository and agent selector configured for the channel so the job lands on a matching agent
+	repoURL, agentSelector, err := d.getChannelRouting(ctx, orgID, discordIntegration.DiscordGuildID, job)
 	if err != nil {
-		log.Printf("❌ Failed to check for connected agents: %v", err)
-		return fmt.Errorf("failed to check for connected agents: %w", err)
+		return fmt.Errorf("failed to get repository for Discord channel: %w", err)
 	}
 
-	var clientID string
-	var messageStatus models.ProcessedDiscordMessageStatus
+	// The urgent keyword is meant for the bot, the agent and the transcript only get the request itself
+	messageText := event.Content
+	if models.ContainsUrgentKeyword(event.Content) {
+		if err := d.raiseJobPriority(ctx, orgID, job, models.JobPriorityUrgent, discordIntegrationID, discordIntegration.DiscordGuildID); err != nil {
+			return fmt.Errorf("failed to raise job priority: %w", err)
+		}
+		messageText = models.StripUrgentKeyword(event.Content)
+	}
+
+	// A new job must not take a free agent away from queued jobs with a higher priority
+	if isNewConversation {
+		if err := d.dispatchHigherPriorityQueuedJobs(ctx, orgID, job); err != nil {
+			return fmt.Errorf("failed to dispatch higher priority queued jobs: %w", err)
+		}
+	}
+
+	// A reply to a job without an agent is handed to a new one, which has not seen the earlier conversation
+	handedOver := false
+	if !isNewConversation {
+		handedOver, err = d.isJobHandedOver(ctx, orgID, job)
+		if err != nil {
+			return fmt.Errorf("failed to check agent of job: %w", err)
+		}
+	}
+
+	clientID, assigned, err := d.agentsUseCase.TryAssignJobToAgent(ctx, job.ID, repoURL, agentSelector, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to assign agent for job: %w", err)
+	}
 
-	if len(connectedAgents) == 0 {
-		// No agents available - queue the message
+	var messageStatus models.ProcessedDiscordMessageStatus
+	if !assigned {
+		// No matching agents available - queue the message
 		log.Printf("⚠️ No available agents to handle Discord mention - queuing message")
 		messageStatus = models.ProcessedDiscordMessageStatusQueued
-		clientID = "" // No agent assigned
 	} else {
-		// Agents available - assign job to agent
-		clientID, err = d.agentsUseCase.GetOrAssignAgentForJob(ctx, job, threadID, orgID)
-		if err != nil {
-			return fmt.Errorf("failed to get or assign agent for job: %w", err)
-		}
 		messageStatus = models.ProcessedDiscordMessageStatusInProgress
 	}
 
+	// Only attachments within the size limits are forwarded to the agent
+	attachments, skippedAttachments := event.Attachments.WithinLimits()
+
 	// Store the Discord message as ProcessedDiscordMessage with appropriate status
 	processedMessage, err := d.discordMessagesService.CreateProcessedDiscordMessage(
 		ctx,
@@ -195,13 +259,37 @@ func (d *DiscordUseCase) ProcessDiscordMessageEvent(
 		job.ID,
 		event.MessageID,
 		threadID,
-		event.Content,
+		messageText,
 		discordIntegrationID,
 		messageStatus,
+		attachments,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create processed Discord message: %w", err)
 	}
+	d.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorUser,
+		event.UserID,
+		models.TranscriptMessageTypeUserMessage,
+		messageText,
+		processedMessage.ID,
+	)
+
+	if len(skippedAttachments) > 0 {
+		err := d.sendSystemMessage(
+			ctx,
+			discordIntegrationID,
+			discordIntegration.DiscordGuildID,
+			event.ChannelID,
+			threadID,
+			skippedAttachments.SkippedNotice(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to send skipped attachments notice: %w", err)
+		}
+	}
 
 	// Add emoji reaction based on message status
 	reactionEmoji := deriveMessageReactionFromStatus(messageStatus)
@@ -224,14 +312,43 @@ func (d *DiscordUseCase) ProcessDiscordMessageEvent(
 
 	// If message was queued, don't send to agent yet - background processor will handle it
 	if messageStatus == models.ProcessedDiscordMessageStatusQueued {
+		if repoURL != "" {
+			startedWaiting, err := d.isOnlyQueuedMessage(ctx, processedMessage)
+			if err != nil {
+				return fmt.Errorf("failed to check queued messages of job: %w", err)
+			}
+			if startedWaiting {
+				waitingMessage := fmt.Sprintf(
+					"Waiting for an available agent working on repository `%s` - your message is queued",
+					repoURL,
+				)
+				if err := d.sendSystemMessage(
+					ctx,
+					discordIntegrationID,
+					discordIntegration.DiscordGuildID,
+					job.DiscordPayload.ChannelID,
+					job.DiscordPayload.ThreadID,
+					waitingMessage,
+				); err != nil {
+					return fmt.Errorf("failed to send queued repository notice: %w", err)
+				}
+			}
+		}
 		log.Printf("📋 Message queued for background processing - job %s", job.ID)
 		log.Printf("📋 Completed successfully - processed Discord message event (queued)")
 		return nil
 	}
 
-	// Send work to assigned agent
-	if event.ThreadID == nil {
-		if err := d.sendStartConversationToAgent(ctx, clientID, processedMessage); err != nil {
+	// Send work to assigned agent - an agent taking over the job gets the earlier conversation as history
+	if event.ThreadID == nil || handedOver {
+		var history []models.ConversationTurn
+		if handedOver {
+			history, err = d.getConversationHistory(ctx, job, []*models.ProcessedDiscordMessage{processedMessage})
+			if err != nil {
+				return err
+			}
+		}
+		if err := d.sendStartConversationToAgent(ctx, clientID, processedMessage, history); err != nil {
 			return fmt.Errorf("failed to send start conversation message: %w", err)
 		}
 	} else {
@@ -253,6 +370,13 @@ func (d *DiscordUseCase) ProcessDiscordReactionEvent(
 	log.Printf("📋 Starting to process Discord reaction event: %s by user %s on message %s in guild %s, channel %s",
 		event.EmojiName, event.UserID, event.MessageID, event.GuildID, event.ChannelID)
 
+	if event.EmojiName == EmojiFire {
+		return d.processUrgentReaction(ctx, event, discordIntegrationID, orgID)
+	}
+	if event.EmojiName == EmojiStopSign {
+		return d.processStopReaction(ctx, event, discordIntegrationID, orgID)
+	}
+
 	// Only handle white check mark, check mark, or similar completion reactions
 	if event.EmojiName != EmojiCheckMark && event.EmojiName != "white_check_mark" &&
 		event.EmojiName != "heavy_check_mark" {
@@ -318,6 +442,7 @@ func (d *DiscordUseCase) ProcessDiscordReactionEvent(
 		return fmt.Errorf("failed to get agent by job id: %w", err)
 	}
 
+	var assignedAgentID string
 	if err := d.txManager.WithTransaction(ctx, func(ctx context.Context) error {
 		// If agent is found, unassign them from the job
 		if maybeAgent.IsPresent() {
@@ -326,14 +451,22 @@ func (d *DiscordUseCase) ProcessDiscordReactionEvent(
 				log.Printf("❌ Failed to unassign agent %s from job %s: %v", agent.ID, job.ID, err)
 				return fmt.Errorf("failed to unassign agent from job: %w", err)
 			}
+			assignedAgentID = agent.ID
 
 			log.Printf("✅ Unassigned agent %s from manually completed job %s", agent.ID, job.ID)
 		}
 
-		// Delete the job and its associated processed messages
-		if err := d.jobsService.DeleteJob(ctx, orgID, job.ID); err != nil {
-			log.Printf("❌ Failed to delete completed job %s: %v", job.ID, err)
-			return fmt.Errorf("failed to delete completed job: %w", err)
+		// Close the job, keeping it and its processed messages as history
+		if err := d.jobsService.CloseJob(
+			ctx,
+			orgID,
+			job.ID,
+			models.JobStatusCompleted,
+			models.ManualJobCompletionReason,
+			assignedAgentID,
+		); err != nil {
+			log.Printf("❌ Failed to close completed job %s: %v", job.ID, err)
+			return fmt.Errorf("failed to close completed job: %w", err)
 		}
 
 		return nil
@@ -353,21 +486,149 @@ func (d *DiscordUseCase) ProcessDiscordReactionEvent(
 		threadChannelID = *event.ThreadID
 	}
 
-	if err := d.sendSystemMessage(ctx, discordIntegrationID, event.GuildID, event.ChannelID, threadChannelID, "Job manually marked as complete"); err != nil {
+	if err := d.sendSystemMessage(ctx, discordIntegrationID, event.GuildID, event.ChannelID, threadChannelID, models.ManualJobCompletionReason); err != nil {
 		log.Printf("❌ Failed to send completion message to Discord thread %s: %v", threadChannelID, err)
 		return fmt.Errorf("failed to send completion message to Discord: %w", err)
 	}
+	d.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorUser,
+		event.UserID,
+		models.TranscriptMessageTypeSystemMessage,
+		models.ManualJobCompletionReason,
+		"",
+	)
 
 	log.Printf("📤 Sent completion message to Discord thread %s", threadChannelID)
 
 	// Send sales notification for manual job completion
 	salesnotif.New(orgID, fmt.Sprintf("Manually completed job `%s`", job.ID))
 
-	log.Printf("🗑️ Deleted manually completed job %s", job.ID)
 	log.Printf("📋 Completed successfully - processed manual job completion for job %s", job.ID)
 	return nil
 }
 
+// processUrgentReaction raises the priority of the job started by the reacted-to message
+// Only the job creator can mark their job as urgent
+func (d *DiscordUseCase) processUrgentReaction(
+	ctx context.Context,
+	event models.DiscordReactionEvent,
+	discordIntegrationID string,
+	orgID models.OrgID,
+) error {
+	threadID := event.MessageID
+	if event.ThreadID != nil {
+		threadID = *event.ThreadID
+	}
+
+	maybeJob, err := d.jobsService.GetJobByDiscordThread(ctx, orgID, threadID, discordIntegrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get job for reaction: %w", err)
+	}
+	if !maybeJob.IsPresent() {
+		log.Printf("⏭️ No job found for message %s in channel %s - ignoring reaction", event.MessageID, event.ChannelID)
+		return nil
+	}
+	job := maybeJob.MustGet()
+
+	if job.DiscordPayload == nil {
+		log.Printf("⏭️ Job %s has no Discord payload", job.ID)
+		return nil
+	}
+	if job.DiscordPayload.UserID != event.UserID {
+		log.Printf(
+			"⏭️ Reaction from %s ignored - job %s was created by %s",
+			event.UserID,
+			job.ID,
+			job.DiscordPayload.UserID,
+		)
+		return nil
+	}
+
+	if err := d.raiseJobPriority(ctx, orgID, job, models.JobPriorityUrgent, discordIntegrationID, event.GuildID); err != nil {
+		return fmt.Errorf("failed to raise job priority: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - processed urgent reaction for job %s", job.ID)
+	return nil
+}
+
+// processStopReaction stops the agent working on the job started by the reacted-to message
+// Only the job creator can stop their job
+func (d *DiscordUseCase) processStopReaction(
+	ctx context.Context,
+	event models.DiscordReactionEvent,
+	discordIntegrationID string,
+	orgID models.OrgID,
+) error {
+	threadID := event.MessageID
+	if event.ThreadID != nil {
+		threadID = *event.ThreadID
+	}
+
+	maybeJob, err := d.jobsService.GetJobByDiscordThread(ctx, orgID, threadID, discordIntegrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get job for reaction: %w", err)
+	}
+	if !maybeJob.IsPresent() {
+		log.Printf("⏭️ No job found for message %s in channel %s - ignoring reaction", event.MessageID, event.ChannelID)
+		return nil
+	}
+	job := maybeJob.MustGet()
+
+	if job.DiscordPayload == nil {
+		log.Printf("⏭️ Job %s has no Discord payload", job.ID)
+		return nil
+	}
+	if job.DiscordPayload.UserID != event.UserID {
+		log.Printf(
+			"⏭️ Reaction from %s ignored - job %s was created by %s",
+			event.UserID,
+			job.ID,
+			job.DiscordPayload.UserID,
+		)
+		return nil
+	}
+
+	if err := d.cancelJob(ctx, orgID, job, event.GuildID); err != nil {
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - processed stop reaction for job %s", job.ID)
+	return nil
+}
+
+// processStopCommand stops the agent working on the job when its creator replies "@bot stop" in the thread
+func (d *DiscordUseCase) processStopCommand(
+	ctx context.Context,
+	job *models.Job,
+	event models.DiscordMessageEvent,
+	orgID models.OrgID,
+) error {
+	if job.DiscordPayload == nil {
+		return fmt.Errorf("job has no Discord payload")
+	}
+	if job.DiscordPayload.UserID != event.UserID {
+		log.Printf("⏭️ Stop command from %s ignored - job %s was created by someone else", event.UserID, job.ID)
+		return d.sendSystemMessage(
+			ctx,
+			job.DiscordPayload.IntegrationID,
+			event.GuildID,
+			job.DiscordPayload.ChannelID,
+			job.DiscordPayload.ThreadID,
+			"Only the user who started this job can stop it",
+		)
+	}
+
+	if err := d.cancelJob(ctx, orgID, job, event.GuildID); err != nil {
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - processed stop command for job %s", job.ID)
+	return nil
+}
+
 func (d *DiscordUseCase) ProcessProcessingMessage(
 	ctx context.Context,
 	clientID string,
@@ -460,22 +721,21 @@ func (d *DiscordUseCase) ProcessSystemMessage(
 	}
 	discordIntegrationID := job.DiscordPayload.IntegrationID
 
+	// Get the agent which sent the message
+	maybeAgent, err := d.agentsService.GetAgentByWSConnectionID(ctx, orgID, clientID)
+	if err != nil {
+		log.Printf("❌ Failed to find agent for client %s: %v", clientID, err)
+		return fmt.Errorf("failed to find agent for client: %w", err)
+	}
+	var agentID string
+	if maybeAgent.IsPresent() {
+		agentID = maybeAgent.MustGet().ID
+	}
+
 	// Check if this is an error message from the agent
 	if isAgentErrorMessage(payload.Message) {
 		log.Printf("❌ Detected agent error message for job %s: %s", job.ID, payload.Message)
 
-		// Get the agent that encountered the error
-		maybeAgent, err := d.agentsService.GetAgentByWSConnectionID(ctx, orgID, clientID)
-		if err != nil {
-			log.Printf("❌ Failed to find agent for error handling: %v", err)
-			return fmt.Errorf("failed to find agent for error handling: %w", err)
-		}
-
-		var agentID string
-		if maybeAgent.IsPresent() {
-			agentID = maybeAgent.MustGet().ID
-		}
-
 		// Clean up the failed job
 		errorMessage := fmt.Sprintf(
 			"%s Agent encountered an error and cannot continue:\n%s",
@@ -509,6 +769,15 @@ func (d *DiscordUseCase) ProcessSystemMessage(
 	if err := d.sendSystemMessage(ctx, discordIntegrationID, integration.DiscordGuildID, job.DiscordPayload.ChannelID, job.DiscordPayload.ThreadID, payload.Message); err != nil {
 		return fmt.Errorf("❌ Failed to send system message to Discord: %v", err)
 	}
+	d.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorAgent,
+		agentID,
+		models.TranscriptMessageTypeSystemMessage,
+		payload.Message,
+		payload.ProcessedMessageID,
+	)
 
 	// Update job timestamp to track activity
 	if err := d.jobsService.UpdateJobTimestamp(ctx, orgID, job.ID); err != nil {
@@ -584,12 +853,19 @@ func (d *DiscordUseCase) ProcessJobComplete(
 		}
 		log.Printf("✅ Unassigned agent %s from completed job %s", agent.ID, jobID)
 
-		// Delete the job and its associated processed messages
-		if err := d.jobsService.DeleteJob(ctx, orgID, jobID); err != nil {
-			log.Printf("❌ Failed to delete completed job %s: %v", jobID, err)
-			return fmt.Errorf("failed to delete completed job: %w", err)
+		// Close the job, keeping it and its processed messages as history
+		if err := d.jobsService.CloseJob(
+			ctx,
+			orgID,
+			jobID,
+			models.JobStatusCompleted,
+			payload.Reason,
+			agent.ID,
+		); err != nil {
+			log.Printf("❌ Failed to close completed job %s: %v", jobID, err)
+			return fmt.Errorf("failed to close completed job: %w", err)
 		}
-		log.Printf("🗑️ Deleted completed job %s", jobID)
+		log.Printf("📁 Closed completed job %s", jobID)
 
 		return nil
 	}); err != nil {
@@ -611,6 +887,15 @@ func (d *DiscordUseCase) ProcessJobComplete(
 		log.Printf("❌ Failed to send completion message to Discord thread %s: %v", job.DiscordPayload.ThreadID, err)
 		return fmt.Errorf("failed to send completion message to Discord: %w", err)
 	}
+	d.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorAgent,
+		agent.ID,
+		models.TranscriptMessageTypeSystemMessage,
+		payload.Reason,
+		"",
+	)
 
 	log.Printf("📤 Sent completion message to Discord thread %s: %s", job.DiscordPayload.ThreadID, payload.Reason)
 
@@ -694,17 +979,34 @@ func (d *DiscordUseCase) ProcessAssistantMessage(
 	}
 	integration := maybeIntegration.MustGet()
 
+	d.finishProgressStatus(ctx, job)
+
 	// Send assistant message to Discord - ThreadID contains the channel/thread info
-	if err := d.sendDiscordMessage(
-		ctx,
-		discordIntegrationID,
-		integration.DiscordGuildID,
-		job.DiscordPayload.ThreadID,
-		job.DiscordPayload.ThreadID,
-		messageToSend,
-	); err != nil {
+	// A streamed reply is replaced with the final message instead
+	if stream, ok := d.assistantStreams.Finish(ctx, payload.ProcessedMessageID); ok && stream.MessageID != "" {
+		err = d.updateDiscordMessage(job.DiscordPayload.ThreadID, stream.MessageID, messageToSend)
+	} else {
+		err = d.sendDiscordMessage(
+			ctx,
+			discordIntegrationID,
+			integration.DiscordGuildID,
+			job.DiscordPayload.ThreadID,
+			job.DiscordPayload.ThreadID,
+			messageToSend,
+		)
+	}
+	if err != nil {
 		return fmt.Errorf("❌ Failed to send assistant message to Discord: %v", err)
 	}
+	d.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorAgent,
+		agent.ID,
+		models.TranscriptMessageTypeAssistantMessage,
+		messageToSend,
+		payload.ProcessedMessageID,
+	)
 
 	// Update job timestamp to track activity
 	if err := d.jobsService.UpdateJobTimestamp(ctx, orgID, job.ID); err != nil {
@@ -775,79 +1077,603 @@ func (d *DiscordUseCase) ProcessAssistantMessage(
 	return nil
 }
 
-// CleanupFailedDiscordJob handles the cleanup of a failed Discord job including Discord notifications and database cleanup
-// This is exported so core use case can call it when deregistering agents
-func (d *DiscordUseCase) CleanupFailedDiscordJob(
+// ProcessAssistantDelta streams a chunk of an assistant reply into the job's Discord thread
+// The reply is posted once and then edited in place, at most once per assistantStreamUpdateInterval per integration.
+func (d *DiscordUseCase) ProcessAssistantDelta(
 	ctx context.Context,
-	job *models.Job,
-	agentID string,
-	failureMessage string,
+	clientID string,
+	payload models.AssistantDeltaPayload,
+	orgID models.OrgID,
 ) error {
-	if job.DiscordPayload == nil {
-		log.Printf("❌ Job %s has no Discord payload", job.ID)
-		return fmt.Errorf("job has no Discord payload")
+	if payload.ProcessedMessageID == "" {
+		return fmt.Errorf("ProcessedMessageID is empty in AssistantDelta payload")
 	}
-	discordIntegrationID := job.DiscordPayload.IntegrationID
-	orgID := job.OrgID
 
-	// Get Discord integration to get guild ID
-	maybeIntegration, err := d.discordIntegrationsService.GetDiscordIntegrationByID(ctx, discordIntegrationID)
+	maybeJob, _, err := d.getAgentJob(ctx, clientID, payload.JobID, orgID)
 	if err != nil {
-		log.Printf("❌ Failed to get Discord integration: %v", err)
-		// Continue with cleanup even if integration lookup fails
+		return err
 	}
-
-	var guildID string
-	if maybeIntegration.IsPresent() {
-		guildID = maybeIntegration.MustGet().DiscordGuildID
+	job, ok := maybeJob.Get()
+	if !ok {
+		log.Printf("⚠️ Job %s not found - already completed, skipping assistant delta", payload.JobID)
+		return nil
 	}
 
-	// Send failure message to Discord thread
-	if guildID != "" {
-		if err := d.sendSystemMessage(ctx, discordIntegrationID, guildID, job.DiscordPayload.ChannelID, job.DiscordPayload.ThreadID, failureMessage); err != nil {
-			log.Printf("❌ Failed to send failure message to Discord thread %s: %v", job.DiscordPayload.ThreadID, err)
-			// Continue with cleanup even if Discord message fails
-		}
+	update := d.assistantStreams.Append(
+		payload.ProcessedMessageID,
+		job.DiscordPayload.IntegrationID,
+		payload.Delta,
+		time.Now(),
+	)
+	if update.Action == streaming.UpdateActionNone {
+		return nil
 	}
-
-	// Update the top-level message emoji to ❌
-	if err := d.updateDiscordMessageReaction(ctx, job.DiscordPayload.ChannelID, job.DiscordPayload.MessageID, EmojiCrossMark, discordIntegrationID); err != nil {
-		log.Printf("❌ Failed to update discord message reaction to ❌ for failed job %s: %v", job.ID, err)
-		// Continue with cleanup even if reaction update fails
+	if err := d.renderStreamUpdate(job, d.assistantStreams, payload.ProcessedMessageID, update); err != nil {
+		return fmt.Errorf("failed to render streamed assistant message in Discord: %w", err)
 	}
 
-	// Perform database operations within transaction
-	if err := d.txManager.WithTransaction(ctx, func(ctx context.Context) error {
-		// If agent ID is provided, unassign agent from job
-		if agentID != "" {
-			if err := d.agentsService.UnassignAgentFromJob(ctx, orgID, agentID, job.ID); err != nil {
-				log.Printf("❌ Failed to unassign agent %s from job %s: %v", agentID, job.ID, err)
-				return fmt.Errorf("failed to unassign agent from job: %w", err)
-			}
-			log.Printf("🔗 Unassigned agent %s from job %s", agentID, job.ID)
-		}
-
-		// Delete the job (use the job's discord integration and organization from the job)
-		if err := d.jobsService.DeleteJob(ctx, orgID, job.ID); err != nil {
-			log.Printf("❌ Failed to delete job %s: %v", job.ID, err)
-			return fmt.Errorf("failed to delete job: %w", err)
-		}
-
-		log.Printf("🗑️ Deleted job %s", job.ID)
-		return nil
-	}); err != nil {
-		return fmt.Errorf("failed to cleanup job %s in transaction: %w", job.ID, err)
+	// Update job timestamp to track activity
+	if err := d.jobsService.UpdateJobTimestamp(ctx, orgID, job.ID); err != nil {
+		return fmt.Errorf("failed to update job timestamp: %w", err)
 	}
 
 	return nil
 }
 
-// ProcessQueuedJobs processes jobs that are queued waiting for available agents
-func (d *DiscordUseCase) ProcessQueuedJobs(ctx context.Context) error {
-	log.Printf("📋 Starting to process queued Discord jobs")
-
-	// Get all discord integrations
-	integrations, err := d.discordIntegrationsService.GetAllDiscordIntegrations(ctx)
+// ProcessProgressEvent shows a step the agent took in the job's status message
+// The status message is posted on the first step and then edited in place to show the latest steps,
+// at most once per assistantStreamUpdateInterval per integration.
+// Every agent reply starts a new status message below it.
+func (d *DiscordUseCase) ProcessProgressEvent(
+	ctx context.Context,
+	clientID string,
+	payload models.ProgressEventPayload,
+	orgID models.OrgID,
+) error {
+	maybeJob, _, err := d.getAgentJob(ctx, clientID, payload.JobID, orgID)
+	if err != nil {
+		return err
+	}
+	job, ok := maybeJob.Get()
+	if !ok {
+		log.Printf("⚠️ Job %s not found - already completed, skipping progress event", payload.JobID)
+		return nil
+	}
+
+	update := d.progressStatuses.AppendLine(
+		job.ID,
+		job.DiscordPayload.IntegrationID,
+		payload.StatusLine(),
+		maxProgressStatusSteps,
+		time.Now(),
+	)
+	if update.Action == streaming.UpdateActionNone {
+		return nil
+	}
+	if err := d.renderStreamUpdate(job, d.progressStatuses, job.ID, update); err != nil {
+		return fmt.Errorf("failed to render progress status in Discord: %w", err)
+	}
+
+	// Update job timestamp to track activity
+	if err := d.jobsService.UpdateJobTimestamp(ctx, orgID, job.ID); err != nil {
+		return fmt.Errorf("failed to update job timestamp: %w", err)
+	}
+
+	return nil
+}
+
+// ProcessArtifact uploads a file the agent produced into the job's Discord thread
+func (d *DiscordUseCase) ProcessArtifact(
+	ctx context.Context,
+	clientID string,
+	payload models.ArtifactPayload,
+	orgID models.OrgID,
+) error {
+	log.Printf("📋 Starting to process artifact %s from client %s", payload.Attachment.Name, clientID)
+	maybeJob, agent, err := d.getAgentJob(ctx, clientID, payload.JobID, orgID)
+	if err != nil {
+		return err
+	}
+	job, ok := maybeJob.Get()
+	if !ok {
+		log.Printf("⚠️ Job %s not found - already completed, skipping artifact", payload.JobID)
+		return nil
+	}
+
+	err = d.discordClient.UploadFile(job.DiscordPayload.ChannelID, clients.DiscordUploadFileParams{
+		Filename:    payload.Attachment.Name,
+		ContentType: payload.Attachment.MimeType,
+		Content:     payload.Attachment.Content,
+		Comment:     trimDiscordMessage(payload.Comment),
+		ThreadID:    &job.DiscordPayload.ThreadID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload artifact to Discord: %w", err)
+	}
+	d.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorAgent,
+		agent.ID,
+		models.TranscriptMessageTypeArtifact,
+		payload.TranscriptContent(),
+		"",
+	)
+
+	// Update job timestamp to track activity
+	if err := d.jobsService.UpdateJobTimestamp(ctx, orgID, job.ID); err != nil {
+		return fmt.Errorf("failed to update job timestamp: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - uploaded artifact %s for job %s", payload.Attachment.Name, job.ID)
+	return nil
+}
+
+// DownloadAttachment downloads a file forwarded to an agent from the Discord message it was attached to
+func (d *DiscordUseCase) DownloadAttachment(ctx context.Context, source models.AttachmentSource) ([]byte, error) {
+	content, err := d.discordClient.DownloadAttachment(source.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download Discord attachment: %w", err)
+	}
+	return content, nil
+}
+
+// CleanupFailedDiscordJob handles the cleanup of a failed Discord job including Discord notifications and database cleanup
+// This is exported so core use case can call it when deregistering agents
+func (d *DiscordUseCase) CleanupFailedDiscordJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	failureMessage string,
+) error {
+	if job.DiscordPayload == nil {
+		log.Printf("❌ Job %s has no Discord payload", job.ID)
+		return fmt.Errorf("job has no Discord payload")
+	}
+	discordIntegrationID := job.DiscordPayload.IntegrationID
+	orgID := job.OrgID
+
+	// Get Discord integration to get guild ID
+	maybeIntegration, err := d.discordIntegrationsService.GetDiscordIntegrationByID(ctx, discordIntegrationID)
+	if err != nil {
+		log.Printf("❌ Failed to get Discord integration: %v", err)
+		// Continue with cleanup even if integration lookup fails
+	}
+
+	var guildID string
+	if maybeIntegration.IsPresent() {
+		guildID = maybeIntegration.MustGet().DiscordGuildID
+	}
+
+	// Send failure message to Discord thread
+	if guildID != "" {
+		if err := d.sendSystemMessage(ctx, discordIntegrationID, guildID, job.DiscordPayload.ChannelID, job.DiscordPayload.ThreadID, failureMessage); err != nil {
+			log.Printf("❌ Failed to send failure message to Discord thread %s: %v", job.DiscordPayload.ThreadID, err)
+			// Continue with cleanup even if Discord message fails
+		}
+	}
+	d.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorSystem,
+		"",
+		models.TranscriptMessageTypeSystemMessage,
+		failureMessage,
+		"",
+	)
+
+	// Update the top-level message emoji to ❌
+	if err := d.updateDiscordMessageReaction(ctx, job.DiscordPayload.ChannelID, job.DiscordPayload.MessageID, EmojiCrossMark, discordIntegrationID); err != nil {
+		log.Printf("❌ Failed to update discord message reaction to ❌ for failed job %s: %v", job.ID, err)
+		// Continue with cleanup even if reaction update fails
+	}
+
+	// Perform database operations within transaction
+	if err := d.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		// If agent ID is provided, unassign agent from job
+		if agentID != "" {
+			if err := d.agentsService.UnassignAgentFromJob(ctx, orgID, agentID, job.ID); err != nil {
+				log.Printf("❌ Failed to unassign agent %s from job %s: %v", agentID, job.ID, err)
+				return fmt.Errorf("failed to unassign agent from job: %w", err)
+			}
+			log.Printf("🔗 Unassigned agent %s from job %s", agentID, job.ID)
+		}
+
+		// Close the job as failed, keeping it and its processed messages as history
+		if err := d.jobsService.CloseJob(ctx, orgID, job.ID, models.JobStatusFailed, failureMessage, agentID); err != nil {
+			log.Printf("❌ Failed to close job %s: %v", job.ID, err)
+			return fmt.Errorf("failed to close job: %w", err)
+		}
+
+		log.Printf("📁 Closed failed job %s", job.ID)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to cleanup job %s in transaction: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// CloseIdleDiscordJob closes a job which was idle for longer than the organization's idle job timeout
+// The notice is posted in the thread, and the agent holding the job is unassigned and told the job was closed.
+func (d *DiscordUseCase) CloseIdleDiscordJob(ctx context.Context, job *models.Job, notice string) error {
+	log.Printf("📋 Starting to close idle Discord job %s", job.ID)
+	if job.DiscordPayload == nil {
+		log.Printf("❌ Job %s has no Discord payload", job.ID)
+		return fmt.Errorf("job has no Discord payload")
+	}
+	discordIntegrationID := job.DiscordPayload.IntegrationID
+	orgID := job.OrgID
+
+	maybeAgent, err := d.agentsService.GetAgentByJobID(ctx, orgID, job.ID)
+	if err != nil {
+		log.Printf("❌ Failed to find agent for job %s: %v", job.ID, err)
+		return fmt.Errorf("failed to get agent by job id: %w", err)
+	}
+	agent, hasAgent := maybeAgent.Get()
+
+	var assignedAgentID string
+	if err := d.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if hasAgent {
+			if err := d.agentsService.UnassignAgentFromJob(ctx, orgID, agent.ID, job.ID); err != nil {
+				log.Printf("❌ Failed to unassign agent %s from job %s: %v", agent.ID, job.ID, err)
+				return fmt.Errorf("failed to unassign agent from job: %w", err)
+			}
+			assignedAgentID = agent.ID
+			log.Printf("🔗 Unassigned agent %s from idle job %s", agent.ID, job.ID)
+		}
+
+		// Close the job as abandoned, keeping it and its processed messages as history
+		if err := d.jobsService.CloseJob(ctx, orgID, job.ID, models.JobStatusAbandoned, notice, assignedAgentID); err != nil {
+			log.Printf("❌ Failed to close idle job %s: %v", job.ID, err)
+			return fmt.Errorf("failed to close idle job: %w", err)
+		}
+		log.Printf("📁 Closed idle job %s", job.ID)
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to close idle job %s in transaction: %w", job.ID, err)
+	}
+
+	if hasAgent {
+		if err := d.notifyAgentJobClosed(ctx, job, agent, notice); err != nil {
+			log.Printf("⚠️ Failed to notify agent %s that idle job %s was closed: %v", agent.ID, job.ID, err)
+			// Don't return error - the job is closed and the agent's next message for it is rejected
+		}
+	}
+
+	if err := d.updateDiscordMessageReaction(
+		ctx,
+		job.DiscordPayload.ChannelID,
+		job.DiscordPayload.MessageID,
+		EmojiCheckMark,
+		discordIntegrationID,
+	); err != nil {
+		log.Printf("⚠️ Failed to update top-level message reaction for idle job %s: %v", job.ID, err)
+		// Don't return error - this is not critical to closing the job
+	}
+
+	// Get Discord integration to get guild ID for sending the closing notice
+	maybeIntegration, err := d.discordIntegrationsService.GetDiscordIntegrationByID(ctx, discordIntegrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get Discord integration: %w", err)
+	}
+	if !maybeIntegration.IsPresent() {
+		return fmt.Errorf("discord integration not found: %s", discordIntegrationID)
+	}
+	integration := maybeIntegration.MustGet()
+
+	if err := d.sendSystemMessage(
+		ctx,
+		discordIntegrationID,
+		integration.DiscordGuildID,
+		job.DiscordPayload.ChannelID,
+		job.DiscordPayload.ThreadID,
+		notice,
+	); err != nil {
+		log.Printf("❌ Failed to send closing notice to Discord thread %s: %v", job.DiscordPayload.ThreadID, err)
+		return fmt.Errorf("failed to send closing notice to Discord: %w", err)
+	}
+	d.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorSystem,
+		"",
+		models.TranscriptMessageTypeSystemMessage,
+		notice,
+		"",
+	)
+
+	log.Printf("📋 Completed successfully - closed idle Discord job %s", job.ID)
+	return nil
+}
+
+// RequeueDiscordJob unassigns a job from its agent and queues its in-progress messages again
+// The next agent picking up the job gets the earlier conversation as history - completed messages stay completed.
+// An empty agentID requeues a job whose agent is already gone.
+func (d *DiscordUseCase) RequeueDiscordJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	notice string,
+) error {
+	log.Printf("📋 Starting to requeue Discord job %s from agent %s", job.ID, agentID)
+	if job.DiscordPayload == nil {
+		log.Printf("❌ Job %s has no Discord payload", job.ID)
+		return fmt.Errorf("job has no Discord payload")
+	}
+	discordIntegrationID := job.DiscordPayload.IntegrationID
+	orgID := job.OrgID
+
+	var requeuedMessages []*models.ProcessedDiscordMessage
+	if err := d.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if agentID != "" {
+			if err := d.agentsService.UnassignAgentFromJob(ctx, orgID, agentID, job.ID); err != nil {
+				log.Printf("❌ Failed to unassign agent %s from job %s: %v", agentID, job.ID, err)
+				return fmt.Errorf("failed to unassign agent from job: %w", err)
+			}
+			log.Printf("🔗 Unassigned agent %s from job %s", agentID, job.ID)
+		}
+
+		// In-flight messages are sent to the new agent again, queued messages are still waiting for it
+		messages, err := d.discordMessagesService.GetProcessedMessagesByJobIDAndStatus(
+			ctx,
+			orgID,
+			job.ID,
+			models.ProcessedDiscordMessageStatusInProgress,
+			discordIntegrationID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get in-progress messages for job %s: %w", job.ID, err)
+		}
+
+		for _, message := range messages {
+			updatedMessage, err := d.discordMessagesService.UpdateProcessedDiscordMessage(
+				ctx,
+				orgID,
+				message.ID,
+				models.ProcessedDiscordMessageStatusQueued,
+				discordIntegrationID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to requeue message %s: %w", message.ID, err)
+			}
+			requeuedMessages = append(requeuedMessages, updatedMessage)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to requeue job %s in transaction: %w", job.ID, err)
+	}
+
+	// Show the top-level message as waiting again
+	if err := d.updateDiscordMessageReaction(ctx, job.DiscordPayload.ChannelID, job.DiscordPayload.MessageID, EmojiHourglass, discordIntegrationID); err != nil {
+		log.Printf("⚠️ Failed to update reaction for requeued job %s: %v", job.ID, err)
+	}
+
+	maybeIntegration, err := d.discordIntegrationsService.GetDiscordIntegrationByID(ctx, discordIntegrationID)
+	if err != nil {
+		log.Printf("❌ Failed to get Discord integration: %v", err)
+	} else if maybeIntegration.IsPresent() {
+		guildID := maybeIntegration.MustGet().DiscordGuildID
+		if err := d.sendSystemMessage(ctx, discordIntegrationID, guildID, job.DiscordPayload.ChannelID, job.DiscordPayload.ThreadID, notice); err != nil {
+			log.Printf("❌ Failed to send requeue notice to Discord thread %s: %v", job.DiscordPayload.ThreadID, err)
+			// Don't return error - the job is already queued for another agent
+		}
+	}
+
+	log.Printf("📋 Completed successfully - requeued Discord job %s with %d messages", job.ID, len(requeuedMessages))
+	return nil
+}
+
+// HasAvailableAgentForDiscordJob returns true if an agent matching the repository and agent selector of the job's
+// channel could take the job right now. Agents which have not been active since activeSince are not counted.
+func (d *DiscordUseCase) HasAvailableAgentForDiscordJob(
+	ctx context.Context,
+	job *models.Job,
+	activeSince time.Time,
+) (bool, error) {
+	if job.DiscordPayload == nil {
+		return false, fmt.Errorf("job has no Discord payload")
+	}
+
+	discordIntegrationID := job.DiscordPayload.IntegrationID
+	maybeIntegration, err := d.discordIntegrationsService.GetDiscordIntegrationByID(ctx, discordIntegrationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get Discord integration: %w", err)
+	}
+	integration, ok := maybeIntegration.Get()
+	if !ok {
+		return false, fmt.Errorf("Discord integration not found: %s", discordIntegrationID)
+	}
+
+	repoURL, agentSelector, err := d.getChannelRouting(ctx, job.OrgID, integration.DiscordGuildID, job)
+	if err != nil {
+		return false, fmt.Errorf("failed to get repository for job %s: %w", job.ID, err)
+	}
+	return d.agentsUseCase.HasAvailableAgent(ctx, repoURL, agentSelector, job.OrgID, activeSince)
+}
+
+// FailStuckDiscordJob fails the in-progress messages of a job whose agent stopped responding mid-turn
+// The messages and the top-level message are marked with ❌ and the explanation is posted in the thread. The agent,
+// if it still holds the job, is unassigned and the job stays open for follow-up messages.
+func (d *DiscordUseCase) FailStuckDiscordJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	explanation string,
+) error {
+	log.Printf("📋 Starting to fail stuck messages of Discord job %s", job.ID)
+	if job.DiscordPayload == nil {
+		log.Printf("❌ Job %s has no Discord payload", job.ID)
+		return fmt.Errorf("job has no Discord payload")
+	}
+	discordIntegrationID := job.DiscordPayload.IntegrationID
+	orgID := job.OrgID
+
+	var failedMessages []*models.ProcessedDiscordMessage
+	if err := d.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if agentID != "" {
+			if err := d.agentsService.UnassignAgentFromJob(ctx, orgID, agentID, job.ID); err != nil {
+				log.Printf("❌ Failed to unassign agent %s from job %s: %v", agentID, job.ID, err)
+				return fmt.Errorf("failed to unassign agent from job: %w", err)
+			}
+			log.Printf("🔗 Unassigned agent %s from stuck job %s", agentID, job.ID)
+		}
+
+		messages, err := d.discordMessagesService.GetProcessedMessagesByJobIDAndStatus(
+			ctx,
+			orgID,
+			job.ID,
+			models.ProcessedDiscordMessageStatusInProgress,
+			discordIntegrationID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get in-progress messages for job %s: %w", job.ID, err)
+		}
+
+		for _, message := range messages {
+			updatedMessage, err := d.discordMessagesService.UpdateProcessedDiscordMessage(
+				ctx,
+				orgID,
+				message.ID,
+				models.ProcessedDiscordMessageStatusFailed,
+				discordIntegrationID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to mark message %s as failed: %w", message.ID, err)
+			}
+			failedMessages = append(failedMessages, updatedMessage)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to fail stuck job %s in transaction: %w", job.ID, err)
+	}
+
+	// The top-level message shows the state of the whole job and is updated separately
+	for _, message := range failedMessages {
+		if message.DiscordMessageID == job.DiscordPayload.MessageID {
+			continue
+		}
+		if err := d.updateDiscordMessageReaction(
+			ctx,
+			message.DiscordThreadID,
+			message.DiscordMessageID,
+			deriveMessageReactionFromStatus(message.Status),
+			discordIntegrationID,
+		); err != nil {
+			log.Printf("⚠️ Failed to update reaction for failed message %s: %v", message.ID, err)
+		}
+	}
+	if err := d.updateDiscordMessageReaction(
+		ctx,
+		job.DiscordPayload.ChannelID,
+		job.DiscordPayload.MessageID,
+		EmojiCrossMark,
+		discordIntegrationID,
+	); err != nil {
+		log.Printf("⚠️ Failed to update top-level message reaction for stuck job %s: %v", job.ID, err)
+	}
+
+	// Get Discord integration to get guild ID for sending the explanation
+	maybeIntegration, err := d.discordIntegrationsService.GetDiscordIntegrationByID(ctx, discordIntegrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get Discord integration: %w", err)
+	}
+	if !maybeIntegration.IsPresent() {
+		return fmt.Errorf("discord integration not found: %s", discordIntegrationID)
+	}
+	integration := maybeIntegration.MustGet()
+
+	if err := d.sendSystemMessage(
+		ctx,
+		discordIntegrationID,
+		integration.DiscordGuildID,
+		job.DiscordPayload.ChannelID,
+		job.DiscordPayload.ThreadID,
+		explanation,
+	); err != nil {
+		log.Printf("❌ Failed to send failure explanation to Discord thread %s: %v", job.DiscordPayload.ThreadID, err)
+		return fmt.Errorf("failed to send failure explanation to Discord: %w", err)
+	}
+	d.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorSystem,
+		"",
+		models.TranscriptMessageTypeSystemMessage,
+		explanation,
+		"",
+	)
+
+	log.Printf("📋 Completed successfully - failed %d stuck messages of Discord job %s", len(failedMessages), job.ID)
+	return nil
+}
+
+// ProcessJobCancelled confirms in the job's thread that the agent acknowledged the cancellation of the job
+func (d *DiscordUseCase) ProcessJobCancelled(ctx context.Context, job *models.Job) error {
+	log.Printf("📋 Starting to confirm cancellation of Discord job %s", job.ID)
+	if job.DiscordPayload == nil {
+		return fmt.Errorf("job has no Discord payload")
+	}
+
+	maybeIntegration, err := d.discordIntegrationsService.GetDiscordIntegrationByID(ctx, job.DiscordPayload.IntegrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get Discord integration: %w", err)
+	}
+	if !maybeIntegration.IsPresent() {
+		return fmt.Errorf("discord integration not found: %s", job.DiscordPayload.IntegrationID)
+	}
+
+	d.finishProgressStatus(ctx, job)
+	if err := d.confirmJobCancelled(ctx, job, maybeIntegration.MustGet().DiscordGuildID); err != nil {
+		return err
+	}
+
+	log.Printf("📋 Completed successfully - confirmed cancellation of Discord job %s", job.ID)
+	return nil
+}
+
+// ProcessJobCancellationUnconfirmed tells the job's thread that the agent never acknowledged the cancellation of
+// the job, so it may still be working on it
+func (d *DiscordUseCase) ProcessJobCancellationUnconfirmed(ctx context.Context, job *models.Job) error {
+	log.Printf("📋 Starting to report unconfirmed cancellation of Discord job %s", job.ID)
+	if job.DiscordPayload == nil {
+		return fmt.Errorf("job has no Discord payload")
+	}
+
+	discordIntegrationID := job.DiscordPayload.IntegrationID
+	maybeIntegration, err := d.discordIntegrationsService.GetDiscordIntegrationByID(ctx, discordIntegrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get Discord integration: %w", err)
+	}
+	if !maybeIntegration.IsPresent() {
+		return fmt.Errorf("discord integration not found: %s", discordIntegrationID)
+	}
+
+	d.finishProgressStatus(ctx, job)
+	if err := d.sendSystemMessage(
+		ctx,
+		discordIntegrationID,
+		maybeIntegration.MustGet().DiscordGuildID,
+		job.DiscordPayload.ChannelID,
+		job.DiscordPayload.ThreadID,
+		"The stop could not be confirmed by the agent - it may still be working on this job",
+	); err != nil {
+		return fmt.Errorf("failed to send unconfirmed cancellation notice to Discord: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - reported unconfirmed cancellation of Discord job %s", job.ID)
+	return nil
+}
+
+// ProcessQueuedJobs processes jobs that are queued waiting for available agents
+func (d *DiscordUseCase) ProcessQueuedJobs(ctx context.Context) error {
+	log.Printf("📋 Starting to process queued Discord jobs")
+
+	// Get all discord integrations
+	integrations, err := d.discordIntegrationsService.GetAllDiscordIntegrations(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get discord integrations: %w", err)
 	}
@@ -857,9 +1683,27 @@ func (d *DiscordUseCase) ProcessQueuedJobs(ctx context.Context) error {
 		return nil
 	}
 
-	totalProcessedJobs := 0
+	totalProcessedJobs, err := d.dispatchQueuedJobsOfIntegrations(ctx, integrations)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("📋 Completed successfully - processed %d queued Discord jobs", totalProcessedJobs)
+	return nil
+}
 
-	for _, integration := range integrations {
+// dispatchQueuedJobsOfIntegrations dispatches the queued jobs of the integrations, one queue per organization
+// Returns the number of jobs handed to an agent
+func (d *DiscordUseCase) dispatchQueuedJobsOfIntegrations(
+	ctx context.Context,
+	integrations []models.DiscordIntegration,
+) (int, error) {
+	// Build one queue per organization so that jobs from all of its channels compete fairly for agents
+	queuedJobsByOrg := make(map[models.OrgID][]models.QueuedJob)
+	jobIntegrations := make(map[string]*models.DiscordIntegration)
+	var orgIDs []models.OrgID
+	for i := range integrations {
+		integration := &integrations[i]
 		discordIntegrationID := integration.ID
 
 		// Get queued messages for this integration
@@ -870,7 +1714,7 @@ func (d *DiscordUseCase) ProcessQueuedJobs(ctx context.Context) error {
 			discordIntegrationID,
 		)
 		if err != nil {
-			return fmt.Errorf("failed to get queued messages for integration %s: %w", discordIntegrationID, err)
+			return 0, fmt.Errorf("failed to get queued messages for integration %s: %w", discordIntegrationID, err)
 		}
 
 		if len(queuedMessages) == 0 {
@@ -879,111 +1723,166 @@ func (d *DiscordUseCase) ProcessQueuedJobs(ctx context.Context) error {
 
 		log.Printf("🔍 Found %d queued messages for integration %s", len(queuedMessages), discordIntegrationID)
 
-		// Group messages by job ID for efficient processing
-		jobMessagesMap := groupDiscordMessagesByJobID(queuedMessages)
-
-		// Try to assign each job with queued messages to an available agent
-		for jobID, messages := range jobMessagesMap {
-			// Only fetch job if we need job payload for processing
+		for jobID, firstQueuedAt := range firstQueuedAtByDiscordJobID(queuedMessages) {
 			maybeJob, err := d.jobsService.GetJobByID(ctx, integration.OrgID, jobID)
 			if err != nil {
-				return fmt.Errorf("failed to get job %s for integration %s: %w", jobID, discordIntegrationID, err)
+				return 0, fmt.Errorf("failed to get job %s for integration %s: %w", jobID, discordIntegrationID, err)
 			}
 			if maybeJob.IsAbsent() {
-				return fmt.Errorf("job %s not found for integration %s", jobID, discordIntegrationID)
+				return 0, fmt.Errorf("job %s not found for integration %s", jobID, discordIntegrationID)
 			}
-			job := maybeJob.MustGet()
 
-			log.Printf("🔄 Processing %d queued messages for job %s", len(messages), job.ID)
-
-			// Get organization ID for this integration
-			orgID := integration.OrgID
-
-			// Try to assign job to an available agent
-			clientID, assigned, err := d.agentsUseCase.TryAssignJobToAgent(ctx, job.ID, orgID)
-			if err != nil {
-				return fmt.Errorf("failed to assign queued job %s: %w", job.ID, err)
-			}
-
-			if !assigned {
-				log.Printf("⚠️ Still no agents available for queued job %s", job.ID)
-				continue
+			if _, exists := queuedJobsByOrg[integration.OrgID]; !exists {
+				orgIDs = append(orgIDs, integration.OrgID)
 			}
+			queuedJobsByOrg[integration.OrgID] = append(queuedJobsByOrg[integration.OrgID], models.QueuedJob{
+				Job:           maybeJob.MustGet(),
+				FirstQueuedAt: firstQueuedAt,
+			})
+			jobIntegrations[jobID] = integration
+		}
+	}
 
-			// Job was successfully assigned - get queued messages and send them to agent
-			queuedMessages, err := d.discordMessagesService.GetProcessedMessagesByJobIDAndStatus(
-				ctx,
-				integration.OrgID,
+	totalProcessedJobs := 0
+	for _, orgID := range orgIDs {
+		// Dispatch in fair-share FIFO order - jobs which can't be assigned stay queued without blocking the rest
+		queue := models.OrderQueuedJobs(queuedJobsByOrg[orgID])
+		log.Printf("📋 Dispatching %d queued jobs for organization %s", len(queue), orgID)
+
+		for position, queuedJob := range queue {
+			job := queuedJob.Job
+			log.Printf(
+				"🔄 Processing queued job %s (queue position %d, first queued at %s)",
 				job.ID,
-				models.ProcessedDiscordMessageStatusQueued,
-				discordIntegrationID,
+				position+1,
+				queuedJob.FirstQueuedAt.Format(time.RFC3339),
 			)
+
+			dispatched, err := d.dispatchQueuedJob(ctx, jobIntegrations[job.ID], job)
 			if err != nil {
-				return fmt.Errorf("failed to get queued messages for job %s: %w", job.ID, err)
+				return totalProcessedJobs, err
+			}
+			if dispatched {
+				totalProcessedJobs++
 			}
+		}
+	}
 
-			log.Printf("📨 Found %d queued messages for job %s", len(queuedMessages), job.ID)
+	return totalProcessedJobs, nil
+}
 
-			// Process each queued message
-			for _, message := range queuedMessages {
-				// Update message status to IN_PROGRESS
-				updatedMessage, err := d.discordMessagesService.UpdateProcessedDiscordMessage(
-					ctx,
-					integration.OrgID,
-					message.ID,
-					models.ProcessedDiscordMessageStatusInProgress,
-					discordIntegrationID,
-				)
-				if err != nil {
-					return fmt.Errorf("failed to update message %s status: %w", message.ID, err)
-				}
+// dispatchQueuedJob tries to assign a queued job to an agent and sends the job's queued messages to it
+// Returns false if no matching agent is available and the job stays queued
+func (d *DiscordUseCase) dispatchQueuedJob(
+	ctx context.Context,
+	integration *models.DiscordIntegration,
+	job *models.Job,
+) (bool, error) {
+	orgID := integration.OrgID
+	discordIntegrationID := integration.ID
 
-				// Determine if this is the first message in the job (new conversation)
-				// Check if this message's ID matches the job's message ID (i.e., it's the top-level message)
-				isNewConversation := false
-				if job.DiscordPayload != nil {
-					isNewConversation = updatedMessage.DiscordMessageID == job.DiscordPayload.MessageID
-				}
+	// A job without an agent is handed to a new one, which has not seen its earlier conversation
+	handedOver, err := d.isJobHandedOver(ctx, orgID, job)
+	if err != nil {
+		return false, fmt.Errorf("failed to check agent of queued job %s: %w", job.ID, err)
+	}
 
-				// Update Discord reaction to show processing (eyes emoji)
-				// For top-level messages, use the original channel and message ID from job payload
-				// For reply messages, use the thread and message ID from the processed message
-				var reactionChannelID, reactionMessageID string
-				if isNewConversation {
-					// Top-level message: use original channel and message ID
-					reactionChannelID = job.DiscordPayload.ChannelID
-					reactionMessageID = job.DiscordPayload.MessageID
-				} else {
-					// Reply message: use thread and message ID
-					reactionChannelID = updatedMessage.DiscordThreadID
-					reactionMessageID = updatedMessage.DiscordMessageID
-				}
+	// Try to assign job to an available agent matching the channel's repository and agent selector
+	repoURL, agentSelector, err := d.getChannelRouting(ctx, orgID, integration.DiscordGuildID, job)
+	if err != nil {
+		return false, fmt.Errorf("failed to get repository for queued job %s: %w", job.ID, err)
+	}
+	clientID, assigned, err := d.agentsUseCase.TryAssignJobToAgent(ctx, job.ID, repoURL, agentSelector, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to assign queued job %s: %w", job.ID, err)
+	}
 
-				if err := d.updateDiscordMessageReaction(ctx, reactionChannelID, reactionMessageID, EmojiEyes, discordIntegrationID); err != nil {
-					return fmt.Errorf("failed to update discord reaction for message %s: %w", message.ID, err)
-				}
+	if !assigned {
+		log.Printf("⚠️ Still no agents available for queued job %s", job.ID)
+		return false, nil
+	}
 
-				// Send work to assigned agent
-				if isNewConversation {
-					log.Printf("📬 Sending start conversation message for job %s to client %s", job.ID, clientID)
-					if err := d.sendStartConversationToAgent(ctx, clientID, updatedMessage); err != nil {
-						return fmt.Errorf("failed to send start conversation for message %s: %w", message.ID, err)
-					}
-				} else {
-					log.Printf("📬 Sending user message %s to client %s", message.ID, clientID)
-					if err := d.sendUserMessageToAgent(ctx, clientID, updatedMessage); err != nil {
-						return fmt.Errorf("failed to send user message %s: %w", message.ID, err)
-					}
-				}
+	// Job was successfully assigned - get queued messages and send them to agent
+	queuedMessages, err := d.discordMessagesService.GetProcessedMessagesByJobIDAndStatus(
+		ctx,
+		integration.OrgID,
+		job.ID,
+		models.ProcessedDiscordMessageStatusQueued,
+		discordIntegrationID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to get queued messages for job %s: %w", job.ID, err)
+	}
 
-				log.Printf("✅ Successfully assigned and sent queued message %s to agent", message.ID)
-			}
+	log.Printf("📨 Found %d queued messages for job %s", len(queuedMessages), job.ID)
 
-			totalProcessedJobs++
-			log.Printf("✅ Successfully processed queued job %s with %d messages", job.ID, len(queuedMessages))
+	// A new agent taking over a started conversation gets the earlier conversation as history with the first
+	// queued message, instead of answering the earlier messages again
+	var history []models.ConversationTurn
+	continuesConversation := handedOver && len(queuedMessages) > 0 && job.DiscordPayload != nil &&
+		queuedMessages[0].DiscordMessageID != job.DiscordPayload.MessageID
+	if continuesConversation {
+		history, err = d.getConversationHistory(ctx, job, queuedMessages)
+		if err != nil {
+			return false, err
 		}
 	}
 
-	log.Printf("📋 Completed successfully - processed %d queued Discord jobs", totalProcessedJobs)
-	return nil
+	// Process each queued message
+	for i, message := range queuedMessages {
+		// Update message status to IN_PROGRESS
+		updatedMessage, err := d.discordMessagesService.UpdateProcessedDiscordMessage(
+			ctx,
+			integration.OrgID,
+			message.ID,
+			models.ProcessedDiscordMessageStatusInProgress,
+			discordIntegrationID,
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to update message %s status: %w", message.ID, err)
+		}
+
+		// Determine if this is the first message in the job (new conversation)
+		// Check if this message's ID matches the job's message ID (i.e., it's the top-level message)
+		isNewConversation := false
+		if job.DiscordPayload != nil {
+			isNewConversation = updatedMessage.DiscordMessageID == job.DiscordPayload.MessageID
+		}
+
+		// Update Discord reaction to show processing (eyes emoji)
+		// For top-level messages, use the original channel and message ID from job payload
+		// For reply messages, use the thread and message ID from the processed message
+		var reactionChannelID, reactionMessageID string
+		if isNewConversation {
+			// Top-level message: use original channel and message ID
+			reactionChannelID = job.DiscordPayload.ChannelID
+			reactionMessageID = job.DiscordPayload.MessageID
+		} else {
+			// Reply message: use thread and message ID
+			reactionChannelID = updatedMessage.DiscordThreadID
+			reactionMessageID = updatedMessage.DiscordMessageID
+		}
+
+		if err := d.updateDiscordMessageReaction(ctx, reactionChannelID, reactionMessageID, EmojiEyes, discordIntegrationID); err != nil {
+			return false, fmt.Errorf("failed to update discord reaction for message %s: %w", message.ID, err)
+		}
+
+		// Send work to assigned agent
+		if isNewConversation || (continuesConversation && i == 0) {
+			log.Printf("📬 Sending start conversation message for job %s to client %s", job.ID, clientID)
+			if err := d.sendStartConversationToAgent(ctx, clientID, updatedMessage, history); err != nil {
+				return false, fmt.Errorf("failed to send start conversation for message %s: %w", message.ID, err)
+			}
+		} else {
+			log.Printf("📬 Sending user message %s to client %s", message.ID, clientID)
+			if err := d.sendUserMessageToAgent(ctx, clientID, updatedMessage); err != nil {
+				return false, fmt.Errorf("failed to send user message %s: %w", message.ID, err)
+			}
+		}
+
+		log.Printf("✅ Successfully assigned and sent queued message %s to agent", message.ID)
+	}
+
+	log.Printf("✅ Successfully processed queued job %s with %d messages", job.ID, len(queuedMessages))
+	return true, nil
 }
@@ -2,17 +2,20 @@ package discord
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/samber/mo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"ccbackend/clients"
 	discordclient "ccbackend/clients/discord"
 	"ccbackend/clients/socketio"
 	"ccbackend/models"
 	"ccbackend/services/agents"
+	"ccbackend/services/connectedchannels"
 	discordintegrations "ccbackend/services/discord_integrations"
 	"ccbackend/services/discordmessages"
 	"ccbackend/services/jobs"
@@ -36,6 +39,7 @@ type discordUseCaseMocks struct {
 	jobsService                *jobs.MockJobsService
 	discordMessagesService     *discordmessages.MockDiscordMessagesService
 	discordIntegrationsService *discordintegrations.MockDiscordIntegrationsService
+	connectedChannelsService   *connectedchannels.MockConnectedChannelsService
 	txManager                  *txmanager.MockTransactionManager
 	agentsUseCase              *agentsUseCase.MockAgentsUseCase
 }
@@ -49,6 +53,7 @@ func setupDiscordUseCaseTest(t *testing.T) *discordUseCaseTestFixture {
 		jobsService:                new(jobs.MockJobsService),
 		discordMessagesService:     new(discordmessages.MockDiscordMessagesService),
 		discordIntegrationsService: new(discordintegrations.MockDiscordIntegrationsService),
+		connectedChannelsService:   new(connectedchannels.MockConnectedChannelsService),
 		txManager:                  new(txmanager.MockTransactionManager),
 		agentsUseCase:              new(agentsUseCase.MockAgentsUseCase),
 	}
@@ -60,6 +65,7 @@ func setupDiscordUseCaseTest(t *testing.T) *discordUseCaseTestFixture {
 		mocks.jobsService,
 		mocks.discordMessagesService,
 		mocks.discordIntegrationsService,
+		mocks.connectedChannelsService,
 		mocks.txManager,
 		mocks.agentsUseCase,
 	)
@@ -79,6 +85,7 @@ func (f *discordUseCaseTestFixture) assertAllExpectations(t *testing.T) {
 	f.mocks.jobsService.AssertExpectations(t)
 	f.mocks.discordMessagesService.AssertExpectations(t)
 	f.mocks.discordIntegrationsService.AssertExpectations(t)
+	f.mocks.connectedChannelsService.AssertExpectations(t)
 	f.mocks.txManager.AssertExpectations(t)
 	f.mocks.agentsUseCase.AssertExpectations(t)
 }
@@ -145,12 +152,6 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 			DiscordGuildID: testGuildID,
 		}
 
-		connectedAgent := &models.ActiveAgent{
-			ID:             testutils.GenerateAgentID(),
-			WSConnectionID: testWSConnectionID,
-			OrgID:          testOrgID,
-		}
-
 		processedMessage := &models.ProcessedDiscordMessage{
 			ID:                   testProcessedID,
 			JobID:                testJobID,
@@ -170,13 +171,16 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 			Return(jobResult, nil)
 		fixture.mocks.discordIntegrationsService.On("GetDiscordIntegrationByID", fixture.ctx, testIntegrationID).
 			Return(mo.Some(discordIntegration), nil)
-		fixture.mocks.wsClient.On("GetClientIDs").Return([]string{testWSConnectionID})
-		fixture.mocks.agentsService.On("GetConnectedActiveAgents", fixture.ctx, testOrgID, []string{testWSConnectionID}).
-			Return([]*models.ActiveAgent{connectedAgent}, nil)
-		fixture.mocks.agentsUseCase.On("GetOrAssignAgentForJob", fixture.ctx, jobResult.Job, testThreadID, testOrgID).
-			Return(testWSConnectionID, nil)
-		fixture.mocks.discordMessagesService.On("CreateProcessedDiscordMessage", fixture.ctx, testOrgID, testJobID, testMessageID, testThreadID, "Hello bot, help me with something", testIntegrationID, models.ProcessedDiscordMessageStatusInProgress).
+		fixture.mocks.connectedChannelsService.On("GetDiscordConnectedChannel", fixture.ctx, testOrgID, testGuildID, testChannelID).
+			Return(mo.None[*models.DiscordConnectedChannel](), nil)
+		fixture.mocks.jobsService.On("HasQueuedJobsAbovePriority", fixture.ctx, testOrgID, models.JobTypeDiscord, models.JobPriorityNormal).
+			Return(false, nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, testJobID, "", models.AgentLabels(nil), testOrgID).
+			Return(testWSConnectionID, true, nil)
+		fixture.mocks.discordMessagesService.On("CreateProcessedDiscordMessage", fixture.ctx, testOrgID, testJobID, testMessageID, testThreadID, "Hello bot, help me with something", testIntegrationID, models.ProcessedDiscordMessageStatusInProgress, models.Attachments(nil)).
 			Return(processedMessage, nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorUser, testUserID, models.TranscriptMessageTypeUserMessage, "Hello bot, help me with something", testProcessedID).
+			Return(&models.JobTranscriptMessage{}, nil)
 		fixture.mocks.discordClient.On("AddReaction", testChannelID, testMessageID, EmojiHourglass).Return(nil)
 		fixture.mocks.discordClient.On("RemoveReaction", testChannelID, testMessageID, mock.AnythingOfType("string")).
 			Return(nil).
@@ -189,8 +193,15 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 		// Expect sendStartConversationToAgent
 		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
 			Return(mo.Some(jobResult.Job), nil).Maybe()
-		fixture.mocks.wsClient.On("SendMessage", testWSConnectionID, mock.AnythingOfType("models.BaseMessage")).
-			Return(nil)
+		fixture.mocks.agentsService.On(
+			"SendMessageToAgent",
+			fixture.ctx,
+			testOrgID,
+			testWSConnectionID,
+			testJobID,
+			mock.Anything,
+			mock.AnythingOfType("models.BaseMessage"),
+		).Return(nil)
 
 		// Execute
 		err := fixture.useCase.ProcessDiscordMessageEvent(
@@ -258,6 +269,7 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -268,6 +280,7 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -346,11 +359,16 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 			Return(jobResult, nil)
 		mockDiscordIntegrationsService.On("GetDiscordIntegrationByID", ctx, testIntegrationID).
 			Return(mo.Some(discordIntegration), nil)
-		mockWSClient.On("GetClientIDs").Return([]string{})
-		mockAgentsService.On("GetConnectedActiveAgents", ctx, testOrgID, []string{}).
-			Return([]*models.ActiveAgent{}, nil)
-		mockDiscordMessagesService.On("CreateProcessedDiscordMessage", ctx, testOrgID, testJobID, testMessageID, testThreadID, "Hello bot, help me with something", testIntegrationID, models.ProcessedDiscordMessageStatusQueued).
+		mockConnectedChannelsService.On("GetDiscordConnectedChannel", ctx, testOrgID, testGuildID, testChannelID).
+			Return(mo.None[*models.DiscordConnectedChannel](), nil)
+		mockJobsService.On("HasQueuedJobsAbovePriority", ctx, testOrgID, models.JobTypeDiscord, models.JobPriorityNormal).
+			Return(false, nil)
+		mockAgentsUseCase.On("TryAssignJobToAgent", ctx, testJobID, "", models.AgentLabels(nil), testOrgID).
+			Return("", false, nil)
+		mockDiscordMessagesService.On("CreateProcessedDiscordMessage", ctx, testOrgID, testJobID, testMessageID, testThreadID, "Hello bot, help me with something", testIntegrationID, models.ProcessedDiscordMessageStatusQueued, models.Attachments(nil)).
 			Return(processedMessage, nil)
+		mockJobsService.On("AddJobTranscriptMessage", ctx, testOrgID, testJobID, models.TranscriptAuthorUser, testUserID, models.TranscriptMessageTypeUserMessage, event.Content, testProcessedID).
+			Return(&models.JobTranscriptMessage{}, nil)
 		mockDiscordClient.On("AddReaction", testChannelID, testMessageID, EmojiHourglass).Return(nil)
 		mockDiscordClient.On("RemoveReaction", testChannelID, testMessageID, mock.AnythingOfType("string")).
 			Return(nil).
@@ -372,6 +390,349 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 		mockDiscordMessagesService.AssertExpectations(t)
 	})
 
+	t.Run("no_agent_for_channel_repo_queues_and_notifies", func(t *testing.T) {
+		// Setup
+		fixture := setupDiscordUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testMessageID := testutils.GenerateDiscordMessageID()
+		testChannelID := testutils.GenerateDiscordChannelID()
+		testGuildID := testutils.GenerateDiscordGuildID()
+		testUserID := testutils.GenerateDiscordUserID()
+		testBotID := testutils.GenerateDiscordBotID()
+		testThreadID := testutils.GenerateDiscordThreadID()
+		testIntegrationID := testutils.GenerateDiscordIntegrationID()
+		testOrgID := testutils.GenerateOrgID()
+		testJobID := testutils.GenerateJobID()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+		testRepoURL := "github.com/acme/backend"
+
+		event := models.DiscordMessageEvent{
+			MessageID: testMessageID,
+			ChannelID: testChannelID,
+			GuildID:   testGuildID,
+			UserID:    testUserID,
+			Content:   "Hello bot, fix the API",
+			Mentions:  []string{testBotID},
+			ThreadID:  nil,
+		}
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			DiscordPayload: &models.DiscordJobPayload{
+				MessageID:     testMessageID,
+				ChannelID:     testChannelID,
+				ThreadID:      testThreadID,
+				UserID:        testUserID,
+				IntegrationID: testIntegrationID,
+			},
+		}
+
+		discordIntegration := &models.DiscordIntegration{
+			ID:             testIntegrationID,
+			OrgID:          testOrgID,
+			DiscordGuildID: testGuildID,
+		}
+
+		connectedChannel := &models.DiscordConnectedChannel{
+			OrgID:          testOrgID,
+			GuildID:        testGuildID,
+			ChannelID:      testChannelID,
+			DefaultRepoURL: &testRepoURL,
+		}
+
+		processedMessage := &models.ProcessedDiscordMessage{
+			ID:                   testProcessedID,
+			JobID:                testJobID,
+			DiscordMessageID:     testMessageID,
+			DiscordThreadID:      testThreadID,
+			TextContent:          "Hello bot, fix the API",
+			DiscordIntegrationID: testIntegrationID,
+			OrgID:                testOrgID,
+			Status:               models.ProcessedDiscordMessageStatusQueued,
+		}
+
+		// Configure expectations
+		fixture.mocks.discordClient.On("GetBotUser").
+			Return(&clients.DiscordBotUser{ID: testBotID, Username: testutils.GenerateDiscordBotUsername(), Bot: true}, nil)
+		fixture.mocks.discordClient.On("CreatePublicThread", testChannelID, testMessageID, mock.AnythingOfType("string")).
+			Return(&clients.DiscordThreadResponse{ThreadID: testThreadID, ThreadName: "CC Sesh #1234"}, nil)
+		fixture.mocks.jobsService.On("GetOrCreateJobForDiscordThread", fixture.ctx, testOrgID, testMessageID, testChannelID, testThreadID, testUserID, testIntegrationID).
+			Return(&models.JobCreationResult{Job: job, Status: models.JobCreationStatusCreated}, nil)
+		fixture.mocks.discordIntegrationsService.On("GetDiscordIntegrationByID", fixture.ctx, testIntegrationID).
+			Return(mo.Some(discordIntegration), nil)
+		fixture.mocks.connectedChannelsService.On("GetDiscordConnectedChannel", fixture.ctx, testOrgID, testGuildID, testChannelID).
+			Return(mo.Some(connectedChannel), nil)
+		fixture.mocks.jobsService.On("HasQueuedJobsAbovePriority", fixture.ctx, testOrgID, models.JobTypeDiscord, models.JobPriorityNormal).
+			Return(false, nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, testJobID, testRepoURL, models.AgentLabels(nil), testOrgID).
+			Return("", false, nil)
+		fixture.mocks.discordMessagesService.On("CreateProcessedDiscordMessage", fixture.ctx, testOrgID, testJobID, testMessageID, testThreadID, "Hello bot, fix the API", testIntegrationID, models.ProcessedDiscordMessageStatusQueued, models.Attachments(nil)).
+			Return(processedMessage, nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorUser, testUserID, models.TranscriptMessageTypeUserMessage, "Hello bot, fix the API", testProcessedID).
+			Return(&models.JobTranscriptMessage{}, nil)
+		fixture.mocks.discordMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedDiscordMessageStatusQueued, testIntegrationID).
+			Return([]*models.ProcessedDiscordMessage{processedMessage}, nil)
+		fixture.mocks.discordClient.On("AddReaction", testChannelID, testMessageID, mock.AnythingOfType("string")).Return(nil)
+		fixture.mocks.discordClient.On("RemoveReaction", testChannelID, testMessageID, mock.AnythingOfType("string")).
+			Return(nil).
+			Maybe()
+		// Expect a notice in the thread naming the repository the job is waiting on
+		fixture.mocks.discordClient.On("PostMessage", testChannelID, mock.MatchedBy(func(params clients.DiscordMessageParams) bool {
+			return strings.Contains(params.Content, testRepoURL) &&
+				params.ThreadID != nil && *params.ThreadID == testThreadID
+		})).
+			Return(&clients.DiscordPostMessageResponse{}, nil)
+
+		// Execute
+		err := fixture.useCase.ProcessDiscordMessageEvent(fixture.ctx, event, testIntegrationID, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		fixture.assertAllExpectations(t)
+		fixture.mocks.agentsService.AssertNotCalled(t, "SendMessageToAgent")
+	})
+
+	t.Run("message_queued_behind_earlier_ones_does_not_repeat_notice", func(t *testing.T) {
+		// Setup
+		fixture := setupDiscordUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testMessageID := testutils.GenerateDiscordMessageID()
+		testChannelID := testutils.GenerateDiscordChannelID()
+		testGuildID := testutils.GenerateDiscordGuildID()
+		testUserID := testutils.GenerateDiscordUserID()
+		testBotID := testutils.GenerateDiscordBotID()
+		testThreadID := testutils.GenerateDiscordThreadID()
+		testIntegrationID := testutils.GenerateDiscordIntegrationID()
+		testOrgID := testutils.GenerateOrgID()
+		testJobID := testutils.GenerateJobID()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+		testRepoURL := "github.com/acme/backend"
+
+		event := models.DiscordMessageEvent{
+			MessageID: testMessageID,
+			ChannelID: testChannelID,
+			GuildID:   testGuildID,
+			UserID:    testUserID,
+			Content:   "Hello bot, fix the API",
+			Mentions:  []string{testBotID},
+			ThreadID:  nil,
+		}
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			DiscordPayload: &models.DiscordJobPayload{
+				MessageID:     testMessageID,
+				ChannelID:     testChannelID,
+				ThreadID:      testThreadID,
+				UserID:        testUserID,
+				IntegrationID: testIntegrationID,
+			},
+		}
+
+		discordIntegration := &models.DiscordIntegration{
+			ID:             testIntegrationID,
+			OrgID:          testOrgID,
+			DiscordGuildID: testGuildID,
+		}
+
+		connectedChannel := &models.DiscordConnectedChannel{
+			OrgID:          testOrgID,
+			GuildID:        testGuildID,
+			ChannelID:      testChannelID,
+			DefaultRepoURL: &testRepoURL,
+		}
+
+		processedMessage := &models.ProcessedDiscordMessage{
+			ID:                   testProcessedID,
+			JobID:                testJobID,
+			DiscordMessageID:     testMessageID,
+			DiscordThreadID:      testThreadID,
+			TextContent:          "Hello bot, fix the API",
+			DiscordIntegrationID: testIntegrationID,
+			OrgID:                testOrgID,
+			Status:               models.ProcessedDiscordMessageStatusQueued,
+		}
+
+		// Configure expectations
+		fixture.mocks.discordClient.On("GetBotUser").
+			Return(&clients.DiscordBotUser{ID: testBotID, Username: testutils.GenerateDiscordBotUsername(), Bot: true}, nil)
+		fixture.mocks.discordClient.On("CreatePublicThread", testChannelID, testMessageID, mock.AnythingOfType("string")).
+			Return(&clients.DiscordThreadResponse{ThreadID: testThreadID, ThreadName: "CC Sesh #1234"}, nil)
+		fixture.mocks.jobsService.On("GetOrCreateJobForDiscordThread", fixture.ctx, testOrgID, testMessageID, testChannelID, testThreadID, testUserID, testIntegrationID).
+			Return(&models.JobCreationResult{Job: job, Status: models.JobCreationStatusCreated}, nil)
+		fixture.mocks.discordIntegrationsService.On("GetDiscordIntegrationByID", fixture.ctx, testIntegrationID).
+			Return(mo.Some(discordIntegration), nil)
+		fixture.mocks.connectedChannelsService.On("GetDiscordConnectedChannel", fixture.ctx, testOrgID, testGuildID, testChannelID).
+			Return(mo.Some(connectedChannel), nil)
+		fixture.mocks.jobsService.On("HasQueuedJobsAbovePriority", fixture.ctx, testOrgID, models.JobTypeDiscord, models.JobPriorityNormal).
+			Return(false, nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, testJobID, testRepoURL, models.AgentLabels(nil), testOrgID).
+			Return("", false, nil)
+		fixture.mocks.discordMessagesService.On("CreateProcessedDiscordMessage", fixture.ctx, testOrgID, testJobID, testMessageID, testThreadID, "Hello bot, fix the API", testIntegrationID, models.ProcessedDiscordMessageStatusQueued, models.Attachments(nil)).
+			Return(processedMessage, nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorUser, testUserID, models.TranscriptMessageTypeUserMessage, "Hello bot, fix the API", testProcessedID).
+			Return(&models.JobTranscriptMessage{}, nil)
+		// An earlier message of the job is still waiting, so the thread was told already
+		earlierMessage := &models.ProcessedDiscordMessage{ID: testutils.GenerateProcessedMessageID(), JobID: testJobID}
+		fixture.mocks.discordMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedDiscordMessageStatusQueued, testIntegrationID).
+			Return([]*models.ProcessedDiscordMessage{earlierMessage, processedMessage}, nil)
+		fixture.mocks.discordClient.On("AddReaction", testChannelID, testMessageID, mock.AnythingOfType("string")).Return(nil)
+		fixture.mocks.discordClient.On("RemoveReaction", testChannelID, testMessageID, mock.AnythingOfType("string")).
+			Return(nil).
+			Maybe()
+		// Execute
+		err := fixture.useCase.ProcessDiscordMessageEvent(fixture.ctx, event, testIntegrationID, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		fixture.assertAllExpectations(t)
+		fixture.mocks.agentsService.AssertNotCalled(t, "SendMessageToAgent")
+		fixture.mocks.discordClient.AssertNotCalled(t, "PostMessage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reply_after_handover_with_nothing_queued_starts_conversation_with_history", func(t *testing.T) {
+		// Setup
+		fixture := setupDiscordUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testMessageID := testutils.GenerateDiscordMessageID()
+		testReplyID := testutils.GenerateDiscordMessageID()
+		testChannelID := testutils.GenerateDiscordChannelID()
+		testGuildID := testutils.GenerateDiscordGuildID()
+		testUserID := testutils.GenerateDiscordUserID()
+		testBotID := testutils.GenerateDiscordBotID()
+		testThreadID := testutils.GenerateDiscordThreadID()
+		testIntegrationID := testutils.GenerateDiscordIntegrationID()
+		testOrgID := testutils.GenerateOrgID()
+		testJobID := testutils.GenerateJobID()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+		testFirstProcessedID := testutils.GenerateProcessedMessageID()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+
+		// A reply in the thread of a job which was requeued after its agent disconnected, with every earlier
+		// message already completed
+		event := models.DiscordMessageEvent{
+			MessageID: testReplyID,
+			ChannelID: testChannelID,
+			GuildID:   testGuildID,
+			UserID:    testUserID,
+			Content:   "Now add a test",
+			Mentions:  []string{testBotID},
+			ThreadID:  &testThreadID,
+		}
+
+		botUser := &clients.DiscordBotUser{
+			ID:       testBotID,
+			Username: testutils.GenerateDiscordBotUsername(),
+			Bot:      true,
+		}
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			DiscordPayload: &models.DiscordJobPayload{
+				MessageID:     testMessageID,
+				ChannelID:     testChannelID,
+				ThreadID:      testThreadID,
+				UserID:        testUserID,
+				IntegrationID: testIntegrationID,
+			},
+		}
+
+		discordIntegration := &models.DiscordIntegration{
+			ID:             testIntegrationID,
+			OrgID:          testOrgID,
+			DiscordGuildID: testGuildID,
+		}
+
+		processedMessage := &models.ProcessedDiscordMessage{
+			ID:                   testProcessedID,
+			JobID:                testJobID,
+			DiscordMessageID:     testReplyID,
+			DiscordThreadID:      testThreadID,
+			TextContent:          "Now add a test",
+			DiscordIntegrationID: testIntegrationID,
+			OrgID:                testOrgID,
+			Status:               models.ProcessedDiscordMessageStatusInProgress,
+		}
+
+		transcript := []*models.JobTranscriptMessage{
+			{
+				Author:             models.TranscriptAuthorUser,
+				MessageType:        models.TranscriptMessageTypeUserMessage,
+				Content:            "Fix the bug",
+				ProcessedMessageID: testFirstProcessedID,
+			},
+			{
+				Author:             models.TranscriptAuthorAgent,
+				MessageType:        models.TranscriptMessageTypeAssistantMessage,
+				Content:            "Fixed it",
+				ProcessedMessageID: testFirstProcessedID,
+			},
+			{
+				Author:             models.TranscriptAuthorUser,
+				MessageType:        models.TranscriptMessageTypeUserMessage,
+				Content:            "Now add a test",
+				ProcessedMessageID: testProcessedID,
+			},
+		}
+
+		// Configure expectations
+		fixture.mocks.discordClient.On("GetBotUser").Return(botUser, nil)
+		fixture.mocks.jobsService.On("GetJobByDiscordThread", fixture.ctx, testOrgID, testThreadID, testIntegrationID).
+			Return(mo.Some(job), nil)
+		fixture.mocks.jobsService.On("GetOrCreateJobForDiscordThread", fixture.ctx, testOrgID, testReplyID, testChannelID, testThreadID, testUserID, testIntegrationID).
+			Return(&models.JobCreationResult{Job: job, Status: models.JobCreationStatusNA}, nil)
+		fixture.mocks.discordIntegrationsService.On("GetDiscordIntegrationByID", fixture.ctx, testIntegrationID).
+			Return(mo.Some(discordIntegration), nil)
+		fixture.mocks.connectedChannelsService.On("GetDiscordConnectedChannel", fixture.ctx, testOrgID, testGuildID, testChannelID).
+			Return(mo.None[*models.DiscordConnectedChannel](), nil)
+		fixture.mocks.agentsService.On("GetAgentByJobID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, testJobID, "", models.AgentLabels(nil), testOrgID).
+			Return(testWSConnectionID, true, nil)
+		fixture.mocks.discordMessagesService.On("CreateProcessedDiscordMessage", fixture.ctx, testOrgID, testJobID, testReplyID, testThreadID, "Now add a test", testIntegrationID, models.ProcessedDiscordMessageStatusInProgress, models.Attachments(nil)).
+			Return(processedMessage, nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorUser, testUserID, models.TranscriptMessageTypeUserMessage, "Now add a test", testProcessedID).
+			Return(&models.JobTranscriptMessage{}, nil)
+		fixture.mocks.jobsService.On("GetJobTranscript", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(transcript), nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(job), nil).Maybe()
+		fixture.mocks.discordClient.On("AddReaction", mock.Anything, mock.Anything, mock.AnythingOfType("string")).
+			Return(nil).
+			Maybe()
+		fixture.mocks.discordClient.On("RemoveReaction", mock.Anything, mock.Anything, mock.AnythingOfType("string")).
+			Return(nil).
+			Maybe()
+
+		var sentMessage models.BaseMessage
+		fixture.mocks.agentsService.On("SendMessageToAgent", fixture.ctx, testOrgID, testWSConnectionID, testJobID, testProcessedID, mock.AnythingOfType("models.BaseMessage")).
+			Run(func(args mock.Arguments) {
+				sentMessage = args.Get(5).(models.BaseMessage)
+			}).
+			Return(nil)
+
+		// Execute
+		err := fixture.useCase.ProcessDiscordMessageEvent(fixture.ctx, event, testIntegrationID, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, models.MessageTypeStartConversation, sentMessage.Type)
+		payload, ok := sentMessage.Payload.(models.StartConversationPayload)
+		require.True(t, ok)
+		assert.Equal(t, "Now add a test", payload.Message)
+		assert.Equal(t, []models.ConversationTurn{
+			{Author: models.ConversationAuthorUser, Message: "Fix the bug"},
+			{Author: models.ConversationAuthorAssistant, Message: "Fixed it"},
+		}, payload.History)
+		fixture.assertAllExpectations(t)
+	})
+
 	t.Run("thread_reply_no_existing_job_error", func(t *testing.T) {
 		// Setup
 		ctx := context.Background()
@@ -381,6 +742,7 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -391,6 +753,7 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -426,6 +789,8 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 		mockDiscordClient.On("GetBotUser").Return(botUser, nil)
 		mockJobsService.On("GetJobByDiscordThread", ctx, testOrgID, testThreadID, testIntegrationID).
 			Return(mo.None[*models.Job](), nil) // No existing job
+		mockJobsService.On("HasClosedJobForDiscordThread", ctx, testOrgID, testThreadID, testIntegrationID).
+			Return(false, nil)
 		// Expect sendSystemMessage call for error
 		mockDiscordClient.On("PostMessage", testChannelID, mock.MatchedBy(func(params clients.DiscordMessageParams) bool {
 			return params.Content == EmojiGear+" Error: new jobs can only be started from top-level messages" &&
@@ -442,6 +807,70 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 		mockJobsService.AssertExpectations(t)
 	})
 
+	t.Run("stop_command_in_thread_of_closed_job_notifies_user", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockDiscordClient := new(discordclient.MockDiscordClient)
+		mockWSClient := new(socketio.MockSocketIOClient)
+		mockAgentsService := new(agents.MockAgentsService)
+		mockJobsService := new(jobs.MockJobsService)
+		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
+		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
+		mockTxManager := new(txmanager.MockTransactionManager)
+		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
+
+		useCase := NewDiscordUseCase(
+			mockDiscordClient,
+			mockWSClient,
+			mockAgentsService,
+			mockJobsService,
+			mockDiscordMessagesService,
+			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
+			mockTxManager,
+			mockAgentsUseCase,
+		)
+
+		testChannelID := testutils.GenerateDiscordChannelID()
+		testBotID := testutils.GenerateDiscordBotID()
+		testThreadID := testutils.GenerateDiscordThreadID()
+		testIntegrationID := testutils.GenerateDiscordIntegrationID()
+		testOrgID := testutils.GenerateOrgID()
+
+		event := models.DiscordMessageEvent{
+			MessageID: testutils.GenerateDiscordMessageID(),
+			ChannelID: testChannelID,
+			GuildID:   testutils.GenerateDiscordGuildID(),
+			UserID:    testutils.GenerateDiscordUserID(),
+			Content:   "<@" + testBotID + "> stop",
+			Mentions:  []string{testBotID},
+			ThreadID:  &testThreadID,
+		}
+
+		// Configure expectations
+		mockDiscordClient.On("GetBotUser").Return(&clients.DiscordBotUser{ID: testBotID, Bot: true}, nil)
+		mockJobsService.On("GetJobByDiscordThread", ctx, testOrgID, testThreadID, testIntegrationID).
+			Return(mo.None[*models.Job](), nil)
+		mockJobsService.On("HasClosedJobForDiscordThread", ctx, testOrgID, testThreadID, testIntegrationID).
+			Return(true, nil)
+		mockDiscordClient.On("PostMessage", testChannelID, mock.MatchedBy(func(params clients.DiscordMessageParams) bool {
+			return params.Content == EmojiGear+" There is nothing to stop - the job in this thread is already closed" &&
+				params.ThreadID != nil && *params.ThreadID == testThreadID
+		})).
+			Return(&clients.DiscordPostMessageResponse{}, nil)
+
+		// Execute
+		err := useCase.ProcessDiscordMessageEvent(ctx, event, testIntegrationID, testOrgID)
+
+		// Assert - the stop command does not start a new job
+		assert.NoError(t, err)
+		mockDiscordClient.AssertExpectations(t)
+		mockJobsService.AssertExpectations(t)
+		mockJobsService.AssertNotCalled(t, "GetOrCreateJobForDiscordThread", mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
 	t.Run("discord_integration_not_found", func(t *testing.T) {
 		// Setup
 		ctx := context.Background()
@@ -451,6 +880,7 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -461,6 +891,7 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -510,55 +941,252 @@ func TestProcessDiscordMessageEvent(t *testing.T) {
 					IntegrationID: testIntegrationID,
 				},
 			},
-			Status: models.JobCreationStatusCreated,
+			Status: models.JobCreationStatusCreated,
+		}
+
+		// Configure expectations
+		mockDiscordClient.On("GetBotUser").Return(botUser, nil)
+		mockDiscordClient.On("CreatePublicThread", testChannelID, testMessageID, mock.AnythingOfType("string")).
+			Return(threadResponse, nil)
+		mockJobsService.On("GetOrCreateJobForDiscordThread", ctx, testOrgID, testMessageID, testChannelID, testThreadID, testUserID, testIntegrationID).
+			Return(jobResult, nil)
+		mockDiscordIntegrationsService.On("GetDiscordIntegrationByID", ctx, testIntegrationID).
+			Return(mo.None[*models.DiscordIntegration](), nil) // Integration not found
+
+		// Execute
+		err := useCase.ProcessDiscordMessageEvent(ctx, event, testIntegrationID, testOrgID)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "discord integration not found")
+		mockDiscordClient.AssertExpectations(t)
+		mockJobsService.AssertExpectations(t)
+		mockDiscordIntegrationsService.AssertExpectations(t)
+	})
+}
+
+func TestProcessDiscordReactionEvent(t *testing.T) {
+	t.Run("success_valid_completion_reaction", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockDiscordClient := new(discordclient.MockDiscordClient)
+		mockWSClient := new(socketio.MockSocketIOClient)
+		mockAgentsService := new(agents.MockAgentsService)
+		mockJobsService := new(jobs.MockJobsService)
+		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
+		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
+		mockTxManager := new(txmanager.MockTransactionManager)
+		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
+
+		useCase := NewDiscordUseCase(
+			mockDiscordClient,
+			mockWSClient,
+			mockAgentsService,
+			mockJobsService,
+			mockDiscordMessagesService,
+			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
+			mockTxManager,
+			mockAgentsUseCase,
+		)
+
+		// Generate consistent test data for this test case
+		testMessageID := testutils.GenerateDiscordMessageID()
+		testChannelID := testutils.GenerateDiscordChannelID()
+		testGuildID := testutils.GenerateDiscordGuildID()
+		testUserID := testutils.GenerateDiscordUserID()
+		testThreadID := testutils.GenerateDiscordThreadID()
+		testIntegrationID := testutils.GenerateDiscordIntegrationID()
+		testOrgID := testutils.GenerateOrgID()
+		testJobID := testutils.GenerateJobID()
+		testAgentID := testutils.GenerateAgentID()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+
+		event := models.DiscordReactionEvent{
+			MessageID: testMessageID,
+			ChannelID: testChannelID,
+			GuildID:   testGuildID,
+			UserID:    testUserID,
+			EmojiName: EmojiCheckMark,
+			ThreadID:  nil,
+		}
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			DiscordPayload: &models.DiscordJobPayload{
+				MessageID:     testMessageID,
+				ChannelID:     testChannelID,
+				ThreadID:      testThreadID,
+				UserID:        testUserID,
+				IntegrationID: testIntegrationID,
+			},
+		}
+
+		discordIntegration := &models.DiscordIntegration{
+			ID:             testIntegrationID,
+			OrgID:          testOrgID,
+			DiscordGuildID: testGuildID,
+		}
+
+		agent := &models.ActiveAgent{
+			ID:             testAgentID,
+			WSConnectionID: testWSConnectionID,
+			OrgID:          testOrgID,
+		}
+
+		// Configure expectations
+		mockJobsService.On("GetJobByDiscordThread", ctx, testOrgID, testMessageID, testIntegrationID).
+			Return(mo.Some(job), nil)
+		mockDiscordIntegrationsService.On("GetDiscordIntegrationByID", ctx, testIntegrationID).
+			Return(mo.Some(discordIntegration), nil)
+		mockAgentsService.On("GetAgentByJobID", ctx, testOrgID, testJobID).
+			Return(mo.Some(agent), nil)
+
+		// Transaction expectations
+		mockTxManager.On("WithTransaction", ctx, mock.AnythingOfType("func(context.Context) error")).
+			Run(func(args mock.Arguments) {
+				// Execute the transaction function
+				txFunc := args.Get(1).(func(context.Context) error)
+				txFunc(ctx) // Execute with same context for simplicity
+			}).Return(nil)
+		mockAgentsService.On("UnassignAgentFromJob", ctx, testOrgID, testAgentID, testJobID).Return(nil)
+		mockJobsService.On(
+			"CloseJob",
+			ctx,
+			testOrgID,
+			testJobID,
+			models.JobStatusCompleted,
+			models.ManualJobCompletionReason,
+			testAgentID,
+		).Return(nil)
+		mockJobsService.On(
+			"AddJobTranscriptMessage",
+			ctx,
+			testOrgID,
+			testJobID,
+			models.TranscriptAuthorUser,
+			testUserID,
+			models.TranscriptMessageTypeSystemMessage,
+			models.ManualJobCompletionReason,
+			"",
+		).Return(&models.JobTranscriptMessage{}, nil)
+
+		// Discord reaction update
+		mockDiscordClient.On("AddReaction", testChannelID, testMessageID, EmojiCheckMark).Return(nil)
+		mockDiscordClient.On("RemoveReaction", testChannelID, testMessageID, mock.AnythingOfType("string")).
+			Return(nil).
+			Maybe()
+
+		// System message
+		mockDiscordClient.On("PostMessage", testChannelID, mock.MatchedBy(func(params clients.DiscordMessageParams) bool {
+			return params.Content == EmojiGear+" Job manually marked as complete"
+		})).
+			Return(&clients.DiscordPostMessageResponse{}, nil)
+
+		// Execute
+		err := useCase.ProcessDiscordReactionEvent(ctx, event, testIntegrationID, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		mockJobsService.AssertExpectations(t)
+		mockDiscordIntegrationsService.AssertExpectations(t)
+		mockAgentsService.AssertExpectations(t)
+		mockTxManager.AssertExpectations(t)
+		mockDiscordClient.AssertExpectations(t)
+	})
+
+	t.Run("urgent_reaction_by_job_creator_raises_priority", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockDiscordClient := new(discordclient.MockDiscordClient)
+		mockJobsService := new(jobs.MockJobsService)
+
+		useCase := NewDiscordUseCase(
+			mockDiscordClient,
+			new(socketio.MockSocketIOClient),
+			new(agents.MockAgentsService),
+			mockJobsService,
+			new(discordmessages.MockDiscordMessagesService),
+			new(discordintegrations.MockDiscordIntegrationsService),
+			new(connectedchannels.MockConnectedChannelsService),
+			new(txmanager.MockTransactionManager),
+			new(agentsUseCase.MockAgentsUseCase),
+		)
+
+		testMessageID := testutils.GenerateDiscordMessageID()
+		testChannelID := testutils.GenerateDiscordChannelID()
+		testGuildID := testutils.GenerateDiscordGuildID()
+		testUserID := testutils.GenerateDiscordUserID()
+		testThreadID := testutils.GenerateDiscordThreadID()
+		testIntegrationID := testutils.GenerateDiscordIntegrationID()
+		testOrgID := testutils.GenerateOrgID()
+		testJobID := testutils.GenerateJobID()
+
+		event := models.DiscordReactionEvent{
+			MessageID: testMessageID,
+			ChannelID: testChannelID,
+			GuildID:   testGuildID,
+			UserID:    testUserID,
+			EmojiName: EmojiFire,
+			ThreadID:  nil,
+		}
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			DiscordPayload: &models.DiscordJobPayload{
+				MessageID:     testMessageID,
+				ChannelID:     testChannelID,
+				ThreadID:      testThreadID,
+				UserID:        testUserID,
+				IntegrationID: testIntegrationID,
+			},
 		}
 
 		// Configure expectations
-		mockDiscordClient.On("GetBotUser").Return(botUser, nil)
-		mockDiscordClient.On("CreatePublicThread", testChannelID, testMessageID, mock.AnythingOfType("string")).
-			Return(threadResponse, nil)
-		mockJobsService.On("GetOrCreateJobForDiscordThread", ctx, testOrgID, testMessageID, testChannelID, testThreadID, testUserID, testIntegrationID).
-			Return(jobResult, nil)
-		mockDiscordIntegrationsService.On("GetDiscordIntegrationByID", ctx, testIntegrationID).
-			Return(mo.None[*models.DiscordIntegration](), nil) // Integration not found
+		mockJobsService.On("GetJobByDiscordThread", ctx, testOrgID, testMessageID, testIntegrationID).
+			Return(mo.Some(job), nil)
+		mockJobsService.On("RaiseJobPriority", ctx, testOrgID, testJobID, models.JobPriorityUrgent).
+			Return(true, nil)
+		mockDiscordClient.On("PostMessage", testChannelID, mock.MatchedBy(func(params clients.DiscordMessageParams) bool {
+			return params.ThreadID != nil && *params.ThreadID == testThreadID &&
+				strings.Contains(params.Content, "Priority raised to urgent")
+		})).
+			Return(&clients.DiscordPostMessageResponse{}, nil)
 
 		// Execute
-		err := useCase.ProcessDiscordMessageEvent(ctx, event, testIntegrationID, testOrgID)
+		err := useCase.ProcessDiscordReactionEvent(ctx, event, testIntegrationID, testOrgID)
 
 		// Assert
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "discord integration not found")
-		mockDiscordClient.AssertExpectations(t)
+		assert.NoError(t, err)
+		assert.Equal(t, models.JobPriorityUrgent, job.Priority)
 		mockJobsService.AssertExpectations(t)
-		mockDiscordIntegrationsService.AssertExpectations(t)
+		mockDiscordClient.AssertExpectations(t)
 	})
-}
 
-func TestProcessDiscordReactionEvent(t *testing.T) {
-	t.Run("success_valid_completion_reaction", func(t *testing.T) {
+	t.Run("stop_reaction_cancels_queued_messages_and_confirms", func(t *testing.T) {
 		// Setup
 		ctx := context.Background()
 		mockDiscordClient := new(discordclient.MockDiscordClient)
-		mockWSClient := new(socketio.MockSocketIOClient)
 		mockAgentsService := new(agents.MockAgentsService)
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
-		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
-		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
 		useCase := NewDiscordUseCase(
 			mockDiscordClient,
-			mockWSClient,
+			new(socketio.MockSocketIOClient),
 			mockAgentsService,
 			mockJobsService,
 			mockDiscordMessagesService,
-			mockDiscordIntegrationsService,
+			new(discordintegrations.MockDiscordIntegrationsService),
+			new(connectedchannels.MockConnectedChannelsService),
 			mockTxManager,
-			mockAgentsUseCase,
+			new(agentsUseCase.MockAgentsUseCase),
 		)
 
-		// Generate consistent test data for this test case
 		testMessageID := testutils.GenerateDiscordMessageID()
 		testChannelID := testutils.GenerateDiscordChannelID()
 		testGuildID := testutils.GenerateDiscordGuildID()
@@ -567,15 +1195,13 @@ func TestProcessDiscordReactionEvent(t *testing.T) {
 		testIntegrationID := testutils.GenerateDiscordIntegrationID()
 		testOrgID := testutils.GenerateOrgID()
 		testJobID := testutils.GenerateJobID()
-		testAgentID := testutils.GenerateAgentID()
-		testWSConnectionID := testutils.GenerateWSConnectionID()
 
 		event := models.DiscordReactionEvent{
 			MessageID: testMessageID,
 			ChannelID: testChannelID,
 			GuildID:   testGuildID,
 			UserID:    testUserID,
-			EmojiName: EmojiCheckMark,
+			EmojiName: EmojiStopSign,
 			ThreadID:  nil,
 		}
 
@@ -590,59 +1216,54 @@ func TestProcessDiscordReactionEvent(t *testing.T) {
 				IntegrationID: testIntegrationID,
 			},
 		}
-
-		discordIntegration := &models.DiscordIntegration{
-			ID:             testIntegrationID,
-			OrgID:          testOrgID,
-			DiscordGuildID: testGuildID,
-		}
-
-		agent := &models.ActiveAgent{
-			ID:             testAgentID,
-			WSConnectionID: testWSConnectionID,
-			OrgID:          testOrgID,
+		queuedMessage := &models.ProcessedDiscordMessage{
+			ID:                   testutils.GenerateProcessedMessageID(),
+			JobID:                testJobID,
+			DiscordMessageID:     testutils.GenerateDiscordMessageID(),
+			DiscordThreadID:      testThreadID,
+			DiscordIntegrationID: testIntegrationID,
+			OrgID:                testOrgID,
+			Status:               models.ProcessedDiscordMessageStatusQueued,
 		}
+		cancelledMessage := *queuedMessage
+		cancelledMessage.Status = models.ProcessedDiscordMessageStatusCancelled
 
 		// Configure expectations
 		mockJobsService.On("GetJobByDiscordThread", ctx, testOrgID, testMessageID, testIntegrationID).
 			Return(mo.Some(job), nil)
-		mockDiscordIntegrationsService.On("GetDiscordIntegrationByID", ctx, testIntegrationID).
-			Return(mo.Some(discordIntegration), nil)
+		mockDiscordMessagesService.On("GetProcessedMessagesByJobIDAndStatus", ctx, testOrgID, testJobID, models.ProcessedDiscordMessageStatusQueued, testIntegrationID).
+			Return([]*models.ProcessedDiscordMessage{queuedMessage}, nil)
+		mockDiscordMessagesService.On("GetProcessedMessagesByJobIDAndStatus", ctx, testOrgID, testJobID, models.ProcessedDiscordMessageStatusInProgress, testIntegrationID).
+			Return([]*models.ProcessedDiscordMessage{}, nil)
 		mockAgentsService.On("GetAgentByJobID", ctx, testOrgID, testJobID).
-			Return(mo.Some(agent), nil)
-
-		// Transaction expectations
+			Return(mo.None[*models.ActiveAgent](), nil)
 		mockTxManager.On("WithTransaction", ctx, mock.AnythingOfType("func(context.Context) error")).
 			Run(func(args mock.Arguments) {
-				// Execute the transaction function
 				txFunc := args.Get(1).(func(context.Context) error)
-				txFunc(ctx) // Execute with same context for simplicity
+				txFunc(ctx)
 			}).Return(nil)
-		mockAgentsService.On("UnassignAgentFromJob", ctx, testOrgID, testAgentID, testJobID).Return(nil)
-		mockJobsService.On("DeleteJob", ctx, testOrgID, testJobID).Return(nil)
-
-		// Discord reaction update
-		mockDiscordClient.On("AddReaction", testChannelID, testMessageID, EmojiCheckMark).Return(nil)
-		mockDiscordClient.On("RemoveReaction", testChannelID, testMessageID, mock.AnythingOfType("string")).
-			Return(nil).
-			Maybe()
-
-		// System message
+		mockDiscordMessagesService.On("UpdateProcessedDiscordMessage", ctx, testOrgID, queuedMessage.ID, models.ProcessedDiscordMessageStatusCancelled, testIntegrationID).
+			Return(&cancelledMessage, nil)
+		mockAgentsService.On("FailPendingAgentJobMessages", ctx, testOrgID, testJobID, "job was cancelled").
+			Return(nil)
+		mockDiscordClient.On("RemoveReaction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockDiscordClient.On("AddReaction", testThreadID, queuedMessage.DiscordMessageID, EmojiNoEntry).Return(nil)
+		mockDiscordClient.On("AddReaction", testChannelID, testMessageID, EmojiRaisedHand).Return(nil)
 		mockDiscordClient.On("PostMessage", testChannelID, mock.MatchedBy(func(params clients.DiscordMessageParams) bool {
-			return params.Content == EmojiGear+" Job manually marked as complete"
+			return params.ThreadID != nil && *params.ThreadID == testThreadID &&
+				strings.Contains(params.Content, "Job stopped")
 		})).
 			Return(&clients.DiscordPostMessageResponse{}, nil)
 
 		// Execute
 		err := useCase.ProcessDiscordReactionEvent(ctx, event, testIntegrationID, testOrgID)
 
-		// Assert
+		// Assert - no agent works on queued messages, so the cancellation is confirmed right away
 		assert.NoError(t, err)
-		mockJobsService.AssertExpectations(t)
-		mockDiscordIntegrationsService.AssertExpectations(t)
-		mockAgentsService.AssertExpectations(t)
-		mockTxManager.AssertExpectations(t)
+		mockDiscordMessagesService.AssertExpectations(t)
 		mockDiscordClient.AssertExpectations(t)
+		mockAgentsService.AssertNotCalled(t, "SendMessageToAgent")
+		mockJobsService.AssertNotCalled(t, "CloseJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	})
 
 	t.Run("ignore_reaction_by_different_user", func(t *testing.T) {
@@ -654,6 +1275,7 @@ func TestProcessDiscordReactionEvent(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -664,6 +1286,7 @@ func TestProcessDiscordReactionEvent(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -721,6 +1344,7 @@ func TestProcessDiscordReactionEvent(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -731,6 +1355,7 @@ func TestProcessDiscordReactionEvent(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -775,6 +1400,7 @@ func TestProcessProcessingMessage(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -785,6 +1411,7 @@ func TestProcessProcessingMessage(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -855,6 +1482,7 @@ func TestProcessProcessingMessage(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -865,6 +1493,7 @@ func TestProcessProcessingMessage(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -899,6 +1528,7 @@ func TestProcessProcessingMessage(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -909,6 +1539,7 @@ func TestProcessProcessingMessage(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -961,6 +1592,7 @@ func TestProcessAssistantMessage(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -971,6 +1603,7 @@ func TestProcessAssistantMessage(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1042,6 +1675,8 @@ func TestProcessAssistantMessage(t *testing.T) {
 		mockDiscordClient.On("PostMessage", testThreadID, clients.DiscordMessageParams{
 			Content: "Here's my response to your question",
 		}).Return(&clients.DiscordPostMessageResponse{}, nil)
+		mockJobsService.On("AddJobTranscriptMessage", ctx, testOrgID, testJobID, models.TranscriptAuthorAgent, testAgentID, models.TranscriptMessageTypeAssistantMessage, payload.Message, testProcessedID).
+			Return(&models.JobTranscriptMessage{}, nil)
 		mockJobsService.On("UpdateJobTimestamp", ctx, testOrgID, testJobID).Return(nil)
 		mockDiscordMessagesService.On("UpdateProcessedDiscordMessage", ctx, testOrgID, testProcessedID, models.ProcessedDiscordMessageStatusCompleted, testIntegrationID).
 			Return(updatedMessage, nil)
@@ -1081,6 +1716,7 @@ func TestProcessAssistantMessage(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1091,6 +1727,7 @@ func TestProcessAssistantMessage(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1123,6 +1760,7 @@ func TestProcessAssistantMessage(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1133,6 +1771,7 @@ func TestProcessAssistantMessage(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1165,6 +1804,159 @@ func TestProcessAssistantMessage(t *testing.T) {
 	})
 }
 
+func TestProcessAssistantDelta(t *testing.T) {
+	t.Run("first_delta_is_posted_and_next_one_buffered", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockDiscordClient := new(discordclient.MockDiscordClient)
+		mockAgentsService := new(agents.MockAgentsService)
+		mockJobsService := new(jobs.MockJobsService)
+		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
+
+		useCase := NewDiscordUseCase(
+			mockDiscordClient,
+			new(socketio.MockSocketIOClient),
+			mockAgentsService,
+			mockJobsService,
+			new(discordmessages.MockDiscordMessagesService),
+			new(discordintegrations.MockDiscordIntegrationsService),
+			new(connectedchannels.MockConnectedChannelsService),
+			new(txmanager.MockTransactionManager),
+			mockAgentsUseCase,
+		)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+		testAgentID := testutils.GenerateAgentID()
+		testOrgID := testutils.GenerateOrgID()
+		testThreadID := testutils.GenerateDiscordThreadID()
+		testClientID := testutils.GenerateClientID()
+
+		agent := &models.ActiveAgent{
+			ID:    testAgentID,
+			OrgID: testOrgID,
+		}
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			DiscordPayload: &models.DiscordJobPayload{
+				ChannelID:     testutils.GenerateDiscordChannelID(),
+				ThreadID:      testThreadID,
+				IntegrationID: testutils.GenerateDiscordIntegrationID(),
+			},
+		}
+
+		// Configure expectations
+		mockAgentsService.On("GetAgentByWSConnectionID", ctx, testOrgID, testClientID).
+			Return(mo.Some(agent), nil)
+		mockJobsService.On("GetJobByID", ctx, testOrgID, testJobID).
+			Return(mo.Some(job), nil)
+		mockAgentsUseCase.On("ValidateJobBelongsToAgent", ctx, testAgentID, testJobID, testOrgID).
+			Return(nil)
+		mockDiscordClient.On("PostMessage", testThreadID, clients.DiscordMessageParams{Content: "Looking into "}).
+			Return(&clients.DiscordPostMessageResponse{MessageID: testutils.GenerateDiscordMessageID()}, nil).
+			Once()
+		mockJobsService.On("UpdateJobTimestamp", ctx, testOrgID, testJobID).Return(nil).Once()
+
+		// Execute
+		for _, delta := range []string{"Looking into ", "the failing test"} {
+			err := useCase.ProcessAssistantDelta(ctx, testClientID, models.AssistantDeltaPayload{
+				JobID:              testJobID,
+				Delta:              delta,
+				ProcessedMessageID: testProcessedID,
+			}, testOrgID)
+			assert.NoError(t, err)
+		}
+
+		// Assert
+		mockDiscordClient.AssertExpectations(t)
+		mockJobsService.AssertExpectations(t)
+		mockDiscordClient.AssertNotCalled(t, "UpdateMessage", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestProcessArtifact(t *testing.T) {
+	t.Run("uploads_artifact_into_job_thread", func(t *testing.T) {
+		// Setup
+		ctx := context.Background()
+		mockDiscordClient := new(discordclient.MockDiscordClient)
+		mockAgentsService := new(agents.MockAgentsService)
+		mockJobsService := new(jobs.MockJobsService)
+		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
+
+		useCase := NewDiscordUseCase(
+			mockDiscordClient,
+			new(socketio.MockSocketIOClient),
+			mockAgentsService,
+			mockJobsService,
+			new(discordmessages.MockDiscordMessagesService),
+			new(discordintegrations.MockDiscordIntegrationsService),
+			new(connectedchannels.MockConnectedChannelsService),
+			new(txmanager.MockTransactionManager),
+			mockAgentsUseCase,
+		)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testAgentID := testutils.GenerateAgentID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateDiscordChannelID()
+		testThreadID := testutils.GenerateDiscordThreadID()
+		testClientID := testutils.GenerateClientID()
+
+		agent := &models.ActiveAgent{
+			ID:    testAgentID,
+			OrgID: testOrgID,
+		}
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			DiscordPayload: &models.DiscordJobPayload{
+				ChannelID:     testChannelID,
+				ThreadID:      testThreadID,
+				IntegrationID: testutils.GenerateDiscordIntegrationID(),
+			},
+		}
+		payload := models.ArtifactPayload{
+			JobID: testJobID,
+			Attachment: models.AgentAttachment{
+				Name:     "report.html",
+				MimeType: "text/html",
+				Content:  []byte("<h1>All tests passed</h1>"),
+			},
+			Comment: "Test report",
+		}
+
+		// Configure expectations
+		mockAgentsService.On("GetAgentByWSConnectionID", ctx, testOrgID, testClientID).
+			Return(mo.Some(agent), nil)
+		mockJobsService.On("GetJobByID", ctx, testOrgID, testJobID).
+			Return(mo.Some(job), nil)
+		mockAgentsUseCase.On("ValidateJobBelongsToAgent", ctx, testAgentID, testJobID, testOrgID).
+			Return(nil)
+		mockDiscordClient.On("UploadFile", testChannelID, clients.DiscordUploadFileParams{
+			Filename:    "report.html",
+			ContentType: "text/html",
+			Content:     payload.Attachment.Content,
+			Comment:     "Test report",
+			ThreadID:    &testThreadID,
+		}).Return(nil).Once()
+		mockJobsService.On("AddJobTranscriptMessage", ctx, testOrgID, testJobID, models.TranscriptAuthorAgent, testAgentID, models.TranscriptMessageTypeArtifact, "report.html: Test report", "").
+			Return(&models.JobTranscriptMessage{}, nil)
+		mockJobsService.On("UpdateJobTimestamp", ctx, testOrgID, testJobID).Return(nil).Once()
+
+		// Execute
+		err := useCase.ProcessArtifact(ctx, testClientID, payload, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		mockDiscordClient.AssertExpectations(t)
+		mockJobsService.AssertExpectations(t)
+		mockAgentsUseCase.AssertExpectations(t)
+	})
+}
+
 func TestProcessSystemMessage(t *testing.T) {
 	t.Run("success_regular_system_message", func(t *testing.T) {
 		// Setup
@@ -1175,6 +1967,7 @@ func TestProcessSystemMessage(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1185,6 +1978,7 @@ func TestProcessSystemMessage(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1212,9 +2006,17 @@ func TestProcessSystemMessage(t *testing.T) {
 			DiscordGuildID: "guild-789",
 		}
 
+		agent := &models.ActiveAgent{
+			ID:             "agent-111",
+			WSConnectionID: "client-123",
+			OrgID:          models.OrgID("org-456"),
+		}
+
 		// Configure expectations
 		mockJobsService.On("GetJobByID", ctx, models.OrgID("org-456"), "job-111").
 			Return(mo.Some(job), nil)
+		mockAgentsService.On("GetAgentByWSConnectionID", ctx, models.OrgID("org-456"), "client-123").
+			Return(mo.Some(agent), nil)
 		mockDiscordIntegrationsService.On("GetDiscordIntegrationByID", ctx, "discord-int-123").
 			Return(mo.Some(discordIntegration), nil)
 		mockDiscordClient.On("PostMessage", "channel-456", mock.MatchedBy(func(params clients.DiscordMessageParams) bool {
@@ -1222,6 +2024,17 @@ func TestProcessSystemMessage(t *testing.T) {
 				params.ThreadID != nil && *params.ThreadID == "thread-123"
 		})).
 			Return(&clients.DiscordPostMessageResponse{}, nil)
+		mockJobsService.On(
+			"AddJobTranscriptMessage",
+			ctx,
+			models.OrgID("org-456"),
+			"job-111",
+			models.TranscriptAuthorAgent,
+			"agent-111",
+			models.TranscriptMessageTypeSystemMessage,
+			payload.Message,
+			"",
+		).Return(&models.JobTranscriptMessage{}, nil)
 		mockJobsService.On("UpdateJobTimestamp", ctx, models.OrgID("org-456"), "job-111").Return(nil)
 
 		// Execute
@@ -1243,6 +2056,7 @@ func TestProcessSystemMessage(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1253,6 +2067,7 @@ func TestProcessSystemMessage(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1306,7 +2121,26 @@ func TestProcessSystemMessage(t *testing.T) {
 				txFunc(ctx)
 			}).Return(nil)
 		mockAgentsService.On("UnassignAgentFromJob", ctx, models.OrgID("org-456"), "agent-111", "job-111").Return(nil)
-		mockJobsService.On("DeleteJob", ctx, models.OrgID("org-456"), "job-111").Return(nil)
+		mockJobsService.On(
+			"CloseJob",
+			ctx,
+			models.OrgID("org-456"),
+			"job-111",
+			models.JobStatusFailed,
+			EmojiCrossMark+" Agent encountered an error and cannot continue:\n"+payload.Message,
+			"agent-111",
+		).Return(nil)
+		mockJobsService.On(
+			"AddJobTranscriptMessage",
+			ctx,
+			models.OrgID("org-456"),
+			"job-111",
+			models.TranscriptAuthorSystem,
+			"",
+			models.TranscriptMessageTypeSystemMessage,
+			EmojiCrossMark+" Agent encountered an error and cannot continue:\n"+payload.Message,
+			"",
+		).Return(&models.JobTranscriptMessage{}, nil)
 
 		// Execute
 		err := useCase.ProcessSystemMessage(ctx, "client-123", payload, models.OrgID("org-456"))
@@ -1328,6 +2162,7 @@ func TestProcessSystemMessage(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1338,6 +2173,7 @@ func TestProcessSystemMessage(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1370,6 +2206,7 @@ func TestProcessJobComplete(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1380,6 +2217,7 @@ func TestProcessJobComplete(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1430,7 +2268,26 @@ func TestProcessJobComplete(t *testing.T) {
 				txFunc(ctx)
 			}).Return(nil)
 		mockAgentsService.On("UnassignAgentFromJob", ctx, models.OrgID("org-456"), "agent-111", "job-111").Return(nil)
-		mockJobsService.On("DeleteJob", ctx, models.OrgID("org-456"), "job-111").Return(nil)
+		mockJobsService.On(
+			"CloseJob",
+			ctx,
+			models.OrgID("org-456"),
+			"job-111",
+			models.JobStatusCompleted,
+			payload.Reason,
+			"agent-111",
+		).Return(nil)
+		mockJobsService.On(
+			"AddJobTranscriptMessage",
+			ctx,
+			models.OrgID("org-456"),
+			"job-111",
+			models.TranscriptAuthorAgent,
+			"agent-111",
+			models.TranscriptMessageTypeSystemMessage,
+			payload.Reason,
+			"",
+		).Return(&models.JobTranscriptMessage{}, nil)
 		mockDiscordIntegrationsService.On("GetDiscordIntegrationByID", ctx, "discord-int-123").
 			Return(mo.Some(discordIntegration), nil)
 		mockDiscordClient.On("PostMessage", "channel-456", mock.MatchedBy(func(params clients.DiscordMessageParams) bool {
@@ -1460,6 +2317,7 @@ func TestProcessJobComplete(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1470,6 +2328,7 @@ func TestProcessJobComplete(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1502,6 +2361,7 @@ func TestProcessQueuedJobs(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1512,6 +2372,7 @@ func TestProcessQueuedJobs(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1562,7 +2423,11 @@ func TestProcessQueuedJobs(t *testing.T) {
 			Return([]*models.ProcessedDiscordMessage{queuedMessage}, nil)
 		mockJobsService.On("GetJobByID", ctx, models.OrgID("org-456"), "job-111").
 			Return(mo.Some(job), nil)
-		mockAgentsUseCase.On("TryAssignJobToAgent", ctx, "job-111", models.OrgID("org-456")).
+		mockConnectedChannelsService.On("GetDiscordConnectedChannel", ctx, models.OrgID("org-456"), "", "channel-456").
+			Return(mo.None[*models.DiscordConnectedChannel](), nil)
+		mockAgentsService.On("GetAgentByJobID", ctx, models.OrgID("org-456"), "job-111").
+			Return(mo.None[*models.ActiveAgent](), nil)
+		mockAgentsUseCase.On("TryAssignJobToAgent", ctx, "job-111", "", models.AgentLabels(nil), models.OrgID("org-456")).
 			Return("client-123", true, nil)
 		mockDiscordMessagesService.On("GetProcessedMessagesByJobIDAndStatus", ctx, models.OrgID("org-456"), "job-111", models.ProcessedDiscordMessageStatusQueued, "discord-int-123").
 			Return([]*models.ProcessedDiscordMessage{queuedMessage}, nil)
@@ -1578,7 +2443,15 @@ func TestProcessQueuedJobs(t *testing.T) {
 			Return(mo.Some(job), nil).Maybe()
 		mockDiscordIntegrationsService.On("GetDiscordIntegrationByID", ctx, "discord-int-123").
 			Return(mo.Some(integration), nil).Maybe()
-		mockWSClient.On("SendMessage", "client-123", mock.AnythingOfType("models.BaseMessage")).Return(nil)
+		mockAgentsService.On(
+			"SendMessageToAgent",
+			ctx,
+			models.OrgID("org-456"),
+			"client-123",
+			"job-111",
+			"processed-123",
+			mock.AnythingOfType("models.BaseMessage"),
+		).Return(nil)
 
 		// Execute
 		err := useCase.ProcessQueuedJobs(ctx)
@@ -1602,6 +2475,7 @@ func TestProcessQueuedJobs(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1612,6 +2486,7 @@ func TestProcessQueuedJobs(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1637,6 +2512,7 @@ func TestProcessQueuedJobs(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1647,6 +2523,7 @@ func TestProcessQueuedJobs(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1680,6 +2557,7 @@ func TestProcessQueuedJobs(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1690,6 +2568,7 @@ func TestProcessQueuedJobs(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1729,7 +2608,11 @@ func TestProcessQueuedJobs(t *testing.T) {
 			Return([]*models.ProcessedDiscordMessage{queuedMessage}, nil)
 		mockJobsService.On("GetJobByID", ctx, models.OrgID("org-456"), "job-111").
 			Return(mo.Some(job), nil)
-		mockAgentsUseCase.On("TryAssignJobToAgent", ctx, "job-111", models.OrgID("org-456")).
+		mockConnectedChannelsService.On("GetDiscordConnectedChannel", ctx, models.OrgID("org-456"), "", "channel-456").
+			Return(mo.None[*models.DiscordConnectedChannel](), nil)
+		mockAgentsService.On("GetAgentByJobID", ctx, models.OrgID("org-456"), "job-111").
+			Return(mo.None[*models.ActiveAgent](), nil)
+		mockAgentsUseCase.On("TryAssignJobToAgent", ctx, "job-111", "", models.AgentLabels(nil), models.OrgID("org-456")).
 			Return("", false, nil) // No agent assigned
 
 		// Execute
@@ -1753,6 +2636,7 @@ func TestCleanupFailedDiscordJob(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1763,6 +2647,7 @@ func TestCleanupFailedDiscordJob(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1802,7 +2687,26 @@ func TestCleanupFailedDiscordJob(t *testing.T) {
 				txFunc(ctx)
 			}).Return(nil)
 		mockAgentsService.On("UnassignAgentFromJob", ctx, models.OrgID("org-456"), "agent-111", "job-111").Return(nil)
-		mockJobsService.On("DeleteJob", ctx, models.OrgID("org-456"), "job-111").Return(nil)
+		mockJobsService.On(
+			"CloseJob",
+			ctx,
+			models.OrgID("org-456"),
+			"job-111",
+			models.JobStatusFailed,
+			"Agent failed to process",
+			"agent-111",
+		).Return(nil)
+		mockJobsService.On(
+			"AddJobTranscriptMessage",
+			ctx,
+			models.OrgID("org-456"),
+			"job-111",
+			models.TranscriptAuthorSystem,
+			"",
+			models.TranscriptMessageTypeSystemMessage,
+			"Agent failed to process",
+			"",
+		).Return(&models.JobTranscriptMessage{}, nil)
 
 		// Execute
 		err := useCase.CleanupFailedDiscordJob(ctx, job, "agent-111", "Agent failed to process")
@@ -1825,6 +2729,7 @@ func TestCleanupFailedDiscordJob(t *testing.T) {
 		mockJobsService := new(jobs.MockJobsService)
 		mockDiscordMessagesService := new(discordmessages.MockDiscordMessagesService)
 		mockDiscordIntegrationsService := new(discordintegrations.MockDiscordIntegrationsService)
+		mockConnectedChannelsService := new(connectedchannels.MockConnectedChannelsService)
 		mockTxManager := new(txmanager.MockTransactionManager)
 		mockAgentsUseCase := new(agentsUseCase.MockAgentsUseCase)
 
@@ -1835,6 +2740,7 @@ func TestCleanupFailedDiscordJob(t *testing.T) {
 			mockJobsService,
 			mockDiscordMessagesService,
 			mockDiscordIntegrationsService,
+			mockConnectedChannelsService,
 			mockTxManager,
 			mockAgentsUseCase,
 		)
@@ -1853,3 +2759,93 @@ func TestCleanupFailedDiscordJob(t *testing.T) {
 		assert.Contains(t, err.Error(), "job has no Discord payload")
 	})
 }
+
+func TestRequeueDiscordJob(t *testing.T) {
+	t.Run("success_requeue_in_flight_messages", func(t *testing.T) {
+		// Setup
+		fixture := setupDiscordUseCaseTest(t)
+		ctx := fixture.ctx
+
+		job := &models.Job{
+			ID:    "job-111",
+			OrgID: models.OrgID("org-456"),
+			DiscordPayload: &models.DiscordJobPayload{
+				MessageID:     "msg-123",
+				ChannelID:     "channel-456",
+				ThreadID:      "thread-123",
+				UserID:        "user-abc",
+				IntegrationID: "discord-int-123",
+			},
+		}
+
+		inProgressMessage := &models.ProcessedDiscordMessage{
+			ID:                   "processed-2",
+			JobID:                "job-111",
+			DiscordMessageID:     "msg-456",
+			DiscordThreadID:      "thread-123",
+			DiscordIntegrationID: "discord-int-123",
+			OrgID:                models.OrgID("org-456"),
+			Status:               models.ProcessedDiscordMessageStatusInProgress,
+		}
+
+		discordIntegration := &models.DiscordIntegration{
+			ID:             "discord-int-123",
+			OrgID:          models.OrgID("org-456"),
+			DiscordGuildID: "guild-789",
+		}
+
+		// Configure expectations
+		fixture.mocks.txManager.On("WithTransaction", ctx, mock.AnythingOfType("func(context.Context) error")).
+			Run(func(args mock.Arguments) {
+				txFunc := args.Get(1).(func(context.Context) error)
+				txFunc(ctx)
+			}).Return(nil)
+		fixture.mocks.agentsService.On("UnassignAgentFromJob", ctx, models.OrgID("org-456"), "agent-111", "job-111").Return(nil)
+		fixture.mocks.discordMessagesService.On("GetProcessedMessagesByJobIDAndStatus", ctx, models.OrgID("org-456"), "job-111", models.ProcessedDiscordMessageStatusInProgress, "discord-int-123").
+			Return([]*models.ProcessedDiscordMessage{inProgressMessage}, nil)
+		fixture.mocks.discordMessagesService.On("UpdateProcessedDiscordMessage", ctx, models.OrgID("org-456"), "processed-2", models.ProcessedDiscordMessageStatusQueued, "discord-int-123").
+			Return(inProgressMessage, nil)
+		fixture.mocks.discordClient.On("AddReaction", "channel-456", "msg-123", EmojiHourglass).Return(nil)
+		fixture.mocks.discordClient.On("RemoveReaction", "channel-456", "msg-123", mock.AnythingOfType("string")).
+			Return(nil).
+			Maybe()
+		fixture.mocks.discordIntegrationsService.On("GetDiscordIntegrationByID", ctx, "discord-int-123").
+			Return(mo.Some(discordIntegration), nil)
+		fixture.mocks.discordClient.On("PostMessage", "channel-456", mock.MatchedBy(func(params clients.DiscordMessageParams) bool {
+			return params.ThreadID != nil && *params.ThreadID == "thread-123"
+		})).
+			Return(&clients.DiscordPostMessageResponse{}, nil)
+
+		// Execute
+		err := fixture.useCase.RequeueDiscordJob(ctx, job, "agent-111", "Agent disconnected")
+
+		// Assert
+		assert.NoError(t, err)
+		fixture.assertAllExpectations(t)
+		fixture.mocks.jobsService.AssertNotCalled(t, "CloseJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		fixture.mocks.discordMessagesService.AssertNotCalled(t, "GetProcessedMessagesByJobIDAndStatus", ctx, models.OrgID("org-456"), "job-111", models.ProcessedDiscordMessageStatusCompleted, "discord-int-123")
+	})
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	t.Run("downloads_file_from_discord", func(t *testing.T) {
+		// Setup
+		fixture := setupDiscordUseCaseTest(t)
+		source := models.AttachmentSource{
+			Platform:      models.JobTypeDiscord,
+			IntegrationID: testutils.GenerateDiscordIntegrationID(),
+			URL:           "https://cdn.discordapp.com/attachments/1/2/error.log",
+		}
+
+		// Configure expectations
+		fixture.mocks.discordClient.On("DownloadAttachment", source.URL).Return([]byte("log lines"), nil)
+
+		// Execute
+		content, err := fixture.useCase.DownloadAttachment(fixture.ctx, source)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("log lines"), content)
+		fixture.mocks.discordClient.AssertExpectations(t)
+	})
+}
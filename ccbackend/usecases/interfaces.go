@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"context"
+	"time"
 
 	"ccbackend/models"
 )
@@ -38,18 +39,48 @@ type SlackUseCaseInterface interface {
 		agentID string,
 		message string,
 	) error
+	CloseIdleSlackJob(ctx context.Context, job *models.Job, notice string) error
+	HasAvailableAgentForSlackJob(ctx context.Context, job *models.Job, activeSince time.Time) (bool, error)
+	FailStuckSlackJob(ctx context.Context, job *models.Job, agentID string, explanation string) error
+	RequeueSlackJob(
+		ctx context.Context,
+		job *models.Job,
+		agentID string,
+		notice string,
+	) error
+	ProcessJobCancelled(ctx context.Context, job *models.Job) error
+	ProcessJobCancellationUnconfirmed(ctx context.Context, job *models.Job) error
 	ProcessAssistantMessage(
 		ctx context.Context,
 		clientID string,
 		payload models.AssistantMessagePayload,
 		orgID models.OrgID,
 	) error
+	ProcessAssistantDelta(
+		ctx context.Context,
+		clientID string,
+		payload models.AssistantDeltaPayload,
+		orgID models.OrgID,
+	) error
+	ProcessProgressEvent(
+		ctx context.Context,
+		clientID string,
+		payload models.ProgressEventPayload,
+		orgID models.OrgID,
+	) error
+	ProcessArtifact(
+		ctx context.Context,
+		clientID string,
+		payload models.ArtifactPayload,
+		orgID models.OrgID,
+	) error
 	ProcessSystemMessage(
 		ctx context.Context,
 		clientID string,
 		payload models.SystemMessagePayload,
 		orgID models.OrgID,
 	) error
+	DownloadAttachment(ctx context.Context, source models.AttachmentSource) ([]byte, error)
 }
 
 // DiscordUseCaseInterface defines the interface for Discord use case operations
@@ -90,11 +121,41 @@ type DiscordUseCaseInterface interface {
 		payload models.AssistantMessagePayload,
 		orgID models.OrgID,
 	) error
+	ProcessAssistantDelta(
+		ctx context.Context,
+		clientID string,
+		payload models.AssistantDeltaPayload,
+		orgID models.OrgID,
+	) error
+	ProcessProgressEvent(
+		ctx context.Context,
+		clientID string,
+		payload models.ProgressEventPayload,
+		orgID models.OrgID,
+	) error
+	ProcessArtifact(
+		ctx context.Context,
+		clientID string,
+		payload models.ArtifactPayload,
+		orgID models.OrgID,
+	) error
 	CleanupFailedDiscordJob(
 		ctx context.Context,
 		job *models.Job,
 		agentID string,
 		message string,
 	) error
+	CloseIdleDiscordJob(ctx context.Context, job *models.Job, notice string) error
+	HasAvailableAgentForDiscordJob(ctx context.Context, job *models.Job, activeSince time.Time) (bool, error)
+	FailStuckDiscordJob(ctx context.Context, job *models.Job, agentID string, explanation string) error
+	RequeueDiscordJob(
+		ctx context.Context,
+		job *models.Job,
+		agentID string,
+		notice string,
+	) error
+	ProcessJobCancelled(ctx context.Context, job *models.Job) error
+	ProcessJobCancellationUnconfirmed(ctx context.Context, job *models.Job) error
 	ProcessQueuedJobs(ctx context.Context) error
+	DownloadAttachment(ctx context.Context, source models.AttachmentSource) ([]byte, error)
 }
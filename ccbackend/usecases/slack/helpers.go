@@ -2,16 +2,19 @@ package slack
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/samber/mo"
 
 	"ccbackend/clients"
 	"ccbackend/core"
 	"ccbackend/models"
+	"ccbackend/usecases/streaming"
 	"ccbackend/utils"
 )
 
@@ -31,10 +34,57 @@ func (s *SlackUseCase) getSlackClientForIntegration(
 	return s.slackClientFactory(integration.SlackAuthToken), nil
 }
 
+// getChannelRouting returns the default repository and agent selector configured for the job's channel
+// Returns an empty repository and a nil selector when the channel has none configured
+func (s *SlackUseCase) getChannelRouting(
+	ctx context.Context,
+	orgID models.OrgID,
+	teamID string,
+	job *models.Job,
+) (string, models.AgentLabels, error) {
+	if job.SlackPayload == nil {
+		return "", nil, fmt.Errorf("job has no Slack payload")
+	}
+
+	maybeChannel, err := s.connectedChannelsService.GetSlackConnectedChannel(ctx, orgID, teamID, job.SlackPayload.ChannelID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get slack connected channel: %w", err)
+	}
+	if !maybeChannel.IsPresent() {
+		return "", nil, nil
+	}
+
+	channel := maybeChannel.MustGet()
+	repoURL := ""
+	if channel.DefaultRepoURL != nil {
+		repoURL = *channel.DefaultRepoURL
+	}
+	return repoURL, channel.AgentSelector, nil
+}
+
+// isOnlyQueuedMessage returns true if the message is the only queued message of its job, i.e. the job has just
+// started waiting for an agent - messages sent while it waits join the queue without another notice
+func (s *SlackUseCase) isOnlyQueuedMessage(ctx context.Context, message *models.ProcessedSlackMessage) (bool, error) {
+	queuedMessages, err := s.slackMessagesService.GetProcessedMessagesByJobIDAndStatus(
+		ctx,
+		message.OrgID,
+		message.JobID,
+		models.ProcessedSlackMessageStatusQueued,
+		message.SlackIntegrationID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to get queued messages of job %s: %w", message.JobID, err)
+	}
+	return len(queuedMessages) == 1 && queuedMessages[0].ID == message.ID, nil
+}
+
+// sendStartConversationToAgent starts the job's conversation on the agent with the message as its prompt
+// A job handed over from another agent carries its earlier conversation as history.
 func (s *SlackUseCase) sendStartConversationToAgent(
 	ctx context.Context,
 	clientID string,
 	message *models.ProcessedSlackMessage,
+	history []models.ConversationTurn,
 ) error {
 	// Get integration-specific Slack client
 	slackClient, err := s.getSlackClientForIntegration(ctx, message.SlackIntegrationID)
@@ -74,10 +124,21 @@ func (s *SlackUseCase) sendStartConversationToAgent(
 			Message:            resolvedText,
 			ProcessedMessageID: message.ID,
 			MessageLink:        permalink,
+			Attachments:        message.Attachments.ForAgent(models.JobTypeSlack, message.SlackIntegrationID),
+			History:            history,
 		},
 	}
 
-	if err := s.wsClient.SendMessage(clientID, startConversationMessage); err != nil {
+	// Sent through the agent outbox which resends it until the agent acknowledges it
+	err = s.agentsService.SendMessageToAgent(
+		ctx,
+		message.OrgID,
+		clientID,
+		message.JobID,
+		message.ID,
+		startConversationMessage,
+	)
+	if err != nil {
 		return fmt.Errorf("failed to send start conversation message to client %s: %v", clientID, err)
 	}
 	log.Printf("🚀 Sent start conversation message to client %s", clientID)
@@ -127,16 +188,58 @@ func (s *SlackUseCase) sendUserMessageToAgent(
 			Message:            resolvedText,
 			ProcessedMessageID: message.ID,
 			MessageLink:        permalink,
+			Attachments:        message.Attachments.ForAgent(models.JobTypeSlack, message.SlackIntegrationID),
 		},
 	}
 
-	if err := s.wsClient.SendMessage(clientID, userMessage); err != nil {
+	err = s.agentsService.SendMessageToAgent(
+		ctx,
+		message.OrgID,
+		clientID,
+		message.JobID,
+		message.ID,
+		userMessage,
+	)
+	if err != nil {
 		return fmt.Errorf("failed to send user message to client %s: %v", clientID, err)
 	}
 	log.Printf("💬 Sent user message to client %s", clientID)
 	return nil
 }
 
+// isJobHandedOver returns true if the job has no agent, e.g. after it was requeued because its agent disconnected,
+// so the agent it is assigned to next has not seen its earlier conversation
+func (s *SlackUseCase) isJobHandedOver(ctx context.Context, orgID models.OrgID, job *models.Job) (bool, error) {
+	maybeAgent, err := s.agentsService.GetAgentByJobID(ctx, orgID, job.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get agent of job %s: %w", job.ID, err)
+	}
+	return maybeAgent.IsAbsent(), nil
+}
+
+// getConversationHistory returns the earlier conversation of a job for the agent taking it over
+// The pending messages are left out as they are sent to the agent as prompts.
+func (s *SlackUseCase) getConversationHistory(
+	ctx context.Context,
+	job *models.Job,
+	pendingMessages []*models.ProcessedSlackMessage,
+) ([]models.ConversationTurn, error) {
+	maybeTranscript, err := s.jobsService.GetJobTranscript(ctx, job.OrgID, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transcript of job %s: %w", job.ID, err)
+	}
+	transcript, ok := maybeTranscript.Get()
+	if !ok {
+		return nil, nil
+	}
+
+	pendingIDs := make([]string, 0, len(pendingMessages))
+	for _, message := range pendingMessages {
+		pendingIDs = append(pendingIDs, message.ID)
+	}
+	return models.ConversationHistory(transcript, pendingIDs), nil
+}
+
 func (s *SlackUseCase) updateSlackMessageReaction(
 	ctx context.Context,
 	channelID, messageTS, newEmoji, slackIntegrationID string,
@@ -183,12 +286,21 @@ func (s *SlackUseCase) sendSlackMessage(
 	ctx context.Context,
 	slackIntegrationID, channelID, threadTS, message string,
 ) error {
+	_, err := s.postSlackMessage(ctx, slackIntegrationID, channelID, threadTS, message)
+	return err
+}
+
+// postSlackMessage sends a message to Slack and returns its timestamp
+func (s *SlackUseCase) postSlackMessage(
+	ctx context.Context,
+	slackIntegrationID, channelID, threadTS, message string,
+) (string, error) {
 	log.Printf("📋 Starting to send message to channel %s, thread %s: %s", channelID, threadTS, message)
 
 	// Get integration-specific Slack client
 	slackClient, err := s.getSlackClientForIntegration(ctx, slackIntegrationID)
 	if err != nil {
-		return fmt.Errorf("failed to get Slack client for integration: %w", err)
+		return "", fmt.Errorf("failed to get Slack client for integration: %w", err)
 	}
 
 	// Send message to Slack
@@ -198,12 +310,29 @@ func (s *SlackUseCase) sendSlackMessage(
 	if threadTS != "" {
 		params.ThreadTS = mo.Some(threadTS)
 	}
-	_, err = slackClient.PostMessage(channelID, params)
+	response, err := slackClient.PostMessage(channelID, params)
 	if err != nil {
-		return fmt.Errorf("failed to send message to Slack: %w", err)
+		return "", fmt.Errorf("failed to send message to Slack: %w", err)
 	}
 
 	log.Printf("📋 Completed successfully - sent message to channel %s, thread %s", channelID, threadTS)
+	return response.Timestamp, nil
+}
+
+// updateSlackMessage replaces the text of a message the bot posted
+func (s *SlackUseCase) updateSlackMessage(
+	ctx context.Context,
+	slackIntegrationID, channelID, messageTS, message string,
+) error {
+	slackClient, err := s.getSlackClientForIntegration(ctx, slackIntegrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get Slack client for integration: %w", err)
+	}
+
+	if err := slackClient.UpdateMessage(channelID, messageTS, utils.ConvertMarkdownToSlack(message)); err != nil {
+		return fmt.Errorf("failed to update Slack message: %w", err)
+	}
+
 	return nil
 }
 
@@ -220,6 +349,111 @@ func (s *SlackUseCase) sendSystemMessage(
 	return s.sendSlackMessage(ctx, slackIntegrationID, channelID, threadTS, systemMessage)
 }
 
+// getAgentJob returns the job an agent's message is about together with the agent,
+// or None if the job no longer exists
+// Returns an error if the agent is not assigned to the job.
+func (s *SlackUseCase) getAgentJob(
+	ctx context.Context,
+	clientID, jobID string,
+	orgID models.OrgID,
+) (mo.Option[*models.Job], *models.ActiveAgent, error) {
+	maybeAgent, err := s.agentsService.GetAgentByWSConnectionID(ctx, orgID, clientID)
+	if err != nil {
+		return mo.None[*models.Job](), nil, fmt.Errorf("failed to find agent for client: %w", err)
+	}
+	agent, ok := maybeAgent.Get()
+	if !ok {
+		return mo.None[*models.Job](), nil, fmt.Errorf("no agent found for client: %s", clientID)
+	}
+
+	maybeJob, err := s.jobsService.GetJobByID(ctx, orgID, jobID)
+	if err != nil {
+		return mo.None[*models.Job](), nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	job, ok := maybeJob.Get()
+	if !ok {
+		return mo.None[*models.Job](), nil, nil
+	}
+	if job.SlackPayload == nil {
+		return mo.None[*models.Job](), nil, fmt.Errorf("job has no Slack payload")
+	}
+
+	// Validate that this agent is actually assigned to this job
+	if err := s.agentsUseCase.ValidateJobBelongsToAgent(ctx, agent.ID, job.ID, orgID); err != nil {
+		return mo.None[*models.Job](), nil, err
+	}
+
+	return mo.Some(job), agent, nil
+}
+
+// recordTranscriptMessage adds a message exchanged in the job's thread to the job's transcript
+// Failures are only logged - the transcript is history and must not hold up the conversation.
+func (s *SlackUseCase) recordTranscriptMessage(
+	ctx context.Context,
+	job *models.Job,
+	author models.TranscriptAuthor,
+	authorID string,
+	messageType models.TranscriptMessageType,
+	content string,
+	processedMessageID string,
+) {
+	_, err := s.jobsService.AddJobTranscriptMessage(
+		ctx,
+		job.OrgID,
+		job.ID,
+		author,
+		authorID,
+		messageType,
+		content,
+		processedMessageID,
+	)
+	if err != nil {
+		log.Printf("⚠️ Failed to record %s in transcript of job %s: %v", messageType, job.ID, err)
+	}
+}
+
+// renderStreamUpdate posts or edits the stream's message in the job's Slack thread
+func (s *SlackUseCase) renderStreamUpdate(
+	ctx context.Context,
+	job *models.Job,
+	streams *streaming.Tracker,
+	key string,
+	update streaming.Update,
+) error {
+	slackIntegrationID := job.SlackPayload.IntegrationID
+	switch update.Action {
+	case streaming.UpdateActionPost:
+		messageTS, err := s.postSlackMessage(
+			ctx,
+			slackIntegrationID,
+			job.SlackPayload.ChannelID,
+			job.SlackPayload.ThreadTS,
+			update.Text,
+		)
+		streams.Posted(key, messageTS)
+		return err
+	case streaming.UpdateActionEdit:
+		return s.updateSlackMessage(ctx, slackIntegrationID, job.SlackPayload.ChannelID, update.MessageID, update.Text)
+	default:
+		return nil
+	}
+}
+
+// finishProgressStatus renders the latest steps of the job's status message before the agent's reply
+// is posted, so the next steps start a new status message below the reply
+func (s *SlackUseCase) finishProgressStatus(ctx context.Context, job *models.Job) {
+	status, ok := s.progressStatuses.Finish(ctx, job.ID)
+	if !ok || status.MessageID == "" || !status.Outdated() {
+		return
+	}
+
+	slackIntegrationID := job.SlackPayload.IntegrationID
+	err := s.updateSlackMessage(ctx, slackIntegrationID, job.SlackPayload.ChannelID, status.MessageID, status.Text)
+	if err != nil {
+		log.Printf("⚠️ Failed to update progress status of job %s: %v", job.ID, err)
+	}
+}
+
 func (s *SlackUseCase) getBotUserID(ctx context.Context, slackIntegrationID string) (string, error) {
 	slackClient, err := s.getSlackClientForIntegration(ctx, slackIntegrationID)
 	if err != nil {
@@ -268,6 +502,196 @@ func (s *SlackUseCase) getBotReactionsOnMessage(
 	return botReactions, nil
 }
 
+// raiseJobPriority raises the job's priority and confirms it in the job's thread
+// Nothing is posted if the job already had the same or a higher priority
+func (s *SlackUseCase) raiseJobPriority(
+	ctx context.Context,
+	orgID models.OrgID,
+	job *models.Job,
+	priority models.JobPriority,
+	slackIntegrationID string,
+) error {
+	if job.SlackPayload == nil {
+		return fmt.Errorf("job has no Slack payload")
+	}
+
+	raised, err := s.jobsService.RaiseJobPriority(ctx, orgID, job.ID, priority)
+	if err != nil {
+		return fmt.Errorf("failed to raise priority of job %s: %w", job.ID, err)
+	}
+	if !raised {
+		log.Printf("⏭️ Job %s already has priority %d or higher", job.ID, priority)
+		return nil
+	}
+	job.Priority = priority
+
+	log.Printf("⏫ Raised priority of job %s to %d", job.ID, priority)
+	confirmation := "Priority raised to urgent - this job will be dispatched ahead of other queued jobs"
+	return s.sendSystemMessage(ctx, slackIntegrationID, job.SlackPayload.ChannelID, job.SlackPayload.ThreadTS, confirmation)
+}
+
+// dispatchHigherPriorityQueuedJobs gives queued jobs with a higher priority than the job the first pick
+// of free agents before the job itself is assigned
+func (s *SlackUseCase) dispatchHigherPriorityQueuedJobs(ctx context.Context, orgID models.OrgID, job *models.Job) error {
+	hasHigherPriorityJobs, err := s.jobsService.HasQueuedJobsAbovePriority(ctx, orgID, models.JobTypeSlack, job.Priority)
+	if err != nil {
+		return fmt.Errorf("failed to check for higher priority queued jobs: %w", err)
+	}
+	if !hasHigherPriorityJobs {
+		return nil
+	}
+
+	// Only the organization's queue competes for its agents - other organizations are left to the background task
+	log.Printf("⏫ Dispatching queued jobs with a higher priority before assigning job %s", job.ID)
+	integrations, err := s.slackIntegrationsService.GetSlackIntegrationsByOrganizationID(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get slack integrations of organization %s: %w", orgID, err)
+	}
+	if _, err := s.dispatchQueuedJobsOfIntegrations(ctx, integrations); err != nil {
+		return err
+	}
+	return nil
+}
+
+// cancelJob cancels the job's queued and in-progress messages and tells the agent working on them to stop
+// The job stays open for follow-up messages. If the agent was told to stop, the cancellation is confirmed
+// in the thread once it acknowledges - or reported as unconfirmed if it never does - otherwise right away.
+func (s *SlackUseCase) cancelJob(ctx context.Context, orgID models.OrgID, job *models.Job) error {
+	log.Printf("📋 Starting to cancel Slack job %s", job.ID)
+	if job.SlackPayload == nil {
+		return fmt.Errorf("job has no Slack payload")
+	}
+	slackIntegrationID := job.SlackPayload.IntegrationID
+
+	var activeMessages, inProgressMessages []*models.ProcessedSlackMessage
+	for _, status := range []models.ProcessedSlackMessageStatus{
+		models.ProcessedSlackMessageStatusQueued,
+		models.ProcessedSlackMessageStatusInProgress,
+	} {
+		messages, err := s.slackMessagesService.GetProcessedMessagesByJobIDAndStatus(
+			ctx,
+			orgID,
+			job.ID,
+			status,
+			slackIntegrationID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get %s messages for job %s: %w", status, job.ID, err)
+		}
+		activeMessages = append(activeMessages, messages...)
+		if status == models.ProcessedSlackMessageStatusInProgress {
+			inProgressMessages = messages
+		}
+	}
+	if len(activeMessages) == 0 {
+		log.Printf("⏭️ Job %s has no queued or in-progress messages to cancel", job.ID)
+		notice := "There is nothing to stop - the agent is not working on this job"
+		return s.sendSystemMessage(ctx, slackIntegrationID, job.SlackPayload.ChannelID, job.SlackPayload.ThreadTS, notice)
+	}
+
+	maybeAgent, err := s.agentsService.GetAgentByJobID(ctx, orgID, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get agent by job id: %w", err)
+	}
+
+	// Queued messages never reached an agent, so only in-progress messages need the agent to stop
+	agent, hasAgent := maybeAgent.Get()
+	awaitingAgent := hasAgent && len(inProgressMessages) > 0
+	var cancelledMessages []*models.ProcessedSlackMessage
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		for _, message := range activeMessages {
+			updatedMessage, err := s.slackMessagesService.UpdateProcessedSlackMessage(
+				ctx,
+				orgID,
+				message.ID,
+				models.ProcessedSlackMessageStatusCancelled,
+				slackIntegrationID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to cancel message %s: %w", message.ID, err)
+			}
+			cancelledMessages = append(cancelledMessages, updatedMessage)
+		}
+
+		// The cancelled prompts must not be resent to the agent, only the cancellation itself
+		if err := s.agentsService.FailPendingAgentJobMessages(ctx, orgID, job.ID, "job was cancelled"); err != nil {
+			return fmt.Errorf("failed to fail pending agent messages of job %s: %w", job.ID, err)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to cancel messages of job %s in transaction: %w", job.ID, err)
+	}
+
+	// The top-level message shows the state of the whole job instead
+	for _, message := range cancelledMessages {
+		if message.SlackTS == job.SlackPayload.ThreadTS {
+			continue
+		}
+		reactionEmoji := deriveMessageReactionFromStatus(message.Status)
+		err := s.updateSlackMessageReaction(
+			ctx,
+			message.SlackChannelID,
+			message.SlackTS,
+			reactionEmoji,
+			slackIntegrationID,
+		)
+		if err != nil {
+			log.Printf("⚠️ Failed to update reaction for cancelled message %s: %v", message.ID, err)
+		}
+	}
+
+	// The agent is told to stop only once the cancellation is committed, so it never stops on one which was
+	// rolled back
+	if awaitingAgent {
+		cancelMessage := models.BaseMessage{
+			ID:      core.NewID("msg"),
+			Type:    models.MessageTypeCancelJob,
+			Payload: models.CancelJobPayload{JobID: job.ID},
+		}
+		latestMessage := inProgressMessages[len(inProgressMessages)-1]
+		err := s.agentsService.SendMessageToAgent(
+			ctx,
+			orgID,
+			agent.WSConnectionID,
+			job.ID,
+			latestMessage.ID,
+			cancelMessage,
+		)
+		if errors.Is(err, core.ErrUnsupportedMessageType) {
+			log.Printf("⚠️ Agent %s does not support cancelling job %s", agent.ID, job.ID)
+			notice := "Your messages were cancelled, but the agent working on this job does not support " +
+				"stopping jobs and may finish its current work - upgrade ccagent to stop jobs from chat"
+			return s.sendSystemMessage(ctx, slackIntegrationID, job.SlackPayload.ChannelID, job.SlackPayload.ThreadTS, notice)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to send cancel job message to agent %s: %w", agent.ID, err)
+		}
+		log.Printf("🛑 Sent cancel job message for job %s to agent %s", job.ID, agent.ID)
+	} else {
+		if err := s.confirmJobCancelled(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("📋 Completed successfully - cancelled %d messages of Slack job %s", len(cancelledMessages), job.ID)
+	return nil
+}
+
+// confirmJobCancelled tells the user the job was stopped and shows it as waiting for next steps
+func (s *SlackUseCase) confirmJobCancelled(ctx context.Context, job *models.Job) error {
+	slackIntegrationID := job.SlackPayload.IntegrationID
+	channelID, threadTS := job.SlackPayload.ChannelID, job.SlackPayload.ThreadTS
+	if err := s.updateSlackMessageReaction(ctx, channelID, threadTS, "hand", slackIntegrationID); err != nil {
+		log.Printf("⚠️ Failed to add hand emoji to cancelled job %s: %v", job.ID, err)
+	}
+
+	confirmation := "Job stopped - reply in this thread to continue"
+	if err := s.sendSystemMessage(ctx, slackIntegrationID, channelID, threadTS, confirmation); err != nil {
+		return fmt.Errorf("failed to send cancellation confirmation to Slack: %w", err)
+	}
+	return nil
+}
+
 func deriveMessageReactionFromStatus(status models.ProcessedSlackMessageStatus) string {
 	switch status {
 	case models.ProcessedSlackMessageStatusInProgress:
@@ -276,6 +700,10 @@ func deriveMessageReactionFromStatus(status models.ProcessedSlackMessageStatus)
 		return "hourglass"
 	case models.ProcessedSlackMessageStatusCompleted:
 		return "white_check_mark"
+	case models.ProcessedSlackMessageStatusCancelled:
+		return "no_entry_sign"
+	case models.ProcessedSlackMessageStatusFailed:
+		return "x"
 	default:
 		utils.AssertInvariant(false, "invalid status received")
 		return ""
@@ -283,7 +711,7 @@ func deriveMessageReactionFromStatus(status models.ProcessedSlackMessageStatus)
 }
 
 func getOldReactions(newEmoji string) []string {
-	allReactions := []string{"hourglass", "eyes", "white_check_mark", "hand", "x"}
+	allReactions := []string{"hourglass", "eyes", "white_check_mark", "hand", "x", "no_entry_sign"}
 
 	var result []string
 	for _, reaction := range allReactions {
@@ -301,11 +729,61 @@ func isAgentErrorMessage(message string) bool {
 	return strings.HasPrefix(message, "ccagent encountered error:")
 }
 
-// groupMessagesByJobID groups processed Slack messages by their job ID
-func groupMessagesByJobID(messages []*models.ProcessedSlackMessage) map[string][]*models.ProcessedSlackMessage {
-	grouped := make(map[string][]*models.ProcessedSlackMessage)
+// firstQueuedAtByJobID returns the creation time of the oldest message of each job
+func firstQueuedAtByJobID(messages []*models.ProcessedSlackMessage) map[string]time.Time {
+	firstQueuedAt := make(map[string]time.Time)
 	for _, msg := range messages {
-		grouped[msg.JobID] = append(grouped[msg.JobID], msg)
+		if queuedAt, exists := firstQueuedAt[msg.JobID]; !exists || msg.CreatedAt.Before(queuedAt) {
+			firstQueuedAt[msg.JobID] = msg.CreatedAt
+		}
+	}
+	return firstQueuedAt
+}
+
+// notifyAgentJobClosed tells the agent which held a job that the backend closed it
+// Agents which do not support job closed messages find out once their next message for the job is rejected.
+func (s *SlackUseCase) notifyAgentJobClosed(
+	ctx context.Context,
+	job *models.Job,
+	agent *models.ActiveAgent,
+	reason string,
+) error {
+	maybeLatestMessage, err := s.slackMessagesService.GetLatestProcessedMessageForJob(
+		ctx,
+		job.OrgID,
+		job.ID,
+		job.SlackPayload.IntegrationID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get latest processed message for job: %w", err)
+	}
+	latestMessage, ok := maybeLatestMessage.Get()
+	if !ok {
+		log.Printf("⏭️ Job %s has no processed messages - not notifying agent %s", job.ID, agent.ID)
+		return nil
+	}
+
+	jobClosedMessage := models.BaseMessage{
+		ID:      core.NewID("msg"),
+		Type:    models.MessageTypeJobClosed,
+		Payload: models.JobClosedPayload{JobID: job.ID, Reason: reason},
+	}
+	err = s.agentsService.SendMessageToAgent(
+		ctx,
+		job.OrgID,
+		agent.WSConnectionID,
+		job.ID,
+		latestMessage.ID,
+		jobClosedMessage,
+	)
+	if errors.Is(err, core.ErrUnsupportedMessageType) {
+		log.Printf("⏭️ Agent %s does not support job closed messages", agent.ID)
+		return nil
 	}
-	return grouped
+	if err != nil {
+		return fmt.Errorf("failed to send job closed message to agent %s: %w", agent.ID, err)
+	}
+
+	log.Printf("📤 Sent job closed message for job %s to agent %s", job.ID, agent.ID)
+	return nil
 }
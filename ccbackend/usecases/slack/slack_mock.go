@@ -2,6 +2,7 @@ package slack
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 
@@ -52,6 +53,36 @@ func (m *MockSlackUseCase) ProcessAssistantMessage(
 	return args.Error(0)
 }
 
+func (m *MockSlackUseCase) ProcessAssistantDelta(
+	ctx context.Context,
+	clientID string,
+	payload models.AssistantDeltaPayload,
+	orgID models.OrgID,
+) error {
+	args := m.Called(ctx, clientID, payload, orgID)
+	return args.Error(0)
+}
+
+func (m *MockSlackUseCase) ProcessProgressEvent(
+	ctx context.Context,
+	clientID string,
+	payload models.ProgressEventPayload,
+	orgID models.OrgID,
+) error {
+	args := m.Called(ctx, clientID, payload, orgID)
+	return args.Error(0)
+}
+
+func (m *MockSlackUseCase) ProcessArtifact(
+	ctx context.Context,
+	clientID string,
+	payload models.ArtifactPayload,
+	orgID models.OrgID,
+) error {
+	args := m.Called(ctx, clientID, payload, orgID)
+	return args.Error(0)
+}
+
 func (m *MockSlackUseCase) ProcessSystemMessage(
 	ctx context.Context,
 	clientID string,
@@ -86,3 +117,58 @@ func (m *MockSlackUseCase) CleanupFailedSlackJob(
 	args := m.Called(ctx, job, agentID, failureMessage)
 	return args.Error(0)
 }
+
+func (m *MockSlackUseCase) CloseIdleSlackJob(ctx context.Context, job *models.Job, notice string) error {
+	args := m.Called(ctx, job, notice)
+	return args.Error(0)
+}
+
+func (m *MockSlackUseCase) HasAvailableAgentForSlackJob(
+	ctx context.Context,
+	job *models.Job,
+	activeSince time.Time,
+) (bool, error) {
+	args := m.Called(ctx, job, activeSince)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSlackUseCase) FailStuckSlackJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	explanation string,
+) error {
+	args := m.Called(ctx, job, agentID, explanation)
+	return args.Error(0)
+}
+
+func (m *MockSlackUseCase) RequeueSlackJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	notice string,
+) error {
+	args := m.Called(ctx, job, agentID, notice)
+	return args.Error(0)
+}
+
+func (m *MockSlackUseCase) ProcessJobCancelled(ctx context.Context, job *models.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockSlackUseCase) ProcessJobCancellationUnconfirmed(ctx context.Context, job *models.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockSlackUseCase) DownloadAttachment(
+	ctx context.Context,
+	source models.AttachmentSource,
+) ([]byte, error) {
+	args := m.Called(ctx, source)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
@@ -3,6 +3,7 @@ package slack
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"ccbackend/models"
 )
@@ -63,6 +64,36 @@ func (u *UnconfiguredSlackUseCase) CleanupFailedSlackJob(
 	return fmt.Errorf("slack use case is not configured")
 }
 
+func (u *UnconfiguredSlackUseCase) CloseIdleSlackJob(ctx context.Context, job *models.Job, notice string) error {
+	return fmt.Errorf("slack use case is not configured")
+}
+
+func (u *UnconfiguredSlackUseCase) HasAvailableAgentForSlackJob(
+	ctx context.Context,
+	job *models.Job,
+	activeSince time.Time,
+) (bool, error) {
+	return false, fmt.Errorf("slack use case is not configured")
+}
+
+func (u *UnconfiguredSlackUseCase) FailStuckSlackJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	explanation string,
+) error {
+	return fmt.Errorf("slack use case is not configured")
+}
+
+func (u *UnconfiguredSlackUseCase) RequeueSlackJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	notice string,
+) error {
+	return fmt.Errorf("slack use case is not configured")
+}
+
 func (u *UnconfiguredSlackUseCase) ProcessAssistantMessage(
 	ctx context.Context,
 	clientID string,
@@ -72,6 +103,33 @@ func (u *UnconfiguredSlackUseCase) ProcessAssistantMessage(
 	return fmt.Errorf("slack use case is not configured")
 }
 
+func (u *UnconfiguredSlackUseCase) ProcessAssistantDelta(
+	ctx context.Context,
+	clientID string,
+	payload models.AssistantDeltaPayload,
+	orgID models.OrgID,
+) error {
+	return fmt.Errorf("slack use case is not configured")
+}
+
+func (u *UnconfiguredSlackUseCase) ProcessProgressEvent(
+	ctx context.Context,
+	clientID string,
+	payload models.ProgressEventPayload,
+	orgID models.OrgID,
+) error {
+	return fmt.Errorf("slack use case is not configured")
+}
+
+func (u *UnconfiguredSlackUseCase) ProcessArtifact(
+	ctx context.Context,
+	clientID string,
+	payload models.ArtifactPayload,
+	orgID models.OrgID,
+) error {
+	return fmt.Errorf("slack use case is not configured")
+}
+
 func (u *UnconfiguredSlackUseCase) ProcessSystemMessage(
 	ctx context.Context,
 	clientID string,
@@ -80,3 +138,18 @@ func (u *UnconfiguredSlackUseCase) ProcessSystemMessage(
 ) error {
 	return fmt.Errorf("slack use case is not configured")
 }
+
+func (u *UnconfiguredSlackUseCase) ProcessJobCancelled(ctx context.Context, job *models.Job) error {
+	return fmt.Errorf("slack use case is not configured")
+}
+
+func (u *UnconfiguredSlackUseCase) ProcessJobCancellationUnconfirmed(ctx context.Context, job *models.Job) error {
+	return fmt.Errorf("slack use case is not configured")
+}
+
+func (u *UnconfiguredSlackUseCase) DownloadAttachment(
+	ctx context.Context,
+	source models.AttachmentSource,
+) ([]byte, error) {
+	return nil, fmt.Errorf("slack use case is not configured")
+}
@@ -6,13 +6,29 @@ import (
 	"ccbackend/salesnotif"
 	"ccbackend/services"
 	"ccbackend/usecases/agents"
+	"ccbackend/usecases/streaming"
 	"ccbackend/utils"
 	"context"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 )
 
+// urgentReaction is the reaction the job creator adds to the top-level message to mark the job as urgent
+const urgentReaction = "fire"
+
+// stopReaction is the reaction the job creator adds to the top-level message to stop the agent working on the job
+const stopReaction = "octagonal_sign"
+
+// assistantStreamUpdateInterval is how often the streamed messages of a workspace are edited in place
+// chat.update is a Tier 3 Slack method which allows roughly 50 calls per minute per workspace, shared by all
+// the assistant replies and progress statuses streamed into the workspace. The interval applies per replica.
+const assistantStreamUpdateInterval = 2 * time.Second
+
+// maxProgressStatusSteps is how many of the latest agent steps a job's status message shows
+const maxProgressStatusSteps = 5
+
 // SlackClientFactory creates a Slack client given an auth token
 type SlackClientFactory func(authToken string) clients.SlackClient
 
@@ -23,9 +39,12 @@ type SlackUseCase struct {
 	jobsService              services.JobsService
 	slackMessagesService     services.SlackMessagesService
 	slackIntegrationsService services.SlackIntegrationsService
+	connectedChannelsService services.ConnectedChannelsService
 	txManager                services.TransactionManager
 	agentsUseCase            agents.AgentsUseCaseInterface
 	slackClientFactory       SlackClientFactory
+	assistantStreams         *streaming.Tracker
+	progressStatuses         *streaming.Tracker
 }
 
 // NewSlackUseCase creates a new instance of SlackUseCase
@@ -35,19 +54,24 @@ func NewSlackUseCase(
 	jobsService services.JobsService,
 	slackMessagesService services.SlackMessagesService,
 	slackIntegrationsService services.SlackIntegrationsService,
+	connectedChannelsService services.ConnectedChannelsService,
 	txManager services.TransactionManager,
 	agentsUseCase agents.AgentsUseCaseInterface,
 	slackClientFactory SlackClientFactory,
 ) *SlackUseCase {
+	streamThrottle := streaming.NewThrottle(assistantStreamUpdateInterval)
 	return &SlackUseCase{
 		wsClient:                 wsClient,
 		agentsService:            agentsService,
 		jobsService:              jobsService,
 		slackMessagesService:     slackMessagesService,
 		slackIntegrationsService: slackIntegrationsService,
+		connectedChannelsService: connectedChannelsService,
 		txManager:                txManager,
 		agentsUseCase:            agentsUseCase,
 		slackClientFactory:       slackClientFactory,
+		assistantStreams:         streaming.NewTracker(streamThrottle),
+		progressStatuses:         streaming.NewTracker(streamThrottle),
 	}
 }
 
@@ -77,10 +101,32 @@ func (s *SlackUseCase) ProcessSlackMessageEvent(
 			return fmt.Errorf("failed to get job for thread reply: %w", err)
 		}
 		if !maybeJob.IsPresent() {
-			// Job not found for thread reply - send error message
-			log.Printf("❌ No existing job found for thread reply in %s", event.Channel)
-			errorMessage := "Error: new jobs can only be started from top-level messages"
-			return s.sendSystemMessage(ctx, slackIntegrationID, event.Channel, event.TS, errorMessage)
+			// A follow-up in the thread of a closed job starts a new job in the same thread
+			hasClosedJob, err := s.jobsService.HasClosedJobForSlackThread(
+				ctx,
+				orgID,
+				event.ThreadTS,
+				event.Channel,
+				slackIntegrationID,
+			)
+			if err != nil {
+				log.Printf("❌ Failed to check for closed job of thread %s: %v", event.ThreadTS, err)
+				return fmt.Errorf("failed to check for closed job of thread: %w", err)
+			}
+			if !hasClosedJob {
+				// Job not found for thread reply - send error message
+				log.Printf("❌ No existing job found for thread reply in %s", event.Channel)
+				errorMessage := "Error: new jobs can only be started from top-level messages"
+				return s.sendSystemMessage(ctx, slackIntegrationID, event.Channel, event.TS, errorMessage)
+			}
+			if models.IsStopJobCommand(event.Text) {
+				log.Printf("⏭️ Stop command in thread %s ignored - the job is already closed", event.ThreadTS)
+				notice := "There is nothing to stop - the job in this thread is already closed"
+				return s.sendSystemMessage(ctx, slackIntegrationID, event.Channel, event.TS, notice)
+			}
+			log.Printf("🔁 Job of thread %s is closed - starting a new job in the thread", event.ThreadTS)
+		} else if models.IsStopJobCommand(event.Text) {
+			return s.processStopCommand(ctx, maybeJob.MustGet(), event, orgID)
 		}
 	} else {
 		log.Printf("🆕 Bot mentioned at start of new thread in channel %s", event.Channel)
@@ -120,33 +166,56 @@ func (s *SlackUseCase) ProcessSlackMessageEvent(
 		return fmt.Errorf("slack integration not found: %s", slackIntegrationID)
 	}
 	// Verify the organization ID matches (already passed as parameter)
+	slackIntegration := maybeSlackIntegration.MustGet()
 
-	// Check if agents are available first
-	connectedClientIDs := s.wsClient.GetClientIDs()
-	log.Printf("📋 Retrieved %d active client IDs", len(connectedClientIDs))
-	connectedAgents, err := s.agentsService.GetConnectedActiveAgents(ctx, orgID, connectedClientIDs)
+	// Resolve the repository and agent selector configured for the channel so the job lands on a matching agent
+	repoURL, agentSelector, err := s.getChannelRouting(ctx, orgID, slackIntegration.SlackTeamID, job)
 	if err != nil {
-		log.Printf("❌ Failed to check for connected agents: %v", err)
-		return fmt.Errorf("failed to check for connected agents: %w", err)
+		return fmt.Errorf("failed to get repository for slack channel: %w", err)
 	}
 
-	var clientID string
-	var messageStatus models.ProcessedSlackMessageStatus
+	// The urgent keyword is meant for the bot, the agent and the transcript only get the request itself
+	messageText := event.Text
+	if models.ContainsUrgentKeyword(event.Text) {
+		if err := s.raiseJobPriority(ctx, orgID, job, models.JobPriorityUrgent, slackIntegrationID); err != nil {
+			return fmt.Errorf("failed to raise job priority: %w", err)
+		}
+		messageText = models.StripUrgentKeyword(event.Text)
+	}
 
-	if len(connectedAgents) == 0 {
-		// No agents available - queue the message
+	// A new job must not take a free agent away from queued jobs with a higher priority
+	if isNewConversation {
+		if err := s.dispatchHigherPriorityQueuedJobs(ctx, orgID, job); err != nil {
+			return fmt.Errorf("failed to dispatch higher priority queued jobs: %w", err)
+		}
+	}
+
+	// A reply to a job without an agent is handed to a new one, which has not seen the earlier conversation
+	handedOver := false
+	if !isNewConversation {
+		handedOver, err = s.isJobHandedOver(ctx, orgID, job)
+		if err != nil {
+			return fmt.Errorf("failed to check agent of job: %w", err)
+		}
+	}
+
+	clientID, assigned, err := s.agentsUseCase.TryAssignJobToAgent(ctx, job.ID, repoURL, agentSelector, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to assign agent for job: %w", err)
+	}
+
+	var messageStatus models.ProcessedSlackMessageStatus
+	if !assigned {
+		// No matching agents available - queue the message
 		log.Printf("⚠️ No available agents to handle Slack mention - queuing message")
 		messageStatus = models.ProcessedSlackMessageStatusQueued
-		clientID = "" // No agent assigned
 	} else {
-		// Agents available - assign job to agent
-		clientID, err = s.agentsUseCase.GetOrAssignAgentForJob(ctx, job, threadTS, orgID)
-		if err != nil {
-			return fmt.Errorf("failed to get or assign agent for job: %w", err)
-		}
 		messageStatus = models.ProcessedSlackMessageStatusInProgress
 	}
 
+	// Only attachments within the size limits are forwarded to the agent
+	attachments, skippedAttachments := event.Attachments.WithinLimits()
+
 	// Store the Slack message as ProcessedSlackMessage with appropriate status
 	processedMessage, err := s.slackMessagesService.CreateProcessedSlackMessage(
 		ctx,
@@ -154,13 +223,30 @@ func (s *SlackUseCase) ProcessSlackMessageEvent(
 		job.ID,
 		event.Channel,
 		event.TS,
-		event.Text,
+		messageText,
 		slackIntegrationID,
 		messageStatus,
+		attachments,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create processed slack message: %w", err)
 	}
+	s.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorUser,
+		event.User,
+		models.TranscriptMessageTypeUserMessage,
+		messageText,
+		processedMessage.ID,
+	)
+
+	if len(skippedAttachments) > 0 {
+		skippedNotice := skippedAttachments.SkippedNotice()
+		if err := s.sendSystemMessage(ctx, slackIntegrationID, event.Channel, threadTS, skippedNotice); err != nil {
+			return fmt.Errorf("failed to send skipped attachments notice: %w", err)
+		}
+	}
 
 	// Add emoji reaction based on message status
 	reactionEmoji := deriveMessageReactionFromStatus(messageStatus)
@@ -179,14 +265,42 @@ func (s *SlackUseCase) ProcessSlackMessageEvent(
 
 	// If message was queued, don't send to agent yet - background processor will handle it
 	if messageStatus == models.ProcessedSlackMessageStatusQueued {
+		if repoURL != "" {
+			startedWaiting, err := s.isOnlyQueuedMessage(ctx, processedMessage)
+			if err != nil {
+				return fmt.Errorf("failed to check queued messages of job: %w", err)
+			}
+			if startedWaiting {
+				waitingMessage := fmt.Sprintf(
+					"Waiting for an available agent working on repository `%s` - your message is queued",
+					repoURL,
+				)
+				if err := s.sendSystemMessage(
+					ctx,
+					slackIntegrationID,
+					job.SlackPayload.ChannelID,
+					job.SlackPayload.ThreadTS,
+					waitingMessage,
+				); err != nil {
+					return fmt.Errorf("failed to send queued repository notice: %w", err)
+				}
+			}
+		}
 		log.Printf("📋 Message queued for background processing - job %s", job.ID)
 		log.Printf("📋 Completed successfully - processed Slack message event (queued)")
 		return nil
 	}
 
-	// Send work to assigned agent
-	if isNewConversation {
-		if err := s.sendStartConversationToAgent(ctx, clientID, processedMessage); err != nil {
+	// Send work to assigned agent - an agent taking over the job gets the earlier conversation as history
+	if isNewConversation || handedOver {
+		var history []models.ConversationTurn
+		if handedOver {
+			history, err = s.getConversationHistory(ctx, job, []*models.ProcessedSlackMessage{processedMessage})
+			if err != nil {
+				return err
+			}
+		}
+		if err := s.sendStartConversationToAgent(ctx, clientID, processedMessage, history); err != nil {
 			return fmt.Errorf("failed to send start conversation message: %w", err)
 		}
 	} else {
@@ -212,6 +326,13 @@ func (s *SlackUseCase) ProcessReactionAdded(
 		channelID,
 	)
 
+	if reactionName == urgentReaction {
+		return s.processUrgentReaction(ctx, userID, channelID, messageTS, slackIntegrationID, orgID)
+	}
+	if reactionName == stopReaction {
+		return s.processStopReaction(ctx, userID, channelID, messageTS, slackIntegrationID, orgID)
+	}
+
 	// Only handle white check mark, check mark, or white tick reactions
 	if reactionName != "white_check_mark" && reactionName != "heavy_check_mark" && reactionName != "white_tick" {
 		log.Printf("⏭️ Ignoring reaction: %s (not a completion emoji)", reactionName)
@@ -262,6 +383,7 @@ func (s *SlackUseCase) ProcessReactionAdded(
 		return fmt.Errorf("failed to get agent by job id: %w", err)
 	}
 
+	var assignedAgentID string
 	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
 		// If agent is found, unassign them from the job
 		if maybeAgent.IsPresent() {
@@ -270,14 +392,22 @@ func (s *SlackUseCase) ProcessReactionAdded(
 				log.Printf("❌ Failed to unassign agent %s from job %s: %v", agent.ID, job.ID, err)
 				return fmt.Errorf("failed to unassign agent from job: %w", err)
 			}
+			assignedAgentID = agent.ID
 
 			log.Printf("✅ Unassigned agent %s from manually completed job %s", agent.ID, job.ID)
 		}
 
-		// Delete the job and its associated processed messages
-		if err := s.jobsService.DeleteJob(ctx, orgID, job.ID); err != nil {
-			log.Printf("❌ Failed to delete completed job %s: %v", job.ID, err)
-			return fmt.Errorf("failed to delete completed job: %w", err)
+		// Close the job, keeping it and its processed messages as history
+		if err := s.jobsService.CloseJob(
+			ctx,
+			orgID,
+			job.ID,
+			models.JobStatusCompleted,
+			models.ManualJobCompletionReason,
+			assignedAgentID,
+		); err != nil {
+			log.Printf("❌ Failed to close completed job %s: %v", job.ID, err)
+			return fmt.Errorf("failed to close completed job: %w", err)
 		}
 
 		return nil
@@ -292,21 +422,121 @@ func (s *SlackUseCase) ProcessReactionAdded(
 	}
 
 	// Send completion message to Slack thread
-	if err := s.sendSystemMessage(ctx, slackIntegrationID, job.SlackPayload.ChannelID, job.SlackPayload.ThreadTS, "Job manually marked as complete"); err != nil {
+	if err := s.sendSystemMessage(ctx, slackIntegrationID, job.SlackPayload.ChannelID, job.SlackPayload.ThreadTS, models.ManualJobCompletionReason); err != nil {
 		log.Printf("❌ Failed to send completion message to Slack thread %s: %v", job.SlackPayload.ThreadTS, err)
 		return fmt.Errorf("failed to send completion message to Slack: %w", err)
 	}
+	s.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorUser,
+		userID,
+		models.TranscriptMessageTypeSystemMessage,
+		models.ManualJobCompletionReason,
+		"",
+	)
 
 	log.Printf("📤 Sent completion message to Slack thread %s", job.SlackPayload.ThreadTS)
 
 	// Send sales notification for manual job completion
 	salesnotif.New(orgID, fmt.Sprintf("Manually completed job `%s`", job.ID))
 
-	log.Printf("🗑️ Deleted manually completed job %s", job.ID)
 	log.Printf("📋 Completed successfully - processed manual job completion for job %s", job.ID)
 	return nil
 }
 
+// processUrgentReaction raises the priority of the job started by the reacted-to message
+// Only the job creator can mark their job as urgent
+func (s *SlackUseCase) processUrgentReaction(
+	ctx context.Context,
+	userID, channelID, messageTS, slackIntegrationID string,
+	orgID models.OrgID,
+) error {
+	maybeJob, err := s.jobsService.GetJobBySlackThread(ctx, orgID, messageTS, channelID, slackIntegrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get job for reaction: %w", err)
+	}
+	if !maybeJob.IsPresent() {
+		log.Printf("⏭️ No job found for message %s in channel %s - ignoring reaction", messageTS, channelID)
+		return nil
+	}
+	job := maybeJob.MustGet()
+
+	if job.SlackPayload == nil {
+		log.Printf("⏭️ Job %s has no Slack payload", job.ID)
+		return nil
+	}
+	if job.SlackPayload.UserID != userID {
+		log.Printf("⏭️ Reaction from %s ignored - job %s was created by %s", userID, job.ID, job.SlackPayload.UserID)
+		return nil
+	}
+
+	if err := s.raiseJobPriority(ctx, orgID, job, models.JobPriorityUrgent, slackIntegrationID); err != nil {
+		return fmt.Errorf("failed to raise job priority: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - processed urgent reaction for job %s", job.ID)
+	return nil
+}
+
+// processStopReaction stops the agent working on the job started by the reacted-to message
+// Only the job creator can stop their job
+func (s *SlackUseCase) processStopReaction(
+	ctx context.Context,
+	userID, channelID, messageTS, slackIntegrationID string,
+	orgID models.OrgID,
+) error {
+	maybeJob, err := s.jobsService.GetJobBySlackThread(ctx, orgID, messageTS, channelID, slackIntegrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get job for reaction: %w", err)
+	}
+	if !maybeJob.IsPresent() {
+		log.Printf("⏭️ No job found for message %s in channel %s - ignoring reaction", messageTS, channelID)
+		return nil
+	}
+	job := maybeJob.MustGet()
+
+	if job.SlackPayload == nil {
+		log.Printf("⏭️ Job %s has no Slack payload", job.ID)
+		return nil
+	}
+	if job.SlackPayload.UserID != userID {
+		log.Printf("⏭️ Reaction from %s ignored - job %s was created by %s", userID, job.ID, job.SlackPayload.UserID)
+		return nil
+	}
+
+	if err := s.cancelJob(ctx, orgID, job); err != nil {
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - processed stop reaction for job %s", job.ID)
+	return nil
+}
+
+// processStopCommand stops the agent working on the job when its creator replies "@bot stop" in the thread
+func (s *SlackUseCase) processStopCommand(
+	ctx context.Context,
+	job *models.Job,
+	event models.SlackMessageEvent,
+	orgID models.OrgID,
+) error {
+	if job.SlackPayload == nil {
+		return fmt.Errorf("job has no Slack payload")
+	}
+	if job.SlackPayload.UserID != event.User {
+		log.Printf("⏭️ Stop command from %s ignored - job %s was created by someone else", event.User, job.ID)
+		errorMessage := "Only the user who started this job can stop it"
+		return s.sendSystemMessage(ctx, job.SlackPayload.IntegrationID, event.Channel, event.ThreadTS, errorMessage)
+	}
+
+	if err := s.cancelJob(ctx, orgID, job); err != nil {
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - processed stop command for job %s", job.ID)
+	return nil
+}
+
 func (s *SlackUseCase) ProcessProcessingMessage(
 	ctx context.Context,
 	clientID string,
@@ -361,9 +591,27 @@ func (s *SlackUseCase) ProcessQueuedJobs(ctx context.Context) error {
 		return nil
 	}
 
-	totalProcessedJobs := 0
+	totalProcessedJobs, err := s.dispatchQueuedJobsOfIntegrations(ctx, integrations)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("📋 Completed successfully - processed %d queued jobs", totalProcessedJobs)
+	return nil
+}
 
-	for _, integration := range integrations {
+// dispatchQueuedJobsOfIntegrations dispatches the queued jobs of the integrations, one queue per organization
+// Returns the number of jobs handed to an agent
+func (s *SlackUseCase) dispatchQueuedJobsOfIntegrations(
+	ctx context.Context,
+	integrations []models.SlackIntegration,
+) (int, error) {
+	// Build one queue per organization so that jobs from all of its channels compete fairly for agents
+	queuedJobsByOrg := make(map[models.OrgID][]models.QueuedJob)
+	jobIntegrations := make(map[string]*models.SlackIntegration)
+	var orgIDs []models.OrgID
+	for i := range integrations {
+		integration := &integrations[i]
 		slackIntegrationID := integration.ID
 
 		// Get queued messages for this integration
@@ -374,7 +622,7 @@ func (s *SlackUseCase) ProcessQueuedJobs(ctx context.Context) error {
 			slackIntegrationID,
 		)
 		if err != nil {
-			return fmt.Errorf("failed to get queued messages for integration %s: %w", slackIntegrationID, err)
+			return 0, fmt.Errorf("failed to get queued messages for integration %s: %w", slackIntegrationID, err)
 		}
 
 		if len(queuedMessages) == 0 {
@@ -383,98 +631,153 @@ func (s *SlackUseCase) ProcessQueuedJobs(ctx context.Context) error {
 
 		log.Printf("🔍 Found %d queued messages for integration %s", len(queuedMessages), slackIntegrationID)
 
-		// Group messages by job ID for efficient processing
-		jobMessagesMap := groupMessagesByJobID(queuedMessages)
-
-		// Try to assign each job with queued messages to an available agent
-		for jobID, messages := range jobMessagesMap {
-			// Only fetch job if we need job payload for processing
+		for jobID, firstQueuedAt := range firstQueuedAtByJobID(queuedMessages) {
 			maybeJob, err := s.jobsService.GetJobByID(ctx, integration.OrgID, jobID)
 			if err != nil {
-				return fmt.Errorf("failed to get job %s for integration %s: %w", jobID, slackIntegrationID, err)
+				return 0, fmt.Errorf("failed to get job %s for integration %s: %w", jobID, slackIntegrationID, err)
 			}
 			if maybeJob.IsAbsent() {
-				return fmt.Errorf("job %s not found for integration %s", jobID, slackIntegrationID)
+				return 0, fmt.Errorf("job %s not found for integration %s", jobID, slackIntegrationID)
 			}
-			job := maybeJob.MustGet()
 
-			log.Printf("🔄 Processing %d queued messages for job %s", len(messages), job.ID)
-
-			// Get organization ID for this integration
-			orgID := integration.OrgID
-
-			// Try to assign job to an available agent
-			clientID, assigned, err := s.agentsUseCase.TryAssignJobToAgent(ctx, job.ID, orgID)
-			if err != nil {
-				return fmt.Errorf("failed to assign queued job %s: %w", job.ID, err)
-			}
-
-			if !assigned {
-				log.Printf("⚠️ Still no agents available for queued job %s", job.ID)
-				continue
+			if _, exists := queuedJobsByOrg[integration.OrgID]; !exists {
+				orgIDs = append(orgIDs, integration.OrgID)
 			}
+			queuedJobsByOrg[integration.OrgID] = append(queuedJobsByOrg[integration.OrgID], models.QueuedJob{
+				Job:           maybeJob.MustGet(),
+				FirstQueuedAt: firstQueuedAt,
+			})
+			jobIntegrations[jobID] = integration
+		}
+	}
 
-			// Job was successfully assigned - get queued messages and send them to agent
-			queuedMessages, err := s.slackMessagesService.GetProcessedMessagesByJobIDAndStatus(
-				ctx,
-				integration.OrgID,
+	totalProcessedJobs := 0
+	for _, orgID := range orgIDs {
+		// Dispatch in fair-share FIFO order - jobs which can't be assigned stay queued without blocking the rest
+		queue := models.OrderQueuedJobs(queuedJobsByOrg[orgID])
+		log.Printf("📋 Dispatching %d queued jobs for organization %s", len(queue), orgID)
+
+		for position, queuedJob := range queue {
+			job := queuedJob.Job
+			log.Printf(
+				"🔄 Processing queued job %s (queue position %d, first queued at %s)",
 				job.ID,
-				models.ProcessedSlackMessageStatusQueued,
-				slackIntegrationID,
+				position+1,
+				queuedJob.FirstQueuedAt.Format(time.RFC3339),
 			)
+
+			dispatched, err := s.dispatchQueuedJob(ctx, jobIntegrations[job.ID], job)
 			if err != nil {
-				return fmt.Errorf("failed to get queued messages for job %s: %w", job.ID, err)
+				return totalProcessedJobs, err
 			}
+			if dispatched {
+				totalProcessedJobs++
+			}
+		}
+	}
 
-			log.Printf("📨 Found %d queued messages for job %s", len(queuedMessages), job.ID)
+	return totalProcessedJobs, nil
+}
 
-			// Process each queued message
-			for _, message := range queuedMessages {
-				// Update message status to IN_PROGRESS
-				updatedMessage, err := s.slackMessagesService.UpdateProcessedSlackMessage(
-					ctx,
-					integration.OrgID,
-					message.ID,
-					models.ProcessedSlackMessageStatusInProgress,
-					slackIntegrationID,
-				)
-				if err != nil {
-					return fmt.Errorf("failed to update message %s status: %w", message.ID, err)
-				}
+// dispatchQueuedJob tries to assign a queued job to an agent and sends the job's queued messages to it
+// Returns false if no matching agent is available and the job stays queued
+func (s *SlackUseCase) dispatchQueuedJob(
+	ctx context.Context,
+	integration *models.SlackIntegration,
+	job *models.Job,
+) (bool, error) {
+	orgID := integration.OrgID
+	slackIntegrationID := integration.ID
 
-				// Update Slack reaction to show processing (eyes emoji)
-				if err := s.updateSlackMessageReaction(ctx, updatedMessage.SlackChannelID, updatedMessage.SlackTS, "eyes", slackIntegrationID); err != nil {
-					return fmt.Errorf("failed to update slack reaction for message %s: %w", message.ID, err)
-				}
+	// A job without an agent is handed to a new one, which has not seen its earlier conversation
+	handedOver, err := s.isJobHandedOver(ctx, orgID, job)
+	if err != nil {
+		return false, fmt.Errorf("failed to check agent of queued job %s: %w", job.ID, err)
+	}
 
-				// Determine if this is the first message in the job (new conversation)
-				// Check if this message's timestamp matches the job's thread timestamp (i.e., it's the top-level message)
-				isNewConversation := false
-				if job.SlackPayload != nil {
-					isNewConversation = updatedMessage.SlackTS == job.SlackPayload.ThreadTS
-				}
+	// Try to assign job to an available agent matching the channel's repository and agent selector
+	repoURL, agentSelector, err := s.getChannelRouting(ctx, orgID, integration.SlackTeamID, job)
+	if err != nil {
+		return false, fmt.Errorf("failed to get repository for queued job %s: %w", job.ID, err)
+	}
+	clientID, assigned, err := s.agentsUseCase.TryAssignJobToAgent(ctx, job.ID, repoURL, agentSelector, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to assign queued job %s: %w", job.ID, err)
+	}
 
-				// Send work to assigned agent
-				if isNewConversation {
-					if err := s.sendStartConversationToAgent(ctx, clientID, updatedMessage); err != nil {
-						return fmt.Errorf("failed to send start conversation for message %s: %w", message.ID, err)
-					}
-				} else {
-					if err := s.sendUserMessageToAgent(ctx, clientID, updatedMessage); err != nil {
-						return fmt.Errorf("failed to send user message %s: %w", message.ID, err)
-					}
-				}
+	if !assigned {
+		log.Printf("⚠️ Still no agents available for queued job %s", job.ID)
+		return false, nil
+	}
 
-				log.Printf("✅ Successfully assigned and sent queued message %s to agent", message.ID)
-			}
+	// Job was successfully assigned - get queued messages and send them to agent
+	queuedMessages, err := s.slackMessagesService.GetProcessedMessagesByJobIDAndStatus(
+		ctx,
+		integration.OrgID,
+		job.ID,
+		models.ProcessedSlackMessageStatusQueued,
+		slackIntegrationID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to get queued messages for job %s: %w", job.ID, err)
+	}
+
+	log.Printf("📨 Found %d queued messages for job %s", len(queuedMessages), job.ID)
 
-			totalProcessedJobs++
-			log.Printf("✅ Successfully processed queued job %s with %d messages", job.ID, len(queuedMessages))
+	// A new agent taking over a started conversation gets the earlier conversation as history with the first
+	// queued message, instead of answering the earlier messages again
+	var history []models.ConversationTurn
+	continuesConversation := handedOver && len(queuedMessages) > 0 && job.SlackPayload != nil &&
+		queuedMessages[0].SlackTS != job.SlackPayload.ThreadTS
+	if continuesConversation {
+		history, err = s.getConversationHistory(ctx, job, queuedMessages)
+		if err != nil {
+			return false, err
 		}
 	}
 
-	log.Printf("📋 Completed successfully - processed %d queued jobs", totalProcessedJobs)
-	return nil
+	// Process each queued message
+	for i, message := range queuedMessages {
+		// Update message status to IN_PROGRESS
+		updatedMessage, err := s.slackMessagesService.UpdateProcessedSlackMessage(
+			ctx,
+			integration.OrgID,
+			message.ID,
+			models.ProcessedSlackMessageStatusInProgress,
+			slackIntegrationID,
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to update message %s status: %w", message.ID, err)
+		}
+
+		// Update Slack reaction to show processing (eyes emoji)
+		if err := s.updateSlackMessageReaction(ctx, updatedMessage.SlackChannelID, updatedMessage.SlackTS, "eyes", slackIntegrationID); err != nil {
+			return false, fmt.Errorf("failed to update slack reaction for message %s: %w", message.ID, err)
+		}
+
+		// Determine if this is the first message in the job (new conversation)
+		// Check if this message's timestamp matches the job's thread timestamp (i.e., it's the top-level message)
+		isNewConversation := false
+		if job.SlackPayload != nil {
+			isNewConversation = updatedMessage.SlackTS == job.SlackPayload.ThreadTS
+		}
+
+		// Send work to assigned agent
+		if isNewConversation || (continuesConversation && i == 0) {
+			if err := s.sendStartConversationToAgent(ctx, clientID, updatedMessage, history); err != nil {
+				return false, fmt.Errorf("failed to send start conversation for message %s: %w", message.ID, err)
+			}
+		} else {
+			if err := s.sendUserMessageToAgent(ctx, clientID, updatedMessage); err != nil {
+				return false, fmt.Errorf("failed to send user message %s: %w", message.ID, err)
+			}
+		}
+
+		log.Printf("✅ Successfully assigned and sent queued message %s to agent", message.ID)
+	}
+
+	log.Printf("✅ Successfully processed queued job %s with %d messages", job.ID, len(queuedMessages))
+	return true, nil
 }
 
 // ProcessJobComplete handles job completion from an agent
@@ -549,12 +852,19 @@ func (s *SlackUseCase) ProcessJobComplete(
 		}
 		log.Printf("✅ Unassigned agent %s from completed job %s", agent.ID, jobID)
 
-		// Delete the job and its associated processed messages
-		if err := s.jobsService.DeleteJob(ctx, orgID, jobID); err != nil {
-			log.Printf("❌ Failed to delete completed job %s: %v", jobID, err)
-			return fmt.Errorf("failed to delete completed job: %w", err)
+		// Close the job, keeping it and its processed messages as history
+		if err := s.jobsService.CloseJob(
+			ctx,
+			orgID,
+			jobID,
+			models.JobStatusCompleted,
+			payload.Reason,
+			agent.ID,
+		); err != nil {
+			log.Printf("❌ Failed to close completed job %s: %v", jobID, err)
+			return fmt.Errorf("failed to close completed job: %w", err)
 		}
-		log.Printf("🗑️ Deleted completed job %s", jobID)
+		log.Printf("📁 Closed completed job %s", jobID)
 
 		return nil
 	}); err != nil {
@@ -566,6 +876,15 @@ func (s *SlackUseCase) ProcessJobComplete(
 		log.Printf("❌ Failed to send completion message to Slack thread %s: %v", job.SlackPayload.ThreadTS, err)
 		return fmt.Errorf("failed to send completion message to Slack: %w", err)
 	}
+	s.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorAgent,
+		agent.ID,
+		models.TranscriptMessageTypeSystemMessage,
+		payload.Reason,
+		"",
+	)
 
 	log.Printf("📤 Sent completion message to Slack thread %s: %s", job.SlackPayload.ThreadTS, payload.Reason)
 
@@ -596,6 +915,15 @@ func (s *SlackUseCase) CleanupFailedSlackJob(
 		log.Printf("❌ Failed to send failure message to Slack thread %s: %v", job.SlackPayload.ThreadTS, err)
 		// Continue with cleanup even if Slack message fails
 	}
+	s.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorSystem,
+		"",
+		models.TranscriptMessageTypeSystemMessage,
+		failureMessage,
+		"",
+	)
 
 	// Update the top-level message emoji to :x:
 	if err := s.updateSlackMessageReaction(ctx, job.SlackPayload.ChannelID, job.SlackPayload.ThreadTS, "x", slackIntegrationID); err != nil {
@@ -614,12 +942,12 @@ func (s *SlackUseCase) CleanupFailedSlackJob(
 			log.Printf("🔗 Unassigned agent %s from job %s", agentID, job.ID)
 		}
 
-		// Delete the job (use the job's slack integration and organization from the job)
-		if err := s.jobsService.DeleteJob(ctx, orgID, job.ID); err != nil {
-			log.Printf("❌ Failed to delete job %s: %v", job.ID, err)
-			return fmt.Errorf("failed to delete job: %w", err)
+		// Close the job as failed, keeping it and its processed messages as history
+		if err := s.jobsService.CloseJob(ctx, orgID, job.ID, models.JobStatusFailed, failureMessage, agentID); err != nil {
+			log.Printf("❌ Failed to close job %s: %v", job.ID, err)
+			return fmt.Errorf("failed to close job: %w", err)
 		}
-		log.Printf("🗑️ Deleted job %s", job.ID)
+		log.Printf("📁 Closed failed job %s", job.ID)
 
 		return nil
 	}); err != nil {
@@ -629,6 +957,324 @@ func (s *SlackUseCase) CleanupFailedSlackJob(
 	return nil
 }
 
+// CloseIdleSlackJob closes a job which was idle for longer than the organization's idle job timeout
+// The notice is posted in the thread, and the agent holding the job is unassigned and told the job was closed.
+func (s *SlackUseCase) CloseIdleSlackJob(ctx context.Context, job *models.Job, notice string) error {
+	log.Printf("📋 Starting to close idle Slack job %s", job.ID)
+	if job.SlackPayload == nil {
+		log.Printf("❌ Job %s has no Slack payload", job.ID)
+		return fmt.Errorf("job has no Slack payload")
+	}
+	slackIntegrationID := job.SlackPayload.IntegrationID
+	orgID := job.OrgID
+
+	maybeAgent, err := s.agentsService.GetAgentByJobID(ctx, orgID, job.ID)
+	if err != nil {
+		log.Printf("❌ Failed to find agent for job %s: %v", job.ID, err)
+		return fmt.Errorf("failed to get agent by job id: %w", err)
+	}
+	agent, hasAgent := maybeAgent.Get()
+
+	var assignedAgentID string
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if hasAgent {
+			if err := s.agentsService.UnassignAgentFromJob(ctx, orgID, agent.ID, job.ID); err != nil {
+				log.Printf("❌ Failed to unassign agent %s from job %s: %v", agent.ID, job.ID, err)
+				return fmt.Errorf("failed to unassign agent from job: %w", err)
+			}
+			assignedAgentID = agent.ID
+			log.Printf("🔗 Unassigned agent %s from idle job %s", agent.ID, job.ID)
+		}
+
+		// Close the job as abandoned, keeping it and its processed messages as history
+		if err := s.jobsService.CloseJob(ctx, orgID, job.ID, models.JobStatusAbandoned, notice, assignedAgentID); err != nil {
+			log.Printf("❌ Failed to close idle job %s: %v", job.ID, err)
+			return fmt.Errorf("failed to close idle job: %w", err)
+		}
+		log.Printf("📁 Closed idle job %s", job.ID)
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to close idle job %s in transaction: %w", job.ID, err)
+	}
+
+	if hasAgent {
+		if err := s.notifyAgentJobClosed(ctx, job, agent, notice); err != nil {
+			log.Printf("⚠️ Failed to notify agent %s that idle job %s was closed: %v", agent.ID, job.ID, err)
+			// Don't return error - the job is closed and the agent's next message for it is rejected
+		}
+	}
+
+	if err := s.updateSlackMessageReaction(
+		ctx,
+		job.SlackPayload.ChannelID,
+		job.SlackPayload.ThreadTS,
+		"white_check_mark",
+		slackIntegrationID,
+	); err != nil {
+		log.Printf("⚠️ Failed to update top-level message reaction for idle job %s: %v", job.ID, err)
+		// Don't return error - this is not critical to closing the job
+	}
+
+	if err := s.sendSystemMessage(
+		ctx,
+		slackIntegrationID,
+		job.SlackPayload.ChannelID,
+		job.SlackPayload.ThreadTS,
+		notice,
+	); err != nil {
+		log.Printf("❌ Failed to send closing notice to Slack thread %s: %v", job.SlackPayload.ThreadTS, err)
+		return fmt.Errorf("failed to send closing notice to Slack: %w", err)
+	}
+	s.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorSystem,
+		"",
+		models.TranscriptMessageTypeSystemMessage,
+		notice,
+		"",
+	)
+
+	log.Printf("📋 Completed successfully - closed idle Slack job %s", job.ID)
+	return nil
+}
+
+// RequeueSlackJob unassigns a job from its agent and queues its in-progress messages again
+// The next agent picking up the job gets the earlier conversation as history - completed messages stay completed.
+// An empty agentID requeues a job whose agent is already gone.
+func (s *SlackUseCase) RequeueSlackJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	notice string,
+) error {
+	log.Printf("📋 Starting to requeue Slack job %s from agent %s", job.ID, agentID)
+	if job.SlackPayload == nil {
+		log.Printf("❌ Job %s has no Slack payload", job.ID)
+		return fmt.Errorf("job has no Slack payload")
+	}
+	slackIntegrationID := job.SlackPayload.IntegrationID
+	orgID := job.OrgID
+
+	var requeuedMessages []*models.ProcessedSlackMessage
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if agentID != "" {
+			if err := s.agentsService.UnassignAgentFromJob(ctx, orgID, agentID, job.ID); err != nil {
+				log.Printf("❌ Failed to unassign agent %s from job %s: %v", agentID, job.ID, err)
+				return fmt.Errorf("failed to unassign agent from job: %w", err)
+			}
+			log.Printf("🔗 Unassigned agent %s from job %s", agentID, job.ID)
+		}
+
+		// In-flight messages are sent to the new agent again, queued messages are still waiting for it
+		messages, err := s.slackMessagesService.GetProcessedMessagesByJobIDAndStatus(
+			ctx,
+			orgID,
+			job.ID,
+			models.ProcessedSlackMessageStatusInProgress,
+			slackIntegrationID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get in-progress messages for job %s: %w", job.ID, err)
+		}
+
+		for _, message := range messages {
+			updatedMessage, err := s.slackMessagesService.UpdateProcessedSlackMessage(
+				ctx,
+				orgID,
+				message.ID,
+				models.ProcessedSlackMessageStatusQueued,
+				slackIntegrationID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to requeue message %s: %w", message.ID, err)
+			}
+			requeuedMessages = append(requeuedMessages, updatedMessage)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to requeue job %s in transaction: %w", job.ID, err)
+	}
+
+	// Show the requeued messages as waiting again
+	for _, message := range requeuedMessages {
+		reactionEmoji := deriveMessageReactionFromStatus(message.Status)
+		if err := s.updateSlackMessageReaction(ctx, message.SlackChannelID, message.SlackTS, reactionEmoji, slackIntegrationID); err != nil {
+			log.Printf("⚠️ Failed to update reaction for requeued message %s: %v", message.ID, err)
+		}
+	}
+
+	if err := s.sendSystemMessage(ctx, slackIntegrationID, job.SlackPayload.ChannelID, job.SlackPayload.ThreadTS, notice); err != nil {
+		log.Printf("❌ Failed to send requeue notice to Slack thread %s: %v", job.SlackPayload.ThreadTS, err)
+		// Don't return error - the job is already queued for another agent
+	}
+
+	log.Printf("📋 Completed successfully - requeued Slack job %s with %d messages", job.ID, len(requeuedMessages))
+	return nil
+}
+
+// HasAvailableAgentForSlackJob returns true if an agent matching the repository and agent selector of the job's
+// channel could take the job right now. Agents which have not been active since activeSince are not counted.
+func (s *SlackUseCase) HasAvailableAgentForSlackJob(
+	ctx context.Context,
+	job *models.Job,
+	activeSince time.Time,
+) (bool, error) {
+	if job.SlackPayload == nil {
+		return false, fmt.Errorf("job has no Slack payload")
+	}
+
+	maybeIntegration, err := s.slackIntegrationsService.GetSlackIntegrationByID(ctx, job.SlackPayload.IntegrationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get Slack integration: %w", err)
+	}
+	integration, ok := maybeIntegration.Get()
+	if !ok {
+		return false, fmt.Errorf("Slack integration not found: %s", job.SlackPayload.IntegrationID)
+	}
+
+	repoURL, agentSelector, err := s.getChannelRouting(ctx, job.OrgID, integration.SlackTeamID, job)
+	if err != nil {
+		return false, fmt.Errorf("failed to get repository for job %s: %w", job.ID, err)
+	}
+	return s.agentsUseCase.HasAvailableAgent(ctx, repoURL, agentSelector, job.OrgID, activeSince)
+}
+
+// FailStuckSlackJob fails the in-progress messages of a job whose agent stopped responding mid-turn
+// The messages are marked with :x: and the explanation is posted in the thread. The agent, if it still holds the job,
+// is unassigned and the job stays open for follow-up messages.
+func (s *SlackUseCase) FailStuckSlackJob(
+	ctx context.Context,
+	job *models.Job,
+	agentID string,
+	explanation string,
+) error {
+	log.Printf("📋 Starting to fail stuck messages of Slack job %s", job.ID)
+	if job.SlackPayload == nil {
+		log.Printf("❌ Job %s has no Slack payload", job.ID)
+		return fmt.Errorf("job has no Slack payload")
+	}
+	slackIntegrationID := job.SlackPayload.IntegrationID
+	orgID := job.OrgID
+
+	var failedMessages []*models.ProcessedSlackMessage
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if agentID != "" {
+			if err := s.agentsService.UnassignAgentFromJob(ctx, orgID, agentID, job.ID); err != nil {
+				log.Printf("❌ Failed to unassign agent %s from job %s: %v", agentID, job.ID, err)
+				return fmt.Errorf("failed to unassign agent from job: %w", err)
+			}
+			log.Printf("🔗 Unassigned agent %s from stuck job %s", agentID, job.ID)
+		}
+
+		messages, err := s.slackMessagesService.GetProcessedMessagesByJobIDAndStatus(
+			ctx,
+			orgID,
+			job.ID,
+			models.ProcessedSlackMessageStatusInProgress,
+			slackIntegrationID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get in-progress messages for job %s: %w", job.ID, err)
+		}
+
+		for _, message := range messages {
+			updatedMessage, err := s.slackMessagesService.UpdateProcessedSlackMessage(
+				ctx,
+				orgID,
+				message.ID,
+				models.ProcessedSlackMessageStatusFailed,
+				slackIntegrationID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to mark message %s as failed: %w", message.ID, err)
+			}
+			failedMessages = append(failedMessages, updatedMessage)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to fail stuck job %s in transaction: %w", job.ID, err)
+	}
+
+	for _, message := range failedMessages {
+		reactionEmoji := deriveMessageReactionFromStatus(message.Status)
+		if err := s.updateSlackMessageReaction(
+			ctx,
+			message.SlackChannelID,
+			message.SlackTS,
+			reactionEmoji,
+			slackIntegrationID,
+		); err != nil {
+			log.Printf("⚠️ Failed to update reaction for failed message %s: %v", message.ID, err)
+		}
+	}
+
+	if err := s.sendSystemMessage(
+		ctx,
+		slackIntegrationID,
+		job.SlackPayload.ChannelID,
+		job.SlackPayload.ThreadTS,
+		explanation,
+	); err != nil {
+		log.Printf("❌ Failed to send failure explanation to Slack thread %s: %v", job.SlackPayload.ThreadTS, err)
+		return fmt.Errorf("failed to send failure explanation to Slack: %w", err)
+	}
+	s.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorSystem,
+		"",
+		models.TranscriptMessageTypeSystemMessage,
+		explanation,
+		"",
+	)
+
+	log.Printf("📋 Completed successfully - failed %d stuck messages of Slack job %s", len(failedMessages), job.ID)
+	return nil
+}
+
+// ProcessJobCancelled confirms in the job's thread that the agent acknowledged the cancellation of the job
+func (s *SlackUseCase) ProcessJobCancelled(ctx context.Context, job *models.Job) error {
+	log.Printf("📋 Starting to confirm cancellation of Slack job %s", job.ID)
+	if job.SlackPayload == nil {
+		return fmt.Errorf("job has no Slack payload")
+	}
+
+	s.finishProgressStatus(ctx, job)
+	if err := s.confirmJobCancelled(ctx, job); err != nil {
+		return err
+	}
+
+	log.Printf("📋 Completed successfully - confirmed cancellation of Slack job %s", job.ID)
+	return nil
+}
+
+// ProcessJobCancellationUnconfirmed tells the job's thread that the agent never acknowledged the cancellation of
+// the job, so it may still be working on it
+func (s *SlackUseCase) ProcessJobCancellationUnconfirmed(ctx context.Context, job *models.Job) error {
+	log.Printf("📋 Starting to report unconfirmed cancellation of Slack job %s", job.ID)
+	if job.SlackPayload == nil {
+		return fmt.Errorf("job has no Slack payload")
+	}
+
+	s.finishProgressStatus(ctx, job)
+	if err := s.sendSystemMessage(
+		ctx,
+		job.SlackPayload.IntegrationID,
+		job.SlackPayload.ChannelID,
+		job.SlackPayload.ThreadTS,
+		"The stop could not be confirmed by the agent - it may still be working on this job",
+	); err != nil {
+		return fmt.Errorf("failed to send unconfirmed cancellation notice to Slack: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - reported unconfirmed cancellation of Slack job %s", job.ID)
+	return nil
+}
+
 // ProcessAssistantMessage handles assistant messages from agents and updates Slack accordingly
 func (s *SlackUseCase) ProcessAssistantMessage(
 	ctx context.Context,
@@ -693,10 +1339,32 @@ func (s *SlackUseCase) ProcessAssistantMessage(
 		log.Printf("⚠️ Agent sent empty response, using fallback message")
 	}
 
-	// Send assistant message to Slack
-	if err := s.sendSlackMessage(ctx, slackIntegrationID, job.SlackPayload.ChannelID, job.SlackPayload.ThreadTS, messageToSend); err != nil {
+	s.finishProgressStatus(ctx, job)
+
+	// Send assistant message to Slack - a streamed reply is replaced with the final message instead
+	if stream, ok := s.assistantStreams.Finish(ctx, payload.ProcessedMessageID); ok && stream.MessageID != "" {
+		err = s.updateSlackMessage(ctx, slackIntegrationID, job.SlackPayload.ChannelID, stream.MessageID, messageToSend)
+	} else {
+		err = s.sendSlackMessage(
+			ctx,
+			slackIntegrationID,
+			job.SlackPayload.ChannelID,
+			job.SlackPayload.ThreadTS,
+			messageToSend,
+		)
+	}
+	if err != nil {
 		return fmt.Errorf("❌ Failed to send assistant message to Slack: %v", err)
 	}
+	s.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorAgent,
+		agent.ID,
+		models.TranscriptMessageTypeAssistantMessage,
+		messageToSend,
+		payload.ProcessedMessageID,
+	)
 
 	// Update job timestamp to track activity
 	if err := s.jobsService.UpdateJobTimestamp(ctx, orgID, job.ID); err != nil {
@@ -755,6 +1423,156 @@ func (s *SlackUseCase) ProcessAssistantMessage(
 	return nil
 }
 
+// ProcessAssistantDelta streams a chunk of an assistant reply into the job's Slack thread
+// The reply is posted once and then edited in place, at most once per assistantStreamUpdateInterval per workspace.
+func (s *SlackUseCase) ProcessAssistantDelta(
+	ctx context.Context,
+	clientID string,
+	payload models.AssistantDeltaPayload,
+	orgID models.OrgID,
+) error {
+	if payload.ProcessedMessageID == "" {
+		return fmt.Errorf("ProcessedMessageID is empty in AssistantDelta payload")
+	}
+
+	maybeJob, _, err := s.getAgentJob(ctx, clientID, payload.JobID, orgID)
+	if err != nil {
+		return err
+	}
+	job, ok := maybeJob.Get()
+	if !ok {
+		log.Printf("⚠️ Job %s not found - already completed, skipping assistant delta", payload.JobID)
+		return nil
+	}
+
+	update := s.assistantStreams.Append(
+		payload.ProcessedMessageID,
+		job.SlackPayload.IntegrationID,
+		payload.Delta,
+		time.Now(),
+	)
+	if update.Action == streaming.UpdateActionNone {
+		return nil
+	}
+	if err := s.renderStreamUpdate(ctx, job, s.assistantStreams, payload.ProcessedMessageID, update); err != nil {
+		return fmt.Errorf("failed to render streamed assistant message in Slack: %w", err)
+	}
+
+	// Update job timestamp to track activity
+	if err := s.jobsService.UpdateJobTimestamp(ctx, orgID, job.ID); err != nil {
+		return fmt.Errorf("failed to update job timestamp: %w", err)
+	}
+
+	return nil
+}
+
+// ProcessProgressEvent shows a step the agent took in the job's status message
+// The status message is posted on the first step and then edited in place to show the latest steps,
+// at most once per assistantStreamUpdateInterval per workspace.
+// Every agent reply starts a new status message below it.
+func (s *SlackUseCase) ProcessProgressEvent(
+	ctx context.Context,
+	clientID string,
+	payload models.ProgressEventPayload,
+	orgID models.OrgID,
+) error {
+	maybeJob, _, err := s.getAgentJob(ctx, clientID, payload.JobID, orgID)
+	if err != nil {
+		return err
+	}
+	job, ok := maybeJob.Get()
+	if !ok {
+		log.Printf("⚠️ Job %s not found - already completed, skipping progress event", payload.JobID)
+		return nil
+	}
+
+	update := s.progressStatuses.AppendLine(
+		job.ID,
+		job.SlackPayload.IntegrationID,
+		payload.StatusLine(),
+		maxProgressStatusSteps,
+		time.Now(),
+	)
+	if update.Action == streaming.UpdateActionNone {
+		return nil
+	}
+	if err := s.renderStreamUpdate(ctx, job, s.progressStatuses, job.ID, update); err != nil {
+		return fmt.Errorf("failed to render progress status in Slack: %w", err)
+	}
+
+	// Update job timestamp to track activity
+	if err := s.jobsService.UpdateJobTimestamp(ctx, orgID, job.ID); err != nil {
+		return fmt.Errorf("failed to update job timestamp: %w", err)
+	}
+
+	return nil
+}
+
+// ProcessArtifact uploads a file the agent produced into the job's Slack thread
+func (s *SlackUseCase) ProcessArtifact(
+	ctx context.Context,
+	clientID string,
+	payload models.ArtifactPayload,
+	orgID models.OrgID,
+) error {
+	log.Printf("📋 Starting to process artifact %s from client %s", payload.Attachment.Name, clientID)
+	maybeJob, agent, err := s.getAgentJob(ctx, clientID, payload.JobID, orgID)
+	if err != nil {
+		return err
+	}
+	job, ok := maybeJob.Get()
+	if !ok {
+		log.Printf("⚠️ Job %s not found - already completed, skipping artifact", payload.JobID)
+		return nil
+	}
+
+	slackClient, err := s.getSlackClientForIntegration(ctx, job.SlackPayload.IntegrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get Slack client for integration: %w", err)
+	}
+	err = slackClient.UploadFile(clients.SlackUploadFileParams{
+		Channel:        job.SlackPayload.ChannelID,
+		ThreadTS:       job.SlackPayload.ThreadTS,
+		Filename:       payload.Attachment.Name,
+		Content:        payload.Attachment.Content,
+		InitialComment: payload.Comment,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload artifact to Slack: %w", err)
+	}
+	s.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorAgent,
+		agent.ID,
+		models.TranscriptMessageTypeArtifact,
+		payload.TranscriptContent(),
+		"",
+	)
+
+	// Update job timestamp to track activity
+	if err := s.jobsService.UpdateJobTimestamp(ctx, orgID, job.ID); err != nil {
+		return fmt.Errorf("failed to update job timestamp: %w", err)
+	}
+
+	log.Printf("📋 Completed successfully - uploaded artifact %s for job %s", payload.Attachment.Name, job.ID)
+	return nil
+}
+
+// DownloadAttachment downloads a file forwarded to an agent from the Slack workspace it was shared in
+func (s *SlackUseCase) DownloadAttachment(ctx context.Context, source models.AttachmentSource) ([]byte, error) {
+	slackClient, err := s.getSlackClientForIntegration(ctx, source.IntegrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Slack client for integration: %w", err)
+	}
+
+	content, err := slackClient.DownloadFile(source.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download Slack file: %w", err)
+	}
+	return content, nil
+}
+
 // ProcessSystemMessage handles system messages from agents and sends them to Slack
 func (s *SlackUseCase) ProcessSystemMessage(
 	ctx context.Context,
@@ -783,22 +1601,21 @@ func (s *SlackUseCase) ProcessSystemMessage(
 	}
 	slackIntegrationID := job.SlackPayload.IntegrationID
 
+	// Get the agent which sent the message
+	maybeAgent, err := s.agentsService.GetAgentByWSConnectionID(ctx, orgID, clientID)
+	if err != nil {
+		log.Printf("❌ Failed to find agent for client %s: %v", clientID, err)
+		return fmt.Errorf("failed to find agent for client: %w", err)
+	}
+	var agentID string
+	if maybeAgent.IsPresent() {
+		agentID = maybeAgent.MustGet().ID
+	}
+
 	// Check if this is an error message from the agent
 	if isAgentErrorMessage(payload.Message) {
 		log.Printf("❌ Detected agent error message for job %s: %s", job.ID, payload.Message)
 
-		// Get the agent that encountered the error
-		maybeAgent, err := s.agentsService.GetAgentByWSConnectionID(ctx, orgID, clientID)
-		if err != nil {
-			log.Printf("❌ Failed to find agent for error handling: %v", err)
-			return fmt.Errorf("failed to find agent for error handling: %w", err)
-		}
-
-		var agentID string
-		if maybeAgent.IsPresent() {
-			agentID = maybeAgent.MustGet().ID
-		}
-
 		// Clean up the failed job
 		errorMessage := fmt.Sprintf(":x: Agent encountered an error and cannot continue:\n%s", payload.Message)
 		if err := s.CleanupFailedSlackJob(ctx, job, agentID, errorMessage); err != nil {
@@ -819,6 +1636,15 @@ func (s *SlackUseCase) ProcessSystemMessage(
 	if err := s.sendSystemMessage(ctx, slackIntegrationID, job.SlackPayload.ChannelID, job.SlackPayload.ThreadTS, payload.Message); err != nil {
 		return fmt.Errorf("❌ Failed to send system message to Slack: %v", err)
 	}
+	s.recordTranscriptMessage(
+		ctx,
+		job,
+		models.TranscriptAuthorAgent,
+		agentID,
+		models.TranscriptMessageTypeSystemMessage,
+		payload.Message,
+		payload.ProcessedMessageID,
+	)
 
 	// Update job timestamp to track activity
 	if err := s.jobsService.UpdateJobTimestamp(ctx, orgID, job.ID); err != nil {
@@ -2,17 +2,21 @@ package slack
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/samber/mo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"ccbackend/clients"
 	slackclient "ccbackend/clients/slack"
 	"ccbackend/clients/socketio"
 	"ccbackend/models"
 	agentsservice "ccbackend/services/agents"
+	"ccbackend/services/connectedchannels"
 	"ccbackend/services/jobs"
 	slackintegrations "ccbackend/services/slack_integrations"
 	"ccbackend/services/slackmessages"
@@ -35,6 +39,7 @@ type slackUseCaseMocks struct {
 	jobsService              *jobs.MockJobsService
 	slackMessagesService     *slackmessages.MockSlackMessagesService
 	slackIntegrationsService *slackintegrations.MockSlackIntegrationsService
+	connectedChannelsService *connectedchannels.MockConnectedChannelsService
 	txManager                *txmanager.MockTransactionManager
 	agentsUseCase            *agentsusecase.MockAgentsUseCase
 	slackClient              *slackclient.MockSlackClient
@@ -48,6 +53,7 @@ func setupSlackUseCaseTest(t *testing.T) *slackUseCaseTestFixture {
 		jobsService:              new(jobs.MockJobsService),
 		slackMessagesService:     new(slackmessages.MockSlackMessagesService),
 		slackIntegrationsService: new(slackintegrations.MockSlackIntegrationsService),
+		connectedChannelsService: new(connectedchannels.MockConnectedChannelsService),
 		txManager:                new(txmanager.MockTransactionManager),
 		agentsUseCase:            new(agentsusecase.MockAgentsUseCase),
 		slackClient:              new(slackclient.MockSlackClient),
@@ -64,6 +70,7 @@ func setupSlackUseCaseTest(t *testing.T) *slackUseCaseTestFixture {
 		mocks.jobsService,
 		mocks.slackMessagesService,
 		mocks.slackIntegrationsService,
+		mocks.connectedChannelsService,
 		mocks.txManager,
 		mocks.agentsUseCase,
 		mockClientFactory,
@@ -91,7 +98,6 @@ func TestProcessSlackMessageEvent(t *testing.T) {
 		testSlackToken := testutils.GenerateSlackToken()
 		testWSConnectionID := testutils.GenerateWSConnectionID()
 		testProcessedID := testutils.GenerateProcessedMessageID()
-		testAgentID := testutils.GenerateAgentID()
 
 		event := models.SlackMessageEvent{
 			User:     testUserID,
@@ -123,12 +129,6 @@ func TestProcessSlackMessageEvent(t *testing.T) {
 			SlackAuthToken: testSlackToken,
 		}
 
-		connectedAgent := &models.ActiveAgent{
-			ID:             testAgentID,
-			WSConnectionID: testWSConnectionID,
-			OrgID:          testOrgID,
-		}
-
 		processedMessage := &models.ProcessedSlackMessage{
 			ID:                 testProcessedID,
 			JobID:              testJobID,
@@ -145,13 +145,16 @@ func TestProcessSlackMessageEvent(t *testing.T) {
 			Return(jobResult, nil)
 		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
 			Return(mo.Some(slackIntegration), nil)
-		fixture.mocks.wsClient.On("GetClientIDs").Return([]string{testWSConnectionID})
-		fixture.mocks.agentsService.On("GetConnectedActiveAgents", fixture.ctx, testOrgID, []string{testWSConnectionID}).
-			Return([]*models.ActiveAgent{connectedAgent}, nil)
-		fixture.mocks.agentsUseCase.On("GetOrAssignAgentForJob", fixture.ctx, jobResult.Job, testThreadTS, testOrgID).
-			Return(testWSConnectionID, nil)
-		fixture.mocks.slackMessagesService.On("CreateProcessedSlackMessage", fixture.ctx, testOrgID, testJobID, testChannelID, testThreadTS, "Hello bot, help me with something", testSlackIntegrationID, models.ProcessedSlackMessageStatusInProgress).
+		fixture.mocks.connectedChannelsService.On("GetSlackConnectedChannel", fixture.ctx, testOrgID, slackIntegration.SlackTeamID, testChannelID).
+			Return(mo.None[*models.SlackConnectedChannel](), nil)
+		fixture.mocks.jobsService.On("HasQueuedJobsAbovePriority", fixture.ctx, testOrgID, models.JobTypeSlack, models.JobPriorityNormal).
+			Return(false, nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, testJobID, "", models.AgentLabels(nil), testOrgID).
+			Return(testWSConnectionID, true, nil)
+		fixture.mocks.slackMessagesService.On("CreateProcessedSlackMessage", fixture.ctx, testOrgID, testJobID, testChannelID, testThreadTS, "Hello bot, help me with something", testSlackIntegrationID, models.ProcessedSlackMessageStatusInProgress, models.Attachments(nil)).
 			Return(processedMessage, nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorUser, testUserID, models.TranscriptMessageTypeUserMessage, "Hello bot, help me with something", testProcessedID).
+			Return(&models.JobTranscriptMessage{}, nil)
 
 		// Mock Slack client expectations for updating reaction
 		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
@@ -179,8 +182,15 @@ func TestProcessSlackMessageEvent(t *testing.T) {
 		fixture.mocks.slackClient.MockResolveMentionsInMessage = func(ctx context.Context, message string) string {
 			return message // Return unchanged for simplicity
 		}
-		fixture.mocks.wsClient.On("SendMessage", testWSConnectionID, mock.AnythingOfType("models.BaseMessage")).
-			Return(nil)
+		fixture.mocks.agentsService.On(
+			"SendMessageToAgent",
+			fixture.ctx,
+			testOrgID,
+			testWSConnectionID,
+			mock.Anything,
+			mock.Anything,
+			mock.AnythingOfType("models.BaseMessage"),
+		).Return(nil)
 
 		// Execute
 		err := fixture.useCase.ProcessSlackMessageEvent(fixture.ctx, event, testSlackIntegrationID, testOrgID)
@@ -195,24 +205,23 @@ func TestProcessSlackMessageEvent(t *testing.T) {
 		fixture.mocks.slackMessagesService.AssertExpectations(t)
 	})
 
-	t.Run("slack_integration_not_found", func(t *testing.T) {
+	t.Run("urgent_keyword_is_stripped_and_only_the_org_queue_is_dispatched", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
-		// Generate consistent test data for this test case
 		testJobID := testutils.GenerateJobID()
 		testUserID := testutils.GenerateSlackUserID()
 		testOrgID := testutils.GenerateOrgID()
 		testChannelID := testutils.GenerateSlackChannelID()
 		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
 		testThreadTS := testutils.GenerateSlackThreadTS()
+		testProcessedID := testutils.GenerateProcessedMessageID()
 
 		event := models.SlackMessageEvent{
-			User:     testUserID,
-			Channel:  testChannelID,
-			Text:     "Hello bot",
-			TS:       testThreadTS,
-			ThreadTS: "",
+			User:    testUserID,
+			Channel: testChannelID,
+			Text:    "<@B123456789> !urgent prod is down",
+			TS:      testThreadTS,
 		}
 
 		job := &models.Job{
@@ -225,31 +234,71 @@ func TestProcessSlackMessageEvent(t *testing.T) {
 				UserID:        testUserID,
 			},
 		}
-
-		jobResult := &models.JobCreationResult{
-			Job:    job,
-			Status: models.JobCreationStatusCreated,
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+		processedMessage := &models.ProcessedSlackMessage{
+			ID:                 testProcessedID,
+			JobID:              testJobID,
+			SlackTS:            testThreadTS,
+			SlackChannelID:     testChannelID,
+			TextContent:        "<@B123456789> prod is down",
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusQueued,
 		}
 
 		// Configure expectations
 		fixture.mocks.jobsService.On("GetOrCreateJobForSlackThread", fixture.ctx, testOrgID, event.TS, event.Channel, event.User, testSlackIntegrationID).
-			Return(jobResult, nil)
+			Return(&models.JobCreationResult{Job: job, Status: models.JobCreationStatusCreated}, nil)
 		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
-			Return(mo.None[*models.SlackIntegration](), nil)
+			Return(mo.Some(slackIntegration), nil)
+		fixture.mocks.connectedChannelsService.On("GetSlackConnectedChannel", fixture.ctx, testOrgID, slackIntegration.SlackTeamID, testChannelID).
+			Return(mo.None[*models.SlackConnectedChannel](), nil)
+		fixture.mocks.jobsService.On("RaiseJobPriority", fixture.ctx, testOrgID, testJobID, models.JobPriorityUrgent).
+			Return(true, nil)
+		fixture.mocks.jobsService.On("HasQueuedJobsAbovePriority", fixture.ctx, testOrgID, models.JobTypeSlack, models.JobPriorityUrgent).
+			Return(true, nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationsByOrganizationID", fixture.ctx, testOrgID).
+			Return([]models.SlackIntegration{}, nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, testJobID, "", models.AgentLabels(nil), testOrgID).
+			Return("", false, nil)
+		fixture.mocks.slackMessagesService.On("CreateProcessedSlackMessage", fixture.ctx, testOrgID, testJobID, testChannelID, testThreadTS, "<@B123456789> prod is down", testSlackIntegrationID, models.ProcessedSlackMessageStatusQueued, models.Attachments(nil)).
+			Return(processedMessage, nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorUser, testUserID, models.TranscriptMessageTypeUserMessage, "<@B123456789> prod is down", testProcessedID).
+			Return(&models.JobTranscriptMessage{}, nil)
+
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: "1234567890.123456"}, nil
+		}
+		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockGetReactions = func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error) {
+			return []clients.SlackItemReaction{}, nil
+		}
+		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
+			return &clients.SlackAuthTestResponse{UserID: "B123456789", TeamID: testSlackIntegrationID}, nil
+		}
 
 		// Execute
 		err := fixture.useCase.ProcessSlackMessageEvent(fixture.ctx, event, testSlackIntegrationID, testOrgID)
 
 		// Assert
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "slack integration not found")
+		require.NoError(t, err)
 		fixture.mocks.jobsService.AssertExpectations(t)
 		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
+		fixture.mocks.slackIntegrationsService.AssertNotCalled(t, "GetAllSlackIntegrations", mock.Anything)
+		fixture.mocks.slackMessagesService.AssertExpectations(t)
+		fixture.mocks.agentsUseCase.AssertExpectations(t)
 	})
-}
 
-func TestProcessReactionAdded(t *testing.T) {
-	t.Run("success_valid_completion_reaction", func(t *testing.T) {
+	t.Run("forwards_attachments_within_limits_and_notifies_about_skipped_ones", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
@@ -260,10 +309,28 @@ func TestProcessReactionAdded(t *testing.T) {
 		testChannelID := testutils.GenerateSlackChannelID()
 		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
 		testThreadTS := testutils.GenerateSlackThreadTS()
-		testAgentID := testutils.GenerateAgentID()
 		testWSConnectionID := testutils.GenerateWSConnectionID()
+		testProcessedID := testutils.GenerateProcessedMessageID()
 
-		reactionName := "white_check_mark"
+		screenshot := models.Attachment{
+			Name:     "screenshot.png",
+			MimeType: "image/png",
+			Size:     1024,
+			URL:      "https://files.slack.com/screenshot.png",
+		}
+		coreDump := models.Attachment{
+			Name:     "core.dump",
+			MimeType: "application/octet-stream",
+			Size:     models.MaxAttachmentSize + 1,
+			URL:      "https://files.slack.com/core.dump",
+		}
+		event := models.SlackMessageEvent{
+			User:        testUserID,
+			Channel:     testChannelID,
+			Text:        "Why does the page look broken?",
+			TS:          testThreadTS,
+			Attachments: models.Attachments{screenshot, coreDump},
+		}
 
 		job := &models.Job{
 			ID:    testJobID,
@@ -275,79 +342,106 @@ func TestProcessReactionAdded(t *testing.T) {
 				UserID:        testUserID,
 			},
 		}
-
 		slackIntegration := &models.SlackIntegration{
 			ID:             testSlackIntegrationID,
 			OrgID:          testOrgID,
 			SlackAuthToken: testutils.GenerateSlackToken(),
 		}
-
-		agent := &models.ActiveAgent{
-			ID:             testAgentID,
-			WSConnectionID: testWSConnectionID,
-			OrgID:          testOrgID,
+		processedMessage := &models.ProcessedSlackMessage{
+			ID:                 testProcessedID,
+			JobID:              testJobID,
+			SlackTS:            testThreadTS,
+			SlackChannelID:     testChannelID,
+			TextContent:        event.Text,
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusInProgress,
+			Attachments:        models.Attachments{screenshot},
 		}
 
-		// Configure expectations
-		fixture.mocks.jobsService.On("GetJobBySlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testSlackIntegrationID).
-			Return(mo.Some(job), nil)
+		// Configure expectations - only the screenshot is stored with the message
+		fixture.mocks.jobsService.On("GetOrCreateJobForSlackThread", fixture.ctx, testOrgID, event.TS, event.Channel, event.User, testSlackIntegrationID).
+			Return(&models.JobCreationResult{Job: job, Status: models.JobCreationStatusCreated}, nil)
 		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
 			Return(mo.Some(slackIntegration), nil)
-		fixture.mocks.agentsService.On("GetAgentByJobID", fixture.ctx, testOrgID, testJobID).
-			Return(mo.Some(agent), nil)
-
-		// Transaction expectations
-		fixture.mocks.txManager.On("WithTransaction", fixture.ctx, mock.AnythingOfType("func(context.Context) error")).
-			Run(func(args mock.Arguments) {
-				// Execute the transaction function
-				txFunc := args.Get(1).(func(context.Context) error)
-				txFunc(fixture.ctx) // Execute with same context for simplicity
-			}).Return(nil)
-		fixture.mocks.agentsService.On("UnassignAgentFromJob", fixture.ctx, testOrgID, testAgentID, testJobID).
-			Return(nil)
-		fixture.mocks.jobsService.On("DeleteJob", fixture.ctx, testOrgID, testJobID).Return(nil)
+		fixture.mocks.connectedChannelsService.On("GetSlackConnectedChannel", fixture.ctx, testOrgID, slackIntegration.SlackTeamID, testChannelID).
+			Return(mo.None[*models.SlackConnectedChannel](), nil)
+		fixture.mocks.jobsService.On("HasQueuedJobsAbovePriority", fixture.ctx, testOrgID, models.JobTypeSlack, models.JobPriorityNormal).
+			Return(false, nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, testJobID, "", models.AgentLabels(nil), testOrgID).
+			Return(testWSConnectionID, true, nil)
+		fixture.mocks.slackMessagesService.On("CreateProcessedSlackMessage", fixture.ctx, testOrgID, testJobID, testChannelID, testThreadTS, event.Text, testSlackIntegrationID, models.ProcessedSlackMessageStatusInProgress, models.Attachments{screenshot}).
+			Return(processedMessage, nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorUser, testUserID, models.TranscriptMessageTypeUserMessage, event.Text, mock.Anything).
+			Return(&models.JobTranscriptMessage{}, nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(job), nil)
 
-		// Mock Slack client for sending system message
+		var postedTexts []string
 		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
-			return &clients.SlackPostMessageResponse{
-				Channel:   channelID,
-				Timestamp: "1234567890.123456",
-			}, nil
+			postedTexts = append(postedTexts, params.Text)
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: testThreadTS}, nil
 		}
-
-		// Execute
-		err := fixture.useCase.ProcessReactionAdded(
+		var downloadedURLs []string
+		fixture.mocks.slackClient.MockDownloadFile = func(url string) ([]byte, error) {
+			downloadedURLs = append(downloadedURLs, url)
+			return []byte("png bytes"), nil
+		}
+		fixture.mocks.agentsService.On(
+			"SendMessageToAgent",
 			fixture.ctx,
-			reactionName,
-			testUserID,
-			testChannelID,
-			testThreadTS,
-			testSlackIntegrationID,
 			testOrgID,
-		)
+			testWSConnectionID,
+			testJobID,
+			testProcessedID,
+			mock.MatchedBy(func(msg models.BaseMessage) bool {
+				// Only the source is sent to the outbox, the file is downloaded when the message is emitted
+				payload, ok := msg.Payload.(models.StartConversationPayload)
+				return ok && len(payload.Attachments) == 1 &&
+					payload.Attachments[0].Name == "screenshot.png" &&
+					payload.Attachments[0].Content == nil &&
+					payload.Attachments[0].Source != nil &&
+					payload.Attachments[0].Source.URL == screenshot.URL &&
+					payload.Attachments[0].Source.IntegrationID == testSlackIntegrationID
+			}),
+		).Return(nil)
+
+		// Execute
+		err := fixture.useCase.ProcessSlackMessageEvent(fixture.ctx, event, testSlackIntegrationID, testOrgID)
 
 		// Assert
-		assert.NoError(t, err)
-		fixture.mocks.jobsService.AssertExpectations(t)
-		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
+		require.NoError(t, err)
+		assert.Empty(t, downloadedURLs)
+		require.Len(t, postedTexts, 1)
+		assert.Contains(t, postedTexts[0], "`core.dump`")
+		assert.NotContains(t, postedTexts[0], "screenshot.png")
+		fixture.mocks.slackMessagesService.AssertExpectations(t)
 		fixture.mocks.agentsService.AssertExpectations(t)
-		fixture.mocks.txManager.AssertExpectations(t)
 	})
 
-	t.Run("ignore_reaction_by_different_user", func(t *testing.T) {
+	t.Run("no_agent_for_channel_repo_queues_and_notifies", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
 		// Generate consistent test data for this test case
 		testJobID := testutils.GenerateJobID()
 		testUserID := testutils.GenerateSlackUserID()
-		testDifferentUserID := testutils.GenerateSlackUserID() // Different user
 		testOrgID := testutils.GenerateOrgID()
 		testChannelID := testutils.GenerateSlackChannelID()
 		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
 		testThreadTS := testutils.GenerateSlackThreadTS()
+		testSlackToken := testutils.GenerateSlackToken()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+		testTeamID := "T0123456789"
+		testRepoURL := "github.com/acme/backend"
 
-		reactionName := "white_check_mark"
+		event := models.SlackMessageEvent{
+			User:     testUserID,
+			Channel:  testChannelID,
+			Text:     "Hello bot, fix the API",
+			TS:       testThreadTS,
+			ThreadTS: "",
+		}
 
 		job := &models.Job{
 			ID:    testJobID,
@@ -356,78 +450,106 @@ func TestProcessReactionAdded(t *testing.T) {
 				IntegrationID: testSlackIntegrationID,
 				ChannelID:     testChannelID,
 				ThreadTS:      testThreadTS,
-				UserID:        testUserID, // Original job creator
+				UserID:        testUserID,
 			},
 		}
 
-		// Configure expectations
-		fixture.mocks.jobsService.On("GetJobBySlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testSlackIntegrationID).
-			Return(mo.Some(job), nil)
-
-		// Execute
-		err := fixture.useCase.ProcessReactionAdded(
-			fixture.ctx,
-			reactionName,
-			testDifferentUserID,
-			testChannelID,
-			testThreadTS,
-			testSlackIntegrationID,
-			testOrgID,
-		)
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackTeamID:    testTeamID,
+			SlackAuthToken: testSlackToken,
+		}
 
-		// Assert
-		assert.NoError(t, err)
-		fixture.mocks.jobsService.AssertExpectations(t)
-	})
+		connectedChannel := &models.SlackConnectedChannel{
+			OrgID:          testOrgID,
+			TeamID:         testTeamID,
+			ChannelID:      testChannelID,
+			DefaultRepoURL: &testRepoURL,
+		}
 
-	t.Run("ignore_non_completion_reaction", func(t *testing.T) {
-		// Setup
-		fixture := setupSlackUseCaseTest(t)
+		processedMessage := &models.ProcessedSlackMessage{
+			ID:                 testProcessedID,
+			JobID:              testJobID,
+			SlackTS:            testThreadTS,
+			SlackChannelID:     testChannelID,
+			TextContent:        "Hello bot, fix the API",
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusQueued,
+		}
 
-		// Generate consistent test data
-		testUserID := testutils.GenerateSlackUserID()
-		testOrgID := testutils.GenerateOrgID()
-		testChannelID := testutils.GenerateSlackChannelID()
-		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
-		testThreadTS := testutils.GenerateSlackThreadTS()
+		// Configure expectations
+		fixture.mocks.jobsService.On("GetOrCreateJobForSlackThread", fixture.ctx, testOrgID, event.TS, event.Channel, event.User, testSlackIntegrationID).
+			Return(&models.JobCreationResult{Job: job, Status: models.JobCreationStatusCreated}, nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+		fixture.mocks.connectedChannelsService.On("GetSlackConnectedChannel", fixture.ctx, testOrgID, testTeamID, testChannelID).
+			Return(mo.Some(connectedChannel), nil)
+		fixture.mocks.jobsService.On("HasQueuedJobsAbovePriority", fixture.ctx, testOrgID, models.JobTypeSlack, models.JobPriorityNormal).
+			Return(false, nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, testJobID, testRepoURL, models.AgentLabels(nil), testOrgID).
+			Return("", false, nil)
+		fixture.mocks.slackMessagesService.On("CreateProcessedSlackMessage", fixture.ctx, testOrgID, testJobID, testChannelID, testThreadTS, "Hello bot, fix the API", testSlackIntegrationID, models.ProcessedSlackMessageStatusQueued, models.Attachments(nil)).
+			Return(processedMessage, nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorUser, testUserID, models.TranscriptMessageTypeUserMessage, event.Text, mock.Anything).
+			Return(&models.JobTranscriptMessage{}, nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{processedMessage}, nil)
 
-		reactionName := "thumbsup" // Not a completion emoji
+		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockGetReactions = func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error) {
+			return []clients.SlackItemReaction{}, nil
+		}
+		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
+			return &clients.SlackAuthTestResponse{UserID: "B123456789", TeamID: testTeamID}, nil
+		}
+		var postedMessages []string
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			postedMessages = append(postedMessages, params.Text)
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: "1234567890.123456"}, nil
+		}
 
 		// Execute
-		err := fixture.useCase.ProcessReactionAdded(
-			fixture.ctx,
-			reactionName,
-			testUserID,
-			testChannelID,
-			testThreadTS,
-			testSlackIntegrationID,
-			testOrgID,
-		)
+		err := fixture.useCase.ProcessSlackMessageEvent(fixture.ctx, event, testSlackIntegrationID, testOrgID)
 
-		// Assert - should return without error and no mocks called
+		// Assert
 		assert.NoError(t, err)
+		require.Len(t, postedMessages, 1)
+		assert.Contains(t, postedMessages[0], testRepoURL)
+		fixture.mocks.connectedChannelsService.AssertExpectations(t)
+		fixture.mocks.agentsUseCase.AssertExpectations(t)
+		fixture.mocks.slackMessagesService.AssertExpectations(t)
+		fixture.mocks.agentsService.AssertNotCalled(t, "SendMessageToAgent")
 	})
-}
 
-func TestProcessJobComplete(t *testing.T) {
-	t.Run("success_agent_completes_job", func(t *testing.T) {
+	t.Run("message_queued_behind_earlier_ones_does_not_repeat_notice", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
 		// Generate consistent test data for this test case
 		testJobID := testutils.GenerateJobID()
+		testUserID := testutils.GenerateSlackUserID()
 		testOrgID := testutils.GenerateOrgID()
-		testWSConnectionID := testutils.GenerateWSConnectionID()
-		testAgentID := testutils.GenerateAgentID()
 		testChannelID := testutils.GenerateSlackChannelID()
-		testThreadTS := testutils.GenerateSlackThreadTS()
 		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
-		testUserID := testutils.GenerateSlackUserID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testSlackToken := testutils.GenerateSlackToken()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+		testTeamID := "T0123456789"
+		testRepoURL := "github.com/acme/backend"
 
-		clientID := testWSConnectionID
-		payload := models.JobCompletePayload{
-			JobID:  testJobID,
-			Reason: "Task completed successfully",
+		event := models.SlackMessageEvent{
+			User:     testUserID,
+			Channel:  testChannelID,
+			Text:     "Hello bot, fix the API",
+			TS:       testThreadTS,
+			ThreadTS: "",
 		}
 
 		job := &models.Job{
@@ -441,27 +563,1784 @@ func TestProcessJobComplete(t *testing.T) {
 			},
 		}
 
-		agent := &models.ActiveAgent{
-			ID:             testAgentID,
-			WSConnectionID: testWSConnectionID,
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
 			OrgID:          testOrgID,
+			SlackTeamID:    testTeamID,
+			SlackAuthToken: testSlackToken,
 		}
 
-		slackIntegration := &models.SlackIntegration{
-			ID:             testSlackIntegrationID,
+		connectedChannel := &models.SlackConnectedChannel{
 			OrgID:          testOrgID,
-			SlackAuthToken: testutils.GenerateSlackToken(),
+			TeamID:         testTeamID,
+			ChannelID:      testChannelID,
+			DefaultRepoURL: &testRepoURL,
+		}
+
+		processedMessage := &models.ProcessedSlackMessage{
+			ID:                 testProcessedID,
+			JobID:              testJobID,
+			SlackTS:            testThreadTS,
+			SlackChannelID:     testChannelID,
+			TextContent:        "Hello bot, fix the API",
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusQueued,
 		}
 
 		// Configure expectations
-		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, payload.JobID).
-			Return(mo.Some(job), nil)
-		fixture.mocks.agentsService.On("GetAgentByWSConnectionID", fixture.ctx, testOrgID, clientID).
+		fixture.mocks.jobsService.On("GetOrCreateJobForSlackThread", fixture.ctx, testOrgID, event.TS, event.Channel, event.User, testSlackIntegrationID).
+			Return(&models.JobCreationResult{Job: job, Status: models.JobCreationStatusCreated}, nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+		fixture.mocks.connectedChannelsService.On("GetSlackConnectedChannel", fixture.ctx, testOrgID, testTeamID, testChannelID).
+			Return(mo.Some(connectedChannel), nil)
+		fixture.mocks.jobsService.On("HasQueuedJobsAbovePriority", fixture.ctx, testOrgID, models.JobTypeSlack, models.JobPriorityNormal).
+			Return(false, nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, testJobID, testRepoURL, models.AgentLabels(nil), testOrgID).
+			Return("", false, nil)
+		fixture.mocks.slackMessagesService.On("CreateProcessedSlackMessage", fixture.ctx, testOrgID, testJobID, testChannelID, testThreadTS, "Hello bot, fix the API", testSlackIntegrationID, models.ProcessedSlackMessageStatusQueued, models.Attachments(nil)).
+			Return(processedMessage, nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorUser, testUserID, models.TranscriptMessageTypeUserMessage, event.Text, mock.Anything).
+			Return(&models.JobTranscriptMessage{}, nil)
+		// An earlier message of the job is still waiting, so the thread was told already
+		earlierMessage := &models.ProcessedSlackMessage{ID: testutils.GenerateProcessedMessageID(), JobID: testJobID}
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{earlierMessage, processedMessage}, nil)
+
+		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockGetReactions = func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error) {
+			return []clients.SlackItemReaction{}, nil
+		}
+		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
+			return &clients.SlackAuthTestResponse{UserID: "B123456789", TeamID: testTeamID}, nil
+		}
+		var postedMessages []string
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			postedMessages = append(postedMessages, params.Text)
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: "1234567890.123456"}, nil
+		}
+
+		// Execute
+		err := fixture.useCase.ProcessSlackMessageEvent(fixture.ctx, event, testSlackIntegrationID, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, postedMessages)
+		fixture.mocks.connectedChannelsService.AssertExpectations(t)
+		fixture.mocks.agentsUseCase.AssertExpectations(t)
+		fixture.mocks.slackMessagesService.AssertExpectations(t)
+		fixture.mocks.agentsService.AssertNotCalled(t, "SendMessageToAgent")
+	})
+
+	t.Run("reply_after_handover_with_nothing_queued_starts_conversation_with_history", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testUserID := testutils.GenerateSlackUserID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testReplyTS := testutils.GenerateSlackThreadTS()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+		testFirstProcessedID := testutils.GenerateProcessedMessageID()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+
+		// A reply in the thread of a job which was requeued after its agent disconnected, with every earlier
+		// message already completed
+		event := models.SlackMessageEvent{
+			User:     testUserID,
+			Channel:  testChannelID,
+			Text:     "Now add a test",
+			TS:       testReplyTS,
+			ThreadTS: testThreadTS,
+		}
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID,
+			},
+		}
+
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+
+		processedMessage := &models.ProcessedSlackMessage{
+			ID:                 testProcessedID,
+			JobID:              testJobID,
+			SlackTS:            testReplyTS,
+			SlackChannelID:     testChannelID,
+			TextContent:        "Now add a test",
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusInProgress,
+		}
+
+		transcript := []*models.JobTranscriptMessage{
+			{
+				Author:             models.TranscriptAuthorUser,
+				MessageType:        models.TranscriptMessageTypeUserMessage,
+				Content:            "Fix the bug",
+				ProcessedMessageID: testFirstProcessedID,
+			},
+			{
+				Author:             models.TranscriptAuthorAgent,
+				MessageType:        models.TranscriptMessageTypeAssistantMessage,
+				Content:            "Fixed it",
+				ProcessedMessageID: testFirstProcessedID,
+			},
+			{
+				Author:             models.TranscriptAuthorUser,
+				MessageType:        models.TranscriptMessageTypeUserMessage,
+				Content:            "Now add a test",
+				ProcessedMessageID: testProcessedID,
+			},
+		}
+
+		// Configure expectations
+		fixture.mocks.jobsService.On("GetJobBySlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testSlackIntegrationID).
+			Return(mo.Some(job), nil)
+		fixture.mocks.jobsService.On("GetOrCreateJobForSlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testUserID, testSlackIntegrationID).
+			Return(&models.JobCreationResult{Job: job, Status: models.JobCreationStatusNA}, nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+		fixture.mocks.connectedChannelsService.On("GetSlackConnectedChannel", fixture.ctx, testOrgID, slackIntegration.SlackTeamID, testChannelID).
+			Return(mo.None[*models.SlackConnectedChannel](), nil)
+		fixture.mocks.agentsService.On("GetAgentByJobID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, testJobID, "", models.AgentLabels(nil), testOrgID).
+			Return(testWSConnectionID, true, nil)
+		fixture.mocks.slackMessagesService.On("CreateProcessedSlackMessage", fixture.ctx, testOrgID, testJobID, testChannelID, testReplyTS, "Now add a test", testSlackIntegrationID, models.ProcessedSlackMessageStatusInProgress, models.Attachments(nil)).
+			Return(processedMessage, nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorUser, testUserID, models.TranscriptMessageTypeUserMessage, "Now add a test", testProcessedID).
+			Return(&models.JobTranscriptMessage{}, nil)
+		fixture.mocks.jobsService.On("GetJobTranscript", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(transcript), nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(job), nil)
+
+		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockGetReactions = func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error) {
+			return []clients.SlackItemReaction{}, nil
+		}
+		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
+			return &clients.SlackAuthTestResponse{UserID: "B123456789"}, nil
+		}
+		fixture.mocks.slackClient.MockGetPermalink = func(params *clients.SlackPermalinkParameters) (string, error) {
+			return "https://workspace.slack.com/archives/" + params.Channel + "/p" + params.TS, nil
+		}
+		fixture.mocks.slackClient.MockResolveMentionsInMessage = func(ctx context.Context, message string) string {
+			return message
+		}
+
+		var sentMessage models.BaseMessage
+		fixture.mocks.agentsService.On("SendMessageToAgent", fixture.ctx, testOrgID, testWSConnectionID, testJobID, testProcessedID, mock.AnythingOfType("models.BaseMessage")).
+			Run(func(args mock.Arguments) {
+				sentMessage = args.Get(5).(models.BaseMessage)
+			}).
+			Return(nil)
+
+		// Execute
+		err := fixture.useCase.ProcessSlackMessageEvent(fixture.ctx, event, testSlackIntegrationID, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, models.MessageTypeStartConversation, sentMessage.Type)
+		payload, ok := sentMessage.Payload.(models.StartConversationPayload)
+		require.True(t, ok)
+		assert.Equal(t, "Now add a test", payload.Message)
+		assert.Equal(t, []models.ConversationTurn{
+			{Author: models.ConversationAuthorUser, Message: "Fix the bug"},
+			{Author: models.ConversationAuthorAssistant, Message: "Fixed it"},
+		}, payload.History)
+		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.agentsService.AssertExpectations(t)
+		fixture.mocks.agentsUseCase.AssertExpectations(t)
+	})
+
+	t.Run("slack_integration_not_found", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testUserID := testutils.GenerateSlackUserID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+
+		event := models.SlackMessageEvent{
+			User:     testUserID,
+			Channel:  testChannelID,
+			Text:     "Hello bot",
+			TS:       testThreadTS,
+			ThreadTS: "",
+		}
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID,
+			},
+		}
+
+		jobResult := &models.JobCreationResult{
+			Job:    job,
+			Status: models.JobCreationStatusCreated,
+		}
+
+		// Configure expectations
+		fixture.mocks.jobsService.On("GetOrCreateJobForSlackThread", fixture.ctx, testOrgID, event.TS, event.Channel, event.User, testSlackIntegrationID).
+			Return(jobResult, nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.None[*models.SlackIntegration](), nil)
+
+		// Execute
+		err := fixture.useCase.ProcessSlackMessageEvent(fixture.ctx, event, testSlackIntegrationID, testOrgID)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "slack integration not found")
+		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
+	})
+
+	t.Run("thread_reply_after_job_closed_starts_new_job_in_thread", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		testJobID := testutils.GenerateJobID()
+		testUserID := testutils.GenerateSlackUserID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+
+		event := models.SlackMessageEvent{
+			User:     testUserID,
+			Channel:  testChannelID,
+			Text:     "One more thing",
+			TS:       testutils.GenerateSlackThreadTS(),
+			ThreadTS: testThreadTS,
+		}
+
+		job := &models.Job{
+			ID:     testJobID,
+			OrgID:  testOrgID,
+			Status: models.JobStatusActive,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID,
+			},
+		}
+
+		// The thread has no active job anymore, only a closed one
+		fixture.mocks.jobsService.On("GetJobBySlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testSlackIntegrationID).
+			Return(mo.None[*models.Job](), nil)
+		fixture.mocks.jobsService.On("HasClosedJobForSlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testSlackIntegrationID).
+			Return(true, nil)
+		fixture.mocks.jobsService.On("GetOrCreateJobForSlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testUserID, testSlackIntegrationID).
+			Return(&models.JobCreationResult{Job: job, Status: models.JobCreationStatusCreated}, nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.None[*models.SlackIntegration](), nil)
+
+		// Execute
+		err := fixture.useCase.ProcessSlackMessageEvent(fixture.ctx, event, testSlackIntegrationID, testOrgID)
+
+		// Assert - the new job is created in the thread of the closed one
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "slack integration not found")
+		fixture.mocks.jobsService.AssertExpectations(t)
+	})
+}
+
+func TestProcessReactionAdded(t *testing.T) {
+	t.Run("success_valid_completion_reaction", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testUserID := testutils.GenerateSlackUserID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testAgentID := testutils.GenerateAgentID()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+
+		reactionName := "white_check_mark"
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID,
+			},
+		}
+
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+
+		agent := &models.ActiveAgent{
+			ID:             testAgentID,
+			WSConnectionID: testWSConnectionID,
+			OrgID:          testOrgID,
+		}
+
+		// Configure expectations
+		fixture.mocks.jobsService.On("GetJobBySlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testSlackIntegrationID).
+			Return(mo.Some(job), nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+		fixture.mocks.agentsService.On("GetAgentByJobID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(agent), nil)
+
+		// Transaction expectations
+		fixture.mocks.txManager.On("WithTransaction", fixture.ctx, mock.AnythingOfType("func(context.Context) error")).
+			Run(func(args mock.Arguments) {
+				// Execute the transaction function
+				txFunc := args.Get(1).(func(context.Context) error)
+				txFunc(fixture.ctx) // Execute with same context for simplicity
+			}).Return(nil)
+		fixture.mocks.agentsService.On("UnassignAgentFromJob", fixture.ctx, testOrgID, testAgentID, testJobID).
+			Return(nil)
+		fixture.mocks.jobsService.On(
+			"CloseJob",
+			fixture.ctx,
+			testOrgID,
+			testJobID,
+			models.JobStatusCompleted,
+			models.ManualJobCompletionReason,
+			testAgentID,
+		).Return(nil)
+		fixture.mocks.jobsService.On(
+			"AddJobTranscriptMessage",
+			fixture.ctx,
+			testOrgID,
+			testJobID,
+			models.TranscriptAuthorUser,
+			testUserID,
+			models.TranscriptMessageTypeSystemMessage,
+			models.ManualJobCompletionReason,
+			"",
+		).Return(&models.JobTranscriptMessage{}, nil)
+
+		// Mock Slack client for sending system message
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			return &clients.SlackPostMessageResponse{
+				Channel:   channelID,
+				Timestamp: "1234567890.123456",
+			}, nil
+		}
+
+		// Execute
+		err := fixture.useCase.ProcessReactionAdded(
+			fixture.ctx,
+			reactionName,
+			testUserID,
+			testChannelID,
+			testThreadTS,
+			testSlackIntegrationID,
+			testOrgID,
+		)
+
+		// Assert
+		assert.NoError(t, err)
+		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
+		fixture.mocks.agentsService.AssertExpectations(t)
+		fixture.mocks.txManager.AssertExpectations(t)
+	})
+
+	t.Run("ignore_reaction_by_different_user", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testUserID := testutils.GenerateSlackUserID()
+		testDifferentUserID := testutils.GenerateSlackUserID() // Different user
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+
+		reactionName := "white_check_mark"
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID, // Original job creator
+			},
+		}
+
+		// Configure expectations
+		fixture.mocks.jobsService.On("GetJobBySlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testSlackIntegrationID).
+			Return(mo.Some(job), nil)
+
+		// Execute
+		err := fixture.useCase.ProcessReactionAdded(
+			fixture.ctx,
+			reactionName,
+			testDifferentUserID,
+			testChannelID,
+			testThreadTS,
+			testSlackIntegrationID,
+			testOrgID,
+		)
+
+		// Assert
+		assert.NoError(t, err)
+		fixture.mocks.jobsService.AssertExpectations(t)
+	})
+
+	t.Run("urgent_reaction_by_job_creator_raises_priority", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		testJobID := testutils.GenerateJobID()
+		testUserID := testutils.GenerateSlackUserID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID,
+			},
+		}
+
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+
+		// Configure expectations
+		fixture.mocks.jobsService.On("GetJobBySlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testSlackIntegrationID).
+			Return(mo.Some(job), nil)
+		fixture.mocks.jobsService.On("RaiseJobPriority", fixture.ctx, testOrgID, testJobID, models.JobPriorityUrgent).
+			Return(true, nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+
+		var postedText string
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			postedText = params.Text
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: "1234567890.123456"}, nil
+		}
+
+		// Execute
+		err := fixture.useCase.ProcessReactionAdded(
+			fixture.ctx,
+			"fire",
+			testUserID,
+			testChannelID,
+			testThreadTS,
+			testSlackIntegrationID,
+			testOrgID,
+		)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, models.JobPriorityUrgent, job.Priority)
+		assert.Contains(t, postedText, "Priority raised to urgent")
+		fixture.mocks.jobsService.AssertExpectations(t)
+	})
+
+	t.Run("stop_reaction_by_job_creator_cancels_job", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		testJobID := testutils.GenerateJobID()
+		testUserID := testutils.GenerateSlackUserID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID,
+			},
+		}
+		inProgressMessage := &models.ProcessedSlackMessage{
+			ID:                 testutils.GenerateProcessedMessageID(),
+			JobID:              testJobID,
+			SlackTS:            testutils.GenerateSlackThreadTS(),
+			SlackChannelID:     testChannelID,
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusInProgress,
+		}
+		cancelledMessage := *inProgressMessage
+		cancelledMessage.Status = models.ProcessedSlackMessageStatusCancelled
+		agent := &models.ActiveAgent{
+			ID:             testutils.GenerateAgentID(),
+			WSConnectionID: testWSConnectionID,
+		}
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+
+		// Configure expectations
+		fixture.mocks.jobsService.On("GetJobBySlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testSlackIntegrationID).
+			Return(mo.Some(job), nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{}, nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedSlackMessageStatusInProgress, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{inProgressMessage}, nil)
+		fixture.mocks.agentsService.On("GetAgentByJobID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(agent), nil)
+		fixture.mocks.agentsService.On(
+			"SendMessageToAgent",
+			fixture.ctx,
+			testOrgID,
+			testWSConnectionID,
+			testJobID,
+			inProgressMessage.ID,
+			mock.MatchedBy(func(msg models.BaseMessage) bool {
+				return msg.Type == models.MessageTypeCancelJob
+			}),
+		).Return(nil)
+		fixture.mocks.txManager.On("WithTransaction", fixture.ctx, mock.AnythingOfType("func(context.Context) error")).
+			Run(func(args mock.Arguments) {
+				txFunc := args.Get(1).(func(context.Context) error)
+				txFunc(fixture.ctx)
+			}).Return(nil)
+		fixture.mocks.slackMessagesService.On("UpdateProcessedSlackMessage", fixture.ctx, testOrgID, inProgressMessage.ID, models.ProcessedSlackMessageStatusCancelled, testSlackIntegrationID).
+			Return(&cancelledMessage, nil)
+		fixture.mocks.agentsService.On("FailPendingAgentJobMessages", fixture.ctx, testOrgID, testJobID, "job was cancelled").
+			Return(nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+
+		var addedReactions []string
+		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			addedReactions = append(addedReactions, name)
+			return nil
+		}
+		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockGetReactions = func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error) {
+			return []clients.SlackItemReaction{}, nil
+		}
+		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
+			return &clients.SlackAuthTestResponse{UserID: "B123456789"}, nil
+		}
+		var postedMessages []string
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			postedMessages = append(postedMessages, params.Text)
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: "1234567890.123456"}, nil
+		}
+
+		// Execute
+		err := fixture.useCase.ProcessReactionAdded(
+			fixture.ctx,
+			"octagonal_sign",
+			testUserID,
+			testChannelID,
+			testThreadTS,
+			testSlackIntegrationID,
+			testOrgID,
+		)
+
+		// Assert - the cancellation is confirmed once the agent acknowledges it
+		require.NoError(t, err)
+		assert.Equal(t, []string{"no_entry_sign"}, addedReactions)
+		assert.Empty(t, postedMessages)
+		fixture.mocks.agentsService.AssertExpectations(t)
+		fixture.mocks.slackMessagesService.AssertExpectations(t)
+		fixture.mocks.jobsService.AssertNotCalled(t, "CloseJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("stop_reaction_does_not_stop_agent_when_cancelling_messages_fails", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		testJobID := testutils.GenerateJobID()
+		testUserID := testutils.GenerateSlackUserID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID,
+			},
+		}
+		inProgressMessage := &models.ProcessedSlackMessage{
+			ID:                 testutils.GenerateProcessedMessageID(),
+			JobID:              testJobID,
+			SlackTS:            testutils.GenerateSlackThreadTS(),
+			SlackChannelID:     testChannelID,
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusInProgress,
+		}
+		agent := &models.ActiveAgent{
+			ID:             testutils.GenerateAgentID(),
+			WSConnectionID: testutils.GenerateWSConnectionID(),
+		}
+
+		// Configure expectations
+		fixture.mocks.jobsService.On("GetJobBySlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testSlackIntegrationID).
+			Return(mo.Some(job), nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{}, nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedSlackMessageStatusInProgress, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{inProgressMessage}, nil)
+		fixture.mocks.agentsService.On("GetAgentByJobID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(agent), nil)
+		fixture.mocks.txManager.On("WithTransaction", fixture.ctx, mock.AnythingOfType("func(context.Context) error")).
+			Return(fmt.Errorf("database unavailable"))
+
+		// Execute
+		err := fixture.useCase.ProcessReactionAdded(
+			fixture.ctx,
+			"octagonal_sign",
+			testUserID,
+			testChannelID,
+			testThreadTS,
+			testSlackIntegrationID,
+			testOrgID,
+		)
+
+		// Assert - the agent keeps working on messages which are still in progress
+		require.Error(t, err)
+		fixture.mocks.agentsService.AssertNotCalled(t, "SendMessageToAgent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("stop_reaction_without_running_work_notifies_user", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		testJobID := testutils.GenerateJobID()
+		testUserID := testutils.GenerateSlackUserID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID,
+			},
+		}
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+
+		// Configure expectations
+		fixture.mocks.jobsService.On("GetJobBySlackThread", fixture.ctx, testOrgID, testThreadTS, testChannelID, testSlackIntegrationID).
+			Return(mo.Some(job), nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, mock.AnythingOfType("models.ProcessedSlackMessageStatus"), testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{}, nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+
+		var postedText string
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			postedText = params.Text
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: "1234567890.123456"}, nil
+		}
+
+		// Execute
+		err := fixture.useCase.ProcessReactionAdded(
+			fixture.ctx,
+			"octagonal_sign",
+			testUserID,
+			testChannelID,
+			testThreadTS,
+			testSlackIntegrationID,
+			testOrgID,
+		)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, postedText, "nothing to stop")
+		fixture.mocks.agentsService.AssertNotCalled(t, "SendMessageToAgent")
+	})
+
+	t.Run("ignore_non_completion_reaction", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data
+		testUserID := testutils.GenerateSlackUserID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+
+		reactionName := "thumbsup" // Not a completion emoji
+
+		// Execute
+		err := fixture.useCase.ProcessReactionAdded(
+			fixture.ctx,
+			reactionName,
+			testUserID,
+			testChannelID,
+			testThreadTS,
+			testSlackIntegrationID,
+			testOrgID,
+		)
+
+		// Assert - should return without error and no mocks called
+		assert.NoError(t, err)
+	})
+}
+
+func TestProcessJobComplete(t *testing.T) {
+	t.Run("success_agent_completes_job", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testOrgID := testutils.GenerateOrgID()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+		testAgentID := testutils.GenerateAgentID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testUserID := testutils.GenerateSlackUserID()
+
+		clientID := testWSConnectionID
+		payload := models.JobCompletePayload{
+			JobID:  testJobID,
+			Reason: "Task completed successfully",
+		}
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID,
+			},
+		}
+
+		agent := &models.ActiveAgent{
+			ID:             testAgentID,
+			WSConnectionID: testWSConnectionID,
+			OrgID:          testOrgID,
+		}
+
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+
+		// Configure expectations
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, payload.JobID).
+			Return(mo.Some(job), nil)
+		fixture.mocks.agentsService.On("GetAgentByWSConnectionID", fixture.ctx, testOrgID, clientID).
+			Return(mo.Some(agent), nil)
+		fixture.mocks.agentsUseCase.On("ValidateJobBelongsToAgent", fixture.ctx, testAgentID, testJobID, testOrgID).
+			Return(nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+
+		// Mock Slack client for updating reaction
+		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockGetReactions = func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error) {
+			return []clients.SlackItemReaction{}, nil
+		}
+		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
+			return &clients.SlackAuthTestResponse{
+				UserID: "B123456789",
+				TeamID: testSlackIntegrationID,
+			}, nil
+		}
+
+		// Transaction expectations
+		fixture.mocks.txManager.On("WithTransaction", fixture.ctx, mock.AnythingOfType("func(context.Context) error")).
+			Run(func(args mock.Arguments) {
+				// Execute the transaction function
+				txFunc := args.Get(1).(func(context.Context) error)
+				txFunc(fixture.ctx) // Execute with same context for simplicity
+			}).Return(nil)
+		fixture.mocks.agentsService.On("UnassignAgentFromJob", fixture.ctx, testOrgID, testAgentID, testJobID).
+			Return(nil)
+		fixture.mocks.jobsService.On(
+			"CloseJob",
+			fixture.ctx,
+			testOrgID,
+			testJobID,
+			models.JobStatusCompleted,
+			payload.Reason,
+			testAgentID,
+		).Return(nil)
+		fixture.mocks.jobsService.On(
+			"AddJobTranscriptMessage",
+			fixture.ctx,
+			testOrgID,
+			testJobID,
+			models.TranscriptAuthorAgent,
+			testAgentID,
+			models.TranscriptMessageTypeSystemMessage,
+			payload.Reason,
+			"",
+		).Return(&models.JobTranscriptMessage{}, nil)
+
+		// Mock system message sending
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			return &clients.SlackPostMessageResponse{
+				Channel:   channelID,
+				Timestamp: "1234567890.123456",
+			}, nil
+		}
+
+		// Execute
+		err := fixture.useCase.ProcessJobComplete(fixture.ctx, clientID, payload, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.agentsService.AssertExpectations(t)
+		fixture.mocks.agentsUseCase.AssertExpectations(t)
+		fixture.mocks.txManager.AssertExpectations(t)
+		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
+	})
+
+	t.Run("job_not_found", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testOrgID := testutils.GenerateOrgID()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+
+		clientID := testWSConnectionID
+		payload := models.JobCompletePayload{
+			JobID:  testJobID,
+			Reason: "Task completed successfully",
+		}
+
+		// Configure expectations - job not found
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, payload.JobID).
+			Return(mo.None[*models.Job](), nil)
+
+		// Execute
+		err := fixture.useCase.ProcessJobComplete(fixture.ctx, clientID, payload, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		fixture.mocks.jobsService.AssertExpectations(t)
+	})
+}
+
+func TestProcessAssistantMessage(t *testing.T) {
+	t.Run("success_send_message", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testAgentID := testutils.GenerateAgentID()
+		testOrgID := testutils.GenerateOrgID()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testUserID := testutils.GenerateSlackUserID()
+
+		clientID := testWSConnectionID
+		payload := models.AssistantMessagePayload{
+			JobID:              testJobID,
+			Message:            "Here's my response to your question",
+			ProcessedMessageID: testProcessedID,
+		}
+
+		agent := &models.ActiveAgent{
+			ID:             testAgentID,
+			WSConnectionID: testWSConnectionID,
+			OrgID:          testOrgID,
+		}
+
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID,
+			},
+		}
+
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+
+		updatedMessage := &models.ProcessedSlackMessage{
+			ID:                 testProcessedID,
+			JobID:              testJobID,
+			SlackTS:            testutils.GenerateSlackMessageID(),
+			SlackChannelID:     testChannelID,
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusCompleted,
+		}
+
+		// Configure expectations
+		fixture.mocks.agentsService.On("GetAgentByWSConnectionID", fixture.ctx, testOrgID, clientID).
+			Return(mo.Some(agent), nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(job), nil)
+		fixture.mocks.agentsUseCase.On("ValidateJobBelongsToAgent", fixture.ctx, testAgentID, testJobID, testOrgID).
+			Return(nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorAgent, testAgentID, models.TranscriptMessageTypeAssistantMessage, payload.Message, testProcessedID).
+			Return(&models.JobTranscriptMessage{}, nil)
+		fixture.mocks.jobsService.On("UpdateJobTimestamp", fixture.ctx, testOrgID, testJobID).Return(nil)
+		fixture.mocks.slackMessagesService.On("UpdateProcessedSlackMessage", fixture.ctx, testOrgID, testProcessedID, models.ProcessedSlackMessageStatusCompleted, testSlackIntegrationID).
+			Return(updatedMessage, nil)
+
+		// Mock Slack client for posting message
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			return &clients.SlackPostMessageResponse{
+				Channel:   channelID,
+				Timestamp: "1234567890.123456",
+			}, nil
+		}
+
+		// Mock reaction and bot user methods for updating message status
+		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockGetReactions = func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error) {
+			return []clients.SlackItemReaction{}, nil
+		}
+		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
+			return &clients.SlackAuthTestResponse{
+				UserID: "B123456789",
+				TeamID: testSlackIntegrationID,
+			}, nil
+		}
+
+		// Check if latest message
+		fixture.mocks.slackMessagesService.On("GetLatestProcessedMessageForJob", fixture.ctx, testOrgID, testJobID, testSlackIntegrationID).
+			Return(mo.Some(updatedMessage), nil)
+
+		// Execute
+		err := fixture.useCase.ProcessAssistantMessage(fixture.ctx, clientID, payload, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		fixture.mocks.agentsService.AssertExpectations(t)
+		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.agentsUseCase.AssertExpectations(t)
+		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
+		fixture.mocks.slackMessagesService.AssertExpectations(t)
+	})
+
+	t.Run("job_not_found", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testAgentID := testutils.GenerateAgentID()
+		testOrgID := testutils.GenerateOrgID()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+
+		clientID := testWSConnectionID
+		payload := models.AssistantMessagePayload{
+			JobID:              testJobID,
+			Message:            "Here's my response",
+			ProcessedMessageID: testProcessedID,
+		}
+
+		agent := &models.ActiveAgent{
+			ID:             testAgentID,
+			WSConnectionID: clientID,
+			OrgID:          testOrgID,
+		}
+
+		// Configure expectations
+		fixture.mocks.agentsService.On("GetAgentByWSConnectionID", fixture.ctx, testOrgID, clientID).
+			Return(mo.Some(agent), nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, payload.JobID).
+			Return(mo.None[*models.Job](), nil)
+
+		// Execute
+		err := fixture.useCase.ProcessAssistantMessage(fixture.ctx, clientID, payload, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		fixture.mocks.agentsService.AssertExpectations(t)
+		fixture.mocks.jobsService.AssertExpectations(t)
+	})
+}
+
+func TestProcessAssistantDelta(t *testing.T) {
+	t.Run("streams_reply_into_one_message_replaced_by_final_message", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testAgentID := testutils.GenerateAgentID()
+		testOrgID := testutils.GenerateOrgID()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testStreamTS := testutils.GenerateSlackMessageID()
+
+		clientID := testWSConnectionID
+		agent := &models.ActiveAgent{
+			ID:             testAgentID,
+			WSConnectionID: testWSConnectionID,
+			OrgID:          testOrgID,
+		}
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+			},
+		}
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+		updatedMessage := &models.ProcessedSlackMessage{
+			ID:                 testProcessedID,
+			JobID:              testJobID,
+			SlackTS:            testThreadTS,
+			SlackChannelID:     testChannelID,
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusCompleted,
+		}
+
+		// Configure expectations
+		fixture.mocks.agentsService.On("GetAgentByWSConnectionID", fixture.ctx, testOrgID, clientID).
+			Return(mo.Some(agent), nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(job), nil)
+		fixture.mocks.agentsUseCase.On("ValidateJobBelongsToAgent", fixture.ctx, testAgentID, testJobID, testOrgID).
+			Return(nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorAgent, testAgentID, models.TranscriptMessageTypeAssistantMessage, "Looking into the failing test - it is fixed now", testProcessedID).
+			Return(&models.JobTranscriptMessage{}, nil)
+		fixture.mocks.jobsService.On("UpdateJobTimestamp", fixture.ctx, testOrgID, testJobID).Return(nil)
+		fixture.mocks.slackMessagesService.
+			On("UpdateProcessedSlackMessage", fixture.ctx, testOrgID, testProcessedID, models.ProcessedSlackMessageStatusCompleted, testSlackIntegrationID).
+			Return(updatedMessage, nil)
+		fixture.mocks.slackMessagesService.
+			On("GetLatestProcessedMessageForJob", fixture.ctx, testOrgID, testJobID, testSlackIntegrationID).
+			Return(mo.Some(updatedMessage), nil)
+
+		var postedTexts, updatedTexts []string
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			postedTexts = append(postedTexts, params.Text)
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: testStreamTS}, nil
+		}
+		fixture.mocks.slackClient.MockUpdateMessage = func(channelID, timestamp, text string) error {
+			assert.Equal(t, testChannelID, channelID)
+			assert.Equal(t, testStreamTS, timestamp)
+			updatedTexts = append(updatedTexts, text)
+			return nil
+		}
+		fixture.mocks.slackClient.MockGetReactions = func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error) {
+			return []clients.SlackItemReaction{}, nil
+		}
+
+		// Execute - the second delta arrives within the update interval and is only buffered
+		for _, delta := range []string{"Looking into ", "the failing test"} {
+			err := fixture.useCase.ProcessAssistantDelta(fixture.ctx, clientID, models.AssistantDeltaPayload{
+				JobID:              testJobID,
+				Delta:              delta,
+				ProcessedMessageID: testProcessedID,
+			}, testOrgID)
+			require.NoError(t, err)
+		}
+		err := fixture.useCase.ProcessAssistantMessage(fixture.ctx, clientID, models.AssistantMessagePayload{
+			JobID:              testJobID,
+			Message:            "Looking into the failing test - it is fixed now",
+			ProcessedMessageID: testProcessedID,
+		}, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Looking into "}, postedTexts)
+		assert.Equal(t, []string{"Looking into the failing test - it is fixed now"}, updatedTexts)
+		fixture.mocks.agentsUseCase.AssertExpectations(t)
+		fixture.mocks.slackMessagesService.AssertExpectations(t)
+	})
+
+	t.Run("job_not_found", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		testJobID := testutils.GenerateJobID()
+		testOrgID := testutils.GenerateOrgID()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+		agent := &models.ActiveAgent{
+			ID:             testutils.GenerateAgentID(),
+			WSConnectionID: testWSConnectionID,
+			OrgID:          testOrgID,
+		}
+
+		// Configure expectations
+		fixture.mocks.agentsService.On("GetAgentByWSConnectionID", fixture.ctx, testOrgID, testWSConnectionID).
+			Return(mo.Some(agent), nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.None[*models.Job](), nil)
+
+		// Execute
+		err := fixture.useCase.ProcessAssistantDelta(fixture.ctx, testWSConnectionID, models.AssistantDeltaPayload{
+			JobID:              testJobID,
+			Delta:              "Hello",
+			ProcessedMessageID: testutils.GenerateProcessedMessageID(),
+		}, testOrgID)
+
+		// Assert
+		assert.NoError(t, err)
+		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.agentsUseCase.AssertNotCalled(t, "ValidateJobBelongsToAgent")
+	})
+}
+
+func TestProcessProgressEvent(t *testing.T) {
+	t.Run("keeps_single_status_message_up_to_date", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testAgentID := testutils.GenerateAgentID()
+		testOrgID := testutils.GenerateOrgID()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testStatusTS := testutils.GenerateSlackMessageID()
+
+		agent := &models.ActiveAgent{
+			ID:             testAgentID,
+			WSConnectionID: testWSConnectionID,
+			OrgID:          testOrgID,
+		}
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+			},
+		}
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+
+		// Configure expectations
+		fixture.mocks.agentsService.On("GetAgentByWSConnectionID", fixture.ctx, testOrgID, testWSConnectionID).
+			Return(mo.Some(agent), nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(job), nil)
+		fixture.mocks.agentsUseCase.On("ValidateJobBelongsToAgent", fixture.ctx, testAgentID, testJobID, testOrgID).
+			Return(nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+		fixture.mocks.jobsService.On("UpdateJobTimestamp", fixture.ctx, testOrgID, testJobID).Return(nil).Once()
+
+		var postedTexts []string
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			postedTexts = append(postedTexts, params.Text)
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: testStatusTS}, nil
+		}
+		fixture.mocks.slackClient.MockUpdateMessage = func(channelID, timestamp, text string) error {
+			t.Fatalf("status message should not be edited within the update interval")
+			return nil
+		}
+
+		// Execute - the second step arrives within the update interval and is only buffered
+		for _, event := range []models.ProgressEventPayload{
+			{JobID: testJobID, Kind: models.ProgressEventKindFileEdit, Title: "Editing src/foo.go"},
+			{JobID: testJobID, Kind: models.ProgressEventKindTests, Title: "Running tests"},
+		} {
+			err := fixture.useCase.ProcessProgressEvent(fixture.ctx, testWSConnectionID, event, testOrgID)
+			require.NoError(t, err)
+		}
+
+		// Assert
+		assert.Equal(t, []string{"✏️ Editing src/foo.go"}, postedTexts)
+		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.agentsUseCase.AssertExpectations(t)
+	})
+}
+
+func TestProcessArtifact(t *testing.T) {
+	t.Run("uploads_artifact_into_job_thread", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testAgentID := testutils.GenerateAgentID()
+		testOrgID := testutils.GenerateOrgID()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+
+		agent := &models.ActiveAgent{
+			ID:             testAgentID,
+			WSConnectionID: testWSConnectionID,
+			OrgID:          testOrgID,
+		}
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+			},
+		}
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+		payload := models.ArtifactPayload{
+			JobID: testJobID,
+			Attachment: models.AgentAttachment{
+				Name:     "fix.diff",
+				MimeType: "text/x-diff",
+				Content:  []byte("--- a/main.go\n+++ b/main.go\n"),
+			},
+			Comment: "Here is the patch",
+		}
+
+		// Configure expectations
+		fixture.mocks.agentsService.On("GetAgentByWSConnectionID", fixture.ctx, testOrgID, testWSConnectionID).
 			Return(mo.Some(agent), nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(job), nil)
 		fixture.mocks.agentsUseCase.On("ValidateJobBelongsToAgent", fixture.ctx, testAgentID, testJobID, testOrgID).
 			Return(nil)
 		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
 			Return(mo.Some(slackIntegration), nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorAgent, testAgentID, models.TranscriptMessageTypeArtifact, "fix.diff: Here is the patch", "").
+			Return(&models.JobTranscriptMessage{}, nil)
+		fixture.mocks.jobsService.On("UpdateJobTimestamp", fixture.ctx, testOrgID, testJobID).Return(nil).Once()
+
+		var uploaded []clients.SlackUploadFileParams
+		fixture.mocks.slackClient.MockUploadFile = func(params clients.SlackUploadFileParams) error {
+			uploaded = append(uploaded, params)
+			return nil
+		}
+
+		// Execute
+		err := fixture.useCase.ProcessArtifact(fixture.ctx, testWSConnectionID, payload, testOrgID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []clients.SlackUploadFileParams{{
+			Channel:        testChannelID,
+			ThreadTS:       testThreadTS,
+			Filename:       "fix.diff",
+			Content:        payload.Attachment.Content,
+			InitialComment: "Here is the patch",
+		}}, uploaded)
+		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.agentsUseCase.AssertExpectations(t)
+	})
+
+	t.Run("job_not_found", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		testJobID := testutils.GenerateJobID()
+		testAgentID := testutils.GenerateAgentID()
+		testOrgID := testutils.GenerateOrgID()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+
+		agent := &models.ActiveAgent{
+			ID:             testAgentID,
+			WSConnectionID: testWSConnectionID,
+			OrgID:          testOrgID,
+		}
+
+		fixture.mocks.agentsService.On("GetAgentByWSConnectionID", fixture.ctx, testOrgID, testWSConnectionID).
+			Return(mo.Some(agent), nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.None[*models.Job](), nil)
+		fixture.mocks.slackClient.MockUploadFile = func(params clients.SlackUploadFileParams) error {
+			t.Fatalf("artifact of a completed job should not be uploaded")
+			return nil
+		}
+
+		// Execute
+		payload := models.ArtifactPayload{
+			JobID:      testJobID,
+			Attachment: models.AgentAttachment{Name: "report.txt", Content: []byte("ok")},
+		}
+		err := fixture.useCase.ProcessArtifact(fixture.ctx, testWSConnectionID, payload, testOrgID)
+
+		// Assert
+		require.NoError(t, err)
+		fixture.mocks.jobsService.AssertExpectations(t)
+	})
+}
+
+func TestProcessQueuedJobs(t *testing.T) {
+	t.Run("success_process_queued_jobs", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testUserID := testutils.GenerateSlackUserID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testSlackToken := testutils.GenerateSlackToken()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+
+		integration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testSlackToken,
+		}
+
+		queuedJob := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID,
+			},
+		}
+
+		queuedMessage := &models.ProcessedSlackMessage{
+			ID:                 testProcessedID,
+			JobID:              testJobID,
+			SlackTS:            testThreadTS,
+			SlackChannelID:     testChannelID,
+			TextContent:        "Queued message content",
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusQueued,
+		}
+
+		updatedMessage := &models.ProcessedSlackMessage{
+			ID:                 testProcessedID,
+			JobID:              testJobID,
+			SlackTS:            testThreadTS,
+			SlackChannelID:     testChannelID,
+			TextContent:        "Queued message content",
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusInProgress,
+		}
+
+		// Configure expectations
+		fixture.mocks.slackIntegrationsService.On("GetAllSlackIntegrations", fixture.ctx).
+			Return([]models.SlackIntegration{*integration}, nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByStatus", fixture.ctx, testOrgID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{queuedMessage}, nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(queuedJob), nil)
+		fixture.mocks.connectedChannelsService.On("GetSlackConnectedChannel", fixture.ctx, testOrgID, integration.SlackTeamID, testChannelID).
+			Return(mo.None[*models.SlackConnectedChannel](), nil)
+		fixture.mocks.agentsService.On("GetAgentByJobID", fixture.ctx, testOrgID, queuedJob.ID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, queuedJob.ID, "", models.AgentLabels(nil), testOrgID).
+			Return(testWSConnectionID, true, nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{queuedMessage}, nil)
+		fixture.mocks.slackMessagesService.On("UpdateProcessedSlackMessage", fixture.ctx, testOrgID, testProcessedID, models.ProcessedSlackMessageStatusInProgress, testSlackIntegrationID).
+			Return(updatedMessage, nil)
+
+		// Mock Slack client for updating reaction
+		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockGetReactions = func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error) {
+			return []clients.SlackItemReaction{}, nil
+		}
+		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
+			return &clients.SlackAuthTestResponse{
+				UserID: "B123456789",
+				TeamID: testSlackIntegrationID,
+			}, nil
+		}
+
+		// Mock expectations for sendStartConversationToAgent
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(queuedJob), nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(integration), nil)
+		fixture.mocks.slackClient.MockGetPermalink = func(params *clients.SlackPermalinkParameters) (string, error) {
+			return "https://workspace.slack.com/archives/" + params.Channel + "/p" + params.TS, nil
+		}
+		fixture.mocks.slackClient.MockResolveMentionsInMessage = func(ctx context.Context, message string) string {
+			return message // Return unchanged for simplicity
+		}
+		fixture.mocks.agentsService.On(
+			"SendMessageToAgent",
+			fixture.ctx,
+			testOrgID,
+			testWSConnectionID,
+			mock.Anything,
+			mock.Anything,
+			mock.AnythingOfType("models.BaseMessage"),
+		).Return(nil)
+
+		// Execute
+		err := fixture.useCase.ProcessQueuedJobs(fixture.ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
+		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.agentsUseCase.AssertExpectations(t)
+		fixture.mocks.slackMessagesService.AssertExpectations(t)
+		fixture.mocks.wsClient.AssertExpectations(t)
+	})
+
+	t.Run("success_hand_over_job_with_history", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testWSConnectionID := testutils.GenerateWSConnectionID()
+		testFirstProcessedID := testutils.GenerateProcessedMessageID()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+
+		integration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+
+		queuedJob := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testutils.GenerateSlackUserID(),
+			},
+		}
+
+		// A follow-up in the thread, requeued after the previous agent disconnected
+		queuedMessage := &models.ProcessedSlackMessage{
+			ID:                 testProcessedID,
+			JobID:              testJobID,
+			SlackTS:            testutils.GenerateSlackThreadTS(),
+			SlackChannelID:     testChannelID,
+			TextContent:        "Now add a test",
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusQueued,
+		}
+		updatedMessage := *queuedMessage
+		updatedMessage.Status = models.ProcessedSlackMessageStatusInProgress
+
+		transcript := []*models.JobTranscriptMessage{
+			{
+				Author:             models.TranscriptAuthorUser,
+				MessageType:        models.TranscriptMessageTypeUserMessage,
+				Content:            "Fix the bug",
+				ProcessedMessageID: testFirstProcessedID,
+			},
+			{
+				Author:             models.TranscriptAuthorAgent,
+				MessageType:        models.TranscriptMessageTypeAssistantMessage,
+				Content:            "Fixed it",
+				ProcessedMessageID: testFirstProcessedID,
+			},
+			{
+				Author:             models.TranscriptAuthorUser,
+				MessageType:        models.TranscriptMessageTypeUserMessage,
+				Content:            "Now add a test",
+				ProcessedMessageID: testProcessedID,
+			},
+		}
+
+		// Configure expectations
+		fixture.mocks.slackIntegrationsService.On("GetAllSlackIntegrations", fixture.ctx).
+			Return([]models.SlackIntegration{*integration}, nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByStatus", fixture.ctx, testOrgID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{queuedMessage}, nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(queuedJob), nil)
+		fixture.mocks.connectedChannelsService.On("GetSlackConnectedChannel", fixture.ctx, testOrgID, integration.SlackTeamID, testChannelID).
+			Return(mo.None[*models.SlackConnectedChannel](), nil)
+		fixture.mocks.agentsService.On("GetAgentByJobID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, testJobID, "", models.AgentLabels(nil), testOrgID).
+			Return(testWSConnectionID, true, nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{queuedMessage}, nil)
+		fixture.mocks.jobsService.On("GetJobTranscript", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(transcript), nil)
+		fixture.mocks.slackMessagesService.On("UpdateProcessedSlackMessage", fixture.ctx, testOrgID, testProcessedID, models.ProcessedSlackMessageStatusInProgress, testSlackIntegrationID).
+			Return(&updatedMessage, nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(integration), nil)
+
+		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockGetReactions = func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error) {
+			return []clients.SlackItemReaction{}, nil
+		}
+		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
+			return &clients.SlackAuthTestResponse{UserID: "B123456789"}, nil
+		}
+		fixture.mocks.slackClient.MockGetPermalink = func(params *clients.SlackPermalinkParameters) (string, error) {
+			return "https://workspace.slack.com/archives/" + params.Channel + "/p" + params.TS, nil
+		}
+		fixture.mocks.slackClient.MockResolveMentionsInMessage = func(ctx context.Context, message string) string {
+			return message
+		}
+
+		var sentMessage models.BaseMessage
+		fixture.mocks.agentsService.On("SendMessageToAgent", fixture.ctx, testOrgID, testWSConnectionID, testJobID, testProcessedID, mock.AnythingOfType("models.BaseMessage")).
+			Run(func(args mock.Arguments) {
+				sentMessage = args.Get(5).(models.BaseMessage)
+			}).
+			Return(nil)
+
+		// Execute
+		err := fixture.useCase.ProcessQueuedJobs(fixture.ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, models.MessageTypeStartConversation, sentMessage.Type)
+		payload, ok := sentMessage.Payload.(models.StartConversationPayload)
+		require.True(t, ok)
+		assert.Equal(t, "Now add a test", payload.Message)
+		assert.Equal(t, []models.ConversationTurn{
+			{Author: models.ConversationAuthorUser, Message: "Fix the bug"},
+			{Author: models.ConversationAuthorAssistant, Message: "Fixed it"},
+		}, payload.History)
+		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.agentsService.AssertExpectations(t)
+		fixture.mocks.slackMessagesService.AssertExpectations(t)
+	})
+
+	t.Run("no_agents_available", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testJobID := testutils.GenerateJobID()
+		testUserID := testutils.GenerateSlackUserID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testSlackToken := testutils.GenerateSlackToken()
+
+		integration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testSlackToken,
+		}
+
+		queuedJob := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testUserID,
+			},
+		}
+
+		queuedMessage := &models.ProcessedSlackMessage{
+			ID:                 testutils.GenerateProcessedMessageID(),
+			JobID:              testJobID,
+			SlackTS:            testThreadTS,
+			SlackChannelID:     testChannelID,
+			TextContent:        "Queued message content",
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusQueued,
+		}
+
+		// Configure expectations
+		fixture.mocks.slackIntegrationsService.On("GetAllSlackIntegrations", fixture.ctx).
+			Return([]models.SlackIntegration{*integration}, nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByStatus", fixture.ctx, testOrgID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{queuedMessage}, nil)
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
+			Return(mo.Some(queuedJob), nil)
+		fixture.mocks.connectedChannelsService.On("GetSlackConnectedChannel", fixture.ctx, testOrgID, integration.SlackTeamID, testChannelID).
+			Return(mo.None[*models.SlackConnectedChannel](), nil)
+		fixture.mocks.agentsService.On("GetAgentByJobID", fixture.ctx, testOrgID, queuedJob.ID).
+			Return(mo.None[*models.ActiveAgent](), nil)
+		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, queuedJob.ID, "", models.AgentLabels(nil), testOrgID).
+			Return("", false, nil) // No agents available
+
+		// Execute
+		err := fixture.useCase.ProcessQueuedJobs(fixture.ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
+		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.agentsUseCase.AssertExpectations(t)
+	})
+}
+
+func TestProcessProcessingMessage(t *testing.T) {
+	t.Run("success_update_message_reaction", func(t *testing.T) {
+		// Setup
+		fixture := setupSlackUseCaseTest(t)
+
+		// Generate consistent test data for this test case
+		testProcessedID := testutils.GenerateProcessedMessageID()
+		testJobID := testutils.GenerateJobID()
+		testClientID := testutils.GenerateClientID()
+		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testMessageID := testutils.GenerateSlackMessageID()
+
+		payload := models.ProcessingMessagePayload{
+			ProcessedMessageID: testProcessedID,
+		}
+
+		processedMessage := &models.ProcessedSlackMessage{
+			ID:                 testProcessedID,
+			JobID:              testJobID,
+			SlackTS:            testMessageID,
+			SlackChannelID:     testChannelID,
+			SlackIntegrationID: testSlackIntegrationID,
+			OrgID:              testOrgID,
+		}
+
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+
+		// Configure expectations
+		fixture.mocks.slackMessagesService.On("GetProcessedSlackMessageByID", fixture.ctx, testOrgID, testProcessedID).
+			Return(mo.Some(processedMessage), nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
 
 		// Mock Slack client for updating reaction
 		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
@@ -480,91 +2359,65 @@ func TestProcessJobComplete(t *testing.T) {
 			}, nil
 		}
 
-		// Transaction expectations
-		fixture.mocks.txManager.On("WithTransaction", fixture.ctx, mock.AnythingOfType("func(context.Context) error")).
-			Run(func(args mock.Arguments) {
-				// Execute the transaction function
-				txFunc := args.Get(1).(func(context.Context) error)
-				txFunc(fixture.ctx) // Execute with same context for simplicity
-			}).Return(nil)
-		fixture.mocks.agentsService.On("UnassignAgentFromJob", fixture.ctx, testOrgID, testAgentID, testJobID).
-			Return(nil)
-		fixture.mocks.jobsService.On("DeleteJob", fixture.ctx, testOrgID, testJobID).Return(nil)
-
-		// Mock system message sending
-		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
-			return &clients.SlackPostMessageResponse{
-				Channel:   channelID,
-				Timestamp: "1234567890.123456",
-			}, nil
-		}
-
 		// Execute
-		err := fixture.useCase.ProcessJobComplete(fixture.ctx, clientID, payload, testOrgID)
+		err := fixture.useCase.ProcessProcessingMessage(fixture.ctx, testClientID, payload, testOrgID)
 
 		// Assert
 		assert.NoError(t, err)
+		fixture.mocks.slackMessagesService.AssertExpectations(t)
 		fixture.mocks.jobsService.AssertExpectations(t)
-		fixture.mocks.agentsService.AssertExpectations(t)
-		fixture.mocks.agentsUseCase.AssertExpectations(t)
-		fixture.mocks.txManager.AssertExpectations(t)
 		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
 	})
 
-	t.Run("job_not_found", func(t *testing.T) {
+	t.Run("message_not_found", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
 		// Generate consistent test data for this test case
-		testJobID := testutils.GenerateJobID()
+		testProcessedID := testutils.GenerateProcessedMessageID()
+		testClientID := testutils.GenerateClientID()
 		testOrgID := testutils.GenerateOrgID()
-		testWSConnectionID := testutils.GenerateWSConnectionID()
 
-		clientID := testWSConnectionID
-		payload := models.JobCompletePayload{
-			JobID:  testJobID,
-			Reason: "Task completed successfully",
+		payload := models.ProcessingMessagePayload{
+			ProcessedMessageID: testProcessedID,
 		}
 
-		// Configure expectations - job not found
-		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, payload.JobID).
-			Return(mo.None[*models.Job](), nil)
+		// Configure expectations
+		fixture.mocks.slackMessagesService.On("GetProcessedSlackMessageByID", fixture.ctx, testOrgID, testProcessedID).
+			Return(mo.None[*models.ProcessedSlackMessage](), nil)
 
 		// Execute
-		err := fixture.useCase.ProcessJobComplete(fixture.ctx, clientID, payload, testOrgID)
+		err := fixture.useCase.ProcessProcessingMessage(fixture.ctx, testClientID, payload, testOrgID)
 
 		// Assert
 		assert.NoError(t, err)
-		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.slackMessagesService.AssertExpectations(t)
 	})
 }
 
-func TestProcessAssistantMessage(t *testing.T) {
-	t.Run("success_send_message", func(t *testing.T) {
+func TestProcessSystemMessage(t *testing.T) {
+	t.Run("success_regular_system_message", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
-		// Generate consistent test data for this test case
+		// Generate consistent test data
 		testJobID := testutils.GenerateJobID()
-		testAgentID := testutils.GenerateAgentID()
 		testOrgID := testutils.GenerateOrgID()
-		testWSConnectionID := testutils.GenerateWSConnectionID()
-		testProcessedID := testutils.GenerateProcessedMessageID()
+		testClientID := testutils.GenerateClientID()
 		testChannelID := testutils.GenerateSlackChannelID()
 		testThreadTS := testutils.GenerateSlackThreadTS()
 		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
 		testUserID := testutils.GenerateSlackUserID()
+		testAgentID := testutils.GenerateAgentID()
 
-		clientID := testWSConnectionID
-		payload := models.AssistantMessagePayload{
-			JobID:              testJobID,
-			Message:            "Here's my response to your question",
-			ProcessedMessageID: testProcessedID,
+		payload := models.SystemMessagePayload{
+			JobID:   testJobID,
+			Message: "System notification message",
 		}
 
 		agent := &models.ActiveAgent{
 			ID:             testAgentID,
-			WSConnectionID: testWSConnectionID,
+			WSConnectionID: testClientID,
 			OrgID:          testOrgID,
 		}
 
@@ -585,28 +2438,16 @@ func TestProcessAssistantMessage(t *testing.T) {
 			SlackAuthToken: testutils.GenerateSlackToken(),
 		}
 
-		updatedMessage := &models.ProcessedSlackMessage{
-			ID:                 testProcessedID,
-			JobID:              testJobID,
-			SlackTS:            testutils.GenerateSlackMessageID(),
-			SlackChannelID:     testChannelID,
-			SlackIntegrationID: testSlackIntegrationID,
-			OrgID:              testOrgID,
-			Status:             models.ProcessedSlackMessageStatusCompleted,
-		}
-
 		// Configure expectations
-		fixture.mocks.agentsService.On("GetAgentByWSConnectionID", fixture.ctx, testOrgID, clientID).
-			Return(mo.Some(agent), nil)
 		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
 			Return(mo.Some(job), nil)
-		fixture.mocks.agentsUseCase.On("ValidateJobBelongsToAgent", fixture.ctx, testAgentID, testJobID, testOrgID).
-			Return(nil)
 		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
 			Return(mo.Some(slackIntegration), nil)
+		fixture.mocks.agentsService.On("GetAgentByWSConnectionID", fixture.ctx, testOrgID, testClientID).
+			Return(mo.Some(agent), nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorAgent, testAgentID, models.TranscriptMessageTypeSystemMessage, payload.Message, "").
+			Return(&models.JobTranscriptMessage{}, nil)
 		fixture.mocks.jobsService.On("UpdateJobTimestamp", fixture.ctx, testOrgID, testJobID).Return(nil)
-		fixture.mocks.slackMessagesService.On("UpdateProcessedSlackMessage", fixture.ctx, testOrgID, testProcessedID, models.ProcessedSlackMessageStatusCompleted, testSlackIntegrationID).
-			Return(updatedMessage, nil)
 
 		// Mock Slack client for posting message
 		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
@@ -616,150 +2457,102 @@ func TestProcessAssistantMessage(t *testing.T) {
 			}, nil
 		}
 
-		// Mock reaction and bot user methods for updating message status
-		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
-			return nil
-		}
-		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
-			return nil
-		}
-		fixture.mocks.slackClient.MockGetReactions = func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error) {
-			return []clients.SlackItemReaction{}, nil
-		}
-		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
-			return &clients.SlackAuthTestResponse{
-				UserID: "B123456789",
-				TeamID: testSlackIntegrationID,
-			}, nil
-		}
-
-		// Check if latest message
-		fixture.mocks.slackMessagesService.On("GetLatestProcessedMessageForJob", fixture.ctx, testOrgID, testJobID, testSlackIntegrationID).
-			Return(mo.Some(updatedMessage), nil)
-
 		// Execute
-		err := fixture.useCase.ProcessAssistantMessage(fixture.ctx, clientID, payload, testOrgID)
+		err := fixture.useCase.ProcessSystemMessage(fixture.ctx, testClientID, payload, testOrgID)
 
 		// Assert
 		assert.NoError(t, err)
-		fixture.mocks.agentsService.AssertExpectations(t)
 		fixture.mocks.jobsService.AssertExpectations(t)
-		fixture.mocks.agentsUseCase.AssertExpectations(t)
 		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
-		fixture.mocks.slackMessagesService.AssertExpectations(t)
 	})
 
 	t.Run("job_not_found", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
-		// Generate consistent test data for this test case
+		// Generate consistent test data
 		testJobID := testutils.GenerateJobID()
-		testAgentID := testutils.GenerateAgentID()
 		testOrgID := testutils.GenerateOrgID()
-		testWSConnectionID := testutils.GenerateWSConnectionID()
-		testProcessedID := testutils.GenerateProcessedMessageID()
-
-		clientID := testWSConnectionID
-		payload := models.AssistantMessagePayload{
-			JobID:              testJobID,
-			Message:            "Here's my response",
-			ProcessedMessageID: testProcessedID,
-		}
+		testClientID := testutils.GenerateClientID()
 
-		agent := &models.ActiveAgent{
-			ID:             testAgentID,
-			WSConnectionID: clientID,
-			OrgID:          testOrgID,
+		payload := models.SystemMessagePayload{
+			JobID:   testJobID,
+			Message: "System message",
 		}
 
 		// Configure expectations
-		fixture.mocks.agentsService.On("GetAgentByWSConnectionID", fixture.ctx, testOrgID, clientID).
-			Return(mo.Some(agent), nil)
-		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, payload.JobID).
+		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
 			Return(mo.None[*models.Job](), nil)
 
 		// Execute
-		err := fixture.useCase.ProcessAssistantMessage(fixture.ctx, clientID, payload, testOrgID)
+		err := fixture.useCase.ProcessSystemMessage(fixture.ctx, testClientID, payload, testOrgID)
 
 		// Assert
 		assert.NoError(t, err)
-		fixture.mocks.agentsService.AssertExpectations(t)
 		fixture.mocks.jobsService.AssertExpectations(t)
 	})
 }
 
-func TestProcessQueuedJobs(t *testing.T) {
-	t.Run("success_process_queued_jobs", func(t *testing.T) {
+func TestRequeueSlackJob(t *testing.T) {
+	t.Run("success_requeue_in_flight_messages", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
 		// Generate consistent test data for this test case
 		testJobID := testutils.GenerateJobID()
-		testUserID := testutils.GenerateSlackUserID()
 		testOrgID := testutils.GenerateOrgID()
+		testAgentID := testutils.GenerateAgentID()
 		testChannelID := testutils.GenerateSlackChannelID()
 		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
 		testThreadTS := testutils.GenerateSlackThreadTS()
 		testSlackToken := testutils.GenerateSlackToken()
-		testWSConnectionID := testutils.GenerateWSConnectionID()
-		testProcessedID := testutils.GenerateProcessedMessageID()
-
-		integration := &models.SlackIntegration{
-			ID:             testSlackIntegrationID,
-			OrgID:          testOrgID,
-			SlackAuthToken: testSlackToken,
-		}
 
-		queuedJob := &models.Job{
+		job := &models.Job{
 			ID:    testJobID,
 			OrgID: testOrgID,
 			SlackPayload: &models.SlackJobPayload{
 				IntegrationID: testSlackIntegrationID,
 				ChannelID:     testChannelID,
 				ThreadTS:      testThreadTS,
-				UserID:        testUserID,
+				UserID:        testutils.GenerateSlackUserID(),
 			},
 		}
 
-		queuedMessage := &models.ProcessedSlackMessage{
-			ID:                 testProcessedID,
+		inProgressMessage := &models.ProcessedSlackMessage{
+			ID:                 testutils.GenerateProcessedMessageID(),
 			JobID:              testJobID,
 			SlackTS:            testThreadTS,
 			SlackChannelID:     testChannelID,
-			TextContent:        "Queued message content",
 			SlackIntegrationID: testSlackIntegrationID,
 			OrgID:              testOrgID,
-			Status:             models.ProcessedSlackMessageStatusQueued,
+			Status:             models.ProcessedSlackMessageStatusInProgress,
 		}
+		requeuedMessage := *inProgressMessage
+		requeuedMessage.Status = models.ProcessedSlackMessageStatusQueued
 
-		updatedMessage := &models.ProcessedSlackMessage{
-			ID:                 testProcessedID,
-			JobID:              testJobID,
-			SlackTS:            testThreadTS,
-			SlackChannelID:     testChannelID,
-			TextContent:        "Queued message content",
-			SlackIntegrationID: testSlackIntegrationID,
-			OrgID:              testOrgID,
-			Status:             models.ProcessedSlackMessageStatusInProgress,
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testSlackToken,
 		}
 
 		// Configure expectations
-		fixture.mocks.slackIntegrationsService.On("GetAllSlackIntegrations", fixture.ctx).
-			Return([]models.SlackIntegration{*integration}, nil)
-		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByStatus", fixture.ctx, testOrgID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
-			Return([]*models.ProcessedSlackMessage{queuedMessage}, nil)
-		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
-			Return(mo.Some(queuedJob), nil)
-		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, queuedJob.ID, testOrgID).
-			Return(testWSConnectionID, true, nil)
-		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
-			Return([]*models.ProcessedSlackMessage{queuedMessage}, nil)
-		fixture.mocks.slackMessagesService.On("UpdateProcessedSlackMessage", fixture.ctx, testOrgID, testProcessedID, models.ProcessedSlackMessageStatusInProgress, testSlackIntegrationID).
-			Return(updatedMessage, nil)
+		fixture.mocks.txManager.On("WithTransaction", fixture.ctx, mock.AnythingOfType("func(context.Context) error")).
+			Run(func(args mock.Arguments) {
+				txFunc := args.Get(1).(func(context.Context) error)
+				txFunc(fixture.ctx)
+			}).Return(nil)
+		fixture.mocks.agentsService.On("UnassignAgentFromJob", fixture.ctx, testOrgID, testAgentID, testJobID).Return(nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedSlackMessageStatusInProgress, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{inProgressMessage}, nil)
+		fixture.mocks.slackMessagesService.On("UpdateProcessedSlackMessage", fixture.ctx, testOrgID, inProgressMessage.ID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
+			Return(&requeuedMessage, nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
 
-		// Mock Slack client for updating reaction
+		var addedReactions []string
 		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			addedReactions = append(addedReactions, name)
 			return nil
 		}
 		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
@@ -769,141 +2562,145 @@ func TestProcessQueuedJobs(t *testing.T) {
 			return []clients.SlackItemReaction{}, nil
 		}
 		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
-			return &clients.SlackAuthTestResponse{
-				UserID: "B123456789",
-				TeamID: testSlackIntegrationID,
-			}, nil
-		}
-
-		// Mock expectations for sendStartConversationToAgent
-		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
-			Return(mo.Some(queuedJob), nil)
-		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
-			Return(mo.Some(integration), nil)
-		fixture.mocks.slackClient.MockGetPermalink = func(params *clients.SlackPermalinkParameters) (string, error) {
-			return "https://workspace.slack.com/archives/" + params.Channel + "/p" + params.TS, nil
+			return &clients.SlackAuthTestResponse{UserID: "B123456789"}, nil
 		}
-		fixture.mocks.slackClient.MockResolveMentionsInMessage = func(ctx context.Context, message string) string {
-			return message // Return unchanged for simplicity
+		var postedMessages []string
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			postedMessages = append(postedMessages, params.Text)
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: "1234567890.123456"}, nil
 		}
-		fixture.mocks.wsClient.On("SendMessage", testWSConnectionID, mock.AnythingOfType("models.BaseMessage")).
-			Return(nil)
 
 		// Execute
-		err := fixture.useCase.ProcessQueuedJobs(fixture.ctx)
+		err := fixture.useCase.RequeueSlackJob(fixture.ctx, job, testAgentID, "Agent disconnected")
 
 		// Assert
 		assert.NoError(t, err)
-		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
-		fixture.mocks.jobsService.AssertExpectations(t)
-		fixture.mocks.agentsUseCase.AssertExpectations(t)
+		assert.Equal(t, []string{"hourglass"}, addedReactions)
+		require.Len(t, postedMessages, 1)
+		assert.Contains(t, postedMessages[0], "Agent disconnected")
+		fixture.mocks.agentsService.AssertExpectations(t)
 		fixture.mocks.slackMessagesService.AssertExpectations(t)
-		fixture.mocks.wsClient.AssertExpectations(t)
+		fixture.mocks.jobsService.AssertNotCalled(t, "CloseJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		fixture.mocks.slackMessagesService.AssertNotCalled(t, "GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedSlackMessageStatusCompleted, testSlackIntegrationID)
 	})
+}
 
-	t.Run("no_agents_available", func(t *testing.T) {
+func TestHasAvailableAgentForSlackJob(t *testing.T) {
+	t.Run("checks_agents_against_the_routing_of_the_job_channel", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
-		// Generate consistent test data for this test case
-		testJobID := testutils.GenerateJobID()
-		testUserID := testutils.GenerateSlackUserID()
 		testOrgID := testutils.GenerateOrgID()
 		testChannelID := testutils.GenerateSlackChannelID()
 		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
-		testThreadTS := testutils.GenerateSlackThreadTS()
-		testSlackToken := testutils.GenerateSlackToken()
-
-		integration := &models.SlackIntegration{
-			ID:             testSlackIntegrationID,
-			OrgID:          testOrgID,
-			SlackAuthToken: testSlackToken,
-		}
+		testTeamID := "T123456789"
+		testRepoURL := "github.com/acme/backend"
+		activeSince := time.Now().Add(-30 * time.Minute)
 
-		queuedJob := &models.Job{
-			ID:    testJobID,
+		job := &models.Job{
+			ID:    testutils.GenerateJobID(),
 			OrgID: testOrgID,
 			SlackPayload: &models.SlackJobPayload{
 				IntegrationID: testSlackIntegrationID,
 				ChannelID:     testChannelID,
-				ThreadTS:      testThreadTS,
-				UserID:        testUserID,
+				ThreadTS:      testutils.GenerateSlackThreadTS(),
 			},
 		}
-
-		queuedMessage := &models.ProcessedSlackMessage{
-			ID:                 testutils.GenerateProcessedMessageID(),
-			JobID:              testJobID,
-			SlackTS:            testThreadTS,
-			SlackChannelID:     testChannelID,
-			TextContent:        "Queued message content",
-			SlackIntegrationID: testSlackIntegrationID,
-			OrgID:              testOrgID,
-			Status:             models.ProcessedSlackMessageStatusQueued,
+		slackIntegration := &models.SlackIntegration{
+			ID:          testSlackIntegrationID,
+			OrgID:       testOrgID,
+			SlackTeamID: testTeamID,
+		}
+		connectedChannel := &models.SlackConnectedChannel{
+			OrgID:          testOrgID,
+			TeamID:         testTeamID,
+			ChannelID:      testChannelID,
+			DefaultRepoURL: &testRepoURL,
+			AgentSelector:  models.AgentLabels{"gpu": "true"},
 		}
 
 		// Configure expectations
-		fixture.mocks.slackIntegrationsService.On("GetAllSlackIntegrations", fixture.ctx).
-			Return([]models.SlackIntegration{*integration}, nil)
-		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByStatus", fixture.ctx, testOrgID, models.ProcessedSlackMessageStatusQueued, testSlackIntegrationID).
-			Return([]*models.ProcessedSlackMessage{queuedMessage}, nil)
-		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
-			Return(mo.Some(queuedJob), nil)
-		fixture.mocks.agentsUseCase.On("TryAssignJobToAgent", fixture.ctx, queuedJob.ID, testOrgID).
-			Return("", false, nil) // No agents available
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+		fixture.mocks.connectedChannelsService.On("GetSlackConnectedChannel", fixture.ctx, testOrgID, testTeamID, testChannelID).
+			Return(mo.Some(connectedChannel), nil)
+		fixture.mocks.agentsUseCase.On("HasAvailableAgent", fixture.ctx, testRepoURL, models.AgentLabels{"gpu": "true"}, testOrgID, activeSince).
+			Return(true, nil)
 
 		// Execute
-		err := fixture.useCase.ProcessQueuedJobs(fixture.ctx)
+		hasAgent, err := fixture.useCase.HasAvailableAgentForSlackJob(fixture.ctx, job, activeSince)
 
 		// Assert
-		assert.NoError(t, err)
+		require.NoError(t, err)
+		assert.True(t, hasAgent)
 		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
-		fixture.mocks.jobsService.AssertExpectations(t)
+		fixture.mocks.connectedChannelsService.AssertExpectations(t)
 		fixture.mocks.agentsUseCase.AssertExpectations(t)
 	})
 }
 
-func TestProcessProcessingMessage(t *testing.T) {
-	t.Run("success_update_message_reaction", func(t *testing.T) {
+func TestFailStuckSlackJob(t *testing.T) {
+	t.Run("success_fail_in_progress_messages", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
 		// Generate consistent test data for this test case
-		testProcessedID := testutils.GenerateProcessedMessageID()
 		testJobID := testutils.GenerateJobID()
-		testClientID := testutils.GenerateClientID()
 		testOrgID := testutils.GenerateOrgID()
+		testAgentID := testutils.GenerateAgentID()
 		testChannelID := testutils.GenerateSlackChannelID()
 		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
-		testMessageID := testutils.GenerateSlackMessageID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
+		testSlackToken := testutils.GenerateSlackToken()
 
-		payload := models.ProcessingMessagePayload{
-			ProcessedMessageID: testProcessedID,
+		job := &models.Job{
+			ID:    testJobID,
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testutils.GenerateSlackUserID(),
+			},
 		}
 
-		processedMessage := &models.ProcessedSlackMessage{
-			ID:                 testProcessedID,
+		inProgressMessage := &models.ProcessedSlackMessage{
+			ID:                 testutils.GenerateProcessedMessageID(),
 			JobID:              testJobID,
-			SlackTS:            testMessageID,
+			SlackTS:            testThreadTS,
 			SlackChannelID:     testChannelID,
 			SlackIntegrationID: testSlackIntegrationID,
 			OrgID:              testOrgID,
+			Status:             models.ProcessedSlackMessageStatusInProgress,
 		}
+		failedMessage := *inProgressMessage
+		failedMessage.Status = models.ProcessedSlackMessageStatusFailed
 
 		slackIntegration := &models.SlackIntegration{
 			ID:             testSlackIntegrationID,
 			OrgID:          testOrgID,
-			SlackAuthToken: testutils.GenerateSlackToken(),
+			SlackAuthToken: testSlackToken,
 		}
 
 		// Configure expectations
-		fixture.mocks.slackMessagesService.On("GetProcessedSlackMessageByID", fixture.ctx, testOrgID, testProcessedID).
-			Return(mo.Some(processedMessage), nil)
+		fixture.mocks.txManager.On("WithTransaction", fixture.ctx, mock.AnythingOfType("func(context.Context) error")).
+			Run(func(args mock.Arguments) {
+				txFunc := args.Get(1).(func(context.Context) error)
+				txFunc(fixture.ctx)
+			}).Return(nil)
+		fixture.mocks.agentsService.On("UnassignAgentFromJob", fixture.ctx, testOrgID, testAgentID, testJobID).Return(nil)
+		fixture.mocks.slackMessagesService.On("GetProcessedMessagesByJobIDAndStatus", fixture.ctx, testOrgID, testJobID, models.ProcessedSlackMessageStatusInProgress, testSlackIntegrationID).
+			Return([]*models.ProcessedSlackMessage{inProgressMessage}, nil)
+		fixture.mocks.slackMessagesService.On("UpdateProcessedSlackMessage", fixture.ctx, testOrgID, inProgressMessage.ID, models.ProcessedSlackMessageStatusFailed, testSlackIntegrationID).
+			Return(&failedMessage, nil)
 		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
 			Return(mo.Some(slackIntegration), nil)
+		fixture.mocks.jobsService.On("AddJobTranscriptMessage", fixture.ctx, testOrgID, testJobID, models.TranscriptAuthorSystem, "", models.TranscriptMessageTypeSystemMessage, "Agent stopped responding", "").
+			Return(&models.JobTranscriptMessage{}, nil)
 
-		// Mock Slack client for updating reaction
+		var addedReactions []string
 		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			addedReactions = append(addedReactions, name)
 			return nil
 		}
 		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
@@ -913,78 +2710,108 @@ func TestProcessProcessingMessage(t *testing.T) {
 			return []clients.SlackItemReaction{}, nil
 		}
 		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
-			return &clients.SlackAuthTestResponse{
-				UserID: "B123456789",
-				TeamID: testSlackIntegrationID,
-			}, nil
+			return &clients.SlackAuthTestResponse{UserID: "B123456789"}, nil
+		}
+		var postedMessages []string
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			postedMessages = append(postedMessages, params.Text)
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: "1234567890.123456"}, nil
 		}
 
 		// Execute
-		err := fixture.useCase.ProcessProcessingMessage(fixture.ctx, testClientID, payload, testOrgID)
+		err := fixture.useCase.FailStuckSlackJob(fixture.ctx, job, testAgentID, "Agent stopped responding")
 
 		// Assert
 		assert.NoError(t, err)
+		assert.Equal(t, []string{"x"}, addedReactions)
+		require.Len(t, postedMessages, 1)
+		assert.Contains(t, postedMessages[0], "Agent stopped responding")
+		fixture.mocks.agentsService.AssertExpectations(t)
 		fixture.mocks.slackMessagesService.AssertExpectations(t)
-		fixture.mocks.jobsService.AssertExpectations(t)
-		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
+		fixture.mocks.jobsService.AssertNotCalled(t, "CloseJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	})
+}
 
-	t.Run("message_not_found", func(t *testing.T) {
+func TestProcessJobCancelled(t *testing.T) {
+	t.Run("confirms_cancellation_in_thread", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
-		// Generate consistent test data for this test case
-		testProcessedID := testutils.GenerateProcessedMessageID()
-		testClientID := testutils.GenerateClientID()
 		testOrgID := testutils.GenerateOrgID()
+		testChannelID := testutils.GenerateSlackChannelID()
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		testThreadTS := testutils.GenerateSlackThreadTS()
 
-		payload := models.ProcessingMessagePayload{
-			ProcessedMessageID: testProcessedID,
+		job := &models.Job{
+			ID:    testutils.GenerateJobID(),
+			OrgID: testOrgID,
+			SlackPayload: &models.SlackJobPayload{
+				IntegrationID: testSlackIntegrationID,
+				ChannelID:     testChannelID,
+				ThreadTS:      testThreadTS,
+				UserID:        testutils.GenerateSlackUserID(),
+			},
+		}
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testOrgID,
+			SlackAuthToken: testutils.GenerateSlackToken(),
 		}
 
 		// Configure expectations
-		fixture.mocks.slackMessagesService.On("GetProcessedSlackMessageByID", fixture.ctx, testOrgID, testProcessedID).
-			Return(mo.None[*models.ProcessedSlackMessage](), nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+
+		var addedReactions []string
+		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			addedReactions = append(addedReactions, name)
+			return nil
+		}
+		fixture.mocks.slackClient.MockRemoveReaction = func(name string, item clients.SlackItemRef) error {
+			return nil
+		}
+		fixture.mocks.slackClient.MockGetReactions = func(item clients.SlackItemRef, params clients.SlackGetReactionsParameters) ([]clients.SlackItemReaction, error) {
+			return []clients.SlackItemReaction{}, nil
+		}
+		fixture.mocks.slackClient.MockAuthTest = func() (*clients.SlackAuthTestResponse, error) {
+			return &clients.SlackAuthTestResponse{UserID: "B123456789"}, nil
+		}
+		var postedText string
+		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
+			postedText = params.Text
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: "1234567890.123456"}, nil
+		}
 
 		// Execute
-		err := fixture.useCase.ProcessProcessingMessage(fixture.ctx, testClientID, payload, testOrgID)
+		err := fixture.useCase.ProcessJobCancelled(fixture.ctx, job)
 
 		// Assert
-		assert.NoError(t, err)
-		fixture.mocks.slackMessagesService.AssertExpectations(t)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"hand"}, addedReactions)
+		assert.Contains(t, postedText, "Job stopped")
 	})
 }
 
-func TestProcessSystemMessage(t *testing.T) {
-	t.Run("success_regular_system_message", func(t *testing.T) {
+func TestProcessJobCancellationUnconfirmed(t *testing.T) {
+	t.Run("reports_unconfirmed_stop_in_thread", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
-		// Generate consistent test data
-		testJobID := testutils.GenerateJobID()
 		testOrgID := testutils.GenerateOrgID()
-		testClientID := testutils.GenerateClientID()
 		testChannelID := testutils.GenerateSlackChannelID()
-		testThreadTS := testutils.GenerateSlackThreadTS()
 		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
-		testUserID := testutils.GenerateSlackUserID()
-
-		payload := models.SystemMessagePayload{
-			JobID:   testJobID,
-			Message: "System notification message",
-		}
+		testThreadTS := testutils.GenerateSlackThreadTS()
 
 		job := &models.Job{
-			ID:    testJobID,
+			ID:    testutils.GenerateJobID(),
 			OrgID: testOrgID,
 			SlackPayload: &models.SlackJobPayload{
 				IntegrationID: testSlackIntegrationID,
 				ChannelID:     testChannelID,
 				ThreadTS:      testThreadTS,
-				UserID:        testUserID,
+				UserID:        testutils.GenerateSlackUserID(),
 			},
 		}
-
 		slackIntegration := &models.SlackIntegration{
 			ID:             testSlackIntegrationID,
 			OrgID:          testOrgID,
@@ -992,52 +2819,65 @@ func TestProcessSystemMessage(t *testing.T) {
 		}
 
 		// Configure expectations
-		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
-			Return(mo.Some(job), nil)
 		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
 			Return(mo.Some(slackIntegration), nil)
-		fixture.mocks.jobsService.On("UpdateJobTimestamp", fixture.ctx, testOrgID, testJobID).Return(nil)
 
-		// Mock Slack client for posting message
+		var addedReactions []string
+		fixture.mocks.slackClient.MockAddReaction = func(name string, item clients.SlackItemRef) error {
+			addedReactions = append(addedReactions, name)
+			return nil
+		}
+		var postedThreadTS, postedText string
 		fixture.mocks.slackClient.MockPostMessage = func(channelID string, params clients.SlackMessageParams) (*clients.SlackPostMessageResponse, error) {
-			return &clients.SlackPostMessageResponse{
-				Channel:   channelID,
-				Timestamp: "1234567890.123456",
-			}, nil
+			postedThreadTS = params.ThreadTS.OrEmpty()
+			postedText = params.Text
+			return &clients.SlackPostMessageResponse{Channel: channelID, Timestamp: "1234567890.123456"}, nil
 		}
 
 		// Execute
-		err := fixture.useCase.ProcessSystemMessage(fixture.ctx, testClientID, payload, testOrgID)
+		err := fixture.useCase.ProcessJobCancellationUnconfirmed(fixture.ctx, job)
 
-		// Assert
-		assert.NoError(t, err)
-		fixture.mocks.jobsService.AssertExpectations(t)
-		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
+		// Assert - the job is not shown as stopped, as the agent may still be working on it
+		require.NoError(t, err)
+		assert.Empty(t, addedReactions)
+		assert.Equal(t, testThreadTS, postedThreadTS)
+		assert.Contains(t, postedText, "could not be confirmed by the agent")
 	})
+}
 
-	t.Run("job_not_found", func(t *testing.T) {
+func TestDownloadAttachment(t *testing.T) {
+	t.Run("downloads_file_with_token_of_integration", func(t *testing.T) {
 		// Setup
 		fixture := setupSlackUseCaseTest(t)
 
-		// Generate consistent test data
-		testJobID := testutils.GenerateJobID()
-		testOrgID := testutils.GenerateOrgID()
-		testClientID := testutils.GenerateClientID()
-
-		payload := models.SystemMessagePayload{
-			JobID:   testJobID,
-			Message: "System message",
+		testSlackIntegrationID := testutils.GenerateSlackIntegrationID()
+		slackIntegration := &models.SlackIntegration{
+			ID:             testSlackIntegrationID,
+			OrgID:          testutils.GenerateOrgID(),
+			SlackAuthToken: testutils.GenerateSlackToken(),
+		}
+		source := models.AttachmentSource{
+			Platform:      models.JobTypeSlack,
+			IntegrationID: testSlackIntegrationID,
+			URL:           "https://files.slack.com/files-pri/T1-F1/download/screenshot.png",
 		}
 
 		// Configure expectations
-		fixture.mocks.jobsService.On("GetJobByID", fixture.ctx, testOrgID, testJobID).
-			Return(mo.None[*models.Job](), nil)
+		fixture.mocks.slackIntegrationsService.On("GetSlackIntegrationByID", fixture.ctx, testSlackIntegrationID).
+			Return(mo.Some(slackIntegration), nil)
+		var downloadedURLs []string
+		fixture.mocks.slackClient.MockDownloadFile = func(url string) ([]byte, error) {
+			downloadedURLs = append(downloadedURLs, url)
+			return []byte("png bytes"), nil
+		}
 
 		// Execute
-		err := fixture.useCase.ProcessSystemMessage(fixture.ctx, testClientID, payload, testOrgID)
+		content, err := fixture.useCase.DownloadAttachment(fixture.ctx, source)
 
 		// Assert
-		assert.NoError(t, err)
-		fixture.mocks.jobsService.AssertExpectations(t)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("png bytes"), content)
+		assert.Equal(t, []string{source.URL}, downloadedURLs)
+		fixture.mocks.slackIntegrationsService.AssertExpectations(t)
 	})
 }
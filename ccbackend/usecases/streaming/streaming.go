@@ -0,0 +1,215 @@
+package streaming
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// staleStreamAge is how long a stream is kept without receiving deltas before it is dropped,
+// e.g. when the agent disconnected before sending its final assistant message
+const staleStreamAge = time.Hour
+
+// pendingPostTimeout is how long Finish waits for the stream's first post to complete
+const pendingPostTimeout = 30 * time.Second
+
+// UpdateAction is what the caller has to do with the chat message after a delta was appended
+type UpdateAction int
+
+const (
+	// UpdateActionNone means the delta was buffered - a chat message of the integration was updated too recently
+	UpdateActionNone UpdateAction = iota
+	// UpdateActionPost means the stream has no chat message yet and the text should be posted
+	UpdateActionPost
+	// UpdateActionEdit means the text should replace the content of the stream's chat message
+	UpdateActionEdit
+)
+
+// Update tells the caller how to render the streamed text
+type Update struct {
+	Action UpdateAction
+	// MessageID is the platform message ID of the stream's chat message (empty for UpdateActionPost)
+	MessageID string
+	// Text is the full text streamed so far
+	Text string
+}
+
+// Stream is text which is streamed into a single chat message edited in place,
+// e.g. an assistant reply or a job's progress status
+type Stream struct {
+	// MessageID is the platform message ID (Slack TS, Discord message ID) once the message is posted
+	MessageID string
+	Text      string
+
+	lines        []string
+	renderedText string
+	posting      bool
+	// posted is closed once the pending first post of the stream completed
+	posted      chan struct{}
+	lastDeltaAt time.Time
+}
+
+// Outdated reports whether the stream has text which was buffered but never rendered in its chat message
+func (s Stream) Outdated() bool {
+	return s.Text != s.renderedText
+}
+
+// Throttle limits how often chat messages are edited per integration, e.g. per Slack workspace,
+// since the platform rate limits apply to all streams of the integration together
+//
+// The limit is per replica - it lives in memory, so with several replicas streaming into the same integration
+// the integration's messages are edited up to once per minUpdateInterval on each of them.
+type Throttle struct {
+	mu                sync.Mutex
+	minUpdateInterval time.Duration
+	lastUpdatedAt     map[string]time.Time
+}
+
+// NewThrottle creates a throttle which allows one edit per integration every minUpdateInterval
+func NewThrottle(minUpdateInterval time.Duration) *Throttle {
+	return &Throttle{
+		minUpdateInterval: minUpdateInterval,
+		lastUpdatedAt:     make(map[string]time.Time),
+	}
+}
+
+// allow reports whether the integration can edit a chat message now and if so, records the edit
+func (t *Throttle) allow(integrationID string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if now.Sub(t.lastUpdatedAt[integrationID]) < t.minUpdateInterval {
+		return false
+	}
+	t.lastUpdatedAt[integrationID] = now
+	return true
+}
+
+// record counts a chat message posted by the integration as an update, so it isn't edited again right away
+func (t *Throttle) record(integrationID string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastUpdatedAt[integrationID] = now
+}
+
+// Tracker keeps the streams being rendered in chat, e.g. assistant replies keyed by processed message ID,
+// and throttles how often their chat messages are edited to stay within the platform rate limits
+//
+// Streams live in memory - the agent's messages are always handled by the replica holding its connection.
+type Tracker struct {
+	mu       sync.Mutex
+	throttle *Throttle
+	streams  map[string]*Stream
+}
+
+// NewTracker creates a tracker whose edits are limited by the throttle, which can be shared between trackers
+func NewTracker(throttle *Throttle) *Tracker {
+	return &Tracker{
+		throttle: throttle,
+		streams:  make(map[string]*Stream),
+	}
+}
+
+// Append adds the delta to the stream of the integration and returns how the chat message should be updated
+// The first delta of a stream is posted right away. Until the post completes and Posted is called,
+// deltas are only buffered, so a stream is never posted twice.
+func (t *Tracker) Append(key, integrationID, delta string, now time.Time) Update {
+	return t.update(key, integrationID, now, func(stream *Stream) {
+		stream.Text += delta
+	})
+}
+
+// AppendLine adds a line to the stream of the integration, keeping only its latest maxLines lines,
+// and returns how the chat message should be updated
+func (t *Tracker) AppendLine(key, integrationID, line string, maxLines int, now time.Time) Update {
+	return t.update(key, integrationID, now, func(stream *Stream) {
+		stream.lines = append(stream.lines, line)
+		if len(stream.lines) > maxLines {
+			stream.lines = stream.lines[len(stream.lines)-maxLines:]
+		}
+		stream.Text = strings.Join(stream.lines, "\n")
+	})
+}
+
+func (t *Tracker) update(key, integrationID string, now time.Time, apply func(stream *Stream)) Update {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.dropStaleStreams(now)
+
+	stream, ok := t.streams[key]
+	if !ok {
+		stream = &Stream{}
+		t.streams[key] = stream
+	}
+	apply(stream)
+	stream.lastDeltaAt = now
+
+	switch {
+	case stream.posting || (stream.MessageID == "" && strings.TrimSpace(stream.Text) == ""):
+		// Chat platforms reject empty messages, so nothing is posted until the stream has some text
+		return Update{Action: UpdateActionNone, Text: stream.Text}
+	case stream.MessageID == "":
+		stream.posting = true
+		stream.posted = make(chan struct{})
+		t.throttle.record(integrationID, now)
+		stream.renderedText = stream.Text
+		return Update{Action: UpdateActionPost, Text: stream.Text}
+	case !t.throttle.allow(integrationID, now):
+		return Update{Action: UpdateActionNone, MessageID: stream.MessageID, Text: stream.Text}
+	default:
+		stream.renderedText = stream.Text
+		return Update{Action: UpdateActionEdit, MessageID: stream.MessageID, Text: stream.Text}
+	}
+}
+
+// Posted records the chat message the stream was posted as
+// An empty message ID means posting failed and the next delta posts the stream again.
+func (t *Tracker) Posted(key, messageID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stream, ok := t.streams[key]
+	if !ok || !stream.posting {
+		return
+	}
+	stream.posting = false
+	stream.MessageID = messageID
+	close(stream.posted)
+}
+
+// Finish removes the stream and returns it, if any, so the caller can render its final text
+// If the stream's first post is still in flight, Finish waits for it, so the caller edits the posted
+// message instead of posting the stream a second time.
+func (t *Tracker) Finish(ctx context.Context, key string) (Stream, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stream, ok := t.streams[key]
+	if ok && stream.posting {
+		posted := stream.posted
+		t.mu.Unlock()
+		select {
+		case <-posted:
+		case <-ctx.Done():
+		case <-time.After(pendingPostTimeout):
+		}
+		t.mu.Lock()
+		stream, ok = t.streams[key]
+	}
+	if !ok {
+		return Stream{}, false
+	}
+	delete(t.streams, key)
+	return *stream, true
+}
+
+func (t *Tracker) dropStaleStreams(now time.Time) {
+	for key, stream := range t.streams {
+		if now.Sub(stream.lastDeltaAt) > staleStreamAge {
+			delete(t.streams, key)
+		}
+	}
+}
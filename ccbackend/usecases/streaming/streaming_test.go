@@ -0,0 +1,163 @@
+package streaming
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	base := time.Date(2025, 9, 28, 10, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return base.Add(time.Duration(ms) * time.Millisecond) }
+	ctx := context.Background()
+
+	t.Run("First delta is posted and later deltas edit the message", func(t *testing.T) {
+		tracker := NewTracker(NewThrottle(time.Second))
+
+		update := tracker.Append("pm_1", "int_1", "Hello", at(0))
+		assert.Equal(t, Update{Action: UpdateActionPost, Text: "Hello"}, update)
+		tracker.Posted("pm_1", "ts_1")
+
+		update = tracker.Append("pm_1", "int_1", ", world", at(1000))
+		assert.Equal(t, Update{Action: UpdateActionEdit, MessageID: "ts_1", Text: "Hello, world"}, update)
+	})
+
+	t.Run("Deltas within the update interval are buffered", func(t *testing.T) {
+		tracker := NewTracker(NewThrottle(time.Second))
+		tracker.Append("pm_1", "int_1", "a", at(0))
+		tracker.Posted("pm_1", "ts_1")
+
+		update := tracker.Append("pm_1", "int_1", "b", at(500))
+		assert.Equal(t, Update{Action: UpdateActionNone, MessageID: "ts_1", Text: "ab"}, update)
+
+		update = tracker.Append("pm_1", "int_1", "c", at(1200))
+		assert.Equal(t, Update{Action: UpdateActionEdit, MessageID: "ts_1", Text: "abc"}, update)
+	})
+
+	t.Run("Deltas arriving while the message is being posted are buffered", func(t *testing.T) {
+		tracker := NewTracker(NewThrottle(time.Second))
+		tracker.Append("pm_1", "int_1", "a", at(0))
+
+		update := tracker.Append("pm_1", "int_1", "b", at(2000))
+		assert.Equal(t, UpdateActionNone, update.Action)
+	})
+
+	t.Run("Failed post is retried on the next delta", func(t *testing.T) {
+		tracker := NewTracker(NewThrottle(time.Second))
+		tracker.Append("pm_1", "int_1", "a", at(0))
+		tracker.Posted("pm_1", "")
+
+		update := tracker.Append("pm_1", "int_1", "b", at(100))
+		assert.Equal(t, Update{Action: UpdateActionPost, Text: "ab"}, update)
+	})
+
+	t.Run("Streams of one integration share the update interval", func(t *testing.T) {
+		throttle := NewThrottle(time.Second)
+		replies := NewTracker(throttle)
+		statuses := NewTracker(throttle)
+		replies.Append("pm_1", "int_1", "a", at(0))
+		replies.Posted("pm_1", "ts_1")
+		replies.Append("pm_2", "int_1", "b", at(0))
+		replies.Posted("pm_2", "ts_2")
+		statuses.AppendLine("job_1", "int_1", "step 1", 5, at(0))
+		statuses.Posted("job_1", "ts_3")
+
+		update := replies.Append("pm_1", "int_1", "c", at(1000))
+		assert.Equal(t, UpdateActionEdit, update.Action)
+
+		update = replies.Append("pm_2", "int_1", "d", at(1100))
+		assert.Equal(t, UpdateActionNone, update.Action)
+		update = statuses.AppendLine("job_1", "int_1", "step 2", 5, at(1200))
+		assert.Equal(t, UpdateActionNone, update.Action)
+
+		statuses.AppendLine("job_2", "int_2", "step 1", 5, at(0))
+		statuses.Posted("job_2", "ts_4")
+		update = statuses.AppendLine("job_2", "int_2", "step 2", 5, at(1200))
+		assert.Equal(t, UpdateActionEdit, update.Action)
+
+		update = replies.Append("pm_2", "int_1", "e", at(2000))
+		assert.Equal(t, Update{Action: UpdateActionEdit, MessageID: "ts_2", Text: "bde"}, update)
+	})
+
+	t.Run("Finish waits for the pending post", func(t *testing.T) {
+		tracker := NewTracker(NewThrottle(time.Second))
+		tracker.Append("pm_1", "int_1", "a", at(0))
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			tracker.Posted("pm_1", "ts_1")
+		}()
+
+		stream, ok := tracker.Finish(ctx, "pm_1")
+		assert.True(t, ok)
+		assert.Equal(t, "ts_1", stream.MessageID)
+	})
+
+	t.Run("Finish stops waiting for the pending post when the context is done", func(t *testing.T) {
+		tracker := NewTracker(NewThrottle(time.Second))
+		tracker.Append("pm_1", "int_1", "a", at(0))
+
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		stream, ok := tracker.Finish(cancelledCtx, "pm_1")
+		assert.True(t, ok)
+		assert.Empty(t, stream.MessageID)
+	})
+
+	t.Run("Finish returns and removes the stream", func(t *testing.T) {
+		tracker := NewTracker(NewThrottle(time.Second))
+		tracker.Append("pm_1", "int_1", "a", at(0))
+		tracker.Posted("pm_1", "ts_1")
+
+		stream, ok := tracker.Finish(ctx, "pm_1")
+		assert.True(t, ok)
+		assert.Equal(t, "ts_1", stream.MessageID)
+		assert.Equal(t, "a", stream.Text)
+
+		_, ok = tracker.Finish(ctx, "pm_1")
+		assert.False(t, ok)
+	})
+
+	t.Run("Lines keep only the latest steps", func(t *testing.T) {
+		tracker := NewTracker(NewThrottle(time.Second))
+
+		update := tracker.AppendLine("job_1", "int_1", "step 1", 2, at(0))
+		assert.Equal(t, Update{Action: UpdateActionPost, Text: "step 1"}, update)
+		tracker.Posted("job_1", "ts_1")
+
+		tracker.AppendLine("job_1", "int_1", "step 2", 2, at(100))
+		update = tracker.AppendLine("job_1", "int_1", "step 3", 2, at(1100))
+		assert.Equal(t, Update{Action: UpdateActionEdit, MessageID: "ts_1", Text: "step 2\nstep 3"}, update)
+	})
+
+	t.Run("Buffered text makes the stream outdated", func(t *testing.T) {
+		tracker := NewTracker(NewThrottle(time.Second))
+		tracker.AppendLine("job_1", "int_1", "step 1", 5, at(0))
+		tracker.Posted("job_1", "ts_1")
+
+		stream, _ := tracker.Finish(ctx, "job_1")
+		assert.False(t, stream.Outdated())
+
+		tracker.AppendLine("job_2", "int_1", "step 1", 5, at(0))
+		tracker.Posted("job_2", "ts_2")
+		tracker.AppendLine("job_2", "int_1", "step 2", 5, at(100))
+
+		stream, _ = tracker.Finish(ctx, "job_2")
+		assert.True(t, stream.Outdated())
+		assert.Equal(t, "step 1\nstep 2", stream.Text)
+	})
+
+	t.Run("Stale streams are dropped", func(t *testing.T) {
+		tracker := NewTracker(NewThrottle(time.Second))
+		tracker.Append("pm_stale", "int_1", "a", at(0))
+		tracker.Posted("pm_stale", "ts_1")
+
+		tracker.Append("pm_1", "int_1", "b", base.Add(staleStreamAge+time.Minute))
+
+		_, ok := tracker.Finish(ctx, "pm_stale")
+		assert.False(t, ok)
+	})
+}
@@ -68,3 +68,19 @@ func SanitiseURL(raw string) string {
 
 	return host + parsed.Path
 }
+
+// RepoURLsMatch compares repository URLs ignoring scheme, case, trailing slashes and .git suffix
+// e.g. "https://github.com/org/repo.git" matches "github.com/org/repo"
+func RepoURLsMatch(a, b string) bool {
+	return normalizeRepoURL(a) == normalizeRepoURL(b)
+}
+
+func normalizeRepoURL(repoURL string) string {
+	normalized := strings.ToLower(strings.TrimSpace(repoURL))
+	if idx := strings.Index(normalized, "://"); idx != -1 {
+		normalized = normalized[idx+3:]
+	}
+	normalized = strings.TrimSuffix(normalized, "/")
+	normalized = strings.TrimSuffix(normalized, ".git")
+	return normalized
+}
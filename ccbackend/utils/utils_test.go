@@ -305,3 +305,23 @@ func TestSanitiseURL(t *testing.T) {
 		})
 	}
 }
+
+func TestRepoURLsMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected bool
+	}{
+		{"identical", "github.com/acme/backend", "github.com/acme/backend", true},
+		{"scheme and .git suffix", "https://github.com/acme/backend.git", "github.com/acme/backend", true},
+		{"case and trailing slash", "GitHub.com/Acme/Backend/", "github.com/acme/backend", true},
+		{"different repositories", "github.com/acme/frontend", "github.com/acme/backend", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RepoURLsMatch(tt.a, tt.b))
+		})
+	}
+}